@@ -20,5 +20,8 @@ config.OPENAPI_JSON = path.join(config.ROOT_DIR, "api", "openapi.json");
 config.FULL_PATH = `${config.URL_PATH}:${config.URL_PORT}/${config.BASE_VERSION}`;
 config.FILE_UPLOAD_PATH = path.join(config.PROJECT_DIR, "uploaded_files");
 config.MOCK_DIR = path.join(config.PROJECT_DIR, "mocks");
+config.ARTIFACT_DIR = path.join(config.PROJECT_DIR, "artifacts");
+config.LINT_RUN_DB = path.join(config.PROJECT_DIR, "data", "lint-runs.sqlite");
+config.SCORING_POLICY_FILE = path.join(config.PROJECT_DIR, "rulesets", "scoring.yaml");
 
 module.exports = config;
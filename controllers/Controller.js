@@ -1,9 +1,12 @@
 const fs = require("node:fs");
 const path = require("node:path");
+const { Readable } = require("node:stream");
 const config = require("../config");
 const Service = require("../services/Service");
 const logger = require("../logger");
 
+const SSE_HEARTBEAT_MS = 15000;
+
 class Controller {
   static getStatusText(status) {
     const statusTexts = {
@@ -13,9 +16,12 @@ class Controller {
       404: "Not Found",
       405: "Method Not Allowed",
       409: "Conflict",
+      413: "Content Too Large",
       422: "Unprocessable Entity",
       429: "Too Many Requests",
       500: "Internal Server Error",
+      502: "Bad Gateway",
+      504: "Gateway Timeout",
     };
     return statusTexts[status] || "Unknown Error";
   }
@@ -35,6 +41,10 @@ class Controller {
       });
     }
     const responsePayload = payload.payload !== undefined ? payload.payload : payload;
+    if (responsePayload instanceof Readable) {
+      Controller.sendStream(response, responsePayload);
+      return;
+    }
     if (Buffer.isBuffer(responsePayload)) {
       if (!response.get("Content-Type")) {
         response.set("Content-Type", "application/octet-stream");
@@ -51,6 +61,28 @@ class Controller {
     }
   }
 
+  /**
+   * Streams a (large) payload such as a ZIP export straight to the client. Once the headers are
+   * sent a failure can no longer become a problem response, so the connection is aborted instead.
+   */
+  static sendStream(response, stream) {
+    if (!response.get("Content-Type")) {
+      response.set("Content-Type", "application/octet-stream");
+    }
+    stream.once("error", (error) => {
+      logger.error(`Streaming response failed: ${error.message}`, { stack: error.stack });
+      if (response.headersSent) {
+        response.destroy(error);
+        return;
+      }
+      stream.unpipe(response);
+      Controller.sendError(response, { code: 500, message: "Het versturen van de response is mislukt." });
+    });
+    // A client that disconnects halfway stops the stream, so its temporary files are cleaned up.
+    response.once("close", () => stream.destroy());
+    stream.pipe(response);
+  }
+
   static sendError(response, error) {
     const status = error.code || 500;
     const reason = error.message || error.error?.message || "Unexpected error";
@@ -160,7 +192,7 @@ class Controller {
 
   static collectRequestParams(request) {
     let requestParams = {};
-    if (request.openapi.schema.requestBody !== null) {
+    if (request.openapi.schema.requestBody) {
       const { content } = request.openapi.schema.requestBody;
       if (content["application/json"] !== undefined) {
         const requestBodyName = Controller.getRequestBodyName(request);
@@ -243,6 +275,76 @@ class Controller {
     return requestParams;
   }
 
+  /**
+   * Server-Sent Events: de service krijgt naast de parameters een `emit(event, data)` functie en een
+   * AbortSignal dat afgaat als de client de verbinding verbreekt. De stream begint pas bij het eerste
+   * event; een fout daarvoor (zoals een ontbrekende oasBody) wordt een gewone problem-response,
+   * daarna een `error` event.
+   */
+  static async handleStreamRequest(request, response, serviceOperation) {
+    let params;
+    try {
+      params = Controller.collectRequestParams(request);
+    } catch (error) {
+      Controller.sendError(response, error);
+      return;
+    }
+    const disconnect = new AbortController();
+    response.on("close", () => {
+      if (!response.writableFinished) {
+        disconnect.abort();
+      }
+    });
+    let heartbeat;
+    const start = () => {
+      response.status(200);
+      response.set({
+        "Content-Type": "text/event-stream; charset=utf-8",
+        "Cache-Control": "no-cache",
+        Connection: "keep-alive",
+        "X-Accel-Buffering": "no",
+      });
+      response.flushHeaders();
+      heartbeat = setInterval(() => {
+        if (!disconnect.signal.aborted) {
+          response.write(": keep-alive\n\n");
+        }
+      }, SSE_HEARTBEAT_MS);
+    };
+    const emit = (event, data) => {
+      if (disconnect.signal.aborted) {
+        return;
+      }
+      if (!response.headersSent) {
+        start();
+      }
+      response.write(`event: ${event}\ndata: ${JSON.stringify(data)}\n\n`);
+    };
+    try {
+      const result = await serviceOperation(params, emit, disconnect.signal);
+      emit("result", result?.payload !== undefined ? result.payload : result);
+    } catch (error) {
+      if (disconnect.signal.aborted) {
+        return;
+      }
+      if (!response.headersSent) {
+        Controller.sendError(response, error);
+        return;
+      }
+      const status = error.code || 500;
+      emit("error", {
+        status,
+        title: Controller.getStatusText(status),
+        detail: error.error?.detail || error.error?.message || error.message || "Unexpected error",
+      });
+    } finally {
+      clearInterval(heartbeat);
+      if (response.headersSent && !response.writableEnded) {
+        response.end();
+      }
+    }
+  }
+
   static async handleRequest(request, response, serviceOperation) {
     try {
       const serviceResponse = await serviceOperation(Controller.collectRequestParams(request));
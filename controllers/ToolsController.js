@@ -9,6 +9,11 @@
 const Controller = require("./Controller");
 const service = require("../services/ToolsService");
 
+// Endpoints that return a specification honour the Accept header (application/json or application/yaml),
+// so it is forwarded to the service alongside the regular request parameters.
+const withAccept = (request, serviceOperation) => (params) =>
+  serviceOperation({ ...params, accept: request.headers.accept });
+
 const arazzoMarkdown = async (request, response) => {
   await Controller.handleRequest(request, response, service.arazzoMarkdown);
 };
@@ -17,22 +22,244 @@ const arazzoMermaid = async (request, response) => {
   await Controller.handleRequest(request, response, service.arazzoMermaid);
 };
 
+const arazzoLint = async (request, response) => {
+  await Controller.handleRequest(request, response, service.arazzoLint);
+};
+
+const generateArazzoTests = async (request, response) => {
+  await Controller.handleRequest(request, response, service.generateArazzoTests);
+};
+
 const convertOAS = async (request, response) => {
-  await Controller.handleRequest(request, response, service.convertOAS);
+  await Controller.handleRequest(request, response, withAccept(request, service.convertOAS));
 };
 
 const createPostmanCollection = async (request, response) => {
   await Controller.handleRequest(request, response, service.createPostmanCollection);
 };
 
+const createBrunoCollection = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createBrunoCollection);
+};
+
+const createInsomniaExport = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createInsomniaExport);
+};
+
+const createHurlTests = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createHurlTests);
+};
+
+const createHttpFiles = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createHttpFiles);
+};
+
+const createCurlScripts = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createCurlScripts);
+};
+
+const createHttpieScripts = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createHttpieScripts);
+};
+
+const createRedocDocs = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createRedocDocs);
+};
+
+const createDocsSite = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createDocsSite);
+};
+
+const createReferenceDocs = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createReferenceDocs);
+};
+
+const createLandingPage = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createLandingPage);
+};
+
+const createGoServer = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createGoServer);
+};
+
+const createCode = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createCode);
+};
+
+const createTypeScriptTypes = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createTypeScriptTypes);
+};
+
+const createPrismMock = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createPrismMock);
+};
+
+const createHostedMock = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createHostedMock);
+};
+
+const deleteHostedMock = async (request, response) => {
+  await Controller.handleRequest(request, response, service.deleteHostedMock);
+};
+
+/**
+ * Requests to a hosted mock are not described in openapi.json, so they bypass the validator and
+ * are passed on as-is. The path is relative to the mock, e.g. /dieren/12.
+ */
+const serveHostedMock = async (request, response) => {
+  try {
+    const { mockId } = request.params;
+    const serviceResponse = await service.serveHostedMock({
+      mockId,
+      method: request.method,
+      path: request.path.slice(`/v1/mock/${mockId}`.length),
+      query: request.query,
+      headers: request.headers,
+      body: request.body,
+    });
+    Controller.sendResponse(response, serviceResponse);
+  } catch (error) {
+    Controller.sendError(response, error);
+  }
+};
+
+const convertPostmanToOAS = async (request, response) => {
+  await Controller.handleRequest(request, response, service.convertPostmanToOAS);
+};
+
+const convertBrunoToOAS = async (request, response) => {
+  await Controller.handleRequest(request, response, service.convertBrunoToOAS);
+};
+
+const convertHarToOAS = async (request, response) => {
+  await Controller.handleRequest(request, response, service.convertHarToOAS);
+};
+
+const createSoapUiProject = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createSoapUiProject);
+};
+
+const createKarateTests = async (request, response) => {
+  await Controller.handleRequest(request, response, service.createKarateTests);
+};
+
 const bundleOAS = async (request, response) => {
-  await Controller.handleRequest(request, response, service.bundleOAS);
+  await Controller.handleRequest(request, response, withAccept(request, service.bundleOAS));
+};
+
+const splitOAS = async (request, response) => {
+  await Controller.handleRequest(request, response, service.splitOAS);
+};
+
+const extractSharedComponents = async (request, response) => {
+  await Controller.handleRequest(request, response, service.extractSharedComponents);
+};
+
+const filterOAS = async (request, response) => {
+  await Controller.handleRequest(request, response, withAccept(request, service.filterOAS));
+};
+
+const pruneOAS = async (request, response) => {
+  await Controller.handleRequest(request, response, withAccept(request, service.pruneOAS));
+};
+
+const formatOAS = async (request, response) => {
+  await Controller.handleRequest(request, response, withAccept(request, service.formatOAS));
+};
+
+const reformatOAS = async (request, response) => {
+  await Controller.handleRequest(request, response, service.reformatOAS);
+};
+
+const diffOAS = async (request, response) => {
+  await Controller.handleRequest(request, response, service.diffOAS);
+};
+
+const redactOAS = async (request, response) => {
+  await Controller.handleRequest(request, response, withAccept(request, service.redactOAS));
+};
+
+const statsOAS = async (request, response) => {
+  await Controller.handleRequest(request, response, service.statsOAS);
+};
+
+const normalizeServers = async (request, response) => {
+  await Controller.handleRequest(request, response, withAccept(request, service.normalizeServers));
+};
+
+const translateOAS = async (request, response) => {
+  await Controller.handleRequest(request, response, withAccept(request, service.translateOAS));
+};
+
+const normalizePolymorphism = async (request, response) => {
+  await Controller.handleRequest(request, response, withAccept(request, service.normalizePolymorphism));
 };
 
 const generateOAS = async (request, response) => {
   await Controller.handleRequest(request, response, service.generateOAS);
 };
 
+const createExamples = async (request, response) => {
+  await Controller.handleRequest(request, response, withAccept(request, service.createExamples));
+};
+
+const applyOverlay = async (request, response) => {
+  await Controller.handleRequest(request, response, withAccept(request, service.applyOverlay));
+};
+
+const listLintRules = async (request, response) => {
+  await Controller.handleRequest(request, response, service.listLintRules);
+};
+
+const getLintRun = async (request, response) => {
+  await Controller.handleRequest(request, response, service.getLintRun);
+};
+
+const lintBatch = async (request, response) => {
+  await Controller.handleRequest(request, response, service.lintBatch);
+};
+
+const lintReport = async (request, response) => {
+  await Controller.handleRequest(request, response, service.lintReport);
+};
+
+const lintDiff = async (request, response) => {
+  await Controller.handleRequest(request, response, service.lintDiff);
+};
+
+const lintFix = async (request, response) => {
+  await Controller.handleRequest(request, response, service.lintFix);
+};
+
+const checkResponseCoverage = async (request, response) => {
+  await Controller.handleRequest(request, response, service.checkResponseCoverage);
+};
+
+const fixResponseCoverage = async (request, response) => {
+  await Controller.handleRequest(request, response, withAccept(request, service.fixResponseCoverage));
+};
+
+const scaffoldAdr = async (request, response) => {
+  await Controller.handleRequest(request, response, withAccept(request, service.scaffoldAdr));
+};
+
+// The self-service endpoints act on the caller's own identity, so the Authorization header is
+// forwarded to the service alongside the regular request parameters.
+const withAuthorization = (request, serviceOperation) => (params) =>
+  serviceOperation({ ...params, authorization: request.headers.authorization });
+
+const listMyClients = async (request, response) => {
+  await Controller.handleRequest(request, response, withAuthorization(request, service.listMyClients));
+};
+
+const createMyClient = async (request, response) => {
+  await Controller.handleRequest(request, response, withAuthorization(request, service.createMyClient));
+};
+
+const deleteMyClient = async (request, response) => {
+  await Controller.handleRequest(request, response, withAuthorization(request, service.deleteMyClient));
+};
+
 const untrustClient = async (request, response) => {
   await Controller.handleRequest(request, response, service.untrustClient);
 };
@@ -41,13 +268,73 @@ const validatorOpenAPIPost = async (request, response) => {
   await Controller.handleRequest(request, response, service.validatorOpenAPIPost);
 };
 
+const validatorOpenAPIStream = async (request, response) => {
+  await Controller.handleStreamRequest(request, response, service.validatorOpenAPIStream);
+};
+
+const validatorOpenAPIStructure = async (request, response) => {
+  await Controller.handleRequest(request, response, service.validatorOpenAPIStructure);
+};
+
 module.exports = {
+  arazzoLint,
   arazzoMarkdown,
   arazzoMermaid,
+  generateArazzoTests,
   convertOAS,
   createPostmanCollection,
+  createBrunoCollection,
+  createInsomniaExport,
+  createHurlTests,
+  createHttpFiles,
+  createCurlScripts,
+  createHttpieScripts,
+  createRedocDocs,
+  createDocsSite,
+  createReferenceDocs,
+  createLandingPage,
+  createGoServer,
+  createCode,
+  createTypeScriptTypes,
+  createPrismMock,
+  createHostedMock,
+  deleteHostedMock,
+  serveHostedMock,
+  convertPostmanToOAS,
+  convertBrunoToOAS,
+  convertHarToOAS,
+  createSoapUiProject,
+  createKarateTests,
   bundleOAS,
+  splitOAS,
+  extractSharedComponents,
+  filterOAS,
+  pruneOAS,
+  formatOAS,
+  reformatOAS,
+  diffOAS,
+  redactOAS,
+  statsOAS,
+  normalizeServers,
+  translateOAS,
+  normalizePolymorphism,
   generateOAS,
+  createExamples,
+  applyOverlay,
+  getLintRun,
+  listLintRules,
+  lintBatch,
+  lintReport,
+  lintDiff,
+  lintFix,
+  checkResponseCoverage,
+  fixResponseCoverage,
+  scaffoldAdr,
+  listMyClients,
+  createMyClient,
+  deleteMyClient,
   untrustClient,
   validatorOpenAPIPost,
+  validatorOpenAPIStream,
+  validatorOpenAPIStructure,
 };
@@ -7,6 +7,8 @@ const bodyParser = require("body-parser");
 const OpenApiValidator = require("express-openapi-validator");
 const logger = require("./logger");
 const config = require("./config");
+const ToolsController = require("./controllers/ToolsController");
+const { maxRequestBytes } = require("./utils/inputSize");
 
 class ExpressServer {
   static sanitizeOperationId(operationId) {
@@ -116,6 +118,7 @@ class ExpressServer {
       404: "Not Found",
       405: "Method Not Allowed",
       409: "Conflict",
+      413: "Content Too Large",
       422: "Unprocessable Entity",
       429: "Too Many Requests",
       500: "Internal Server Error",
@@ -130,7 +133,7 @@ class ExpressServer {
   setupMiddleware() {
     // this.setupAllowedMedia();
     this.app.use(cors());
-    this.app.use(bodyParser.json({ limit: "14MB" }));
+    this.app.use(bodyParser.json({ limit: maxRequestBytes() }));
     this.app.use(express.json());
     this.app.use(express.urlencoded({ extended: false }));
     this.app.use((_req, res, next) => {
@@ -139,6 +142,8 @@ class ExpressServer {
     });
     const sendOpenApiSpec = (_req, res) => res.json(this.schema);
     this.app.get("/v1/openapi.json", sendOpenApiSpec);
+    // Hosted mocks answer on the paths of the mocked API, which openapi.json cannot describe.
+    this.app.all("/v1/mock/:mockId/*splat", ToolsController.serveHostedMock);
     this.app.use(
       OpenApiValidator.middleware({
         apiSpec: this.schema,
@@ -17,13 +17,20 @@ loadLocalEnvFile();
 const config = require("./config");
 const logger = require("./logger");
 const ExpressServer = require("./expressServer");
+const { getArtifactStore } = require("./services/ArtifactStoreService");
+const { getLintRunStore } = require("./services/LintRunStoreService");
+const { startRulesetRefresh } = require("./services/OasValidatorService");
 
 let expressServer;
 
 const launchServer = async () => {
   try {
+    // Een verkeerd ingestelde lint-run-opslag (geen sleutel, geen node:sqlite) houdt de start tegen.
+    getLintRunStore();
     expressServer = new ExpressServer(config.URL_PORT, config.OPENAPI_JSON);
     expressServer.launch();
+    getArtifactStore().startRetentionSweep();
+    startRulesetRefresh();
     logger.info("Express server running");
   } catch (error) {
     logger.error("Express Server failure", error.message);
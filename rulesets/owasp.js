@@ -0,0 +1,163 @@
+const { createRulesetFunction } = require("@stoplight/spectral-core");
+const { oas3 } = require("@stoplight/spectral-formats");
+const { falsy, pattern, schema, truthy } = require("@stoplight/spectral-functions");
+
+/**
+ * Ingebouwde OWASP API Security Top 10 (2023) ruleset, gebaseerd op de regels van
+ * @stoplight/spectral-owasp-ruleset. Alleen regels die zonder extra afhankelijkheden te
+ * controleren zijn zitten erin; de codes zijn gelijk aan die van het origineel.
+ */
+
+const DOCUMENTATION_URL = "https://owasp.org/API-Security/editions/2023/en/0x11-t10/";
+const WRITE_METHODS = ["post", "put", "patch", "delete"];
+
+const hasSecurity = (requirements) => Array.isArray(requirements) && requirements.length > 0;
+
+const requireWriteSecurity = createRulesetFunction({ input: null, options: null }, (operation, _options, context) => {
+  if (!operation || typeof operation !== "object") {
+    return [];
+  }
+  const root = context.document?.data;
+  const requirements = Array.isArray(operation.security) ? operation.security : root?.security;
+  const anonymousAllowed =
+    hasSecurity(requirements) && requirements.some((item) => item && Object.keys(item).length === 0);
+  if (!hasSecurity(requirements) || anonymousAllowed) {
+    return [{ message: "Schrijvende operaties moeten beveiligd zijn met een security requirement." }];
+  }
+  return [];
+});
+
+const rule = (definition) => ({ documentationUrl: DOCUMENTATION_URL, formats: [oas3], ...definition });
+
+module.exports = {
+  formats: [oas3],
+  rules: {
+    "owasp:api1:2023-no-numeric-ids": rule({
+      description: "Gebruik geen oplopende numerieke ids in paden; ze zijn te raden (BOLA).",
+      message: "Padparameter gebruikt een integer id; kies een niet te raden id zoals een UUID.",
+      severity: "error",
+      given: "$.paths..parameters[?(@ && @.in == 'path')].schema",
+      then: { field: "type", function: pattern, functionOptions: { notMatch: "^integer$" } },
+    }),
+    "owasp:api2:2023-no-http-basic": rule({
+      description: "HTTP Basic authenticatie stuurt wachtwoorden mee met elk verzoek.",
+      message: "Gebruik geen HTTP Basic authenticatie.",
+      severity: "error",
+      given: "$.components.securitySchemes[*]",
+      then: { field: "scheme", function: pattern, functionOptions: { notMatch: "/^basic$/i" } },
+    }),
+    "owasp:api2:2023-auth-insecure-schemes": rule({
+      description: "Negotiate en OAuth 1.0 gelden als verouderde authenticatieschema's.",
+      message: "Gebruik geen verouderd authenticatieschema ({{value}}).",
+      severity: "error",
+      given: "$.components.securitySchemes[?(@ && @.type == 'http')]",
+      then: { field: "scheme", function: pattern, functionOptions: { notMatch: "/^(negotiate|oauth)$/i" } },
+    }),
+    "owasp:api2:2023-no-api-keys-in-url": rule({
+      description: "API keys in de URL komen in logs en browsergeschiedenis terecht.",
+      message: "Stuur API keys niet via de {{value}}; gebruik een header.",
+      severity: "error",
+      given: "$.components.securitySchemes[?(@ && @.type == 'apiKey')]",
+      then: { field: "in", function: pattern, functionOptions: { notMatch: "^(path|query)$" } },
+    }),
+    "owasp:api2:2023-write-restricted": rule({
+      description: "Operaties die data wijzigen horen niet anoniem aanroepbaar te zijn.",
+      severity: "error",
+      given: `$.paths[*][${WRITE_METHODS.join(",")}]`,
+      then: { function: requireWriteSecurity },
+    }),
+    "owasp:api3:2023-no-additionalProperties": rule({
+      description: "Objecten met additionalProperties: true nemen onbekende velden aan (mass assignment).",
+      message: "Sta geen willekeurige extra velden toe met additionalProperties: true.",
+      severity: "warn",
+      given: "$..[?(@ && @.type == 'object' && @.properties)]",
+      then: { field: "additionalProperties", function: falsy },
+    }),
+    "owasp:api4:2023-array-limit": rule({
+      description: "Arrays zonder maxItems maken onbegrensd grote verzoeken mogelijk.",
+      message: "Geef arrays een maxItems.",
+      severity: "error",
+      given: "$..[?(@ && @.type == 'array')]",
+      then: { field: "maxItems", function: truthy },
+    }),
+    "owasp:api4:2023-string-limit": rule({
+      description: "Strings zonder maxLength, enum of const maken onbegrensd grote verzoeken mogelijk.",
+      message: "Geef strings een maxLength, enum of const.",
+      severity: "error",
+      given: "$..[?(@ && @.type == 'string')]",
+      then: {
+        function: schema,
+        functionOptions: {
+          schema: { anyOf: [{ required: ["maxLength"] }, { required: ["enum"] }, { required: ["const"] }] },
+        },
+      },
+    }),
+    "owasp:api4:2023-integer-limit": rule({
+      description: "Integers zonder minimum en maximum kunnen tot overflows en dure queries leiden.",
+      message: "Geef integers een minimum en maximum.",
+      severity: "error",
+      given: "$..[?(@ && @.type == 'integer')]",
+      then: {
+        function: schema,
+        functionOptions: {
+          schema: {
+            anyOf: [
+              { required: ["minimum", "maximum"] },
+              { required: ["exclusiveMinimum", "exclusiveMaximum"] },
+              { required: ["enum"] },
+            ],
+          },
+        },
+      },
+    }),
+    "owasp:api4:2023-rate-limit": rule({
+      description: "Succesvolle responses horen rate limit headers te bevatten.",
+      message: "Voeg RateLimit (of X-RateLimit-Limit) headers toe aan de response.",
+      severity: "warn",
+      given: "$.paths[*][*].responses[?(@property.match(/^2\\d\\d$/))]",
+      then: {
+        field: "headers",
+        function: schema,
+        functionOptions: {
+          schema: {
+            type: "object",
+            anyOf: [
+              { required: ["RateLimit"] },
+              { required: ["RateLimit-Limit", "RateLimit-Reset"] },
+              { required: ["X-RateLimit-Limit"] },
+              { required: ["X-Rate-Limit-Limit"] },
+            ],
+          },
+        },
+      },
+    }),
+    "owasp:api4:2023-rate-limit-responses-429": rule({
+      description: "Operaties horen te documenteren dat ze een 429 Too Many Requests kunnen geven.",
+      message: "Documenteer een 429 response.",
+      severity: "warn",
+      given: "$.paths[*][*].responses",
+      then: { field: "429", function: truthy },
+    }),
+    "owasp:api8:2023-define-error-responses-401": rule({
+      description: "Operaties horen een 401 response te documenteren.",
+      message: "Documenteer een 401 response.",
+      severity: "warn",
+      given: "$.paths[*][*].responses",
+      then: { field: "401", function: truthy },
+    }),
+    "owasp:api8:2023-define-error-responses-500": rule({
+      description: "Operaties horen een 500 response te documenteren.",
+      message: "Documenteer een 500 response.",
+      severity: "warn",
+      given: "$.paths[*][*].responses",
+      then: { field: "500", function: truthy },
+    }),
+    "owasp:api8:2023-no-server-http": rule({
+      description: "Servers horen alleen via HTTPS bereikbaar te zijn.",
+      message: "Server URL {{value}} gebruikt geen HTTPS.",
+      severity: "error",
+      given: "$.servers[*].url",
+      then: { function: pattern, functionOptions: { notMatch: "/^http:/i" } },
+    }),
+  },
+};
@@ -0,0 +1,158 @@
+const { resolveOasDocument, serializeOasDocument } = require("./OasInputService");
+const { ADR_COMPONENTS_URL, applyFixes } = require("./OasResponseCoverageService");
+const { formatPointer } = require("../utils/jsonPointer");
+const { collectOperations } = require("../utils/openapi");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+
+const API_VERSION_HEADER = "API-Version";
+// De foutresponses en headers uit het ADR components-bestand waar deze tools al naar verwijzen.
+const ADR_ERROR_STATUSES = new Set(["400", "401", "403", "404", "500"]);
+const ADR_HEADERS = [API_VERSION_HEADER, "Link"];
+const CONTACT_FIELDS = ["name", "email", "url"];
+const LOCAL_RESPONSE_REF = /^#\/components\/responses\/([^/]+)$/;
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+const isSuccessStatus = (status) => /^2(\d\d|XX)$/i.test(status);
+
+const pointer = (...segments) => formatPointer(segments);
+
+const text = (value) => (typeof value === "string" && value.trim() ? value.trim() : undefined);
+
+/**
+ * Vult `info.contact` aan tot naam, e-mail en URL (regel `info-contact-fields-exist`). Een veld dat
+ * ontbreekt krijgt de waarde uit `contact`, anders een `@TODO`-placeholder die nog ingevuld moet worden.
+ */
+const scaffoldContact = (spec, contact, record) => {
+  if (!isObject(spec.info)) {
+    spec.info = {};
+  }
+  if (!isObject(spec.info.contact)) {
+    spec.info.contact = {};
+  }
+  for (const field of CONTACT_FIELDS) {
+    if (!text(spec.info.contact[field])) {
+      spec.info.contact[field] = text(contact?.[field]) ?? `@TODO: Add contact ${field}`;
+      record(pointer("info", "contact", field), `Contactveld ${field} toegevoegd.`);
+    }
+  }
+};
+
+/**
+ * Zet de `API-Version` header (regel `missing-version-header`) op elke success response. Verwijst de
+ * response naar `#/components/responses/...`, dan krijgt dat component de header, één keer. Een
+ * externe response kan hier niet worden aangepast en blijft zoals hij is.
+ */
+const scaffoldVersionHeaders = (spec, record) => {
+  const visited = new Set();
+  for (const { path, method, operation } of collectOperations(spec)) {
+    const responses = isObject(operation.responses) ? operation.responses : {};
+    for (const [status, response] of Object.entries(responses).filter(([status]) => isSuccessStatus(status))) {
+      let target = response;
+      let at = pointer("paths", path, method, "responses", status);
+      const match = typeof response?.$ref === "string" ? LOCAL_RESPONSE_REF.exec(response.$ref) : null;
+      if (match) {
+        target = spec.components?.responses?.[match[1]];
+        at = response.$ref;
+      } else if (typeof response?.$ref === "string") {
+        continue;
+      }
+      if (!isObject(target) || visited.has(at)) {
+        continue;
+      }
+      visited.add(at);
+      const headers = isObject(target.headers) ? target.headers : {};
+      if (Object.keys(headers).some((name) => name.toLowerCase() === API_VERSION_HEADER.toLowerCase())) {
+        continue;
+      }
+      target.headers = {
+        ...headers,
+        [API_VERSION_HEADER]: { $ref: `${ADR_COMPONENTS_URL}#/headers/${API_VERSION_HEADER}` },
+      };
+      record(`${at}/headers/${API_VERSION_HEADER}`, "API-Version header toegevoegd.");
+    }
+  }
+};
+
+const isInline = (value) => isObject(value) && typeof value.$ref !== "string";
+
+const referenceHeaders = (headers, at, record) => {
+  for (const name of Object.keys(isObject(headers) ? headers : {})) {
+    const shared = ADR_HEADERS.find((header) => header.toLowerCase() === name.toLowerCase());
+    if (shared && isInline(headers[name])) {
+      headers[name] = { $ref: `${ADR_COMPONENTS_URL}#/headers/${shared}` };
+      record(pointer(...at, name), `Header ${name} verwijst nu naar het ADR components-bestand.`);
+    }
+  }
+};
+
+/**
+ * Vervangt inline foutresponses (400, 401, 403, 404 en 500) en inline `API-Version` en `Link`
+ * headers door een `$ref` naar het ADR components-bestand, in de operaties en in `components`. Een
+ * eigen beschrijving of schema van zo'n response vervalt daarmee; wat al een `$ref` is blijft staan.
+ */
+const referenceSharedComponents = (spec, record) => {
+  const responseLists = collectOperations(spec).map(({ path, method, operation }) => ({
+    responses: operation.responses,
+    at: ["paths", path, method, "responses"],
+  }));
+  responseLists.push({ responses: spec.components?.responses, at: ["components", "responses"] });
+  for (const { responses, at } of responseLists.filter(({ responses }) => isObject(responses))) {
+    for (const [status, response] of Object.entries(responses)) {
+      if (ADR_ERROR_STATUSES.has(status) && isInline(response)) {
+        responses[status] = { $ref: `${ADR_COMPONENTS_URL}#/responses/${status}` };
+        record(pointer(...at, status), `Foutresponse ${status} verwijst nu naar het ADR components-bestand.`);
+      } else if (isInline(response)) {
+        referenceHeaders(response.headers, [...at, status, "headers"], record);
+      }
+    }
+  }
+  referenceHeaders(spec.components?.headers, ["components", "headers"], record);
+};
+
+/**
+ * Voegt de onderdelen toe die de ADR-regels verwachten: contactgegevens, de `API-Version` header op
+ * success responses en de standaard foutresponses (400, 401 en 403 bij beveiligde operaties, 500)
+ * uit het ADR components-bestand. Bestaande onderdelen blijven staan, tenzij `referenceAdrComponents`
+ * inline foutresponses en headers door de gedeelde componenten vervangt. `changes` noemt elke
+ * wijziging met een JSON pointer.
+ */
+const scaffoldDocument = (spec, { contact, referenceAdrComponents = false } = {}) => {
+  const changes = [];
+  const record = (path, message) => changes.push({ path, message });
+  if (referenceAdrComponents) {
+    referenceSharedComponents(spec, record);
+  }
+  scaffoldContact(spec, contact, record);
+  scaffoldVersionHeaders(spec, record);
+  for (const { path, method, status } of applyFixes(spec)) {
+    record(pointer("paths", path, method, "responses", status), `Standaard foutresponse ${status} toegevoegd.`);
+  }
+  return { spec, changes };
+};
+
+const scaffold = async (input, { responseFormat } = {}) => {
+  const { spec, format, source } = await resolveOasDocument(input);
+  const { changes } = scaffoldDocument(spec, {
+    contact: input?.contact,
+    referenceAdrComponents: input?.referenceAdrComponents === true,
+  });
+  stampDocument(
+    spec,
+    buildProvenance({
+      tool: "oas-adr-scaffold",
+      source,
+      details: { scaffolded: changes.length > 0 ? changes.map((change) => change.path) : undefined },
+    }),
+  );
+  const result = serializeOasDocument(spec, responseFormat ?? format, "openapi-adr");
+  result.headers["X-Scaffolded"] = String(changes.length);
+  return result;
+};
+
+module.exports = {
+  referenceSharedComponents,
+  scaffold,
+  scaffoldContact,
+  scaffoldDocument,
+};
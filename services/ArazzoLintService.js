@@ -0,0 +1,243 @@
+const Service = require("./Service");
+const ArazzoVisualizationService = require("./ArazzoVisualizationService");
+const { fetchSpecification } = require("./RemoteSpecificationService");
+const { resolveOasInput } = require("./OasInputService");
+const { stripBom } = require("../utils/encoding");
+const { YamlLimitError, parseYaml } = require("../utils/yaml");
+
+const SOURCE_REF_PREFIX = "$sourceDescriptions.";
+const STEP_REFERENCE_PATTERN = /\$steps\.([A-Za-z0-9_-]+)/g;
+
+const RULES = {
+  "arazzo-document": "Het document moet een `arazzo` versie en minstens één workflow bevatten.",
+  "workflow-id-required": "Elke workflow moet een workflowId hebben.",
+  "workflow-id-unique": "workflowIds moeten uniek zijn binnen het document.",
+  "step-id-required": "Elke stap moet een stepId hebben.",
+  "step-id-unique": "stepIds moeten uniek zijn binnen een workflow.",
+  "step-target-required": "Een stap verwijst naar precies één operationId, operationPath of workflowId.",
+  "operation-id-resolvable": "operationId moet voorkomen in een van de sourceDescriptions.",
+  "workflow-reference-resolvable": "workflowId van een stap moet naar een bestaande workflow verwijzen.",
+  "step-reference-resolvable":
+    "Verwijzingen naar $steps.<stepId> en goto-acties moeten naar een bestaande stap wijzen.",
+  "source-description-resolvable": "sourceDescriptions van type openapi moeten op te halen zijn.",
+};
+
+const isNonEmptyString = (value) => typeof value === "string" && value.trim().length > 0;
+
+const resolveArazzoContents = async (input) => {
+  if (isNonEmptyString(input?.arazzoBody)) {
+    return stripBom(input.arazzoBody);
+  }
+  if (isNonEmptyString(input?.arazzoUrl)) {
+    let parsedUrl;
+    try {
+      parsedUrl = new URL(input.arazzoUrl);
+    } catch {
+      throw Service.rejectResponse({ message: "De waarde van arazzoUrl is geen geldige URL." }, 400);
+    }
+    return fetchSpecification(parsedUrl.toString(), {
+      errorMessage: "Het ophalen van de Arazzo specificatie is mislukt.",
+    });
+  }
+  throw Service.rejectResponse({ message: "Geef een arazzoBody of arazzoUrl mee." }, 400);
+};
+
+const parseDocument = (contents, label) => {
+  try {
+    return parseYaml(contents);
+  } catch (error) {
+    if (error instanceof YamlLimitError) {
+      throw Service.rejectResponse({ message: error.message }, 400);
+    }
+    throw Service.rejectResponse(
+      { message: `Kan ${label} specificatie niet parseren.`, detail: error?.message || "Onbekende fout" },
+      400,
+    );
+  }
+};
+
+const loadOpenApiDocument = async (input) => {
+  if (!isNonEmptyString(input?.oasBody) && !isNonEmptyString(input?.oasUrl)) {
+    return undefined;
+  }
+  const { contents } = await resolveOasInput(input);
+  return parseDocument(contents, "OpenAPI");
+};
+
+const collectStepReferences = (value, found = []) => {
+  if (typeof value === "string") {
+    for (const match of value.matchAll(STEP_REFERENCE_PATTERN)) {
+      found.push(match[1]);
+    }
+  } else if (Array.isArray(value)) {
+    value.forEach((item) => collectStepReferences(item, found));
+  } else if (value && typeof value === "object") {
+    Object.values(value).forEach((item) => collectStepReferences(item, found));
+  }
+  return found;
+};
+
+class ArazzoLinter {
+  constructor(document, lookups) {
+    this.document = document;
+    this.lookups = lookups;
+    this.messages = [];
+    this.unresolvedSources = 0;
+  }
+
+  report(code, path, message, severity = "error") {
+    this.messages.push({ code, severity, message: message || RULES[code], path: path.map(String) });
+  }
+
+  lintSources() {
+    const sources = Array.isArray(this.document.sourceDescriptions) ? this.document.sourceDescriptions : [];
+    sources.forEach((source, index) => {
+      if (source?.type && source.type !== "openapi") {
+        return;
+      }
+      if (!this.lookups.has(source?.name || "") && !this.lookups.has("")) {
+        this.unresolvedSources += 1;
+        this.report(
+          "source-description-resolvable",
+          ["sourceDescriptions", index, "url"],
+          `Bron "${source?.name}" kon niet worden opgehaald; operationIds uit deze bron zijn niet gecontroleerd.`,
+          "warning",
+        );
+      }
+    });
+  }
+
+  lintStepTarget(step, path, workflowIds) {
+    const targets = ["operationId", "operationPath", "workflowId"].filter((key) => isNonEmptyString(step[key]));
+    if (targets.length !== 1) {
+      this.report("step-target-required", path);
+      return;
+    }
+    if (targets[0] === "operationId") {
+      const { source } = ArazzoVisualizationService.parseStepOperation(step.operationId);
+      // Zonder bronvermelding kan de operatie ook in een niet opgehaalde bron staan.
+      const sourceLoaded = source
+        ? this.lookups.has(source) || this.lookups.has("")
+        : this.lookups.size > 0 && this.unresolvedSources === 0;
+      if (sourceLoaded && !ArazzoVisualizationService.findStepOperation(step, this.lookups)) {
+        this.report(
+          "operation-id-resolvable",
+          [...path, "operationId"],
+          `operationId "${step.operationId}" komt in geen enkele sourceDescription voor.`,
+        );
+      }
+    }
+    if (targets[0] === "workflowId" && !step.workflowId.startsWith(SOURCE_REF_PREFIX)) {
+      if (!workflowIds.has(step.workflowId)) {
+        this.report(
+          "workflow-reference-resolvable",
+          [...path, "workflowId"],
+          `Workflow "${step.workflowId}" bestaat niet in dit document.`,
+        );
+      }
+    }
+  }
+
+  lintStepReferences(workflow, workflowPath, stepIds) {
+    const references = collectStepReferences({ steps: workflow.steps, outputs: workflow.outputs });
+    for (const reference of new Set(references)) {
+      if (!stepIds.has(reference)) {
+        this.report("step-reference-resolvable", workflowPath, `Verwijzing naar onbekende stap "$steps.${reference}".`);
+      }
+    }
+    (workflow.steps || []).forEach((step, stepIndex) => {
+      ["onSuccess", "onFailure"].forEach((key) => {
+        (Array.isArray(step?.[key]) ? step[key] : []).forEach((action, actionIndex) => {
+          if (action?.type === "goto" && isNonEmptyString(action.stepId) && !stepIds.has(action.stepId)) {
+            this.report(
+              "step-reference-resolvable",
+              [...workflowPath, "steps", stepIndex, key, actionIndex, "stepId"],
+              `goto verwijst naar onbekende stap "${action.stepId}".`,
+            );
+          }
+        });
+      });
+    });
+  }
+
+  lintWorkflow(workflow, index, workflowIds) {
+    const path = ["workflows", index];
+    const steps = Array.isArray(workflow?.steps) ? workflow.steps : [];
+    const stepIds = new Set();
+    steps.forEach((step, stepIndex) => {
+      const stepPath = [...path, "steps", stepIndex];
+      if (!isNonEmptyString(step?.stepId)) {
+        this.report("step-id-required", stepPath);
+      } else if (stepIds.has(step.stepId)) {
+        this.report("step-id-unique", [...stepPath, "stepId"], `stepId "${step.stepId}" komt vaker voor.`);
+      } else {
+        stepIds.add(step.stepId);
+      }
+      if (step && typeof step === "object") {
+        this.lintStepTarget(step, stepPath, workflowIds);
+      }
+    });
+    this.lintStepReferences(workflow || {}, path, stepIds);
+  }
+
+  run() {
+    const { document } = this;
+    if (!document || typeof document !== "object" || !isNonEmptyString(String(document.arazzo ?? ""))) {
+      this.report("arazzo-document", []);
+      return this.messages;
+    }
+    const workflows = Array.isArray(document.workflows) ? document.workflows : [];
+    if (workflows.length === 0) {
+      this.report("arazzo-document", ["workflows"]);
+      return this.messages;
+    }
+    const workflowIds = new Set();
+    workflows.forEach((workflow, index) => {
+      if (!isNonEmptyString(workflow?.workflowId)) {
+        this.report("workflow-id-required", ["workflows", index]);
+      } else if (workflowIds.has(workflow.workflowId)) {
+        this.report(
+          "workflow-id-unique",
+          ["workflows", index, "workflowId"],
+          `workflowId "${workflow.workflowId}" komt vaker voor.`,
+        );
+      } else {
+        workflowIds.add(workflow.workflowId);
+      }
+    });
+    this.lintSources();
+    workflows.forEach((workflow, index) => this.lintWorkflow(workflow, index, workflowIds));
+    return this.messages;
+  }
+}
+
+const lintDocument = (document, lookups = new Map()) => {
+  const messages = new ArazzoLinter(document, lookups).run();
+  const errorCount = messages.filter((message) => message.severity === "error").length;
+  return {
+    valid: errorCount === 0,
+    errorCount,
+    warningCount: messages.length - errorCount,
+    messages,
+  };
+};
+
+/**
+ * Lint een Arazzo document met de DON workflow-regels. operationIds worden gecontroleerd tegen
+ * het meegegeven OpenAPI-document (oasBody/oasUrl) of anders tegen de sourceDescriptions.
+ */
+const lint = async (input) => {
+  const document = parseDocument(await resolveArazzoContents(input), "Arazzo");
+  const openapiDocument = await loadOpenApiDocument(input);
+  const lookups =
+    document && typeof document === "object"
+      ? await ArazzoVisualizationService.loadSourceDescriptions(document, openapiDocument)
+      : new Map();
+  return lintDocument(document, lookups);
+};
+
+module.exports = {
+  RULES,
+  lint,
+  lintDocument,
+};
@@ -0,0 +1,702 @@
+const Service = require("./Service");
+const ArazzoVisualizationService = require("./ArazzoVisualizationService");
+const { sanitizeFileName } = require("../utils/fileName");
+const { buildProvenance, describeProvenance } = require("../utils/provenance");
+
+const SUPPORTED_LANGUAGES = ["jest", "go"];
+const DEFAULT_LANGUAGE = "jest";
+const DEFAULT_FILENAME = "arazzo-workflows";
+const COMPONENT_PARAMETER_PREFIX = "$components.parameters.";
+const COMPONENT_INPUTS_PREFIX = "#/components/inputs/";
+
+const UNSUPPORTED_LANGUAGE_ERROR = `language wordt niet ondersteund. Gebruik ${SUPPORTED_LANGUAGES.join(" of ")}.`;
+
+const normalizeLanguage = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return DEFAULT_LANGUAGE;
+  }
+  const normalized = String(value).trim().toLowerCase();
+  if (normalized === "javascript" || normalized === "js") {
+    return "jest";
+  }
+  if (normalized === "golang") {
+    return "go";
+  }
+  if (!SUPPORTED_LANGUAGES.includes(normalized)) {
+    throw Service.rejectResponse({ message: UNSUPPORTED_LANGUAGE_ERROR }, 400);
+  }
+  return normalized;
+};
+
+// ---------------------------------------------------------------------------
+// OpenAPI bronnen koppelen aan stappen
+// ---------------------------------------------------------------------------
+
+const resolveServerUrl = (lookups) => {
+  for (const { document } of lookups.values()) {
+    const url = Array.isArray(document?.servers) ? document.servers[0]?.url : undefined;
+    if (typeof url === "string" && url) {
+      return url.replace(/\/+$/, "");
+    }
+  }
+  return "http://localhost:8080";
+};
+
+// ---------------------------------------------------------------------------
+// Workflow -> testplan (taalonafhankelijk)
+// ---------------------------------------------------------------------------
+
+const resolveParameter = (parameter, components) => {
+  const reference = typeof parameter?.reference === "string" ? parameter.reference : "";
+  if (reference.startsWith(COMPONENT_PARAMETER_PREFIX)) {
+    const resolved = components?.parameters?.[reference.slice(COMPONENT_PARAMETER_PREFIX.length)];
+    if (resolved) {
+      return { ...resolved, ...(parameter.value !== undefined ? { value: parameter.value } : {}) };
+    }
+  }
+  return parameter;
+};
+
+const mergeParameters = (workflowParameters, stepParameters, components) => {
+  const merged = new Map();
+  for (const parameter of [...(workflowParameters || []), ...(stepParameters || [])]) {
+    const resolved = resolveParameter(parameter, components);
+    if (resolved?.name) {
+      merged.set(`${resolved.in || "query"}:${resolved.name}`, {
+        name: resolved.name,
+        in: resolved.in || "query",
+        value: resolved.value,
+      });
+    }
+  }
+  return Array.from(merged.values());
+};
+
+const collectDefaultInputs = (inputs, components) => {
+  let schema = inputs;
+  if (schema?.$ref && typeof schema.$ref === "string" && schema.$ref.startsWith(COMPONENT_INPUTS_PREFIX)) {
+    schema = components?.inputs?.[schema.$ref.slice(COMPONENT_INPUTS_PREFIX.length)];
+  }
+  const defaults = {};
+  Object.entries(schema?.properties || {}).forEach(([name, property]) => {
+    if (property?.default !== undefined) {
+      defaults[name] = property.default;
+    } else if (property?.example !== undefined) {
+      defaults[name] = property.example;
+    } else if (Array.isArray(property?.examples) && property.examples.length > 0) {
+      [defaults[name]] = property.examples;
+    }
+  });
+  return defaults;
+};
+
+const buildStepPlan = (step, index, workflow, lookups, components) => {
+  const stepId = step.stepId || `step${index + 1}`;
+  const plan = {
+    stepId,
+    description: typeof step.description === "string" ? step.description.trim() : "",
+    successCriteria: Array.isArray(step.successCriteria) ? step.successCriteria : [],
+    outputs: step.outputs && typeof step.outputs === "object" ? step.outputs : {},
+  };
+  if (step.workflowId) {
+    return { ...plan, unsupported: `Stap roept workflow ${step.workflowId} aan; dit wordt niet gegenereerd.` };
+  }
+  const operation = ArazzoVisualizationService.findStepOperation(step, lookups);
+  if (!operation) {
+    const reference = step.operationId || step.operationPath || "(geen operatie)";
+    return { ...plan, unsupported: `Operatie ${reference} niet gevonden in de OpenAPI bron.` };
+  }
+  return {
+    ...plan,
+    method: operation.method,
+    path: operation.path,
+    parameters: mergeParameters(workflow.parameters, step.parameters, components),
+    requestBody: step.requestBody
+      ? { contentType: step.requestBody.contentType || "application/json", payload: step.requestBody.payload }
+      : undefined,
+  };
+};
+
+const buildTestPlan = (arazzoDocument, lookups) => {
+  const components = arazzoDocument.components || {};
+  return {
+    title: arazzoDocument.info?.title || "Arazzo workflows",
+    baseUrl: resolveServerUrl(lookups),
+    workflows: (arazzoDocument.workflows || []).map((workflow, index) => ({
+      workflowId: workflow.workflowId || `workflow${index + 1}`,
+      summary: workflow.summary || "",
+      inputs: collectDefaultInputs(workflow.inputs, components),
+      steps: (workflow.steps || []).map((step, stepIndex) =>
+        buildStepPlan(step, stepIndex, workflow, lookups, components),
+      ),
+    })),
+  };
+};
+
+// ---------------------------------------------------------------------------
+// Jest renderer
+// ---------------------------------------------------------------------------
+
+const JEST_RUNTIME = `const BASE_URL = process.env.API_BASE_URL || DEFAULT_BASE_URL;
+const OVERRIDE_INPUTS = JSON.parse(process.env.ARAZZO_INPUTS || "{}");
+
+const pointer = (value, path) =>
+  path
+    .split("/")
+    .slice(1)
+    .map((segment) => segment.replace(/~1/g, "/").replace(/~0/g, "~"))
+    .reduce((current, key) => (current === undefined || current === null ? undefined : current[key]), value);
+
+const evaluate = (ctx, expression) => {
+  if (typeof expression !== "string") return expression;
+  if (expression.includes("{$")) {
+    return expression.replace(/\\{(\\$[^}]+)\\}/g, (_match, inner) => String(evaluate(ctx, inner)));
+  }
+  if (!expression.startsWith("$")) return expression;
+  const [head, fragment] = expression.split("#");
+  if (head === "$statusCode") return ctx.response?.status;
+  if (head === "$response.body") return fragment ? pointer(ctx.body, fragment) : ctx.body;
+  if (head.startsWith("$response.header.")) return ctx.response?.headers.get(head.slice(17));
+  if (head.startsWith("$inputs.")) return pointer(ctx.inputs, \`/\${head.slice(8).split(".").join("/")}\`);
+  if (head.startsWith("$steps.")) {
+    const [stepId, kind, ...rest] = head.slice(7).split(".");
+    const value = pointer(ctx.steps[stepId]?.[kind], \`/\${rest.join("/")}\`);
+    return fragment ? pointer(value, fragment) : value;
+  }
+  return expression;
+};
+
+const resolveValue = (ctx, value) => {
+  if (Array.isArray(value)) return value.map((item) => resolveValue(ctx, item));
+  if (value && typeof value === "object") {
+    return Object.fromEntries(Object.entries(value).map(([key, item]) => [key, resolveValue(ctx, item)]));
+  }
+  return evaluate(ctx, value);
+};
+
+const parseOperand = (ctx, raw) => {
+  const operand = raw.trim();
+  if (operand.startsWith("$")) return evaluate(ctx, operand);
+  if (/^'.*'$/.test(operand) || /^".*"$/.test(operand)) return operand.slice(1, -1);
+  if (operand === "true" || operand === "false") return operand === "true";
+  if (operand === "null") return null;
+  return Number.isNaN(Number(operand)) ? operand : Number(operand);
+};
+
+const compare = (left, operator, right) => {
+  switch (operator) {
+    case "==":
+      return String(left) === String(right);
+    case "!=":
+      return String(left) !== String(right);
+    case "<":
+      return Number(left) < Number(right);
+    case "<=":
+      return Number(left) <= Number(right);
+    case ">":
+      return Number(left) > Number(right);
+    default:
+      return Number(left) >= Number(right);
+  }
+};
+
+const expectCriterion = (ctx, criterion) => {
+  if (criterion.type === "regex") {
+    expect(String(evaluate(ctx, criterion.context))).toMatch(new RegExp(criterion.condition));
+    return;
+  }
+  if (criterion.type && criterion.type !== "simple") {
+    console.warn(\`Criterium van type \${criterion.type?.type || criterion.type} wordt overgeslagen: \${criterion.condition}\`);
+    return;
+  }
+  for (const part of criterion.condition.split("&&")) {
+    const match = /^(.+?)\\s*(==|!=|<=|>=|<|>)\\s*(.+)$/.exec(part.trim());
+    if (!match) {
+      expect(Boolean(parseOperand(ctx, part))).toBe(true);
+      continue;
+    }
+    const [, left, operator, right] = match;
+    const actual = parseOperand(ctx, left);
+    expect({ condition: part.trim(), result: compare(actual, operator, parseOperand(ctx, right)) }).toEqual({
+      condition: part.trim(),
+      result: true,
+    });
+  }
+};
+
+const send = async (ctx, { method, path, parameters = [], requestBody }) => {
+  let resolvedPath = path;
+  const url = new URL(BASE_URL);
+  const headers = {};
+  for (const parameter of parameters) {
+    const value = resolveValue(ctx, parameter.value);
+    if (parameter.in === "path") {
+      resolvedPath = resolvedPath.replace(\`{\${parameter.name}}\`, encodeURIComponent(String(value)));
+    } else if (parameter.in === "header") {
+      headers[parameter.name] = String(value);
+    } else if (parameter.in === "query") {
+      url.searchParams.append(parameter.name, String(value));
+    }
+  }
+  url.pathname = \`\${url.pathname.replace(/\\/+$/, "")}\${resolvedPath}\`;
+  const init = { method, headers };
+  if (requestBody) {
+    const payload = resolveValue(ctx, requestBody.payload);
+    headers["Content-Type"] = requestBody.contentType;
+    init.body = typeof payload === "string" ? payload : JSON.stringify(payload);
+  }
+  ctx.response = await fetch(url, init);
+  const text = await ctx.response.text();
+  try {
+    ctx.body = text ? JSON.parse(text) : undefined;
+  } catch {
+    ctx.body = text;
+  }
+};
+
+const storeOutputs = (ctx, stepId, outputs) => {
+  ctx.steps[stepId] = { outputs: resolveValue(ctx, outputs) };
+};`;
+
+const provenanceComment = (plan) =>
+  plan.provenance ? [`// ${describeProvenance(plan.provenance).replace(/[\r\n]+/g, " ")}`] : [];
+
+const renderJest = (plan) => {
+  const lines = [
+    `// Gegenereerd door de DON Tools API uit "${plan.title.replace(/"/g, "'")}".`,
+    ...provenanceComment(plan),
+    "// Zet API_BASE_URL om tegen een andere omgeving te testen en ARAZZO_INPUTS (JSON) voor workflow inputs.",
+    "",
+    `const DEFAULT_BASE_URL = ${JSON.stringify(plan.baseUrl)};`,
+    JEST_RUNTIME,
+  ];
+  plan.workflows.forEach((workflow) => {
+    const describeName = workflow.summary ? `${workflow.workflowId}: ${workflow.summary}` : workflow.workflowId;
+    lines.push(
+      "",
+      `describe(${JSON.stringify(describeName)}, () => {`,
+      `  const ctx = { inputs: { ...${JSON.stringify(workflow.inputs)}, ...OVERRIDE_INPUTS }, steps: {} };`,
+    );
+    workflow.steps.forEach((step) => {
+      lines.push("");
+      if (step.description) {
+        lines.push(`  // ${step.description.split("\n")[0]}`);
+      }
+      if (step.unsupported) {
+        lines.push(`  test.todo(${JSON.stringify(`${step.stepId}: ${step.unsupported}`)});`);
+        return;
+      }
+      lines.push(`  test(${JSON.stringify(`${step.stepId} (${step.method} ${step.path})`)}, async () => {`);
+      const request = { method: step.method, path: step.path, parameters: step.parameters };
+      if (step.requestBody) {
+        request.requestBody = step.requestBody;
+      }
+      lines.push(`    await send(ctx, ${JSON.stringify(request)});`);
+      step.successCriteria.forEach((criterion) => {
+        lines.push(`    expectCriterion(ctx, ${JSON.stringify(criterion)});`);
+      });
+      lines.push(`    storeOutputs(ctx, ${JSON.stringify(step.stepId)}, ${JSON.stringify(step.outputs)});`, "  });");
+    });
+    lines.push("});");
+  });
+  return `${lines.join("\n")}\n`;
+};
+
+// ---------------------------------------------------------------------------
+// Go renderer
+// ---------------------------------------------------------------------------
+
+const GO_RUNTIME = `type parameter struct {
+	Name  string \`json:"name"\`
+	In    string \`json:"in"\`
+	Value any    \`json:"value"\`
+}
+
+type requestBody struct {
+	ContentType string \`json:"contentType"\`
+	Payload     any    \`json:"payload"\`
+}
+
+type request struct {
+	Method      string       \`json:"method"\`
+	Path        string       \`json:"path"\`
+	Parameters  []parameter  \`json:"parameters"\`
+	RequestBody *requestBody \`json:"requestBody"\`
+}
+
+type criterion struct {
+	Condition string \`json:"condition"\`
+	Context   string \`json:"context"\`
+	Type      any    \`json:"type"\`
+}
+
+type workflowContext struct {
+	inputs   map[string]any
+	steps    map[string]map[string]any
+	response *http.Response
+	body     any
+}
+
+var (
+	templatePattern  = regexp.MustCompile(\`\\{(\\$[^}]+)\\}\`)
+	conditionPattern = regexp.MustCompile(\`^(.+?)\\s*(==|!=|<=|>=|<|>)\\s*(.+)$\`)
+)
+
+func baseURL() string {
+	if value := os.Getenv("API_BASE_URL"); value != "" {
+		return value
+	}
+	return defaultBaseURL
+}
+
+func decode[T any](t *testing.T, raw string) T {
+	t.Helper()
+	var value T
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		t.Fatalf("ongeldige testdata: %v", err)
+	}
+	return value
+}
+
+func newContext(t *testing.T, defaults string) *workflowContext {
+	inputs := decode[map[string]any](t, defaults)
+	if inputs == nil {
+		inputs = map[string]any{}
+	}
+	if raw := os.Getenv("ARAZZO_INPUTS"); raw != "" {
+		for key, value := range decode[map[string]any](t, raw) {
+			inputs[key] = value
+		}
+	}
+	return &workflowContext{inputs: inputs, steps: map[string]map[string]any{}}
+}
+
+func pointer(value any, path string) any {
+	if path == "" {
+		return value
+	}
+	current := value
+	for _, segment := range strings.Split(path, "/")[1:] {
+		segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case map[string]any:
+			current = node[segment]
+		case []any:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil
+			}
+			current = node[index]
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+func (c *workflowContext) evaluate(expression string) any {
+	if strings.Contains(expression, "{$") {
+		return templatePattern.ReplaceAllStringFunc(expression, func(match string) string {
+			return fmt.Sprint(c.evaluate(match[1 : len(match)-1]))
+		})
+	}
+	if !strings.HasPrefix(expression, "$") {
+		return expression
+	}
+	head, fragment, _ := strings.Cut(expression, "#")
+	switch {
+	case head == "$statusCode":
+		if c.response == nil {
+			return nil
+		}
+		return c.response.StatusCode
+	case head == "$response.body":
+		return pointer(c.body, fragment)
+	case strings.HasPrefix(head, "$response.header."):
+		if c.response == nil {
+			return nil
+		}
+		return c.response.Header.Get(strings.TrimPrefix(head, "$response.header."))
+	case strings.HasPrefix(head, "$inputs."):
+		return pointer(c.inputs, "/"+strings.ReplaceAll(strings.TrimPrefix(head, "$inputs."), ".", "/"))
+	case strings.HasPrefix(head, "$steps."):
+		parts := strings.Split(strings.TrimPrefix(head, "$steps."), ".")
+		if len(parts) < 3 {
+			return nil
+		}
+		value := pointer(c.steps[parts[0]], "/"+strings.Join(parts[1:], "/"))
+		return pointer(value, fragment)
+	}
+	return expression
+}
+
+func (c *workflowContext) resolve(value any) any {
+	switch node := value.(type) {
+	case string:
+		return c.evaluate(node)
+	case []any:
+		resolved := make([]any, len(node))
+		for i, item := range node {
+			resolved[i] = c.resolve(item)
+		}
+		return resolved
+	case map[string]any:
+		resolved := make(map[string]any, len(node))
+		for key, item := range node {
+			resolved[key] = c.resolve(item)
+		}
+		return resolved
+	}
+	return value
+}
+
+func (c *workflowContext) send(t *testing.T, req request) {
+	t.Helper()
+	target, err := url.Parse(baseURL())
+	if err != nil {
+		t.Fatalf("ongeldige API_BASE_URL: %v", err)
+	}
+	path := req.Path
+	query := target.Query()
+	headers := http.Header{}
+	for _, param := range req.Parameters {
+		value := fmt.Sprint(c.resolve(param.Value))
+		switch param.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(value))
+		case "header":
+			headers.Set(param.Name, value)
+		case "query":
+			query.Add(param.Name, value)
+		}
+	}
+	target.Path = strings.TrimRight(target.Path, "/") + path
+	target.RawQuery = query.Encode()
+	var body io.Reader
+	if req.RequestBody != nil {
+		payload := c.resolve(req.RequestBody.Payload)
+		if text, ok := payload.(string); ok {
+			body = strings.NewReader(text)
+		} else {
+			encoded, err := json.Marshal(payload)
+			if err != nil {
+				t.Fatalf("request body niet te serialiseren: %v", err)
+			}
+			body = bytes.NewReader(encoded)
+		}
+		headers.Set("Content-Type", req.RequestBody.ContentType)
+	}
+	httpReq, err := http.NewRequest(req.Method, target.String(), body)
+	if err != nil {
+		t.Fatalf("request niet op te bouwen: %v", err)
+	}
+	httpReq.Header = headers
+	resp, err := http.DefaultClient.Do(httpReq)
+	if err != nil {
+		t.Fatalf("%s %s mislukt: %v", req.Method, target, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("response niet te lezen: %v", err)
+	}
+	c.response = resp
+	c.body = nil
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &c.body); err != nil {
+			c.body = string(raw)
+		}
+	}
+}
+
+func (c *workflowContext) operand(raw string) any {
+	operand := strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(operand, "$"):
+		return c.evaluate(operand)
+	case len(operand) >= 2 && (operand[0] == '\\'' || operand[0] == '"') && operand[len(operand)-1] == operand[0]:
+		return operand[1 : len(operand)-1]
+	}
+	return operand
+}
+
+func compare(left any, operator string, right any) bool {
+	l, r := fmt.Sprint(left), fmt.Sprint(right)
+	lf, lerr := strconv.ParseFloat(l, 64)
+	rf, rerr := strconv.ParseFloat(r, 64)
+	numeric := lerr == nil && rerr == nil
+	switch operator {
+	case "==":
+		return l == r || (numeric && lf == rf)
+	case "!=":
+		return !(l == r || (numeric && lf == rf))
+	case "<":
+		return numeric && lf < rf
+	case "<=":
+		return numeric && lf <= rf
+	case ">":
+		return numeric && lf > rf
+	default:
+		return numeric && lf >= rf
+	}
+}
+
+func (c *workflowContext) expect(t *testing.T, crit criterion) {
+	t.Helper()
+	if kind, ok := crit.Type.(string); ok && kind == "regex" {
+		value := fmt.Sprint(c.evaluate(crit.Context))
+		if !regexp.MustCompile(crit.Condition).MatchString(value) {
+			t.Errorf("%q voldoet niet aan %s", value, crit.Condition)
+		}
+		return
+	}
+	if kind, ok := crit.Type.(string); crit.Type != nil && (!ok || kind != "simple") {
+		t.Logf("criterium van type %v wordt overgeslagen: %s", crit.Type, crit.Condition)
+		return
+	}
+	for _, part := range strings.Split(crit.Condition, "&&") {
+		match := conditionPattern.FindStringSubmatch(strings.TrimSpace(part))
+		if match == nil {
+			t.Errorf("criterium niet te interpreteren: %s", part)
+			continue
+		}
+		left := c.operand(match[1])
+		if !compare(left, match[2], c.operand(match[3])) {
+			t.Errorf("criterium %q niet gehaald (waarde: %v)", strings.TrimSpace(part), left)
+		}
+	}
+}
+
+func (c *workflowContext) store(t *testing.T, stepID string, outputs string) {
+	resolved, _ := c.resolve(decode[map[string]any](t, outputs)).(map[string]any)
+	c.steps[stepID] = map[string]any{"outputs": resolved}
+}`;
+
+const goString = (value) => JSON.stringify(value);
+const goJson = (value) => goString(JSON.stringify(value ?? null));
+
+const toGoIdentifier = (value) => {
+  const parts = String(value)
+    .split(/[^A-Za-z0-9]+/)
+    .filter(Boolean)
+    .map((part) => part.charAt(0).toUpperCase() + part.slice(1));
+  const identifier = parts.join("") || "Workflow";
+  return /^[0-9]/.test(identifier) ? `W${identifier}` : identifier;
+};
+
+const renderGo = (plan) => {
+  const lines = [
+    `// Code gegenereerd door de DON Tools API uit ${goString(plan.title)}. DO NOT EDIT.`,
+    ...provenanceComment(plan),
+    "// Zet API_BASE_URL om tegen een andere omgeving te testen en ARAZZO_INPUTS (JSON) voor workflow inputs.",
+    "",
+    "package arazzo_test",
+    "",
+    "import (",
+    '\t"bytes"',
+    '\t"encoding/json"',
+    '\t"fmt"',
+    '\t"io"',
+    '\t"net/http"',
+    '\t"net/url"',
+    '\t"os"',
+    '\t"regexp"',
+    '\t"strconv"',
+    '\t"strings"',
+    '\t"testing"',
+    ")",
+    "",
+    `const defaultBaseURL = ${goString(plan.baseUrl)}`,
+    "",
+    GO_RUNTIME,
+  ];
+  const usedNames = new Set();
+  plan.workflows.forEach((workflow) => {
+    let name = `Test${toGoIdentifier(workflow.workflowId)}`;
+    while (usedNames.has(name)) {
+      name = `${name}_`;
+    }
+    usedNames.add(name);
+    lines.push("");
+    if (workflow.summary) {
+      lines.push(`// ${name} voert workflow ${workflow.workflowId} uit: ${workflow.summary.split("\n")[0]}`);
+    }
+    lines.push(`func ${name}(t *testing.T) {`, `\tctx := newContext(t, ${goJson(workflow.inputs)})`);
+    workflow.steps.forEach((step) => {
+      lines.push("");
+      if (step.description) {
+        lines.push(`\t// ${step.description.split("\n")[0]}`);
+      }
+      if (step.unsupported) {
+        lines.push(
+          `\tt.Run(${goString(step.stepId)}, func(t *testing.T) {`,
+          `\t\tt.Skip(${goString(step.unsupported)})`,
+          "\t})",
+        );
+        return;
+      }
+      const request = { method: step.method, path: step.path, parameters: step.parameters };
+      if (step.requestBody) {
+        request.requestBody = step.requestBody;
+      }
+      lines.push(`\tif !t.Run(${goString(step.stepId)}, func(t *testing.T) {`);
+      lines.push(`\t\tctx.send(t, decode[request](t, ${goJson(request)}))`);
+      step.successCriteria.forEach((criterion) => {
+        lines.push(`\t\tctx.expect(t, decode[criterion](t, ${goJson(criterion)}))`);
+      });
+      lines.push(
+        `\t\tctx.store(t, ${goString(step.stepId)}, ${goJson(step.outputs)})`,
+        "\t}) {",
+        "\t\tt.FailNow()",
+        "\t}",
+      );
+    });
+    lines.push("}");
+  });
+  return `${lines.join("\n")}\n`;
+};
+
+// ---------------------------------------------------------------------------
+// Publieke API
+// ---------------------------------------------------------------------------
+
+const RENDERERS = {
+  jest: {
+    render: renderJest,
+    filename: (base) => `${base}.test.js`,
+    contentType: "text/javascript; charset=utf-8",
+  },
+  go: {
+    render: renderGo,
+    filename: (base) => `${base.replace(/-/g, "_")}_test.go`,
+    contentType: "text/x-go; charset=utf-8",
+  },
+};
+
+const generate = async (input) => {
+  const language = normalizeLanguage(input?.language);
+  const { arazzoDocument, openapiDocument } = await ArazzoVisualizationService.convertInputToArazzo(input);
+  const lookups = await ArazzoVisualizationService.loadSourceDescriptions(arazzoDocument, openapiDocument);
+  const plan = buildTestPlan(arazzoDocument, lookups);
+  plan.provenance = buildProvenance({
+    tool: "arazzo-tests",
+    source: input?.arazzoUrl || input?.oasUrl,
+    details: { language },
+  });
+  const renderer = RENDERERS[language];
+  const filenameBase = sanitizeFileName(plan.title, { fallback: DEFAULT_FILENAME, lowercase: true });
+  return {
+    headers: {
+      "Content-Type": renderer.contentType,
+      "Content-Disposition": `attachment; filename="${renderer.filename(filenameBase)}"`,
+    },
+    rawBody: Buffer.from(renderer.render(plan), "utf8"),
+  };
+};
+
+module.exports = {
+  buildTestPlan,
+  generate,
+  renderGo,
+  renderJest,
+};
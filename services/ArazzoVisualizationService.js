@@ -3,7 +3,6 @@
 const fs = require("node:fs/promises");
 const path = require("node:path");
 const os = require("node:os");
-const jsYaml = require("js-yaml");
 const {
   logger: redoclyLogger,
   createConfig,
@@ -15,6 +14,8 @@ const {
 const Service = require("./Service");
 const { fetchSpecification } = require("./RemoteSpecificationService");
 const { resolveOasInput } = require("./OasInputService");
+const { stripBom } = require("../utils/encoding");
+const { YamlLimitError, parseYaml } = require("../utils/yaml");
 const appLogger = require("../logger");
 
 // ---------------------------------------------------------------------------
@@ -167,9 +168,12 @@ const ensureTempFile = async (contents, filename = "input.yaml") => {
 
 const parseYamlOrUndefined = (contents) => {
   try {
-    const parsed = jsYaml.load(contents);
+    const parsed = parseYaml(contents);
     return parsed && typeof parsed === "object" && !Array.isArray(parsed) ? parsed : undefined;
-  } catch {
+  } catch (error) {
+    if (error instanceof YamlLimitError) {
+      throw Service.rejectResponse({ message: error.message }, 400);
+    }
     return undefined;
   }
 };
@@ -196,7 +200,7 @@ const resolveVisualizationInput = async (input) => {
   if (typeof arazzoBody === "string" && arazzoBody.trim().length > 0) {
     return {
       source: "request-body",
-      contents: arazzoBody,
+      contents: stripBom(arazzoBody),
     };
   }
 
@@ -484,6 +488,73 @@ const describeStepOperation = (step, operationLookup) => {
   return { parsedOperation, operationDetails, suffix };
 };
 
+/**
+ * Laadt de OpenAPI bronnen van een Arazzo-document (of het meegegeven OpenAPI-document) en
+ * bouwt per bron een operation lookup. Bronnen die niet op te halen zijn worden overgeslagen.
+ */
+const loadSourceDescriptions = async (arazzoDocument, openapiDocument) => {
+  const lookups = new Map();
+  if (openapiDocument) {
+    lookups.set("", {
+      document: openapiDocument,
+      operations: buildOperationLookup(openapiDocument),
+    });
+    return lookups;
+  }
+  const sources = Array.isArray(arazzoDocument.sourceDescriptions) ? arazzoDocument.sourceDescriptions : [];
+  for (const source of sources) {
+    if (source?.type && source.type !== "openapi") {
+      continue;
+    }
+    let url;
+    try {
+      url = new URL(source.url);
+    } catch {
+      continue;
+    }
+    if (url.protocol !== "https:" && url.protocol !== "http:") {
+      continue;
+    }
+    try {
+      const contents = await fetchSpecification(url.toString());
+      const document = parseYaml(contents);
+      lookups.set(source.name || "", {
+        document,
+        operations: buildOperationLookup(document),
+      });
+    } catch (error) {
+      appLogger.warn(`[ArazzoService] bron ${source.name} (${url}) niet geladen: ${error?.message}`);
+    }
+  }
+  return lookups;
+};
+
+const findStepOperation = (step, lookups) => {
+  if (typeof step.operationId === "string" && step.operationId) {
+    const { source, operationId } = parseStepOperation(step.operationId);
+    const candidates = source && lookups.has(source) ? [lookups.get(source)] : Array.from(lookups.values());
+    for (const candidate of candidates) {
+      const operation = candidate.operations.get(operationId);
+      if (operation) {
+        return { method: operation.method, path: operation.path };
+      }
+    }
+    return undefined;
+  }
+  if (typeof step.operationPath === "string") {
+    // {$sourceDescriptions.name.url}#/paths/~1pets~1{petId}/get
+    const pointer = step.operationPath.split("#")[1] || "";
+    const segments = pointer
+      .split("/")
+      .slice(1)
+      .map((segment) => decodeURIComponent(segment).replace(/~1/g, "/").replace(/~0/g, "~"));
+    if (segments.length === 3 && segments[0] === "paths") {
+      return { method: segments[2].toUpperCase(), path: segments[1] };
+    }
+  }
+  return undefined;
+};
+
 // ---------------------------------------------------------------------------
 // Markdown output
 // ---------------------------------------------------------------------------
@@ -742,6 +813,10 @@ const visualize = async (input) => {
 
 module.exports = {
   visualize,
+  buildOperationLookup,
+  findStepOperation,
+  loadSourceDescriptions,
+  parseStepOperation,
   convertInputToArazzo,
   convertOasInputToArazzo,
   buildMarkdownFromArazzo,
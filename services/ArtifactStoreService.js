@@ -0,0 +1,210 @@
+const crypto = require("node:crypto");
+const fs = require("node:fs/promises");
+const path = require("node:path");
+const config = require("../config");
+const { KEY_LENGTH, decrypt, encrypt, parseEncryptionKey } = require("../utils/encryption");
+const { loadHttpCacheConfig } = require("../utils/httpCache");
+const logger = require("../logger");
+
+const DEFAULT_RETENTION_DAYS = 30;
+const DEFAULT_SWEEP_INTERVAL_MS = 60 * 60 * 1000;
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+const FILE_EXTENSION = ".bin";
+const SAFE_SEGMENT = /^[A-Za-z0-9._-]+$/;
+
+const parsePositiveNumber = (value, fallback) => {
+  const parsed = Number(value);
+  if (Number.isFinite(parsed) && parsed > 0) {
+    return parsed;
+  }
+  return fallback;
+};
+
+const assertSafeSegment = (value, label) => {
+  if (typeof value !== "string" || !SAFE_SEGMENT.test(value) || value === "." || value === "..") {
+    throw new Error(`Ongeldige ${label} voor artifact opslag: ${value}`);
+  }
+  return value;
+};
+
+const removeExpiredFiles = async (directory, cutoff) => {
+  let entries;
+  try {
+    entries = await fs.readdir(directory, { withFileTypes: true });
+  } catch (error) {
+    if (error.code === "ENOENT") {
+      return 0;
+    }
+    throw error;
+  }
+  let removed = 0;
+  for (const entry of entries) {
+    const entryPath = path.join(directory, entry.name);
+    if (entry.isDirectory()) {
+      removed += await removeExpiredFiles(entryPath, cutoff);
+      continue;
+    }
+    try {
+      const stats = await fs.stat(entryPath);
+      if (stats.mtimeMs < cutoff) {
+        await fs.rm(entryPath, { force: true });
+        removed += 1;
+      }
+    } catch (error) {
+      logger.warn(`[ArtifactStoreService] kan ${entryPath} niet opruimen: ${error.message}`);
+    }
+  }
+  return removed;
+};
+
+/**
+ * Opslag voor resultaten en artifacts die langer moeten bestaan dan één request. Alles wordt
+ * versleuteld weggeschreven (AES-256-GCM) en na de bewaartermijn automatisch verwijderd, zodat
+ * specificaties van interne systemen niet onbeperkt op DON-infrastructuur blijven staan.
+ */
+class ArtifactStore {
+  constructor({
+    directory = config.ARTIFACT_DIR,
+    retentionDays = DEFAULT_RETENTION_DAYS,
+    encryptionKey,
+    sweepIntervalMs = DEFAULT_SWEEP_INTERVAL_MS,
+    extraRetentionDirectories = [],
+  } = {}) {
+    this.directory = directory;
+    this.retentionDays = parsePositiveNumber(retentionDays, DEFAULT_RETENTION_DAYS);
+    this.sweepIntervalMs = parsePositiveNumber(sweepIntervalMs, DEFAULT_SWEEP_INTERVAL_MS);
+    this.extraRetentionDirectories = extraRetentionDirectories.filter(Boolean);
+    if (encryptionKey) {
+      this.key = encryptionKey;
+      this.ephemeralKey = false;
+    } else {
+      this.key = crypto.randomBytes(KEY_LENGTH);
+      this.ephemeralKey = true;
+    }
+    this.sweepTimer = undefined;
+  }
+
+  static fromEnv() {
+    const store = new ArtifactStore({
+      directory: process.env.ARTIFACT_DIR || config.ARTIFACT_DIR,
+      retentionDays: process.env.ARTIFACT_RETENTION_DAYS,
+      encryptionKey: parseEncryptionKey(process.env.ARTIFACT_ENCRYPTION_KEY),
+      sweepIntervalMs: process.env.ARTIFACT_SWEEP_INTERVAL_MS,
+      extraRetentionDirectories: [config.FILE_UPLOAD_PATH, loadHttpCacheConfig().directory],
+    });
+    if (store.ephemeralKey) {
+      logger.warn(
+        "[ArtifactStoreService] ARTIFACT_ENCRYPTION_KEY ontbreekt; opgeslagen artifacts zijn na een herstart onleesbaar.",
+      );
+    }
+    return store;
+  }
+
+  get retentionMs() {
+    return this.retentionDays * DAY_MS;
+  }
+
+  resolvePath(namespace, id) {
+    const safeNamespace = assertSafeSegment(namespace, "namespace");
+    const safeId = assertSafeSegment(id, "id");
+    return path.join(this.directory, safeNamespace, `${safeId}${FILE_EXTENSION}`);
+  }
+
+  async save(namespace, id, contents) {
+    const filePath = this.resolvePath(namespace, id);
+    const plaintext = Buffer.isBuffer(contents) ? contents : Buffer.from(String(contents), "utf8");
+    await fs.mkdir(path.dirname(filePath), { recursive: true, mode: 0o700 });
+    const tempPath = `${filePath}.${process.pid}.${Date.now()}.tmp`;
+    await fs.writeFile(tempPath, encrypt(this.key, plaintext), { mode: 0o600 });
+    await fs.rename(tempPath, filePath);
+    return filePath;
+  }
+
+  async saveJson(namespace, id, value) {
+    return this.save(namespace, id, JSON.stringify(value));
+  }
+
+  /**
+   * Geeft undefined terug als het artifact niet (meer) bestaat of de bewaartermijn is verstreken.
+   */
+  async load(namespace, id) {
+    const filePath = this.resolvePath(namespace, id);
+    let stats;
+    try {
+      stats = await fs.stat(filePath);
+    } catch (error) {
+      if (error.code === "ENOENT") {
+        return undefined;
+      }
+      throw error;
+    }
+    if (Date.now() - stats.mtimeMs > this.retentionMs) {
+      await fs.rm(filePath, { force: true });
+      return undefined;
+    }
+    const payload = await fs.readFile(filePath);
+    return decrypt(this.key, payload);
+  }
+
+  async loadJson(namespace, id) {
+    const contents = await this.load(namespace, id);
+    if (contents === undefined) {
+      return undefined;
+    }
+    return JSON.parse(contents.toString("utf8"));
+  }
+
+  async remove(namespace, id) {
+    await fs.rm(this.resolvePath(namespace, id), { force: true });
+  }
+
+  async purgeExpired(now = Date.now()) {
+    const cutoff = now - this.retentionMs;
+    let removed = 0;
+    for (const directory of [this.directory, ...this.extraRetentionDirectories]) {
+      removed += await removeExpiredFiles(directory, cutoff);
+    }
+    if (removed > 0) {
+      logger.info(`[ArtifactStoreService] ${removed} verlopen artifact(s) verwijderd`);
+    }
+    return removed;
+  }
+
+  startRetentionSweep() {
+    if (this.sweepTimer) {
+      return;
+    }
+    const sweep = () =>
+      this.purgeExpired().catch((error) => {
+        logger.error(`[ArtifactStoreService] opruimen mislukt: ${error.message}`);
+      });
+    sweep();
+    this.sweepTimer = setInterval(sweep, this.sweepIntervalMs);
+    this.sweepTimer.unref?.();
+  }
+
+  stopRetentionSweep() {
+    if (this.sweepTimer) {
+      clearInterval(this.sweepTimer);
+      this.sweepTimer = undefined;
+    }
+  }
+}
+
+let defaultStore;
+
+const getArtifactStore = () => {
+  if (!defaultStore) {
+    defaultStore = ArtifactStore.fromEnv();
+  }
+  return defaultStore;
+};
+
+module.exports = {
+  ArtifactStore,
+  decrypt,
+  encrypt,
+  getArtifactStore,
+  parseEncryptionKey,
+};
@@ -0,0 +1,297 @@
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { sanitizeFileName, uniqueFileName } = require("../utils/fileName");
+const {
+  buildJsonRequestSample,
+  collectOperations,
+  expandServerUrl,
+  operationName,
+  parameterExample,
+  resolveAuthScheme,
+  resolveClientCredentials,
+} = require("../utils/openapi");
+const { PROVENANCE_EXTENSION, buildProvenance } = require("../utils/provenance");
+const { createTempZipStream } = require("../utils/tempFiles");
+
+const DEFAULT_COLLECTION_NAME = "bruno-collection";
+const DEFAULT_ENVIRONMENT_NAME = "default";
+const DEFAULT_BASE_URL = "http://localhost";
+const INDENT = "  ";
+const TEMP_PREFIX = "bruno-export-";
+
+const indent = (text, depth = 1) =>
+  text
+    .split("\n")
+    .map((line) => (line.length > 0 ? INDENT.repeat(depth) + line : line))
+    .join("\n");
+
+const block = (name, lines) => `${name} {\n${lines.map((line) => indent(line)).join("\n")}\n}\n`;
+
+const textBlock = (name, text) => `${name} {\n${indent(text)}\n}\n`;
+
+// Waarden in een .bru dictionary staan op één regel; regeleinden zouden het bestand breken.
+const bruValue = (value) => (value === undefined || value === null ? "" : String(value).replace(/\r?\n/g, " "));
+
+const pair = (key, value, enabled = true) => `${enabled ? "" : "~"}${key}: ${bruValue(value)}`;
+
+/**
+ * Bruno kent bearer en basic als auth-blok; een API-key in een header wordt een header met
+ * variabele. Requests met OAuth2 client credentials erven de OAuth2-configuratie van de collectie
+ * (`collection.bru`); andere OAuth2-flows en OpenID Connect gebruiken een bearer `token`.
+ */
+const resolveAuth = (document, operation) => {
+  const scheme = resolveAuthScheme(document, operation);
+  if (scheme?.type === "apiKey") {
+    return { mode: "none", header: scheme.header };
+  }
+  const clientCredentials = scheme?.definition?.type === "oauth2" && scheme.definition.flows?.clientCredentials;
+  if (clientCredentials) {
+    return { mode: "inherit", oauth2: true };
+  }
+  return { mode: scheme?.type || "none" };
+};
+
+const toBruPath = (path) => path.replace(/\{([^}]+)\}/g, ":$1");
+
+const requestName = (entry) => bruValue(operationName(entry));
+
+const renderRequest = (document, entry, seq, { synthesizeExamples }) => {
+  const { path, method, operation, parameters, requestBody } = entry;
+  const auth = resolveAuth(document, operation);
+  const sample = buildJsonRequestSample(document, requestBody, { synthesize: synthesizeExamples });
+  const byLocation = (location) => parameters.filter((parameter) => parameter.in === location);
+
+  const sections = [
+    block("meta", [pair("name", requestName(entry)), pair("type", "http"), pair("seq", seq)]),
+    block(method, [
+      pair("url", `{{baseUrl}}${toBruPath(path)}`),
+      pair("body", sample === undefined ? "none" : "json"),
+      pair("auth", auth.mode),
+    ]),
+  ];
+  if (operation.description) {
+    sections.push(textBlock("docs", String(operation.description).trim()));
+  }
+  const query = byLocation("query");
+  if (query.length > 0) {
+    // Optionele queryparameters staan uitgeschakeld (`~`) zodat ze niet standaard worden meegestuurd.
+    sections.push(
+      block(
+        "params:query",
+        query.map((parameter) =>
+          pair(parameter.name, parameterExample(document, parameter), parameter.required === true),
+        ),
+      ),
+    );
+  }
+  const pathParameters = byLocation("path");
+  if (pathParameters.length > 0) {
+    sections.push(
+      block(
+        "params:path",
+        pathParameters.map((parameter) => pair(parameter.name, parameterExample(document, parameter))),
+      ),
+    );
+  }
+  const headers = byLocation("header").map((parameter) =>
+    pair(parameter.name, parameterExample(document, parameter), parameter.required === true),
+  );
+  if (auth.header) {
+    headers.push(pair(auth.header, "{{apiKey}}"));
+  }
+  if (headers.length > 0) {
+    sections.push(block("headers", headers));
+  }
+  if (auth.mode === "bearer") {
+    sections.push(block("auth:bearer", [pair("token", "{{token}}")]));
+  }
+  if (auth.mode === "basic") {
+    sections.push(block("auth:basic", [pair("username", "{{username}}"), pair("password", "{{password}}")]));
+  }
+  if (sample !== undefined) {
+    sections.push(textBlock("body:json", JSON.stringify(sample, null, 2)));
+  }
+  return sections.join("\n");
+};
+
+/**
+ * Variabelen voor de environments, afgeleid van de authenticatie van de requests. Geheimen (tokens,
+ * wachtwoorden, API-keys, client secrets) staan in `vars:secret`, zodat Bruno ze niet in de
+ * collectie opslaat; `tokenUrl` en `scope` krijgen de waarden uit de OAuth2 client credentials flow.
+ */
+const collectEnvironmentVariables = (document, operations, clientCredentials) => {
+  const variables = new Map();
+  const add = (name, { value = "", secret = false } = {}) => {
+    if (!variables.has(name)) {
+      variables.set(name, { name, value, secret });
+    }
+  };
+  for (const { operation } of operations) {
+    const auth = resolveAuth(document, operation);
+    if (auth.mode === "bearer") {
+      add("token", { secret: true });
+    }
+    if (auth.mode === "basic") {
+      add("username");
+      add("password", { secret: true });
+    }
+    if (auth.oauth2 && clientCredentials) {
+      add("tokenUrl", { value: clientCredentials.tokenUrl });
+      add("scope", { value: clientCredentials.scopes.join(" ") });
+      add("clientId");
+      add("clientSecret", { secret: true });
+    }
+    if (auth.header) {
+      add("apiKey", { secret: true });
+    }
+  }
+  return [...variables.values()];
+};
+
+/**
+ * `collection.bru` met de OAuth2 client credentials configuratie die de requests erven; de consument
+ * hoeft alleen `clientId` en `clientSecret` in te vullen.
+ */
+const renderCollectionAuth = () =>
+  [
+    block("auth", [pair("mode", "oauth2")]),
+    block("auth:oauth2", [
+      pair("grant_type", "client_credentials"),
+      pair("access_token_url", "{{tokenUrl}}"),
+      pair("client_id", "{{clientId}}"),
+      pair("client_secret", "{{clientSecret}}"),
+      pair("scope", "{{scope}}"),
+    ]),
+  ].join("\n");
+
+const renderEnvironment = (server, variables) => {
+  const sections = [
+    block("vars", [
+      pair("baseUrl", expandServerUrl(server) || DEFAULT_BASE_URL),
+      ...variables.filter((variable) => !variable.secret).map((variable) => pair(variable.name, variable.value)),
+    ]),
+  ];
+  const secrets = variables.filter((variable) => variable.secret).map((variable) => variable.name);
+  if (secrets.length > 0) {
+    sections.push(`vars:secret [\n${secrets.map((name) => indent(name)).join(",\n")}\n]\n`);
+  }
+  return sections.join("\n");
+};
+
+/**
+ * Zet een OpenAPI document om naar een Bruno collectie: `bruno.json`, een `.bru` bestand per operatie
+ * in een map per (eerste) tag en in `environments/` een environment per server met `baseUrl` en
+ * placeholders voor API-keys, tokens en OAuth2 client credentials. De vertaling gebeurt in het
+ * proces; er is geen Bruno CLI of npx nodig.
+ */
+const buildCollection = (document, { source, synthesizeExamples = true } = {}) => {
+  const title = typeof document.info?.title === "string" && document.info.title.trim();
+  const collectionName = title || DEFAULT_COLLECTION_NAME;
+  const operations = collectOperations(document);
+  const files = [];
+
+  files.push({
+    name: "bruno.json",
+    data: JSON.stringify(
+      {
+        version: "1",
+        name: collectionName,
+        type: "collection",
+        ignore: ["node_modules", ".git"],
+        [PROVENANCE_EXTENSION]: buildProvenance({ tool: "oas-bruno", source }),
+      },
+      null,
+      2,
+    ),
+  });
+
+  const clientCredentials = resolveClientCredentials(document);
+  if (clientCredentials) {
+    files.push({ name: "collection.bru", data: renderCollectionAuth() });
+  }
+
+  const variables = collectEnvironmentVariables(document, operations, clientCredentials);
+  const servers = Array.isArray(document.servers) && document.servers.length > 0 ? document.servers : [{}];
+  const environmentNames = new Set();
+  for (const server of servers) {
+    const environmentName = uniqueFileName(
+      environmentNames,
+      sanitizeFileName(server.description || "", { fallback: DEFAULT_ENVIRONMENT_NAME }),
+    );
+    files.push({ name: `environments/${environmentName}.bru`, data: renderEnvironment(server, variables) });
+  }
+
+  const folders = new Map();
+  const folderNames = new Set();
+  for (const entry of operations) {
+    const tag = Array.isArray(entry.operation.tags) && entry.operation.tags[0];
+    const key = tag || "";
+    if (!folders.has(key)) {
+      const directory = tag ? `${uniqueFileName(folderNames, sanitizeFileName(tag, { fallback: "folder" }))}/` : "";
+      folders.set(key, { directory, seq: 0, fileNames: new Set() });
+      if (tag) {
+        files.push({ name: `${directory}folder.bru`, data: block("meta", [pair("name", tag)]) });
+      }
+    }
+    const folder = folders.get(key);
+    folder.seq += 1;
+    const fileName = uniqueFileName(folder.fileNames, sanitizeFileName(requestName(entry), { fallback: "request" }));
+    files.push({
+      name: `${folder.directory}${fileName}.bru`,
+      data: renderRequest(document, entry, folder.seq, { synthesizeExamples }),
+    });
+  }
+
+  return { collectionName, files };
+};
+
+const convert = async (input) => {
+  const resolved = await resolveOasDocument(input);
+  let collection;
+  try {
+    collection = buildCollection(resolved.spec, {
+      source: resolved.source,
+      synthesizeExamples: input.synthesizeExamples !== false,
+    });
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Conversie naar Bruno is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const filenameBase = sanitizeFileName(collection.collectionName, {
+    fallback: DEFAULT_COLLECTION_NAME,
+    lowercase: true,
+  });
+  let rawBody;
+  try {
+    rawBody = await createTempZipStream(
+      TEMP_PREFIX,
+      collection.files.map((file) => ({ ...file, name: `${filenameBase}/${file.name}` })),
+    );
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Het samenstellen van de Bruno-export is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+
+  return {
+    headers: {
+      "Content-Type": "application/zip",
+      "Content-Disposition": `attachment; filename="${filenameBase}.zip"`,
+    },
+    rawBody,
+  };
+};
+
+module.exports = {
+  buildCollection,
+  convert,
+};
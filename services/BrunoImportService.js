@@ -0,0 +1,290 @@
+const Service = require("./Service");
+const { sanitizeFileName } = require("../utils/fileName");
+const { HTTP_METHODS } = require("../utils/openapi");
+const { buildOasSkeleton, splitRequestUrl, substituteVariables } = require("../utils/oasSkeleton");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+const { readZip } = require("../utils/zip");
+
+const DEFAULT_TITLE = "bruno-collection";
+const MISSING_ARCHIVE_ERROR = "Geef de Bruno collectie mee als base64-gecodeerde ZIP in archive.";
+const INVALID_COLLECTION_ERROR = "Geen geldige Bruno collectie: de ZIP bevat geen .bru requests.";
+const BASE64_PATTERN = /^[A-Za-z0-9+/]+={0,2}$/;
+
+const BODY_TYPES = {
+  json: "application/json",
+  text: "text/plain",
+  xml: "application/xml",
+  "form-urlencoded": "application/x-www-form-urlencoded",
+  "multipart-form": "multipart/form-data",
+};
+
+const dedent = (lines) => lines.map((line) => line.replace(/^ {2}/, "")).join("\n");
+
+const parseDictionary = (text) =>
+  text
+    .split("\n")
+    .map((line) => line.trim())
+    .filter((line) => line !== "")
+    .map((line) => {
+      const enabled = !line.startsWith("~");
+      const entry = enabled ? line : line.slice(1);
+      const separator = entry.indexOf(":");
+      return separator < 0
+        ? { key: entry, value: "", enabled }
+        : { key: entry.slice(0, separator).trim(), value: entry.slice(separator + 1).trim(), enabled };
+    });
+
+/**
+ * Leest een `.bru` bestand als blokken: `naam { ... }` met de inhoud zonder inspringing, en lijsten
+ * als `vars:secret [ ... ]`. Dictionary-blokken worden met `dictionary(naam)` gelezen; `~` aan het
+ * begin van een regel betekent uitgeschakeld.
+ */
+const parseBru = (text) => {
+  const blocks = new Map();
+  const lines = String(text).replace(/\r\n/g, "\n").split("\n");
+  for (let index = 0; index < lines.length; index += 1) {
+    const start = /^([A-Za-z0-9:_-]+)\s*([{[])\s*$/.exec(lines[index]);
+    if (!start) {
+      continue;
+    }
+    const close = start[2] === "{" ? "}" : "]";
+    const content = [];
+    for (index += 1; index < lines.length && lines[index].trimEnd() !== close; index += 1) {
+      content.push(lines[index]);
+    }
+    blocks.set(start[1], dedent(content));
+  }
+  return {
+    has: (name) => blocks.has(name),
+    text: (name) => blocks.get(name),
+    dictionary: (name) => (blocks.has(name) ? parseDictionary(blocks.get(name)) : []),
+    value: (name, key) => parseDictionary(blocks.get(name) || "").find((entry) => entry.key === key)?.value,
+  };
+};
+
+// Uitgeschakelde (`~`) query- en headerparameters zijn optioneel en worden ook parameters.
+const toPairs = (entries) => entries.filter((entry) => entry.key).map(({ key, value }) => ({ name: key, value }));
+
+const parseJson = (text) => {
+  try {
+    return JSON.parse(text);
+  } catch {
+    return undefined;
+  }
+};
+
+const resolveRequestBody = (bru, mode) => {
+  const contentType = BODY_TYPES[mode];
+  if (!contentType) {
+    return undefined;
+  }
+  if (mode === "form-urlencoded" || mode === "multipart-form") {
+    const fields = bru
+      .dictionary(`body:${mode}`)
+      .filter((entry) => entry.enabled && entry.key)
+      .map(({ key, value }) => ({ name: key, value, file: /^@file\(/.test(value) }));
+    return fields.length > 0 ? { contentType, fields } : undefined;
+  }
+  const text = bru.text(`body:${mode}`);
+  if (text === undefined || text.trim() === "") {
+    return undefined;
+  }
+  const parsed = mode === "json" ? parseJson(text) : undefined;
+  return { contentType, value: parsed === undefined ? text : parsed };
+};
+
+/**
+ * Auth van een request, collectie of map; `mode` komt uit het methode-blok van een request of uit
+ * het `auth` blok van `collection.bru`/`folder.bru`. Bij `inherit` geldt de auth van de map en
+ * anders die van `collection.bru`.
+ */
+const resolveSecurity = (bru, mode, inherited, variables) => {
+  if (!mode || mode === "inherit") {
+    return inherited;
+  }
+  if (mode === "bearer" || mode === "basic") {
+    return { type: mode };
+  }
+  if (mode === "apikey") {
+    return {
+      type: "apiKey",
+      name: bru.value("auth:apikey", "key") || undefined,
+      in: bru.value("auth:apikey", "placement") === "queryparams" ? "query" : "header",
+    };
+  }
+  if (mode === "oauth2") {
+    const tokenUrl = substituteVariables(bru.value("auth:oauth2", "access_token_url") || "", variables);
+    const scope = substituteVariables(bru.value("auth:oauth2", "scope") || "", variables);
+    return {
+      type: "oauth2",
+      tokenUrl: tokenUrl && !tokenUrl.includes("{{") ? tokenUrl : undefined,
+      scopes: scope.includes("{{") ? [] : scope.split(/\s+/).filter(Boolean),
+    };
+  }
+  return undefined;
+};
+
+/**
+ * Haalt collectie, mappen, requests en environments uit de bestanden van een Bruno ZIP. Een
+ * gemeenschappelijke hoofdmap (zoals de export van `POST /v1/oas/bruno` die heeft) wordt genegeerd.
+ */
+const readCollection = (files) => {
+  const texts = files.map((file) => ({ name: file.name.replace(/\\/g, "/"), text: file.data.toString("utf8") }));
+  const brunoJson = texts.find((file) => /(^|\/)bruno\.json$/.test(file.name));
+  const root = brunoJson ? brunoJson.name.slice(0, -"bruno.json".length) : "";
+  const relative = texts
+    .filter((file) => file.name.startsWith(root))
+    .map((file) => ({ ...file, name: file.name.slice(root.length) }));
+  return {
+    config: (brunoJson && parseJson(brunoJson.text)) || {},
+    files: relative,
+  };
+};
+
+/**
+ * Zet de bestanden van een Bruno collectie om naar een OpenAPI 3.0 skelet; de omgekeerde richting
+ * van `POST /v1/oas/bruno`. Mappen op het hoogste niveau worden tags, `params:path`, `params:query`
+ * en `headers` worden parameters en de body's leveren schema's. Een host als `{{baseUrl}}` levert
+ * een server per environment op.
+ */
+const buildOpenApiDocument = (files, { source } = {}) => {
+  const { config, files: collectionFiles } = readCollection(files);
+  const environments = collectionFiles
+    .filter((file) => /^environments\/[^/]+\.bru$/.test(file.name))
+    .map((file) => ({
+      name: file.name.slice("environments/".length, -".bru".length),
+      variables: new Map(parseBru(file.text).dictionary("vars").map(({ key, value }) => [key, value])),
+    }));
+  const variables = environments[0]?.variables || new Map();
+  const collectionBru = collectionFiles.find((file) => file.name === "collection.bru");
+  const collectionAuth = collectionBru && parseBru(collectionBru.text);
+  const collectionSecurity =
+    collectionAuth && resolveSecurity(collectionAuth, collectionAuth.value("auth", "mode"), undefined, variables);
+  const folders = new Map(
+    collectionFiles
+      .filter((file) => /(^|\/)folder\.bru$/.test(file.name))
+      .map((file) => [file.name.slice(0, -"folder.bru".length), parseBru(file.text)]),
+  );
+
+  const requests = [];
+  for (const file of collectionFiles) {
+    if (!file.name.endsWith(".bru") || /(^|\/)(folder|collection)\.bru$/.test(file.name)) {
+      continue;
+    }
+    if (file.name.startsWith("environments/")) {
+      continue;
+    }
+    const bru = parseBru(file.text);
+    const method = HTTP_METHODS.find((candidate) => bru.has(candidate));
+    if (!method) {
+      continue;
+    }
+    const directory = file.name.slice(0, file.name.lastIndexOf("/") + 1);
+    const [topDirectory] = directory.split("/");
+    const tag = topDirectory ? folders.get(`${topDirectory}/`)?.value("meta", "name") || topDirectory : undefined;
+    requests.push({ bru, method, directory, folder: folders.get(directory), tag });
+  }
+  if (requests.length === 0) {
+    throw Service.rejectResponse({ message: INVALID_COLLECTION_ERROR }, 400);
+  }
+  // Binnen een map volgen requests de `seq` uit de meta, zoals Bruno ze toont.
+  requests.sort(
+    (left, right) =>
+      left.directory.localeCompare(right.directory) ||
+      Number(left.bru.value("meta", "seq") || 0) - Number(right.bru.value("meta", "seq") || 0),
+  );
+
+  const servers = [];
+  const addServer = (server) => {
+    if (server.url && !servers.some((item) => item.url === server.url)) {
+      servers.push(server);
+    }
+  };
+  const operations = requests.map(({ bru, method, folder, tag }) => {
+    const url = bru.value(method, "url") || "";
+    const { server, hostVariable, path, query } = splitRequestUrl(url, variables);
+    if (hostVariable) {
+      for (const environment of environments) {
+        const value = environment.variables.get(hostVariable);
+        if (value) {
+          addServer({ url: value.replace(/\/+$/, ""), description: environment.name });
+        }
+      }
+    } else if (server) {
+      addServer({ url: server });
+    }
+    const folderSecurity = folder
+      ? resolveSecurity(folder, folder.value("auth", "mode"), collectionSecurity, variables)
+      : collectionSecurity;
+    return {
+      method,
+      path,
+      summary: bru.value("meta", "name"),
+      description: bru.text("docs")?.trim(),
+      tag,
+      pathParameters: toPairs(bru.dictionary("params:path")),
+      query: bru.has("params:query") ? toPairs(bru.dictionary("params:query")) : query,
+      headers: toPairs(bru.dictionary("headers")),
+      requestBody: resolveRequestBody(bru, bru.value(method, "body")),
+      security: resolveSecurity(bru, bru.value(method, "auth"), folderSecurity, variables),
+    };
+  });
+
+  const document = buildOasSkeleton({
+    info: { title: typeof config.name === "string" ? config.name : undefined },
+    servers,
+    operations,
+  });
+  return stampDocument(document, buildProvenance({ tool: "bruno-oas", source }));
+};
+
+const decodeArchive = (archive) => {
+  const text = typeof archive === "string" ? archive.replace(/\s+/g, "").replace(/^data:[^,]*,/, "") : "";
+  if (!text || !BASE64_PATTERN.test(text)) {
+    throw Service.rejectResponse({ message: MISSING_ARCHIVE_ERROR }, 400);
+  }
+  try {
+    return readZip(Buffer.from(text, "base64"));
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "De Bruno collectie kon niet worden uitgepakt.",
+        detail: error.message,
+      },
+      400,
+    );
+  }
+};
+
+const convert = async (input) => {
+  const files = decodeArchive(input?.archive);
+  let document;
+  try {
+    document = buildOpenApiDocument(files, { source: "request-body" });
+  } catch (error) {
+    if (Service.isErrorResponse(error)) {
+      throw error;
+    }
+    throw Service.rejectResponse(
+      {
+        message: "Conversie van Bruno collectie naar OpenAPI is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const filenameBase = sanitizeFileName(document.info.title, { fallback: DEFAULT_TITLE, lowercase: true });
+  return {
+    headers: {
+      "Content-Type": "application/json",
+      "Content-Disposition": `attachment; filename="${filenameBase}.openapi.json"`,
+    },
+    rawBody: Buffer.from(JSON.stringify(document, null, 2), "utf8"),
+  };
+};
+
+module.exports = {
+  buildOpenApiDocument,
+  convert,
+  parseBru,
+};
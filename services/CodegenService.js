@@ -0,0 +1,881 @@
+const crypto = require("node:crypto");
+const fs = require("node:fs/promises");
+const os = require("node:os");
+const path = require("node:path");
+const { execFile } = require("node:child_process");
+const { promisify } = require("node:util");
+const { kebabCase, snakeCase, upperCamelCase } = require("case-anything");
+const Service = require("./Service");
+const { bundle } = require("./OasBundleService");
+const { resolveOasDocument } = require("./OasInputService");
+const { convertSpec } = require("./OasConversionService");
+const { sanitizeFileName } = require("../utils/fileName");
+const {
+  collectExternalRefs,
+  collectOperations,
+  expandServerUrl,
+  resolveAuthScheme,
+  resolveRef,
+  schemaRefName,
+} = require("../utils/openapi");
+const { buildProvenance, describeProvenance, stampDocument } = require("../utils/provenance");
+const { dumpYaml } = require("../utils/yaml");
+const { createZip } = require("../utils/zip");
+const logger = require("../logger");
+
+const DEFAULT_FILENAME = "openapi";
+const TEMP_PREFIX = "codegen-";
+const DEFAULT_TIMEOUT_SECONDS = 120;
+const OAPI_CODEGEN_MODULE = "github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen";
+const PYTHON_LINE_LENGTH = 100;
+const MAX_DETAIL_LINES = 20;
+// Python-keywords plus de namen die de gegenereerde methodes zelf gebruiken.
+const PYTHON_RESERVED = new Set(
+  [
+    "False None True and as assert async await break class continue def del elif else except finally for from",
+    "global if import in is lambda nonlocal not or pass raise return try while with yield body self",
+  ]
+    .join(" ")
+    .split(" "),
+);
+const execFileAsync = promisify(execFile);
+
+const GO_FRAMEWORKS = {
+  gin: {
+    generate: "gin-server",
+    import: '"github.com/gin-gonic/gin"',
+    stub: (context) => [`\t${context}.JSON(http.StatusNotImplemented, notImplemented)`],
+    main: (apiVersion) => [
+      "\trouter := gin.Default()",
+      "\trouter.Use(func(c *gin.Context) {",
+      `\t\tc.Header("API-Version", ${apiVersion})`,
+      "\t\tc.Next()",
+      "\t})",
+      "\tapi.RegisterHandlers(router, api.NewServer())",
+      '\tlog.Fatal(router.Run(":8080"))',
+    ],
+    mainImports: ['"log"'],
+  },
+  echo: {
+    generate: "echo-server",
+    import: '"github.com/labstack/echo/v4"',
+    stub: (context) => [`\treturn ${context}.JSON(http.StatusNotImplemented, notImplemented)`],
+    main: (apiVersion) => [
+      "\te := echo.New()",
+      "\te.Use(func(next echo.HandlerFunc) echo.HandlerFunc {",
+      "\t\treturn func(c echo.Context) error {",
+      `\t\t\tc.Response().Header().Set("API-Version", ${apiVersion})`,
+      "\t\t\treturn next(c)",
+      "\t\t}",
+      "\t})",
+      "\tapi.RegisterHandlers(e, api.NewServer())",
+      '\te.Logger.Fatal(e.Start(":8080"))',
+    ],
+    mainImports: [],
+  },
+};
+const DEFAULT_GO_FRAMEWORK = "gin";
+
+const resolveTimeoutSeconds = () => {
+  const value = Number.parseInt(process.env.CODEGEN_TIMEOUT_SECONDS ?? "", 10);
+  return Number.isFinite(value) && value > 0 ? value : DEFAULT_TIMEOUT_SECONDS;
+};
+
+// De eerste regels van de foutmelding van een generator, zonder de tijdelijke map en Java-stacktraces.
+const generatorDetail = (stderr, cwd) =>
+  String(stderr ?? "")
+    .split(cwd)
+    .join(".")
+    .split("\n")
+    .map((line) => line.trimEnd())
+    .filter((line) => line && !/^\s+at\s/.test(line))
+    .slice(0, MAX_DETAIL_LINES)
+    .join("\n");
+
+/**
+ * Draait een generator-CLI in `cwd`. Een ontbrekende CLI geeft een `503` met de naam van de
+ * omgevingsvariabele waarmee het pad in te stellen is; fouten van de generator zelf een `400`,
+ * omdat die vrijwel altijd over de specificatie gaan.
+ */
+const runGenerator = async ({ bin, envName, args, cwd, label }) => {
+  const timeoutSeconds = resolveTimeoutSeconds();
+  try {
+    return await execFileAsync(bin, args, { cwd, maxBuffer: 20 * 1024 * 1024, timeout: timeoutSeconds * 1000 });
+  } catch (error) {
+    logger.error(`[CodegenService] ${label} failed: ${error?.message}`);
+    if (error?.code === "ENOENT" && error?.path === bin) {
+      throw Service.rejectResponse(
+        {
+          message: `Codegeneratie met ${label} is niet beschikbaar op deze server.`,
+          detail: `${bin} is niet gevonden; stel ${envName} in.`,
+        },
+        503,
+      );
+    }
+    if (error?.killed) {
+      throw Service.rejectResponse(
+        { message: `Codegeneratie met ${label} duurde langer dan ${timeoutSeconds} seconden en is afgebroken.` },
+        504,
+      );
+    }
+    throw Service.rejectResponse(
+      {
+        message: `Codegeneratie met ${label} is mislukt.`,
+        detail: generatorDetail(error?.stderr, cwd) || undefined,
+      },
+      400,
+    );
+  }
+};
+
+const resolveGoFramework = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return DEFAULT_GO_FRAMEWORK;
+  }
+  const framework = String(value).toLowerCase();
+  if (!GO_FRAMEWORKS[framework]) {
+    throw Service.rejectResponse(
+      { message: `Ongeldige waarde voor framework: "${value}". Kies uit: ${Object.keys(GO_FRAMEWORKS).join(", ")}.` },
+      400,
+    );
+  }
+  return framework;
+};
+
+/**
+ * Leest de methodes van `ServerInterface` uit de code van oapi-codegen, met het commentaar erboven
+ * (`// (GET /pets)`). De signatures worden ongewijzigd overgenomen in de stubs.
+ */
+const parseServerInterface = (source) => {
+  const block = /^type ServerInterface interface \{\n([\s\S]*?)^\}/m.exec(source);
+  if (!block) {
+    return [];
+  }
+  const methods = [];
+  let comments = [];
+  for (const rawLine of block[1].split("\n")) {
+    const line = rawLine.trim();
+    if (line.startsWith("//")) {
+      comments.push(line);
+      continue;
+    }
+    const match = /^(\w+)\((\w+)\s.*$/.exec(line);
+    if (match) {
+      methods.push({ name: match[1], context: match[2], signature: line, comments });
+    }
+    comments = [];
+  }
+  return methods;
+};
+
+const renderGoImports = (groups) => {
+  const filled = groups.filter((group) => group.length > 0);
+  if (filled.length === 0) {
+    return [];
+  }
+  const lines = filled.flatMap((group, index) => [...(index > 0 ? [""] : []), ...group.map((item) => `\t${item}`)]);
+  return ["import (", ...lines, ")", ""];
+};
+
+const renderGoServerStubs = (methods, framework) => {
+  const settings = GO_FRAMEWORKS[framework];
+  const signatures = methods.map((method) => method.signature).join("\n");
+  const thirdParty = [
+    ...(methods.length > 0 ? [settings.import] : []),
+    ...(signatures.includes("openapi_types.") ? ['openapi_types "github.com/oapi-codegen/runtime/types"'] : []),
+  ].sort();
+  const lines = [
+    "package api",
+    "",
+    ...renderGoImports([['"net/http"', ...(/\btime\./.test(signatures) ? ['"time"'] : [])], thirdParty]),
+    "// Server implementeert ServerInterface. Vervang de stubs door de eigen implementatie; ze geven",
+    "// nu 501 Not Implemented terug.",
+    "type Server struct{}",
+    "",
+    "var _ ServerInterface = (*Server)(nil)",
+    "",
+    'var notImplemented = map[string]any{"status": http.StatusNotImplemented, "title": "Not Implemented"}',
+    "",
+    "func NewServer() *Server {",
+    "\treturn &Server{}",
+    "}",
+  ];
+  for (const method of methods) {
+    lines.push(
+      "",
+      ...method.comments,
+      `func (s *Server) ${method.signature} {`,
+      ...settings.stub(method.context),
+      "}",
+    );
+  }
+  return `${lines.join("\n")}\n`;
+};
+
+const renderGoMain = (moduleName, framework, version) => {
+  const settings = GO_FRAMEWORKS[framework];
+  const lines = [
+    "package main",
+    "",
+    ...renderGoImports([settings.mainImports, [settings.import], [`"${moduleName}/api"`]]),
+    "// Versie uit info.version van de specificatie, meegestuurd in elke response (ADR /core/version-header).",
+    `const apiVersion = ${JSON.stringify(version)}`,
+    "",
+    "func main() {",
+    ...settings.main("apiVersion"),
+    "}",
+  ];
+  return `${lines.join("\n")}\n`;
+};
+
+const renderGoReadme = (title, framework, provenance) =>
+  [
+    `# ${title}`,
+    "",
+    `Servercode (${framework}) gegenereerd met [oapi-codegen](https://github.com/oapi-codegen/oapi-codegen) uit`,
+    "`api/openapi.yaml`.",
+    "",
+    "- `api/api.gen.go`: modellen, routering en de ingebouwde specificatie; niet met de hand aanpassen",
+    "- `api/server.go`: stubs per operatie die `501 Not Implemented` teruggeven; hier komt de implementatie",
+    "- `main.go`: start de server op poort 8080 en zet de `API-Version` header",
+    "",
+    "```sh",
+    "go mod tidy",
+    "go run .",
+    "```",
+    "",
+    "Na een wijziging in `api/openapi.yaml` maakt `go generate ./...` `api/api.gen.go` opnieuw aan.",
+    "",
+    `<!-- ${describeProvenance(provenance).replace(/--/g, "- -")} -->`,
+    "",
+  ].join("\n");
+
+/**
+ * Go-server met oapi-codegen (`OAPI_CODEGEN_BIN`, standaard `oapi-codegen` op het PATH). De CLI
+ * levert modellen en routering voor Gin of Echo; de stubs in `server.go` en `main.go` worden hier
+ * uit de gegenereerde `ServerInterface` opgebouwd, zodat het project direct compileert.
+ */
+const generateGoServer = async ({ document, workDir, moduleName, title, provenance, options }) => {
+  const framework = resolveGoFramework(options.framework);
+  const apiDir = path.join(workDir, "api");
+  await fs.mkdir(apiDir, { recursive: true });
+  const config = {
+    package: "api",
+    output: "api.gen.go",
+    generate: { models: true, [GO_FRAMEWORKS[framework].generate]: true, "embedded-spec": true },
+  };
+  const files = [
+    { name: "api/openapi.yaml", data: dumpYaml(document) },
+    { name: "api/oapi-codegen.yaml", data: dumpYaml(config) },
+    {
+      name: "api/generate.go",
+      data: `package api\n\n//go:generate go run ${OAPI_CODEGEN_MODULE} --config=oapi-codegen.yaml openapi.yaml\n`,
+    },
+  ];
+  for (const file of files) {
+    await fs.writeFile(path.join(workDir, file.name), file.data, "utf8");
+  }
+  await runGenerator({
+    bin: process.env.OAPI_CODEGEN_BIN || "oapi-codegen",
+    envName: "OAPI_CODEGEN_BIN",
+    args: ["-config", "oapi-codegen.yaml", "openapi.yaml"],
+    cwd: apiDir,
+    label: "oapi-codegen",
+  });
+  const generated = await fs.readFile(path.join(apiDir, "api.gen.go"), "utf8");
+  return [
+    { name: "go.mod", data: `module ${moduleName}\n\ngo 1.22\n` },
+    { name: "main.go", data: renderGoMain(moduleName, framework, String(document.info?.version ?? "")) },
+    { name: "README.md", data: renderGoReadme(title, framework, provenance) },
+    ...files,
+    { name: "api/api.gen.go", data: generated },
+    { name: "api/server.go", data: renderGoServerStubs(parseServerInterface(generated), framework) },
+  ];
+};
+
+// Geldige, unieke Python-naam in snake_case; gereserveerde namen krijgen een `_` erachter.
+const pythonIdentifier = (value, used, fallback) => {
+  let name = snakeCase(String(value ?? "")).replace(/[^a-z0-9_]/g, "_") || fallback;
+  if (/^\d/.test(name)) {
+    name = `_${name}`;
+  }
+  if (PYTHON_RESERVED.has(name)) {
+    name = `${name}_`;
+  }
+  let candidate = name;
+  for (let counter = 2; used.has(candidate); counter += 1) {
+    candidate = `${name}_${counter}`;
+  }
+  used.add(candidate);
+  return candidate;
+};
+
+const pythonString = (value) => JSON.stringify(String(value ?? ""));
+
+const pythonDocstring = (value) => String(value).replace(/\\/g, "\\\\").replace(/"""/g, '\\"\\"\\"');
+
+const isJsonMediaType = (type) => /^application\/(?:[\w.+-]+\+)?json\b/i.test(type);
+
+/**
+ * Python-type voor een schema. Naar `components.schemas` verwijzen wordt `models.<Naam>` als de naam
+ * al een geldige klassenaam is (datamodel-code-generator laat die ongemoeid); anders `Any`.
+ */
+const pythonType = (document, rawSchema, modelNames) => {
+  const refName = schemaRefName(rawSchema);
+  if (refName) {
+    return modelNames.has(refName) ? `models.${refName}` : "Any";
+  }
+  const schema = resolveRef(document, rawSchema);
+  const type = Array.isArray(schema?.type) ? schema.type.find((item) => item !== "null") : schema?.type;
+  if (type === "array") {
+    return `list[${pythonType(document, schema.items, modelNames)}]`;
+  }
+  return { string: "str", integer: "int", number: "float", boolean: "bool", object: "dict[str, Any]" }[type] || "Any";
+};
+
+// f-string voor het pad, met de padparameters via `_path` ge-escaped.
+const pythonPath = (path, parameterNames) => {
+  const parts = path.split(/\{([^}]+)\}/);
+  const hasParameters = parts.length > 1;
+  const literal = (text) => {
+    const escaped = text.replace(/\\/g, "\\\\").replace(/"/g, '\\"');
+    return hasParameters ? escaped.replace(/\{/g, "{{").replace(/\}/g, "}}") : escaped;
+  };
+  const body = parts
+    .map((part, index) => {
+      if (index % 2 === 0) {
+        return literal(part);
+      }
+      return parameterNames.has(part) ? `{_path(${parameterNames.get(part)})}` : literal(`{${part}}`);
+    })
+    .join("");
+  return `${hasParameters ? "f" : ""}"${body}"`;
+};
+
+// Eén regel als die past, anders één argument per regel met een afsluitende komma (zoals Black).
+const pythonCall = (indent, head, items, tail) => {
+  const single = `${indent}${head}${items.join(", ")}${tail}`;
+  if (single.length <= PYTHON_LINE_LENGTH) {
+    return [single];
+  }
+  return [`${indent}${head}`, ...items.map((item) => `${indent}    ${item},`), `${indent}${tail}`];
+};
+
+const renderPythonOperation = (document, entry, modelNames, usedMethodNames) => {
+  const { path, method, operation, parameters, requestBody } = entry;
+  const methodName = pythonIdentifier(operation.operationId || `${method} ${path}`, usedMethodNames, method);
+  const usedNames = new Set(["self", "body"]);
+  const positional = [];
+  const keywords = [];
+  const pathNames = new Map();
+  const groups = { query: [], header: [] };
+  const sorted = parameters
+    .filter((parameter) => parameter.in === "path" || groups[parameter.in])
+    .sort((left, right) => Number(Boolean(right.required)) - Number(Boolean(left.required)));
+  for (const parameter of sorted) {
+    const name = pythonIdentifier(parameter.name, usedNames, "param");
+    const type = pythonType(document, parameter.schema, modelNames);
+    if (parameter.in === "path") {
+      pathNames.set(parameter.name, name);
+      positional.push(`${name}: ${type}`);
+      continue;
+    }
+    keywords.push(parameter.required ? `${name}: ${type}` : `${name}: ${type} | None = None`);
+    groups[parameter.in].push(`${pythonString(parameter.name)}: ${name}`);
+  }
+
+  const requestArgs = [pythonString(method.toUpperCase()), pythonPath(path, pathNames)];
+  const [bodyType, bodyMedia] = Object.entries(requestBody?.content || {})[0] || [];
+  if (bodyType) {
+    const json = isJsonMediaType(bodyType);
+    const type = json ? pythonType(document, bodyMedia?.schema, modelNames) : "bytes";
+    if (requestBody.required) {
+      positional.push(`body: ${type}`);
+    } else {
+      keywords.push(`body: ${type} | None = None`);
+    }
+    if (json) {
+      requestArgs.push("json=_dump(body)");
+    } else {
+      requestArgs.push("content=body");
+      groups.header.push(`"Content-Type": ${pythonString(bodyType)}`);
+    }
+  }
+  for (const [location, entries] of Object.entries(groups)) {
+    if (entries.length > 0) {
+      requestArgs.push(`${location === "query" ? "params" : "headers"}={${entries.join(", ")}}`);
+    }
+  }
+  const auth = resolveAuthScheme(document, operation);
+  if (auth) {
+    requestArgs.push(`auth=${pythonString(auth.type === "apiKey" ? "api_key" : auth.type)}`);
+    if (auth.type === "apiKey") {
+      requestArgs.push(`api_key_header=${pythonString(auth.header)}`);
+    }
+  }
+
+  const success = Object.entries(operation.responses || {}).find(([status]) => /^2/.test(status));
+  const response = resolveRef(document, success?.[1]);
+  const [responseType, responseMedia] = Object.entries(response?.content || {})[0] || [];
+  let returnType = "None";
+  let returnLine = "";
+  if (responseType && isJsonMediaType(responseType)) {
+    returnType = pythonType(document, responseMedia?.schema, modelNames);
+    returnLine = returnType === "Any" ? "return response.json()" : `return _parse(${returnType}, response.json())`;
+  } else if (responseType) {
+    returnType = "bytes";
+    returnLine = "return response.content";
+  }
+
+  const summary = String(operation.summary || operation.description || "").trim().split("\n")[0];
+  const args = ["self", ...positional, ...(keywords.length > 0 ? ["*", ...keywords] : [])];
+  const call = pythonCall("        ", `${returnLine ? "response = " : ""}self._request(`, requestArgs, ")");
+  return [
+    ...pythonCall("    ", `def ${methodName}(`, args, `) -> ${returnType}:`),
+    ...(summary
+      ? [`        """${pythonDocstring(summary)}`, "", `        ${method.toUpperCase()} ${pythonDocstring(path)}`]
+      : [`        """${method.toUpperCase()} ${pythonDocstring(path)}`]),
+    ...(operation.deprecated ? ["", "        Verouderd: deze operatie wordt uitgefaseerd."] : []),
+    '        """',
+    ...call,
+    ...(returnLine ? [`        ${returnLine}`] : []),
+  ];
+};
+
+const PYTHON_CLIENT_BASE = [
+  "class ApiError(Exception):",
+  '    """Response met een 4xx- of 5xx-status; `response` bevat de volledige response."""',
+  "",
+  "    def __init__(self, response: httpx.Response) -> None:",
+  '        super().__init__(f"{response.request.method} {response.request.url}: HTTP {response.status_code}")',
+  "        self.response = response",
+  "        self.status_code = response.status_code",
+  "",
+  "",
+  "def _path(value: Any) -> str:",
+  '    return quote(str(value), safe="")',
+  "",
+  "",
+  "def _dump(value: Any) -> Any:",
+  "    if value is None or isinstance(value, BaseModel):",
+  '        return None if value is None else value.model_dump(mode="json", by_alias=True, exclude_none=True)',
+  '    return TypeAdapter(Any).dump_python(value, mode="json")',
+  "",
+  "",
+  "def _parse(type_: Any, data: Any) -> Any:",
+  "    return TypeAdapter(type_).validate_python(data)",
+  "",
+  "",
+  "class Client:",
+  "    def __init__(",
+  "        self,",
+  "        base_url: str = DEFAULT_BASE_URL,",
+  "        *,",
+  "        token: str | None = None,",
+  "        api_key: str | None = None,",
+  "        username: str | None = None,",
+  "        password: str | None = None,",
+  "        headers: dict[str, str] | None = None,",
+  "        timeout: float = 30.0,",
+  "        http_client: httpx.Client | None = None,",
+  "    ) -> None:",
+  "        self._client = http_client or httpx.Client(base_url=base_url, headers=headers, timeout=timeout)",
+  "        self._token = token",
+  "        self._api_key = api_key",
+  "        self._username = username",
+  "        self._password = password",
+  "",
+  "    def close(self) -> None:",
+  "        self._client.close()",
+  "",
+  "    def __enter__(self) -> Client:",
+  "        return self",
+  "",
+  "    def __exit__(self, *exc_info: object) -> None:",
+  "        self.close()",
+  "",
+  "    def _request(",
+  "        self,",
+  "        method: str,",
+  "        url: str,",
+  "        *,",
+  "        params: dict[str, Any] | None = None,",
+  "        headers: dict[str, Any] | None = None,",
+  "        json: Any = None,",
+  "        content: bytes | None = None,",
+  "        auth: str | None = None,",
+  "        api_key_header: str | None = None,",
+  "    ) -> httpx.Response:",
+  "        params = {key: value for key, value in (params or {}).items() if value is not None}",
+  "        headers = {key: str(value) for key, value in (headers or {}).items() if value is not None}",
+  "        basic_auth = None",
+  '        if auth == "bearer" and self._token:',
+  '            headers["Authorization"] = f"Bearer {self._token}"',
+  '        elif auth == "basic" and self._username is not None:',
+  '            basic_auth = httpx.BasicAuth(self._username, self._password or "")',
+  '        elif auth == "api_key" and api_key_header and self._api_key:',
+  "            headers[api_key_header] = self._api_key",
+  "        response = self._client.request(",
+  "            method,",
+  "            url,",
+  "            params=params,",
+  "            headers=headers,",
+  "            json=json,",
+  "            content=content,",
+  "            auth=basic_auth or httpx.USE_CLIENT_DEFAULT,",
+  "        )",
+  "        if response.is_error:",
+  "            raise ApiError(response)",
+  "        return response",
+];
+
+/**
+ * `client.py` van de Python-client: een synchrone httpx-client met een methode per operatie (naam
+ * uit `operationId` in snake_case). Bodies en responses met een schema uit `components.schemas`
+ * gebruiken de pydantic-modellen uit `models.py`; 4xx en 5xx geven een `ApiError`.
+ */
+const renderPythonClient = (document, { modelNames = new Set(), provenance } = {}) => {
+  const title = String(document.info?.title || "API").trim();
+  const server = (Array.isArray(document.servers) ? document.servers : []).map(expandServerUrl).find(Boolean);
+  const usedMethodNames = new Set(["close", "_request"]);
+  const lines = [
+    `"""Client voor ${pythonDocstring(title)} ${pythonDocstring(document.info?.version ?? "")}.`,
+    ...(provenance ? ["", pythonDocstring(describeProvenance(provenance))] : []),
+    '"""',
+    "",
+    "from __future__ import annotations",
+    "",
+    "from typing import Any",
+    "from urllib.parse import quote",
+    "",
+    "import httpx",
+    "from pydantic import BaseModel, TypeAdapter",
+    "",
+    "from . import models",
+    "",
+    `DEFAULT_BASE_URL = ${pythonString(server || "http://localhost")}`,
+    "",
+    "",
+    ...PYTHON_CLIENT_BASE,
+  ];
+  for (const entry of collectOperations(document)) {
+    lines.push("", ...renderPythonOperation(document, entry, modelNames, usedMethodNames));
+  }
+  return `${lines.join("\n")}\n`;
+};
+
+// Pakketversies (PEP 440, Maven) moeten numeriek zijn; een andere info.version wordt 0.1.0.
+const packageVersion = (version) => (/^\d+(?:\.\d+)*$/.test(String(version ?? "")) ? String(version) : "0.1.0");
+
+/**
+ * Python-client met httpx en pydantic. De modellen komen van datamodel-code-generator
+ * (`DATAMODEL_CODEGEN_BIN`, standaard `datamodel-codegen` op het PATH); de client zelf wordt hier
+ * opgebouwd, zodat methodenamen en -signatures aansluiten op de operaties.
+ */
+const generatePythonClient = async ({ document, workDir, title, provenance }) => {
+  const packageName = pythonIdentifier(`${title} client`, new Set(), "api_client");
+  const schemaNames = Object.keys(document.components?.schemas || {});
+  let models = '"""Geen schema\'s in de specificatie."""\n';
+  await fs.writeFile(path.join(workDir, "openapi.yaml"), dumpYaml(document), "utf8");
+  if (schemaNames.length > 0) {
+    await runGenerator({
+      bin: process.env.DATAMODEL_CODEGEN_BIN || "datamodel-codegen",
+      envName: "DATAMODEL_CODEGEN_BIN",
+      args: [
+        "--input",
+        "openapi.yaml",
+        "--input-file-type",
+        "openapi",
+        "--output",
+        "models.py",
+        "--output-model-type",
+        "pydantic_v2.BaseModel",
+        "--target-python-version",
+        "3.10",
+        "--use-standard-collections",
+        "--use-union-operator",
+        "--disable-timestamp",
+      ],
+      cwd: workDir,
+      label: "datamodel-code-generator",
+    });
+    models = await fs.readFile(path.join(workDir, "models.py"), "utf8");
+  }
+  const modelNames = new Set(schemaNames.filter((name) => /^[A-Z][A-Za-z0-9]*$/.test(name)));
+  const projectName = kebabCase(packageName);
+  const pyproject = [
+    "[build-system]",
+    'requires = ["hatchling"]',
+    'build-backend = "hatchling.build"',
+    "",
+    "[project]",
+    `name = ${pythonString(projectName)}`,
+    `version = ${pythonString(packageVersion(document.info?.version))}`,
+    `description = ${pythonString(`Python-client voor ${title}`)}`,
+    'requires-python = ">=3.10"',
+    'dependencies = ["httpx>=0.27", "pydantic>=2.5"]',
+    "",
+    "[tool.hatch.build.targets.wheel]",
+    `packages = ["src/${packageName}"]`,
+    "",
+  ].join("\n");
+  const readme = [
+    `# ${title}`,
+    "",
+    "Python-client (httpx en pydantic) gegenereerd uit `openapi.yaml`.",
+    "",
+    "```python",
+    `from ${packageName} import Client`,
+    "",
+    "with Client(token=\"...\") as client:",
+    "    ...",
+    "```",
+    "",
+    `- \`src/${packageName}/models.py\`: pydantic-modellen, gegenereerd met datamodel-code-generator`,
+    `- \`src/${packageName}/client.py\`: \`Client\` met een methode per operatie; fouten geven een \`ApiError\``,
+    "",
+    `<!-- ${describeProvenance(provenance).replace(/--/g, "- -")} -->`,
+    "",
+  ].join("\n");
+  return [
+    { name: "pyproject.toml", data: pyproject },
+    { name: "README.md", data: readme },
+    { name: "openapi.yaml", data: dumpYaml(document) },
+    {
+      name: `src/${packageName}/__init__.py`,
+      data: 'from .client import ApiError, Client\n\n__all__ = ["ApiError", "Client"]\n',
+    },
+    { name: `src/${packageName}/models.py`, data: models },
+    { name: `src/${packageName}/client.py`, data: renderPythonClient(document, { modelNames, provenance }) },
+  ];
+};
+
+// Alle bestanden onder `directory`; uitvoerbare bestanden (zoals `gradlew`) houden dat in de ZIP.
+const readGeneratedFiles = async (directory) => {
+  const files = [];
+  for (const relativePath of (await fs.readdir(directory, { recursive: true })).sort()) {
+    const filePath = path.join(directory, relativePath);
+    const stat = await fs.stat(filePath);
+    if (!stat.isFile()) {
+      continue;
+    }
+    const name = relativePath.split(path.sep).join("/");
+    const data = await fs.readFile(filePath);
+    files.push(stat.mode & 0o111 ? { name, data, mode: 0o755 } : { name, data });
+  }
+  return files;
+};
+
+/**
+ * Draait openapi-generator (`OPENAPI_GENERATOR_BIN`, standaard `openapi-generator-cli` op het PATH)
+ * met een vaste configuratie per taal. De configuratie en de specificatie gaan mee in de ZIP, zodat
+ * teams met precies dezelfde instellingen opnieuw kunnen genereren.
+ */
+const runOpenApiGenerator = async ({ document, workDir, generator, config }) => {
+  const outputDir = path.join(workDir, "out");
+  const configFile = { name: "openapi-generator.json", data: `${JSON.stringify(config, null, 2)}\n` };
+  const specFile = { name: "openapi.yaml", data: dumpYaml(document) };
+  for (const file of [configFile, specFile]) {
+    await fs.writeFile(path.join(workDir, file.name), file.data, "utf8");
+  }
+  await runGenerator({
+    bin: process.env.OPENAPI_GENERATOR_BIN || "openapi-generator-cli",
+    envName: "OPENAPI_GENERATOR_BIN",
+    args: ["generate", "-i", specFile.name, "-g", generator, "-c", configFile.name, "-o", outputDir],
+    cwd: workDir,
+    label: "openapi-generator",
+  });
+  const generated = await readGeneratedFiles(outputDir);
+  const names = new Set(generated.map((file) => file.name));
+  return [...generated, ...[configFile, specFile].filter((file) => !names.has(file.name))];
+};
+
+const JAVA_PACKAGE_PATTERN = /^[a-z_][a-z0-9_]*(?:\.[a-z_][a-z0-9_]*)*$/;
+
+// Standaard `nl.<titel>.client`, met alleen kleine letters en cijfers uit de titel.
+const resolveJavaPackage = (value, title) => {
+  if (value === undefined || value === null || value === "") {
+    const compact = sanitizeFileName(title, { fallback: "api", lowercase: true }).replace(/[^a-z0-9]/g, "");
+    return `nl.${/^[a-z]/.test(compact) ? compact : `api${compact}`}.client`;
+  }
+  const packageName = String(value).trim();
+  if (!JAVA_PACKAGE_PATTERN.test(packageName)) {
+    throw Service.rejectResponse(
+      { message: `Ongeldige waarde voor packageName: "${value}". Gebruik een Java-package zoals nl.gemeente.client.` },
+      400,
+    );
+  }
+  return packageName;
+};
+
+/**
+ * Java-client met openapi-generator (`java`, library `webclient`): Spring WebClient met Jackson en
+ * Jakarta EE, zodat de client direct in een Spring Boot 3-applicatie past.
+ */
+const generateJavaClient = async ({ document, workDir, moduleName, title, options }) => {
+  const packageName = resolveJavaPackage(options.packageName, title);
+  return runOpenApiGenerator({
+    document,
+    workDir,
+    generator: "java",
+    config: {
+      library: "webclient",
+      groupId: packageName.split(".").slice(0, -1).join(".") || packageName,
+      artifactId: `${moduleName}-client`,
+      artifactVersion: packageVersion(document.info?.version),
+      invokerPackage: packageName,
+      apiPackage: `${packageName}.api`,
+      modelPackage: `${packageName}.model`,
+      useJakartaEe: true,
+      dateLibrary: "java8",
+      serializationLibrary: "jackson",
+      openApiNullable: false,
+      hideGenerationTimestamp: true,
+    },
+  });
+};
+
+const CSHARP_NAMESPACE_PATTERN = /^[A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*$/;
+
+// Standaard `<Titel>.Client` in PascalCase.
+const resolveCSharpNamespace = (value, title) => {
+  if (value === undefined || value === null || value === "") {
+    const compact = upperCamelCase(title).replace(/[^A-Za-z0-9_]/g, "");
+    return `${/^[A-Za-z_]/.test(compact) ? compact : `Api${compact}`}.Client`;
+  }
+  const namespace = String(value).trim();
+  if (!CSHARP_NAMESPACE_PATTERN.test(namespace)) {
+    throw Service.rejectResponse(
+      { message: `Ongeldige waarde voor packageName: "${value}". Gebruik een C#-namespace zoals Gemeente.Client.` },
+      400,
+    );
+  }
+  return namespace;
+};
+
+// Vaste project-GUID per namespace; anders kiest openapi-generator bij elke run een nieuwe.
+const stableGuid = (name) => {
+  const hex = crypto.createHash("sha1").update(`don-tools-api:${name}`).digest("hex");
+  const parts = [hex.slice(0, 8), hex.slice(8, 12), `5${hex.slice(13, 16)}`, `a${hex.slice(17, 20)}`, hex.slice(20, 32)];
+  return parts.join("-").toUpperCase();
+};
+
+/**
+ * .NET-client met openapi-generator (`csharp`, library `generichost`): een projectmap met de client
+ * voor .NET 8, registratie via `IServiceCollection` en nullable reference types.
+ */
+const generateCSharpClient = async ({ document, workDir, title, options }) => {
+  const packageName = resolveCSharpNamespace(options.packageName, title);
+  return runOpenApiGenerator({
+    document,
+    workDir,
+    generator: "csharp",
+    config: {
+      library: "generichost",
+      packageName,
+      packageVersion: packageVersion(document.info?.version),
+      packageGuid: `{${stableGuid(packageName)}}`,
+      targetFramework: "net8.0",
+      nullableReferenceTypes: true,
+      useDateTimeOffset: true,
+      hideGenerationTimestamp: true,
+    },
+  });
+};
+
+const GENERATORS = {
+  "go-server": generateGoServer,
+  python: generatePythonClient,
+  java: generateJavaClient,
+  csharp: generateCSharpClient,
+};
+
+const resolveLanguage = (value) => {
+  const language = String(value ?? "").toLowerCase();
+  if (!GENERATORS[language]) {
+    throw Service.rejectResponse(
+      { message: `Ongeldige waarde voor language: "${value ?? ""}". Kies uit: ${Object.keys(GENERATORS).join(", ")}.` },
+      400,
+    );
+  }
+  return language;
+};
+
+/**
+ * De generators lossen zelf `$ref`s op, buiten het beleid voor uitgaand verkeer om en ook naar bestanden
+ * op de server. Externe verwijzingen gaan daarom eerst door `OasBundleService` (met de begrensde en
+ * getoetste resolver); een generator krijgt alleen een document met verwijzingen binnen het document.
+ */
+const withLocalRefs = async (input, spec) => {
+  if (collectExternalRefs(spec).length === 0) {
+    return spec;
+  }
+  const bundled = await bundle(input, { mode: "bundle", responseFormat: "json" });
+  const document = JSON.parse(bundled.rawBody.toString("utf8"));
+  const [remaining] = collectExternalRefs(document);
+  if (remaining !== undefined) {
+    throw Service.rejectResponse({ message: `De verwijzing ${remaining} kon niet worden gebundeld.` }, 400);
+  }
+  return document;
+};
+
+/**
+ * Genereert code voor `language` uit een OpenAPI document en geeft een ZIP terug. OpenAPI 3.1 wordt
+ * eerst naar 3.0 omgezet, omdat de generators 3.1 niet (volledig) ondersteunen.
+ */
+const generate = async (input, options = {}) => {
+  const language = resolveLanguage(options.language);
+  const generator = GENERATORS[language];
+  const resolved = await resolveOasDocument(input);
+  if (collectOperations(resolved.spec).length === 0) {
+    throw Service.rejectResponse({ message: "De specificatie bevat geen operaties om code voor te genereren." }, 400);
+  }
+  const { spec } = await convertSpec(await withLocalRefs(input, resolved.spec), "3.0");
+  const provenance = buildProvenance({ tool: `oas-codegen-${language}`, source: resolved.source });
+  const document = stampDocument(spec, provenance);
+  const title = (typeof document.info?.title === "string" && document.info.title.trim()) || DEFAULT_FILENAME;
+  const filenameBase = `${sanitizeFileName(title, { fallback: DEFAULT_FILENAME, lowercase: true })}-${language}`;
+
+  let workDir;
+  let files;
+  try {
+    workDir = await fs.mkdtemp(path.join(os.tmpdir(), TEMP_PREFIX));
+    files = await generator({
+      document,
+      workDir,
+      moduleName: sanitizeFileName(title, { fallback: DEFAULT_FILENAME, lowercase: true }),
+      title,
+      provenance,
+      options: input,
+    });
+  } catch (error) {
+    if (Service.isErrorResponse(error)) {
+      throw error;
+    }
+    logger.error(`[CodegenService] ${language} failed: ${error?.message}`);
+    throw Service.rejectResponse({ message: "Codegeneratie is mislukt.", detail: error?.message }, 500);
+  } finally {
+    if (workDir) {
+      try {
+        await fs.rm(workDir, { recursive: true, force: true });
+      } catch {
+        // ignore cleanup errors
+      }
+    }
+  }
+
+  return {
+    headers: {
+      "Content-Type": "application/zip",
+      "Content-Disposition": `attachment; filename="${filenameBase}.zip"`,
+    },
+    rawBody: createZip(files.map((file) => ({ ...file, name: `${filenameBase}/${file.name}` }))),
+  };
+};
+
+module.exports = {
+  generate,
+  parseServerInterface,
+  renderGoServerStubs,
+  renderPythonClient,
+};
@@ -0,0 +1,295 @@
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { mapWithConcurrency } = require("../utils/concurrency");
+const { sanitizeFileName, uniqueFileName } = require("../utils/fileName");
+const { decodePointerSegment, encodePointerSegment } = require("../utils/jsonPointer");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+const { isSwagger2 } = require("../utils/swagger");
+const { dumpYaml } = require("../utils/yaml");
+const { createZip } = require("../utils/zip");
+
+const LIBRARY_FILE = "components.yaml";
+const OUTPUT_FORMATS = ["zip", "report"];
+const MAX_SPECIFICATIONS = 20;
+const FETCH_CONCURRENCY = 4;
+const SCHEMA_REF = /^#\/components\/schemas\/([^/]+)(\/.*)?$/;
+// Waarden onder deze sleutels zijn data; een property die `$ref` heet is daar geen verwijzing.
+const DATA_KEYS = new Set(["example", "examples", "default", "const", "enum"]);
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+const isNonEmptyString = (value) => typeof value === "string" && value.trim().length > 0;
+
+const parseSchemaRef = (ref) => {
+  const match = typeof ref === "string" ? ref.match(SCHEMA_REF) : null;
+  return match ? { name: decodePointerSegment(match[1]), rest: match[2] ?? "" } : undefined;
+};
+
+const schemasOf = (spec) => (isObject(spec.components?.schemas) ? spec.components.schemas : {});
+
+/**
+ * Geeft per schema in `components.schemas` een canonieke tekst: sleutels gesorteerd en elke lokale
+ * `$ref` vervangen door de tekst van het doel. Twee schema's zijn structureel gelijk als hun tekst
+ * gelijk is, ook als ze in de ene specificatie `Adres` en in de andere `Postadres` heten. Een kringverwijzing
+ * wordt de afstand tot het schema op de stapel, zodat ook recursieve schema's te vergelijken zijn.
+ */
+const fingerprintSchemas = (spec) => {
+  const schemas = schemasOf(spec);
+  const memo = new Map();
+  const visit = (name, stack) => {
+    if (memo.has(name)) {
+      return { text: memo.get(name), reach: Number.POSITIVE_INFINITY };
+    }
+    const onStack = stack.indexOf(name);
+    if (onStack !== -1) {
+      return { text: `{"$cycle":${stack.length - onStack}}`, reach: onStack };
+    }
+    const depth = stack.length;
+    stack.push(name);
+    let reach = Number.POSITIVE_INFINITY;
+    const canonical = (value, followRefs) => {
+      if (Array.isArray(value)) {
+        return `[${value.map((item) => canonical(item, followRefs)).join(",")}]`;
+      }
+      if (!isObject(value)) {
+        return JSON.stringify(value);
+      }
+      const entries = Object.keys(value)
+        .sort()
+        .map((key) => {
+          const target = key === "$ref" && followRefs ? parseSchemaRef(value[key]) : undefined;
+          if (target && Object.hasOwn(schemas, target.name)) {
+            const result = visit(target.name, stack);
+            reach = Math.min(reach, result.reach);
+            return `"$ref":[${result.text},${JSON.stringify(target.rest)}]`;
+          }
+          return `${JSON.stringify(key)}:${canonical(value[key], followRefs && !DATA_KEYS.has(key))}`;
+        });
+      return `{${entries.join(",")}}`;
+    };
+    const text = canonical(schemas[name], true);
+    stack.pop();
+    if (reach >= depth) {
+      memo.set(name, text);
+    }
+    return { text, reach };
+  };
+  return new Map(Object.keys(schemas).map((name) => [name, visit(name, []).text]));
+};
+
+// De naam die het vaakst voorkomt; bij gelijke stand de eerst gevonden.
+const preferredName = (occurrences) => {
+  const counts = new Map();
+  for (const { schema } of occurrences) {
+    counts.set(schema, (counts.get(schema) ?? 0) + 1);
+  }
+  return [...counts.entries()].reduce((best, entry) => (entry[1] > best[1] ? entry : best))[0];
+};
+
+/**
+ * Zoekt schema's die in minstens `minSpecifications` specificaties structureel gelijk voorkomen. Elke groep
+ * krijgt een unieke naam in de bibliotheek. Een gedeeld schema verwijst alleen naar schema's die zelf ook
+ * gedeeld zijn: hun canonieke tekst zit in die van het verwijzende schema.
+ */
+const findSharedSchemas = (specs, { minSpecifications = 2 } = {}) => {
+  const groups = new Map();
+  specs.forEach((spec, index) => {
+    for (const [schema, text] of fingerprintSchemas(spec)) {
+      if (!groups.has(text)) {
+        groups.set(text, []);
+      }
+      groups.get(text).push({ index, schema });
+    }
+  });
+  const usedNames = new Set();
+  return [...groups.values()]
+    .filter((occurrences) => new Set(occurrences.map(({ index }) => index)).size >= minSpecifications)
+    .map((occurrences) => ({ name: uniqueFileName(usedNames, preferredName(occurrences)), occurrences }));
+};
+
+const rewriteRefs = (value, replace) => {
+  if (Array.isArray(value)) {
+    return value.map((child) => rewriteRefs(child, replace));
+  }
+  if (!isObject(value)) {
+    return value;
+  }
+  return Object.fromEntries(
+    Object.entries(value).map(([key, child]) => {
+      if (key === "$ref" && typeof child === "string") {
+        return [key, replace(child)];
+      }
+      return [key, DATA_KEYS.has(key) ? child : rewriteRefs(child, replace)];
+    }),
+  );
+};
+
+const refReplacer = (libraryNames, prefix) => (ref) => {
+  const target = parseSchemaRef(ref);
+  if (!target || !libraryNames.has(target.name)) {
+    return ref;
+  }
+  return `${prefix}#/components/schemas/${encodePointerSegment(libraryNames.get(target.name))}${target.rest}`;
+};
+
+/**
+ * Haalt de gedeelde schema's uit de specificaties: de bibliotheek krijgt één exemplaar per groep (uit de
+ * eerste specificatie waarin de groep voorkomt) en in elke specificatie verdwijnen de gedeelde schema's
+ * uit `components.schemas`, met elke verwijzing ernaar omgezet naar `components.yaml`.
+ */
+const extractLibrary = (specs, shared) => {
+  const libraryNames = specs.map(() => new Map());
+  for (const { name, occurrences } of shared) {
+    for (const { index, schema } of occurrences) {
+      libraryNames[index].set(schema, name);
+    }
+  }
+  const schemas = {};
+  for (const { name, occurrences } of shared) {
+    const { index, schema } = occurrences[0];
+    schemas[name] = rewriteRefs(schemasOf(specs[index])[schema], refReplacer(libraryNames[index], ""));
+  }
+  const rewritten = specs.map((spec, index) => {
+    const result = rewriteRefs(spec, refReplacer(libraryNames[index], LIBRARY_FILE));
+    const remaining = schemasOf(result);
+    for (const schema of libraryNames[index].keys()) {
+      delete remaining[schema];
+    }
+    if (isObject(result.components?.schemas) && Object.keys(remaining).length === 0) {
+      delete result.components.schemas;
+      if (Object.keys(result.components).length === 0) {
+        delete result.components;
+      }
+    }
+    return result;
+  });
+  return { schemas, specs: rewritten };
+};
+
+const resolveOutputFormat = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return "zip";
+  }
+  const normalized = String(value).toLowerCase();
+  if (!OUTPUT_FORMATS.includes(normalized)) {
+    throw Service.rejectResponse(
+      { message: `Onbekend outputFormat "${value}". Kies uit: ${OUTPUT_FORMATS.join(", ")}.` },
+      400,
+    );
+  }
+  return normalized;
+};
+
+const resolveSpecifications = async (input) => {
+  const items = Array.isArray(input?.specifications) ? input.specifications : [];
+  if (items.length < 2 || items.length > MAX_SPECIFICATIONS) {
+    throw Service.rejectResponse(
+      { message: `Geef in specifications tussen 2 en ${MAX_SPECIFICATIONS} specificaties mee.` },
+      400,
+    );
+  }
+  const resolved = await mapWithConcurrency(items, FETCH_CONCURRENCY, async (item, index) => {
+    if (!isNonEmptyString(item?.oasBody) && !isNonEmptyString(item?.oasUrl)) {
+      throw Service.rejectResponse({ message: `Geef voor specificatie ${index + 1} een oasUrl of oasBody mee.` }, 400);
+    }
+    const document = await resolveOasDocument({ oasBody: item.oasBody, oasUrl: item.oasUrl, headers: input.headers });
+    if (isSwagger2(document.spec)) {
+      throw Service.rejectResponse(
+        {
+          message: `Specificatie ${index + 1} is Swagger 2.0. Zet die eerst om met POST /v1/oas/convert.`,
+        },
+        400,
+      );
+    }
+    return { ...document, label: isNonEmptyString(item.name) ? item.name : document.spec.info?.title };
+  });
+  const versions = [...new Set(resolved.map(({ spec }) => String(spec.openapi ?? "").slice(0, 3)))];
+  if (versions.length > 1) {
+    throw Service.rejectResponse(
+      {
+        message: `De specificaties hebben verschillende OpenAPI-versies (${versions.join(", ")}).`,
+        detail: "Zet ze eerst om naar dezelfde versie met POST /v1/oas/convert.",
+      },
+      400,
+    );
+  }
+  const usedNames = new Set([LIBRARY_FILE.replace(/\.yaml$/, "")]);
+  return resolved.map((document) => ({
+    ...document,
+    name: uniqueFileName(usedNames, sanitizeFileName(document.label, { fallback: "openapi", lowercase: true })),
+  }));
+};
+
+/**
+ * Vergelijkt de schema's van meerdere specificaties van één organisatie en haalt de structureel gelijke
+ * naar een gedeeld `components.yaml`. De ZIP bevat dat bestand en elke specificatie (in het eigen formaat)
+ * met verwijzingen naar de bibliotheek; met `outputFormat=report` volgt alleen de lijst van gedeelde schema's.
+ */
+const extract = async (input, { outputFormat } = {}) => {
+  const format = resolveOutputFormat(outputFormat);
+  const minSpecifications = input.minSpecifications ?? 2;
+  if (!Number.isInteger(minSpecifications) || minSpecifications < 2) {
+    throw Service.rejectResponse({ message: "minSpecifications moet een geheel getal van minimaal 2 zijn." }, 400);
+  }
+  const documents = await resolveSpecifications(input);
+  const shared = findSharedSchemas(
+    documents.map(({ spec }) => spec),
+    { minSpecifications },
+  );
+  if (format === "report") {
+    const components = shared.map(({ name, occurrences }) => ({
+      name,
+      occurrences: occurrences.map(({ index, schema }) => ({ specification: documents[index].name, schema })),
+    }));
+    return {
+      headers: { "Content-Type": "application/json" },
+      rawBody: Buffer.from(JSON.stringify({ components }, null, 2), "utf8"),
+    };
+  }
+
+  const { schemas, specs } = extractLibrary(
+    documents.map(({ spec }) => spec),
+    shared,
+  );
+  const library = {
+    openapi: documents[0].spec.openapi,
+    info: { title: "Gedeelde componenten", version: "1.0.0" },
+    paths: {},
+    components: { schemas },
+  };
+  stampDocument(
+    library,
+    buildProvenance({
+      tool: "oas-shared-components",
+      source: [...new Set(documents.map(({ source }) => source))].join(", "),
+      details: { specifications: documents.length },
+    }),
+  );
+  const files = [{ name: LIBRARY_FILE, data: dumpYaml(library) }];
+  specs.forEach((spec, index) => {
+    const { name, format: specFormat, source } = documents[index];
+    stampDocument(spec, buildProvenance({ tool: "oas-shared-components", source }));
+    files.push(
+      specFormat === "json"
+        ? { name: `${name}.json`, data: JSON.stringify(spec, null, 2) }
+        : { name: `${name}.yaml`, data: dumpYaml(spec) },
+    );
+  });
+  return {
+    headers: {
+      "Content-Type": "application/zip",
+      "Content-Disposition": 'attachment; filename="shared-components.zip"',
+      "X-Shared-Components": String(shared.length),
+    },
+    rawBody: createZip(files.map((file) => ({ ...file, name: `shared-components/${file.name}` }))),
+  };
+};
+
+module.exports = {
+  extract,
+  extractLibrary,
+  findSharedSchemas,
+  fingerprintSchemas,
+  refReplacer,
+  rewriteRefs,
+};
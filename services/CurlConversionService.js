@@ -0,0 +1,116 @@
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { sanitizeFileName } = require("../utils/fileName");
+const { buildJsonRequestSample, parameterExample, resolveAuthScheme } = require("../utils/openapi");
+const {
+  BODY_DELIMITER,
+  SHELL_OUTPUT_FORMATS,
+  acceptsJson,
+  buildShellScripts,
+  buildShellUrl,
+  escapeDoubleQuoted,
+  withHeredocBody,
+} = require("../utils/shellScript");
+const { createZip } = require("../utils/zip");
+
+const DEFAULT_COLLECTION_NAME = "curl-scripts";
+
+/**
+ * Eén curl-aanroep voor een operatie, met verplichte query- en headerparameters, authenticatie uit
+ * omgevingsvariabelen en een voorbeeld-body via een heredoc (zodat JSON niet ge-escaped hoeft te worden).
+ */
+const renderCommand = (document, entry) => {
+  const { path, method, operation, parameters, requestBody } = entry;
+  const scheme = resolveAuthScheme(document, operation);
+  const sample = buildJsonRequestSample(document, requestBody);
+  const url = buildShellUrl(document, path, parameters);
+  const args = [`curl --fail-with-body -sS -X ${method.toUpperCase()} "${url}"`];
+  if (acceptsJson(operation)) {
+    args.push('-H "Accept: application/json"');
+  }
+  for (const parameter of parameters.filter((item) => item.in === "header" && item.required === true)) {
+    args.push(`-H "${escapeDoubleQuoted(`${parameter.name}: ${parameterExample(document, parameter)}`)}"`);
+  }
+  if (scheme?.type === "bearer") {
+    args.push('-H "Authorization: Bearer ${TOKEN}"');
+  }
+  if (scheme?.type === "basic") {
+    args.push('-u "${USERNAME}:${PASSWORD}"');
+  }
+  if (scheme?.type === "apiKey") {
+    args.push(`-H "${escapeDoubleQuoted(scheme.header)}: \${API_KEY}"`);
+  }
+  if (sample === undefined) {
+    return args.join(" \\\n  ");
+  }
+  args.push('-H "Content-Type: application/json"', `--data-binary @- <<'${BODY_DELIMITER}'`);
+  return withHeredocBody(args.join(" \\\n  "), sample);
+};
+
+/**
+ * Zet een OpenAPI document om naar curl-scripts: standaard één bash-script met een functie per
+ * operatie, of met `outputFormat=zip` een ZIP met een uitvoerbaar script per operatie.
+ */
+const buildCurlScripts = (document, { source, outputFormat = "script" } = {}) => {
+  const title = typeof document.info?.title === "string" && document.info.title.trim();
+  return {
+    collectionName: title || DEFAULT_COLLECTION_NAME,
+    ...buildShellScripts(document, { source, tool: "oas-curl", outputFormat, renderCommand }),
+  };
+};
+
+const resolveOutputFormat = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return "script";
+  }
+  const normalized = String(value).toLowerCase();
+  if (!SHELL_OUTPUT_FORMATS.includes(normalized)) {
+    throw Service.rejectResponse(
+      { message: `Onbekend outputFormat "${value}". Kies uit: ${SHELL_OUTPUT_FORMATS.join(", ")}.` },
+      400,
+    );
+  }
+  return normalized;
+};
+
+const convert = async (input, { outputFormat } = {}) => {
+  const format = resolveOutputFormat(outputFormat);
+  const resolved = await resolveOasDocument(input);
+  let result;
+  try {
+    result = buildCurlScripts(resolved.spec, { source: resolved.source, outputFormat: format });
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Genereren van curl-scripts is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const filenameBase = sanitizeFileName(result.collectionName, {
+    fallback: DEFAULT_COLLECTION_NAME,
+    lowercase: true,
+  });
+  if (format === "zip") {
+    return {
+      headers: {
+        "Content-Type": "application/zip",
+        "Content-Disposition": `attachment; filename="${filenameBase}-curl.zip"`,
+      },
+      rawBody: createZip(result.files.map((file) => ({ ...file, name: `${filenameBase}/${file.name}` }))),
+    };
+  }
+  return {
+    headers: {
+      "Content-Type": "text/x-shellscript; charset=utf-8",
+      "Content-Disposition": `attachment; filename="${filenameBase}.sh"`,
+    },
+    rawBody: Buffer.from(result.script, "utf8"),
+  };
+};
+
+module.exports = {
+  buildCurlScripts,
+  convert,
+};
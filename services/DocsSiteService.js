@@ -0,0 +1,130 @@
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { sanitizeFileName } = require("../utils/fileName");
+const { buildProvenance, describeProvenance, stampDocument } = require("../utils/provenance");
+const { createZip } = require("../utils/zip");
+
+const DEFAULT_FILENAME = "openapi";
+const DEFAULT_RENDERER = "swagger-ui";
+const CDN_BASE_URL = "https://cdn.jsdelivr.net/npm";
+
+// Vastgepind op de major-versie; jsDelivr levert daarbinnen de laatste release.
+const RENDERERS = {
+  "swagger-ui": {
+    styles: [`${CDN_BASE_URL}/swagger-ui-dist@5/swagger-ui.css`],
+    scripts: [`${CDN_BASE_URL}/swagger-ui-dist@5/swagger-ui-bundle.js`],
+    body: ['<div id="swagger-ui"></div>'],
+    init: [
+      "window.ui = SwaggerUIBundle({",
+      '  dom_id: "#swagger-ui",',
+      "  spec: window.OPENAPI_SPEC,",
+      "  deepLinking: true,",
+      "});",
+    ],
+  },
+  elements: {
+    styles: [`${CDN_BASE_URL}/@stoplight/elements@8/styles.min.css`],
+    scripts: [`${CDN_BASE_URL}/@stoplight/elements@8/web-components.min.js`],
+    body: ['<elements-api id="docs" router="hash" layout="sidebar"></elements-api>'],
+    init: ['document.getElementById("docs").apiDescriptionDocument = window.OPENAPI_SPEC;'],
+  },
+};
+
+const escapeHtml = (value) =>
+  String(value ?? "")
+    .replace(/&/g, "&amp;")
+    .replace(/</g, "&lt;")
+    .replace(/>/g, "&gt;")
+    .replace(/"/g, "&quot;");
+
+const resolveRenderer = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return DEFAULT_RENDERER;
+  }
+  const renderer = String(value).toLowerCase();
+  if (!RENDERERS[renderer]) {
+    throw Service.rejectResponse(
+      { message: `Ongeldige waarde voor renderer: "${value}". Kies uit: ${Object.keys(RENDERERS).join(", ")}.` },
+      400,
+    );
+  }
+  return renderer;
+};
+
+const renderIndex = (renderer, title, provenance) => {
+  const { styles, scripts, body, init } = RENDERERS[renderer];
+  return [
+    "<!DOCTYPE html>",
+    `<!-- ${describeProvenance(provenance).replace(/--/g, "- -")} -->`,
+    '<html lang="nl">',
+    "  <head>",
+    '    <meta charset="utf-8">',
+    '    <meta name="viewport" content="width=device-width, initial-scale=1">',
+    `    <title>${escapeHtml(title)}</title>`,
+    ...styles.map((href) => `    <link rel="stylesheet" href="${href}">`),
+    "  </head>",
+    "  <body>",
+    ...body.map((line) => `    ${line}`),
+    ...scripts.map((src) => `    <script src="${src}"></script>`),
+    '    <script src="spec.js"></script>',
+    "    <script>",
+    ...init.map((line) => `      ${line}`),
+    "    </script>",
+    "  </body>",
+    "</html>",
+    "",
+  ].join("\n");
+};
+
+/**
+ * Statische documentatiesite voor een OpenAPI document: `index.html` met Swagger UI of Stoplight
+ * Elements (`renderer`), `spec.js` met de specificatie en `openapi.json` om te downloaden. De
+ * specificatie komt uit `spec.js` en niet via een fetch, zodat de site ook vanaf schijf werkt; de
+ * renderer zelf wordt van jsDelivr geladen.
+ */
+const buildSite = (document, { renderer = DEFAULT_RENDERER, source } = {}) => {
+  const provenance = buildProvenance({ tool: `oas-${renderer}`, source });
+  const spec = stampDocument(document, provenance);
+  const title = (typeof spec.info?.title === "string" && spec.info.title.trim()) || DEFAULT_FILENAME;
+  const json = JSON.stringify(spec, null, 2);
+  return {
+    title,
+    files: [
+      { name: "index.html", data: renderIndex(renderer, title, provenance) },
+      { name: "spec.js", data: `window.OPENAPI_SPEC = ${json};\n` },
+      { name: "openapi.json", data: `${json}\n` },
+    ],
+  };
+};
+
+const convert = async (input) => {
+  const renderer = resolveRenderer(input?.renderer);
+  const resolved = await resolveOasDocument(input);
+  let site;
+  try {
+    site = buildSite(resolved.spec, { renderer, source: resolved.source });
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Genereren van de documentatiesite is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const filenameBase = `${sanitizeFileName(site.title, { fallback: DEFAULT_FILENAME, lowercase: true })}-${renderer}`;
+  const entries = site.files.map((file) => ({ ...file, name: `${filenameBase}/${file.name}` }));
+
+  return {
+    headers: {
+      "Content-Type": "application/zip",
+      "Content-Disposition": `attachment; filename="${filenameBase}.zip"`,
+    },
+    rawBody: createZip(entries),
+  };
+};
+
+module.exports = {
+  buildSite,
+  convert,
+};
@@ -0,0 +1,474 @@
+const Service = require("./Service");
+const { resolveOasDocument, serializeOasDocument } = require("./OasInputService");
+const { sanitizeFileName } = require("../utils/fileName");
+const { collectOperations, resolveRef } = require("../utils/openapi");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+
+const DEFAULT_FILENAME = "openapi";
+const OUTPUT_FORMATS = ["map", "spec"];
+const MAX_DEPTH = 8;
+const LOCAL_REF_PREFIX = "#/";
+const DEFAULT_STRING = "voorbeeld";
+// Volgorde waarin tekens voor een tekenklasse uit een pattern worden geprobeerd.
+const CLASS_CANDIDATES = [
+  ..."abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789",
+  ..." -_.,:;/+=@#!?*()[]{}<>'\"&%$^~`|\\",
+];
+
+const FORMAT_EXAMPLES = {
+  "date-time": "2024-01-15T09:30:00Z",
+  date: "2024-01-15",
+  time: "09:30:00",
+  duration: "P1D",
+  email: "info@example.nl",
+  "idn-email": "info@example.nl",
+  uri: "https://www.example.nl",
+  url: "https://www.example.nl",
+  iri: "https://www.example.nl",
+  "uri-reference": "/voorbeeld",
+  "iri-reference": "/voorbeeld",
+  "uri-template": "https://www.example.nl/{id}",
+  uuid: "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+  hostname: "api.example.nl",
+  "idn-hostname": "api.example.nl",
+  ipv4: "192.0.2.1",
+  ipv6: "2001:db8::1",
+  byte: "dm9vcmJlZWxk",
+  binary: "",
+  password: "geheim",
+  "json-pointer": "/voorbeeld",
+  regex: "^[a-z]+$",
+};
+
+// Voorbeeldwaarden op basis van de propertynaam, voor strings zonder format, enum of pattern.
+const NAMED_STRINGS = [
+  [/e-?mail/i, "info@example.nl"],
+  [/(telefoon|phone|mobiel)/i, "+31 70 123 4567"],
+  [/(postcode|zip)/i, "2511 AB"],
+  [/(woonplaats|plaats|city|gemeente)/i, "Den Haag"],
+  [/(straat|street)/i, "Lange Voorhout"],
+  [/(^|_)(land|country)(code)?$/i, "NL"],
+  [/iban/i, "NL91ABNA0417164300"],
+  [/(voornaam|first_?name|given_?name)/i, "Jan"],
+  [/(achternaam|last_?name|family_?name|surname)/i, "Jansen"],
+  [/(url|website|href|link)$/i, "https://www.example.nl"],
+  [/(naam|name)$/i, "Jan Jansen"],
+  [/(omschrijving|beschrijving|description)$/i, "Een korte omschrijving."],
+];
+
+const NAMED_NUMBERS = [
+  [/huisnummer|house_?number/i, 12],
+  [/(aantal|count|total)$/i, 10],
+  [/(jaar|year)$/i, 2024],
+];
+
+const parseQuantifier = (pattern, state) => {
+  const char = pattern[state.index];
+  let min = 1;
+  let max = 1;
+  if (char === "*" || char === "+" || char === "?") {
+    state.index += 1;
+    min = char === "+" ? 1 : 0;
+    max = char === "?" ? 1 : Number.POSITIVE_INFINITY;
+  } else if (char === "{") {
+    const match = /^\{(\d+)(,(\d*))?\}/.exec(pattern.slice(state.index));
+    if (!match) {
+      return { min, max };
+    }
+    state.index += match[0].length;
+    min = Number(match[1]);
+    max = match[2] === undefined ? min : match[3] === "" ? Number.POSITIVE_INFINITY : Number(match[3]);
+  } else {
+    return { min, max };
+  }
+  if (pattern[state.index] === "?") {
+    state.index += 1;
+  }
+  return { min, max };
+};
+
+const ESCAPES = { d: "1", D: "a", w: "a", W: "-", s: " ", S: "a", t: "\t", n: "\n", r: "\r", b: "", B: "" };
+
+const parseClass = (pattern, state) => {
+  const start = state.index;
+  state.index += 1;
+  if (pattern[state.index] === "^") {
+    state.index += 1;
+  }
+  if (pattern[state.index] === "]") {
+    state.index += 1;
+  }
+  while (state.index < pattern.length && pattern[state.index] !== "]") {
+    state.index += pattern[state.index] === "\\" ? 2 : 1;
+  }
+  state.index += 1;
+  const matcher = new RegExp(`^${pattern.slice(start, state.index)}$`, "u");
+  const candidate = CLASS_CANDIDATES.find((char) => matcher.test(char));
+  if (candidate === undefined) {
+    throw new Error("Geen teken gevonden voor tekenklasse.");
+  }
+  return candidate;
+};
+
+const parseAtom = (pattern, state) => {
+  const char = pattern[state.index];
+  if (char === "^" || char === "$") {
+    state.index += 1;
+    return "";
+  }
+  if (char === "(") {
+    state.index += 1;
+    const lookaround = /^\?(=|!|<=|<!)/.exec(pattern.slice(state.index));
+    const group = /^\?(:|<[^>]+>)/.exec(pattern.slice(state.index));
+    if (lookaround || group) {
+      state.index += (lookaround || group)[0].length;
+    }
+    const value = parseAlternation(pattern, state);
+    state.index += 1;
+    return lookaround ? "" : value;
+  }
+  if (char === "[") {
+    return parseClass(pattern, state);
+  }
+  if (char === "\\") {
+    const escaped = pattern[state.index + 1];
+    state.index += 2;
+    if (escaped === "u" && /^[0-9a-fA-F]{4}/.test(pattern.slice(state.index))) {
+      state.index += 4;
+      return String.fromCharCode(Number.parseInt(pattern.slice(state.index - 4, state.index), 16));
+    }
+    if (/[1-9]/.test(escaped)) {
+      throw new Error("Terugverwijzingen worden niet ondersteund.");
+    }
+    return ESCAPES[escaped] ?? escaped;
+  }
+  state.index += 1;
+  return char === "." ? "a" : char;
+};
+
+const parseSequence = (pattern, state) => {
+  let value = "";
+  while (state.index < pattern.length && pattern[state.index] !== "|" && pattern[state.index] !== ")") {
+    const atom = parseAtom(pattern, state);
+    const { min, max } = parseQuantifier(pattern, state);
+    value += atom.repeat(min > 0 ? min : Math.min(max, 1));
+  }
+  return value;
+};
+
+// Kiest bij een alternatie altijd het eerste alternatief; de overige worden alleen overgeslagen.
+const parseAlternation = (pattern, state) => {
+  const first = parseSequence(pattern, state);
+  while (pattern[state.index] === "|") {
+    state.index += 1;
+    parseSequence(pattern, state);
+  }
+  return first;
+};
+
+/**
+ * Maakt een string die aan een (ECMAScript) pattern voldoet: per tekenklasse het eerste passende
+ * teken, per quantifier het minimum (maar minstens één herhaling als dat mag). Lukt dat niet, of
+ * voldoet het resultaat niet, dan is de uitkomst undefined.
+ */
+const sampleFromPattern = (pattern) => {
+  try {
+    const value = parseAlternation(pattern, { index: 0 });
+    return new RegExp(pattern, "u").test(value) ? value : undefined;
+  } catch {
+    return undefined;
+  }
+};
+
+const fitLength = (value, schema) => {
+  let result = value;
+  if (Number.isInteger(schema.maxLength) && result.length > schema.maxLength) {
+    result = result.slice(0, schema.maxLength);
+  }
+  if (Number.isInteger(schema.minLength) && result.length < schema.minLength) {
+    result = result.padEnd(schema.minLength, "x");
+  }
+  return result;
+};
+
+const sampleString = (schema, name) => {
+  if (typeof schema.pattern === "string") {
+    const value = sampleFromPattern(schema.pattern);
+    if (value !== undefined) {
+      return value;
+    }
+  }
+  if (Object.hasOwn(FORMAT_EXAMPLES, schema.format)) {
+    return FORMAT_EXAMPLES[schema.format];
+  }
+  const named = NAMED_STRINGS.find(([pattern]) => pattern.test(name || ""));
+  return fitLength(named ? named[1] : DEFAULT_STRING, schema);
+};
+
+// OpenAPI 3.0 kent `exclusiveMinimum: true` naast `minimum`; 3.1 (JSON Schema) een getal als grens.
+const numericBound = (limit, exclusive, step) => {
+  if (typeof exclusive === "number") {
+    return exclusive + step;
+  }
+  if (typeof limit === "number") {
+    return exclusive === true ? limit + step : limit;
+  }
+  return undefined;
+};
+
+const sampleNumber = (schema, type, name) => {
+  const named = NAMED_NUMBERS.find(([pattern]) => pattern.test(name || ""));
+  const step = type === "integer" ? 1 : 0.5;
+  const lower = numericBound(schema.minimum, schema.exclusiveMinimum, step);
+  const upper = numericBound(schema.maximum, schema.exclusiveMaximum, -step);
+  let value = named ? named[1] : 1;
+  if (lower !== undefined && value < lower) {
+    value = lower;
+  }
+  if (upper !== undefined && value > upper) {
+    value = upper;
+  }
+  if (typeof schema.multipleOf === "number" && schema.multipleOf > 0) {
+    value = Math.ceil(value / schema.multipleOf) * schema.multipleOf;
+  }
+  return type === "integer" ? Math.round(value) : value;
+};
+
+const schemaType = (schema) => {
+  if (Array.isArray(schema.type)) {
+    return schema.type.find((type) => type !== "null");
+  }
+  if (schema.type) {
+    return schema.type;
+  }
+  if (schema.properties || schema.additionalProperties) {
+    return "object";
+  }
+  return schema.items ? "array" : undefined;
+};
+
+/**
+ * Bouwt een realistisch voorbeeld uit een schema. Een `example`, `examples`, `const`, `default` of
+ * enum gaat voor; verder worden formats, patterns, lengtes, grenzen en `minItems` gerespecteerd en
+ * geeft de propertynaam een hint (`email`, `postcode`, `huisnummer`, ...). Alle properties komen in
+ * het voorbeeld, behalve `skip` (bijvoorbeeld `readOnly` voor een request body). Een schema dat zichzelf
+ * (via `$ref`) bevat wordt op die plek weggelaten.
+ */
+const generateExample = (document, rawSchema, { name, skip, refs = [] } = {}) => {
+  const ref = typeof rawSchema?.$ref === "string" ? rawSchema.$ref : undefined;
+  if (ref && (refs.includes(ref) || !ref.startsWith(LOCAL_REF_PREFIX))) {
+    return undefined;
+  }
+  const schema = resolveRef(document, rawSchema);
+  if (!schema || typeof schema !== "object" || refs.length > MAX_DEPTH) {
+    return undefined;
+  }
+  const path = ref ? [...refs, ref] : refs;
+  if (schema.example !== undefined) {
+    return schema.example;
+  }
+  if (Array.isArray(schema.examples) && schema.examples.length > 0) {
+    return schema.examples[0];
+  }
+  if (schema.const !== undefined) {
+    return schema.const;
+  }
+  if (schema.default !== undefined) {
+    return schema.default;
+  }
+  if (Array.isArray(schema.enum) && schema.enum.length > 0) {
+    return schema.enum.find((value) => value !== null) ?? schema.enum[0];
+  }
+  if (Array.isArray(schema.allOf) && schema.allOf.length > 0) {
+    const parts = schema.allOf.map((part) => generateExample(document, part, { name, skip, refs: path }));
+    const { allOf: _allOf, ...rest } = schema;
+    const own = Object.keys(rest).length > 0 ? generateExample(document, rest, { name, skip, refs: path }) : undefined;
+    const objects = [...parts, own].filter((part) => part && typeof part === "object" && !Array.isArray(part));
+    return objects.length > 0 ? Object.assign({}, ...objects) : parts.find((part) => part !== undefined);
+  }
+  const variant = schema.oneOf?.[0] ?? schema.anyOf?.[0];
+  if (variant) {
+    return generateExample(document, variant, { name, skip, refs: path });
+  }
+  const type = schemaType(schema);
+  if (type === "object") {
+    const example = {};
+    for (const [property, propertySchema] of Object.entries(schema.properties || {})) {
+      if (skip && resolveRef(document, propertySchema)?.[skip]) {
+        continue;
+      }
+      const value = generateExample(document, propertySchema, { name: property, skip, refs: path });
+      if (value !== undefined) {
+        example[property] = value;
+      }
+    }
+    const additional = schema.additionalProperties;
+    if (Object.keys(example).length === 0 && additional && typeof additional === "object") {
+      const value = generateExample(document, additional, { skip, refs: path });
+      if (value !== undefined) {
+        example.sleutel = value;
+      }
+    }
+    return example;
+  }
+  if (type === "array") {
+    const item = generateExample(document, schema.items, { name, skip, refs: path });
+    const count = Math.max(Number.isInteger(schema.minItems) ? schema.minItems : 1, 1);
+    return item === undefined ? [] : Array.from({ length: count }, () => item);
+  }
+  if (type === "integer" || type === "number") {
+    return sampleNumber(schema, type, name);
+  }
+  if (type === "boolean") {
+    return true;
+  }
+  if (type === "string") {
+    return sampleString(schema, name);
+  }
+  return undefined;
+};
+
+const resolveOutputFormat = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return "map";
+  }
+  const normalized = String(value).toLowerCase();
+  if (!OUTPUT_FORMATS.includes(normalized)) {
+    throw Service.rejectResponse(
+      { message: `Onbekend outputFormat "${value}". Kies uit: ${OUTPUT_FORMATS.join(", ")}.` },
+      400,
+    );
+  }
+  return normalized;
+};
+
+/**
+ * Voorbeelden voor alle schema's in `components.schemas`, op naam.
+ */
+const buildExampleMap = (document) => {
+  const examples = {};
+  for (const name of Object.keys(document.components?.schemas || {})) {
+    const pointer = name.replace(/~/g, "~0").replace(/\//g, "~1");
+    const value = generateExample(document, { $ref: `#/components/schemas/${pointer}` });
+    if (value !== undefined) {
+      examples[name] = value;
+    }
+  }
+  return examples;
+};
+
+const hasExample = (target) =>
+  target.example !== undefined || (target.examples && Object.keys(target.examples).length > 0);
+
+const injectMediaExamples = (document, content, skip) => {
+  let inserted = 0;
+  for (const media of Object.values(content || {})) {
+    if (!media || typeof media !== "object" || hasExample(media) || !media.schema) {
+      continue;
+    }
+    const value = generateExample(document, media.schema, { skip });
+    if (value !== undefined) {
+      media.example = value;
+      inserted += 1;
+    }
+  }
+  return inserted;
+};
+
+/**
+ * Zet een voorbeeld op elk media type van een request body of response dat er nog geen heeft: in de
+ * operaties onder `paths` en `webhooks` en in `components.requestBodies` en `components.responses`.
+ * Schema's blijven ongemoeid. Geeft het aantal toegevoegde voorbeelden terug.
+ */
+const injectContentExamples = (document) => {
+  let inserted = 0;
+  // collectOperations kijkt alleen naar `paths`; webhooks hebben dezelfde vorm.
+  const operations = [...collectOperations(document), ...collectOperations({ ...document, paths: document.webhooks })];
+  for (const { operation, requestBody } of operations) {
+    inserted += injectMediaExamples(document, requestBody?.content, "readOnly");
+    for (const response of Object.values(operation.responses || {})) {
+      inserted += injectMediaExamples(document, resolveRef(document, response)?.content, "writeOnly");
+    }
+  }
+  for (const requestBody of Object.values(document.components?.requestBodies || {})) {
+    inserted += injectMediaExamples(document, resolveRef(document, requestBody)?.content, "readOnly");
+  }
+  for (const response of Object.values(document.components?.responses || {})) {
+    inserted += injectMediaExamples(document, resolveRef(document, response)?.content, "writeOnly");
+  }
+  return inserted;
+};
+
+/**
+ * Zet voorbeelden in de specificatie waar ze ontbreken: op de media types van request bodies en
+ * responses en op elk schema in `components.schemas` (`example`, of `examples` bij OpenAPI 3.1).
+ * Bestaande voorbeelden blijven staan. Geeft het aantal toegevoegde voorbeelden terug.
+ */
+const injectExamples = (document) => {
+  const isOas31 = String(document.openapi || "").startsWith("3.1");
+  // Eerst de bodies: een voorbeeld op een componentschema zou anders ook readOnly-velden in
+  // request bodies opleveren.
+  let inserted = injectContentExamples(document);
+  for (const [name, value] of Object.entries(buildExampleMap(document))) {
+    const schema = document.components.schemas[name];
+    if (!schema || typeof schema !== "object" || schema.$ref || hasExample(schema)) {
+      continue;
+    }
+    if (isOas31) {
+      schema.examples = [value];
+    } else {
+      schema.example = value;
+    }
+    inserted += 1;
+  }
+  return inserted;
+};
+
+const isTrue = (value) => value === true || String(value).toLowerCase() === "true";
+
+/**
+ * Genereert voorbeelden voor de schema's van een specificatie: als JSON-map van schemanaam naar
+ * voorbeeld (`outputFormat=map`, standaard) of als specificatie met de voorbeelden erin
+ * (`outputFormat=spec`), in het formaat van de input. `writeBack` geeft ook de specificatie terug,
+ * maar vult alleen de request- en responsebodies aan.
+ */
+const convert = async (input, { outputFormat, writeBack, responseFormat } = {}) => {
+  const writeBackOnly = isTrue(writeBack);
+  const format = writeBackOnly ? "spec" : resolveOutputFormat(outputFormat);
+  if (writeBackOnly && outputFormat && String(outputFormat).toLowerCase() !== "spec") {
+    throw Service.rejectResponse(
+      {
+        message: `writeBack=true geeft de specificatie terug en gaat niet samen met outputFormat "${outputFormat}".`,
+      },
+      400,
+    );
+  }
+  const resolved = await resolveOasDocument(input);
+  const { spec, source } = resolved;
+  const title = (typeof spec.info?.title === "string" && spec.info.title.trim()) || DEFAULT_FILENAME;
+  const filenameBase = `${sanitizeFileName(title, { fallback: DEFAULT_FILENAME, lowercase: true })}-examples`;
+  if (format === "spec") {
+    const inserted = writeBackOnly ? injectContentExamples(spec) : injectExamples(spec);
+    const details = writeBackOnly ? { writeBack: true } : {};
+    stampDocument(spec, buildProvenance({ tool: "oas-examples", source, details }));
+    const result = serializeOasDocument(spec, responseFormat ?? resolved.format, filenameBase);
+    result.headers["X-Inserted-Examples"] = String(inserted);
+    return result;
+  }
+  return {
+    headers: {
+      "Content-Type": "application/json",
+      "Content-Disposition": `attachment; filename="${filenameBase}.json"`,
+    },
+    rawBody: Buffer.from(`${JSON.stringify(buildExampleMap(spec), null, 2)}\n`, "utf8"),
+  };
+};
+
+module.exports = {
+  buildExampleMap,
+  convert,
+  generateExample,
+  injectContentExamples,
+  injectExamples,
+  sampleFromPattern,
+};
@@ -0,0 +1,247 @@
+const Service = require("./Service");
+const { resolveOasInput } = require("./OasInputService");
+const { sanitizeFileName } = require("../utils/fileName");
+const { buildOasSkeleton, splitRequestUrl } = require("../utils/oasSkeleton");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+
+const DEFAULT_TITLE = "har-capture";
+const INVALID_HAR_ERROR = "Geen geldig HAR-bestand: verwacht een JSON-object met log.entries.";
+const NO_API_REQUESTS_ERROR = "Het HAR-bestand bevat geen API-verzoeken om een specificatie uit af te leiden.";
+
+// Browsers leggen ook pagina's, scripts, stylesheets, afbeeldingen en fonts vast; die horen niet bij de API.
+const API_RESOURCE_TYPES = new Set(["xhr", "fetch"]);
+const ASSET_MIME_TYPE = /^(text\/(html|css|javascript)|application\/(x-)?javascript|(image|font|video|audio)\/)/i;
+
+// Headers die de browser zelf zet; als parameter zouden ze alleen ruis in de specificatie zijn.
+const BROWSER_HEADER =
+  /^(:|sec-|accept-|if-)|^(cache-control|dnt|origin|pragma|priority|referer|te|upgrade-insecure-requests)$/i;
+const SECRET_HEADER = /api[-_]?key|token|secret/i;
+
+const UUID_SEGMENT = /^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/i;
+const HEX_SEGMENT = /^[0-9a-f]{16,}$/i;
+const BASE_PATH_SEGMENT = /^(api|v\d+(\.\d+)*)$/i;
+
+/**
+ * Herkent padsegmenten die een identificatie lijken (getallen, UUID's, lange hexadecimale of
+ * gemengde codes met cijfers) en maakt er een padparameter van, genoemd naar het segment ervoor
+ * (`/dieren/42` wordt `/dieren/{dierenId}`).
+ */
+const templatePath = (path) => {
+  const used = new Set();
+  const pathParameters = [];
+  const segments = path
+    .split("/")
+    .filter((segment) => segment !== "")
+    .map((segment, index, all) => {
+      const value = decodeURIComponent(segment);
+      const looksLikeId =
+        /^\d+$/.test(value) ||
+        UUID_SEGMENT.test(value) ||
+        HEX_SEGMENT.test(value) ||
+        (value.length >= 8 && /\d/.test(value) && /^[A-Za-z0-9_-]+$/.test(value) && !/^v\d+$/i.test(value));
+      if (!looksLikeId) {
+        return segment;
+      }
+      const previous = index > 0 ? all[index - 1].replace(/[^A-Za-z0-9]/g, "") : "";
+      let name = previous && !/^\d/.test(previous) ? `${previous}Id` : "id";
+      for (let counter = 2; used.has(name); counter += 1) {
+        name = `${name.replace(/\d+$/, "")}${counter}`;
+      }
+      used.add(name);
+      pathParameters.push({ name, value });
+      return `{${name}}`;
+    });
+  return { path: `/${segments.join("/")}`, pathParameters };
+};
+
+/**
+ * Basispad per origin: de leidende segmenten als `/api` en `/v1` die alle verzoeken naar die origin
+ * delen. Dat pad hoort bij de server-URL en niet bij de paden.
+ */
+const resolveBasePaths = (locations) => {
+  const basePaths = new Map();
+  for (const { server, path } of locations) {
+    const segments = path.split("/").filter((segment) => segment !== "");
+    const current = basePaths.get(server);
+    const candidate = current === undefined ? segments : current;
+    const shared = [];
+    for (let index = 0; index < candidate.length && index < segments.length - 1; index += 1) {
+      if (candidate[index] !== segments[index] || !BASE_PATH_SEGMENT.test(segments[index])) {
+        break;
+      }
+      shared.push(segments[index]);
+    }
+    basePaths.set(server, shared);
+  }
+  return basePaths;
+};
+
+const headerValue = (headers, name) =>
+  (Array.isArray(headers) ? headers : []).find((header) => String(header.name).toLowerCase() === name)?.value;
+
+const mimeTypeOf = (value) => String(value || "").split(";")[0].trim().toLowerCase();
+
+const isApiEntry = (entry) => {
+  const resourceType = entry._resourceType;
+  if (typeof resourceType === "string") {
+    return API_RESOURCE_TYPES.has(resourceType.toLowerCase());
+  }
+  return !ASSET_MIME_TYPE.test(mimeTypeOf(entry.response?.content?.mimeType));
+};
+
+const parseBody = (mimeType, text) => {
+  if (/json/i.test(mimeType)) {
+    try {
+      return JSON.parse(text);
+    } catch {
+      return text;
+    }
+  }
+  return text;
+};
+
+const resolveRequestBody = (postData) => {
+  if (!postData) {
+    return undefined;
+  }
+  const contentType = mimeTypeOf(postData.mimeType) || "application/json";
+  if (Array.isArray(postData.params) && postData.params.length > 0) {
+    return {
+      contentType,
+      fields: postData.params.map((param) => ({ name: param.name, value: param.value, file: Boolean(param.fileName) })),
+    };
+  }
+  if (typeof postData.text !== "string" || postData.text.trim() === "") {
+    return undefined;
+  }
+  return { contentType, value: parseBody(contentType, postData.text) };
+};
+
+const resolveResponse = (response) => {
+  const content = response?.content || {};
+  const contentType = mimeTypeOf(content.mimeType);
+  let text = typeof content.text === "string" ? content.text : "";
+  if (content.encoding === "base64") {
+    text = Buffer.from(text, "base64").toString("utf8");
+  }
+  return {
+    status: response?.status,
+    description: response?.statusText || undefined,
+    body: text.trim() === "" || !contentType ? undefined : { contentType, value: parseBody(contentType, text) },
+  };
+};
+
+/**
+ * Authenticatie uit de vastgelegde headers. Waarden van `Authorization` en headers die op een
+ * sleutel of token lijken komen niet in de specificatie terecht.
+ */
+const resolveSecurity = (headers) => {
+  const authorization = String(headerValue(headers, "authorization") || "");
+  if (/^bearer\s/i.test(authorization)) {
+    return { type: "bearer" };
+  }
+  if (/^basic\s/i.test(authorization)) {
+    return { type: "basic" };
+  }
+  const apiKey = (Array.isArray(headers) ? headers : []).find((header) => SECRET_HEADER.test(header.name));
+  return apiKey ? { type: "apiKey", in: "header", name: apiKey.name } : undefined;
+};
+
+/**
+ * Leidt een OpenAPI 3.0 skelet af uit een HAR-opname (bijvoorbeeld uit de developer tools van de
+ * browser). Alleen API-verzoeken tellen mee: XHR/fetch, of bij opnames zonder resourcetype alles
+ * behalve pagina's en statische bestanden. Concrete paden worden templates met padparameters,
+ * verzoeken naar hetzelfde pad worden één operatie en de schema's van alle voorbeelden worden
+ * samengevoegd. Elke origin wordt een server, met een gedeeld basispad als `/v1`.
+ */
+const buildOpenApiDocument = (har, { source } = {}) => {
+  const entries = har.log.entries.filter(
+    (entry) => entry?.request?.url && String(entry.request.method).toUpperCase() !== "OPTIONS" && isApiEntry(entry),
+  );
+  if (entries.length === 0) {
+    throw Service.rejectResponse({ message: NO_API_REQUESTS_ERROR }, 400);
+  }
+  const locations = entries.map(({ request }) => splitRequestUrl(request.url));
+  const basePaths = resolveBasePaths(locations);
+  const servers = [];
+  const operations = entries.map(({ request, response }, index) => {
+    const { server, path: fullPath } = locations[index];
+    const basePath = basePaths.get(server);
+    const serverUrl = basePath.length > 0 ? `${server}/${basePath.join("/")}` : server;
+    if (serverUrl && !servers.includes(serverUrl)) {
+      servers.push(serverUrl);
+    }
+    const segments = fullPath.split("/").filter((segment) => segment !== "");
+    const { path, pathParameters } = templatePath(segments.slice(basePath.length).join("/"));
+    const headers = (Array.isArray(request.headers) ? request.headers : []).filter(
+      (header) => !BROWSER_HEADER.test(header.name) && !SECRET_HEADER.test(header.name),
+    );
+    return {
+      method: request.method,
+      path,
+      pathParameters,
+      query: (Array.isArray(request.queryString) ? request.queryString : []).map(({ name, value }) => ({
+        name,
+        value,
+      })),
+      headers: headers.map(({ name, value }) => ({ name, value })),
+      requestBody: resolveRequestBody(request.postData),
+      responses: [resolveResponse(response)],
+      security: resolveSecurity(request.headers),
+    };
+  });
+  const title = har.log.pages?.[0]?.title;
+  const document = buildOasSkeleton({
+    info: { title: typeof title === "string" && title.trim() ? title.trim() : servers[0] },
+    servers,
+    operations,
+  });
+  return stampDocument(document, buildProvenance({ tool: "har-oas", source }));
+};
+
+const parseHar = (contents) => {
+  let har;
+  try {
+    har = JSON.parse(typeof contents === "string" ? contents.trim() : "");
+  } catch {
+    throw Service.rejectResponse({ message: INVALID_HAR_ERROR }, 400);
+  }
+  if (!Array.isArray(har?.log?.entries)) {
+    throw Service.rejectResponse({ message: INVALID_HAR_ERROR }, 400);
+  }
+  return har;
+};
+
+const convert = async (input) => {
+  const { contents, source } = await resolveOasInput(input);
+  const har = parseHar(contents);
+  let document;
+  try {
+    document = buildOpenApiDocument(har, { source });
+  } catch (error) {
+    if (Service.isErrorResponse(error)) {
+      throw error;
+    }
+    throw Service.rejectResponse(
+      {
+        message: "Afleiden van een OpenAPI specificatie uit het HAR-bestand is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const filenameBase = sanitizeFileName(document.info.title, { fallback: DEFAULT_TITLE, lowercase: true });
+  return {
+    headers: {
+      "Content-Type": "application/json",
+      "Content-Disposition": `attachment; filename="${filenameBase}.openapi.json"`,
+    },
+    rawBody: Buffer.from(JSON.stringify(document, null, 2), "utf8"),
+  };
+};
+
+module.exports = {
+  buildOpenApiDocument,
+  convert,
+  templatePath,
+};
@@ -0,0 +1,390 @@
+const { randomUUID } = require("node:crypto");
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { buildSample, collectOperations, resolveRef } = require("../utils/openapi");
+const logger = require("../logger");
+
+const BASE_PATH = "/v1/mock";
+const DEFAULT_TTL_MINUTES = 60;
+const DEFAULT_MAX_MOCKS = 25;
+const MINUTE_MS = 60 * 1000;
+const MOCK_ID_PATTERN = /^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/;
+const JSON_MEDIA_TYPE = /[/+]json\b/i;
+const PROBLEM_CONTENT_TYPE = "application/problem+json";
+// De mock antwoordt vanaf het domein van deze API. Headers uit de specificatie die daar cookies, CORS,
+// beveiligingsbeleid, redirects of opgeslagen data beïnvloeden, worden niet meegestuurd.
+const BLOCKED_HEADERS = new Set([
+  "clear-site-data",
+  "connection",
+  "content-length",
+  "content-security-policy",
+  "content-security-policy-report-only",
+  "content-type",
+  "location",
+  "refresh",
+  "service-worker-allowed",
+  "set-cookie",
+  "set-cookie2",
+  "strict-transport-security",
+  "transfer-encoding",
+  "www-authenticate",
+  "x-content-type-options",
+  "x-frame-options",
+]);
+const BLOCKED_HEADER_PREFIXES = ["access-control-", "cross-origin-", "permissions-policy"];
+// Op elke mock-response: een HTML-voorbeeld uit een specificatie mag geen script draaien op dit domein.
+const SECURITY_HEADERS = {
+  "Content-Security-Policy": "sandbox",
+  "X-Content-Type-Options": "nosniff",
+};
+
+const parsePositiveInteger = (value, fallback) => {
+  const parsed = Number.parseInt(value ?? "", 10);
+  return Number.isFinite(parsed) && parsed > 0 ? parsed : fallback;
+};
+
+const isMockId = (id) => typeof id === "string" && MOCK_ID_PATTERN.test(id);
+
+const escapeRegExp = (value) => value.replace(/[.*+?^${}()|[\]\\]/g, "\\$&");
+
+/**
+ * Zet een padtemplate (`/dieren/{id}`) om naar een reguliere expressie met de namen van de
+ * padparameters. Vaste paden gaan bij het matchen voor templates, zoals in OpenAPI bedoeld.
+ */
+const compilePath = (template) => {
+  const names = [];
+  const pattern = template
+    .split(/(\{[^}]+\})/)
+    .map((part) => {
+      const match = /^\{([^}]+)\}$/.exec(part);
+      if (!match) {
+        return escapeRegExp(part);
+      }
+      names.push(match[1]);
+      return "([^/]+)";
+    })
+    .join("");
+  return { regex: new RegExp(`^${pattern}/?$`), names };
+};
+
+const compileRoutes = (document) =>
+  collectOperations(document)
+    .map((entry) => ({ ...entry, ...compilePath(entry.path) }))
+    .sort((a, b) => a.names.length - b.names.length);
+
+/**
+ * In-memory register van gehoste mock-servers. Een mock verloopt na de TTL en wordt bij het
+ * volgende gebruik van het register opgeruimd; het aantal gelijktijdige mocks is begrensd zodat
+ * een instantie niet onbeperkt specificaties in het geheugen houdt.
+ */
+class HostedMockStore {
+  constructor({ ttlMinutes = DEFAULT_TTL_MINUTES, maxMocks = DEFAULT_MAX_MOCKS, now = Date.now } = {}) {
+    this.ttlMs = parsePositiveInteger(ttlMinutes, DEFAULT_TTL_MINUTES) * MINUTE_MS;
+    this.maxMocks = parsePositiveInteger(maxMocks, DEFAULT_MAX_MOCKS);
+    this.now = now;
+    this.mocks = new Map();
+  }
+
+  static fromEnv() {
+    return new HostedMockStore({
+      ttlMinutes: process.env.MOCK_TTL_MINUTES,
+      maxMocks: process.env.MOCK_MAX_INSTANCES,
+    });
+  }
+
+  purgeExpired() {
+    const now = this.now();
+    for (const [id, mock] of this.mocks) {
+      if (mock.expiresAt <= now) {
+        this.mocks.delete(id);
+      }
+    }
+  }
+
+  /**
+   * Geeft undefined terug als het maximum aantal mocks bereikt is.
+   */
+  add(document, { dynamic = false, source } = {}) {
+    this.purgeExpired();
+    if (this.mocks.size >= this.maxMocks) {
+      return undefined;
+    }
+    const mock = {
+      id: randomUUID(),
+      document,
+      routes: compileRoutes(document),
+      dynamic,
+      source,
+      expiresAt: this.now() + this.ttlMs,
+    };
+    this.mocks.set(mock.id, mock);
+    return mock;
+  }
+
+  get(id) {
+    this.purgeExpired();
+    return isMockId(id) ? this.mocks.get(id) : undefined;
+  }
+
+  remove(id) {
+    return isMockId(id) && this.mocks.delete(id);
+  }
+}
+
+let defaultStore;
+
+const getHostedMockStore = () => {
+  if (!defaultStore) {
+    defaultStore = HostedMockStore.fromEnv();
+  }
+  return defaultStore;
+};
+
+const describeMock = (mock) => ({
+  id: mock.id,
+  url: `${BASE_PATH}/${mock.id}`,
+  expiresAt: new Date(mock.expiresAt).toISOString(),
+  dynamic: mock.dynamic,
+  operations: mock.routes.map(({ method, path }) => ({ method: method.toUpperCase(), path })),
+});
+
+/**
+ * Start een mock-server voor de specificatie. Lokale `$ref`s worden per request opgelost; externe
+ * verwijzingen moeten vooraf gebundeld zijn. `mockDynamic` bouwt responses altijd uit de schema's.
+ */
+const createMock = async (input, store = getHostedMockStore()) => {
+  const { spec, source } = await resolveOasDocument(input);
+  if (typeof spec.openapi !== "string" || !spec.openapi.startsWith("3.")) {
+    throw Service.rejectResponse({ message: "Alleen OpenAPI 3.x specificaties kunnen worden gemockt." }, 400);
+  }
+  if (collectOperations(spec).length === 0) {
+    throw Service.rejectResponse({ message: "De specificatie bevat geen operaties om te mocken." }, 400);
+  }
+  const mock = store.add(spec, { dynamic: input?.mockDynamic === true, source });
+  if (!mock) {
+    throw Service.rejectResponse(
+      {
+        message: "Er draaien al te veel mock-servers; probeer het later opnieuw.",
+        detail: `Maximaal ${store.maxMocks} gelijktijdige mock-servers.`,
+      },
+      429,
+    );
+  }
+  logger.info(`[HostedMockService] mock ${mock.id} gestart voor ${source}`);
+  return describeMock(mock);
+};
+
+const removeMock = (id, store = getHostedMockStore()) => {
+  if (!store.remove(id)) {
+    throw Service.rejectResponse({ message: "Mock-server niet gevonden of verlopen." }, 404);
+  }
+};
+
+const problem = (status, title, detail, invalidParams) => ({
+  code: status,
+  headers: { "Content-Type": PROBLEM_CONTENT_TYPE },
+  payload: Buffer.from(
+    JSON.stringify({ status, title, detail, ...(invalidParams?.length > 0 ? { invalidParams } : {}) }),
+    "utf8",
+  ),
+});
+
+/**
+ * Leest de `Prefer` header zoals Prism die kent: `code=404`, `example=naam` en `dynamic=true`.
+ */
+const parsePrefer = (header) => {
+  const preferences = {};
+  for (const part of String(header || "").split(/[,;]/)) {
+    const [key, ...rest] = part.split("=");
+    const value = rest.join("=").trim().replace(/^"(.*)"$/, "$1");
+    if (key.trim() && value) {
+      preferences[key.trim().toLowerCase()] = value;
+    }
+  }
+  return preferences;
+};
+
+const matchRoutes = (routes, path) => routes.filter((route) => route.regex.test(path));
+
+const isEmptyBody = (body) =>
+  body === undefined ||
+  body === null ||
+  body === "" ||
+  (typeof body === "object" && !Buffer.isBuffer(body) && Object.keys(body).length === 0);
+
+/**
+ * Controleert alleen of verplichte query- en headerparameters en een verplichte body aanwezig zijn;
+ * de mock valideert geen waarden tegen schema's.
+ */
+const findMissingInput = ({ parameters, requestBody }, { query, headers, body }) => {
+  const invalidParams = [];
+  for (const parameter of parameters) {
+    if (!parameter.required || (parameter.in !== "query" && parameter.in !== "header")) {
+      continue;
+    }
+    const value = parameter.in === "query" ? query?.[parameter.name] : headers?.[parameter.name.toLowerCase()];
+    if (value === undefined || value === "") {
+      invalidParams.push({ name: parameter.name, reason: `Verplichte ${parameter.in}-parameter ontbreekt.` });
+    }
+  }
+  if (requestBody?.required && isEmptyBody(body)) {
+    invalidParams.push({ name: "body", reason: "Verplichte request body ontbreekt." });
+  }
+  return invalidParams;
+};
+
+const statusFromKey = (key) => {
+  if (/^\d{3}$/.test(key)) {
+    return Number(key);
+  }
+  const range = /^([1-5])XX$/i.exec(key);
+  return range ? Number(range[1]) * 100 : 200;
+};
+
+/**
+ * Kiest de response: de gevraagde statuscode uit `Prefer`, anders de eerste 2xx, anders `default`,
+ * anders de eerste die de operatie beschrijft.
+ */
+const pickResponseKey = (responses, preferredCode) => {
+  const keys = Object.keys(responses || {});
+  if (preferredCode !== undefined) {
+    return [preferredCode, `${preferredCode.charAt(0)}XX`, `${preferredCode.charAt(0)}xx`].find((key) =>
+      keys.includes(key),
+    );
+  }
+  const success = keys.filter((key) => /^2(\d\d|XX)$/i.test(key)).sort();
+  return success[0] ?? (keys.includes("default") ? "default" : keys[0]);
+};
+
+const pickMediaType = (content, accept) => {
+  const types = Object.keys(content || {});
+  const accepted = String(accept || "")
+    .split(",")
+    .map((part) => part.split(";")[0].trim().toLowerCase())
+    .filter((type) => type && type !== "*/*");
+  return (
+    types.find((type) => accepted.includes(type.toLowerCase())) ??
+    types.find((type) => JSON_MEDIA_TYPE.test(type)) ??
+    types[0]
+  );
+};
+
+const buildResponseBody = (document, media, { dynamic, exampleName }) => {
+  if (!dynamic) {
+    if (exampleName !== undefined) {
+      const named = resolveRef(document, media.examples?.[exampleName]);
+      if (named?.value !== undefined) {
+        return named.value;
+      }
+    }
+    if (media.example !== undefined) {
+      return media.example;
+    }
+    const firstExample = resolveRef(document, Object.values(media.examples || {})[0]);
+    if (firstExample?.value !== undefined) {
+      return firstExample.value;
+    }
+  }
+  return buildSample(document, media.schema, 0, { skip: "writeOnly" });
+};
+
+const isBlockedHeader = (name) => {
+  const lower = name.toLowerCase();
+  return BLOCKED_HEADERS.has(lower) || BLOCKED_HEADER_PREFIXES.some((prefix) => lower.startsWith(prefix));
+};
+
+const buildResponseHeaders = (document, response) => {
+  const headers = {};
+  for (const [name, rawHeader] of Object.entries(response.headers || {})) {
+    const header = resolveRef(document, rawHeader);
+    if (isBlockedHeader(name) || !header) {
+      continue;
+    }
+    const value = header.example ?? buildSample(document, header.schema, 0, { skip: "writeOnly" });
+    if (value !== null && value !== undefined) {
+      headers[name] = typeof value === "object" ? JSON.stringify(value) : String(value);
+    }
+  }
+  return headers;
+};
+
+const respond = (id, { method, path, query, headers, body }, store) => {
+  const mock = store.get(id);
+  if (!mock) {
+    throw Service.rejectResponse({ message: "Mock-server niet gevonden of verlopen." }, 404);
+  }
+  const { document } = mock;
+  const matches = matchRoutes(mock.routes, path || "/");
+  if (matches.length === 0) {
+    return problem(404, "Not Found", `Geen pad in de specificatie komt overeen met ${path}.`);
+  }
+  const requestMethod = String(method).toLowerCase();
+  const match =
+    matches.find((route) => route.method === requestMethod) ??
+    (requestMethod === "head" ? matches.find((route) => route.method === "get") : undefined);
+  if (!match) {
+    const allowed = [...new Set(matches.map((route) => route.method.toUpperCase()))];
+    return {
+      ...problem(405, "Method Not Allowed", `${method} wordt voor ${path} niet ondersteund.`),
+      headers: { "Content-Type": PROBLEM_CONTENT_TYPE, Allow: allowed.join(", ") },
+    };
+  }
+  const invalidParams = findMissingInput(match, { query, headers, body });
+  if (invalidParams.length > 0) {
+    return problem(422, "Unprocessable Entity", "De request voldoet niet aan de specificatie.", invalidParams);
+  }
+
+  const prefer = parsePrefer(headers?.prefer);
+  if (/^1\d\d$/.test(prefer.code ?? "")) {
+    return problem(400, "Bad Request", `De mock kan geen informatieve response (${prefer.code}) geven.`);
+  }
+  const preferredCode = /^[2-5]\d\d$/.test(prefer.code ?? "") ? prefer.code : undefined;
+  const { responses } = match.operation;
+  const responseKey = pickResponseKey(responses, preferredCode);
+  if (responseKey === undefined) {
+    const detail = preferredCode
+      ? `De operatie beschrijft geen response ${preferredCode}.`
+      : "De operatie beschrijft geen responses.";
+    return problem(400, "Bad Request", detail);
+  }
+  const response = resolveRef(document, responses[responseKey]) || {};
+  const status = preferredCode ? Number(preferredCode) : statusFromKey(responseKey);
+  if (status < 200) {
+    return problem(500, "Internal Server Error", `De mock kan geen informatieve response (${responseKey}) geven.`);
+  }
+  const responseHeaders = buildResponseHeaders(document, response);
+  const mediaType = pickMediaType(response.content, headers?.accept);
+  if (!mediaType || status === 204 || status === 304) {
+    return { code: status, headers: responseHeaders, payload: Buffer.alloc(0) };
+  }
+  const value = buildResponseBody(document, response.content[mediaType] || {}, {
+    dynamic: mock.dynamic || prefer.dynamic === "true",
+    exampleName: prefer.example,
+  });
+  const text = typeof value === "string" && !JSON_MEDIA_TYPE.test(mediaType) ? value : JSON.stringify(value);
+  return {
+    code: status,
+    headers: { ...responseHeaders, "Content-Type": mediaType },
+    payload: Buffer.from(text ?? "", "utf8"),
+  };
+};
+
+/**
+ * Beantwoordt een request aan een gehoste mock. Fouten van de mock zelf (onbekend pad, ontbrekende
+ * parameters) zijn responses van de gemockte API en komen als problem+json terug; alleen een
+ * onbekende of verlopen mock is een fout van deze API.
+ */
+const handleRequest = (id, request, store = getHostedMockStore()) => {
+  const response = respond(id, request, store);
+  return { ...response, headers: { ...response.headers, ...SECURITY_HEADERS } };
+};
+
+module.exports = {
+  BASE_PATH,
+  HostedMockStore,
+  createMock,
+  getHostedMockStore,
+  handleRequest,
+  isMockId,
+  removeMock,
+};
@@ -0,0 +1,155 @@
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { sanitizeFileName, uniqueFileName } = require("../utils/fileName");
+const {
+  buildJsonRequestSample,
+  collectAuthVariables,
+  collectOperations,
+  collectPathVariables,
+  expandServerUrl,
+  operationName,
+  parameterExample,
+  resolveAuthScheme,
+} = require("../utils/openapi");
+const { buildProvenance, describeProvenance } = require("../utils/provenance");
+const { createZip } = require("../utils/zip");
+
+const DEFAULT_COLLECTION_NAME = "http-requests";
+const DEFAULT_FILE_NAME = "requests";
+const DEFAULT_BASE_URL = "http://localhost";
+const SETTINGS_FILE = ".vscode/settings.json";
+
+const comment = (text) => `# ${String(text).replace(/\r?\n/g, " ")}`;
+
+const toQueryString = (pairs) =>
+  pairs.length === 0
+    ? ""
+    : `?${pairs.map(([name, value]) => `${encodeURIComponent(name)}=${encodeURIComponent(value)}`).join("&")}`;
+
+/**
+ * Padparameters zonder voorbeeld worden een REST Client variabele met de naam van de parameter; die
+ * staat (leeg) in de `$shared` environment.
+ */
+const pathValue = (document, parameters, name) => {
+  const parameter = parameters.find((item) => item.in === "path" && item.name === name);
+  const value = parameter ? parameterExample(document, parameter) : "";
+  return value ? encodeURIComponent(value) : `{{${name}}}`;
+};
+
+const renderRequest = (document, entry) => {
+  const { path, method, operation, parameters, requestBody } = entry;
+  const scheme = resolveAuthScheme(document, operation);
+  const sample = buildJsonRequestSample(document, requestBody);
+  const withExample = (parameter) => [parameter.name, parameterExample(document, parameter)];
+  const required = (location) =>
+    parameters.filter((parameter) => parameter.in === location && parameter.required === true).map(withExample);
+  const optional = parameters
+    .filter((parameter) => (parameter.in === "query" || parameter.in === "header") && parameter.required !== true)
+    .map((parameter) => `${parameter.name} (${parameter.in})`);
+
+  const url = `{{baseUrl}}${path.replace(/\{([^}]+)\}/g, (match, name) => pathValue(document, parameters, name))}`;
+  const lines = [`### ${operationName(entry).replace(/\r?\n/g, " ")}`];
+  if (operation.operationId) {
+    lines.push(`# @name ${String(operation.operationId).replace(/\s+/g, "_")}`);
+  }
+  if (optional.length > 0) {
+    lines.push(comment(`Optioneel: ${optional.join(", ")}`));
+  }
+  lines.push(`${method.toUpperCase()} ${url}${toQueryString(required("query"))}`);
+  for (const [name, value] of required("header")) {
+    lines.push(`${name}: ${value}`);
+  }
+  if (scheme?.type === "bearer") {
+    lines.push("Authorization: Bearer {{token}}");
+  }
+  if (scheme?.type === "basic") {
+    lines.push("Authorization: Basic {{username}} {{password}}");
+  }
+  if (scheme?.type === "apiKey") {
+    lines.push(`${scheme.header}: {{apiKey}}`);
+  }
+  if (sample !== undefined) {
+    lines.push("Content-Type: application/json", "", JSON.stringify(sample, null, 2));
+  }
+  return lines.join("\n");
+};
+
+/**
+ * REST Client leest environments uit `rest-client.environmentVariables` in de workspace settings:
+ * een environment per server met `baseUrl`, en `$shared` met de (lege) variabelen die in elke
+ * environment gelden.
+ */
+const buildSettings = (document, operations) => {
+  const servers = Array.isArray(document.servers) && document.servers.length > 0 ? document.servers : [{}];
+  const variables = [...collectAuthVariables(document, operations), ...collectPathVariables(document, operations)];
+  const shared = Object.fromEntries(variables.map((name) => [name, ""]));
+  const environments = { $shared: shared };
+  const used = new Set(["$shared"]);
+  for (const server of servers) {
+    const name = uniqueFileName(used, sanitizeFileName(server.description || "", { fallback: "default" }));
+    environments[name] = { baseUrl: expandServerUrl(server) || DEFAULT_BASE_URL };
+  }
+  return { "rest-client.environmentVariables": environments };
+};
+
+/**
+ * Zet een OpenAPI document om naar VS Code REST Client bestanden: een `.http` bestand per (eerste)
+ * tag met een request per operatie, en `.vscode/settings.json` met een environment per server.
+ */
+const buildHttpFiles = (document, { source } = {}) => {
+  const title = typeof document.info?.title === "string" && document.info.title.trim();
+  const collectionName = title || DEFAULT_COLLECTION_NAME;
+  const operations = collectOperations(document);
+  const header = comment(describeProvenance(buildProvenance({ tool: "oas-http", source })));
+
+  const groups = new Map();
+  for (const entry of operations) {
+    const tag = (Array.isArray(entry.operation.tags) && entry.operation.tags[0]) || "";
+    if (!groups.has(tag)) {
+      groups.set(tag, []);
+    }
+    groups.get(tag).push(renderRequest(document, entry));
+  }
+
+  const usedNames = new Set();
+  const files = [...groups.entries()].map(([tag, requests]) => ({
+    name: `${uniqueFileName(usedNames, sanitizeFileName(tag, { fallback: DEFAULT_FILE_NAME }))}.http`,
+    data: `${header}\n\n${requests.join("\n\n")}\n`,
+  }));
+  files.push({ name: SETTINGS_FILE, data: `${JSON.stringify(buildSettings(document, operations), null, 2)}\n` });
+  return { collectionName, files };
+};
+
+const convert = async (input) => {
+  const resolved = await resolveOasDocument(input);
+  let result;
+  try {
+    result = buildHttpFiles(resolved.spec, { source: resolved.source });
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Genereren van .http bestanden is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const filenameBase = sanitizeFileName(result.collectionName, {
+    fallback: DEFAULT_COLLECTION_NAME,
+    lowercase: true,
+  });
+  const entries = result.files.map((file) => ({ ...file, name: `${filenameBase}/${file.name}` }));
+
+  return {
+    headers: {
+      "Content-Type": "application/zip",
+      "Content-Disposition": `attachment; filename="${filenameBase}-http.zip"`,
+    },
+    rawBody: createZip(entries),
+  };
+};
+
+module.exports = {
+  buildHttpFiles,
+  convert,
+};
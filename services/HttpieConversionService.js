@@ -0,0 +1,122 @@
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { sanitizeFileName } = require("../utils/fileName");
+const { buildJsonRequestSample, parameterExample, resolveAuthScheme } = require("../utils/openapi");
+const {
+  BODY_DELIMITER,
+  SHELL_OUTPUT_FORMATS,
+  acceptsJson,
+  buildShellScripts,
+  buildShellUrl,
+  escapeDoubleQuoted,
+  withHeredocBody,
+} = require("../utils/shellScript");
+const { createZip } = require("../utils/zip");
+
+const DEFAULT_COLLECTION_NAME = "httpie-scripts";
+
+/**
+ * Eén HTTPie-aanroep voor een operatie. Verplichte queryparameters worden `naam==waarde` items en
+ * headers `Naam:waarde` items; een voorbeeld-body gaat via stdin (heredoc), anders krijgt `http`
+ * `--ignore-stdin` zodat het script niet op invoer blijft wachten.
+ */
+const renderCommand = (document, entry) => {
+  const { path, method, operation, parameters, requestBody } = entry;
+  const scheme = resolveAuthScheme(document, operation);
+  const sample = buildJsonRequestSample(document, requestBody);
+  const url = buildShellUrl(document, path, parameters, { includeQuery: false });
+  const options = sample === undefined ? "--check-status --ignore-stdin" : "--check-status";
+  const args = [`http ${options} ${method.toUpperCase()} "${url}"`];
+  const quoted = (item) => `"${escapeDoubleQuoted(item)}"`;
+  for (const parameter of parameters.filter((item) => item.in === "query" && item.required === true)) {
+    args.push(quoted(`${parameter.name}==${parameterExample(document, parameter)}`));
+  }
+  if (acceptsJson(operation)) {
+    args.push("Accept:application/json");
+  }
+  for (const parameter of parameters.filter((item) => item.in === "header" && item.required === true)) {
+    args.push(quoted(`${parameter.name}:${parameterExample(document, parameter)}`));
+  }
+  if (scheme?.type === "bearer") {
+    args.push('"Authorization:Bearer ${TOKEN}"');
+  }
+  if (scheme?.type === "basic") {
+    args.push('-a "${USERNAME}:${PASSWORD}"');
+  }
+  if (scheme?.type === "apiKey") {
+    args.push(`"${escapeDoubleQuoted(scheme.header)}:\${API_KEY}"`);
+  }
+  if (sample === undefined) {
+    return args.join(" \\\n  ");
+  }
+  args.push("Content-Type:application/json", `<<'${BODY_DELIMITER}'`);
+  return withHeredocBody(args.join(" \\\n  "), sample);
+};
+
+/**
+ * Zet een OpenAPI document om naar HTTPie-scripts: standaard één bash-script met een functie per
+ * operatie, of met `outputFormat=zip` een ZIP met een uitvoerbaar script per operatie.
+ */
+const buildHttpieScripts = (document, { source, outputFormat = "script" } = {}) => {
+  const title = typeof document.info?.title === "string" && document.info.title.trim();
+  return {
+    collectionName: title || DEFAULT_COLLECTION_NAME,
+    ...buildShellScripts(document, { source, tool: "oas-httpie", outputFormat, renderCommand }),
+  };
+};
+
+const resolveOutputFormat = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return "script";
+  }
+  const normalized = String(value).toLowerCase();
+  if (!SHELL_OUTPUT_FORMATS.includes(normalized)) {
+    throw Service.rejectResponse(
+      { message: `Onbekend outputFormat "${value}". Kies uit: ${SHELL_OUTPUT_FORMATS.join(", ")}.` },
+      400,
+    );
+  }
+  return normalized;
+};
+
+const convert = async (input, { outputFormat } = {}) => {
+  const format = resolveOutputFormat(outputFormat);
+  const resolved = await resolveOasDocument(input);
+  let result;
+  try {
+    result = buildHttpieScripts(resolved.spec, { source: resolved.source, outputFormat: format });
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Genereren van HTTPie-scripts is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const filenameBase = sanitizeFileName(result.collectionName, {
+    fallback: DEFAULT_COLLECTION_NAME,
+    lowercase: true,
+  });
+  if (format === "zip") {
+    return {
+      headers: {
+        "Content-Type": "application/zip",
+        "Content-Disposition": `attachment; filename="${filenameBase}-httpie.zip"`,
+      },
+      rawBody: createZip(result.files.map((file) => ({ ...file, name: `${filenameBase}/${file.name}` }))),
+    };
+  }
+  return {
+    headers: {
+      "Content-Type": "text/x-shellscript; charset=utf-8",
+      "Content-Disposition": `attachment; filename="${filenameBase}.sh"`,
+    },
+    rawBody: Buffer.from(result.script, "utf8"),
+  };
+};
+
+module.exports = {
+  buildHttpieScripts,
+  convert,
+};
@@ -0,0 +1,157 @@
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { sanitizeFileName, uniqueFileName } = require("../utils/fileName");
+const {
+  buildJsonRequestSample,
+  collectAuthVariables,
+  collectOperations,
+  collectPathVariables,
+  expandServerUrl,
+  operationName,
+  parameterExample,
+  resolveAuthScheme,
+} = require("../utils/openapi");
+const { buildProvenance, describeProvenance } = require("../utils/provenance");
+const { createZip } = require("../utils/zip");
+
+const DEFAULT_COLLECTION_NAME = "hurl-tests";
+const DEFAULT_BASE_URL = "http://localhost";
+const VARIABLES_FILE = "hurl.env";
+const API_VERSION_HEADER = "API-Version";
+
+// Eén regel commentaar; regeleinden in summaries zouden anders als Hurl-syntax gelezen worden.
+const comment = (text) => `# ${String(text).replace(/\r?\n/g, " ")}`;
+
+/**
+ * Padparameters krijgen hun voorbeeldwaarde; zonder voorbeeld wordt het een Hurl-variabele met de
+ * naam van de parameter, die in `hurl.env` of met `--variable` ingevuld kan worden.
+ */
+const buildUrl = (document, path, pathParameters) => {
+  const values = new Map(
+    pathParameters.map((parameter) => [parameter.name, parameterExample(document, parameter)]),
+  );
+  const expanded = path.replace(/\{([^}]+)\}/g, (match, name) => {
+    const value = values.get(name);
+    return value ? encodeURIComponent(value) : `{{${name}}}`;
+  });
+  return `{{baseUrl}}${expanded}`;
+};
+
+/**
+ * Verwachte status: de eerste expliciete 2xx-code uit de responses. Zonder zo'n code (alleen `2XX` of
+ * `default`) accepteert de test elke status en controleert een assert dat het geen fout is.
+ */
+const expectedStatus = (operation) => {
+  const codes = Object.keys(operation.responses || {});
+  return codes.find((code) => /^2\d\d$/.test(code));
+};
+
+const pairLine = (name, value, enabled) => `${enabled ? "" : "# "}${name}: ${value}`;
+
+const renderRequest = (document, entry) => {
+  const { path, method, operation, parameters, requestBody } = entry;
+  const scheme = resolveAuthScheme(document, operation);
+  const sample = buildJsonRequestSample(document, requestBody);
+  const byLocation = (location) => parameters.filter((parameter) => parameter.in === location);
+  const lines = [
+    comment(operationName(entry)),
+    `${method.toUpperCase()} ${buildUrl(document, path, byLocation("path"))}`,
+  ];
+
+  // Optionele headers en queryparameters staan als commentaar in het bestand, klaar om aan te zetten.
+  for (const parameter of byLocation("header")) {
+    lines.push(pairLine(parameter.name, parameterExample(document, parameter), parameter.required === true));
+  }
+  if (scheme?.type === "bearer") {
+    lines.push("Authorization: Bearer {{token}}");
+  }
+  if (scheme?.type === "basic") {
+    lines.push("[BasicAuth]", "{{username}}: {{password}}");
+  }
+  if (scheme?.type === "apiKey") {
+    lines.push(`${scheme.header}: {{apiKey}}`);
+  }
+  const query = byLocation("query");
+  if (query.length > 0) {
+    lines.push("[QueryStringParams]");
+    for (const parameter of query) {
+      lines.push(pairLine(parameter.name, parameterExample(document, parameter), parameter.required === true));
+    }
+  }
+  if (sample !== undefined) {
+    lines.push(JSON.stringify(sample, null, 2));
+  }
+
+  const status = expectedStatus(operation);
+  lines.push("", `HTTP ${status || "*"}`, "[Asserts]");
+  if (!status) {
+    lines.push("status < 400");
+  }
+  const version = document.info?.version;
+  lines.push(
+    typeof version === "string" && version.length > 0
+      ? `header "${API_VERSION_HEADER}" == "${version.replace(/["\\]/g, "\\$&")}"`
+      : `header "${API_VERSION_HEADER}" exists`,
+  );
+  return `${lines.join("\n")}\n`;
+};
+
+const renderVariables = (document, operations) => {
+  const servers = Array.isArray(document.servers) ? document.servers : [];
+  const baseUrl = expandServerUrl(servers[0]) || DEFAULT_BASE_URL;
+  const names = [...collectAuthVariables(document, operations), ...collectPathVariables(document, operations)];
+  return [`baseUrl=${baseUrl}`, ...names.map((name) => `${name}=`)].join("\n").concat("\n");
+};
+
+/**
+ * Zet een OpenAPI document om naar Hurl smoke tests: een `.hurl` bestand per operatie dat de
+ * verwachte status en de `API-Version` header controleert, plus `hurl.env` met `baseUrl` (de eerste
+ * server) en de variabelen voor authenticatie en padparameters zonder voorbeeld.
+ */
+const buildTests = (document, { source } = {}) => {
+  const title = typeof document.info?.title === "string" && document.info.title.trim();
+  const collectionName = title || DEFAULT_COLLECTION_NAME;
+  const operations = collectOperations(document);
+  const header = comment(describeProvenance(buildProvenance({ tool: "oas-hurl", source })));
+  const usedNames = new Set([VARIABLES_FILE.toLowerCase()]);
+  const files = operations.map((entry) => ({
+    name: `${uniqueFileName(usedNames, sanitizeFileName(operationName(entry), { fallback: "request" }))}.hurl`,
+    data: `${header}\n${renderRequest(document, entry)}`,
+  }));
+  files.push({ name: VARIABLES_FILE, data: renderVariables(document, operations) });
+  return { collectionName, files };
+};
+
+const convert = async (input) => {
+  const resolved = await resolveOasDocument(input);
+  let tests;
+  try {
+    tests = buildTests(resolved.spec, { source: resolved.source });
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Genereren van Hurl tests is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const filenameBase = sanitizeFileName(tests.collectionName, {
+    fallback: DEFAULT_COLLECTION_NAME,
+    lowercase: true,
+  });
+  const entries = tests.files.map((file) => ({ ...file, name: `${filenameBase}/${file.name}` }));
+
+  return {
+    headers: {
+      "Content-Type": "application/zip",
+      "Content-Disposition": `attachment; filename="${filenameBase}-hurl.zip"`,
+    },
+    rawBody: createZip(entries),
+  };
+};
+
+module.exports = {
+  buildTests,
+  convert,
+};
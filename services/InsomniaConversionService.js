@@ -0,0 +1,196 @@
+const { createHash } = require("node:crypto");
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { sanitizeFileName } = require("../utils/fileName");
+const {
+  buildJsonRequestSample,
+  collectAuthVariables,
+  collectOperations,
+  expandServerUrl,
+  operationName,
+  parameterExample,
+  resolveAuthScheme,
+} = require("../utils/openapi");
+const { PROVENANCE_EXTENSION, buildProvenance } = require("../utils/provenance");
+
+const DEFAULT_COLLECTION_NAME = "insomnia-collection";
+const DEFAULT_BASE_URL = "http://localhost";
+const EXPORT_FORMAT = 4;
+const SORT_KEY_STEP = 100;
+
+const variable = (name) => `{{ _.${name} }}`;
+
+/**
+ * Insomnia koppelt resources via `_id`/`parentId`. De ids worden afgeleid van de inhoud, zodat een
+ * opnieuw geïmporteerde export bestaande requests bijwerkt in plaats van ze te dupliceren.
+ */
+const resourceId = (prefix, ...parts) =>
+  `${prefix}_${createHash("sha256").update(parts.join("\u0000")).digest("hex").slice(0, 32)}`;
+
+const toInsomniaUrl = (path) => `${variable("baseUrl")}${path.replace(/\{([^}]+)\}/g, ":$1")}`;
+
+const buildAuthentication = (scheme) => {
+  if (scheme?.type === "bearer") {
+    return { type: "bearer", token: variable("token") };
+  }
+  if (scheme?.type === "basic") {
+    return { type: "basic", username: variable("username"), password: variable("password") };
+  }
+  return {};
+};
+
+const buildRequest = (document, entry, { workspaceId, parentId, sortKey, synthesizeExamples }) => {
+  const { path, method, operation, parameters, requestBody } = entry;
+  const scheme = resolveAuthScheme(document, operation);
+  const sample = buildJsonRequestSample(document, requestBody, { synthesize: synthesizeExamples });
+  const byLocation = (location) => parameters.filter((parameter) => parameter.in === location);
+  // Optionele parameters worden wel opgenomen, maar staan uit zodat ze niet standaard worden meegestuurd.
+  const toPair = (parameter) => ({
+    name: parameter.name,
+    value: parameterExample(document, parameter),
+    disabled: parameter.required !== true,
+  });
+
+  const headers = byLocation("header").map(toPair);
+  if (scheme?.type === "apiKey") {
+    headers.push({ name: scheme.header, value: variable("apiKey") });
+  }
+  if (sample !== undefined) {
+    headers.push({ name: "Content-Type", value: "application/json" });
+  }
+
+  return {
+    _id: resourceId("req", workspaceId, method, path),
+    _type: "request",
+    parentId,
+    name: operationName(entry),
+    description: typeof operation.description === "string" ? operation.description : "",
+    method: method.toUpperCase(),
+    url: toInsomniaUrl(path),
+    pathParameters: byLocation("path").map((parameter) => ({
+      name: parameter.name,
+      value: parameterExample(document, parameter),
+    })),
+    parameters: byLocation("query").map(toPair),
+    headers,
+    body: sample === undefined ? {} : { mimeType: "application/json", text: JSON.stringify(sample, null, 2) },
+    authentication: buildAuthentication(scheme),
+    metaSortKey: sortKey,
+  };
+};
+
+/**
+ * Zet een OpenAPI document om naar een Insomnia v4 export: een workspace met een basisenvironment
+ * (authenticatievariabelen), een sub-environment per server met `baseUrl`, een request group per
+ * (eerste) tag en een request per operatie.
+ */
+const buildExport = (document, { source, now = new Date(), synthesizeExamples = true } = {}) => {
+  const title = typeof document.info?.title === "string" && document.info.title.trim();
+  const collectionName = title || DEFAULT_COLLECTION_NAME;
+  const operations = collectOperations(document);
+  const workspaceId = resourceId("wrk", collectionName);
+  const baseEnvironmentId = resourceId("env", workspaceId, "base");
+  const servers = Array.isArray(document.servers) && document.servers.length > 0 ? document.servers : [{}];
+  const firstBaseUrl = expandServerUrl(servers[0]) || DEFAULT_BASE_URL;
+
+  const resources = [
+    {
+      _id: workspaceId,
+      _type: "workspace",
+      parentId: null,
+      name: collectionName,
+      description: typeof document.info?.description === "string" ? document.info.description : "",
+      scope: "collection",
+    },
+    {
+      _id: baseEnvironmentId,
+      _type: "environment",
+      parentId: workspaceId,
+      name: "Base Environment",
+      data: {
+        baseUrl: firstBaseUrl,
+        ...Object.fromEntries(collectAuthVariables(document, operations).map((name) => [name, ""])),
+      },
+    },
+  ];
+
+  servers.forEach((server, index) => {
+    resources.push({
+      _id: resourceId("env", workspaceId, String(index)),
+      _type: "environment",
+      parentId: baseEnvironmentId,
+      name: server.description || expandServerUrl(server) || `Server ${index + 1}`,
+      data: { baseUrl: expandServerUrl(server) || DEFAULT_BASE_URL },
+    });
+  });
+
+  const groups = new Map();
+  operations.forEach((entry, index) => {
+    const tag = Array.isArray(entry.operation.tags) && entry.operation.tags[0];
+    let parentId = workspaceId;
+    if (tag) {
+      if (!groups.has(tag)) {
+        const group = {
+          _id: resourceId("fld", workspaceId, tag),
+          _type: "request_group",
+          parentId: workspaceId,
+          name: tag,
+          metaSortKey: (groups.size + 1) * SORT_KEY_STEP,
+        };
+        groups.set(tag, group);
+        resources.push(group);
+      }
+      parentId = groups.get(tag)._id;
+    }
+    const sortKey = (index + 1) * SORT_KEY_STEP;
+    resources.push(buildRequest(document, entry, { workspaceId, parentId, sortKey, synthesizeExamples }));
+  });
+
+  return {
+    collectionName,
+    export: {
+      _type: "export",
+      __export_format: EXPORT_FORMAT,
+      __export_date: now.toISOString(),
+      __export_source: "don-tools-api",
+      [PROVENANCE_EXTENSION]: buildProvenance({ tool: "oas-insomnia", source, now }),
+      resources,
+    },
+  };
+};
+
+const convert = async (input) => {
+  const resolved = await resolveOasDocument(input);
+  let result;
+  try {
+    result = buildExport(resolved.spec, {
+      source: resolved.source,
+      synthesizeExamples: input.synthesizeExamples !== false,
+    });
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Conversie naar Insomnia is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const filenameBase = sanitizeFileName(result.collectionName, {
+    fallback: DEFAULT_COLLECTION_NAME,
+    lowercase: true,
+  });
+
+  return {
+    headers: {
+      "Content-Type": "application/json",
+      "Content-Disposition": `attachment; filename="${filenameBase}.insomnia.json"`,
+    },
+    rawBody: Buffer.from(JSON.stringify(result.export, null, 2), "utf8"),
+  };
+};
+
+module.exports = {
+  buildExport,
+  convert,
+};
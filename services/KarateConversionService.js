@@ -0,0 +1,364 @@
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { sanitizeFileName, uniqueFileName } = require("../utils/fileName");
+const {
+  buildJsonRequestSample,
+  collectAuthVariables,
+  collectOperations,
+  collectPathVariables,
+  expandServerUrl,
+  operationName,
+  parameterExample,
+  resolveAuthScheme,
+  resolveRef,
+} = require("../utils/openapi");
+const { buildProvenance, describeProvenance } = require("../utils/provenance");
+const { createZip } = require("../utils/zip");
+
+const DEFAULT_COLLECTION_NAME = "karate-tests";
+const DEFAULT_BASE_URL = "http://localhost";
+const CONFIG_FILE = "karate-config.js";
+const API_VERSION_HEADER = "API-Version";
+const MAX_SCHEMA_DEPTH = 12;
+const INDENT = "  ";
+
+// Eén regel commentaar; regeleinden in summaries zouden anders als Gherkin gelezen worden.
+const comment = (text) => `# ${String(text).replace(/\r?\n/g, " ")}`;
+
+const singleLine = (text) => String(text).replace(/\s*\r?\n\s*/g, " ");
+
+// Karate-variabelen zijn JavaScript-identifiers; een parameter als `dier-id` wordt `dier_id`.
+const toIdentifier = (name) => {
+  const identifier = String(name).replace(/[^A-Za-z0-9_$]/g, "_");
+  return /^[A-Za-z_$]/.test(identifier) ? identifier : `_${identifier}`;
+};
+
+const literal = (value) => JSON.stringify(String(value));
+
+// Meerregelige JSON gaat als doc string mee, ingesprongen onder de stap.
+const docString = (text) => [
+  `${INDENT}"""`,
+  ...String(text)
+    .split("\n")
+    .map((line) => `${INDENT}${line}`),
+  `${INDENT}"""`,
+];
+
+const schemaType = (schema) => {
+  const type = Array.isArray(schema.type) ? schema.type.find((item) => item !== "null") : schema.type;
+  if (type) {
+    return type;
+  }
+  if (schema.properties || schema.allOf) {
+    return "object";
+  }
+  return schema.items ? "array" : undefined;
+};
+
+const isNullable = (schema) => schema.nullable === true || (Array.isArray(schema.type) && schema.type.includes("null"));
+
+/**
+ * Zet response-schema's om naar Karate schema's met fuzzy markers (`#string`, `#number`, `#uuid`,
+ * `#[] dierSchema`, ...). Niet-verplichte en nullable velden krijgen `##`. Objecten in arrays en
+ * optionele objecten worden een eigen `def`, want Karate verwijst daar naar een variabele; `defs`
+ * staat in de volgorde waarin ze gedefinieerd moeten worden.
+ */
+const createSchemaBuilder = (document) => {
+  const defs = new Map();
+  const refNames = new Map();
+  const building = new Set();
+
+  const defName = (rawSchema, hint) => {
+    const refName = typeof rawSchema?.$ref === "string" ? rawSchema.$ref.split("/").pop() : hint;
+    const base = `${toIdentifier(refName || "item").replace(/^./, (first) => first.toLowerCase())}Schema`;
+    let name = base;
+    for (let counter = 2; [...refNames.values()].includes(name) || defs.has(name); counter += 1) {
+      name = `${base}${counter}`;
+    }
+    return name;
+  };
+
+  let toValue;
+
+  const objectValue = (schema, depth) => {
+    const properties = {};
+    const required = new Set(Array.isArray(schema.required) ? schema.required : []);
+    for (const part of schema.allOf || []) {
+      const resolved = resolveRef(document, part) || {};
+      Object.assign(properties, resolved.properties);
+      for (const name of resolved.required || []) {
+        required.add(name);
+      }
+    }
+    Object.assign(properties, schema.properties);
+    return Object.fromEntries(
+      Object.entries(properties)
+        .filter(([, property]) => !resolveRef(document, property)?.writeOnly)
+        .map(([name, property]) => [name, toValue(property, !required.has(name), name, depth + 1)]),
+    );
+  };
+
+  // Geeft de naam van de def terug, of `undefined` bij een zelfverwijzend schema dat nog gebouwd wordt.
+  const define = (rawSchema, hint, depth) => {
+    const key = typeof rawSchema?.$ref === "string" ? rawSchema.$ref : undefined;
+    if (key && refNames.has(key)) {
+      return building.has(key) ? undefined : refNames.get(key);
+    }
+    const name = defName(rawSchema, hint);
+    if (key) {
+      refNames.set(key, name);
+      building.add(key);
+    }
+    const value = objectValue(resolveRef(document, rawSchema), depth);
+    building.delete(key);
+    defs.set(name, value);
+    return name;
+  };
+
+  toValue = (rawSchema, optional, hint, depth) => {
+    const schema = resolveRef(document, rawSchema);
+    const prefix = optional || (schema && isNullable(schema)) ? "##" : "#";
+    if (!schema || typeof schema !== "object" || depth > MAX_SCHEMA_DEPTH) {
+      return prefix === "##" ? "#ignore" : "#present";
+    }
+    const type = schemaType(schema);
+    if (type === "object") {
+      if (!schema.properties && !schema.allOf) {
+        return `${prefix}object`;
+      }
+      if (prefix === "#" && !rawSchema.$ref) {
+        return objectValue(schema, depth);
+      }
+      const name = define(rawSchema, hint, depth);
+      return name ? `${prefix}(${name})` : `${prefix}object`;
+    }
+    if (type === "array") {
+      const items = resolveRef(document, schema.items);
+      const itemType = items && schemaType(items);
+      if (itemType === "object" && (items.properties || items.allOf)) {
+        const name = define(schema.items, `${hint}Item`, depth);
+        return name ? `${prefix}[] ${name}` : `${prefix}array`;
+      }
+      const itemValue = itemType && itemType !== "array" ? toValue(schema.items, false, hint, depth + 1) : undefined;
+      return typeof itemValue === "string" && itemValue !== "#present" ? `${prefix}[] ${itemValue}` : `${prefix}array`;
+    }
+    if (type === "string") {
+      return schema.format === "uuid" ? `${prefix}uuid` : `${prefix}string`;
+    }
+    if (type === "integer" || type === "number") {
+      return `${prefix}number`;
+    }
+    if (type === "boolean") {
+      return `${prefix}boolean`;
+    }
+    return prefix === "##" ? "#ignore" : "#present";
+  };
+
+  return {
+    defs,
+    build: (rawSchema) => toValue(rawSchema, false, "response", 0),
+  };
+};
+
+/**
+ * Verwachte status: de eerste expliciete 2xx-code uit de responses. Zonder zo'n code (alleen `2XX` of
+ * `default`) controleert de test alleen dat het geen fout is.
+ */
+const expectedStatus = (operation) => Object.keys(operation.responses || {}).find((code) => /^2\d\d$/.test(code));
+
+const jsonSchemaOf = (document, response) => {
+  const content = resolveRef(document, response)?.content || {};
+  const mediaType = Object.keys(content).find((type) => /[/+]json\b/i.test(type));
+  return mediaType ? content[mediaType].schema : undefined;
+};
+
+/**
+ * `path` met de segmenten als argumenten. Padparameters krijgen hun voorbeeldwaarde; zonder voorbeeld
+ * wordt het een variabele uit `karate-config.js`.
+ */
+const buildPathStep = (document, path, pathParameters) => {
+  const segments = path
+    .split("/")
+    .filter((segment) => segment !== "")
+    .map((segment) =>
+      segment
+        .split(/(\{[^}]+\})/)
+        .filter((part) => part !== "")
+        .map((part) => {
+          const name = /^\{([^}]+)\}$/.exec(part)?.[1];
+          if (!name) {
+            return literal(part);
+          }
+          const parameter = pathParameters.find((item) => item.name === name);
+          const value = parameter && parameterExample(document, parameter);
+          return value ? literal(value) : toIdentifier(name);
+        })
+        .join(" + "),
+    );
+  return segments.length > 0 ? `Given path ${segments.join(", ")}` : "Given path '/'";
+};
+
+// Optionele headers en queryparameters staan als commentaar in de scenario's, klaar om aan te zetten.
+const parameterStep = (keyword, document, parameter) =>
+  `${parameter.required === true ? "" : "# "}And ${keyword} ${parameter.name} = ${literal(
+    parameterExample(document, parameter),
+  )}`;
+
+const renderScenario = (document, entry) => {
+  const { path, method, operation, parameters, requestBody } = entry;
+  const scheme = resolveAuthScheme(document, operation);
+  const sample = buildJsonRequestSample(document, requestBody);
+  const byLocation = (location) => parameters.filter((parameter) => parameter.in === location);
+  const tags = (Array.isArray(operation.tags) ? operation.tags : [])
+    .map((tag) => `@${String(tag).replace(/\s+/g, "_")}`)
+    .join(" ");
+  const steps = [buildPathStep(document, path, byLocation("path"))];
+
+  for (const parameter of byLocation("query")) {
+    steps.push(parameterStep("param", document, parameter));
+  }
+  for (const parameter of byLocation("header")) {
+    steps.push(parameterStep("header", document, parameter));
+  }
+  if (scheme?.type === "bearer") {
+    steps.push("And header Authorization = 'Bearer ' + token");
+  }
+  if (scheme?.type === "basic") {
+    steps.push("And header Authorization = basicAuth");
+  }
+  if (scheme?.type === "apiKey") {
+    steps.push(`And header ${scheme.header} = apiKey`);
+  }
+  if (sample !== undefined) {
+    steps.push("And request", ...docString(JSON.stringify(sample, null, 2)));
+  }
+  steps.push(`When method ${method}`);
+
+  const status = expectedStatus(operation);
+  steps.push(status ? `Then status ${status}` : "Then assert responseStatus < 400");
+  const version = document.info?.version;
+  steps.push(
+    `* def apiVersion = karate.response.header(${literal(API_VERSION_HEADER)})`,
+    typeof version === "string" && version.length > 0
+      ? `And match apiVersion == ${literal(version)}`
+      : "And match apiVersion == '#notnull'",
+  );
+
+  const schema = status && jsonSchemaOf(document, operation.responses[status]);
+  if (schema) {
+    const builder = createSchemaBuilder(document);
+    const expected = builder.build(schema);
+    const definitions = [...builder.defs].flatMap(([name, value]) => [
+      `* def ${name} =`,
+      ...docString(JSON.stringify(value, null, 2)),
+    ]);
+    steps.push(
+      ...definitions,
+      ...(typeof expected === "string"
+        ? [`And match response == ${literal(expected)}`]
+        : ["And match response ==", ...docString(JSON.stringify(expected, null, 2))]),
+    );
+  }
+
+  return [
+    `Feature: ${singleLine(operationName(entry))}`,
+    "",
+    `${INDENT}Background:`,
+    `${INDENT}${INDENT}* url baseUrl`,
+    "",
+    ...(tags ? [`${INDENT}${tags}`] : []),
+    `${INDENT}Scenario: ${method.toUpperCase()} ${path}`,
+    ...steps.map((step) => `${INDENT}${INDENT}${step}`),
+  ].join("\n");
+};
+
+/**
+ * `karate-config.js`: `baseUrl` is de eerste server, met `karate.env` als naam (de beschrijving) van
+ * een andere server. Authenticatie en padparameters zonder voorbeeld komen uit system properties
+ * (`-Dtoken=...`); voor basic auth wordt de header in de config opgebouwd.
+ */
+const renderConfig = (document, operations) => {
+  const servers = Array.isArray(document.servers) ? document.servers : [];
+  const authVariables = collectAuthVariables(document, operations);
+  const names = [...authVariables, ...collectPathVariables(document, operations)];
+  const properties = [
+    `baseUrl: ${literal(expandServerUrl(servers[0]) || DEFAULT_BASE_URL)}`,
+    ...names.map((name) => `${toIdentifier(name)}: karate.properties[${literal(name)}] || ''`),
+  ];
+  const lines = [
+    "function fn() {",
+    "  var config = {",
+    ...properties.map((line, index) => `    ${line}${index < properties.length - 1 ? "," : ""}`),
+    "  };",
+  ];
+  servers.slice(1).forEach((server, index) => {
+    const environment = server.description || `server${index + 2}`;
+    lines.push(
+      `  if (karate.env == ${literal(environment)}) {`,
+      `    config.baseUrl = ${literal(expandServerUrl(server) || DEFAULT_BASE_URL)};`,
+      "  }",
+    );
+  });
+  if (authVariables.includes("username")) {
+    lines.push(
+      "  var credentials = config.username + ':' + config.password;",
+      "  var Base64 = Java.type('java.util.Base64');",
+      "  config.basicAuth = 'Basic ' + Base64.getEncoder().encodeToString(credentials.toString().getBytes());",
+    );
+  }
+  lines.push("  return config;", "}");
+  return `${lines.join("\n")}\n`;
+};
+
+/**
+ * Zet een OpenAPI document om naar Karate contracttests: een `.feature` bestand per operatie dat de
+ * verwachte status, de `API-Version` header en de JSON response tegen het schema controleert, plus
+ * `karate-config.js` met `baseUrl` en de variabelen voor authenticatie en padparameters.
+ */
+const buildFeatures = (document, { source } = {}) => {
+  const title = typeof document.info?.title === "string" && document.info.title.trim();
+  const collectionName = title || DEFAULT_COLLECTION_NAME;
+  const operations = collectOperations(document);
+  const provenance = describeProvenance(buildProvenance({ tool: "oas-karate", source }));
+  const usedNames = new Set();
+  const files = operations.map((entry) => ({
+    name: `${uniqueFileName(usedNames, sanitizeFileName(operationName(entry), { fallback: "operation" }))}.feature`,
+    data: `${comment(provenance)}\n${renderScenario(document, entry)}\n`,
+  }));
+  files.push({ name: CONFIG_FILE, data: `// ${provenance}\n${renderConfig(document, operations)}` });
+  return { collectionName, files };
+};
+
+const convert = async (input) => {
+  const resolved = await resolveOasDocument(input);
+  let features;
+  try {
+    features = buildFeatures(resolved.spec, { source: resolved.source });
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Genereren van Karate tests is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const filenameBase = sanitizeFileName(features.collectionName, {
+    fallback: DEFAULT_COLLECTION_NAME,
+    lowercase: true,
+  });
+  const entries = features.files.map((file) => ({ ...file, name: `${filenameBase}/${file.name}` }));
+
+  return {
+    headers: {
+      "Content-Type": "application/zip",
+      "Content-Disposition": `attachment; filename="${filenameBase}-karate.zip"`,
+    },
+    rawBody: createZip(entries),
+  };
+};
+
+module.exports = {
+  buildFeatures,
+  convert,
+};
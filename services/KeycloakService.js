@@ -5,12 +5,15 @@ const Service = require("./Service");
 const KEYCLOAK_CLIENT_DESCRIPTION = "Dit is een read-only api key. Meer info: https://apis.developer.overheid.nl/apis/toevoegen";
 const DEFAULT_TIMEOUT_MS = 30000;
 const MAX_ERROR_BODY_LENGTH = 8192;
+const CLIENTS_PAGE_SIZE = 100;
 
 const ERROR_CODES = {
   CONFIG: "config",
   CONFLICT: "conflict",
   UNAUTHORIZED: "unauthorized",
   CLIENT_ID_MISSING: "client_id_missing",
+  UNAUTHENTICATED: "unauthenticated",
+  NOT_FOUND: "not_found",
   GENERIC: "generic",
 };
 
@@ -44,7 +47,7 @@ const truncate = (value, limit = MAX_ERROR_BODY_LENGTH) => {
   return `${value.slice(0, limit)}…`;
 };
 
-const buildKeycloakPayload = (clientId, email) => {
+const buildKeycloakPayload = (clientId, email, owner) => {
   const payload = {
     clientId,
     name: clientId,
@@ -62,6 +65,9 @@ const buildKeycloakPayload = (clientId, email) => {
   if (email) {
     attributes.email = email;
   }
+  if (owner) {
+    attributes.owner = owner;
+  }
   if (Object.keys(attributes).length > 0) {
     payload.attributes = attributes;
   }
@@ -132,6 +138,29 @@ const parseUntrustClientInput = (params) => {
   return { email };
 };
 
+const parseBearerToken = (authorization) => {
+  const match = /^Bearer\s+(\S+)\s*$/i.exec(trimString(authorization));
+  if (!match) {
+    throw new KeycloakError("Authorization header met Bearer token ontbreekt", ERROR_CODES.UNAUTHENTICATED);
+  }
+  return match[1];
+};
+
+const readAttribute = (client, name) => {
+  const value = client?.attributes?.[name];
+  if (Array.isArray(value)) {
+    return trimString(value[0]);
+  }
+  return trimString(value);
+};
+
+const toClientSummary = (client) => ({
+  apiKey: client.id,
+  email: readAttribute(client, "email") || undefined,
+  description: client.description || undefined,
+  enabled: client.enabled !== false,
+});
+
 const translateKeycloakError = (error) => {
   if (!(error instanceof KeycloakError)) {
     return null;
@@ -145,6 +174,10 @@ const translateKeycloakError = (error) => {
       return { status: 403, message: "Geen toegang tot Keycloak admin API" };
     case ERROR_CODES.CLIENT_ID_MISSING:
       return { status: 400, message: "clientId ontbreekt of is ongeldig" };
+    case ERROR_CODES.UNAUTHENTICATED:
+      return { status: 401, message: error.message || "Niet geauthenticeerd" };
+    case ERROR_CODES.NOT_FOUND:
+      return { status: 404, message: "Client niet gevonden" };
     default:
       return { status: 500, message: error.message || "Er is een fout opgetreden bij Keycloak." };
   }
@@ -154,6 +187,7 @@ class KeycloakService {
   constructor({
     adminClientsURL = "",
     tokenURL = "",
+    userInfoURL = "",
     clientId = "",
     clientSecret = "",
     timeoutMs = DEFAULT_TIMEOUT_MS,
@@ -161,6 +195,7 @@ class KeycloakService {
   } = {}) {
     this.adminClientsURL = trimString(adminClientsURL);
     this.tokenURL = trimString(tokenURL);
+    this.userInfoURL = trimString(userInfoURL);
     this.clientId = trimString(clientId);
     this.clientSecret = trimString(clientSecret);
     this.timeoutMs = Number.isFinite(timeoutMs) && timeoutMs > 0 ? timeoutMs : DEFAULT_TIMEOUT_MS;
@@ -173,10 +208,12 @@ class KeycloakService {
 
     const tokenBase = buildUrlFromEnv(process.env.KEYCLOAK_BASE_URL, process.env.KEYCLOAK_REALM, "/realms/");
     const tokenURL = tokenBase ? `${tokenBase}/protocol/openid-connect/token` : "";
+    const userInfoURL = tokenBase ? `${tokenBase}/protocol/openid-connect/userinfo` : "";
 
     return new KeycloakService({
       adminClientsURL,
       tokenURL,
+      userInfoURL,
       clientId: process.env.AUTH_CLIENT_ID,
       clientSecret: process.env.AUTH_CLIENT_SECRET,
     });
@@ -194,10 +231,11 @@ class KeycloakService {
     }
 
     const email = trimString(typeof input === "string" ? input : input?.email);
+    const owner = trimString(input?.owner);
 
     const token = await this.fetchToken();
     const clientId = randomUUID();
-    const payload = buildKeycloakPayload(clientId, email);
+    const payload = buildKeycloakPayload(clientId, email, owner);
 
     const { signal, cleanup } = createTimeoutSignal(this.timeoutMs);
     let response;
@@ -244,6 +282,123 @@ class KeycloakService {
     }
   }
 
+  /**
+   * Valideert het access token van de aanroeper via het userinfo endpoint van Keycloak en geeft
+   * de identiteit terug. Daarmee hoeft deze service zelf geen JWKS of JWT-validatie te doen.
+   */
+  async fetchCallerIdentity(authorization) {
+    if (!this.userInfoURL) {
+      throw new KeycloakError("Keycloak configuratie ontbreekt", ERROR_CODES.CONFIG);
+    }
+    const accessToken = parseBearerToken(authorization);
+    const response = await this.request(this.userInfoURL, {
+      headers: { Authorization: `Bearer ${accessToken}`, Accept: "application/json" },
+    });
+    const text = truncate(await response.text());
+    if (response.status === 401 || response.status === 403) {
+      throw new KeycloakError("Access token is ongeldig of verlopen", ERROR_CODES.UNAUTHENTICATED);
+    }
+    if (!response.ok) {
+      throw new KeycloakError(`Keycloak userinfo response ${response.status}: ${text}`, ERROR_CODES.GENERIC);
+    }
+    let parsed;
+    try {
+      parsed = JSON.parse(text || "{}");
+    } catch {
+      throw new KeycloakError("Keycloak userinfo response bevat geen geldig JSON", ERROR_CODES.GENERIC);
+    }
+    const subject = trimString(parsed.sub);
+    if (!subject) {
+      throw new KeycloakError("Access token bevat geen subject", ERROR_CODES.UNAUTHENTICATED);
+    }
+    return { subject, email: trimString(parsed.email) };
+  }
+
+  /**
+   * Geeft alle clients van `owner`. De admin API geeft clients per pagina van `CLIENTS_PAGE_SIZE`; de
+   * pagina's worden afgelopen tot er een niet vol is, zodat ook latere clients gevonden worden.
+   */
+  async listClientsForOwner(owner) {
+    if (!this.isConfigured()) {
+      throw new KeycloakError("Keycloak configuratie ontbreekt", ERROR_CODES.CONFIG);
+    }
+    const token = await this.fetchToken();
+    const clients = [];
+    for (let first = 0; ; first += CLIENTS_PAGE_SIZE) {
+      const page = await this.fetchClientsPage(token, owner, first);
+      clients.push(...page);
+      if (page.length < CLIENTS_PAGE_SIZE) {
+        break;
+      }
+    }
+    // Filter ook zelf op eigenaar: oudere Keycloak-versies negeren de q-parameter.
+    return clients.filter((client) => readAttribute(client, "owner") === owner).map(toClientSummary);
+  }
+
+  async fetchClientsPage(token, owner, first) {
+    const url = new URL(this.adminClientsURL);
+    url.searchParams.set("q", `owner:${owner}`);
+    url.searchParams.set("first", String(first));
+    url.searchParams.set("max", String(CLIENTS_PAGE_SIZE));
+    const response = await this.request(url.toString(), {
+      headers: { Authorization: `Bearer ${token}`, Accept: "application/json" },
+    });
+    const text = truncate(await response.text(), Number.POSITIVE_INFINITY);
+    if (response.status === 401 || response.status === 403) {
+      throw new KeycloakError("Geen toegang tot Keycloak admin API", ERROR_CODES.UNAUTHORIZED);
+    }
+    if (!response.ok) {
+      throw new KeycloakError(`Keycloak response ${response.status}: ${truncate(text)}`, ERROR_CODES.GENERIC);
+    }
+    let clients;
+    try {
+      clients = JSON.parse(text || "[]");
+    } catch {
+      throw new KeycloakError("Keycloak clients response bevat geen geldig JSON", ERROR_CODES.GENERIC);
+    }
+    return Array.isArray(clients) ? clients : [];
+  }
+
+  async deleteClientForOwner(owner, apiKey) {
+    const id = trimString(apiKey);
+    const owned = await this.listClientsForOwner(owner);
+    if (!id || !owned.some((client) => client.apiKey === id)) {
+      throw new KeycloakError("Client niet gevonden", ERROR_CODES.NOT_FOUND);
+    }
+    const token = await this.fetchToken();
+    const response = await this.request(`${this.adminClientsURL}/${encodeURIComponent(id)}`, {
+      method: "DELETE",
+      headers: { Authorization: `Bearer ${token}` },
+    });
+    switch (response.status) {
+      case 204:
+        return;
+      case 404:
+        throw new KeycloakError("Client niet gevonden", ERROR_CODES.NOT_FOUND);
+      case 401:
+      case 403:
+        throw new KeycloakError("Geen toegang tot Keycloak admin API", ERROR_CODES.UNAUTHORIZED);
+      default: {
+        const text = truncate(await response.text());
+        throw new KeycloakError(`Keycloak response ${response.status}: ${text}`, ERROR_CODES.GENERIC);
+      }
+    }
+  }
+
+  async request(url, options) {
+    const { signal, cleanup } = createTimeoutSignal(this.timeoutMs);
+    try {
+      return await this.fetch(url, { method: "GET", ...options, signal });
+    } catch (error) {
+      if (error.name === "TimeoutError" || error.name === "AbortError") {
+        throw new KeycloakError("Timeout tijdens verzoek naar Keycloak", ERROR_CODES.GENERIC);
+      }
+      throw new KeycloakError(`Netwerkfout richting Keycloak: ${error.message}`, ERROR_CODES.GENERIC);
+    } finally {
+      cleanup();
+    }
+  }
+
   async fetchToken() {
     if (!this.tokenURL || !this.clientId || !this.clientSecret) {
       throw new KeycloakError("Keycloak configuratie ontbreekt", ERROR_CODES.CONFIG);
@@ -307,6 +462,7 @@ module.exports = {
   KeycloakService,
   KeycloakError,
   ERROR_CODES,
+  parseBearerToken,
   parseUntrustClientInput,
   translateKeycloakError,
 };
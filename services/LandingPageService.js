@@ -0,0 +1,231 @@
+const Service = require("./Service");
+const BrunoConversionService = require("./BrunoConversionService");
+const OasValidatorService = require("./OasValidatorService");
+const PostmanConversionService = require("./PostmanConversionService");
+const { resolveOasDocument } = require("./OasInputService");
+const { sanitizeFileName } = require("../utils/fileName");
+const { expandServerUrl } = require("../utils/openapi");
+const { buildProvenance, describeProvenance, stampDocument } = require("../utils/provenance");
+const { createZip } = require("../utils/zip");
+
+const DEFAULT_FILENAME = "openapi";
+const DOWNLOADS_DIR = "downloads";
+const SAFE_LINK = /^(https?:|mailto:)/i;
+
+const PAGE_STYLES = [
+  "body { font-family: sans-serif; line-height: 1.5; color: #1a1a1a; margin: 0; background: #f5f5f5; }",
+  "main { max-width: 48rem; margin: 0 auto; padding: 2rem 1.5rem; background: #fff; min-height: 100vh; }",
+  "header { border-bottom: 2px solid #154273; margin-bottom: 1.5rem; }",
+  "h1 { color: #154273; margin-bottom: 0.25rem; }",
+  "h2 { color: #154273; font-size: 1.2rem; margin-top: 2rem; }",
+  ".version { color: #555; margin-top: 0; }",
+  ".description { white-space: pre-line; }",
+  "code { font-family: monospace; overflow-wrap: anywhere; }",
+  "footer { margin-top: 3rem; font-size: 0.8rem; color: #666; }",
+];
+
+const escapeHtml = (value) =>
+  String(value ?? "")
+    .replace(/&/g, "&amp;")
+    .replace(/</g, "&lt;")
+    .replace(/>/g, "&gt;")
+    .replace(/"/g, "&quot;");
+
+const text = (value) => (typeof value === "string" ? value.trim() : "");
+
+const scoreColor = (score) => {
+  if (score >= 100) {
+    return "#2e7d32";
+  }
+  if (score >= 80) {
+    return "#689f38";
+  }
+  if (score >= 50) {
+    return "#f9a825";
+  }
+  return "#c62828";
+};
+
+const scoreLabel = (rulesetVersion) => (rulesetVersion === "owasp" ? "OWASP" : `ADR ${rulesetVersion}`);
+
+// Benadering van de tekstbreedte in Verdana 11px, zoals shields.io-badges die gebruiken.
+const badgeTextWidth = (value) => Math.round(value.length * 6.8) + 12;
+
+/**
+ * Badge in de stijl van shields.io met de ADR-score: groen bij 100, oplopend naar rood onder 50.
+ */
+const renderScoreBadge = ({ score, rulesetVersion }) => {
+  const label = scoreLabel(rulesetVersion);
+  const value = `${score}%`;
+  const labelWidth = badgeTextWidth(label);
+  const valueWidth = badgeTextWidth(value);
+  const width = labelWidth + valueWidth;
+  return [
+    `<svg xmlns="http://www.w3.org/2000/svg" width="${width}" height="20" role="img" aria-label="${label}: ${value}">`,
+    `  <title>${label}: ${value}</title>`,
+    `  <rect width="${labelWidth}" height="20" rx="3" fill="#555"/>`,
+    `  <rect x="${labelWidth}" width="${valueWidth}" height="20" rx="3" fill="${scoreColor(score)}"/>`,
+    `  <rect x="${labelWidth}" width="4" height="20" fill="${scoreColor(score)}"/>`,
+    '  <g fill="#fff" text-anchor="middle" font-family="Verdana,DejaVu Sans,sans-serif" font-size="11">',
+    `    <text x="${labelWidth / 2}" y="14">${label}</text>`,
+    `    <text x="${labelWidth + valueWidth / 2}" y="14">${value}</text>`,
+    "  </g>",
+    "</svg>",
+    "",
+  ].join("\n");
+};
+
+const link = (href, label) =>
+  SAFE_LINK.test(href) ? `<a href="${escapeHtml(href)}">${escapeHtml(label)}</a>` : escapeHtml(label);
+
+const section = (title, lines) => (lines.length > 0 ? [`<h2>${escapeHtml(title)}</h2>`, ...lines] : []);
+
+const renderContact = (contact) => {
+  const parts = [];
+  if (text(contact?.name)) {
+    parts.push(escapeHtml(contact.name.trim()));
+  }
+  if (text(contact?.email)) {
+    parts.push(link(`mailto:${contact.email.trim()}`, contact.email.trim()));
+  }
+  if (text(contact?.url)) {
+    parts.push(link(contact.url.trim(), contact.url.trim()));
+  }
+  return parts.length > 0 ? [`<p>${parts.join("<br>")}</p>`] : [];
+};
+
+const renderServers = (servers) => {
+  const items = (Array.isArray(servers) ? servers : [])
+    .map((server) => ({ url: expandServerUrl(server), description: text(server?.description) }))
+    .filter(({ url }) => url)
+    .map(({ url, description }) => {
+      const suffix = description ? `: ${escapeHtml(description)}` : "";
+      return `  <li><code>${escapeHtml(url)}</code>${suffix}</li>`;
+    });
+  return items.length > 0 ? ["<ul>", ...items, "</ul>"] : [];
+};
+
+const renderScore = (lintResult) => {
+  const findings = lintResult.failures === 1 ? "1 error-bevinding" : `${lintResult.failures} error-bevindingen`;
+  const measuredAt = lintResult.createdAt ? ` op ${escapeHtml(String(lintResult.createdAt).slice(0, 10))}` : "";
+  const label = escapeHtml(scoreLabel(lintResult.rulesetVersion));
+  return [
+    `<p><img src="badge.svg" alt="${label}: ${lintResult.score}%"></p>`,
+    `<p>Score ${lintResult.score}% op de ${label} ruleset${measuredAt}, met ${findings}.</p>`,
+  ];
+};
+
+/**
+ * De landingspagina zelf. Alle tekst uit de specificatie wordt ge-escaped en alleen http(s)- en
+ * mailto-links worden klikbaar, zodat een specificatie geen script in de pagina kan krijgen.
+ */
+const renderLandingPage = (document, { lintResult, downloads, provenance }) => {
+  const info = document.info || {};
+  const title = text(info.title) || DEFAULT_FILENAME;
+  const license = text(info.license?.name)
+    ? [`<p>${info.license.url ? link(info.license.url, info.license.name) : escapeHtml(info.license.name)}</p>`]
+    : [];
+  const body = [
+    "<header>",
+    `  <h1>${escapeHtml(title)}</h1>`,
+    ...(text(info.version) ? [`  <p class="version">Versie ${escapeHtml(info.version)}</p>`] : []),
+    "</header>",
+    ...(text(info.description) ? [`<p class="description">${escapeHtml(info.description.trim())}</p>`] : []),
+    ...section("Servers", renderServers(document.servers)),
+    ...section("API Design Rules", renderScore(lintResult)),
+    ...section("Downloads", [
+      "<ul>",
+      ...downloads.map(({ href, label }) => `  <li><a href="${escapeHtml(href)}">${escapeHtml(label)}</a></li>`),
+      "</ul>",
+    ]),
+    ...section("Contact", renderContact(info.contact)),
+    ...section("Licentie", license),
+    "<footer>Gegenereerd met de tools van developer.overheid.nl.</footer>",
+  ];
+  return [
+    "<!DOCTYPE html>",
+    `<!-- ${describeProvenance(provenance).replace(/--/g, "- -")} -->`,
+    '<html lang="nl">',
+    "<head>",
+    '<meta charset="utf-8">',
+    '<meta name="viewport" content="width=device-width, initial-scale=1">',
+    `<title>${escapeHtml(title)}</title>`,
+    "<style>",
+    ...PAGE_STYLES,
+    "</style>",
+    "</head>",
+    "<body>",
+    "<main>",
+    ...body,
+    "</main>",
+    "</body>",
+    "</html>",
+    "",
+  ].join("\n");
+};
+
+// De exports zijn ZIP-streams; die worden hier ingelezen om in de ZIP van de landingspagina te passen.
+const readArtifact = async (name, label, build) => {
+  const { rawBody } = await build();
+  const data = Buffer.isBuffer(rawBody) ? rawBody : Buffer.concat(await rawBody.toArray());
+  return { name: `${DOWNLOADS_DIR}/${name}`, label, data };
+};
+
+/**
+ * Statische landingspagina voor een API om op de base-URL te hosten: `index.html` met info,
+ * servers, contact en de ADR-score, `badge.svg` met die score, de specificatie en de Postman- en
+ * Bruno-collecties om te downloaden. De lint-instellingen (`ruleset`, `ignoreRules`, ...) uit de
+ * request gelden ook voor de score.
+ */
+const convert = async (input) => {
+  const resolved = await resolveOasDocument(input);
+  const title = text(resolved.spec.info?.title) || DEFAULT_FILENAME;
+  const name = sanitizeFileName(title, { fallback: DEFAULT_FILENAME, lowercase: true });
+  // De specificatie is al opgehaald; de lint en de collecties gebruiken dezelfde inhoud.
+  const localInput = { ...input, oasUrl: undefined, oasBody: resolved.contents, newman: false };
+  const lintResult = await OasValidatorService.validate(localInput);
+  const artifacts = [
+    await readArtifact(`${name}-postman.zip`, "Postman-collectie", () => PostmanConversionService.convert(localInput)),
+    await readArtifact(`${name}-bruno.zip`, "Bruno-collectie", () => BrunoConversionService.convert(localInput)),
+  ];
+
+  const provenance = buildProvenance({ tool: "oas-landing-page", source: resolved.source });
+  const document = stampDocument(resolved.spec, provenance);
+  const downloads = [
+    { href: "openapi.json", label: "OpenAPI-specificatie" },
+    ...artifacts.map((artifact) => ({ href: artifact.name, label: artifact.label })),
+  ];
+  let page;
+  try {
+    page = renderLandingPage(document, { lintResult, downloads, provenance });
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Genereren van de landingspagina is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const filenameBase = `${name}-landing`;
+  const files = [
+    { name: "index.html", data: page },
+    { name: "badge.svg", data: renderScoreBadge(lintResult) },
+    { name: "openapi.json", data: `${JSON.stringify(document, null, 2)}\n` },
+    ...artifacts,
+  ];
+
+  return {
+    headers: {
+      "Content-Type": "application/zip",
+      "Content-Disposition": `attachment; filename="${filenameBase}.zip"`,
+    },
+    rawBody: createZip(files.map((file) => ({ name: `${filenameBase}/${file.name}`, data: file.data }))),
+  };
+};
+
+module.exports = {
+  convert,
+  renderLandingPage,
+  renderScoreBadge,
+};
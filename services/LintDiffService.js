@@ -0,0 +1,131 @@
+const Service = require("./Service");
+const OasValidatorService = require("./OasValidatorService");
+const { getLintRunStore } = require("./LintRunStoreService");
+
+const isNonEmptyString = (value) => typeof value === "string" && value.trim().length > 0;
+
+/**
+ * Haalt een opgeslagen lint-run op of lint de meegegeven specificatie. Nieuw gemaakte runs worden
+ * als `created` gemarkeerd zodat de aanroeper ze kan bewaren.
+ */
+const resolveSide = async (side, label, settings, store) => {
+  if (isNonEmptyString(side?.lintId)) {
+    const lintResult = await store.get(side.lintId.trim());
+    if (!lintResult) {
+      throw Service.rejectResponse({ message: `Lint-run voor ${label} niet gevonden of verlopen.` }, 404);
+    }
+    return { lintResult, created: false };
+  }
+  if (isNonEmptyString(side?.oasBody) || isNonEmptyString(side?.oasUrl)) {
+    const lintResult = await OasValidatorService.validate({
+      ...settings,
+      oasBody: side.oasBody,
+      oasUrl: side.oasUrl,
+    });
+    return { lintResult, created: true };
+  }
+  throw Service.rejectResponse({ message: `Geef voor ${label} een lintId, oasUrl of oasBody mee.` }, 400);
+};
+
+const toFinding = (message) => {
+  const info = Array.isArray(message.infos) ? message.infos[0] : undefined;
+  return {
+    code: message.code,
+    severity: message.severity,
+    path: info?.path || "body",
+    message: info?.message || "",
+  };
+};
+
+const fingerprint = (finding) => `${finding.code}|${finding.path}`;
+
+const groupFindings = (lintResult) => {
+  const groups = new Map();
+  for (const finding of (lintResult.messages || []).map(toFinding)) {
+    const key = fingerprint(finding);
+    groups.set(key, [...(groups.get(key) || []), finding]);
+  }
+  return groups;
+};
+
+const countErrors = (findings) => findings.filter((finding) => finding.severity === "error").length;
+
+const resolveVerdict = (scoreDelta, newFindings, resolvedFindings) => {
+  if (scoreDelta !== 0) {
+    return scoreDelta > 0 ? "improved" : "regressed";
+  }
+  const errorDelta = countErrors(resolvedFindings) - countErrors(newFindings);
+  if (errorDelta !== 0) {
+    return errorDelta > 0 ? "improved" : "regressed";
+  }
+  return "unchanged";
+};
+
+const describeRun = (lintResult) => ({
+  lintId: lintResult.id,
+  score: lintResult.score,
+  failures: lintResult.failures,
+  rulesetVersion: lintResult.rulesetVersion,
+});
+
+/**
+ * Vergelijkt twee LintResults. Bevindingen worden gematcht op regelcode en pad; de melding zelf
+ * telt niet mee omdat die per ruleset-versie kan verschillen. Komt dezelfde combinatie vaker voor,
+ * dan wordt alleen het verschil in aantal als nieuw of opgelost gerapporteerd.
+ */
+const diffLintResults = (baseResult, headResult) => {
+  const baseGroups = groupFindings(baseResult);
+  const headGroups = groupFindings(headResult);
+  const newFindings = [];
+  const resolvedFindings = [];
+  const unchangedFindings = [];
+  for (const [key, headFindings] of headGroups) {
+    const baseCount = baseGroups.get(key)?.length || 0;
+    unchangedFindings.push(...headFindings.slice(0, baseCount));
+    newFindings.push(...headFindings.slice(baseCount));
+  }
+  for (const [key, baseFindings] of baseGroups) {
+    const headCount = headGroups.get(key)?.length || 0;
+    resolvedFindings.push(...baseFindings.slice(headCount));
+  }
+  const scoreDelta = (headResult.score ?? 0) - (baseResult.score ?? 0);
+  return {
+    base: describeRun(baseResult),
+    head: describeRun(headResult),
+    scoreDelta,
+    verdict: resolveVerdict(scoreDelta, newFindings, resolvedFindings),
+    summary: {
+      new: newFindings.length,
+      resolved: resolvedFindings.length,
+      unchanged: unchangedFindings.length,
+    },
+    new: newFindings,
+    resolved: resolvedFindings,
+    unchanged: unchangedFindings,
+  };
+};
+
+/**
+ * Lint-diff tussen `base` en `head`; elk is een opgeslagen lintId of een specificatie (oasUrl/oasBody)
+ * die met dezelfde instellingen gelint wordt. Nieuw gemaakte LintResults worden ook teruggegeven.
+ */
+const diff = async (input, { store = getLintRunStore() } = {}) => {
+  const settings = {
+    targetVersion: input?.targetVersion,
+    ruleset: input?.ruleset,
+    ignoreRules: input?.ignoreRules,
+    engine: input?.engine,
+    headers: input?.headers,
+  };
+  const base = await resolveSide(input?.base, "base", settings, store);
+  const head = await resolveSide(input?.head, "head", settings, store);
+  return {
+    diff: diffLintResults(base.lintResult, head.lintResult),
+    lintResults: [base, head].filter((side) => side.created).map((side) => side.lintResult),
+  };
+};
+
+module.exports = {
+  diff,
+  diffLintResults,
+};
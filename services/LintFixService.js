@@ -0,0 +1,82 @@
+const OasValidatorService = require("./OasValidatorService");
+const { scaffoldContact } = require("./AdrScaffoldService");
+const { resolveOasDocument, serializeOasDocument } = require("./OasInputService");
+const { formatPointer } = require("../utils/jsonPointer");
+const { collectServerLists } = require("../utils/openapi");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+const pointer = (...segments) => formatPointer(segments);
+
+/**
+ * Haalt de slash aan het eind van paden weg (`/dieren/` wordt `/dieren`). Bestaat het pad zonder
+ * slash al, dan blijft het pad staan: samenvoegen is geen veilige automatische correctie.
+ */
+const fixTrailingSlashes = (spec, record) => {
+  if (!isObject(spec.paths)) {
+    return;
+  }
+  const paths = {};
+  for (const [path, pathItem] of Object.entries(spec.paths)) {
+    const trimmed = path.length > 1 ? path.replace(/\/+$/, "") || "/" : path;
+    if (trimmed !== path && !Object.hasOwn(spec.paths, trimmed)) {
+      paths[trimmed] = pathItem;
+      record("paths-no-trailing-slash", pointer("paths", path), `Pad ${path} hernoemd naar ${trimmed}.`);
+    } else {
+      paths[path] = pathItem;
+    }
+  }
+  spec.paths = paths;
+};
+
+const fixHttpServers = (spec, record) => {
+  for (const { servers, at } of collectServerLists(spec)) {
+    servers.forEach((server, index) => {
+      if (typeof server?.url === "string" && /^http:\/\//i.test(server.url)) {
+        const url = server.url.replace(/^http:\/\//i, "https://");
+        record("owasp:api8:2023-no-server-http", pointer(...at, index, "url"), `${server.url} wordt ${url}.`);
+        server.url = url;
+      }
+    });
+  }
+};
+
+/**
+ * Past de automatische correcties toe voor de regels waarvoor dat veilig kan: paden zonder slash aan
+ * het eind, een `info.contact` skelet met `@TODO`-placeholders en `https` in plaats van `http` voor
+ * servers. `fixes` noemt per correctie de regel en de plek (JSON pointer in het origineel).
+ */
+const applyLintFixes = (spec) => {
+  const fixes = [];
+  const record = (rule, path, message) => fixes.push({ rule, path, message });
+  // Eerst de servers, zodat de pointers nog de oorspronkelijke paden noemen.
+  fixHttpServers(spec, record);
+  fixTrailingSlashes(spec, record);
+  scaffoldContact(spec, undefined, (path, message) => record("info-contact-fields-exist", path, message));
+  return { spec, fixes };
+};
+
+/**
+ * Corrigeert de specificatie en lint het resultaat opnieuw met dezelfde instellingen (ruleset,
+ * ignoreRules, engine, ...). De gecorrigeerde specificatie komt als tekst terug in het formaat van de
+ * input, samen met het LintResult van de bevindingen die overblijven.
+ */
+const fix = async (input) => {
+  const { spec, format, source } = await resolveOasDocument(input);
+  const { fixes } = applyLintFixes(spec);
+  stampDocument(spec, buildProvenance({ tool: "lint-fix", source }));
+  const contents = serializeOasDocument(spec, format, "openapi-fixed").rawBody.toString("utf8");
+  const lintResult = await OasValidatorService.validate({
+    ...input,
+    oasUrl: undefined,
+    oasBody: contents,
+    callbackUrl: undefined,
+  });
+  return { fixes, format, spec: contents, lintResult };
+};
+
+module.exports = {
+  applyLintFixes,
+  fix,
+};
@@ -0,0 +1,85 @@
+const SEVERITY_ORDER = ["error", "warning", "info", "hint"];
+const SEVERITY_ICONS = { error: "❌", warning: "⚠️", info: "ℹ️", hint: "💡" };
+const MAX_PATHS_PER_RULE = 20;
+
+const escapeTableCell = (value) =>
+  String(value ?? "")
+    .replace(/\|/g, "\\|")
+    .replace(/\r?\n/g, " ");
+
+const severityRank = (severity) => {
+  const index = SEVERITY_ORDER.indexOf(String(severity).toLowerCase());
+  return index === -1 ? SEVERITY_ORDER.length : index;
+};
+
+const groupByRule = (messages) => {
+  const groups = new Map();
+  for (const message of messages) {
+    const info = Array.isArray(message.infos) ? message.infos[0] : undefined;
+    const group = groups.get(message.code) || {
+      code: message.code,
+      severity: String(message.severity).toLowerCase(),
+      message: info?.message || "",
+      paths: [],
+    };
+    group.paths.push(info?.path || "body");
+    groups.set(message.code, group);
+  }
+  return Array.from(groups.values()).sort(
+    (a, b) => severityRank(a.severity) - severityRank(b.severity) || b.paths.length - a.paths.length,
+  );
+};
+
+const renderRuleDetails = (group) => {
+  const shown = group.paths.slice(0, MAX_PATHS_PER_RULE).map((path) => `- \`${path}\``);
+  const hidden = group.paths.length - shown.length;
+  return [
+    "<details>",
+    `<summary><code>${group.code}</code> (${group.paths.length})</summary>`,
+    "",
+    group.message,
+    "",
+    ...shown,
+    ...(hidden > 0 ? [`- … en nog ${hidden}`] : []),
+    "",
+    "</details>",
+  ];
+};
+
+/**
+ * Markdown-samenvatting van een LintResult, bedoeld om door een bot als PR-commentaar te plaatsen:
+ * score, een tabel met bevindingen per regel en per regel een inklapbare lijst met paden.
+ */
+const renderMarkdown = (lintResult, { title = "Resultaat API Design Rules" } = {}) => {
+  const messages = Array.isArray(lintResult?.messages) ? lintResult.messages : [];
+  const groups = groupByRule(messages);
+  const status = lintResult.successes ? "✅ Voldoet" : "❌ Voldoet niet";
+  const lines = [
+    `## ${title}`,
+    "",
+    `**Score:** ${lintResult.score}/100 · ${status} · ruleset ${lintResult.rulesetVersion}`,
+    "",
+  ];
+  if (groups.length === 0) {
+    lines.push("Geen bevindingen.");
+  } else {
+    lines.push("| | Regel | Severity | Aantal | Melding |", "| --- | --- | --- | ---: | --- |");
+    for (const group of groups) {
+      const icon = SEVERITY_ICONS[group.severity] || "";
+      const cells = [icon, `\`${group.code}\``, group.severity, group.paths.length, escapeTableCell(group.message)];
+      lines.push(`| ${cells.join(" | ")} |`);
+    }
+    lines.push("");
+    for (const group of groups) {
+      lines.push(...renderRuleDetails(group));
+    }
+  }
+  if (lintResult.id) {
+    lines.push("", `<sub>Lint-run \`${lintResult.id}\`</sub>`);
+  }
+  return `${lines.join("\n")}\n`;
+};
+
+module.exports = {
+  renderMarkdown,
+};
@@ -0,0 +1,131 @@
+const Service = require("./Service");
+const OasValidatorService = require("./OasValidatorService");
+const { RegisterError, getRegisterClient } = require("./RegisterClientService");
+const logger = require("../logger");
+
+const TOP_VIOLATIONS_LIMIT = 10;
+
+const isNonEmptyString = (value) => typeof value === "string" && value.trim().length > 0;
+
+const resolveOasUrlsFromRegister = async (organisationUri, registerClient) => {
+  let apis;
+  try {
+    apis = await registerClient.listApisForOrganisation(organisationUri);
+  } catch (error) {
+    if (error instanceof RegisterError) {
+      logger.error(`[LintReportService] register lookup for ${organisationUri} failed: ${error.message}`);
+      throw Service.rejectResponse(
+        { message: "Het ophalen van de API's uit het register is mislukt.", detail: error.message },
+        502,
+      );
+    }
+    throw error;
+  }
+  const oasUrls = apis.map((api) => api?.oasUrl).filter(isNonEmptyString);
+  if (oasUrls.length === 0) {
+    throw Service.rejectResponse(
+      { message: "Het register bevat geen API's met een oasUrl voor deze organisatie.", detail: organisationUri },
+      404,
+    );
+  }
+  return Array.from(new Set(oasUrls));
+};
+
+const resolveReportUrls = async (input, registerClient) => {
+  if (Array.isArray(input?.oasUrls) && input.oasUrls.length > 0) {
+    return input.oasUrls;
+  }
+  if (isNonEmptyString(input?.organisationUri)) {
+    return resolveOasUrlsFromRegister(input.organisationUri.trim(), registerClient);
+  }
+  throw Service.rejectResponse({ message: "Geef oasUrls of een organisationUri mee." }, 400);
+};
+
+const countBySeverity = (messages, severity) =>
+  messages.filter((message) => String(message.severity).toLowerCase() === severity).length;
+
+const summarizeItem = ({ oasUrl, lintResult, error }) => {
+  if (error) {
+    return { oasUrl, error };
+  }
+  return {
+    oasUrl,
+    lintId: lintResult.id,
+    score: lintResult.score,
+    errors: countBySeverity(lintResult.messages, "error"),
+    warnings: countBySeverity(lintResult.messages, "warning"),
+  };
+};
+
+/**
+ * Telt per regelcode hoe vaak een bevinding voorkomt en bij hoeveel API's. De lijst is gesorteerd
+ * op het aantal geraakte API's, zodat de meest wijdverspreide overtredingen bovenaan staan.
+ */
+const collectTopViolations = (lintResults) => {
+  const violations = new Map();
+  for (const lintResult of lintResults) {
+    const seen = new Set();
+    for (const message of lintResult.messages) {
+      const entry = violations.get(message.code) || {
+        code: message.code,
+        severity: message.severity,
+        count: 0,
+        apiCount: 0,
+      };
+      entry.count += 1;
+      if (!seen.has(message.code)) {
+        entry.apiCount += 1;
+        seen.add(message.code);
+      }
+      violations.set(message.code, entry);
+    }
+  }
+  return Array.from(violations.values())
+    .sort((a, b) => b.apiCount - a.apiCount || b.count - a.count || a.code.localeCompare(b.code))
+    .slice(0, TOP_VIOLATIONS_LIMIT);
+};
+
+const aggregate = (items) => {
+  const lintResults = items.filter((item) => item.lintResult).map((item) => item.lintResult);
+  const totalScore = lintResults.reduce((sum, lintResult) => sum + lintResult.score, 0);
+  return {
+    apiCount: items.length,
+    lintedCount: lintResults.length,
+    failedCount: items.length - lintResults.length,
+    averageScore: lintResults.length > 0 ? Math.round((totalScore / lintResults.length) * 10) / 10 : null,
+    compliantCount: lintResults.filter((lintResult) => lintResult.successes).length,
+    topViolations: collectTopViolations(lintResults),
+    apis: items.map(summarizeItem),
+  };
+};
+
+// Een organisatie kan meer API's hebben dan er in één batch passen; die gaan dan in delen. `headers`
+// blijven ook dan beperkt tot de origin van de eerste URL.
+const lintInBatches = async (input, oasUrls) => {
+  const items = [];
+  for (let start = 0; start < oasUrls.length; start += OasValidatorService.MAX_BATCH_SIZE) {
+    const batch = oasUrls.slice(start, start + OasValidatorService.MAX_BATCH_SIZE);
+    const results = await OasValidatorService.validateBatch({ ...input, oasUrls: batch }, { headerUrl: oasUrls[0] });
+    items.push(...results);
+  }
+  return items;
+};
+
+/**
+ * Lint alle API's van een organisatie (of een opgegeven lijst oasUrls) en vat de resultaten
+ * samen. De losse LintResults worden ook teruggegeven zodat de aanroeper ze kan bewaren.
+ */
+const buildReport = async (input, { registerClient = getRegisterClient() } = {}) => {
+  const oasUrls = await resolveReportUrls(input, registerClient);
+  const items = await lintInBatches(input, oasUrls);
+  const report = aggregate(items);
+  if (isNonEmptyString(input?.organisationUri)) {
+    report.organisationUri = input.organisationUri.trim();
+  }
+  return { report, lintResults: items.map((item) => item.lintResult).filter(Boolean) };
+};
+
+module.exports = {
+  aggregate,
+  buildReport,
+};
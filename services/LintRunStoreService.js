@@ -0,0 +1,185 @@
+const fs = require("node:fs");
+const path = require("node:path");
+const config = require("../config");
+const { decrypt, encrypt, getArtifactStore } = require("./ArtifactStoreService");
+const logger = require("../logger");
+
+const NAMESPACE = "lint";
+const LINT_ID_PATTERN = /^[A-Za-z0-9-]{1,64}$/;
+const DEFAULT_SQLITE_RETENTION_DAYS = 365;
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+/**
+ * Opslag van lint-runs. Een implementatie biedt `save(lintResult)` en `get(id)`; `get` geeft
+ * undefined terug als de run onbekend of verlopen is. De standaardimplementatie bewaart runs
+ * versleuteld in de ArtifactStore, zodat ze dezelfde bewaartermijn volgen als andere artifacts:
+ * na `ARTIFACT_RETENTION_DAYS` verdwijnt een run en werkt een link ernaar niet meer.
+ */
+class ArtifactLintRunStore {
+  constructor(artifactStore) {
+    this.artifactStore = artifactStore;
+  }
+
+  async save(lintResult) {
+    await this.artifactStore.saveJson(NAMESPACE, lintResult.id, lintResult);
+    return lintResult.id;
+  }
+
+  async get(id) {
+    if (!isValidLintId(id)) {
+      return undefined;
+    }
+    return this.artifactStore.loadJson(NAMESPACE, id);
+  }
+}
+
+/**
+ * Bewaart runs in een SQLite-database (`node:sqlite`), los van de artifact-opslag en met een eigen
+ * bewaartermijn, zodat links vanuit het portaal langer blijven werken. De LintResult staat net als
+ * in de ArtifactStore versleuteld in de database; verlopen runs worden bij het opslaan verwijderd.
+ */
+// `node:sqlite` is experimenteel: zonder vlag pas vanaf Node 22.13 (en 23.4), met `--experimental-sqlite`
+// vanaf 22.5. Pas hier laden, want alleen deze opslag heeft het nodig.
+const loadSqlite = () => {
+  const [major, minor] = process.versions.node.split(".").map(Number);
+  if (major > 22 || (major === 22 && minor >= 5)) {
+    try {
+      return require("node:sqlite");
+    } catch {
+      // valt door naar de foutmelding hieronder
+    }
+  }
+  throw new Error(
+    `LINT_RUN_STORE=sqlite vraagt Node 22.13 of nieuwer (of 22.5 met --experimental-sqlite), niet ${process.version}.`,
+  );
+};
+
+class SqliteLintRunStore {
+  constructor({ database, encryptionKey, retentionDays = DEFAULT_SQLITE_RETENTION_DAYS }) {
+    this.database = database;
+    this.key = encryptionKey;
+    const days = Number(retentionDays);
+    this.retentionMs = (Number.isFinite(days) && days > 0 ? days : DEFAULT_SQLITE_RETENTION_DAYS) * DAY_MS;
+    database.exec(
+      "CREATE TABLE IF NOT EXISTS lint_runs (id TEXT PRIMARY KEY, created_at INTEGER NOT NULL, payload BLOB NOT NULL)",
+    );
+    database.exec("CREATE INDEX IF NOT EXISTS lint_runs_created_at ON lint_runs (created_at)");
+  }
+
+  static open(filename, options) {
+    const { DatabaseSync } = loadSqlite();
+    fs.mkdirSync(path.dirname(filename), { recursive: true, mode: 0o700 });
+    return new SqliteLintRunStore({ ...options, database: new DatabaseSync(filename) });
+  }
+
+  async save(lintResult, now = Date.now()) {
+    this.database.prepare("DELETE FROM lint_runs WHERE created_at < ?").run(now - this.retentionMs);
+    this.database
+      .prepare("INSERT OR REPLACE INTO lint_runs (id, created_at, payload) VALUES (?, ?, ?)")
+      .run(lintResult.id, now, encrypt(this.key, Buffer.from(JSON.stringify(lintResult), "utf8")));
+    return lintResult.id;
+  }
+
+  async get(id, now = Date.now()) {
+    if (!isValidLintId(id)) {
+      return undefined;
+    }
+    const row = this.database
+      .prepare("SELECT payload FROM lint_runs WHERE id = ? AND created_at >= ?")
+      .get(id, now - this.retentionMs);
+    if (!row) {
+      return undefined;
+    }
+    try {
+      return JSON.parse(decrypt(this.key, Buffer.from(row.payload)).toString("utf8"));
+    } catch (error) {
+      logger.warn(`[LintRunStoreService] lint-run ${id} niet leesbaar: ${error.message}`);
+      return undefined;
+    }
+  }
+}
+
+class NoopLintRunStore {
+  async save(lintResult) {
+    return lintResult.id;
+  }
+
+  async get() {
+    return undefined;
+  }
+}
+
+const isValidLintId = (id) => typeof id === "string" && LINT_ID_PATTERN.test(id);
+
+// Runs in een blijvende opslag moeten na een herstart leesbaar zijn, dus niet met een tijdelijke sleutel.
+const requireConfiguredKey = (artifactStore, backend) => {
+  if (artifactStore.ephemeralKey) {
+    throw new Error(
+      `LINT_RUN_STORE=${backend} vraagt een ARTIFACT_ENCRYPTION_KEY; anders zijn runs na een herstart onleesbaar.`,
+    );
+  }
+  return artifactStore;
+};
+
+/**
+ * Maakt de opslag voor `LINT_RUN_STORE`. `artifact` en `sqlite` weigeren te starten zonder
+ * `ARTIFACT_ENCRYPTION_KEY`; zonder instelling is het `artifact` als er een sleutel is en anders `none`.
+ */
+const createLintRunStore = (backend = process.env.LINT_RUN_STORE) => {
+  const normalized = String(backend || "")
+    .trim()
+    .toLowerCase();
+  switch (normalized) {
+    case "artifact":
+      return new ArtifactLintRunStore(requireConfiguredKey(getArtifactStore(), normalized));
+    case "sqlite":
+      return SqliteLintRunStore.open(process.env.LINT_RUN_DB || config.LINT_RUN_DB, {
+        encryptionKey: requireConfiguredKey(getArtifactStore(), normalized).key,
+        retentionDays: process.env.LINT_RUN_RETENTION_DAYS,
+      });
+    case "none":
+      return new NoopLintRunStore();
+    default:
+      if (normalized) {
+        logger.warn(`[LintRunStoreService] onbekende LINT_RUN_STORE "${backend}", de standaardopslag wordt gebruikt`);
+      }
+      if (getArtifactStore().ephemeralKey) {
+        logger.warn("[LintRunStoreService] ARTIFACT_ENCRYPTION_KEY ontbreekt; lint-runs worden niet bewaard.");
+        return new NoopLintRunStore();
+      }
+      return new ArtifactLintRunStore(getArtifactStore());
+  }
+};
+
+let defaultStore;
+
+const getLintRunStore = () => {
+  if (!defaultStore) {
+    defaultStore = createLintRunStore();
+  }
+  return defaultStore;
+};
+
+/**
+ * Bewaart een lint-run zonder dat een opslagfout de validatie zelf laat mislukken.
+ */
+const persistLintRun = async (lintResult, store = getLintRunStore()) => {
+  if (!lintResult || typeof lintResult.id !== "string") {
+    return;
+  }
+  try {
+    await store.save(lintResult);
+  } catch (error) {
+    logger.warn(`[LintRunStoreService] lint-run ${lintResult.id} niet opgeslagen: ${error.message}`);
+  }
+};
+
+module.exports = {
+  ArtifactLintRunStore,
+  NoopLintRunStore,
+  SqliteLintRunStore,
+  createLintRunStore,
+  getLintRunStore,
+  isValidLintId,
+  persistLintRun,
+};
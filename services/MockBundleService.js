@@ -0,0 +1,102 @@
+const Service = require("./Service");
+const { bundle } = require("./OasBundleService");
+const { sanitizeFileName } = require("../utils/fileName");
+const { PROVENANCE_EXTENSION, buildProvenance, describeProvenance } = require("../utils/provenance");
+const { parseJsonOrYaml } = require("../utils/yaml");
+const { createZip } = require("../utils/zip");
+
+const DEFAULT_FILENAME = "openapi";
+const PRISM_IMAGE = "stoplight/prism:5";
+const PRISM_PACKAGE = "@stoplight/prism-cli@5";
+const PRISM_PORT = 4010;
+const EXECUTABLE_MODE = 0o755;
+
+const renderCompose = (specName, dynamic, provenance) =>
+  [
+    `# ${describeProvenance(provenance)}`,
+    "services:",
+    "  prism:",
+    `    image: ${PRISM_IMAGE}`,
+    `    command: mock -h 0.0.0.0${dynamic ? " --dynamic" : ""} /api/${specName}`,
+    "    volumes:",
+    `      - ./${specName}:/api/${specName}:ro`,
+    "    ports:",
+    `      - "${PRISM_PORT}:${PRISM_PORT}"`,
+    "",
+  ].join("\n");
+
+const renderScript = (specName, dynamic, provenance) =>
+  [
+    "#!/bin/sh",
+    `# ${describeProvenance(provenance)}`,
+    "# Start de Prism mock-server zonder Docker; vereist Node.js 18 of nieuwer.",
+    "set -e",
+    'cd "$(dirname "$0")"',
+    `exec npx --yes ${PRISM_PACKAGE} mock --port "\${PORT:-${PRISM_PORT}}"${dynamic ? " --dynamic" : ""} ${specName}`,
+    "",
+  ].join("\n");
+
+const renderReadme = (title, specName, dynamic) =>
+  [
+    `# Mock-server voor ${title}`,
+    "",
+    `Start met Docker (\`docker compose up\`) of zonder Docker met \`./mock.sh\`. De mock draait op`,
+    `http://localhost:${PRISM_PORT} en gebruikt \`${specName}\`: de specificatie met alle verwijzingen opgelost.`,
+    "",
+    dynamic
+      ? "Responses worden uit de schema's gegenereerd (`--dynamic`)."
+      : "Responses komen uit de voorbeelden in de specificatie; zonder voorbeeld maakt Prism er een uit het schema.",
+    "",
+    "Met de `Prefer` header kies je per request een andere response:",
+    "",
+    "- `Prefer: code=404`: de response met die statuscode",
+    "- `Prefer: example=<naam>`: een benoemd voorbeeld",
+    "- `Prefer: dynamic=true`: een uit het schema gegenereerde response",
+    "",
+    "Requests worden tegen de specificatie gevalideerd; een ongeldige request geeft een `422` met de fouten.",
+    "",
+  ].join("\n");
+
+/**
+ * Kant-en-klare mock-setup met Prism: de gebundelde specificatie zonder `$ref`s (via de bundler),
+ * een `docker-compose.yml`, een `mock.sh` voor `npx` en een README. `mockDynamic` laat Prism
+ * responses uit de schema's genereren in plaats van de voorbeelden te gebruiken.
+ */
+const convert = async (input) => {
+  const dynamic = input?.mockDynamic === true;
+  const bundled = await bundle(input);
+  const format = bundled.headers["Content-Type"] === "application/json" ? "json" : "yaml";
+  let document;
+  try {
+    ({ document } = parseJsonOrYaml(bundled.rawBody.toString("utf8")));
+  } catch (error) {
+    throw Service.rejectResponse(
+      { message: "De gebundelde specificatie kon niet worden gelezen.", detail: error.message },
+      500,
+    );
+  }
+  // De specificatie gaat ongewijzigd mee (met de herkomst van de bundler, en bij YAML met de ankers
+  // voor circulaire verwijzingen); de overige bestanden nemen de bron daaruit over.
+  const provenance = buildProvenance({ tool: "oas-prism-mock", source: document?.[PROVENANCE_EXTENSION]?.source });
+  const title = (typeof document?.info?.title === "string" && document.info.title.trim()) || DEFAULT_FILENAME;
+  const specName = `openapi.${format}`;
+  const filenameBase = `${sanitizeFileName(title, { fallback: DEFAULT_FILENAME, lowercase: true })}-mock`;
+  const files = [
+    { name: specName, data: bundled.rawBody },
+    { name: "docker-compose.yml", data: renderCompose(specName, dynamic, provenance) },
+    { name: "mock.sh", data: renderScript(specName, dynamic, provenance), mode: EXECUTABLE_MODE },
+    { name: "README.md", data: renderReadme(title, specName, dynamic) },
+  ];
+
+  return {
+    headers: {
+      "Content-Type": "application/zip",
+      "Content-Disposition": `attachment; filename="${filenameBase}.zip"`,
+    },
+    rawBody: createZip(files.map((file) => ({ ...file, name: `${filenameBase}/${file.name}` }))),
+  };
+};
+
+module.exports = {
+  convert,
+};
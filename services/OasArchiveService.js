@@ -0,0 +1,205 @@
+const fs = require("node:fs/promises");
+const os = require("node:os");
+const path = require("node:path");
+const { execFile } = require("node:child_process");
+const { promisify } = require("node:util");
+const Service = require("./Service");
+const { stripBom } = require("../utils/encoding");
+const { InputTooLargeError, assertInputSize } = require("../utils/inputSize");
+const { OUTBOUND_GUARD_ARGS } = require("../utils/outboundPolicy");
+const { YamlLimitError, parseJsonOrYaml } = require("../utils/yaml");
+const { readZip } = require("../utils/zip");
+const logger = require("../logger");
+
+const TEMP_PREFIX = "oas-archive-";
+const ROOT_NAMES = ["openapi.yaml", "openapi.yml", "openapi.json"];
+const SPEC_FILE = /\.(ya?ml|json)$/i;
+const URL_REF = /^[a-z][a-z0-9+.-]*:/i;
+const HTTP_REF = /^https?:\/\//i;
+const DRIVE_LETTER = /^[a-z]:/i;
+// Een fictieve hoofdmap om verwijzingen tegen op te lossen; wat erbuiten uitkomt, zit niet in het archief.
+const ARCHIVE_ROOT = "/archive";
+const BASE64_PATTERN = /^[A-Za-z0-9+/]+={0,2}$/;
+const execFileAsync = promisify(execFile);
+
+const reject = (message, detail) => Service.rejectResponse(detail ? { message, detail } : { message }, 400);
+
+const decodeArchive = (archive) => {
+  const text = archive.replace(/\s+/g, "").replace(/^data:[^,]*,/, "");
+  if (!BASE64_PATTERN.test(text)) {
+    throw reject("oasArchive moet een base64-gecodeerde ZIP zijn.");
+  }
+  try {
+    return readZip(Buffer.from(text, "base64"));
+  } catch (error) {
+    throw reject("Het archief met de specificatie kon niet worden uitgepakt.", error.message);
+  }
+};
+
+/**
+ * Bestandsnamen als relatief POSIX-pad binnen het archief. Een naam die buiten het archief wijst
+ * (absoluut pad of `..`) maakt het hele archief ongeldig.
+ */
+const normalizeEntries = (entries) => {
+  const files = new Map();
+  for (const { name, data } of entries) {
+    const normalized = path.posix.normalize(name.replace(/\\/g, "/"));
+    if (path.posix.isAbsolute(normalized) || normalized === ".." || normalized.startsWith("../")) {
+      throw reject(`Het archief bevat een bestand buiten de hoofdmap: ${name}.`);
+    }
+    files.set(normalized, data);
+  }
+  return files;
+};
+
+/**
+ * Het hoofddocument: `oasArchiveRoot` als die is meegegeven, anders de `openapi.yaml`, `openapi.yml`
+ * of `openapi.json` die het minst diep in het archief staat (een ZIP van GitHub heeft één map
+ * bovenaan). Twee kandidaten op dezelfde diepte zijn dubbelzinnig.
+ */
+const findRoot = (files, requestedRoot) => {
+  if (typeof requestedRoot === "string" && requestedRoot.trim()) {
+    const root = path.posix.normalize(requestedRoot.trim().replace(/\\/g, "/"));
+    if (!files.has(root)) {
+      throw reject(`oasArchiveRoot ${requestedRoot} zit niet in het archief.`);
+    }
+    return root;
+  }
+  const depth = (name) => name.split("/").length;
+  const candidates = [...files.keys()].filter((name) => ROOT_NAMES.includes(path.posix.basename(name).toLowerCase()));
+  if (candidates.length === 0) {
+    throw reject("Het archief bevat geen openapi.yaml of openapi.json. Geef het hoofddocument mee met oasArchiveRoot.");
+  }
+  const minimum = Math.min(...candidates.map(depth));
+  const shallowest = candidates.filter((name) => depth(name) === minimum);
+  if (shallowest.length > 1) {
+    throw reject(`Het archief bevat meer dan één hoofddocument: ${shallowest.join(", ")}. Kies met oasArchiveRoot.`);
+  }
+  return shallowest[0];
+};
+
+const decodeRefPath = (value) => {
+  try {
+    return decodeURIComponent(value);
+  } catch {
+    return value;
+  }
+};
+
+const collectRefs = (node, refs = []) => {
+  if (Array.isArray(node)) {
+    node.forEach((item) => collectRefs(item, refs));
+  } else if (node && typeof node === "object") {
+    for (const [key, value] of Object.entries(node)) {
+      if (key === "$ref" && typeof value === "string") {
+        refs.push(value);
+      } else {
+        collectRefs(value, refs);
+      }
+    }
+  }
+  return refs;
+};
+
+/**
+ * Het bestand in het archief waar een verwijzing vanuit `name` naar wijst, of undefined als de verwijzing
+ * buiten het archief uitkomt. Een absoluut pad of een schijfletter wordt door de bundler als pad op de
+ * schijf van de server gelezen en wijst dus nooit naar het archief, ook niet als er een bestand
+ * `etc/passwd` in zit.
+ */
+const resolveArchiveRef = (name, file) => {
+  const decoded = decodeRefPath(file).replace(/\\/g, "/");
+  if (path.posix.isAbsolute(decoded) || DRIVE_LETTER.test(decoded)) {
+    return undefined;
+  }
+  const resolved = path.posix.resolve(ARCHIVE_ROOT, path.posix.dirname(name), decoded);
+  return resolved.startsWith(`${ARCHIVE_ROOT}/`) ? resolved.slice(ARCHIVE_ROOT.length + 1) : undefined;
+};
+
+/**
+ * Controleert de `$ref`s van alle JSON- en YAML-bestanden: een relatieve verwijzing moet naar een
+ * bestand in het archief wijzen. Zo leest de bundler nooit iets van de schijf van de server.
+ * Verwijzingen naar een http(s)-URL (zoals het ADR components-bestand) blijven toegestaan; andere
+ * schemes, zoals `file:`, niet.
+ */
+const assertRefsWithinArchive = (files) => {
+  for (const [name, data] of files) {
+    if (!SPEC_FILE.test(name)) {
+      continue;
+    }
+    let document;
+    try {
+      ({ document } = parseJsonOrYaml(stripBom(data.toString("utf8"))));
+    } catch (error) {
+      throw reject(
+        error instanceof YamlLimitError ? error.message : `Kan ${name} uit het archief niet parseren.`,
+        error.message,
+      );
+    }
+    for (const ref of collectRefs(document)) {
+      const [file] = ref.split("#");
+      if (!file || HTTP_REF.test(file)) {
+        continue;
+      }
+      const target = URL_REF.test(file) ? undefined : resolveArchiveRef(name, file);
+      if (target === undefined) {
+        throw reject(`${name} verwijst naar ${file}, buiten het archief. Gebruik een relatief pad of een http(s)-URL.`);
+      }
+      if (!files.has(target)) {
+        throw reject(`${name} verwijst naar ${file}, maar dat bestand zit niet in het archief.`);
+      }
+    }
+  }
+};
+
+// Pas bij gebruik opgezocht: OasInputService laadt deze module en heeft de CLI verder niet nodig.
+const runRedoclyBundle = async (inputPath, outputPath) => {
+  const redoclyBin = require.resolve("@redocly/cli/bin/cli");
+  const args = [redoclyBin, "bundle", inputPath, "--output", outputPath, "--ext", "json"];
+  return execFileAsync(process.execPath, [...OUTBOUND_GUARD_ARGS, ...args], { maxBuffer: 20 * 1024 * 1024 });
+};
+
+/**
+ * Maakt van een base64-gecodeerde ZIP met een specificatie in meerdere bestanden één document: de
+ * bestanden worden in een tijdelijke map uitgepakt en met `redocly bundle` samengevoegd, waarbij
+ * verwijzingen naar andere bestanden componenten worden. Het resultaat gaat verder als `oasBody`.
+ */
+const resolveArchiveInput = async (input) => {
+  const files = normalizeEntries(decodeArchive(input.oasArchive));
+  const root = findRoot(files, input.oasArchiveRoot);
+  assertRefsWithinArchive(files);
+
+  let tmpDir;
+  try {
+    tmpDir = await fs.mkdtemp(path.join(os.tmpdir(), TEMP_PREFIX));
+    for (const [name, data] of files) {
+      const filePath = path.join(tmpDir, ...name.split("/"));
+      await fs.mkdir(path.dirname(filePath), { recursive: true });
+      await fs.writeFile(filePath, data);
+    }
+    const outputPath = path.join(tmpDir, `${TEMP_PREFIX}bundle.json`);
+    await runRedoclyBundle(path.join(tmpDir, ...root.split("/")), outputPath);
+    assertInputSize((await fs.stat(outputPath)).size);
+    return { source: `archive:${root}`, contents: await fs.readFile(outputPath, "utf8") };
+  } catch (error) {
+    if (error instanceof InputTooLargeError) {
+      throw Service.rejectResponse({ message: `Het samengevoegde archief is te groot. ${error.message}` }, 413);
+    }
+    logger.error("[OasArchiveService] bundling the archive failed via redocly CLI", { message: error?.message });
+    throw reject("Het samenvoegen van de bestanden uit het archief is mislukt.", error?.message);
+  } finally {
+    if (tmpDir) {
+      await fs.rm(tmpDir, { recursive: true, force: true }).catch(() => {});
+    }
+  }
+};
+
+const hasArchiveInput = (input) => typeof input?.oasArchive === "string" && input.oasArchive.trim().length > 0;
+
+module.exports = {
+  assertRefsWithinArchive,
+  findRoot,
+  hasArchiveInput,
+  normalizeEntries,
+  resolveArchiveInput,
+};
@@ -4,16 +4,51 @@ const path = require("node:path");
 const { URL } = require("node:url");
 const { execFile } = require("node:child_process");
 const { promisify } = require("node:util");
-const jsYaml = require("js-yaml");
 const Service = require("./Service");
+const { fingerprintSchemas, refReplacer, rewriteRefs } = require("./ComponentLibraryService");
 const { resolveOasInput } = require("./OasInputService");
+const { normalizeRequestHeaders } = require("./RemoteSpecificationService");
+const { CircularReferenceError, ON_CYCLE, dereferenceDocument } = require("../utils/dereference");
 const { sanitizeFileName } = require("../utils/fileName");
+const { HTTP_CACHE_ARGS } = require("../utils/httpCache");
+const { collectExternalRefs } = require("../utils/openapi");
+const { OUTBOUND_GUARD_ARGS } = require("../utils/outboundPolicy");
+const { parseOrderedDocument, serializeOrdered, withSourceOrder } = require("../utils/orderedDocument");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+const { shareIdenticalSchemas } = require("../utils/schemaSharing");
+const { REF_HEADERS_ARGS, REF_HEADERS_ENV, isHostPattern } = require("../utils/refHeaders");
+const { HTTP_SETTINGS, RESOLVER_HTTP_ARGS, isAllowedValue, resolverHttpEnv } = require("../utils/resolverHttp");
+const {
+  MANIFEST_ENV,
+  RESOLVER_LIMIT_ARGS,
+  ResolverLimitError,
+  assertDepth,
+  loadResolverLimits,
+  readLimitError,
+  readManifest,
+} = require("../utils/resolverLimits");
+const {
+  SCHEMA_SOURCES_ARGS,
+  documentUrl,
+  parseTaggedName,
+  sourceTag,
+  tagSchemaSources,
+} = require("../utils/schemaSources");
+const { YamlLimitError, assertSafeYaml, parseJsonOrYaml } = require("../utils/yaml");
 const logger = require("../logger");
 
 const DEFAULT_FILENAME = "openapi";
+const BUNDLE_MODES = ["dereference", "bundle"];
+const SCOPES = ["all", "internal"];
+const MAX_REF_HOSTS = 20;
+// `manifest` geeft in plaats van het document de lijst van opgehaalde externe documenten.
+const OUTPUT_FORMATS = ["spec", "manifest"];
+const HTTP_REF = /^https?:\/\//i;
 const REDOCLY_BIN = require.resolve("@redocly/cli/bin/cli");
 const execFileAsync = promisify(execFile);
 
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
 const guessPreferredExtension = (contents) => {
   if (typeof contents !== "string") {
     return ".json";
@@ -54,66 +89,199 @@ const deriveDocumentName = (doc, source) => {
   return DEFAULT_FILENAME;
 };
 
-const runRedoclyBundle = async (inputPath, outputPath, ext) => {
-  const args = [
-    REDOCLY_BIN,
-    "bundle",
-    inputPath,
-    "--output",
-    outputPath,
-    "--ext",
-    ext,
-    "--dereferenced",
-  ];
-  return execFileAsync(process.execPath, args, { maxBuffer: 20 * 1024 * 1024 });
+const resolveOnCycle = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return "ref";
+  }
+  const normalized = String(value).toLowerCase();
+  if (!ON_CYCLE.includes(normalized)) {
+    throw Service.rejectResponse(
+      { message: `Onbekende onCycle "${value}". Kies uit: ${ON_CYCLE.join(", ")}.` },
+      400,
+    );
+  }
+  return normalized;
 };
 
-const bundle = async (input) => {
-  const resolved = await resolveOasInput(input);
-  const contents = typeof resolved.contents === "string" ? resolved.contents : "";
-  if (!contents.trim()) {
+const resolveOutputFormat = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return "spec";
+  }
+  const normalized = String(value).toLowerCase();
+  if (!OUTPUT_FORMATS.includes(normalized)) {
     throw Service.rejectResponse(
-      {
-        message: "Body ontbreekt of ongeldig: gebruik oasUrl of oasBody.",
-      },
+      { message: `Onbekend outputFormat "${value}". Kies uit: ${OUTPUT_FORMATS.join(", ")}.` },
       400,
     );
   }
+  return normalized;
+};
+
+const resolveMode = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return "dereference";
+  }
+  const normalized = String(value).toLowerCase();
+  if (!BUNDLE_MODES.includes(normalized)) {
+    throw Service.rejectResponse(
+      { message: `Onbekende mode "${value}". Kies uit: ${BUNDLE_MODES.join(", ")}.` },
+      400,
+    );
+  }
+  return normalized;
+};
+
+// Redocly haalt de externe verwijzingen binnen als componenten; `dereference` schrijft daarna in het
+// proces zelf elke `$ref` uit (zie `dereferenceDocument`), zodat kringverwijzingen beheersbaar blijven.
+// De headers per host en het pad van het manifest gaan via de omgeving naar het child process; de
+// limieten omhullen de headers, zodat een redirect binnen één verwijzing niet als extra document telt.
+// Onder de cache zit alleen de HTTP-client (timeout, herhalingen, redirects, proxy), met de overrides uit
+// `refHttp`. Een verzoek met headers uit `refHeaders` gaat altijd langs de cache.
+// Het benoemen van schema's naar hun bron zit het verst ervan af en ziet dus de URL zoals Redocly die vraagt.
+const runRedoclyBundle = async (inputPath, outputPath, { deadline, refHeaders, refHttp, manifestPath }) => {
+  const args = [REDOCLY_BIN, "bundle", inputPath, "--output", outputPath, "--ext", "json"];
+  const withHeaders = Object.keys(refHeaders).length > 0;
+  const preloads = [
+    ...OUTBOUND_GUARD_ARGS,
+    ...RESOLVER_HTTP_ARGS,
+    ...HTTP_CACHE_ARGS,
+    ...(withHeaders ? REF_HEADERS_ARGS : []),
+    ...RESOLVER_LIMIT_ARGS,
+    ...SCHEMA_SOURCES_ARGS,
+  ];
+  const env = { ...process.env, ...resolverHttpEnv(refHttp), [MANIFEST_ENV]: manifestPath };
+  if (withHeaders) {
+    env[REF_HEADERS_ENV] = JSON.stringify(refHeaders);
+  }
+  return execFileAsync(process.execPath, [...preloads, ...args], {
+    env,
+    maxBuffer: 20 * 1024 * 1024,
+    timeout: Math.max(1, deadline - Date.now()),
+  });
+};
+
+/**
+ * Controleert `refHeaders`: per host (`api.example.nl`, `*.example.nl`, eventueel met poort) de headers
+ * die worden meegestuurd bij het ophalen van externe `$ref`s op die host, met dezelfde regels als `headers`.
+ */
+const normalizeRefHeaders = (value) => {
+  if (value === undefined || value === null) {
+    return {};
+  }
+  if (typeof value !== "object" || Array.isArray(value)) {
+    throw Service.rejectResponse({ message: "refHeaders moet een object met hosts en headers zijn." }, 400);
+  }
+  const entries = Object.entries(value);
+  if (entries.length > MAX_REF_HOSTS) {
+    throw Service.rejectResponse({ message: `Geef in refHeaders maximaal ${MAX_REF_HOSTS} hosts mee.` }, 400);
+  }
+  const normalized = {};
+  for (const [pattern, headers] of entries) {
+    const host = pattern.trim().toLowerCase();
+    if (!isHostPattern(host)) {
+      throw Service.rejectResponse({ message: `"${pattern}" in refHeaders is geen host of *.domein.` }, 400);
+    }
+    normalized[host] = normalizeRequestHeaders(headers);
+  }
+  return normalized;
+};
+
+/**
+ * Controleert `refHttp`: per request andere waarden voor de HTTP-client die externe `$ref`s ophaalt
+ * (`timeoutMs`, `retries`, `backoffMs`, `maxRedirects`), binnen de grenzen uit `utils/resolverHttp.js`.
+ */
+const normalizeRefHttp = (value) => {
+  if (value === undefined || value === null) {
+    return {};
+  }
+  if (typeof value !== "object" || Array.isArray(value)) {
+    throw Service.rejectResponse({ message: "refHttp moet een object met instellingen zijn." }, 400);
+  }
+  for (const [name, setting] of Object.entries(value)) {
+    if (!Object.hasOwn(HTTP_SETTINGS, name)) {
+      throw Service.rejectResponse(
+        { message: `Onbekende instelling "${name}" in refHttp. Kies uit: ${Object.keys(HTTP_SETTINGS).join(", ")}.` },
+        400,
+      );
+    }
+    if (!isAllowedValue(name, setting)) {
+      const { min, max } = HTTP_SETTINGS[name];
+      throw Service.rejectResponse(
+        { message: `refHttp.${name} moet een geheel getal van ${min} tot en met ${max} zijn.` },
+        400,
+      );
+    }
+  }
+  return { ...value };
+};
+
+/**
+ * Redocly leest een verwijzing naar een pad of een `file:`-URL van de schijf van de server. In een
+ * `oasBody` of `oasUrl` mogen daarom alleen verwijzingen binnen het document en naar een http(s)-URL
+ * staan; een specificatie in meerdere bestanden gaat als `oasArchive`, die al is samengevoegd.
+ */
+const assertRemoteRefs = (document) => {
+  const fileRef = collectExternalRefs(document).find((ref) => !HTTP_REF.test(ref));
+  if (fileRef !== undefined) {
+    const message = `De verwijzing ${fileRef} wijst naar een bestand. Gebruik een http(s)-URL of een oasArchive.`;
+    throw Service.rejectResponse({ message }, 400);
+  }
+};
+
+const rejectLimit = (error) =>
+  Service.rejectResponse(
+    { message: `Het oplossen van verwijzingen is gestopt: ${error.message}`, limit: error.limit },
+    400,
+  );
 
+const rejectTimeout = (limits) =>
+  Service.rejectResponse(
+    {
+      message: `Het bundelen duurde langer dan ${limits.timeoutMs / 1000} seconden en is afgebroken.`,
+      detail: "De limiet is in te stellen met OAS_RESOLVE_TIMEOUT_MS.",
+    },
+    504,
+  );
+
+/**
+ * Laat Redocly de externe verwijzingen binnenhalen, binnen de limieten en met een deadline voor het
+ * geheel. Geeft het resultaat als object terug met de opgehaalde documenten volgens het manifest. Is de
+ * input al geparset, dan gaat die als JSON naar Redocly, met de bron in de namen van externe schema's.
+ */
+const bundleExternalRefs = async (contents, parsed, limits, { refHeaders, refHttp }) => {
+  const deadline = Date.now() + limits.timeoutMs;
   let tmpDir;
-  const inputExt = guessPreferredExtension(contents);
+  const input = isObject(parsed) ? JSON.stringify(tagSchemaSources(parsed, undefined, { local: false })) : contents;
+  const inputExt = guessPreferredExtension(input);
   const inputPath = () => path.join(tmpDir, `input${inputExt}`);
-  const outputPath = (ext) => path.join(tmpDir, `bundle.${ext}`);
+  const outputPath = () => path.join(tmpDir, "bundle.json");
+  const manifestPath = () => path.join(tmpDir, "manifest.jsonl");
 
-  let bundledText;
-  let document;
-  let outputExt = "json";
   try {
     tmpDir = await fs.mkdtemp(path.join(os.tmpdir(), "oas-bundle-"));
-    await fs.writeFile(inputPath(), contents, "utf8");
-    try {
-      await runRedoclyBundle(inputPath(), outputPath("json"), "json");
-      bundledText = await fs.readFile(outputPath("json"), "utf8");
-      document = JSON.parse(bundledText);
-    } catch (jsonError) {
-      const errText = `${jsonError?.stderr || ""}${jsonError?.stdout || ""}${jsonError?.message || ""}`;
-      const hasCircular = errText.toLowerCase().includes("circular reference");
-      if (!hasCircular) {
-        throw jsonError;
-      }
-      logger.warn("[OasBundleService] JSON bundle failed due to circular refs, retrying with YAML", {
-        message: jsonError?.message,
-      });
-      outputExt = "yaml";
-      await runRedoclyBundle(inputPath(), outputPath("yaml"), "yaml");
-      bundledText = await fs.readFile(outputPath("yaml"), "utf8");
-      document = jsYaml.load(bundledText);
-    }
+    await fs.writeFile(inputPath(), input, "utf8");
+    await runRedoclyBundle(inputPath(), outputPath(), {
+      deadline,
+      refHeaders,
+      refHttp,
+      manifestPath: manifestPath(),
+    });
+    return {
+      document: JSON.parse(await fs.readFile(outputPath(), "utf8")),
+      documents: await readManifest(manifestPath()),
+    };
   } catch (error) {
     logger.error("[OasBundleService] bundle failed via redocly CLI", {
       message: error?.message,
       stack: error?.stack,
     });
+    const limitError = readLimitError(error);
+    if (limitError) {
+      throw rejectLimit(limitError);
+    }
+    if (error?.killed) {
+      throw rejectTimeout(limits);
+    }
     const status = typeof error?.status === "number" && error.status >= 400 ? error.status : 400;
     throw Service.rejectResponse(
       {
@@ -131,6 +299,176 @@ const bundle = async (input) => {
       }
     }
   }
+};
+
+// Het deel van de bron dat achter een hernoemd schema komt: de bestandsnaam zonder extensie, of de host.
+const sourceSuffix = (source) => {
+  try {
+    const parsed = new URL(source);
+    const basename = path.posix.basename(parsed.pathname).replace(/\.[^.]+$/, "");
+    return sanitizeFileName(basename) || sanitizeFileName(parsed.hostname);
+  } catch {
+    return sanitizeFileName(source);
+  }
+};
+
+/**
+ * Geeft de schema's uit externe documenten hun definitieve naam (zie `utils/schemaSources.js`). Een
+ * naam die maar één schema heeft, of alleen schema's met dezelfde structuur, blijft de naam zonder bron;
+ * gelijke schema's worden één component. Zijn er onder één naam verschillende schema's, dan houdt een
+ * schema uit de input zelf de naam en krijgt elk extern schema de bron erachter (`Adres_gemeente`). De
+ * uitkomst hangt niet af van de volgorde waarin Redocly de documenten ophaalde. Elke `$ref` gaat mee.
+ */
+const resolveSchemaCollisions = (document, documents, rootSource) => {
+  const schemas = document.components?.schemas;
+  if (!isObject(schemas)) {
+    return { document, collisions: [] };
+  }
+  const sources = new Map(documents.map(({ url }) => [sourceTag(url), documentUrl(url)]));
+  const fingerprints = fingerprintSchemas(document);
+  const groups = new Map();
+  for (const key of Object.keys(schemas)) {
+    const tagged = parseTaggedName(key);
+    const name = tagged?.name ?? key;
+    const variants = groups.get(name) ?? new Map();
+    groups.set(name, variants);
+    const fingerprint = fingerprints.get(key);
+    variants.set(fingerprint, [
+      ...(variants.get(fingerprint) ?? []),
+      { key, source: tagged ? (sources.get(tagged.tag) ?? tagged.tag) : undefined },
+    ]);
+  }
+
+  const used = new Set(Object.keys(schemas).filter((key) => !parseTaggedName(key)));
+  const claim = (base) => {
+    let candidate = base;
+    for (let counter = 2; used.has(candidate); counter += 1) {
+      candidate = `${base}-${counter}`;
+    }
+    used.add(candidate);
+    return candidate;
+  };
+  const names = new Map();
+  const collisions = [];
+  // Eerst de namen zonder botsing, zodat een hernoemd schema nooit de naam van een ander wegneemt.
+  const collides = (variants) => (variants.size > 1 ? 1 : 0);
+  const ordered = [...groups.entries()].sort(
+    ([a, variantsA], [b, variantsB]) => collides(variantsA) - collides(variantsB) || a.localeCompare(b),
+  );
+  for (const [name, variants] of ordered) {
+    const collision = variants.size > 1;
+    const report = [...variants.values()]
+      .map((entries) => ({
+        entries,
+        own: entries.some(({ source }) => source === undefined),
+        sources: [...new Set(entries.map(({ source }) => source ?? rootSource))].sort(),
+      }))
+      .sort((a, b) => Number(b.own) - Number(a.own) || a.sources[0].localeCompare(b.sources[0]))
+      .map(({ entries, own, sources: variantSources }) => {
+        const external = variantSources.find((source) => source !== rootSource) ?? variantSources[0];
+        let finalName = name;
+        if (!own) {
+          finalName = claim(collision ? `${name}_${sourceSuffix(external)}` : name);
+        }
+        for (const { key } of entries) {
+          names.set(key, finalName);
+        }
+        return { name: finalName, sources: variantSources };
+      });
+    if (collision) {
+      collisions.push({ name, variants: report });
+    }
+  }
+
+  const rewritten = rewriteRefs(document, refReplacer(names, ""));
+  const merged = {};
+  for (const [key, schema] of Object.entries(rewritten.components.schemas)) {
+    if (!Object.hasOwn(merged, names.get(key))) {
+      merged[names.get(key)] = schema;
+    }
+  }
+  rewritten.components.schemas = merged;
+  collisions.sort((a, b) => a.name.localeCompare(b.name));
+  return { document: rewritten, collisions };
+};
+
+/**
+ * `scope=internal` schrijft alleen lokale verwijzingen uit en laat externe staan, zonder Redocly en
+ * zonder netwerkverkeer; dat kan alleen bij `mode=dereference`.
+ */
+const resolveScope = (value, bundleMode) => {
+  if (value === undefined || value === null || value === "") {
+    return "all";
+  }
+  const normalized = String(value).toLowerCase();
+  if (!SCOPES.includes(normalized)) {
+    throw Service.rejectResponse({ message: `Onbekende scope "${value}". Kies uit: ${SCOPES.join(", ")}.` }, 400);
+  }
+  if (normalized === "internal" && bundleMode !== "dereference") {
+    throw Service.rejectResponse({ message: "scope=internal kan alleen met mode=dereference." }, 400);
+  }
+  return normalized;
+};
+
+// `shareSchemas=true` brengt na het uitschrijven gelijke schema's terug naar één component.
+const resolveShareSchemas = (value, bundleMode) => {
+  const enabled = value === true || String(value).toLowerCase() === "true";
+  if (enabled && bundleMode !== "dereference") {
+    throw Service.rejectResponse({ message: "shareSchemas=true kan alleen met mode=dereference." }, 400);
+  }
+  return enabled;
+};
+
+const bundle = async (input, { mode, onCycle, scope, shareSchemas, outputFormat, responseFormat } = {}) => {
+  const format = resolveOutputFormat(outputFormat);
+  const bundleMode = resolveMode(mode);
+  const cycleHandling = resolveOnCycle(onCycle);
+  const resolvedScope = resolveScope(scope, bundleMode);
+  const sharing = resolveShareSchemas(shareSchemas, bundleMode);
+  const refHeaders = normalizeRefHeaders(input?.refHeaders);
+  const refHttp = normalizeRefHttp(input?.refHttp);
+  const resolved = await resolveOasInput(input);
+  const contents = typeof resolved.contents === "string" ? resolved.contents : "";
+  if (!contents.trim()) {
+    throw Service.rejectResponse(
+      {
+        message: "Body ontbreekt of ongeldig: gebruik oasUrl of oasBody.",
+      },
+      400,
+    );
+  }
+
+  let parsed;
+  try {
+    parsed = assertSafeYaml(contents);
+  } catch (error) {
+    if (error instanceof YamlLimitError) {
+      throw Service.rejectResponse({ message: error.message }, 400);
+    }
+    throw error;
+  }
+
+  const limits = loadResolverLimits();
+  let document;
+  let documents = [];
+  let collisions = [];
+  let shared = [];
+  if (resolvedScope === "internal") {
+    try {
+      ({ document } = parseJsonOrYaml(contents));
+    } catch (error) {
+      throw Service.rejectResponse(
+        { message: "De specificatie is geen geldige JSON of YAML.", detail: error.message },
+        400,
+      );
+    }
+  } else {
+    assertRemoteRefs(parsed);
+    ({ document, documents } = await bundleExternalRefs(contents, parsed, limits, { refHeaders, refHttp }));
+    if (isObject(document)) {
+      ({ document, collisions } = resolveSchemaCollisions(document, documents, resolved.source));
+    }
+  }
 
   if (!document || typeof document !== "object" || Array.isArray(document)) {
     throw Service.rejectResponse(
@@ -141,8 +479,59 @@ const bundle = async (input) => {
     );
   }
 
+  try {
+    if (bundleMode === "dereference") {
+      document = dereferenceDocument(document, { onCycle: cycleHandling });
+    }
+    if (sharing) {
+      ({ document, shared } = shareIdenticalSchemas(document));
+    }
+    assertDepth(document, limits);
+  } catch (error) {
+    if (error instanceof ResolverLimitError) {
+      throw rejectLimit(error);
+    }
+    if (error instanceof CircularReferenceError) {
+      throw Service.rejectResponse(
+        {
+          message: `De specificatie kan niet volledig worden uitgeschreven. ${error.message}`,
+          detail: "Met onCycle=ref blijft de verwijzing die de kring sluit als $ref staan.",
+          cycle: error.cycle,
+        },
+        400,
+      );
+    }
+    throw error;
+  }
+
+  if (format === "manifest") {
+    const manifest = { source: resolved.source, mode: bundleMode, documents, collisions };
+    return {
+      headers: {
+        "Content-Type": "application/json",
+        "X-Resolved-Documents": String(documents.length),
+        "X-Schema-Collisions": String(collisions.length),
+      },
+      rawBody: Buffer.from(JSON.stringify(manifest, null, 2), "utf8"),
+    };
+  }
+
   const docName = deriveDocumentName(document, resolved.source);
-  const buffer = Buffer.from(bundledText, "utf8");
+  stampDocument(
+    document,
+    buildProvenance({
+      tool: "oas-bundle",
+      source: resolved.source,
+      details:
+        bundleMode === "dereference"
+          ? { mode: bundleMode, scope: resolvedScope, onCycle: cycleHandling, shareSchemas: sharing }
+          : { mode: bundleMode },
+    }),
+  );
+  // JSON.parse zet statuscodes vooraan; de volgorde van de input herstelt dat.
+  const outputExt = responseFormat === "yaml" ? "yaml" : "json";
+  const serialized = serializeOrdered(withSourceOrder(document, parseOrderedDocument(contents).document), outputExt);
+  const buffer = Buffer.from(serialized, "utf8");
   const filename = `${docName}.${outputExt}`;
   const contentType = outputExt === "json" ? "application/json" : "application/yaml";
 
@@ -150,6 +539,9 @@ const bundle = async (input) => {
     headers: {
       "Content-Type": contentType,
       "Content-Disposition": `attachment; filename="${filename}"`,
+      "X-Resolved-Documents": String(documents.length),
+      "X-Schema-Collisions": String(collisions.length),
+      "X-Shared-Schemas": String(shared.length),
     },
     rawBody: buffer,
   };
@@ -157,4 +549,5 @@ const bundle = async (input) => {
 
 module.exports = {
   bundle,
+  resolveSchemaCollisions,
 };
@@ -1,39 +1,40 @@
 const { Converter } = require("@apiture/openapi-down-convert");
 const { upgrade: scalarUpgrade } = require("@scalar/openapi-upgrader");
-const jsYaml = require("js-yaml");
 const Service = require("./Service");
 const { resolveOasInput } = require("./OasInputService");
+const { convertSchemas31To30 } = require("../utils/openapi31");
+const { parseOrderedDocument, serializeOrdered, withSourceOrder } = require("../utils/orderedDocument");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+const { SWAGGER_VERSION, downgradeToSwagger2, isSwagger2, upgradeSwagger2 } = require("../utils/swagger");
+const { YamlLimitError } = require("../utils/yaml");
 const logger = require("../logger");
 
 const DEFAULT_TARGET_VERSION = "3.1.0";
 
 const EMPTY_BODY_ERROR = "Body ontbreekt of ongeldig: gebruik oasUrl of oasBody";
 const VERSION_MISSING_ERROR = "OpenAPI document bevat geen geldig openapi versieveld";
-const UNSUPPORTED_VERSION_ERROR = "Alleen OpenAPI 3.0 en 3.1 worden ondersteund";
-const UNSUPPORTED_TARGET_VERSION_ERROR = "targetVersion wordt niet ondersteund. Gebruik 3.0 of 3.1.";
+const UNSUPPORTED_VERSION_ERROR = "Alleen Swagger 2.0 en OpenAPI 3.0 en 3.1 worden ondersteund";
+const UNSUPPORTED_TARGET_VERSION_ERROR = "targetVersion wordt niet ondersteund. Gebruik 2.0, 3.0 of 3.1.";
 
 const parseSpecification = (contents) => {
   const trimmed = contents.trim();
   if (trimmed.length === 0) {
     throw Service.rejectResponse({ message: EMPTY_BODY_ERROR }, 400);
   }
+  let parsed;
   try {
-    const spec = JSON.parse(trimmed);
-    if (!spec || typeof spec !== "object" || Array.isArray(spec)) {
-      throw new Error("Ongeldig OpenAPI document");
-    }
-    return { spec, format: "json" };
-  } catch (_jsonError) {
-    try {
-      const spec = jsYaml.load(trimmed);
-      if (!spec || typeof spec !== "object" || Array.isArray(spec)) {
-        throw new Error("Ongeldig OpenAPI document");
-      }
-      return { spec, format: "yaml" };
-    } catch (yamlError) {
-      throw new Error(`Kan OpenAPI specificatie niet parseren: ${yamlError.message}`);
+    parsed = parseOrderedDocument(trimmed);
+  } catch (error) {
+    if (error instanceof YamlLimitError) {
+      throw Service.rejectResponse({ message: error.message }, 400);
     }
+    throw new Error(`Kan OpenAPI specificatie niet parseren: ${error.message}`);
   }
+  const { plain: spec, document: sourceOrder, format } = parsed;
+  if (!spec || typeof spec !== "object" || Array.isArray(spec)) {
+    throw new Error("Kan OpenAPI specificatie niet parseren: Ongeldig OpenAPI document");
+  }
+  return { spec, sourceOrder, format };
 };
 
 const resolveVersionDescriptor = (value) => {
@@ -58,10 +59,15 @@ const resolveVersionDescriptor = (value) => {
   return null;
 };
 
+const isSwaggerTarget = (value) => ["2", SWAGGER_VERSION].includes(String(value ?? "").trim());
+
 const normalizeTargetVersion = (value) => {
   if (typeof value !== "string" || value.trim().length === 0) {
     return DEFAULT_TARGET_VERSION;
   }
+  if (isSwaggerTarget(value)) {
+    return SWAGGER_VERSION;
+  }
   const descriptor = resolveVersionDescriptor(value);
   if (!descriptor) {
     throw Service.rejectResponse(
@@ -81,7 +87,21 @@ const ensureObjectSpec = (value, errorMessage) => {
   return value;
 };
 
-const convertSpec = async (spec, targetVersion, options = {}) => {
+const convertSpec = async (source, targetVersion, options = {}) => {
+  if (source.swagger !== undefined && !isSwagger2(source)) {
+    throw Service.rejectResponse({ message: UNSUPPORTED_VERSION_ERROR }, 400);
+  }
+  if (isSwaggerTarget(targetVersion)) {
+    if (isSwagger2(source)) {
+      return { spec: source, resolvedVersion: SWAGGER_VERSION, warnings: [] };
+    }
+    // Via OpenAPI 3.0, zodat ook een 3.1 document eerst de gewone downgrade krijgt.
+    const { spec: openapi30, warnings: earlier = [] } = await convertSpec(source, "3.0");
+    const { spec: downgraded, warnings } = downgradeToSwagger2(openapi30);
+    return { spec: downgraded, resolvedVersion: SWAGGER_VERSION, warnings: [...earlier, ...warnings] };
+  }
+  // Swagger 2.0 wordt eerst OpenAPI 3.0; daarna volgt de gewone omzetting naar de doelversie.
+  const spec = isSwagger2(source) ? upgradeSwagger2(source) : source;
   const sourceDescriptor = resolveVersionDescriptor(spec.openapi);
   const rawVersion = spec.openapi == null ? "" : String(spec.openapi).trim();
   if (rawVersion.length === 0 || !sourceDescriptor) {
@@ -112,29 +132,38 @@ const convertSpec = async (spec, targetVersion, options = {}) => {
   }
 
   if (sourceDescriptor.major === "3.1" && targetDescriptor.major === "3.0") {
-    const downConverter = new Converter(spec);
+    // Eerst de eigen keyword-mapping, zodat verloren informatie gemeld kan worden.
+    const { spec: prepared, warnings } = convertSchemas31To30(spec);
+    const downConverter = new Converter(prepared);
     const downgraded = ensureObjectSpec(
       downConverter.convert(),
       "OpenAPI down converter retourneerde een ongeldig document.",
     );
     downgraded.openapi = targetDescriptor.canonical;
-    return { spec: downgraded, resolvedVersion: targetDescriptor.canonical };
+    return { spec: downgraded, resolvedVersion: targetDescriptor.canonical, warnings };
   }
 
   throw Service.rejectResponse({ message: UNSUPPORTED_VERSION_ERROR }, 400);
 };
 
-const serializeSpecification = (spec, format, targetVersion) => {
-  const filenameBase = `openapi-${targetVersion.replace(/\./g, "-")}`;
+/**
+ * Schrijft het omgezette document in de sleutelvolgorde van de bron, zodat dezelfde input altijd
+ * dezelfde output geeft en een diff alleen de omzetting laat zien. Zonder volgorde zou JavaScript
+ * statuscodes als `200` en `404` oplopend vooraan zetten.
+ */
+const serializeSpecification = (spec, format, targetVersion, sourceOrder) => {
+  const prefix = targetVersion === SWAGGER_VERSION ? "swagger" : "openapi";
+  const filenameBase = `${prefix}-${targetVersion.replace(/\./g, "-")}`;
+  const ordered = withSourceOrder(spec, sourceOrder);
   if (format === "json") {
-    const json = JSON.stringify(spec, null, 2);
+    const json = serializeOrdered(ordered, "json");
     return {
       buffer: Buffer.from(json, "utf8"),
       contentType: "application/json",
       filename: `${filenameBase}.json`,
     };
   }
-  const yaml = jsYaml.dump(spec, { lineWidth: -1 });
+  const yaml = serializeOrdered(ordered, "yaml");
   return {
     buffer: Buffer.from(yaml, "utf8"),
     contentType: "application/yaml",
@@ -142,12 +171,12 @@ const serializeSpecification = (spec, format, targetVersion) => {
   };
 };
 
-const convert = async (input) => {
+const convert = async (input, { responseFormat } = {}) => {
   const requestedTargetVersion = typeof input?.targetVersion === "string" ? input.targetVersion : undefined;
   const targetVersion = normalizeTargetVersion(requestedTargetVersion);
   const hasExplicitTargetVersion =
     typeof requestedTargetVersion === "string" && requestedTargetVersion.trim().length > 0;
-  const { contents } = await resolveOasInput(input);
+  const { contents, source } = await resolveOasInput(input);
   let parsed;
   try {
     parsed = parseSpecification(contents);
@@ -157,10 +186,10 @@ const convert = async (input) => {
     throw Service.rejectResponse({ message: error.message }, 500);
   }
 
-  const { spec, format } = parsed;
-  let convertedSpec, resolvedVersion;
+  const { spec, sourceOrder, format } = parsed;
+  let convertedSpec, resolvedVersion, warnings;
   try {
-    ({ spec: convertedSpec, resolvedVersion } = await convertSpec(spec, targetVersion, {
+    ({ spec: convertedSpec, resolvedVersion, warnings } = await convertSpec(spec, targetVersion, {
       preserveSourceVersion: !hasExplicitTargetVersion,
     }));
   } catch (error) {
@@ -172,16 +201,34 @@ const convert = async (input) => {
     );
   }
 
-  const { buffer, contentType, filename } = serializeSpecification(convertedSpec, format, resolvedVersion);
+  stampDocument(
+    convertedSpec,
+    buildProvenance({
+      tool: "oas-convert",
+      source,
+      details: { targetVersion: resolvedVersion, warnings: warnings?.length > 0 ? warnings : undefined },
+    }),
+  );
+  const { buffer, contentType, filename } = serializeSpecification(
+    convertedSpec,
+    responseFormat ?? format,
+    resolvedVersion,
+    sourceOrder,
+  );
+  const headers = {
+    "Content-Type": contentType,
+    "Content-Disposition": `attachment; filename="${filename}"`,
+  };
+  if (warnings) {
+    headers["X-Conversion-Warnings"] = String(warnings.length);
+  }
   return {
-    headers: {
-      "Content-Type": contentType,
-      "Content-Disposition": `attachment; filename="${filename}"`,
-    },
+    headers,
     rawBody: buffer,
   };
 };
 
 module.exports = {
   convert,
+  convertSpec,
 };
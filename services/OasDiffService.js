@@ -0,0 +1,483 @@
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { HTTP_METHODS, resolveRef } = require("../utils/openapi");
+const { bumpRank, classifyBump, parseSemver } = require("../utils/semver");
+const { isSwagger2, upgradeSwagger2 } = require("../utils/swagger");
+
+const CHANGE_TYPES = ["breaking", "deprecation", "non-breaking"];
+const TYPE_HEADINGS = { breaking: "Breaking", deprecation: "Deprecations", "non-breaking": "Non-breaking" };
+const MAX_SCHEMA_DEPTH = 32;
+const MAJOR_IN_URI = /\/v(\d+)(?:\/|$)/i;
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+const isNonEmptyString = (value) => typeof value === "string" && value.trim().length > 0;
+
+const escapeTableCell = (value) =>
+  String(value ?? "")
+    .replace(/\|/g, "\\|")
+    .replace(/\r?\n/g, " ");
+
+const resolveSide = async (side, label, headers) => {
+  if (!isNonEmptyString(side?.oasBody) && !isNonEmptyString(side?.oasUrl)) {
+    throw Service.rejectResponse({ message: `Geef voor ${label} een oasUrl of oasBody mee.` }, 400);
+  }
+  const { spec } = await resolveOasDocument({ oasBody: side.oasBody, oasUrl: side.oasUrl, headers });
+  return isSwagger2(spec) ? upgradeSwagger2(spec) : spec;
+};
+
+// `/dieren/{id}` en `/dieren/{dierId}` zijn hetzelfde pad; alleen de naam van de parameter verschilt.
+const templateKey = (path) => path.replace(/\{[^}]+\}/g, "{}");
+
+// Padparameters worden op hun positie in het pad gematcht, headers hoofdletterongevoelig.
+const parameterKey = (path, parameter) => {
+  if (parameter.in === "path") {
+    const names = [...path.matchAll(/\{([^}]+)\}/g)].map((match) => match[1]);
+    const position = names.indexOf(parameter.name);
+    return `path:${position === -1 ? parameter.name : position}`;
+  }
+  return `${parameter.in}:${parameter.in === "header" ? parameter.name.toLowerCase() : parameter.name}`;
+};
+
+const indexParameters = (spec, path, pathItem, operation) => {
+  const parameters = new Map();
+  for (const candidate of [...(pathItem.parameters ?? []), ...(operation.parameters ?? [])]) {
+    const parameter = resolveRef(spec, candidate);
+    if (isNonEmptyString(parameter?.name) && isNonEmptyString(parameter.in)) {
+      parameters.set(parameterKey(path, parameter), parameter);
+    }
+  }
+  return parameters;
+};
+
+const indexPaths = (spec) => {
+  const paths = new Map();
+  for (const [path, rawPathItem] of Object.entries(isObject(spec.paths) ? spec.paths : {})) {
+    const pathItem = resolveRef(spec, rawPathItem);
+    if (isObject(pathItem)) {
+      paths.set(templateKey(path), { path, pathItem });
+    }
+  }
+  return paths;
+};
+
+const describeType = (type) => (Array.isArray(type) ? [...type].sort().join("|") : type);
+
+// Voegt de leden van een allOf samen, zodat properties en required uit alle delen meetellen.
+const flattenSchema = (spec, schema, depth = 0) => {
+  const resolved = resolveRef(spec, schema);
+  if (!isObject(resolved)) {
+    return { properties: {}, required: [] };
+  }
+  const flat = {
+    ...resolved,
+    properties: { ...(isObject(resolved.properties) ? resolved.properties : {}) },
+    required: Array.isArray(resolved.required) ? [...resolved.required] : [],
+  };
+  if (depth < MAX_SCHEMA_DEPTH && Array.isArray(resolved.allOf)) {
+    for (const member of resolved.allOf) {
+      const part = flattenSchema(spec, member, depth + 1);
+      flat.type = flat.type ?? part.type;
+      flat.enum = flat.enum ?? part.enum;
+      flat.items = flat.items ?? part.items;
+      flat.properties = { ...part.properties, ...flat.properties };
+      flat.required = [...new Set([...flat.required, ...part.required])];
+    }
+  }
+  return flat;
+};
+
+const subject = (label) => (label ? `property ${label}` : "het schema");
+
+const diffEnum = (context, where, label, base, head, direction) => {
+  const name = subject(label);
+  if (!Array.isArray(head.enum)) {
+    return;
+  }
+  if (!Array.isArray(base.enum)) {
+    const type = direction === "request" ? "breaking" : "non-breaking";
+    context.report(type, "enum-added", where, `De waarden van ${name} zijn beperkt tot een enum.`);
+    return;
+  }
+  const format = (values) => values.map((value) => JSON.stringify(value)).join(", ");
+  const removed = base.enum.filter((value) => !head.enum.includes(value));
+  const added = head.enum.filter((value) => !base.enum.includes(value));
+  // Een request mag minder waarden niet meer sturen; een client kan een nieuwe responsewaarde niet verwachten.
+  if (removed.length > 0) {
+    const type = direction === "request" ? "breaking" : "non-breaking";
+    context.report(type, "enum-value-removed", where, `Enum-waarden ${format(removed)} van ${name} zijn verwijderd.`);
+  }
+  if (added.length > 0) {
+    const type = direction === "request" ? "non-breaking" : "breaking";
+    context.report(type, "enum-value-added", where, `Enum-waarden ${format(added)} zijn toegevoegd aan ${name}.`);
+  }
+};
+
+const diffProperties = (context, where, label, base, head, direction, ancestors) => {
+  const propertyLabel = (name) => (label ? `${label}.${name}` : name);
+  for (const name of Object.keys(base.properties)) {
+    if (!Object.hasOwn(head.properties, name)) {
+      context.report("breaking", "property-removed", where, `Property ${propertyLabel(name)} is verwijderd.`);
+    }
+  }
+  for (const name of Object.keys(head.properties)) {
+    const wasRequired = base.required.includes(name);
+    const isRequired = head.required.includes(name);
+    const property = propertyLabel(name);
+    if (!Object.hasOwn(base.properties, name)) {
+      if (direction === "request" && isRequired) {
+        context.report("breaking", "required-property-added", where, `Verplichte property ${property} is toegevoegd.`);
+      } else {
+        context.report("non-breaking", "property-added", where, `Property ${property} is toegevoegd.`);
+      }
+      continue;
+    }
+    if (wasRequired !== isRequired) {
+      // In een request raakt een nieuw verplicht veld de client, in een response een veld dat kan ontbreken.
+      const breaking = direction === "request" ? isRequired : wasRequired;
+      const code = isRequired ? "property-became-required" : "property-became-optional";
+      const message = `Property ${property} is ${isRequired ? "verplicht" : "optioneel"} geworden.`;
+      context.report(breaking ? "breaking" : "non-breaking", code, where, message);
+    }
+    diffSchema(context, where, property, base.properties[name], head.properties[name], direction, ancestors);
+  }
+};
+
+/**
+ * Vergelijkt twee schema's in de richting van het bericht: in een request is strenger worden
+ * breaking (nieuw verplicht veld, minder enum-waarden), in een response juist ruimer worden
+ * (veld optioneel, extra enum-waarden). Een verwijderde property of ander type is altijd breaking.
+ */
+const diffSchema = (context, where, label, baseSchema, headSchema, direction, ancestors = new Set()) => {
+  // Een recursief schema (Dier.moeder is weer een Dier) wordt maar één keer per tak vergeleken.
+  let nested = ancestors;
+  if (typeof baseSchema?.$ref === "string" && typeof headSchema?.$ref === "string") {
+    const pair = `${baseSchema.$ref}|${headSchema.$ref}`;
+    if (ancestors.has(pair)) {
+      return;
+    }
+    nested = new Set([...ancestors, pair]);
+  }
+  const base = flattenSchema(context.base, baseSchema);
+  const head = flattenSchema(context.head, headSchema);
+  const name = subject(label);
+  if (base.type !== undefined && head.type !== undefined && describeType(base.type) !== describeType(head.type)) {
+    const message = `Het type van ${name} is gewijzigd van ${describeType(base.type)} naar ${describeType(head.type)}.`;
+    context.report("breaking", "type-changed", where, message);
+    return;
+  }
+  if (base.deprecated !== true && head.deprecated === true) {
+    const message = label ? `Property ${label} is deprecated.` : "Het schema is deprecated.";
+    context.report("deprecation", "schema-deprecated", where, message);
+  }
+  diffEnum(context, where, label, base, head, direction);
+  diffProperties(context, where, label, base, head, direction, nested);
+  if (base.items !== undefined && head.items !== undefined) {
+    diffSchema(context, where, `${label}[]`, base.items, head.items, direction, nested);
+  }
+};
+
+const diffContent = (context, where, baseContent, headContent, direction) => {
+  const base = isObject(baseContent) ? baseContent : {};
+  const head = isObject(headContent) ? headContent : {};
+  for (const mediaType of Object.keys(base)) {
+    if (!Object.hasOwn(head, mediaType)) {
+      context.report("breaking", "media-type-removed", where, `Media type ${mediaType} is verwijderd.`);
+    }
+  }
+  for (const mediaType of Object.keys(head)) {
+    if (!Object.hasOwn(base, mediaType)) {
+      context.report("non-breaking", "media-type-added", where, `Media type ${mediaType} is toegevoegd.`);
+    } else if (base[mediaType]?.schema !== undefined && head[mediaType]?.schema !== undefined) {
+      const location = { ...where, location: `${where.location} (${mediaType})` };
+      diffSchema(context, location, "", base[mediaType].schema, head[mediaType].schema, direction);
+    }
+  }
+};
+
+const diffParameters = (context, operation, base, head) => {
+  for (const [key, parameter] of base.parameters) {
+    if (!head.parameters.has(key)) {
+      const where = { operation, location: `${parameter.in}-parameter ${parameter.name}` };
+      context.report("breaking", "parameter-removed", where, `Parameter ${parameter.name} is verwijderd.`);
+    }
+  }
+  for (const [key, parameter] of head.parameters) {
+    const where = { operation, location: `${parameter.in}-parameter ${parameter.name}` };
+    const previous = base.parameters.get(key);
+    if (!previous) {
+      if (parameter.required === true) {
+        const message = `Verplichte parameter ${parameter.name} is toegevoegd.`;
+        context.report("breaking", "required-parameter-added", where, message);
+      } else {
+        context.report("non-breaking", "parameter-added", where, `Parameter ${parameter.name} is toegevoegd.`);
+      }
+      continue;
+    }
+    if (previous.required !== true && parameter.required === true) {
+      const message = `Parameter ${parameter.name} is verplicht geworden.`;
+      context.report("breaking", "parameter-became-required", where, message);
+    }
+    if (previous.deprecated !== true && parameter.deprecated === true) {
+      context.report("deprecation", "parameter-deprecated", where, `Parameter ${parameter.name} is deprecated.`);
+    }
+    if (previous.schema !== undefined && parameter.schema !== undefined) {
+      diffSchema(context, where, "", previous.schema, parameter.schema, "request");
+    }
+  }
+};
+
+const diffRequestBody = (context, operation, baseBody, headBody) => {
+  const where = { operation, location: "request body" };
+  const base = resolveRef(context.base, baseBody);
+  const head = resolveRef(context.head, headBody);
+  if (!isObject(head)) {
+    if (isObject(base)) {
+      context.report("breaking", "request-body-removed", where, "De request body is verwijderd.");
+    }
+    return;
+  }
+  if (!isObject(base)) {
+    const type = head.required === true ? "breaking" : "non-breaking";
+    const message = `Een ${head.required === true ? "verplichte" : "optionele"} request body is toegevoegd.`;
+    context.report(type, "request-body-added", where, message);
+    return;
+  }
+  if (base.required !== true && head.required === true) {
+    context.report("breaking", "request-body-became-required", where, "De request body is verplicht geworden.");
+  }
+  diffContent(context, where, base.content, head.content, "request");
+};
+
+const diffResponses = (context, operation, baseResponses, headResponses) => {
+  const normalize = (responses) =>
+    new Map(
+      Object.entries(isObject(responses) ? responses : {})
+        .filter(([code]) => !code.startsWith("x-"))
+        .map(([code, response]) => [code.toUpperCase(), response]),
+    );
+  const base = normalize(baseResponses);
+  const head = normalize(headResponses);
+  for (const code of base.keys()) {
+    if (!head.has(code)) {
+      const where = { operation, location: `response ${code}` };
+      context.report("breaking", "response-removed", where, `Response ${code} is verwijderd.`);
+    }
+  }
+  for (const [code, rawResponse] of head) {
+    const where = { operation, location: `response ${code}` };
+    if (!base.has(code)) {
+      context.report("non-breaking", "response-added", where, `Response ${code} is toegevoegd.`);
+      continue;
+    }
+    const previous = resolveRef(context.base, base.get(code));
+    const response = resolveRef(context.head, rawResponse);
+    diffContent(context, where, previous?.content, response?.content, "response");
+  }
+};
+
+const diffOperation = (context, operation, base, head) => {
+  if (base.operation.deprecated !== true && head.operation.deprecated === true) {
+    context.report("deprecation", "operation-deprecated", { operation, location: "" }, "De operatie is deprecated.");
+  }
+  diffParameters(context, operation, base, head);
+  diffRequestBody(context, operation, base.operation.requestBody, head.operation.requestBody);
+  diffResponses(context, operation, base.operation.responses, head.operation.responses);
+};
+
+const describeOperation = (spec, path, pathItem, method) => ({
+  operation: pathItem[method],
+  parameters: indexParameters(spec, path, pathItem, pathItem[method]),
+});
+
+/**
+ * Vergelijkt twee OpenAPI documenten en deelt elke wijziging in als `breaking`, `non-breaking` of
+ * `deprecation`. Paden worden gematcht op hun template (de naam van padparameters telt niet mee),
+ * parameters op locatie en naam, responses op statuscode. Lokale `$ref`s worden gevolgd.
+ */
+const diffSpecs = (baseSpec, headSpec) => {
+  const changes = [];
+  const context = {
+    base: baseSpec,
+    head: headSpec,
+    report: (type, code, where, message) => changes.push({ type, code, ...where, message }),
+  };
+  const basePaths = indexPaths(baseSpec);
+  const headPaths = indexPaths(headSpec);
+  for (const [key, { path }] of basePaths) {
+    if (!headPaths.has(key)) {
+      context.report("breaking", "path-removed", { operation: path, location: "" }, `Pad ${path} is verwijderd.`);
+    }
+  }
+  for (const [key, head] of headPaths) {
+    const base = basePaths.get(key);
+    if (!base) {
+      const where = { operation: head.path, location: "" };
+      context.report("non-breaking", "path-added", where, `Pad ${head.path} is toegevoegd.`);
+      continue;
+    }
+    for (const method of HTTP_METHODS) {
+      const operation = `${method.toUpperCase()} ${head.path}`;
+      const where = { operation, location: "" };
+      const inBase = isObject(base.pathItem[method]);
+      const inHead = isObject(head.pathItem[method]);
+      if (inBase && !inHead) {
+        context.report("breaking", "operation-removed", where, `Operatie ${operation} is verwijderd.`);
+      } else if (!inBase && inHead) {
+        context.report("non-breaking", "operation-added", where, `Operatie ${operation} is toegevoegd.`);
+      } else if (inBase && inHead) {
+        diffOperation(
+          context,
+          operation,
+          describeOperation(baseSpec, base.path, base.pathItem, method),
+          describeOperation(headSpec, head.path, head.pathItem, method),
+        );
+      }
+    }
+  }
+  return changes;
+};
+
+const describeSpec = (spec) => ({
+  title: typeof spec.info?.title === "string" ? spec.info.title : "",
+  version: typeof spec.info?.version === "string" ? spec.info.version : "",
+});
+
+// Zonder versienummer en provenance, die per versie horen te verschillen.
+const withoutVersion = (spec) => {
+  const copy = { ...spec, info: { ...spec.info } };
+  delete copy.info.version;
+  delete copy["x-don-generated"];
+  return JSON.stringify(copy);
+};
+
+const recommendBump = (summary, baseSpec, headSpec, from) => {
+  if (summary.breaking > 0) {
+    // Onder 1.0.0 mag alles veranderen; een breaking wijziging is dan een minor (semver §4).
+    return from?.major === 0 ? "minor" : "major";
+  }
+  if (summary.nonBreaking > 0 || summary.deprecation > 0) {
+    return "minor";
+  }
+  return withoutVersion(baseSpec) === withoutVersion(headSpec) ? "none" : "patch";
+};
+
+/**
+ * Advies voor info.version van `head` volgens de ADR-regels voor versionering: breaking wijzigingen
+ * vragen een major, nieuwe functionaliteit en deprecations een minor en overige wijzigingen (zoals
+ * beschrijvingen) een patch. `findings` noemt elke overtreding: een te kleine of verlaagde versie,
+ * een versie die geen semver is (semver) en een server-URL met een andere major-versie
+ * (include-major-version-in-uri).
+ */
+const adviseVersion = (summary, baseSpec, headSpec) => {
+  const baseVersion = baseSpec.info?.version;
+  const headVersion = headSpec.info?.version;
+  const from = parseSemver(baseVersion);
+  const to = parseSemver(headVersion);
+  const recommendedBump = recommendBump(summary, baseSpec, headSpec, from);
+  const findings = [];
+  for (const [label, version, parsed] of [
+    ["base", baseVersion, from],
+    ["head", headVersion, to],
+  ]) {
+    if (!parsed) {
+      const value = JSON.stringify(version ?? "");
+      findings.push(`info.version ${value} van ${label} is geen semantische versie (semver).`);
+    }
+  }
+  const actualBump = from && to ? classifyBump(from, to) : undefined;
+  if (actualBump === "downgrade") {
+    findings.push(`info.version is verlaagd van ${baseVersion} naar ${headVersion}.`);
+  } else if (actualBump && bumpRank(actualBump) < bumpRank(recommendedBump)) {
+    const actual = actualBump === "none" ? "ongewijzigd" : `een ${actualBump}-verhoging`;
+    const versions = `${baseVersion} → ${headVersion}`;
+    findings.push(`De wijzigingen vragen een ${recommendedBump}-verhoging; ${versions} is ${actual}.`);
+  }
+  if (to && to.major > 0) {
+    for (const server of Array.isArray(headSpec.servers) ? headSpec.servers : []) {
+      const match = MAJOR_IN_URI.exec(typeof server?.url === "string" ? server.url : "");
+      if (match && Number(match[1]) !== to.major) {
+        const rule = "include-major-version-in-uri";
+        findings.push(`Server ${server.url} noemt v${match[1]} in plaats van v${to.major} (${rule}).`);
+      }
+    }
+  }
+  return { recommendedBump, actualBump, compliant: findings.length === 0, findings };
+};
+
+const renderVersioning = ({ base, head, versioning }) => {
+  const { recommendedBump, actualBump, compliant, findings } = versioning;
+  const actual = actualBump ? ` · info.version ${base.version} → ${head.version} (${actualBump})` : "";
+  return [
+    `**Versie:** aanbevolen ${recommendedBump}${actual} ${compliant ? "✅" : "❌"}`,
+    ...(findings.length > 0 ? ["", ...findings.map((finding) => `- ${finding}`)] : []),
+    "",
+  ];
+};
+
+/**
+ * Markdown-samenvatting van een diff, bijvoorbeeld voor een PR-commentaar of release notes: per
+ * soort wijziging een tabel met operatie, plek en omschrijving, voorafgegaan door het versieadvies.
+ */
+const renderMarkdown = (result) => {
+  const { base, head, summary, changes } = result;
+  const versions = base.version || head.version ? ` ${base.version || "?"} → ${head.version || "?"}` : "";
+  const lines = [
+    `## API-wijzigingen${head.title ? `: ${head.title}` : ""}${versions}`,
+    "",
+    `**${summary.breaking} breaking** · ${summary.deprecation} deprecation · ${summary.nonBreaking} non-breaking`,
+    "",
+    ...renderVersioning(result),
+  ];
+  if (changes.length === 0) {
+    lines.push("Geen wijzigingen gevonden.");
+  }
+  for (const type of CHANGE_TYPES) {
+    const selected = changes.filter((change) => change.type === type);
+    if (selected.length === 0) {
+      continue;
+    }
+    lines.push(`### ${TYPE_HEADINGS[type]}`, "", "| Operatie | Plek | Wijziging |", "| --- | --- | --- |");
+    for (const change of selected) {
+      const cells = [`\`${change.operation}\``, escapeTableCell(change.location), escapeTableCell(change.message)];
+      lines.push(`| ${cells.join(" | ")} |`);
+    }
+    lines.push("");
+  }
+  return `${lines.join("\n").trimEnd()}\n`;
+};
+
+const buildDiff = (baseSpec, headSpec) => {
+  const changes = diffSpecs(baseSpec, headSpec);
+  const count = (type) => changes.filter((change) => change.type === type).length;
+  const summary = {
+    breaking: count("breaking"),
+    deprecation: count("deprecation"),
+    nonBreaking: count("non-breaking"),
+  };
+  const result = {
+    base: describeSpec(baseSpec),
+    head: describeSpec(headSpec),
+    summary,
+    versioning: adviseVersion(summary, baseSpec, headSpec),
+    changes,
+  };
+  return { ...result, markdown: renderMarkdown(result) };
+};
+
+/**
+ * Diff tussen twee specificaties (`base` en `head`, elk met oasUrl of oasBody). Swagger 2.0 wordt
+ * eerst omgezet naar OpenAPI 3.0, zodat ook een overstap van 2.0 naar 3.x te vergelijken is.
+ */
+const diff = async (input) => {
+  const baseSpec = await resolveSide(input?.base, "base", input?.headers);
+  const headSpec = await resolveSide(input?.head, "head", input?.headers);
+  return buildDiff(baseSpec, headSpec);
+};
+
+module.exports = {
+  adviseVersion,
+  buildDiff,
+  diff,
+  diffSpecs,
+};
@@ -0,0 +1,167 @@
+const Service = require("./Service");
+const { resolveOasDocument, serializeOasDocument } = require("./OasInputService");
+const { pruneComponents } = require("./OasPruneService");
+const { HTTP_METHODS, resolveRef } = require("../utils/openapi");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+
+const LIST_FIELDS = ["includeTags", "excludeTags", "includePaths", "excludePaths", "includeMethods", "excludeMethods"];
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+const toList = (value) =>
+  (Array.isArray(value) ? value : [])
+    .filter((entry) => typeof entry === "string" && entry.trim())
+    .map((entry) => entry.trim());
+
+/**
+ * Zet een pad-glob om naar een reguliere expressie: `*` matcht binnen één padsegment, `**` over
+ * segmenten heen en `?` één teken. `/intern/**` matcht ook `/intern` zelf.
+ */
+const globToRegExp = (glob) => {
+  const tokens = glob.split(/(\/\*\*|\*\*|\*|\?)/);
+  const source = tokens
+    .map((token) => {
+      switch (token) {
+        case "/**":
+          return "(?:/.*)?";
+        case "**":
+          return ".*";
+        case "*":
+          return "[^/]*";
+        case "?":
+          return "[^/]";
+        default:
+          return token.replace(/[.+^${}()|[\]\\]/g, "\\$&");
+      }
+    })
+    .join("");
+  return new RegExp(`^${source}$`);
+};
+
+const buildCriteria = (input) => {
+  const lists = Object.fromEntries(LIST_FIELDS.map((field) => [field, toList(input?.[field])]));
+  const criteria = {
+    includeTags: new Set(lists.includeTags),
+    excludeTags: new Set(lists.excludeTags),
+    includePaths: lists.includePaths.map(globToRegExp),
+    excludePaths: lists.excludePaths.map(globToRegExp),
+    includeMethods: new Set(lists.includeMethods.map((method) => method.toLowerCase())),
+    excludeMethods: new Set(lists.excludeMethods.map((method) => method.toLowerCase())),
+    excludeInternal: input?.excludeInternal === true,
+  };
+  const hasFilter = criteria.excludeInternal || LIST_FIELDS.some((field) => lists[field].length > 0);
+  return hasFilter ? criteria : undefined;
+};
+
+const keepOperation = (criteria, { path, method, operation, pathItem }) => {
+  const tags = Array.isArray(operation.tags) ? operation.tags : [];
+  const matchesPath = (patterns) => patterns.some((pattern) => pattern.test(path));
+  if (criteria.excludeInternal && (operation["x-internal"] === true || pathItem["x-internal"] === true)) {
+    return false;
+  }
+  if (criteria.includeTags.size > 0 && !tags.some((tag) => criteria.includeTags.has(tag))) {
+    return false;
+  }
+  if (tags.some((tag) => criteria.excludeTags.has(tag))) {
+    return false;
+  }
+  if (criteria.includePaths.length > 0 && !matchesPath(criteria.includePaths)) {
+    return false;
+  }
+  if (matchesPath(criteria.excludePaths)) {
+    return false;
+  }
+  if (criteria.includeMethods.size > 0 && !criteria.includeMethods.has(method)) {
+    return false;
+  }
+  return !criteria.excludeMethods.has(method);
+};
+
+const usedTags = (spec) => {
+  const tags = new Set();
+  for (const rawPathItem of Object.values(isObject(spec.paths) ? spec.paths : {})) {
+    const pathItem = resolveRef(spec, rawPathItem);
+    for (const method of HTTP_METHODS) {
+      for (const tag of Array.isArray(pathItem?.[method]?.tags) ? pathItem[method].tags : []) {
+        tags.add(tag);
+      }
+    }
+  }
+  return tags;
+};
+
+/**
+ * Verwijdert de operaties die niet door de filters komen; een pad zonder operaties verdwijnt helemaal.
+ * Tags op het hoogste niveau die alleen door verwijderde operaties werden gebruikt vallen ook weg.
+ * Componenten blijven staan, ook als ze niet meer gebruikt worden.
+ */
+const filterDocument = (spec, criteria) => {
+  const tagsBefore = usedTags(spec);
+  let removed = 0;
+  for (const [path, rawPathItem] of Object.entries(isObject(spec.paths) ? spec.paths : {})) {
+    const pathItem = resolveRef(spec, rawPathItem);
+    if (!isObject(pathItem)) {
+      continue;
+    }
+    const methods = HTTP_METHODS.filter((method) => isObject(pathItem[method]));
+    const kept = methods.filter((method) =>
+      keepOperation(criteria, { path, method, operation: pathItem[method], pathItem }),
+    );
+    if (kept.length === methods.length) {
+      continue;
+    }
+    removed += methods.length - kept.length;
+    if (kept.length === 0) {
+      delete spec.paths[path];
+      continue;
+    }
+    // Een gedeeld path item (via $ref) wordt een eigen kopie, zodat andere paden het origineel houden.
+    const filtered = { ...pathItem };
+    for (const method of methods.filter((method) => !kept.includes(method))) {
+      delete filtered[method];
+    }
+    spec.paths[path] = filtered;
+  }
+  if (Array.isArray(spec.tags)) {
+    const tagsAfter = usedTags(spec);
+    spec.tags = spec.tags.filter((tag) => !tagsBefore.has(tag?.name) || tagsAfter.has(tag.name));
+  }
+  return { spec, removed };
+};
+
+/**
+ * Geeft een deelverzameling van de specificatie terug, bijvoorbeeld een publieke versie van een
+ * interne specificatie: op tags, pad-globs, methods en `x-internal`. Met `removeUnusedComponents`
+ * verdwijnen ook de componenten die na het filteren niet meer gebruikt worden.
+ */
+const filter = async (input, { responseFormat } = {}) => {
+  const criteria = buildCriteria(input);
+  if (!criteria) {
+    throw Service.rejectResponse(
+      { message: "Geef minstens één filter mee (tags, paden, methods of excludeInternal)." },
+      400,
+    );
+  }
+  const { spec, format, source } = await resolveOasDocument(input);
+  const { removed } = filterDocument(spec, criteria);
+  const pruned = input?.removeUnusedComponents === true ? pruneComponents(spec).removed : undefined;
+  stampDocument(
+    spec,
+    buildProvenance({
+      tool: "oas-filter",
+      source,
+      details: { removedComponents: pruned?.length > 0 ? pruned : undefined },
+    }),
+  );
+  const result = serializeOasDocument(spec, responseFormat ?? format, "openapi-filtered");
+  result.headers["X-Removed-Operations"] = String(removed);
+  if (pruned) {
+    result.headers["X-Removed-Components"] = String(pruned.length);
+  }
+  return result;
+};
+
+module.exports = {
+  filter,
+  globToRegExp,
+};
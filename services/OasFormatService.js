@@ -0,0 +1,292 @@
+const Service = require("./Service");
+const { resolveOasDocument, serializeOasDocument } = require("./OasInputService");
+const { HTTP_METHODS } = require("../utils/openapi");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+
+const OUTPUT_FORMATS = ["json", "yaml"];
+const STATUS_RANGE = /^[1-5]xx$/i;
+
+const SCHEMA_ORDER = [
+  "$ref",
+  "title",
+  "description",
+  "type",
+  "format",
+  "nullable",
+  "readOnly",
+  "writeOnly",
+  "deprecated",
+  "required",
+  "enum",
+  "const",
+  "default",
+  "pattern",
+  "minLength",
+  "maxLength",
+  "minimum",
+  "exclusiveMinimum",
+  "maximum",
+  "exclusiveMaximum",
+  "multipleOf",
+  "minItems",
+  "maxItems",
+  "uniqueItems",
+  "minProperties",
+  "maxProperties",
+  "discriminator",
+  "allOf",
+  "oneOf",
+  "anyOf",
+  "not",
+  "properties",
+  "additionalProperties",
+  "prefixItems",
+  "items",
+  "example",
+  "examples",
+];
+
+/**
+ * Per soort object de conventionele volgorde van de velden (zoals openapi-format die hanteert) en
+ * de soort van de kinderen: `"info"` voor één object, `["server"]` voor een lijst en `{ map: ... }`
+ * voor een map met namen. Velden die niet in de volgorde staan komen erachter, extensies (`x-`) als
+ * laatste; verder blijft hun volgorde gelijk.
+ */
+const KINDS = {
+  root: {
+    order: [
+      "openapi",
+      "info",
+      "jsonSchemaDialect",
+      "servers",
+      "security",
+      "tags",
+      "externalDocs",
+      "paths",
+      "webhooks",
+      "components",
+    ],
+    children: {
+      info: "info",
+      servers: ["server"],
+      tags: ["tag"],
+      paths: { map: "pathItem" },
+      webhooks: { map: "pathItem" },
+      components: "components",
+    },
+  },
+  info: {
+    order: ["title", "summary", "description", "termsOfService", "contact", "license", "version"],
+    children: {},
+  },
+  server: { order: ["url", "description", "variables"], children: {} },
+  tag: { order: ["name", "description", "externalDocs"], children: {} },
+  components: {
+    order: [
+      "schemas",
+      "responses",
+      "parameters",
+      "examples",
+      "requestBodies",
+      "headers",
+      "securitySchemes",
+      "links",
+      "callbacks",
+      "pathItems",
+    ],
+    children: {
+      schemas: { map: "schema" },
+      responses: { map: "response" },
+      parameters: { map: "parameter" },
+      requestBodies: { map: "requestBody" },
+      headers: { map: "header" },
+      securitySchemes: { map: "securityScheme" },
+      callbacks: { map: { map: "pathItem" } },
+      pathItems: { map: "pathItem" },
+    },
+  },
+  pathItem: {
+    order: ["$ref", "summary", "description", "servers", "parameters", ...HTTP_METHODS],
+    children: {
+      servers: ["server"],
+      parameters: ["parameter"],
+      ...Object.fromEntries(HTTP_METHODS.map((method) => [method, "operation"])),
+    },
+  },
+  operation: {
+    order: [
+      "tags",
+      "summary",
+      "description",
+      "operationId",
+      "parameters",
+      "requestBody",
+      "responses",
+      "callbacks",
+      "deprecated",
+      "security",
+      "servers",
+      "externalDocs",
+    ],
+    children: {
+      parameters: ["parameter"],
+      requestBody: "requestBody",
+      responses: "responses",
+      callbacks: { map: { map: "pathItem" } },
+      servers: ["server"],
+    },
+  },
+  parameter: {
+    order: [
+      "$ref",
+      "name",
+      "in",
+      "description",
+      "required",
+      "deprecated",
+      "allowEmptyValue",
+      "style",
+      "explode",
+      "allowReserved",
+      "schema",
+      "example",
+      "examples",
+      "content",
+    ],
+    children: { schema: "schema", content: { map: "mediaType" } },
+  },
+  header: {
+    order: ["$ref", "description", "required", "deprecated", "style", "explode", "schema", "example", "examples"],
+    children: { schema: "schema", content: { map: "mediaType" } },
+  },
+  requestBody: { order: ["$ref", "description", "required", "content"], children: { content: { map: "mediaType" } } },
+  mediaType: { order: ["schema", "example", "examples", "encoding"], children: { schema: "schema" } },
+  response: {
+    order: ["$ref", "description", "headers", "content", "links"],
+    children: { headers: { map: "header" }, content: { map: "mediaType" } },
+  },
+  securityScheme: {
+    order: ["$ref", "type", "description", "name", "in", "scheme", "bearerFormat", "flows", "openIdConnectUrl"],
+    children: {},
+  },
+  schema: {
+    order: SCHEMA_ORDER,
+    children: {
+      properties: { map: "schema" },
+      patternProperties: { map: "schema" },
+      $defs: { map: "schema" },
+      additionalProperties: "schema",
+      items: "schema",
+      not: "schema",
+      allOf: ["schema"],
+      oneOf: ["schema"],
+      anyOf: ["schema"],
+      prefixItems: ["schema"],
+    },
+  },
+};
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+// `2xx` wordt `2XX` en `GET` wordt `get`; andere sleutels blijven zoals ze zijn.
+const normalizeStatusCode = (code) => (STATUS_RANGE.test(code) ? code.toUpperCase() : code);
+
+const normalizeMethod = (key) => (HTTP_METHODS.includes(key.toLowerCase()) ? key.toLowerCase() : key);
+
+// Numerieke statuscodes staan in een JS-object altijd vooraan en oplopend; daarna volgen de bereiken
+// (`4XX`), `default` en extensies.
+const statusRank = (code) => {
+  if (/^\d+$/.test(code)) {
+    return 0;
+  }
+  if (STATUS_RANGE.test(code)) {
+    return Number(code[0]);
+  }
+  return code === "default" ? 10 : 11;
+};
+
+const orderKeys = (keys, order) => {
+  const known = order.filter((key) => keys.includes(key));
+  const others = keys.filter((key) => !order.includes(key) && !key.startsWith("x-"));
+  const extensions = keys.filter((key) => !order.includes(key) && key.startsWith("x-"));
+  return [...known, ...others, ...extensions];
+};
+
+const formatResponses = (value) => {
+  const entries = Object.entries(value).map(([code, response]) => [normalizeStatusCode(code), response]);
+  entries.sort(([a], [b]) => statusRank(a) - statusRank(b));
+  return Object.fromEntries(
+    entries.map(([code, response]) => [code, code.startsWith("x-") ? response : formatNode(response, "response")]),
+  );
+};
+
+const formatChild = (value, kind) => {
+  if (Array.isArray(kind)) {
+    return Array.isArray(value) ? value.map((item) => formatNode(item, kind[0])) : value;
+  }
+  if (isObject(kind)) {
+    return isObject(value)
+      ? Object.fromEntries(Object.entries(value).map(([name, item]) => [name, formatChild(item, kind.map)]))
+      : value;
+  }
+  return formatNode(value, kind);
+};
+
+/**
+ * Zet de velden van een object in de conventionele volgorde voor zijn soort en doet hetzelfde voor
+ * de kinderen waarvan de soort bekend is. Methods in een path item worden lowercase en statuscode-
+ * bereiken uppercase.
+ */
+const formatNode = (value, kind) => {
+  if (!isObject(value)) {
+    return value;
+  }
+  if (kind === "responses") {
+    return formatResponses(value);
+  }
+  const { order, children } = KINDS[kind];
+  const normalized =
+    kind === "pathItem"
+      ? Object.fromEntries(Object.entries(value).map(([key, child]) => [normalizeMethod(key), child]))
+      : value;
+  return Object.fromEntries(
+    orderKeys(Object.keys(normalized), order).map((key) => {
+      const child = normalized[key];
+      return [key, Object.hasOwn(children, key) ? formatChild(child, children[key]) : child];
+    }),
+  );
+};
+
+const formatDocument = (spec) => formatNode(spec, "root");
+
+const resolveOutputFormat = (value, inputFormat) => {
+  if (value === undefined || value === null || value === "") {
+    return inputFormat;
+  }
+  const normalized = String(value).toLowerCase();
+  if (!OUTPUT_FORMATS.includes(normalized)) {
+    throw Service.rejectResponse(
+      { message: `Onbekend outputFormat "${value}". Kies uit: ${OUTPUT_FORMATS.join(", ")}.` },
+      400,
+    );
+  }
+  return normalized;
+};
+
+/**
+ * Normaliseert een specificatie zoals openapi-format: velden in de conventionele volgorde, methods
+ * lowercase en statuscodes oplopend. Met `outputFormat` (json of yaml) wordt het document meteen
+ * omgezet; zonder blijft het formaat van de input.
+ */
+const format = async (input, { outputFormat, responseFormat } = {}) => {
+  const resolved = await resolveOasDocument(input);
+  const targetFormat = resolveOutputFormat(outputFormat, responseFormat ?? resolved.format);
+  const formatted = formatDocument(resolved.spec);
+  stampDocument(formatted, buildProvenance({ tool: "oas-format", source: resolved.source }));
+  return serializeOasDocument(formatted, targetFormat, "openapi-formatted");
+};
+
+module.exports = {
+  format,
+  formatDocument,
+};
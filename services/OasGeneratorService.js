@@ -2,6 +2,7 @@ const { kebabCase, upperCamelCase } = require("case-anything");
 const Service = require("./Service");
 const { resolveOasInput } = require("./OasInputService");
 const { sanitizeFileName } = require("../utils/fileName");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
 const logger = require("../logger");
 
 const EMPTY_BODY_ERROR = "Body ontbreekt of heeft een ongeldig formaat.";
@@ -319,7 +320,7 @@ const deriveFilename = (title) => {
 };
 
 const generate = async (input) => {
-  const { contents } = await resolveOasInput(input);
+  const { contents, source } = await resolveOasInput(input);
   let config;
   try {
     config = parseGeneratorConfig(contents);
@@ -329,7 +330,7 @@ const generate = async (input) => {
   }
 
   try {
-    const document = buildOpenApiDocument(config);
+    const document = stampDocument(buildOpenApiDocument(config), buildProvenance({ tool: "oas-generate", source }));
     const filename = deriveFilename(config.title);
     const buffer = Buffer.from(JSON.stringify(document, null, 2), "utf8");
     return {
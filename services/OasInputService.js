@@ -1,5 +1,21 @@
 const Service = require("./Service");
+const { hasArchiveInput, resolveArchiveInput } = require("./OasArchiveService");
 const { fetchSpecification } = require("./RemoteSpecificationService");
+const { stripBom } = require("../utils/encoding");
+const { assertInputSize } = require("../utils/inputSize");
+const { YamlLimitError, dumpYaml, parseJsonOrYaml } = require("../utils/yaml");
+
+const PARSE_ERROR = "Kan OpenAPI specificatie niet parseren.";
+const INVALID_DOCUMENT_ERROR = "OpenAPI document moet een object zijn.";
+
+// Een `oasBody` groter dan OAS_MAX_INPUT_BYTES wordt niet verwerkt (413).
+const assertOasBodySize = (oasBody) => {
+  try {
+    assertInputSize(Buffer.byteLength(oasBody, "utf8"));
+  } catch (error) {
+    throw Service.rejectResponse({ message: error.message }, 413);
+  }
+};
 
 const resolveOasInput = async (input) => {
   if (!input || typeof input !== "object") {
@@ -12,11 +28,15 @@ const resolveOasInput = async (input) => {
   }
   const { oasBody, oasUrl } = input;
   if (typeof oasBody === "string" && oasBody.trim().length > 0) {
+    assertOasBodySize(oasBody);
     return {
       source: "request-body",
-      contents: oasBody,
+      contents: stripBom(oasBody),
     };
   }
+  if (hasArchiveInput(input)) {
+    return resolveArchiveInput(input);
+  }
   if (typeof oasUrl === "string" && oasUrl.trim().length > 0) {
     let parsedUrl;
     try {
@@ -31,6 +51,7 @@ const resolveOasInput = async (input) => {
     }
     const contents = await fetchSpecification(parsedUrl.toString(), {
       errorMessage: "Het ophalen van de OpenAPI specificatie is mislukt.",
+      headers: input.headers,
     });
     return {
       source: parsedUrl.toString(),
@@ -39,12 +60,109 @@ const resolveOasInput = async (input) => {
   }
   throw Service.rejectResponse(
     {
-      message: "Geef een oasBody of oasUrl mee.",
+      message: "Geef een oasBody, oasUrl of oasArchive mee.",
     },
     400,
   );
 };
 
+/**
+ * Parseert de opgehaalde specificatie naar een object en onthoudt het formaat (json/yaml), zodat
+ * een bewerkt document in hetzelfde formaat teruggegeven kan worden.
+ */
+const parseOasDocument = (contents) => {
+  const text = typeof contents === "string" ? contents.trim() : "";
+  if (!text) {
+    throw Service.rejectResponse({ message: "Body ontbreekt of ongeldig: gebruik oasUrl of oasBody" }, 400);
+  }
+  let parsed;
+  try {
+    parsed = parseJsonOrYaml(text);
+  } catch (error) {
+    const message = error instanceof YamlLimitError ? error.message : PARSE_ERROR;
+    throw Service.rejectResponse({ message, detail: error.message }, 400);
+  }
+  if (!parsed.document || typeof parsed.document !== "object" || Array.isArray(parsed.document)) {
+    throw Service.rejectResponse({ message: INVALID_DOCUMENT_ERROR }, 400);
+  }
+  return { spec: parsed.document, format: parsed.format };
+};
+
+const DOCUMENT_FORMATS = ["json", "yaml"];
+const DOCUMENT_MEDIA_TYPES = {
+  "application/json": "json",
+  "application/openapi+json": "json",
+  "application/vnd.oai.openapi+json": "json",
+  "application/yaml": "yaml",
+  "application/x-yaml": "yaml",
+  "application/openapi+yaml": "yaml",
+  "application/vnd.oai.openapi": "yaml",
+  "text/yaml": "yaml",
+  "text/x-yaml": "yaml",
+};
+
+// Het formaat met de hoogste q-waarde; bij gelijke q telt de volgorde in de header.
+const formatFromAccept = (accept) => {
+  const candidates = String(accept ?? "")
+    .split(",")
+    .map((range, position) => {
+      const [type, ...parameters] = range.split(";").map((part) => part.trim().toLowerCase());
+      const q = parameters.find((parameter) => parameter.startsWith("q="));
+      return { format: DOCUMENT_MEDIA_TYPES[type], q: q ? Number.parseFloat(q.slice(2)) : 1, position };
+    })
+    .filter(({ format, q }) => format && q > 0)
+    .sort((a, b) => b.q - a.q || a.position - b.position);
+  return candidates[0]?.format;
+};
+
+/**
+ * Bepaalt het gevraagde formaat (json of yaml) van een specificatie in de response: de query-parameter
+ * `format` gaat voor, daarna de `Accept` header. Zonder voorkeur (of met alleen een wildcard) volgt
+ * `undefined` en kiest het endpoint zelf, meestal het formaat van de input.
+ */
+const negotiateDocumentFormat = ({ format, accept } = {}) => {
+  if (format !== undefined && format !== null && format !== "") {
+    const normalized = String(format).toLowerCase();
+    if (!DOCUMENT_FORMATS.includes(normalized)) {
+      throw Service.rejectResponse(
+        { message: `Onbekend format "${format}". Kies uit: ${DOCUMENT_FORMATS.join(", ")}.` },
+        400,
+      );
+    }
+    return normalized;
+  }
+  return formatFromAccept(accept);
+};
+
+const resolveOasDocument = async (input) => {
+  const resolved = await resolveOasInput(input);
+  return { source: resolved.source, contents: resolved.contents, ...parseOasDocument(resolved.contents) };
+};
+
+const serializeOasDocument = (spec, format, filenameBase) => {
+  if (format === "json") {
+    return {
+      headers: {
+        "Content-Type": "application/json",
+        "Content-Disposition": `attachment; filename="${filenameBase}.json"`,
+      },
+      rawBody: Buffer.from(JSON.stringify(spec, null, 2), "utf8"),
+    };
+  }
+  return {
+    headers: {
+      "Content-Type": "application/yaml",
+      "Content-Disposition": `attachment; filename="${filenameBase}.yaml"`,
+    },
+    rawBody: Buffer.from(dumpYaml(spec), "utf8"),
+  };
+};
+
 module.exports = {
+  assertOasBodySize,
+  negotiateDocumentFormat,
+  parseOasDocument,
+  resolveOasDocument,
   resolveOasInput,
+  serializeOasDocument,
 };
@@ -0,0 +1,124 @@
+const { resolveOasDocument, serializeOasDocument } = require("./OasInputService");
+const { decodePointerSegment } = require("../utils/jsonPointer");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+
+const COMPONENT_REF = /^#\/components\/([^/]+)\/([^/]+)/;
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+const componentKey = (section, name) => `${section}/${name}`;
+
+/**
+ * Verzamelt de componenten die vanuit het document bereikbaar zijn: via `$ref`, via de namen in
+ * security requirements en via de mapping van een discriminator. Het zoeken begint bij alles buiten
+ * `components` (paden, webhooks, ...) en gaat verder in elk component dat daarbij gevonden wordt.
+ */
+const collectReachable = (spec) => {
+  const components = isObject(spec.components) ? spec.components : {};
+  const reachable = new Set();
+  const pending = [];
+  const mark = (section, name) => {
+    const key = componentKey(section, name);
+    if (!reachable.has(key) && isObject(components[section]) && Object.hasOwn(components[section], name)) {
+      reachable.add(key);
+      pending.push(components[section][name]);
+    }
+  };
+  const markRef = (ref) => {
+    const match = typeof ref === "string" ? COMPONENT_REF.exec(ref) : null;
+    if (match) {
+      mark(decodePointerSegment(match[1]), decodePointerSegment(match[2]));
+    }
+  };
+  const visit = (value) => {
+    if (Array.isArray(value)) {
+      value.forEach(visit);
+      return;
+    }
+    if (!isObject(value)) {
+      return;
+    }
+    for (const [key, child] of Object.entries(value)) {
+      if (key === "$ref") {
+        markRef(child);
+      } else if (key === "security" && Array.isArray(child)) {
+        for (const requirement of child.filter(isObject)) {
+          Object.keys(requirement).forEach((name) => mark("securitySchemes", name));
+        }
+      } else if (key === "discriminator" && isObject(child?.mapping)) {
+        // Een mapping-waarde is een $ref of alleen de naam van een schema.
+        for (const target of Object.values(child.mapping)) {
+          if (typeof target === "string" && target.startsWith("#")) {
+            markRef(target);
+          } else if (typeof target === "string") {
+            mark("schemas", target);
+          }
+        }
+      }
+      // Een example is data; een property die `$ref` heet is daar geen verwijzing.
+      if (key !== "example") {
+        visit(child);
+      }
+    }
+  };
+  for (const [key, value] of Object.entries(spec)) {
+    if (key !== "components") {
+      visit({ [key]: value });
+    }
+  }
+  while (pending.length > 0) {
+    visit(pending.pop());
+  }
+  return reachable;
+};
+
+/**
+ * Verwijdert de componenten die vanuit geen enkel pad of webhook (of ander deel buiten `components`)
+ * bereikbaar zijn. Secties die daardoor leeg raken vervallen. `removed` noemt elk verwijderd component
+ * als JSON pointer.
+ */
+const pruneComponents = (spec) => {
+  const removed = [];
+  if (!isObject(spec.components)) {
+    return { spec, removed };
+  }
+  const reachable = collectReachable(spec);
+  for (const [section, entries] of Object.entries(spec.components)) {
+    if (!isObject(entries)) {
+      continue;
+    }
+    const unused = Object.keys(entries).filter((name) => !reachable.has(componentKey(section, name)));
+    for (const name of unused) {
+      removed.push(`#/components/${section}/${name}`);
+      delete entries[name];
+    }
+    if (unused.length > 0 && Object.keys(entries).length === 0) {
+      delete spec.components[section];
+    }
+  }
+  if (removed.length > 0 && Object.keys(spec.components).length === 0) {
+    delete spec.components;
+  }
+  return { spec, removed };
+};
+
+const prune = async (input, { responseFormat } = {}) => {
+  const { spec, format, source } = await resolveOasDocument(input);
+  const { removed } = pruneComponents(spec);
+  stampDocument(
+    spec,
+    buildProvenance({
+      tool: "oas-prune",
+      source,
+      details: { removedComponents: removed.length > 0 ? removed : undefined },
+    }),
+  );
+  const result = serializeOasDocument(spec, responseFormat ?? format, "openapi-pruned");
+  result.headers["X-Removed-Components"] = String(removed.length);
+  return result;
+};
+
+module.exports = {
+  prune,
+  pruneComponents,
+};
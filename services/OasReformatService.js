@@ -0,0 +1,55 @@
+const Service = require("./Service");
+const { parseOasDocument, resolveOasInput } = require("./OasInputService");
+const { fromPlain, parseOrderedDocument, serializeOrdered } = require("../utils/orderedDocument");
+const { PROVENANCE_EXTENSION, buildProvenance } = require("../utils/provenance");
+
+const TARGET_FORMATS = ["json", "yaml"];
+
+const CONTENT_TYPES = {
+  json: "application/json",
+  yaml: "application/yaml",
+};
+
+const resolveTargetFormat = (value, sourceFormat) => {
+  if (value === undefined || value === null || value === "") {
+    return sourceFormat === "json" ? "yaml" : "json";
+  }
+  const normalized = String(value).toLowerCase();
+  if (!TARGET_FORMATS.includes(normalized)) {
+    throw Service.rejectResponse(
+      { message: `Onbekend targetFormat "${value}". Kies uit: ${TARGET_FORMATS.join(", ")}.` },
+      400,
+    );
+  }
+  return normalized;
+};
+
+/**
+ * Zet een specificatie om tussen JSON en YAML zonder iets aan de inhoud te veranderen: alle sleutels
+ * houden de volgorde uit de bron, ook statuscodes als `404` voor `200`. Zonder `targetFormat` wordt
+ * het het andere formaat. YAML-commentaar en anchors gaan verloren (JSON kent ze niet); een alias
+ * wordt uitgeschreven. Het herkomstblok komt als laatste sleutel, zodat een diff verder leeg blijft.
+ */
+const reformat = async (input, { targetFormat } = {}) => {
+  const { contents, source } = await resolveOasInput(input);
+  // parseOasDocument bewaakt de limieten en geeft dezelfde foutmeldingen als de andere tools.
+  const { format } = parseOasDocument(contents);
+  const { document } = parseOrderedDocument(contents);
+  const target = resolveTargetFormat(targetFormat, format);
+  document.delete(PROVENANCE_EXTENSION);
+  document.set(
+    PROVENANCE_EXTENSION,
+    fromPlain(buildProvenance({ tool: "oas-reformat", source, details: { sourceFormat: format } })),
+  );
+  return {
+    headers: {
+      "Content-Type": CONTENT_TYPES[target],
+      "Content-Disposition": `attachment; filename="openapi.${target}"`,
+    },
+    rawBody: Buffer.from(serializeOrdered(document, target), "utf8"),
+  };
+};
+
+module.exports = {
+  reformat,
+};
@@ -0,0 +1,130 @@
+const { resolveOasDocument, serializeOasDocument } = require("./OasInputService");
+const { lookupPointer } = require("../utils/jsonPointer");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+
+const ADR_COMPONENTS_URL = "https://static.developer.overheid.nl/adr/components.yaml";
+const HTTP_METHODS = ["get", "put", "post", "delete", "options", "head", "patch", "trace"];
+const BODYLESS_SUCCESS_STATUSES = new Set(["204", "205", "304"]);
+
+const isSuccessStatus = (status) => /^2(\d\d|XX)$/i.test(status);
+
+const hasContent = (response, spec) => {
+  const resolved = resolveLocalRef(response, spec);
+  return Boolean(resolved?.content && Object.keys(resolved.content).length > 0);
+};
+
+const resolveLocalRef = (value, spec) =>
+  typeof value?.$ref === "string" ? (lookupPointer(spec, value.$ref) ?? value) : value;
+
+const isSecured = (operation, spec) => {
+  const requirements = Array.isArray(operation.security) ? operation.security : spec.security;
+  if (!Array.isArray(requirements) || requirements.length === 0) {
+    return false;
+  }
+  // Een leeg requirement-object betekent dat anoniem aanroepen ook is toegestaan.
+  return !requirements.some((requirement) => requirement && Object.keys(requirement).length === 0);
+};
+
+const isCovered = (responses, status) => Object.hasOwn(responses, status) || Object.hasOwn(responses, `${status[0]}XX`);
+
+const requiredErrorStatuses = (operation, spec) => {
+  const statuses = ["400"];
+  if (isSecured(operation, spec)) {
+    statuses.push("401", "403");
+  }
+  statuses.push("500");
+  return statuses;
+};
+
+const inspectOperation = (spec, pathKey, method, operation) => {
+  const responses = operation.responses && typeof operation.responses === "object" ? operation.responses : {};
+  const successStatuses = Object.keys(responses).filter(isSuccessStatus);
+  const hasSuccessContent = successStatuses.some(
+    (status) => BODYLESS_SUCCESS_STATUSES.has(status) || method === "head" || hasContent(responses[status], spec),
+  );
+  const missingErrorResponses = requiredErrorStatuses(operation, spec).filter(
+    (status) => !isCovered(responses, status),
+  );
+  return {
+    path: pathKey,
+    method,
+    operationId: operation.operationId,
+    hasSuccessResponse: successStatuses.length > 0,
+    hasSuccessContent,
+    missingErrorResponses,
+  };
+};
+
+const forEachOperation = (spec, callback) => {
+  const paths = spec.paths && typeof spec.paths === "object" ? spec.paths : {};
+  for (const [pathKey, pathItem] of Object.entries(paths)) {
+    if (!pathItem || typeof pathItem !== "object") {
+      continue;
+    }
+    for (const method of HTTP_METHODS) {
+      const operation = pathItem[method];
+      if (operation && typeof operation === "object") {
+        callback(pathKey, method, operation);
+      }
+    }
+  }
+};
+
+const analyze = (spec) => {
+  const operations = [];
+  forEachOperation(spec, (pathKey, method, operation) => {
+    operations.push(inspectOperation(spec, pathKey, method, operation));
+  });
+  const incomplete = operations.filter(
+    (operation) =>
+      !operation.hasSuccessResponse || !operation.hasSuccessContent || operation.missingErrorResponses.length > 0,
+  );
+  return {
+    operationCount: operations.length,
+    compliantCount: operations.length - incomplete.length,
+    operations: incomplete,
+  };
+};
+
+/**
+ * Vult ontbrekende foutresponses aan met een verwijzing naar de standaardresponse uit het ADR
+ * components-bestand. Ontbrekende success-content kan niet automatisch worden bedacht en blijft
+ * in het rapport staan.
+ */
+const applyFixes = (spec) => {
+  const inserted = [];
+  forEachOperation(spec, (pathKey, method, operation) => {
+    if (!operation.responses || typeof operation.responses !== "object") {
+      operation.responses = {};
+    }
+    for (const status of requiredErrorStatuses(operation, spec)) {
+      if (!isCovered(operation.responses, status)) {
+        operation.responses[status] = { $ref: `${ADR_COMPONENTS_URL}#/responses/${status}` };
+        inserted.push({ path: pathKey, method, status });
+      }
+    }
+  });
+  return inserted;
+};
+
+const check = async (input) => {
+  const { spec } = await resolveOasDocument(input);
+  return analyze(spec);
+};
+
+const fix = async (input, { responseFormat } = {}) => {
+  const { spec, format, source } = await resolveOasDocument(input);
+  const inserted = applyFixes(spec);
+  stampDocument(spec, buildProvenance({ tool: "oas-responses-fix", source }));
+  const result = serializeOasDocument(spec, responseFormat ?? format, "openapi-responses");
+  result.headers["X-Inserted-Responses"] = String(inserted.length);
+  return result;
+};
+
+module.exports = {
+  ADR_COMPONENTS_URL,
+  analyze,
+  applyFixes,
+  check,
+  fix,
+};
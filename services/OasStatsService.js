@@ -0,0 +1,89 @@
+const { resolveOasDocument } = require("./OasInputService");
+const { HTTP_METHODS, collectOperations } = require("../utils/openapi");
+const { isSwagger2, upgradeSwagger2 } = require("../utils/swagger");
+
+// Kinderen die een niveau dieper liggen; allOf/oneOf/anyOf/not combineren op hetzelfde niveau.
+const NESTED_KEYWORDS = ["properties", "patternProperties", "items", "additionalProperties", "prefixItems"];
+const MAP_KEYWORDS = new Set(["properties", "patternProperties"]);
+const COMPOSITION_KEYWORDS = ["allOf", "oneOf", "anyOf", "not"];
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+const countKeys = (value) => (isObject(value) ? Object.keys(value).length : 0);
+
+const subschemas = (value) => {
+  if (Array.isArray(value)) {
+    return value.filter(isObject);
+  }
+  return isObject(value) ? [value] : [];
+};
+
+/**
+ * De nestingdiepte van een schema zoals het geschreven is: een schema zonder kinderen is 1 diep,
+ * properties, items en additionalProperties liggen een niveau dieper. Een `$ref` telt als één niveau
+ * en wordt niet gevolgd, zodat recursieve schema's een eindige diepte hebben.
+ */
+const schemaDepth = (schema) => {
+  if (!isObject(schema) || typeof schema.$ref === "string") {
+    return 1;
+  }
+  let depth = 1;
+  for (const keyword of NESTED_KEYWORDS) {
+    const children = MAP_KEYWORDS.has(keyword) ? Object.values(schema[keyword] ?? {}) : schema[keyword];
+    for (const child of subschemas(children)) {
+      depth = Math.max(depth, 1 + schemaDepth(child));
+    }
+  }
+  for (const keyword of COMPOSITION_KEYWORDS) {
+    for (const child of subschemas(schema[keyword])) {
+      depth = Math.max(depth, schemaDepth(child));
+    }
+  }
+  return depth;
+};
+
+const round = (value) => Math.round(value * 100) / 100;
+
+/**
+ * Kengetallen over de omvang en complexiteit van een specificatie. `parameters` telt de parameters
+ * per operatie (inclusief die van het pad); `schemas` en `securitySchemes` tellen de componenten.
+ * Swagger 2.0 wordt eerst omgezet, zodat `definitions` als schema's meetellen.
+ */
+const computeStats = (spec, contents = "") => {
+  const document = isSwagger2(spec) ? upgradeSwagger2(spec) : spec;
+  const operations = collectOperations(document);
+  const byMethod = Object.fromEntries(
+    HTTP_METHODS.map((method) => [method, operations.filter((operation) => operation.method === method).length]),
+  );
+  const schemas = isObject(document.components?.schemas) ? document.components.schemas : {};
+  const depths = Object.values(schemas).map(schemaDepth);
+  const totalDepth = depths.reduce((total, depth) => total + depth, 0);
+  return {
+    title: document.info?.title,
+    version: document.info?.version,
+    openapi: spec.openapi ?? spec.swagger,
+    paths: countKeys(document.paths),
+    webhooks: countKeys(document.webhooks),
+    operations: { total: operations.length, byMethod },
+    parameters: operations.reduce((total, operation) => total + operation.parameters.length, 0),
+    schemas: depths.length,
+    securitySchemes: countKeys(document.components?.securitySchemes),
+    averageSchemaDepth: depths.length > 0 ? round(totalDepth / depths.length) : 0,
+    maxSchemaDepth: depths.length > 0 ? Math.max(...depths) : 0,
+    documentSize: {
+      bytes: Buffer.byteLength(contents, "utf8"),
+      lines: contents ? contents.replace(/\r?\n$/, "").split(/\r?\n/).length : 0,
+    },
+  };
+};
+
+const stats = async (input) => {
+  const { spec, contents } = await resolveOasDocument(input);
+  return computeStats(spec, contents);
+};
+
+module.exports = {
+  computeStats,
+  schemaDepth,
+  stats,
+};
@@ -0,0 +1,84 @@
+const { Spectral, Document } = require("@stoplight/spectral-core");
+const Parsers = require("@stoplight/spectral-parsers");
+const Service = require("./Service");
+const { resolveOasInput } = require("./OasInputService");
+const { createSpectralResolver } = require("./RemoteSpecificationService");
+const { formatPointer } = require("../utils/jsonPointer");
+const { YamlLimitError, assertSafeYaml } = require("../utils/yaml");
+const logger = require("../logger");
+
+// De regels uit de standaard `oas` ruleset die het document tegen de officiële JSON Schema's van
+// Swagger 2.0 en OpenAPI 3.0/3.1 valideren; verder niets.
+const SCHEMA_RULES = ["oas2-schema", "oas3-schema"];
+
+let structureSpectralPromise;
+
+const loadStructureSpectral = () => {
+  if (!structureSpectralPromise) {
+    structureSpectralPromise = (async () => {
+      const { oas } = require("@stoplight/spectral-rulesets");
+      const spectral = new Spectral({ resolver: createSpectralResolver() });
+      spectral.setRuleset({
+        extends: [[oas, "off"]],
+        rules: Object.fromEntries(SCHEMA_RULES.map((rule) => [rule, "error"])),
+      });
+      return spectral;
+    })().catch((error) => {
+      structureSpectralPromise = undefined;
+      logger.error(`[OasStructureService] Unable to load schema rules: ${error.message}`);
+      throw Service.rejectResponse(
+        { message: "Kan de regels voor structurele validatie niet laden.", detail: error.message },
+        500,
+      );
+    });
+  }
+  return structureSpectralPromise;
+};
+
+const toError = (diagnostic) => {
+  const line = diagnostic.range?.start?.line;
+  return {
+    code: String(diagnostic.code || "parser"),
+    path: formatPointer(Array.isArray(diagnostic.path) ? diagnostic.path : []),
+    message: diagnostic.message,
+    line: typeof line === "number" ? line + 1 : undefined,
+  };
+};
+
+const detectSpecVersion = (document) => {
+  const version = document?.openapi ?? document?.swagger;
+  return version === undefined || version === null ? undefined : String(version);
+};
+
+/**
+ * Controleert alleen of het document een geldige OpenAPI (of Swagger 2.0) specificatie is volgens de
+ * officiële JSON Schema's, plus parseerfouten in de JSON of YAML. De ADR-regels spelen hier geen rol;
+ * die lint `POST /v1/oas/validate`. Bevindingen zijn altijd fouten.
+ */
+const validateStructure = async (input) => {
+  const { contents, source } = await resolveOasInput(input);
+  let parsed;
+  try {
+    parsed = assertSafeYaml(contents);
+  } catch (error) {
+    if (error instanceof YamlLimitError) {
+      throw Service.rejectResponse({ message: error.message }, 400);
+    }
+    throw error;
+  }
+  const spectral = await loadStructureSpectral();
+  const document = new Document(contents, Parsers.Yaml, source);
+  const parseDiagnostics = Array.isArray(document.diagnostics) ? document.diagnostics : [];
+  const diagnostics = await spectral.run(document, { ignoreUnknownFormat: false });
+  const errors = [...parseDiagnostics, ...diagnostics].map(toError);
+  return {
+    valid: errors.length === 0,
+    specVersion: detectSpecVersion(parsed),
+    errorCount: errors.length,
+    errors,
+  };
+};
+
+module.exports = {
+  validateStructure,
+};
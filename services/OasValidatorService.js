@@ -1,42 +1,75 @@
+const { execFile } = require("node:child_process");
 const { randomUUID } = require("node:crypto");
+const fs = require("node:fs/promises");
+const path = require("node:path");
+const { promisify } = require("node:util");
 const { Spectral, Document } = require("@stoplight/spectral-core");
 const Parsers = require("@stoplight/spectral-parsers");
 const Service = require("./Service");
-const { fetchSpecification } = require("./RemoteSpecificationService");
+const { renderMarkdown } = require("./LintMarkdownService");
+const {
+  buildRemoteSpectral,
+  collectRulesetFunctions,
+  getRemoteRuleset,
+  startRemoteRulesetRefresh,
+} = require("./RemoteRulesetService");
+const { hasArchiveInput, resolveArchiveInput } = require("./OasArchiveService");
+const { assertOasBodySize } = require("./OasInputService");
+const { createSpectralResolver, fetchSpecification } = require("./RemoteSpecificationService");
+const {
+  computeScore,
+  getEmbeddedPolicy,
+  getScoringPolicy,
+  isMeasured,
+  parsePolicy,
+} = require("./ScoringPolicyService");
+const { mapWithConcurrency } = require("../utils/concurrency");
+const { stripBom } = require("../utils/encoding");
+const { OUTBOUND_GUARD_ARGS } = require("../utils/outboundPolicy");
+const { createTempFiles } = require("../utils/tempFiles");
+const { WorkerTimeoutError, createWorkerPool } = require("../utils/workerPool");
+const { YamlLimitError, assertSafeYaml } = require("../utils/yaml");
 const logger = require("../logger");
 
+const execFileAsync = promisify(execFile);
+
 const RULESET_LOADERS = {
   "2.0": () => import("@developer-overheid-nl/adr-rulesets/rulesets/adr-20"),
   "2.1": () => import("@developer-overheid-nl/adr-rulesets/rulesets/adr-21"),
+  owasp: () => import("../rulesets/owasp.js"),
 };
+const ADR_RULESET_VERSIONS = ["2.0", "2.1"];
 const DEFAULT_RULESET_VERSION = "2.1";
 
+const RULESETS = ["adr", "owasp"];
+const DEFAULT_RULESET = "adr";
+const OWASP_RULESET_PATH = path.join(__dirname, "..", "rulesets", "owasp.js");
+const SPECTRAL_CLI_ENTRY = "@stoplight/spectral-cli/dist/index.js";
+
+const OUTPUT_FORMATS = ["lintresult", "spectral", "github", "markdown"];
+const DEFAULT_OUTPUT_FORMAT = "lintresult";
+
+const ENGINES = ["embedded", "spectral-cli"];
+const DEFAULT_ENGINE = "embedded";
+const DEFAULT_LINT_TIMEOUT_SECONDS = 120;
+const DEFAULT_MAX_LINT_TIMEOUT_SECONDS = 300;
+
+const DEFAULT_BATCH_CONCURRENCY = 4;
+const MAX_BATCH_SIZE = 100;
+
 const SEVERITY_LABELS = ["error", "warning", "info", "hint"];
 
-const MEASURED_RULE_GROUPS = {
-  openapi3: "openapi3",
-  "nlgov:openapi3": "openapi3",
-  "openapi-root-exists": "openapi-root-exists",
-  "nlgov:openapi-root-exists": "openapi-root-exists",
-  "missing-version-header": "version-header",
-  "nlgov:missing-version-header": "version-header",
-  "missing-header": "version-header",
-  "nlgov:missing-header": "version-header",
-  "include-major-version-in-uri": "include-major-version-in-uri",
-  "nlgov:include-major-version-in-uri": "include-major-version-in-uri",
-  "paths-no-trailing-slash": "paths-no-trailing-slash",
-  "nlgov:paths-no-trailing-slash": "paths-no-trailing-slash",
-  "info-contact-fields-exist": "info-contact-fields-exist",
-  "nlgov:info-contact-fields-exist": "info-contact-fields-exist",
-  "http-methods": "http-methods",
-  "nlgov:http-methods": "http-methods",
-  semver: "semver",
-  "nlgov:semver": "semver",
-};
-
-const MEASURED_GROUP_KEYS = Array.from(new Set(Object.values(MEASURED_RULE_GROUPS)));
+const EXAMPLE_RULES = [
+  "oas2-valid-media-example",
+  "oas2-valid-schema-example",
+  "oas3-valid-media-example",
+  "oas3-valid-schema-example",
+];
+
+const REMOTE_RULESET_VERSION = DEFAULT_RULESET_VERSION;
 
 const spectralInstancePromises = new Map();
+let exampleSpectralPromise;
 
 const loadSpectral = (rulesetVersion) => {
   if (!spectralInstancePromises.has(rulesetVersion)) {
@@ -44,7 +77,7 @@ const loadSpectral = (rulesetVersion) => {
       try {
         const loader = RULESET_LOADERS[rulesetVersion];
         const module = await loader();
-        const spectral = new Spectral();
+        const spectral = new Spectral({ resolver: createSpectralResolver() });
         spectral.setRuleset(module.default);
         return spectral;
       } catch (error) {
@@ -64,7 +97,50 @@ const loadSpectral = (rulesetVersion) => {
   return spectralInstancePromises.get(rulesetVersion);
 };
 
-const resolveSpecificationInput = async (input) => {
+// Eigen functies van de meegeleverde ADR ruleset, op naam, voor een gepubliceerde ruleset die ze gebruikt.
+const loadEmbeddedFunctions = async () =>
+  collectRulesetFunctions((await RULESET_LOADERS[REMOTE_RULESET_VERSION]()).default);
+
+let remoteSpectral;
+
+// Per thread één instantie voor de laatst meegegeven ruleset; een nieuwe versie vervangt die.
+const loadRemoteSpectral = (remoteRuleset) => {
+  if (remoteSpectral?.hash !== remoteRuleset.hash) {
+    const promise = loadEmbeddedFunctions().then((functions) => buildRemoteSpectral(remoteRuleset.contents, functions));
+    remoteSpectral = { hash: remoteRuleset.hash, promise };
+    promise.catch(() => {
+      if (remoteSpectral?.promise === promise) {
+        remoteSpectral = undefined;
+      }
+    });
+  }
+  return remoteSpectral.promise;
+};
+
+/**
+ * Voor de standaard ADR-versie gaat een op afstand gepubliceerde ruleset (ADR_RULESET_URL, zie
+ * `getRemoteRuleset`) voor de meegeleverde kopie; die blijft de fallback als de ruleset niet te laden is.
+ */
+const resolveSpectral = async (rulesetVersion, remoteRuleset) => {
+  if (rulesetVersion === REMOTE_RULESET_VERSION && remoteRuleset) {
+    try {
+      return await loadRemoteSpectral(remoteRuleset);
+    } catch (error) {
+      logger.warn(`[OasValidatorService] gepubliceerde ruleset niet geladen, terug naar de kopie: ${error.message}`);
+    }
+  }
+  return loadSpectral(rulesetVersion);
+};
+
+/**
+ * Start het periodiek ophalen van de ruleset van `ADR_RULESET_URL` (zie RemoteRulesetService).
+ */
+const startRulesetRefresh = () => startRemoteRulesetRefresh(loadEmbeddedFunctions);
+
+const isNonEmptyString = (value) => typeof value === "string" && value.trim().length > 0;
+
+// Wat zonder ophalen te controleren is; de streaming-variant begint pas na deze controle aan de stream.
+const assertSpecificationInput = (input) => {
   if (!input || typeof input !== "object") {
     throw Service.rejectResponse(
       {
@@ -73,40 +149,51 @@ const resolveSpecificationInput = async (input) => {
       400,
     );
   }
+  if (!isNonEmptyString(input.oasBody) && !hasArchiveInput(input) && !isNonEmptyString(input.oasUrl)) {
+    throw Service.rejectResponse(
+      {
+        message: "Geef een oasBody, oasUrl of oasArchive mee.",
+      },
+      400,
+    );
+  }
+  if (isNonEmptyString(input.oasBody)) {
+    assertOasBodySize(input.oasBody);
+  }
+};
+
+const resolveSpecificationInput = async (input) => {
+  assertSpecificationInput(input);
   const { oasBody, oasUrl } = input;
-  if (typeof oasBody === "string" && oasBody.trim().length > 0) {
+  if (isNonEmptyString(oasBody)) {
     return {
       source: "request-body",
-      contents: oasBody,
+      contents: stripBom(oasBody),
     };
   }
-  if (typeof oasUrl === "string" && oasUrl.trim().length > 0) {
-    let parsedUrl;
-    try {
-      parsedUrl = new URL(oasUrl);
-    } catch (error) {
-      logger.error("[OasValidatorService] invalid oasUrl", { message: error.message });
-      throw Service.rejectResponse(
-        {
-          message: "De waarde van oasUrl is geen geldige URL.",
-        },
-        400,
-      );
-    }
-    const contents = await fetchSpecification(parsedUrl.toString(), {
-      errorMessage: "Het ophalen van de OpenAPI specificatie is mislukt.",
-    });
-    return {
-      source: parsedUrl.toString(),
-      contents,
-    };
+  if (hasArchiveInput(input)) {
+    return resolveArchiveInput(input);
   }
-  throw Service.rejectResponse(
-    {
-      message: "Geef een oasBody of oasUrl mee.",
-    },
-    400,
-  );
+  let parsedUrl;
+  try {
+    parsedUrl = new URL(oasUrl);
+  } catch (error) {
+    logger.error("[OasValidatorService] invalid oasUrl", { message: error.message });
+    throw Service.rejectResponse(
+      {
+        message: "De waarde van oasUrl is geen geldige URL.",
+      },
+      400,
+    );
+  }
+  const contents = await fetchSpecification(parsedUrl.toString(), {
+    errorMessage: "Het ophalen van de OpenAPI specificatie is mislukt.",
+    headers: input.headers,
+  });
+  return {
+    source: parsedUrl.toString(),
+    contents,
+  };
 };
 
 const buildInfo = (lintMessageId, diagnostic) => {
@@ -136,81 +223,573 @@ const mapDiagnosticsToMessages = (diagnostics, timestamp) =>
     };
   });
 
-const computeAdrScore = (messages) => {
-  const failedGroups = new Set();
-  messages.forEach((message) => {
-    if (String(message.severity).toLowerCase() !== "error") {
-      return;
-    }
-    const group = MEASURED_RULE_GROUPS[message.code];
-    if (group) {
-      failedGroups.add(group);
-    }
-  });
+const normalizeRuleCode = (code) => String(code || "").replace(/^nlgov:/, "");
 
-  if (MEASURED_GROUP_KEYS.length === 0) {
-    return { score: 100, failedGroups: [] };
+const normalizeIgnoreRules = (value) => {
+  if (value === undefined || value === null) {
+    return new Set();
   }
-
-  const score = Math.round((1 - failedGroups.size / MEASURED_GROUP_KEYS.length) * 100);
-  return {
-    score: Math.max(0, Math.min(100, score)),
-    failedGroups: Array.from(failedGroups).sort(),
-  };
+  if (!Array.isArray(value) || value.some((item) => typeof item !== "string")) {
+    throw Service.rejectResponse({ message: "ignoreRules moet een lijst met regelcodes zijn." }, 400);
+  }
+  return new Set(value.map((item) => normalizeRuleCode(item.trim())).filter(Boolean));
 };
 
-const buildLintResult = (diagnostics, rulesetVersion) => {
+/**
+ * Onderdrukte bevindingen tellen niet mee voor failures en score, maar blijven zichtbaar in
+ * suppressedMessages zodat duidelijk is wat er genegeerd is.
+ */
+const buildLintResult = (
+  diagnostics,
+  { rulesetVersion, lintId = randomUUID(), ignoreRules = new Set(), scoringPolicy = getEmbeddedPolicy() },
+) => {
   const timestamp = new Date().toISOString();
-  const messages = mapDiagnosticsToMessages(diagnostics, timestamp);
+  const allMessages = mapDiagnosticsToMessages(diagnostics, timestamp);
+  const messages = allMessages.filter((message) => !ignoreRules.has(normalizeRuleCode(message.code)));
+  const suppressedMessages = allMessages.filter((message) => ignoreRules.has(normalizeRuleCode(message.code)));
   const errorCount = messages.filter((message) => String(message.severity).toLowerCase() === "error").length;
-  const { score } = computeAdrScore(messages);
-  return {
-    id: randomUUID(),
+  const { score, weightedScore } = computeScore(messages, scoringPolicy);
+  const result = {
+    id: lintId,
     apiId: "",
     createdAt: timestamp,
     failures: errorCount,
     messages,
     score,
+    weightedScore,
     successes: score === 100,
     rulesetVersion,
   };
+  if (ignoreRules.size > 0) {
+    result.suppressedMessages = suppressedMessages;
+  }
+  return result;
+};
+
+/**
+ * Zelfde vorm als `spectral lint --format json`, zodat bestaande tooling de output zonder
+ * aanpassingen kan verwerken. Onderdrukte regels worden weggelaten.
+ */
+const toSpectralOutput = (diagnostics, ignoreRules = new Set()) =>
+  diagnostics
+    .filter((diagnostic) => !ignoreRules.has(normalizeRuleCode(diagnostic.code)))
+    .map((diagnostic) => {
+      const output = {
+        code: diagnostic.code,
+        path: Array.isArray(diagnostic.path) ? diagnostic.path.map(String) : [],
+        message: diagnostic.message,
+        severity: typeof diagnostic.severity === "number" ? diagnostic.severity : 2,
+        range: diagnostic.range,
+      };
+      if (diagnostic.source) {
+        output.source = diagnostic.source;
+      }
+      return output;
+    });
+
+const GITHUB_COMMANDS = ["error", "warning", "notice", "notice"];
+
+const escapeGithubData = (value) =>
+  String(value).replace(/%/g, "%25").replace(/\r/g, "%0D").replace(/\n/g, "%0A");
+
+const escapeGithubProperty = (value) => escapeGithubData(value).replace(/:/g, "%3A").replace(/,/g, "%2C");
+
+/**
+ * Workflow commands (`::error file=…,line=…::melding`) die een GitHub Action direct naar stdout kan
+ * schrijven om bevindingen als annotaties in een pull request te tonen. Regels en kolommen zijn
+ * 1-based, Spectral telt vanaf 0.
+ */
+const toGithubAnnotations = (diagnostics, { ignoreRules = new Set(), file }) => {
+  const lines = toSpectralOutput(diagnostics, ignoreRules).map((diagnostic) => {
+    const command = GITHUB_COMMANDS[diagnostic.severity] || "notice";
+    const properties = [`file=${escapeGithubProperty(file)}`];
+    const start = diagnostic.range?.start;
+    const end = diagnostic.range?.end;
+    if (typeof start?.line === "number") {
+      properties.push(`line=${start.line + 1}`, `col=${(start.character || 0) + 1}`);
+    }
+    if (typeof end?.line === "number") {
+      properties.push(`endLine=${end.line + 1}`);
+    }
+    properties.push(`title=${escapeGithubProperty(diagnostic.code)}`);
+    const location = diagnostic.path.length > 0 ? ` (${diagnostic.path.join(".")})` : "";
+    return `::${command} ${properties.join(",")}::${escapeGithubData(`${diagnostic.message}${location}`)}`;
+  });
+  return {
+    headers: { "Content-Type": "text/plain; charset=utf-8" },
+    rawBody: Buffer.from(lines.length > 0 ? `${lines.join("\n")}\n` : "", "utf8"),
+  };
+};
+
+const normalizeOutputFormat = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return DEFAULT_OUTPUT_FORMAT;
+  }
+  const format = String(value).trim().toLowerCase();
+  if (!OUTPUT_FORMATS.includes(format)) {
+    throw Service.rejectResponse(
+      { message: `Onbekend outputFormat "${value}". Kies uit: ${OUTPUT_FORMATS.join(", ")}.` },
+      400,
+    );
+  }
+  return format;
 };
 
-const normalizeRulesetVersion = (value) => {
+const matchRulesetVersion = (value) => {
   if (typeof value === "number" && Number.isFinite(value)) {
-    value = value.toString();
+    value = value.toFixed(1);
   }
   if (typeof value !== "string") {
-    return DEFAULT_RULESET_VERSION;
+    return undefined;
   }
   const trimmed = value.trim();
   if (trimmed === "2") {
     return "2.0";
   }
-  if (Object.hasOwn(RULESET_LOADERS, trimmed)) {
-    return trimmed;
+  // Een patch-versie (2.1.0) gebruikt de ruleset van de minor-versie.
+  const minor = trimmed.split(".").slice(0, 2).join(".");
+  return ADR_RULESET_VERSIONS.includes(minor) ? minor : undefined;
+};
+
+const normalizeRulesetVersion = (value) => matchRulesetVersion(value) || DEFAULT_RULESET_VERSION;
+
+/**
+ * Leest de ADR-versie die een specificatie zelf opgeeft via `x-adr-version` in de root of in `info`.
+ */
+const detectRulesetVersion = (document) => {
+  if (!document || typeof document !== "object") {
+    return undefined;
+  }
+  return matchRulesetVersion(document["x-adr-version"]) || matchRulesetVersion(document.info?.["x-adr-version"]);
+};
+
+/**
+ * Kiest de ruleset: de ADR ruleset in de gevraagde versie, of met `ruleset: "owasp"` de ingebouwde
+ * OWASP API Security ruleset. In dat laatste geval speelt targetVersion geen rol.
+ */
+const resolveRulesetVersion = (input) => {
+  const value = input?.ruleset;
+  const ruleset =
+    value === undefined || value === null || value === "" ? DEFAULT_RULESET : String(value).trim().toLowerCase();
+  if (!RULESETS.includes(ruleset)) {
+    throw Service.rejectResponse({ message: `Onbekende ruleset "${value}". Kies uit: ${RULESETS.join(", ")}.` }, 400);
+  }
+  return ruleset === "owasp" ? "owasp" : normalizeRulesetVersion(input?.targetVersion);
+};
+
+/**
+ * Zonder targetVersion bepaalt de specificatie zelf de ADR-versie (`x-adr-version`); anders geldt
+ * de standaardversie. `rulesetSource` vermeldt waar de keuze vandaan komt.
+ */
+const selectRuleset = (input, rulesetVersion, document) => {
+  if (rulesetVersion === "owasp") {
+    return { rulesetVersion, rulesetSource: "request" };
+  }
+  if (matchRulesetVersion(input?.targetVersion)) {
+    return { rulesetVersion, rulesetSource: "request" };
+  }
+  const detected = detectRulesetVersion(document);
+  if (detected) {
+    return { rulesetVersion: detected, rulesetSource: "document" };
+  }
+  return { rulesetVersion, rulesetSource: "default" };
+};
+
+const normalizeEngine = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return DEFAULT_ENGINE;
+  }
+  const engine = String(value).trim().toLowerCase();
+  if (!ENGINES.includes(engine)) {
+    throw Service.rejectResponse({ message: `Onbekende engine "${value}". Kies uit: ${ENGINES.join(", ")}.` }, 400);
+  }
+  return engine;
+};
+
+const readPositiveSeconds = (name, fallback) => {
+  const value = Number(process.env[name]);
+  return Number.isFinite(value) && value > 0 ? value : fallback;
+};
+
+/**
+ * `timeoutSeconds` per request, begrensd door `LINT_MAX_TIMEOUT_SECONDS`. Zonder waarde geldt
+ * `LINT_TIMEOUT_SECONDS` (standaard 120).
+ */
+const normalizeTimeoutSeconds = (value) => {
+  const max = readPositiveSeconds("LINT_MAX_TIMEOUT_SECONDS", DEFAULT_MAX_LINT_TIMEOUT_SECONDS);
+  if (value === undefined || value === null || value === "") {
+    return Math.min(readPositiveSeconds("LINT_TIMEOUT_SECONDS", DEFAULT_LINT_TIMEOUT_SECONDS), max);
+  }
+  const seconds = Number(value);
+  if (!Number.isFinite(seconds) || seconds <= 0 || seconds > max) {
+    throw Service.rejectResponse({ message: `timeoutSeconds moet een getal tussen 0 en ${max} zijn.` }, 400);
+  }
+  return seconds;
+};
+
+const lintTimeoutError = (timeoutSeconds) =>
+  Service.rejectResponse(
+    {
+      message: `De validatie duurde langer dan ${timeoutSeconds} seconden en is afgebroken.`,
+      detail: "Verhoog timeoutSeconds of splits de specificatie op.",
+    },
+    504,
+  );
+
+// Spectral evalueert de regels synchroon; alleen een run in een eigen thread is na de timeout echt te stoppen.
+const lintWorkers = createWorkerPool(path.join(__dirname, "lintWorker.js"), { maxIdle: DEFAULT_BATCH_CONCURRENCY });
+
+const runInLintWorker = async (task, timeoutSeconds, { onProgress, signal } = {}) => {
+  try {
+    return await lintWorkers.run(task, { timeoutMs: timeoutSeconds * 1000, onEvent: onProgress, signal });
+  } catch (error) {
+    if (error instanceof WorkerTimeoutError) {
+      throw lintTimeoutError(timeoutSeconds);
+    }
+    throw error;
   }
-  return DEFAULT_RULESET_VERSION;
 };
 
 const resolveValidationSettings = (input) => ({
-  rulesetVersion: normalizeRulesetVersion(input?.targetVersion),
+  rulesetVersion: resolveRulesetVersion(input),
+  ignoreRules: normalizeIgnoreRules(input?.ignoreRules),
+  engine: normalizeEngine(input?.engine),
+  validateExamples: input?.validateExamples === true,
+  timeoutSeconds: normalizeTimeoutSeconds(input?.timeoutSeconds),
 });
 
-const validate = async (input) => {
+/**
+ * Aparte Spectral-instantie met alleen de example-regels uit de standaard `oas` ruleset. Zo
+ * hoeft de ADR ruleset niet te veranderen en kost de controle alleen tijd als erom gevraagd wordt.
+ */
+const loadExampleSpectral = () => {
+  if (!exampleSpectralPromise) {
+    exampleSpectralPromise = (async () => {
+      const { oas } = require("@stoplight/spectral-rulesets");
+      const spectral = new Spectral({ resolver: createSpectralResolver() });
+      spectral.setRuleset({
+        extends: [[oas, "off"]],
+        rules: Object.fromEntries(EXAMPLE_RULES.map((rule) => [rule, "error"])),
+      });
+      return spectral;
+    })().catch((error) => {
+      exampleSpectralPromise = undefined;
+      logger.error(`[OasValidatorService] Unable to load example rules: ${error.message}`);
+      throw Service.rejectResponse(
+        { message: "Kan de regels voor example-validatie niet laden.", detail: error.message },
+        500,
+      );
+    });
+  }
+  return exampleSpectralPromise;
+};
+
+const diagnosticKey = (diagnostic) =>
+  `${diagnostic.code}|${Array.isArray(diagnostic.path) ? diagnostic.path.join(".") : ""}|${diagnostic.message}`;
+
+const runExampleValidation = async (contents, source, diagnostics) => {
+  const spectral = await loadExampleSpectral();
+  const exampleDiagnostics = await spectral.run(new Document(contents, Parsers.Yaml, source), {
+    ignoreUnknownFormat: false,
+  });
+  const seen = new Set(diagnostics.map(diagnosticKey));
+  return [...diagnostics, ...exampleDiagnostics.filter((diagnostic) => !seen.has(diagnosticKey(diagnostic)))];
+};
+
+const runEmbeddedEngine = async (contents, source, rulesetVersion, remoteRuleset, onProgress) => {
+  const spectral = await resolveSpectral(rulesetVersion, remoteRuleset);
+  onProgress({ phase: "linting", engine: "embedded", ruleCount: Object.keys(spectral.ruleset?.rules || {}).length });
+  const document = new Document(contents, Parsers.Yaml, source);
+  const parseDiagnostics = Array.isArray(document.diagnostics) ? document.diagnostics : [];
+  const lintDiagnostics = await spectral.run(document, { ignoreUnknownFormat: false });
+  return [...parseDiagnostics, ...lintDiagnostics];
+};
+
+const resolveCliRuleset = (rulesetVersion) => {
+  const override = process.env[`SPECTRAL_RULESET_${rulesetVersion.replace(".", "_").toUpperCase()}`];
+  if (override) {
+    return override;
+  }
+  if (rulesetVersion === "owasp") {
+    return OWASP_RULESET_PATH;
+  }
+  return require.resolve(`@developer-overheid-nl/adr-rulesets/rulesets/adr-${rulesetVersion.replace(".", "")}`);
+};
+
+// Het pakket wordt pas bij een run opgezocht: zonder de CLI start de server gewoon en geeft alleen
+// `engine: "spectral-cli"` een 503.
+const resolveSpectralBin = () => {
+  try {
+    return require.resolve(SPECTRAL_CLI_ENTRY);
+  } catch (error) {
+    logger.error(`[OasValidatorService] spectral-cli not available: ${error.message}`);
+    throw Service.rejectResponse(
+      {
+        message: "Linten met de Spectral CLI is niet beschikbaar op deze server.",
+        detail: "@stoplight/spectral-cli is niet geïnstalleerd; installeer het pakket of stel SPECTRAL_BIN in.",
+      },
+      503,
+    );
+  }
+};
+
+// De meegeleverde CLI draait zoals de Redocly CLI met de eigen Node en de preload die uitgaande verbindingen
+// toetst; een andere CLI via `SPECTRAL_BIN` wordt zonder preload gestart.
+const spectralCommand = (args) =>
+  process.env.SPECTRAL_BIN
+    ? [process.env.SPECTRAL_BIN, args]
+    : [process.execPath, [...OUTBOUND_GUARD_ARGS, resolveSpectralBin(), ...args]];
+
+/**
+ * Draait de Spectral CLI (`@stoplight/spectral-cli`, of `SPECTRAL_BIN`) in een apart proces, zoals de
+ * bundler met Redocly doet. Bedoeld om bevindingen van de ingebouwde engine te kunnen vergelijken met
+ * wat de CLI lokaal rapporteert.
+ */
+const runSpectralCli = async (inputPath, rulesetVersion, timeoutSeconds, signal) => {
+  try {
+    const args = ["lint", inputPath, "--ruleset", resolveCliRuleset(rulesetVersion), "--format", "json", "--quiet"];
+    let stdout;
+    try {
+      ({ stdout } = await execFileAsync(...spectralCommand(args), {
+        maxBuffer: 20 * 1024 * 1024,
+        timeout: timeoutSeconds * 1000,
+        signal,
+      }));
+    } catch (error) {
+      if (signal?.aborted) {
+        throw signal.reason;
+      }
+      if (error.killed) {
+        throw lintTimeoutError(timeoutSeconds);
+      }
+      // Spectral sluit af met code 1 zodra er bevindingen met severity error zijn.
+      if (error.code !== 1 || typeof error.stdout !== "string") {
+        throw error;
+      }
+      stdout = error.stdout;
+    }
+    const diagnostics = JSON.parse(stdout.trim() || "[]");
+    return Array.isArray(diagnostics) ? diagnostics : [];
+  } catch (error) {
+    if (Service.isErrorResponse(error)) {
+      throw error;
+    }
+    logger.error(`[OasValidatorService] spectral-cli failed: ${error.message}`);
+    throw Service.rejectResponse(
+      {
+        message: "Validatie met de Spectral CLI is mislukt.",
+        detail: error.stderr?.toString().trim() || error.message,
+      },
+      500,
+    );
+  }
+};
+
+/**
+ * Zet het document één keer in een tijdelijk bestand. De Spectral CLI en de worker threads lezen het
+ * daar, zodat het niet per taak als kopie naar een andere thread gaat. `remove` ruimt het op.
+ */
+const spoolContents = async (contents) => {
+  const tempFiles = await createTempFiles("oas-lint-");
+  const name = contents.trimStart().startsWith("{") ? "openapi.json" : "openapi.yaml";
+  const inputPath = path.join(tempFiles.directory, name);
+  try {
+    await fs.writeFile(inputPath, contents, { encoding: "utf8", mode: 0o600 });
+  } catch (error) {
+    await tempFiles.remove();
+    throw error;
+  }
+  return { inputPath, remove: tempFiles.remove };
+};
+
+/**
+ * `onProgress` krijgt per fase een event ({ phase, ... }) voor de streaming-variant. Spectral
+ * evalueert alle regels in één doorloop over het document, dus voortgang per regel is er niet.
+ * Het eerste event volgt pas als de input zonder ophalen in orde is. Gaat `signal` af, dan wordt
+ * de lopende Spectral-run gestopt.
+ */
+const validate = async (input, { lintId, outputFormat, annotationFile, onProgress = () => {}, signal } = {}) => {
+  const format = normalizeOutputFormat(outputFormat);
+  assertSpecificationInput(input);
+  const settings = resolveValidationSettings(input);
+  const { ignoreRules, engine, validateExamples, timeoutSeconds } = settings;
+  onProgress({ phase: "loading" });
   const { contents, source } = await resolveSpecificationInput(input);
-  const { rulesetVersion } = resolveValidationSettings(input);
+  onProgress({ phase: "parsing", source, bytes: Buffer.byteLength(contents, "utf8") });
+  let document;
+  try {
+    document = assertSafeYaml(contents);
+  } catch (error) {
+    if (error instanceof YamlLimitError) {
+      throw Service.rejectResponse({ message: error.message }, 400);
+    }
+    throw error;
+  }
+  const { rulesetVersion, rulesetSource } = selectRuleset(input, settings.rulesetVersion, document);
   logger.info(
-    `[OasValidatorService] validate using ADR ruleset ${rulesetVersion} (targetVersion=${input?.targetVersion || "default"}, source=${source})`,
+    `[OasValidatorService] validate using ruleset ${rulesetVersion} (${rulesetSource}, engine=${engine}, source=${source})`,
   );
+  if (engine === "spectral-cli") {
+    onProgress({ phase: "linting", engine });
+  }
+  // De workers halen de gepubliceerde ruleset niet zelf op; ze krijgen de versie van de hoofdthread mee.
+  const remoteRuleset =
+    engine === "embedded" && rulesetVersion === REMOTE_RULESET_VERSION ? getRemoteRuleset() : undefined;
+  const { inputPath, remove } = await spoolContents(contents);
+  let diagnostics;
+  try {
+    diagnostics =
+      engine === "spectral-cli"
+        ? await runSpectralCli(inputPath, rulesetVersion, timeoutSeconds, signal)
+        : await runInLintWorker({ task: "lint", inputPath, source, rulesetVersion, remoteRuleset }, timeoutSeconds, {
+            onProgress,
+            signal,
+          });
+    onProgress({ phase: "linted", findingCount: diagnostics.length });
+    if (validateExamples) {
+      onProgress({ phase: "validating-examples" });
+      const task = { task: "examples", inputPath, source, diagnostics };
+      diagnostics = await runInLintWorker(task, timeoutSeconds, { signal });
+    }
+  } finally {
+    await remove();
+  }
+  if (format === "spectral") {
+    return toSpectralOutput(diagnostics, ignoreRules);
+  }
+  if (format === "github") {
+    const file = annotationFile || (source === "request-body" ? "openapi.yaml" : source);
+    return toGithubAnnotations(diagnostics, { ignoreRules, file });
+  }
+  onProgress({ phase: "scoring" });
+  const scoringPolicy = await resolveScoringPolicy(rulesetVersion);
+  const lintResult = buildLintResult(diagnostics, { rulesetVersion, lintId, ignoreRules, scoringPolicy });
+  lintResult.rulesetSource = rulesetSource;
+  lintResult.rulesetOrigin = remoteRuleset ? "remote" : "embedded";
+  if (format === "markdown") {
+    return {
+      headers: { "Content-Type": "text/markdown; charset=utf-8" },
+      rawBody: Buffer.from(renderMarkdown(lintResult), "utf8"),
+      lintResult,
+    };
+  }
+  return lintResult;
+};
+
+/**
+ * Het scorebeleid in rulesets/scoring.yaml gaat over ADR-regels. Voor de OWASP ruleset telt elke
+ * regel als eigen groep mee, zodat de score het aandeel regels zonder error-bevindingen weergeeft.
+ */
+const resolveScoringPolicy = async (rulesetVersion) => {
+  if (rulesetVersion !== "owasp") {
+    return getScoringPolicy();
+  }
   const spectral = await loadSpectral(rulesetVersion);
-  const document = new Document(contents, Parsers.Yaml, source);
-  const parseDiagnostics = Array.isArray(document.diagnostics) ? document.diagnostics : [];
-  const lintDiagnostics = await spectral.run(document, { ignoreUnknownFormat: false });
-  const diagnostics = [...parseDiagnostics, ...lintDiagnostics];
-  return buildLintResult(diagnostics, rulesetVersion);
+  const codes = Object.keys(spectral.ruleset?.rules || {});
+  return parsePolicy({ groups: Object.fromEntries(codes.map((code) => [code, { rules: [code] }])) });
+};
+
+const describeRule = (name, rule, scoringPolicy) => {
+  const severityIndex = typeof rule.severity === "number" ? rule.severity : -1;
+  return {
+    code: name,
+    description: rule.description || rule.message || "",
+    severity: SEVERITY_LABELS[severityIndex] || "off",
+    documentationUrl: rule.documentationUrl || undefined,
+    enabled: rule.enabled !== false && severityIndex >= 0,
+    measured: isMeasured(name, scoringPolicy),
+  };
+};
+
+/**
+ * Alle regels uit de actieve ruleset, inclusief of ze meetellen voor de score. De volgorde
+ * volgt de ruleset zodat de portal de documentatie in dezelfde volgorde kan tonen.
+ */
+const listRules = async ({ targetVersion, ruleset } = {}) => {
+  const rulesetVersion = resolveRulesetVersion({ targetVersion, ruleset });
+  const spectral = await resolveSpectral(rulesetVersion, getRemoteRuleset());
+  const rules = spectral.ruleset?.rules || {};
+  const scoringPolicy = await resolveScoringPolicy(rulesetVersion);
+  return {
+    rulesetVersion,
+    rules: Object.entries(rules).map(([name, rule]) => describeRule(name, rule, scoringPolicy)),
+  };
+};
+
+const resolveBatchConcurrency = () => {
+  const envValue = Number(process.env.LINT_BATCH_CONCURRENCY);
+  if (Number.isInteger(envValue) && envValue > 0) {
+    return envValue;
+  }
+  return DEFAULT_BATCH_CONCURRENCY;
+};
+
+const describeBatchError = (error) => {
+  if (Service.isErrorResponse(error)) {
+    return {
+      status: typeof error.code === "number" ? error.code : 400,
+      detail: error.error?.detail || error.error?.message || "Er is een fout opgetreden.",
+    };
+  }
+  return {
+    status: typeof error?.status === "number" && error.status > 0 ? error.status : 500,
+    detail: error?.message || "Er is een fout opgetreden.",
+  };
+};
+
+const urlOrigin = (url) => {
+  try {
+    const { origin } = new URL(url);
+    return origin === "null" ? undefined : origin;
+  } catch {
+    return undefined;
+  }
+};
+
+/**
+ * Lint meerdere specificaties met een begrensde worker pool. Een mislukte specificatie laat de
+ * batch niet falen; het betreffende item krijgt een error in plaats van een lintResult.
+ * `headers` (zoals een Authorization-header voor één gateway) gaan alleen mee naar URL's met dezelfde
+ * origin als de eerste URL, of als `headerUrl` wanneer een grotere lijst in delen gelint wordt.
+ */
+const validateBatch = async (input, { headerUrl = input?.oasUrls?.[0] } = {}) => {
+  const oasUrls = Array.isArray(input?.oasUrls) ? input.oasUrls : [];
+  const headerOrigin = urlOrigin(headerUrl);
+  if (oasUrls.length === 0) {
+    throw Service.rejectResponse({ message: "Geef minimaal één oasUrl mee in oasUrls." }, 400);
+  }
+  if (oasUrls.length > MAX_BATCH_SIZE) {
+    throw Service.rejectResponse({ message: `Een batch mag maximaal ${MAX_BATCH_SIZE} specificaties bevatten.` }, 400);
+  }
+  const concurrency = resolveBatchConcurrency();
+  logger.info(`[OasValidatorService] batch validate of ${oasUrls.length} specs (concurrency=${concurrency})`);
+  return mapWithConcurrency(oasUrls, concurrency, async (oasUrl) => {
+    try {
+      const lintResult = await validate({
+        oasUrl,
+        targetVersion: input.targetVersion,
+        ruleset: input.ruleset,
+        headers: headerOrigin !== undefined && urlOrigin(oasUrl) === headerOrigin ? input.headers : undefined,
+        ignoreRules: input.ignoreRules,
+        engine: input.engine,
+        validateExamples: input.validateExamples,
+        timeoutSeconds: input.timeoutSeconds,
+      });
+      return { oasUrl, lintResult };
+    } catch (error) {
+      const { status, detail } = describeBatchError(error);
+      logger.warn(`[OasValidatorService] batch item ${oasUrl} failed: ${detail}`);
+      return { oasUrl, error: { status, detail } };
+    }
+  });
 };
 
 module.exports = {
+  MAX_BATCH_SIZE,
+  listRules,
+  runEmbeddedEngine,
+  runExampleValidation,
+  startRulesetRefresh,
   validate,
+  validateBatch,
 };
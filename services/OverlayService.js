@@ -0,0 +1,172 @@
+const Service = require("./Service");
+const { fetchSpecification } = require("./RemoteSpecificationService");
+const { resolveOasDocument, serializeOasDocument } = require("./OasInputService");
+const { stripBom } = require("../utils/encoding");
+const { JsonPathError, queryJsonPath } = require("../utils/jsonPath");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+const { YamlLimitError, parseJsonOrYaml } = require("../utils/yaml");
+
+const SUPPORTED_OVERLAY_VERSION = /^1\.\d+\.\d+$/;
+
+const isNonEmptyString = (value) => typeof value === "string" && value.trim().length > 0;
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+const resolveOverlayContents = async (input) => {
+  if (isNonEmptyString(input?.overlayBody)) {
+    return stripBom(input.overlayBody);
+  }
+  if (isNonEmptyString(input?.overlayUrl)) {
+    let parsedUrl;
+    try {
+      parsedUrl = new URL(input.overlayUrl);
+    } catch {
+      throw Service.rejectResponse({ message: "De waarde van overlayUrl is geen geldige URL." }, 400);
+    }
+    return fetchSpecification(parsedUrl.toString(), {
+      errorMessage: "Het ophalen van het Overlay-document is mislukt.",
+    });
+  }
+  throw Service.rejectResponse({ message: "Geef een overlayBody of overlayUrl mee." }, 400);
+};
+
+const parseOverlay = (contents) => {
+  let overlay;
+  try {
+    overlay = parseJsonOrYaml(contents.trim()).document;
+  } catch (error) {
+    const message = error instanceof YamlLimitError ? error.message : "Kan Overlay-document niet parseren.";
+    throw Service.rejectResponse({ message, detail: error.message }, 400);
+  }
+  if (!isObject(overlay) || !SUPPORTED_OVERLAY_VERSION.test(String(overlay.overlay ?? ""))) {
+    throw Service.rejectResponse(
+      { message: "Overlay-document moet een object zijn met overlay-versie 1.x (bijvoorbeeld overlay: 1.0.0)." },
+      400,
+    );
+  }
+  if (!Array.isArray(overlay.actions) || overlay.actions.some((action) => !isNonEmptyString(action?.target))) {
+    throw Service.rejectResponse({ message: "Overlay-document moet actions met elk een target bevatten." }, 400);
+  }
+  return overlay;
+};
+
+// Objecten worden recursief samengevoegd; andere waarden (ook arrays) vervangen de bestaande waarde.
+const mergeValue = (target, update) => {
+  if (!isObject(target) || !isObject(update)) {
+    return structuredClone(update);
+  }
+  for (const [key, value] of Object.entries(update)) {
+    target[key] = Object.hasOwn(target, key) ? mergeValue(target[key], value) : structuredClone(value);
+  }
+  return target;
+};
+
+const updateNode = (document, node, update) => {
+  if (Array.isArray(node.value)) {
+    node.value.push(structuredClone(update));
+    return document;
+  }
+  const merged = mergeValue(node.value, update);
+  if (node.parent === null) {
+    return merged;
+  }
+  node.parent[node.key] = merged;
+  return document;
+};
+
+// Array-elementen van achter naar voren, zodat de indexen van de overige nodes blijven kloppen.
+const removeNodes = (nodes) => {
+  const indexOf = (node) => (typeof node.key === "number" ? node.key : 0);
+  const ordered = [...nodes].sort((a, b) => indexOf(b) - indexOf(a));
+  for (const node of ordered) {
+    if (Array.isArray(node.parent)) {
+      node.parent.splice(node.key, 1);
+    } else {
+      delete node.parent[node.key];
+    }
+  }
+};
+
+const uniqueNodes = (nodes) => {
+  const seen = new Map();
+  for (const node of nodes) {
+    const parents = seen.get(node.parent) ?? new Map();
+    parents.set(node.key, node);
+    seen.set(node.parent, parents);
+  }
+  return [...seen.values()].flatMap((parents) => [...parents.values()]);
+};
+
+/**
+ * Past de acties van een Overlay 1.0 document in volgorde toe. `update` wordt recursief in een
+ * geselecteerd object samengevoegd of aan een geselecteerde array toegevoegd; `remove: true`
+ * verwijdert de geselecteerde nodes. Een actie waarvan de target niets selecteert is geen fout,
+ * maar wordt geteld in `unmatched`.
+ */
+const applyOverlay = (spec, overlay) => {
+  let document = spec;
+  const unmatched = [];
+  overlay.actions.forEach((action, index) => {
+    let nodes;
+    try {
+      nodes = uniqueNodes(queryJsonPath(document, action.target));
+    } catch (error) {
+      if (error instanceof JsonPathError) {
+        throw Service.rejectResponse(
+          { message: `Ongeldige target in actie ${index + 1}: ${action.target}`, detail: error.message },
+          400,
+        );
+      }
+      throw error;
+    }
+    if (nodes.length === 0) {
+      unmatched.push({ index, target: action.target });
+      return;
+    }
+    if (action.remove === true) {
+      if (nodes.some((node) => node.parent === null)) {
+        throw Service.rejectResponse({ message: `Actie ${index + 1} kan het hele document niet verwijderen.` }, 400);
+      }
+      removeNodes(nodes);
+    } else if (action.update !== undefined) {
+      for (const node of nodes) {
+        document = updateNode(document, node, action.update);
+      }
+    }
+  });
+  return { spec: document, unmatched };
+};
+
+/**
+ * Past een Overlay-document (overlayUrl/overlayBody) toe op de specificatie (oasUrl/oasBody) en geeft
+ * de aangepaste specificatie terug in het formaat van de input.
+ */
+const apply = async (input, { responseFormat } = {}) => {
+  const { spec, format, source } = await resolveOasDocument(input);
+  const overlay = parseOverlay(await resolveOverlayContents(input));
+  const { spec: patched, unmatched } = applyOverlay(spec, overlay);
+  if (!isObject(patched)) {
+    throw Service.rejectResponse(
+      { message: "Na het toepassen van de overlay is de specificatie geen object meer." },
+      400,
+    );
+  }
+  const title = overlay.info?.title;
+  const version = overlay.info?.version;
+  stampDocument(
+    patched,
+    buildProvenance({
+      tool: "oas-overlay",
+      source,
+      details: { overlay: [title, version].filter(isNonEmptyString).join(" ") },
+    }),
+  );
+  const result = serializeOasDocument(patched, responseFormat ?? format, "openapi-overlay");
+  result.headers["X-Overlay-Unmatched-Actions"] = String(unmatched.length);
+  return result;
+};
+
+module.exports = {
+  apply,
+  applyOverlay,
+};
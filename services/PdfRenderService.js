@@ -0,0 +1,92 @@
+const fs = require("node:fs/promises");
+const os = require("node:os");
+const path = require("node:path");
+const { pathToFileURL } = require("node:url");
+const { execFile } = require("node:child_process");
+const { promisify } = require("node:util");
+const Service = require("./Service");
+const logger = require("../logger");
+
+const TEMP_PREFIX = "pdf-render-";
+const DEFAULT_TIMEOUT_SECONDS = 60;
+const execFileAsync = promisify(execFile);
+
+// Chromium haalt niets van het netwerk: alle verkeer gaat naar een proxy die niet bestaat, ook naar
+// loopback (`<-loopback>` schrapt de impliciete uitzondering daarvoor).
+const NETWORK_ARGS = [
+  "--proxy-server=127.0.0.1:0",
+  "--proxy-bypass-list=<-loopback>",
+  "--disable-background-networking",
+];
+
+const resolveTimeoutSeconds = () => {
+  const value = Number.parseInt(process.env.PDF_TIMEOUT_SECONDS ?? "", 10);
+  return Number.isFinite(value) && value > 0 ? value : DEFAULT_TIMEOUT_SECONDS;
+};
+
+/**
+ * Drukt een HTML-document met headless Chromium (`CHROMIUM_BIN`, standaard `chromium` op het PATH)
+ * af als PDF. De HTML moet zelfstandig zijn: er wordt vanaf schijf geladen en niet op scripts of
+ * externe bronnen gewacht, en netwerkverkeer loopt dood op een onbereikbare proxy. Chromium draait in
+ * zijn eigen sandbox; `CHROMIUM_NO_SANDBOX=true` zet die uit en is alleen bedoeld voor lokale ontwikkeling.
+ */
+const renderPdf = async (html) => {
+  const bin = process.env.CHROMIUM_BIN || "chromium";
+  const timeoutSeconds = resolveTimeoutSeconds();
+  let tmpDir;
+  try {
+    tmpDir = await fs.mkdtemp(path.join(os.tmpdir(), TEMP_PREFIX));
+    const inputPath = path.join(tmpDir, "document.html");
+    const outputPath = path.join(tmpDir, "document.pdf");
+    await fs.writeFile(inputPath, html, "utf8");
+    const args = [
+      "--headless",
+      ...(process.env.CHROMIUM_NO_SANDBOX === "true" ? ["--no-sandbox"] : []),
+      ...NETWORK_ARGS,
+      "--disable-gpu",
+      "--disable-dev-shm-usage",
+      "--no-pdf-header-footer",
+      `--user-data-dir=${path.join(tmpDir, "profile")}`,
+      `--print-to-pdf=${outputPath}`,
+      pathToFileURL(inputPath).href,
+    ];
+    await execFileAsync(bin, args, { maxBuffer: 20 * 1024 * 1024, timeout: timeoutSeconds * 1000 });
+    return await fs.readFile(outputPath);
+  } catch (error) {
+    logger.error(`[PdfRenderService] chromium failed: ${error?.message}`);
+    if (error?.code === "ENOENT" && error?.path === bin) {
+      throw Service.rejectResponse(
+        {
+          message: "PDF-rendering is niet beschikbaar op deze server.",
+          detail: `Chromium (${bin}) is niet gevonden; stel CHROMIUM_BIN in.`,
+        },
+        503,
+      );
+    }
+    if (error?.killed) {
+      throw Service.rejectResponse(
+        { message: `Het renderen van de PDF duurde langer dan ${timeoutSeconds} seconden en is afgebroken.` },
+        504,
+      );
+    }
+    throw Service.rejectResponse(
+      {
+        message: "Het renderen van de PDF is mislukt.",
+        detail: `${error?.stderr || ""}`.trim() || error?.message,
+      },
+      500,
+    );
+  } finally {
+    if (tmpDir) {
+      try {
+        await fs.rm(tmpDir, { recursive: true, force: true });
+      } catch {
+        // ignore cleanup errors
+      }
+    }
+  }
+};
+
+module.exports = {
+  renderPdf,
+};
@@ -0,0 +1,277 @@
+const Service = require("./Service");
+const { resolveOasDocument, serializeOasDocument } = require("./OasInputService");
+const { decodePointerSegment, encodePointerSegment, formatPointer } = require("../utils/jsonPointer");
+const { resolveRef } = require("../utils/openapi");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+const { isSwagger2 } = require("../utils/swagger");
+
+const OUTPUT_FORMATS = ["spec", "report"];
+const COMPOSITIONS = ["oneOf", "anyOf"];
+const SCHEMA_REF_PREFIX = "#/components/schemas/";
+// Waarden onder deze sleutels zijn data; een `enum` of `oneOf` daarin is geen schema.
+const DATA_KEYS = new Set(["example", "examples", "default", "const", "enum"]);
+// Onder deze sleutels staat een map van namen naar schema's; een property die `const` heet is geen const.
+const SCHEMA_MAPS = new Set(["properties", "patternProperties", "$defs", "definitions", "dependentSchemas", "schemas"]);
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+const schemaRef = (name) => `${SCHEMA_REF_PREFIX}${encodePointerSegment(name)}`;
+
+const schemaName = (ref) =>
+  typeof ref === "string" && ref.startsWith(SCHEMA_REF_PREFIX) && !ref.slice(SCHEMA_REF_PREFIX.length).includes("/")
+    ? decodePointerSegment(ref.slice(SCHEMA_REF_PREFIX.length))
+    : undefined;
+
+// Bezoekt elk object in het document met zijn pad, behalve data (voorbeelden, defaults), extensies en
+// de maps van namen naar schema's zelf (de schema's daarin wel).
+const walk = (node, at, visit) => {
+  if (Array.isArray(node)) {
+    node.forEach((item, index) => walk(item, [...at, index], visit));
+    return;
+  }
+  if (!isObject(node)) {
+    return;
+  }
+  if (!SCHEMA_MAPS.has(at[at.length - 1])) {
+    visit(node, at);
+  }
+  for (const [key, value] of Object.entries(node)) {
+    if (!DATA_KEYS.has(key) && !key.startsWith("x-")) {
+      walk(value, [...at, key], visit);
+    }
+  }
+};
+
+// Vervangt een sleutel op dezelfde plek, zodat de volgorde van het schema blijft staan.
+const replaceKey = (node, from, to, value) => {
+  const entries = Object.entries(node);
+  for (const [key] of entries) {
+    delete node[key];
+  }
+  for (const [key, current] of entries) {
+    node[key === from ? to : key] = key === from ? value : current;
+  }
+};
+
+// De properties van een schema, inclusief die uit de leden van een allOf.
+const collectProperties = (spec, schema, seen = new Set()) => {
+  const resolved = resolveRef(spec, schema);
+  if (!isObject(resolved) || seen.has(resolved)) {
+    return {};
+  }
+  seen.add(resolved);
+  const members = Array.isArray(resolved.allOf) ? resolved.allOf : [];
+  return Object.assign(
+    {},
+    ...members.map((member) => collectProperties(spec, member, seen)),
+    isObject(resolved.properties) ? resolved.properties : {},
+  );
+};
+
+// Een property met `const` of één enum-waarde legt de discriminatorwaarde vast; anders geldt de schemanaam.
+const discriminatorValue = (spec, name, propertyName) => {
+  const property = resolveRef(spec, collectProperties(spec, { $ref: schemaRef(name) })[propertyName]);
+  if (isObject(property) && typeof property.const === "string") {
+    return property.const;
+  }
+  if (isObject(property) && Array.isArray(property.enum) && property.enum.length === 1) {
+    return String(property.enum[0]);
+  }
+  return name;
+};
+
+// Schema's die met `allOf` van het basisschema erven, voor een discriminator zonder oneOf of anyOf.
+const findSubtypes = (spec, baseName) =>
+  Object.entries(isObject(spec.components?.schemas) ? spec.components.schemas : {})
+    .filter(
+      ([name, schema]) =>
+        name !== baseName &&
+        Array.isArray(schema?.allOf) &&
+        schema.allOf.some((member) => schemaName(member?.$ref) === baseName),
+    )
+    .map(([name]) => name);
+
+const isObjectSchema = (spec, schema) => {
+  const resolved = resolveRef(spec, schema);
+  return isObject(resolved) && (resolved.type === "object" || isObject(resolved.properties));
+};
+
+const isNullSchema = (schema) => isObject(schema) && schema.type === "null" && Object.keys(schema).length === 1;
+
+/**
+ * Vult `discriminator.mapping` aan: elk lid van de oneOf/anyOf (of bij erven met allOf elk subtype) zonder
+ * mapping krijgt er een, met als sleutel de `const` of enige enum-waarde van de discriminator-property,
+ * en anders de schemanaam zoals de specificatie impliciet aanneemt. Bestaande mappings blijven staan.
+ */
+const completeMapping = (spec, node, at, change, warn) => {
+  const { propertyName } = node.discriminator;
+  const members = COMPOSITIONS.map((keyword) => node[keyword]).find(Array.isArray);
+  const baseName = at.length === 3 && at[0] === "components" && at[1] === "schemas" ? at[2] : undefined;
+  let targets = [];
+  if (members) {
+    members.forEach((member, index) => {
+      const name = schemaName(member?.$ref);
+      if (name === undefined) {
+        warn(
+          "inline-discriminator-member",
+          [...at, members === node.oneOf ? "oneOf" : "anyOf", index],
+          "Een lid zonder $ref naar components.schemas kan geen discriminator-mapping krijgen.",
+        );
+      } else {
+        targets.push(name);
+      }
+    });
+  } else if (baseName !== undefined) {
+    targets = findSubtypes(spec, baseName);
+  }
+  const mapping = isObject(node.discriminator.mapping) ? node.discriminator.mapping : {};
+  const mapped = new Set(Object.values(mapping).map((value) => schemaName(value) ?? value));
+  const added = {};
+  for (const name of targets) {
+    if (!Object.hasOwn(collectProperties(spec, { $ref: schemaRef(name) }), propertyName)) {
+      warn(
+        "missing-discriminator-property",
+        ["components", "schemas", name],
+        `${name} heeft geen property ${propertyName}, terwijl de discriminator daarop kiest.`,
+      );
+    }
+    if (mapped.has(name)) {
+      continue;
+    }
+    const key = discriminatorValue(spec, name, propertyName);
+    if (Object.hasOwn(mapping, key) || Object.hasOwn(added, key)) {
+      warn(
+        "mapping-conflict",
+        [...at, "discriminator", "mapping"],
+        `De waarde ${key} wijst al naar een ander schema; ${name} krijgt geen mapping.`,
+      );
+      continue;
+    }
+    added[key] = schemaRef(name);
+    change("discriminator-mapping", [...at, "discriminator", "mapping", key], `${key} wijst naar ${name}.`);
+  }
+  if (Object.keys(added).length > 0) {
+    node.discriminator.mapping = { ...mapping, ...added };
+  }
+};
+
+// Patronen waar codegenerators en validators vaak op vastlopen; ze worden gemeld, niet aangepast.
+const flagCompositions = (spec, node, at, warn) => {
+  for (const keyword of COMPOSITIONS) {
+    const members = node[keyword];
+    if (!Array.isArray(members)) {
+      continue;
+    }
+    const where = [...at, keyword];
+    if (members.length === 1) {
+      warn("single-member", where, `Een ${keyword} met één lid; gebruik het schema zelf of allOf.`);
+    }
+    if (isObject(node.properties)) {
+      warn(
+        "composition-with-properties",
+        where,
+        `${keyword} naast properties in hetzelfde schema; veel generators negeren een van beide.`,
+      );
+    }
+    const allObjects = members.length > 1 && members.every((member) => isObjectSchema(spec, member));
+    if (allObjects && !isObject(node.discriminator)) {
+      warn(
+        "object-variants-without-discriminator",
+        where,
+        `${keyword} met alleen objecten maar zonder discriminator; tooling moet raden welke variant het is.`,
+      );
+    }
+    members.forEach((member, index) => {
+      const resolved = resolveRef(spec, member);
+      if (isObject(resolved) && COMPOSITIONS.some((nested) => Array.isArray(resolved[nested]))) {
+        warn("nested-composition", [...where, index], `Geneste oneOf/anyOf in een ${keyword}.`);
+      }
+      if (isNullSchema(member)) {
+        warn(
+          "null-variant",
+          [...where, index],
+          `{ type: "null" } als lid van ${keyword}; een type-array met "null" wordt beter ondersteund.`,
+        );
+      }
+    });
+  }
+};
+
+/**
+ * Normaliseert polymorfie in alle schema's van het document: ontbrekende `discriminator.mapping` wordt
+ * aangevuld, een enum met één waarde wordt `const` in OpenAPI 3.1 en `const` wordt weer een enum in 3.0
+ * (dat `const` niet kent). oneOf/anyOf-constructies waar tooling vaak op breekt, worden als waarschuwing
+ * gemeld. Pointers wijzen naar de plek van de wijziging of het patroon.
+ */
+const normalizePolymorphism = (spec) => {
+  const is31 = String(spec.openapi ?? "").startsWith("3.1");
+  const changes = [];
+  const warnings = [];
+  const change = (type, at, message) => changes.push({ type, path: formatPointer(at), message });
+  const warn = (type, at, message) => warnings.push({ type, path: formatPointer(at), message });
+  walk(spec, [], (node, at) => {
+    if (is31 && Array.isArray(node.enum) && node.enum.length === 1 && !Object.hasOwn(node, "const")) {
+      change("enum-to-const", [...at, "const"], `enum met één waarde wordt const ${JSON.stringify(node.enum[0])}.`);
+      replaceKey(node, "enum", "const", node.enum[0]);
+    } else if (!is31 && Object.hasOwn(node, "const") && !Object.hasOwn(node, "enum")) {
+      change(
+        "const-to-enum",
+        [...at, "enum"],
+        `const wordt enum [${JSON.stringify(node.const)}]; OpenAPI 3.0 kent geen const.`,
+      );
+      replaceKey(node, "const", "enum", [node.const]);
+    }
+    if (isObject(node.discriminator) && typeof node.discriminator.propertyName === "string") {
+      completeMapping(spec, node, at, change, warn);
+    }
+    flagCompositions(spec, node, at, warn);
+  });
+  return { spec, changes, warnings };
+};
+
+const resolveOutputFormat = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return "spec";
+  }
+  const normalized = String(value).toLowerCase();
+  if (!OUTPUT_FORMATS.includes(normalized)) {
+    throw Service.rejectResponse(
+      { message: `Onbekend outputFormat "${value}". Kies uit: ${OUTPUT_FORMATS.join(", ")}.` },
+      400,
+    );
+  }
+  return normalized;
+};
+
+/**
+ * Geeft de specificatie met genormaliseerde polymorfie terug, met het aantal wijzigingen en waarschuwingen
+ * in headers, of met `outputFormat=report` het rapport zelf.
+ */
+const normalize = async (input, { outputFormat, responseFormat } = {}) => {
+  const format = resolveOutputFormat(outputFormat);
+  const resolved = await resolveOasDocument(input);
+  if (isSwagger2(resolved.spec)) {
+    throw Service.rejectResponse(
+      { message: "Swagger 2.0 kent geen oneOf en anyOf. Zet de specificatie eerst om met POST /v1/oas/convert." },
+      400,
+    );
+  }
+  const { spec, changes, warnings } = normalizePolymorphism(resolved.spec);
+  if (format === "report") {
+    const body = { changes, warnings };
+    return {
+      headers: { "Content-Type": "application/json" },
+      rawBody: Buffer.from(JSON.stringify(body, null, 2), "utf8"),
+    };
+  }
+  stampDocument(spec, buildProvenance({ tool: "oas-polymorphism", source: resolved.source }));
+  const result = serializeOasDocument(spec, responseFormat ?? resolved.format, "openapi-polymorphism");
+  result.headers["X-Schema-Changes"] = String(changes.length);
+  result.headers["X-Schema-Warnings"] = String(warnings.length);
+  return result;
+};
+
+module.exports = {
+  normalize,
+  normalizePolymorphism,
+};
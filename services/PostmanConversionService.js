@@ -1,14 +1,75 @@
+const { randomUUID } = require("node:crypto");
 const Service = require("./Service");
-const { resolveOasInput } = require("./OasInputService");
+const { parseOasDocument, resolveOasInput } = require("./OasInputService");
 const openapiToPostman = require("openapi-to-postmanv2");
-const { sanitizeFileName } = require("../utils/fileName");
+const { sanitizeFileName, uniqueFileName } = require("../utils/fileName");
+const {
+  buildJsonRequestSample,
+  collectOperations,
+  expandServerUrl,
+  resolveClientCredentials,
+  resolveRef,
+  resolveTokenUrl,
+} = require("../utils/openapi");
+const { PROVENANCE_EXTENSION, buildProvenance, describeProvenance } = require("../utils/provenance");
+const { createTempZipStream } = require("../utils/tempFiles");
 
 const EMPTY_BODY_ERROR = "Body ontbreekt of ongeldig: gebruik oasUrl of oasBody";
 const DEFAULT_COLLECTION_NAME = "postman-collection";
+const DEFAULT_BASE_URL = "http://localhost";
+const NEWMAN_VERSION = "^6.2.1";
+const TEMP_PREFIX = "postman-export-";
+const NEWMAN_JUNIT_REPORT = "results/junit.xml";
+const EXECUTABLE_MODE = 0o755;
 
-const convertToPostman = (data) =>
+const FOLDER_STRATEGIES = { paths: "Paths", tags: "Tags" };
+const PARAMETER_RESOLUTIONS = { schema: "Schema", example: "Example" };
+const COLLECTION_VERSIONS = { "2.0": "2.0", "2.1": "2.1" };
+const COLLECTION_SCHEMAS = {
+  "2.0": "https://schema.getpostman.com/json/collection/v2.0.0/collection.json",
+  "2.1": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json",
+};
+
+const resolveOption = (value, allowed, field) => {
+  if (value === undefined || value === null || value === "") {
+    return undefined;
+  }
+  const option = allowed[String(value).toLowerCase()];
+  if (!option) {
+    throw Service.rejectResponse(
+      { message: `Ongeldige waarde voor ${field}: "${value}". Kies uit: ${Object.keys(allowed).join(", ")}.` },
+      400,
+    );
+  }
+  return option;
+};
+
+/**
+ * Vertaalt de velden van het verzoek naar opties van openapi-to-postmanv2. `folderStrategy` bepaalt of
+ * requests per pad of per tag in mappen komen; `requestParametersResolution` of parameters en bodies
+ * uit het schema of uit de examples gevuld worden (in de library heet die optie inmiddels
+ * `parametersResolution`). Niet opgegeven opties houden de standaard van de library.
+ */
+const resolveConversionOptions = (input) => {
+  const options = {};
+  const folderStrategy = resolveOption(input?.folderStrategy, FOLDER_STRATEGIES, "folderStrategy");
+  if (folderStrategy) {
+    options.folderStrategy = folderStrategy;
+  }
+  const parametersResolution = resolveOption(
+    input?.requestParametersResolution,
+    PARAMETER_RESOLUTIONS,
+    "requestParametersResolution",
+  );
+  if (parametersResolution) {
+    options.parametersResolution = parametersResolution;
+  }
+  return options;
+};
+
+const convertToPostman = (data, options = {}) =>
   new Promise((resolve, reject) => {
-    openapiToPostman.convert({ type: "string", data }, {}, (error, result) => {
+    openapiToPostman.convert({ type: "string", data }, options, (error, result) => {
       if (error) {
         reject(error);
         return;
@@ -23,7 +84,257 @@ const convertToPostman = (data) =>
     });
   });
 
+const normalizeSegments = (segments) =>
+  segments.filter((segment) => segment !== "").map((segment) => segment.replace(/^\{(.+)\}$/, ":$1"));
+
+const requestPathSegments = (url) => {
+  if (Array.isArray(url?.path)) {
+    return normalizeSegments(url.path.map((segment) => (typeof segment === "string" ? segment : segment?.value || "")));
+  }
+  const raw = typeof url === "string" ? url : url?.raw || "";
+  return normalizeSegments(raw.replace(/^\{\{baseUrl\}\}/, "").split("?")[0].split("/"));
+};
+
+// Het pad in de collectie kan een basispad uit de server-URL bevatten; vergelijk daarom vanaf het eind.
+const endsWithSegments = (segments, suffix) => {
+  const offset = segments.length - suffix.length;
+  return offset >= 0 && suffix.every((segment, index) => segments[offset + index] === segment);
+};
+
+const hasBody = (body) => Boolean(body && typeof body.raw === "string" && body.raw.trim() !== "");
+
+/**
+ * Vult lege JSON-bodies in de collectie met een uit het schema opgebouwd voorbeeld. openapi-to-postmanv2
+ * laat de body leeg als het document (of de gekozen `requestParametersResolution`) geen voorbeeld
+ * oplevert; requests worden via methode en pad aan de operaties gekoppeld. Geeft het aantal
+ * aangevulde requests terug.
+ */
+const fillEmptyBodies = (collection, spec) => {
+  const samples = collectOperations(spec)
+    .map(({ path, method, requestBody }) => ({
+      method: method.toUpperCase(),
+      segments: normalizeSegments(path.split("/")),
+      sample: buildJsonRequestSample(spec, requestBody),
+    }))
+    .filter(({ sample }) => sample !== undefined)
+    // Langste paden eerst, zodat /dieren/:id niet als /:id gematcht wordt.
+    .sort((left, right) => right.segments.length - left.segments.length);
+  let filled = 0;
+  const visit = (items) => {
+    for (const item of items || []) {
+      if (Array.isArray(item.item)) {
+        visit(item.item);
+        continue;
+      }
+      const request = item.request;
+      if (!request || hasBody(request.body)) {
+        continue;
+      }
+      const segments = requestPathSegments(request.url);
+      const match = samples.find(
+        (candidate) =>
+          candidate.method === String(request.method).toUpperCase() && endsWithSegments(segments, candidate.segments),
+      );
+      if (match) {
+        request.body = {
+          mode: "raw",
+          raw: JSON.stringify(match.sample, null, 2),
+          options: { raw: { language: "json" } },
+        };
+        filled += 1;
+      }
+    }
+  };
+  visit(collection.item);
+  return filled;
+};
+
+const OAUTH2_CLIENT_CREDENTIALS_AUTH = {
+  type: "oauth2",
+  oauth2: [
+    ["grant_type", "client_credentials"],
+    ["accessTokenUrl", "{{tokenUrl}}"],
+    ["clientId", "{{clientId}}"],
+    ["clientSecret", "{{clientSecret}}"],
+    ["scope", "{{scope}}"],
+    ["client_authentication", "header"],
+    ["addTokenTo", "header"],
+  ].map(([key, value]) => ({ key, value, type: "string" })),
+};
+
+/**
+ * Zet bij een OAuth2 client credentials flow de auth van de collectie op die flow, met `tokenUrl`,
+ * `scope`, `clientId` en `clientSecret` als variabelen uit de environment. De lege OAuth2-auth die
+ * openapi-to-postmanv2 op mappen en requests zet wordt verwijderd, zodat die de collectie-auth erven.
+ */
+const configureClientCredentialsAuth = (collection, spec) => {
+  if (!resolveClientCredentials(spec)) {
+    return false;
+  }
+  const visit = (items) => {
+    for (const item of items || []) {
+      if (item.auth?.type === "oauth2") {
+        delete item.auth;
+      }
+      if (item.request?.auth?.type === "oauth2") {
+        delete item.request.auth;
+      }
+      visit(item.item);
+    }
+  };
+  visit(collection.item);
+  collection.auth = structuredClone(OAUTH2_CLIENT_CREDENTIALS_AUTH);
+  return true;
+};
+
+// v2.1 beschrijft auth-parameters als lijst van { key, value }; v2.0 als object per auth-type.
+const toV20Auth = (auth) => {
+  if (!auth || !Array.isArray(auth[auth.type])) {
+    return auth;
+  }
+  return {
+    ...auth,
+    [auth.type]: Object.fromEntries(auth[auth.type].map(({ key, value }) => [key, value])),
+  };
+};
+
+/**
+ * Zet een v2.1 collectie om naar Postman Collection v2.0, voor gateways en tools die v2.0 nog niet
+ * voorbij zijn. Het verschil zit in de schema-URL en de notatie van auth op collectie, mappen en
+ * requests.
+ */
+const toCollectionV20 = (collection) => {
+  const visit = (items) => {
+    for (const item of items || []) {
+      if (item.auth) {
+        item.auth = toV20Auth(item.auth);
+      }
+      if (item.request?.auth) {
+        item.request.auth = toV20Auth(item.request.auth);
+      }
+      visit(item.item);
+    }
+  };
+  visit(collection.item);
+  if (collection.auth) {
+    collection.auth = toV20Auth(collection.auth);
+  }
+  collection.info = { ...collection.info, schema: COLLECTION_SCHEMAS["2.0"] };
+  return collection;
+};
+
+const variable = (key, value = "", type = "default") => ({ key, value, type, enabled: true });
+
+/**
+ * Variabelen voor de security schemes, met de namen die openapi-to-postmanv2 in de collectie
+ * gebruikt (`{{apiKey}}`, `{{bearerToken}}`, `{{basicAuthUsername}}`/`{{basicAuthPassword}}`). Voor
+ * OAuth2 komen er `tokenUrl` (uit de flows), `clientId` en `clientSecret` bij, en bij een client
+ * credentials flow ook `scope`.
+ */
+const buildSecurityVariables = (spec) => {
+  const variables = new Map();
+  const clientCredentials = resolveClientCredentials(spec);
+  for (const candidate of Object.values(spec.components?.securitySchemes || {})) {
+    const scheme = resolveRef(spec, candidate);
+    const httpScheme = String(scheme?.scheme).toLowerCase();
+    if (scheme?.type === "apiKey") {
+      variables.set("apiKey", variable("apiKey", "", "secret"));
+    }
+    if (scheme?.type === "http" && httpScheme === "bearer") {
+      variables.set("bearerToken", variable("bearerToken", "", "secret"));
+    }
+    if (scheme?.type === "http" && httpScheme === "basic") {
+      variables.set("basicAuthUsername", variable("basicAuthUsername"));
+      variables.set("basicAuthPassword", variable("basicAuthPassword", "", "secret"));
+    }
+    if (scheme?.type === "oauth2" || scheme?.type === "openIdConnect") {
+      variables.set("tokenUrl", variable("tokenUrl", clientCredentials?.tokenUrl || resolveTokenUrl(spec)));
+      if (clientCredentials) {
+        variables.set("scope", variable("scope", clientCredentials.scopes.join(" ")));
+      }
+      variables.set("clientId", variable("clientId"));
+      variables.set("clientSecret", variable("clientSecret", "", "secret"));
+    }
+  }
+  return [...variables.values()];
+};
+
+/**
+ * Een Postman environment per server: `baseUrl` (met ingevulde servervariabelen) overschrijft de
+ * collectievariabele, aangevuld met de variabelen voor authenticatie.
+ */
+const buildEnvironments = (spec, collectionName) => {
+  const servers = Array.isArray(spec.servers) && spec.servers.length > 0 ? spec.servers : [{}];
+  const securityVariables = buildSecurityVariables(spec);
+  const usedNames = new Set();
+  return servers.map((server, index) => {
+    const label = server.description || (servers.length > 1 ? `server ${index + 1}` : "");
+    const name = label ? `${collectionName} - ${label}` : collectionName;
+    return {
+      fileName: uniqueFileName(usedNames, sanitizeFileName(label, { fallback: "environment" })),
+      environment: {
+        id: randomUUID(),
+        name,
+        values: [variable("baseUrl", expandServerUrl(server) || DEFAULT_BASE_URL), ...securityVariables],
+        _postman_variable_scope: "environment",
+      },
+    };
+  });
+};
+
+/**
+ * Bestanden om de collectie direct met Newman te draaien: een `package.json` met Newman als
+ * devDependency en een `test`-script (bij meerdere environments ook `test:<environment>`), en
+ * `run-newman.sh` dat zonder installatie via npx draait. Beide schrijven naast de CLI-uitvoer een
+ * JUnit-rapport voor CI.
+ */
+const buildNewmanFiles = (filenameBase, environmentFileNames, { source } = {}) => {
+  const collectionFile = `${filenameBase}.postman_collection.json`;
+  const environmentFile = (fileName) => `${fileName}.postman_environment.json`;
+  const reporters = `--reporters cli,junit --reporter-junit-export ${NEWMAN_JUNIT_REPORT}`;
+  const runArgs = (fileName) => `run ${collectionFile} -e ${environmentFile(fileName)} ${reporters}`;
+  const [defaultEnvironment] = environmentFileNames;
+  const scripts = { test: `newman ${runArgs(defaultEnvironment)}` };
+  if (environmentFileNames.length > 1) {
+    for (const fileName of environmentFileNames) {
+      scripts[`test:${fileName}`] = `newman ${runArgs(fileName)}`;
+    }
+  }
+  const packageJson = {
+    name: `${filenameBase}-newman`,
+    private: true,
+    description: describeProvenance(buildProvenance({ tool: "oas-postman", source })),
+    scripts,
+    devDependencies: { newman: NEWMAN_VERSION },
+  };
+  const script = [
+    "#!/usr/bin/env bash",
+    `# Gebruik: ./run-newman.sh [environment], met environment een van: ${environmentFileNames.join(", ")}`,
+    "set -euo pipefail",
+    'cd "$(dirname "$0")"',
+    "",
+    `ENVIRONMENT="\${1:-${defaultEnvironment}}"`,
+    `npx --yes "newman@${NEWMAN_VERSION}" run ${collectionFile} \\`,
+    '  -e "${ENVIRONMENT}.postman_environment.json" \\',
+    `  ${reporters}`,
+    "",
+  ].join("\n");
+  return [
+    { name: "package.json", data: `${JSON.stringify(packageJson, null, 2)}\n` },
+    { name: "run-newman.sh", data: script, mode: EXECUTABLE_MODE },
+  ];
+};
+
+/**
+ * Zet een OpenAPI document om naar een Postman collectie (standaard v2.1, met `collectionVersion`
+ * ook v2.0) plus een environment per server, samen in een ZIP; met `newman` komen daar een
+ * `package.json` en `run-newman.sh` bij. openapi-to-postmanv2 draait als library in het proces; er
+ * is geen npx of externe CLI nodig, dus ook geen koude installatie.
+ */
 const convert = async (input) => {
+  const options = resolveConversionOptions(input);
+  const collectionVersion =
+    resolveOption(input?.collectionVersion, COLLECTION_VERSIONS, "collectionVersion") || "2.1";
   let resolved;
   try {
     resolved = await resolveOasInput(input);
@@ -43,10 +354,11 @@ const convert = async (input) => {
   if (!trimmed) {
     throw Service.rejectResponse({ message: EMPTY_BODY_ERROR }, 400);
   }
+  const { spec } = parseOasDocument(trimmed);
 
   let conversionResult;
   try {
-    conversionResult = await convertToPostman(trimmed);
+    conversionResult = await convertToPostman(trimmed, options);
   } catch (error) {
     throw Service.rejectResponse(
       {
@@ -69,22 +381,73 @@ const convert = async (input) => {
   }
 
   const collection = collectionOutput.data;
+  if (input.synthesizeExamples !== false) {
+    fillEmptyBodies(collection, spec);
+  }
+  configureClientCredentialsAuth(collection, spec);
+  if (collectionVersion === "2.0") {
+    toCollectionV20(collection);
+  }
+  // Postman bewaart onbekende velden in info, dus de provenance staat daar in plaats van op het hoogste niveau.
+  collection.info = {
+    ...collection.info,
+    [PROVENANCE_EXTENSION]: buildProvenance({ tool: "oas-postman", source: resolved.source }),
+  };
   const collectionName = collection?.info?.name || DEFAULT_COLLECTION_NAME;
   const filenameBase = sanitizeFileName(collectionName, {
     fallback: DEFAULT_COLLECTION_NAME,
     lowercase: true,
   });
-  const json = JSON.stringify(collection, null, 2);
+  const environments = buildEnvironments(spec, collectionName);
+  // Een generator: elk bestand wordt pas geserialiseerd als het naar schijf gaat, zodat er steeds maar
+  // één (mogelijk grote) JSON-tekst in het geheugen staat.
+  function* files() {
+    yield {
+      name: `${filenameBase}/${filenameBase}.postman_collection.json`,
+      data: JSON.stringify(collection, null, 2),
+    };
+    for (const { fileName, environment } of environments) {
+      yield {
+        name: `${filenameBase}/${fileName}.postman_environment.json`,
+        data: JSON.stringify(environment, null, 2),
+      };
+    }
+    if (input.newman === true) {
+      const environmentFileNames = environments.map(({ fileName }) => fileName);
+      for (const file of buildNewmanFiles(filenameBase, environmentFileNames, { source: resolved.source })) {
+        yield { ...file, name: `${filenameBase}/${file.name}` };
+      }
+    }
+  }
+
+  let rawBody;
+  try {
+    rawBody = await createTempZipStream(TEMP_PREFIX, files());
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Het samenstellen van de Postman-export is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
 
   return {
     headers: {
-      "Content-Type": "application/json",
-      "Content-Disposition": `attachment; filename="${filenameBase}.json"`,
+      "Content-Type": "application/zip",
+      "Content-Disposition": `attachment; filename="${filenameBase}-postman.zip"`,
     },
-    rawBody: Buffer.from(json, "utf8"),
+    rawBody,
   };
 };
 
 module.exports = {
+  buildEnvironments,
+  buildNewmanFiles,
+  configureClientCredentialsAuth,
   convert,
+  fillEmptyBodies,
+  resolveConversionOptions,
+  toCollectionV20,
 };
@@ -0,0 +1,257 @@
+const Service = require("./Service");
+const { resolveOasInput } = require("./OasInputService");
+const { sanitizeFileName } = require("../utils/fileName");
+const { buildOasSkeleton, splitRequestUrl, substituteVariables } = require("../utils/oasSkeleton");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+
+const DEFAULT_TITLE = "postman-collection";
+const INVALID_COLLECTION_ERROR = "Geen geldige Postman collectie: verwacht een JSON-object met info en item.";
+
+const textOf = (description) =>
+  typeof description === "string" ? description : typeof description?.content === "string" ? description.content : "";
+
+const enabled = (entries) =>
+  (Array.isArray(entries) ? entries : []).filter((entry) => entry && !entry.disabled && entry.key);
+
+// v2.1 beschrijft auth-parameters als lijst van { key, value }, v2.0 als object per auth-type.
+const authParameter = (auth, key) => {
+  const parameters = auth?.[auth.type];
+  if (Array.isArray(parameters)) {
+    return parameters.find((parameter) => parameter.key === key)?.value;
+  }
+  return parameters?.[key];
+};
+
+const resolveSecurity = (auth, variables) => {
+  if (!auth || auth.type === "noauth") {
+    return undefined;
+  }
+  if (auth.type === "bearer" || auth.type === "basic") {
+    return { type: auth.type };
+  }
+  if (auth.type === "apikey") {
+    return {
+      type: "apiKey",
+      name: authParameter(auth, "key") || undefined,
+      in: authParameter(auth, "in") === "query" ? "query" : "header",
+    };
+  }
+  if (auth.type === "oauth2") {
+    const tokenUrl = substituteVariables(authParameter(auth, "accessTokenUrl") || "", variables);
+    const scope = substituteVariables(authParameter(auth, "scope") || "", variables);
+    return {
+      type: "oauth2",
+      tokenUrl: tokenUrl && !tokenUrl.includes("{{") ? tokenUrl : undefined,
+      scopes: scope.includes("{{") ? [] : scope.split(/\s+/).filter(Boolean),
+    };
+  }
+  return undefined;
+};
+
+const toRawUrl = (url) => {
+  if (typeof url === "string") {
+    return url;
+  }
+  if (typeof url?.raw === "string") {
+    return url.raw;
+  }
+  const host = Array.isArray(url?.host) ? url.host.join(".") : url?.host || "";
+  const path = Array.isArray(url?.path) ? url.path.join("/") : url?.path || "";
+  return `${url?.protocol ? `${url.protocol}://` : ""}${host}/${path}`;
+};
+
+const splitUrl = (url, variables) => {
+  const { server, path, query } = splitRequestUrl(toRawUrl(url), variables);
+  // Het query-array van de collectie heeft beschrijvingen en uitgeschakelde parameters; de raw URL niet.
+  if (!Array.isArray(url?.query)) {
+    return { server, path, query };
+  }
+  return {
+    server,
+    path,
+    query: enabled(url.query).map((item) => ({
+      name: item.key,
+      value: item.value,
+      description: textOf(item.description),
+    })),
+  };
+};
+
+const parseJson = (text) => {
+  try {
+    return JSON.parse(text);
+  } catch {
+    return undefined;
+  }
+};
+
+const headerValue = (headers, name) =>
+  enabled(headers).find((header) => header.key.toLowerCase() === name.toLowerCase())?.value;
+
+const RAW_LANGUAGE_TYPES = {
+  json: "application/json",
+  xml: "application/xml",
+  html: "text/html",
+  text: "text/plain",
+};
+
+/**
+ * Request body uit een Postman body. Raw JSON wordt geparsed zodat er een schema uit volgt;
+ * urlencoded en formdata worden een formulier met een veld per key.
+ */
+const resolveRequestBody = (body, headers) => {
+  if (!body || body.disabled) {
+    return undefined;
+  }
+  if (body.mode === "raw" && typeof body.raw === "string" && body.raw.trim() !== "") {
+    const declared = headerValue(headers, "Content-Type");
+    const contentType = declared || RAW_LANGUAGE_TYPES[body.options?.raw?.language] || "application/json";
+    const parsed = /json/i.test(contentType) ? parseJson(body.raw) : undefined;
+    return { contentType: contentType.split(";")[0].trim(), value: parsed === undefined ? body.raw : parsed };
+  }
+  if (body.mode === "urlencoded" || body.mode === "formdata") {
+    const fields = enabled(body[body.mode]).map((field) => ({
+      name: field.key,
+      value: field.value,
+      file: field.type === "file",
+    }));
+    if (fields.length === 0) {
+      return undefined;
+    }
+    return {
+      contentType: body.mode === "urlencoded" ? "application/x-www-form-urlencoded" : "multipart/form-data",
+      fields,
+    };
+  }
+  if (body.mode === "graphql" && body.graphql) {
+    const variables = typeof body.graphql.variables === "string" ? parseJson(body.graphql.variables) : undefined;
+    return { contentType: "application/json", value: { query: body.graphql.query || "", variables: variables || {} } };
+  }
+  return undefined;
+};
+
+// Opgeslagen voorbeeldresponses in de collectie worden responses met een schema uit de body.
+const resolveResponses = (responses) =>
+  (Array.isArray(responses) ? responses : []).map((response) => {
+    const contentType = (headerValue(response.header, "Content-Type") || "application/json").split(";")[0].trim();
+    const text = typeof response.body === "string" ? response.body : "";
+    const parsed = /json/i.test(contentType) ? parseJson(text) : undefined;
+    return {
+      status: response.code,
+      description: response.status || response.name,
+      body: text.trim() === "" ? undefined : { contentType, value: parsed === undefined ? text : parsed },
+    };
+  });
+
+/**
+ * Haalt de requests uit een Postman collectie (v2.0 of v2.1) en zet ze om naar operaties voor het
+ * OAS-skelet. Mappen op het hoogste niveau worden tags; auth wordt van collectie en mappen geërfd.
+ */
+const collectRequests = (collection) => {
+  const variables = new Map(enabled(collection.variable).map((item) => [item.key, String(item.value ?? "")]));
+  const servers = [];
+  const operations = [];
+
+  const visit = (items, { tag, auth }) => {
+    for (const item of Array.isArray(items) ? items : []) {
+      if (Array.isArray(item.item)) {
+        visit(item.item, { tag: tag || item.name, auth: item.auth || auth });
+        continue;
+      }
+      const request = typeof item.request === "string" ? { url: item.request } : item.request;
+      if (!request) {
+        continue;
+      }
+      const headers = Array.isArray(request.header) ? request.header : [];
+      const { server, path, query } = splitUrl(request.url, variables);
+      if (server && !servers.includes(server)) {
+        servers.push(server);
+      }
+      const pathVariables = Array.isArray(request.url?.variable) ? request.url.variable : [];
+      operations.push({
+        method: request.method || "GET",
+        path,
+        summary: item.name,
+        description: textOf(request.description) || textOf(item.description),
+        tag,
+        pathParameters: pathVariables.map((variable) => ({
+          name: variable.key,
+          value: variable.value,
+          description: textOf(variable.description),
+        })),
+        query,
+        headers: enabled(headers).map((header) => ({
+          name: header.key,
+          value: header.value,
+          description: textOf(header.description),
+        })),
+        requestBody: resolveRequestBody(request.body, headers),
+        responses: resolveResponses(item.response),
+        security: resolveSecurity(request.auth || auth, variables),
+      });
+    }
+  };
+  visit(collection.item, { tag: undefined, auth: collection.auth });
+  return { servers, operations };
+};
+
+/**
+ * Reconstrueert een OpenAPI 3.0 skelet uit een Postman collectie: paden, methodes, parameters,
+ * request bodies en (uit opgeslagen voorbeelden) responses, met schema's die uit de voorbeelden zijn
+ * afgeleid. Het resultaat is een startpunt; beschrijvingen, verplichte velden en foutresponses moeten
+ * nog aangevuld worden.
+ */
+const buildOpenApiDocument = (collection, { source } = {}) => {
+  const { servers, operations } = collectRequests(collection);
+  const document = buildOasSkeleton({
+    info: {
+      title: collection.info?.name,
+      description: textOf(collection.info?.description),
+      version: typeof collection.info?.version === "string" ? collection.info.version : undefined,
+    },
+    servers,
+    operations,
+  });
+  return stampDocument(document, buildProvenance({ tool: "postman-oas", source }));
+};
+
+const parseCollection = (contents) => {
+  const collection = parseJson(typeof contents === "string" ? contents.trim() : "");
+  if (!collection || typeof collection !== "object" || Array.isArray(collection) || !collection.info) {
+    throw Service.rejectResponse({ message: INVALID_COLLECTION_ERROR }, 400);
+  }
+  if (!Array.isArray(collection.item)) {
+    throw Service.rejectResponse({ message: INVALID_COLLECTION_ERROR }, 400);
+  }
+  return collection;
+};
+
+const convert = async (input) => {
+  const { contents, source } = await resolveOasInput(input);
+  const collection = parseCollection(contents);
+  let document;
+  try {
+    document = buildOpenApiDocument(collection, { source });
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Conversie van Postman collectie naar OpenAPI is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const filenameBase = sanitizeFileName(document.info.title, { fallback: DEFAULT_TITLE, lowercase: true });
+  return {
+    headers: {
+      "Content-Type": "application/json",
+      "Content-Disposition": `attachment; filename="${filenameBase}.openapi.json"`,
+    },
+    rawBody: Buffer.from(JSON.stringify(document, null, 2), "utf8"),
+  };
+};
+
+module.exports = {
+  buildOpenApiDocument,
+  convert,
+};
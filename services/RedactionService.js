@@ -0,0 +1,260 @@
+const Service = require("./Service");
+const { resolveOasDocument, serializeOasDocument } = require("./OasInputService");
+const { pruneComponents } = require("./OasPruneService");
+const { formatPointer } = require("../utils/jsonPointer");
+const { HTTP_METHODS, expandServerUrl } = require("../utils/openapi");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+
+const OUTPUT_FORMATS = ["spec", "report"];
+
+// Extensies met notities voor het eigen team, zoals `x-todo` of `x-internal-note`.
+const INTERNAL_EXTENSION = /^x-(internal|comments?|notes?|todo|fixme)(-|$)/i;
+const HTML_COMMENT = /[ \t]*<!--[\s\S]*?-->/g;
+const INTERNAL_HOST = [
+  /^localhost$/,
+  /\.localhost$/,
+  /^127\./,
+  /^10\./,
+  /^192\.168\./,
+  /^172\.(1[6-9]|2\d|3[01])\./,
+  /^0\.0\.0\.0$/,
+  /^\[::1\]$/,
+  /\.(local|internal|intern|lan|corp|localdomain)$/,
+];
+const EMAIL = /[A-Z0-9._%+-]+@((?:[A-Z0-9-]+\.)+[A-Z]{2,})/gi;
+const EXAMPLE_DOMAIN = /(^|\.)example(\.[a-z]+)?$/i;
+const NINE_DIGITS = /(?<!\d)\d{9}(?!\d)/g;
+
+// Testnummers: het e-mailadres valt onder example.com, het BSN voldoet aan de elfproef.
+const PLACEHOLDER_EMAIL = "naam@example.com";
+const PLACEHOLDER_BSN = "999999990";
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+const passesElevenTest = (digits) => {
+  const sum = [...digits].reduce((total, digit, index) => total + Number(digit) * (index === 8 ? -1 : 9 - index), 0);
+  return sum % 11 === 0 && digits !== "000000000";
+};
+
+const isInternalServer = (server) => {
+  let hostname;
+  try {
+    ({ hostname } = new URL(expandServerUrl(server)));
+  } catch {
+    // relatieve URL (zoals /v1) of URL met variabelen zonder default
+    return false;
+  }
+  return INTERNAL_HOST.some((pattern) => pattern.test(hostname.toLowerCase()));
+};
+
+const redactString = (context, value, at) => {
+  let redacted = value.replace(EMAIL, (match, domain) => {
+    if (EXAMPLE_DOMAIN.test(domain)) {
+      return match;
+    }
+    context.record("pii-email", at, "E-mailadres in voorbeeld vervangen.");
+    return PLACEHOLDER_EMAIL;
+  });
+  redacted = redacted.replace(NINE_DIGITS, (match) => {
+    if (!passesElevenTest(match) || match === PLACEHOLDER_BSN) {
+      return match;
+    }
+    context.record("pii-bsn", at, "BSN in voorbeeld vervangen.");
+    return PLACEHOLDER_BSN;
+  });
+  return redacted;
+};
+
+// Voorbeelddata: elke string (of elk getal van negen cijfers) wordt op persoonsgegevens gecontroleerd.
+const redactExample = (context, value, at) => {
+  if (typeof value === "string") {
+    return redactString(context, value, at);
+  }
+  if (typeof value === "number" && Number.isInteger(value) && /^\d{9}$/.test(String(value))) {
+    const redacted = redactString(context, String(value), at);
+    return redacted === String(value) ? value : Number(redacted);
+  }
+  if (Array.isArray(value)) {
+    return value.map((item, index) => redactExample(context, item, [...at, index]));
+  }
+  if (isObject(value)) {
+    return Object.fromEntries(
+      Object.entries(value).map(([key, child]) => [key, redactExample(context, child, [...at, key])]),
+    );
+  }
+  return value;
+};
+
+const redactExamples = (context, value, at) => {
+  if (Array.isArray(value)) {
+    return redactExample(context, value, at);
+  }
+  if (!isObject(value)) {
+    return value;
+  }
+  // Media types en parameters hebben een map van Example Objects met de data in `value`.
+  return Object.fromEntries(
+    Object.entries(value).map(([name, example]) => [
+      name,
+      isObject(example) && Object.hasOwn(example, "value")
+        ? { ...example, value: redactExample(context, example.value, [...at, name, "value"]) }
+        : example,
+    ]),
+  );
+};
+
+const isInternal = (value) => isObject(value) && value["x-internal"] === true;
+
+const redactServers = (context, servers, at) =>
+  servers.filter((server, index) => {
+    if (isObject(server) && !isInternal(server) && isInternalServer(server)) {
+      context.record("internal-server", [...at, index], `Interne server ${server.url} verwijderd.`);
+      return false;
+    }
+    return true;
+  });
+
+const redactNode = (context, value, at) => {
+  if (Array.isArray(value)) {
+    const kept = [];
+    value.forEach((item, index) => {
+      if (isInternal(item)) {
+        context.record("x-internal", [...at, index], "Onderdeel met x-internal: true verwijderd.");
+      } else {
+        kept.push(redactNode(context, item, [...at, index]));
+      }
+    });
+    return kept;
+  }
+  if (!isObject(value)) {
+    return value;
+  }
+  const redacted = {};
+  for (const [key, child] of Object.entries(value)) {
+    const childAt = [...at, key];
+    if (key === "x-internal") {
+      continue;
+    }
+    if (key === "$comment" || INTERNAL_EXTENSION.test(key)) {
+      context.record("internal-comment", childAt, `${key} verwijderd.`);
+    } else if (isInternal(child)) {
+      context.record("x-internal", childAt, "Onderdeel met x-internal: true verwijderd.");
+    } else if (key === "example") {
+      redacted[key] = redactExample(context, child, childAt);
+    } else if (key === "examples") {
+      redacted[key] = redactExamples(context, child, childAt);
+    } else if (key === "servers" && Array.isArray(child)) {
+      redacted[key] = redactNode(context, redactServers(context, child, childAt), childAt);
+    } else if (key === "description" && typeof child === "string" && child.includes("<!--")) {
+      context.record("internal-comment", childAt, "HTML-commentaar uit de beschrijving verwijderd.");
+      redacted[key] = child.replace(HTML_COMMENT, "").trim();
+    } else {
+      redacted[key] = redactNode(context, child, childAt);
+    }
+    // Een lijst die door het redigeren leeg raakt (bijvoorbeeld `parameters`) vervalt.
+    if (Array.isArray(child) && child.length > 0 && redacted[key]?.length === 0) {
+      delete redacted[key];
+    }
+  }
+  // Een verwijderde property is ook niet meer verplicht.
+  if (isObject(value.properties) && isObject(redacted.properties) && Array.isArray(redacted.required)) {
+    redacted.required = redacted.required.filter(
+      (name) => !Object.hasOwn(value.properties, name) || Object.hasOwn(redacted.properties, name),
+    );
+  }
+  return redacted;
+};
+
+const hasOperations = (pathItem) => isObject(pathItem) && HTTP_METHODS.some((method) => isObject(pathItem[method]));
+
+// Een pad waarvan alle operaties intern waren verdwijnt helemaal.
+const dropEmptyPaths = (original, redacted) => {
+  for (const [path, pathItem] of Object.entries(isObject(redacted.paths) ? redacted.paths : {})) {
+    if (hasOperations(original.paths[path]) && !hasOperations(pathItem)) {
+      delete redacted.paths[path];
+    }
+  }
+};
+
+/**
+ * Componenten die alleen door verwijderde onderdelen werden gebruikt, zouden interne schema's alsnog
+ * publiceren en vallen daarom ook weg. Componenten die al in het origineel ongebruikt waren blijven staan.
+ */
+const dropOrphanedComponents = (context, original, redacted) => {
+  if (!isObject(original.components) || !isObject(redacted.components)) {
+    return;
+  }
+  const unusedBefore = new Set(pruneComponents(structuredClone(original)).removed);
+  const snapshot = structuredClone(redacted.components);
+  for (const ref of pruneComponents(redacted).removed) {
+    const [section, name] = ref.split("/").slice(2);
+    if (!unusedBefore.has(ref)) {
+      context.record("orphaned-component", ["components", section, name], "Component werd alleen intern gebruikt.");
+    } else if (Object.hasOwn(snapshot[section] ?? {}, name)) {
+      redacted.components ??= {};
+      redacted.components[section] ??= {};
+      redacted.components[section][name] = snapshot[section][name];
+    }
+  }
+};
+
+/**
+ * Maakt een specificatie klaar voor externe publicatie: onderdelen met `x-internal: true` (paden,
+ * operaties, parameters, properties, tags, servers) en servers op interne hosts verdwijnen, net als
+ * `$comment`, notitie-extensies (`x-todo`, `x-internal-note`, ...) en HTML-commentaar in
+ * beschrijvingen. E-mailadressen en BSN's in voorbeelden worden vervangen door testwaarden.
+ * `redactions` noemt elke ingreep met een JSON pointer naar de plek in het brondocument.
+ */
+const redactDocument = (spec) => {
+  const redactions = [];
+  const context = {
+    record: (type, at, message) => redactions.push({ type, path: formatPointer(at), message }),
+  };
+  const redacted = redactNode(context, spec, []);
+  if (isObject(spec.paths) && isObject(redacted.paths)) {
+    dropEmptyPaths(spec, redacted);
+  }
+  dropOrphanedComponents(context, spec, redacted);
+  return { spec: redacted, redactions };
+};
+
+const resolveOutputFormat = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return "spec";
+  }
+  const normalized = String(value).toLowerCase();
+  if (!OUTPUT_FORMATS.includes(normalized)) {
+    throw Service.rejectResponse(
+      { message: `Onbekend outputFormat "${value}". Kies uit: ${OUTPUT_FORMATS.join(", ")}.` },
+      400,
+    );
+  }
+  return normalized;
+};
+
+/**
+ * Geeft de opgeschoonde specificatie terug in het formaat van de input, of met `outputFormat=report`
+ * het rapport van wat er is weggehaald. Het rapport staat bewust niet in de specificatie zelf: de
+ * pointers zouden de namen van interne paden prijsgeven.
+ */
+const redact = async (input, { outputFormat, responseFormat } = {}) => {
+  const format = resolveOutputFormat(outputFormat);
+  const resolved = await resolveOasDocument(input);
+  const { spec, redactions } = redactDocument(resolved.spec);
+  if (format === "report") {
+    const body = { total: redactions.length, redactions };
+    return {
+      headers: { "Content-Type": "application/json" },
+      rawBody: Buffer.from(JSON.stringify(body, null, 2), "utf8"),
+    };
+  }
+  stampDocument(spec, buildProvenance({ tool: "oas-redact", source: resolved.source }));
+  const result = serializeOasDocument(spec, responseFormat ?? resolved.format, "openapi-redacted");
+  result.headers["X-Redactions"] = String(redactions.length);
+  return result;
+};
+
+module.exports = {
+  redact,
+  redactDocument,
+};
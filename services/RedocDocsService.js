@@ -0,0 +1,88 @@
+const fs = require("node:fs/promises");
+const os = require("node:os");
+const path = require("node:path");
+const { execFile } = require("node:child_process");
+const { promisify } = require("node:util");
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { sanitizeFileName } = require("../utils/fileName");
+const { OUTBOUND_GUARD_ARGS } = require("../utils/outboundPolicy");
+const { buildProvenance, describeProvenance, stampDocument } = require("../utils/provenance");
+const logger = require("../logger");
+
+const DEFAULT_FILENAME = "openapi";
+const REDOCLY_BIN = require.resolve("@redocly/cli/bin/cli");
+const TEMP_PREFIX = "redoc-docs-";
+const execFileAsync = promisify(execFile);
+
+const runRedoclyBuildDocs = async (inputPath, outputPath, title) => {
+  // Zonder `--cdn` zit Redoc zelf in het HTML-bestand; zonder Google Fonts is er geen enkele externe bron.
+  const args = [REDOCLY_BIN, "build-docs", inputPath, "--output", outputPath, "--disableGoogleFont"];
+  if (title) {
+    args.push("--title", title);
+  }
+  return execFileAsync(process.execPath, [...OUTBOUND_GUARD_ARGS, ...args], { maxBuffer: 20 * 1024 * 1024 });
+};
+
+// Herkomst als HTML-commentaar direct na de doctype, zodat de browser niet in quirks mode schiet.
+const withProvenanceComment = (html, provenance) => {
+  const comment = `<!-- ${describeProvenance(provenance).replace(/--/g, "- -")} -->`;
+  const doctype = /^\s*<!doctype html>/i.exec(html);
+  return doctype ? `${doctype[0]}\n${comment}${html.slice(doctype[0].length)}` : `${comment}\n${html}`;
+};
+
+/**
+ * Bouwt met Redocly CLI (`build-docs`) één HTML-bestand dat de specificatie met Redoc toont. Redoc
+ * en de specificatie staan in het bestand zelf, zodat het zonder hosting of internettoegang te
+ * openen en te publiceren is.
+ */
+const buildDocs = async (input) => {
+  const resolved = await resolveOasDocument(input);
+  const provenance = buildProvenance({ tool: "oas-redoc", source: resolved.source });
+  const document = stampDocument(resolved.spec, provenance);
+  const title = typeof document.info?.title === "string" ? document.info.title.trim() : "";
+
+  let tmpDir;
+  let html;
+  try {
+    tmpDir = await fs.mkdtemp(path.join(os.tmpdir(), TEMP_PREFIX));
+    const inputPath = path.join(tmpDir, "openapi.json");
+    const outputPath = path.join(tmpDir, "docs.html");
+    await fs.writeFile(inputPath, JSON.stringify(document), "utf8");
+    await runRedoclyBuildDocs(inputPath, outputPath, title);
+    html = await fs.readFile(outputPath, "utf8");
+  } catch (error) {
+    logger.error("[RedocDocsService] build-docs failed via redocly CLI", {
+      message: error?.message,
+      stack: error?.stack,
+    });
+    throw Service.rejectResponse(
+      {
+        message: "Het genereren van de Redoc-documentatie is mislukt.",
+        detail: `${error?.stderr || ""}`.trim() || error?.message,
+      },
+      400,
+    );
+  } finally {
+    if (tmpDir) {
+      try {
+        await fs.rm(tmpDir, { recursive: true, force: true });
+      } catch {
+        // ignore cleanup errors
+      }
+    }
+  }
+
+  const filenameBase = sanitizeFileName(title, { fallback: DEFAULT_FILENAME, lowercase: true });
+  return {
+    headers: {
+      "Content-Type": "text/html; charset=utf-8",
+      "Content-Disposition": `attachment; filename="${filenameBase}-docs.html"`,
+    },
+    rawBody: Buffer.from(withProvenanceComment(html, provenance), "utf8"),
+  };
+};
+
+module.exports = {
+  buildDocs,
+};
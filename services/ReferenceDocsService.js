@@ -0,0 +1,492 @@
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { sanitizeFileName } = require("../utils/fileName");
+const { collectOperations, expandServerUrl, operationName, resolveRef, schemaRefName } = require("../utils/openapi");
+const { buildProvenance, describeProvenance } = require("../utils/provenance");
+const { renderPdf } = require("./PdfRenderService");
+
+const DEFAULT_FILENAME = "openapi";
+const UNTAGGED_SECTION = "Overige operaties";
+// De pagina laadt niets: geen scripts, geen afbeeldingen of andere bronnen, alleen de eigen <style>.
+const CONTENT_SECURITY_POLICY = "default-src 'none'; style-src 'unsafe-inline'";
+const PARAMETER_LOCATIONS = ["path", "query", "header", "cookie"];
+
+const normalizeText = (value) => (typeof value === "string" ? value.trim() : "");
+
+const slug = (text) =>
+  String(text)
+    .normalize("NFKD")
+    .replace(/\p{M}+/gu, "")
+    .toLowerCase()
+    .replace(/[^a-z0-9]+/g, "-")
+    .replace(/^-+|-+$/g, "");
+
+/**
+ * Markdown-notatie van de bouwstenen van de referentie. Ankers staan als `<a id>` vóór de kop, zodat
+ * links ook werken in renderers die zelf andere ids aan koppen geven.
+ */
+const markdown = {
+  name: "markdown",
+  extension: "md",
+  contentType: "text/markdown; charset=utf-8",
+  comment: (text) => [`<!-- ${String(text).replace(/--/g, "- -")} -->`, ""],
+  heading: (level, text, anchor) => [
+    ...(anchor ? [`<a id="${anchor}"></a>`, ""] : []),
+    `${"#".repeat(level)} ${text}`,
+    "",
+  ],
+  paragraph: (text) => [text, ""],
+  list: (items) => [...items.map((item) => `- ${item}`), ""],
+  table: (headers, rows) => [
+    `| ${headers.join(" | ")} |`,
+    `| ${headers.map(() => "---").join(" | ")} |`,
+    ...rows.map((row) => `| ${row.map((cell) => markdown.cell(cell)).join(" | ")} |`),
+    "",
+  ],
+  cell: (value) =>
+    String(value ?? "")
+      .replace(/\|/g, "\\|")
+      .replace(/\r?\n/g, " "),
+  code: (text) => `\`${text}\``,
+  strong: (text) => `**${text}**`,
+  link: (text, anchor) => `[${text}](#${anchor})`,
+  text: (value) => String(value ?? ""),
+};
+
+/**
+ * AsciiDoc-notatie voor Antora/Asciidoctor. Inline code gebruikt `+...+` zodat tekens als `*` en `_`
+ * in namen en paden niet als opmaak gelezen worden.
+ */
+const asciidoc = {
+  name: "asciidoc",
+  extension: "adoc",
+  contentType: "text/asciidoc; charset=utf-8",
+  comment: (text) => [`// ${String(text).replace(/\r?\n/g, " ")}`, ""],
+  heading: (level, text, anchor) => [...(anchor ? [`[[${anchor}]]`] : []), `${"=".repeat(level)} ${text}`, ""],
+  paragraph: (text) => [text, ""],
+  list: (items) => [...items.map((item) => `* ${item}`), ""],
+  table: (headers, rows) => [
+    '[options="header"]',
+    "|===",
+    headers.map((header) => `|${header}`).join(" "),
+    ...rows.map((row) => row.map((cell) => `|${asciidoc.cell(cell)}`).join(" ")),
+    "|===",
+    "",
+  ],
+  cell: (value) =>
+    String(value ?? "")
+      .replace(/\|/g, "\\|")
+      .replace(/\r?\n/g, " "),
+  code: (text) => `\`+${text}+\``,
+  strong: (text) => `*${text}*`,
+  link: (text, anchor) => `<<${anchor},${text}>>`,
+  text: (value) => String(value ?? ""),
+};
+
+const escapeHtml = (value) =>
+  String(value ?? "")
+    .replace(/&/g, "&amp;")
+    .replace(/</g, "&lt;")
+    .replace(/>/g, "&gt;")
+    .replace(/"/g, "&quot;");
+
+// Opmaak voor het afdrukken: A4, geen externe fonts, tabellen die niet midden in een rij afbreken.
+const HTML_STYLES = [
+  "@page { size: A4; margin: 18mm 15mm; }",
+  "body { font-family: sans-serif; font-size: 10pt; line-height: 1.4; color: #1a1a1a; }",
+  "h1, h2, h3 { break-after: avoid; }",
+  "h2 { border-bottom: 1px solid #999; padding-bottom: 2pt; margin-top: 18pt; }",
+  "p { white-space: pre-line; }",
+  "table { border-collapse: collapse; width: 100%; margin-bottom: 10pt; }",
+  "th, td { border: 1px solid #bbb; padding: 3pt 5pt; text-align: left; vertical-align: top; }",
+  "tr { break-inside: avoid; }",
+  "th { background: #eee; }",
+  "code { font-family: monospace; font-size: 9pt; overflow-wrap: anywhere; }",
+];
+
+/**
+ * HTML-notatie, ook de basis voor de PDF. Tekst uit de specificatie gaat via `text` en wordt
+ * ge-escaped; de overige bouwstenen leveren al opgemaakte HTML op. `document` zet er een
+ * zelfstandige pagina met ingebouwde opmaak omheen, met een CSP die alleen die opmaak toestaat.
+ */
+const html = {
+  name: "html",
+  extension: "html",
+  contentType: "text/html; charset=utf-8",
+  comment: (text) => [`<!-- ${String(text).replace(/--/g, "- -")} -->`],
+  heading: (level, text, anchor) => [`<h${level}${anchor ? ` id="${anchor}"` : ""}>${escapeHtml(text)}</h${level}>`],
+  paragraph: (text) => [`<p>${text}</p>`],
+  list: (items) => ["<ul>", ...items.map((item) => `  <li>${item}</li>`), "</ul>"],
+  table: (headers, rows) => [
+    "<table>",
+    `  <thead><tr>${headers.map((header) => `<th>${escapeHtml(header)}</th>`).join("")}</tr></thead>`,
+    "  <tbody>",
+    ...rows.map((row) => `    <tr>${row.map((cell) => `<td>${html.cell(cell)}</td>`).join("")}</tr>`),
+    "  </tbody>",
+    "</table>",
+  ],
+  cell: (value) => String(value ?? ""),
+  code: (text) => `<code>${escapeHtml(text)}</code>`,
+  strong: (text) => `<strong>${escapeHtml(text)}</strong>`,
+  link: (text, anchor) => `<a href="#${anchor}">${escapeHtml(text)}</a>`,
+  text: escapeHtml,
+  document: (title, header, body) => [
+    "<!DOCTYPE html>",
+    ...header,
+    '<html lang="nl">',
+    "<head>",
+    '<meta charset="utf-8">',
+    `<meta http-equiv="Content-Security-Policy" content="${CONTENT_SECURITY_POLICY}">`,
+    `<title>${escapeHtml(title)}</title>`,
+    "<style>",
+    ...HTML_STYLES,
+    "</style>",
+    "</head>",
+    "<body>",
+    ...body,
+    "</body>",
+    "</html>",
+  ],
+};
+
+// Zelfde HTML, daarna met headless Chromium afgedrukt (zie PdfRenderService).
+const pdf = { ...html, name: "pdf", extension: "pdf", contentType: "application/pdf" };
+
+const WRITERS = { markdown, asciidoc, html, pdf };
+
+const schemaAnchor = (name) => `schema-${slug(name)}`;
+
+/**
+ * Korte typeomschrijving van een schema: een link naar de bijlage voor benoemde schema's, `array van
+ * ...` voor arrays en anders het type met format (`string (date-time)`).
+ */
+const describeType = (document, writer, rawSchema) => {
+  const refName = schemaRefName(rawSchema);
+  if (refName) {
+    return writer.link(refName, schemaAnchor(refName));
+  }
+  const schema = resolveRef(document, rawSchema);
+  if (!schema || typeof schema !== "object") {
+    return "";
+  }
+  for (const keyword of ["oneOf", "anyOf"]) {
+    if (Array.isArray(schema[keyword])) {
+      const variants = schema[keyword].map((variant) => describeType(document, writer, variant)).filter(Boolean);
+      return `${keyword === "oneOf" ? "één van" : "een of meer van"}: ${variants.join(", ")}`;
+    }
+  }
+  if (Array.isArray(schema.allOf)) {
+    return schema.allOf.map((part) => describeType(document, writer, part)).filter(Boolean).join(" + ");
+  }
+  const type = Array.isArray(schema.type) ? schema.type.filter((item) => item !== "null").join(" | ") : schema.type;
+  if (type === "array") {
+    const items = describeType(document, writer, schema.items);
+    return items ? `array van ${items}` : "array";
+  }
+  const label = writer.text(type || (schema.properties ? "object" : ""));
+  return schema.format ? `${label} (${writer.text(schema.format)})` : label;
+};
+
+// Beschrijving plus enum-waarden, default en deprecated van het schema, als één zin voor een tabelcel.
+const describeSchemaDetails = (document, writer, rawSchema, description) => {
+  const schema = resolveRef(document, rawSchema) || {};
+  const parts = [writer.text(normalizeText(description))];
+  if (Array.isArray(schema.enum)) {
+    parts.push(`Waarden: ${schema.enum.map((value) => writer.code(JSON.stringify(value))).join(", ")}.`);
+  }
+  if (schema.default !== undefined) {
+    parts.push(`Standaard: ${writer.code(JSON.stringify(schema.default))}.`);
+  }
+  if (schema.deprecated) {
+    parts.push("Verouderd.");
+  }
+  return parts.filter(Boolean).join(" ");
+};
+
+const renderParameters = (document, writer, parameters) => {
+  const sorted = [...parameters].sort(
+    (left, right) => PARAMETER_LOCATIONS.indexOf(left.in) - PARAMETER_LOCATIONS.indexOf(right.in),
+  );
+  return [
+    ...writer.paragraph(writer.strong("Parameters")),
+    ...writer.table(
+      ["Naam", "In", "Type", "Verplicht", "Beschrijving"],
+      sorted.map((parameter) => [
+        writer.code(parameter.name),
+        parameter.in,
+        describeType(document, writer, parameter.schema),
+        parameter.required ? "ja" : "nee",
+        describeSchemaDetails(
+          document,
+          writer,
+          parameter.schema,
+          parameter.description ?? resolveRef(document, parameter.schema)?.description,
+        ),
+      ]),
+    ),
+  ];
+};
+
+const renderRequestBody = (document, writer, requestBody) => {
+  const content = Object.entries(requestBody.content || {});
+  const lines = writer.paragraph(`${writer.strong("Request body")}${requestBody.required ? " (verplicht)" : ""}`);
+  const description = normalizeText(requestBody.description);
+  if (description) {
+    lines.push(...writer.paragraph(writer.text(description)));
+  }
+  if (content.length > 0) {
+    lines.push(
+      ...writer.table(
+        ["Content-Type", "Schema"],
+        content.map(([type, media]) => [writer.code(type), describeType(document, writer, media?.schema)]),
+      ),
+    );
+  }
+  return lines;
+};
+
+const renderResponses = (document, writer, responses) => {
+  const rows = [];
+  for (const [status, rawResponse] of Object.entries(responses || {})) {
+    const response = resolveRef(document, rawResponse) || {};
+    const content = Object.entries(response.content || {});
+    const description = writer.text(normalizeText(response.description));
+    if (content.length === 0) {
+      rows.push([writer.code(status), description, "", ""]);
+    }
+    for (const [type, media] of content) {
+      rows.push([writer.code(status), description, writer.code(type), describeType(document, writer, media?.schema)]);
+    }
+  }
+  if (rows.length === 0) {
+    return [];
+  }
+  return [
+    ...writer.paragraph(writer.strong("Responses")),
+    ...writer.table(["Status", "Beschrijving", "Content-Type", "Schema"], rows),
+  ];
+};
+
+const renderOperation = (document, writer, entry) => {
+  const { path, method, operation, parameters, requestBody } = entry;
+  const lines = [
+    ...writer.heading(3, operationName(entry), slug(`${method}-${path}`)),
+    ...writer.paragraph(writer.code(`${method.toUpperCase()} ${path}`)),
+  ];
+  if (operation.deprecated) {
+    lines.push(...writer.paragraph(`${writer.strong("Verouderd:")} deze operatie wordt uitgefaseerd.`));
+  }
+  const description = normalizeText(operation.description);
+  if (description && description !== normalizeText(operation.summary)) {
+    lines.push(...writer.paragraph(writer.text(description)));
+  }
+  const security = operation.security ?? document.security ?? [];
+  const schemes = [...new Set(security.flatMap((requirement) => Object.keys(requirement || {})))];
+  if (schemes.length > 0) {
+    lines.push(...writer.paragraph(`${writer.strong("Authenticatie:")} ${schemes.map(writer.code).join(", ")}`));
+  }
+  if (parameters.length > 0) {
+    lines.push(...renderParameters(document, writer, parameters));
+  }
+  if (requestBody) {
+    lines.push(...renderRequestBody(document, writer, requestBody));
+  }
+  lines.push(...renderResponses(document, writer, operation.responses));
+  return lines;
+};
+
+const renderSecuritySchemes = (document, writer) => {
+  const schemes = Object.entries(document.components?.securitySchemes || {});
+  if (schemes.length === 0) {
+    return [];
+  }
+  return [
+    ...writer.heading(2, "Authenticatie", "authenticatie"),
+    ...writer.table(
+      ["Naam", "Type", "Details", "Beschrijving"],
+      schemes.map(([name, rawScheme]) => {
+        const scheme = resolveRef(document, rawScheme) || {};
+        const flows = Object.entries(scheme.flows || {}).map(([flow, details]) =>
+          [flow, details?.tokenUrl].filter(Boolean).join(": "),
+        );
+        const details = {
+          http: scheme.scheme,
+          apiKey: `${scheme.name} (${scheme.in})`,
+          oauth2: flows.join(", "),
+          openIdConnect: scheme.openIdConnectUrl,
+        }[scheme.type];
+        return [
+          writer.code(name),
+          writer.text(scheme.type),
+          writer.text(details),
+          writer.text(normalizeText(scheme.description)),
+        ];
+      }),
+    ),
+  ];
+};
+
+const renderSchema = (document, writer, name, rawSchema) => {
+  const schema = resolveRef(document, rawSchema) || {};
+  const lines = writer.heading(3, name, schemaAnchor(name));
+  const description = normalizeText(schema.description);
+  if (description) {
+    lines.push(...writer.paragraph(writer.text(description)));
+  }
+  const required = new Set(Array.isArray(schema.required) ? schema.required : []);
+  const properties = Object.entries(schema.properties || {});
+  for (const part of schema.allOf || []) {
+    const resolved = resolveRef(document, part) || {};
+    const refName = schemaRefName(part);
+    if (refName) {
+      lines.push(...writer.paragraph(`Bevat alle velden van ${writer.link(refName, schemaAnchor(refName))}.`));
+      continue;
+    }
+    properties.push(...Object.entries(resolved.properties || {}));
+    for (const requiredName of resolved.required || []) {
+      required.add(requiredName);
+    }
+  }
+  if (properties.length > 0) {
+    lines.push(
+      ...writer.table(
+        ["Veld", "Type", "Verplicht", "Beschrijving"],
+        properties.map(([property, propertySchema]) => [
+          writer.code(property),
+          describeType(document, writer, propertySchema),
+          required.has(property) ? "ja" : "nee",
+          describeSchemaDetails(document, writer, propertySchema, resolveRef(document, propertySchema)?.description),
+        ]),
+      ),
+    );
+  } else {
+    const type = describeType(document, writer, schema);
+    const details = describeSchemaDetails(document, writer, schema);
+    lines.push(...writer.paragraph([type && `Type: ${type}.`, details].filter(Boolean).join(" ")));
+  }
+  return lines;
+};
+
+/**
+ * Bouwt een API-referentie uit een OpenAPI document met `writer` voor de notatie: inleiding met
+ * versie en servers, authenticatie, een sectie per tag met per operatie parameters, request body
+ * en responses in tabellen, en een bijlage met de schema's uit `components.schemas`. Operaties met
+ * meerdere tags staan alleen onder de eerste tag.
+ */
+const renderReference = (document, writer, { source } = {}) => {
+  const title = normalizeText(document.info?.title) || "API";
+  const header = writer.comment(describeProvenance(buildProvenance({ tool: `oas-${writer.name}`, source })));
+  const lines = writer.heading(1, title);
+  const description = normalizeText(document.info?.description);
+  if (description) {
+    lines.push(...writer.paragraph(writer.text(description)));
+  }
+  const servers = (Array.isArray(document.servers) ? document.servers : [])
+    .map((server) => [expandServerUrl(server), normalizeText(server.description)])
+    .filter(([url]) => url);
+  lines.push(
+    ...writer.list([
+      `${writer.strong("Versie:")} ${writer.text(document.info?.version)}`,
+      `${writer.strong("OpenAPI:")} ${writer.text(document.openapi ?? document.swagger)}`,
+      ...servers.map(([url, serverDescription]) =>
+        [`${writer.strong("Server:")} ${writer.code(url)}`, serverDescription && `(${writer.text(serverDescription)})`]
+          .filter(Boolean)
+          .join(" "),
+      ),
+    ]),
+  );
+
+  const sections = new Map(
+    (Array.isArray(document.tags) ? document.tags : []).map((tag) => [tag.name, { tag, operations: [] }]),
+  );
+  for (const entry of collectOperations(document)) {
+    const name = (Array.isArray(entry.operation.tags) && entry.operation.tags[0]) || UNTAGGED_SECTION;
+    if (!sections.has(name)) {
+      sections.set(name, { tag: { name }, operations: [] });
+    }
+    sections.get(name).operations.push(entry);
+  }
+  const filled = [...sections.values()].filter((section) => section.operations.length > 0);
+  const schemas = Object.entries(document.components?.schemas || {});
+
+  lines.push(
+    ...writer.heading(2, "Inhoud"),
+    ...writer.list([
+      ...(document.components?.securitySchemes ? [writer.link("Authenticatie", "authenticatie")] : []),
+      ...filled.map(({ tag }) => writer.link(tag.name, `tag-${slug(tag.name)}`)),
+      ...(schemas.length > 0 ? [writer.link("Schema's", "schemas")] : []),
+    ]),
+    ...renderSecuritySchemes(document, writer),
+  );
+  for (const { tag, operations } of filled) {
+    lines.push(...writer.heading(2, tag.name, `tag-${slug(tag.name)}`));
+    const tagDescription = normalizeText(tag.description);
+    if (tagDescription) {
+      lines.push(...writer.paragraph(writer.text(tagDescription)));
+    }
+    for (const entry of operations) {
+      lines.push(...renderOperation(document, writer, entry));
+    }
+  }
+  if (schemas.length > 0) {
+    lines.push(...writer.heading(2, "Schema's", "schemas"));
+    for (const [name, schema] of schemas) {
+      lines.push(...renderSchema(document, writer, name, schema));
+    }
+  }
+  while (lines.at(-1) === "") {
+    lines.pop();
+  }
+  const output = writer.document ? writer.document(title, header, lines) : [...header, ...lines];
+  return `${output.join("\n")}\n`;
+};
+
+const resolveWriter = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return markdown;
+  }
+  const writer = WRITERS[String(value).toLowerCase()];
+  if (!writer) {
+    throw Service.rejectResponse(
+      { message: `Onbekend outputFormat "${value}". Kies uit: ${Object.keys(WRITERS).join(", ")}.` },
+      400,
+    );
+  }
+  return writer;
+};
+
+const convert = async (input, { outputFormat } = {}) => {
+  const writer = resolveWriter(outputFormat);
+  const resolved = await resolveOasDocument(input);
+  let text;
+  try {
+    text = renderReference(resolved.spec, writer, { source: resolved.source });
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Genereren van de API-referentie is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const filenameBase = sanitizeFileName(normalizeText(resolved.spec.info?.title), {
+    fallback: DEFAULT_FILENAME,
+    lowercase: true,
+  });
+  return {
+    headers: {
+      "Content-Type": writer.contentType,
+      "Content-Disposition": `attachment; filename="${filenameBase}-reference.${writer.extension}"`,
+    },
+    rawBody: writer === pdf ? await renderPdf(text) : Buffer.from(text, "utf8"),
+  };
+};
+
+module.exports = {
+  asciidoc,
+  convert,
+  html,
+  markdown,
+  renderReference,
+};
@@ -0,0 +1,240 @@
+const { URL } = require("node:url");
+const logger = require("../logger");
+
+const DEFAULT_BASE_URL = "https://api.developer.overheid.nl/api-register/v1";
+const DEFAULT_TIMEOUT_MS = 30000;
+const DEFAULT_ATTEMPTS = 3;
+const RETRY_DELAY_MS = 500;
+const MAX_ERROR_BODY_LENGTH = 2048;
+const RETRYABLE_STATUSES = new Set([429, 502, 503, 504]);
+const IDEMPOTENT_METHODS = new Set(["GET", "HEAD", "PUT", "DELETE"]);
+
+const ERROR_CODES = {
+  CONFIG: "config",
+  UNAUTHORIZED: "unauthorized",
+  NOT_FOUND: "not_found",
+  PRECONDITION_FAILED: "precondition_failed",
+  GENERIC: "generic",
+};
+
+class RegisterError extends Error {
+  constructor(message, code = ERROR_CODES.GENERIC, status = undefined) {
+    super(message);
+    this.name = "RegisterError";
+    this.code = code;
+    this.status = status;
+  }
+}
+
+const trimString = (value) => (typeof value === "string" ? value.trim() : "");
+
+const wait = (ms) => new Promise((resolve) => setTimeout(resolve, ms));
+
+const codeForStatus = (status) => {
+  switch (status) {
+    case 401:
+    case 403:
+      return ERROR_CODES.UNAUTHORIZED;
+    case 404:
+      return ERROR_CODES.NOT_FOUND;
+    case 412:
+      return ERROR_CODES.PRECONDITION_FAILED;
+    default:
+      return ERROR_CODES.GENERIC;
+  }
+};
+
+const parseBody = (text) => {
+  if (!text) {
+    return undefined;
+  }
+  try {
+    return JSON.parse(text);
+  } catch {
+    return text;
+  }
+};
+
+const extractItems = (data) => {
+  if (Array.isArray(data)) {
+    return data;
+  }
+  for (const key of ["results", "data", "apis"]) {
+    if (Array.isArray(data?.[key])) {
+      return data[key];
+    }
+  }
+  return [];
+};
+
+// Een API zonder organisatie hoort bij niemand, zodat die niet in het rapport van elke organisatie belandt.
+const belongsToOrganisation = (api, organisationUri) => {
+  const uri = typeof api?.organisation === "string" ? api.organisation : api?.organisation?.uri;
+  return Boolean(uri) && uri === organisationUri;
+};
+
+/**
+ * Client voor de api-register API. GET-verzoeken worden met `If-None-Match` herhaald zodra een
+ * ETag bekend is; bij een 304 wordt de eerder ontvangen body teruggegeven met `notModified: true`.
+ * Schrijfacties kunnen een ETag meekrijgen die als `If-Match` wordt verstuurd, zodat gelijktijdige
+ * wijzigingen met een 412 worden geweigerd in plaats van elkaar te overschrijven.
+ */
+class RegisterClient {
+  constructor({
+    baseURL = DEFAULT_BASE_URL,
+    apiKey = "",
+    timeoutMs = DEFAULT_TIMEOUT_MS,
+    attempts = DEFAULT_ATTEMPTS,
+    fetchImpl,
+  } = {}) {
+    this.baseURL = trimString(baseURL).replace(/\/+$/, "");
+    this.apiKey = trimString(apiKey);
+    this.timeoutMs = Number.isFinite(timeoutMs) && timeoutMs > 0 ? timeoutMs : DEFAULT_TIMEOUT_MS;
+    this.attempts = Number.isInteger(attempts) && attempts > 0 ? attempts : DEFAULT_ATTEMPTS;
+    this.fetch = typeof fetchImpl === "function" ? fetchImpl : fetch;
+    this.etagCache = new Map();
+  }
+
+  static fromEnv() {
+    return new RegisterClient({
+      baseURL: process.env.REGISTER_BASE_URL || DEFAULT_BASE_URL,
+      apiKey: process.env.REGISTER_API_KEY,
+      timeoutMs: Number(process.env.REGISTER_TIMEOUT_MS),
+    });
+  }
+
+  isConfigured() {
+    return Boolean(this.baseURL);
+  }
+
+  buildUrl(path, query = {}) {
+    if (!this.isConfigured()) {
+      throw new RegisterError("Register configuratie ontbreekt", ERROR_CODES.CONFIG);
+    }
+    const url = new URL(`${this.baseURL}/${String(path).replace(/^\/+/, "")}`);
+    for (const [key, value] of Object.entries(query)) {
+      if (value !== undefined && value !== null && value !== "") {
+        url.searchParams.set(key, String(value));
+      }
+    }
+    return url.toString();
+  }
+
+  buildHeaders({ etag, ifMatch, body } = {}) {
+    const headers = { Accept: "application/json" };
+    if (this.apiKey) {
+      headers["X-Api-Key"] = this.apiKey;
+    }
+    if (etag) {
+      headers["If-None-Match"] = etag;
+    }
+    if (ifMatch) {
+      headers["If-Match"] = ifMatch;
+    }
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+    return headers;
+  }
+
+  async send(method, url, { body, ifMatch } = {}) {
+    const cached = method === "GET" ? this.etagCache.get(url) : undefined;
+    const init = {
+      method,
+      headers: this.buildHeaders({ etag: cached?.etag, ifMatch, body }),
+      body: body === undefined ? undefined : JSON.stringify(body),
+    };
+    const attempts = IDEMPOTENT_METHODS.has(method) ? this.attempts : 1;
+    let lastError;
+    for (let attempt = 1; attempt <= attempts; attempt += 1) {
+      try {
+        const response = await this.fetch(url, { ...init, signal: AbortSignal.timeout(this.timeoutMs) });
+        if (RETRYABLE_STATUSES.has(response.status) && attempt < attempts) {
+          lastError = new RegisterError(`Register gaf status ${response.status}`, ERROR_CODES.GENERIC, response.status);
+        } else {
+          return await this.handleResponse(method, url, response, cached);
+        }
+      } catch (error) {
+        if (error instanceof RegisterError) {
+          throw error;
+        }
+        lastError = new RegisterError(`Netwerkfout richting register: ${error.message}`, ERROR_CODES.GENERIC);
+      }
+      logger.warn(`[RegisterClient] ${method} ${url} mislukt (poging ${attempt}/${attempts}): ${lastError.message}`);
+      if (attempt < attempts) {
+        await wait(RETRY_DELAY_MS * 2 ** (attempt - 1));
+      }
+    }
+    throw lastError;
+  }
+
+  async handleResponse(method, url, response, cached) {
+    if (response.status === 304 && cached) {
+      return { status: 304, data: cached.data, etag: cached.etag, notModified: true };
+    }
+    const text = await response.text();
+    if (!response.ok) {
+      const preview = text.length > MAX_ERROR_BODY_LENGTH ? `${text.slice(0, MAX_ERROR_BODY_LENGTH)}…` : text;
+      throw new RegisterError(
+        `Register gaf status ${response.status}${preview ? `: ${preview}` : ""}`,
+        codeForStatus(response.status),
+        response.status,
+      );
+    }
+    const data = parseBody(text);
+    const etag = response.headers.get("etag") || undefined;
+    if (method === "GET" && etag) {
+      this.etagCache.set(url, { etag, data });
+    }
+    return { status: response.status, data, etag, notModified: false };
+  }
+
+  async listApis({ page, perPage } = {}) {
+    return this.send("GET", this.buildUrl("apis", { page, perPage }));
+  }
+
+  /**
+   * Haalt alle API's van een organisatie op door de pagina's af te lopen. Het filter wordt ook
+   * lokaal toegepast, zodat een register dat de query negeert geen API's van anderen oplevert.
+   */
+  async listApisForOrganisation(organisationUri, { perPage = 100, maxPages = 20 } = {}) {
+    const apis = [];
+    for (let page = 1; page <= maxPages; page += 1) {
+      const { data } = await this.send("GET", this.buildUrl("apis", { organisation: organisationUri, page, perPage }));
+      const items = extractItems(data);
+      apis.push(...items.filter((api) => belongsToOrganisation(api, organisationUri)));
+      if (items.length < perPage) {
+        break;
+      }
+    }
+    return apis;
+  }
+
+  async getApi(id) {
+    return this.send("GET", this.buildUrl(`apis/${encodeURIComponent(id)}`));
+  }
+
+  async createApi(payload) {
+    return this.send("POST", this.buildUrl("apis"), { body: payload });
+  }
+
+  async updateApi(id, payload, { etag } = {}) {
+    return this.send("PUT", this.buildUrl(`apis/${encodeURIComponent(id)}`), { body: payload, ifMatch: etag });
+  }
+}
+
+let sharedClient;
+
+const getRegisterClient = () => {
+  if (!sharedClient) {
+    sharedClient = RegisterClient.fromEnv();
+  }
+  return sharedClient;
+};
+
+module.exports = {
+  ERROR_CODES,
+  RegisterClient,
+  RegisterError,
+  getRegisterClient,
+};
@@ -0,0 +1,202 @@
+const crypto = require("node:crypto");
+const { Spectral } = require("@stoplight/spectral-core");
+const formats = require("@stoplight/spectral-formats");
+const functions = require("@stoplight/spectral-functions");
+const rulesets = require("@stoplight/spectral-rulesets");
+const { createSpectralResolver } = require("./RemoteSpecificationService");
+const { decodeSpecification } = require("../utils/encoding");
+const { parseYaml } = require("../utils/yaml");
+const logger = require("../logger");
+
+const DEFAULT_REFRESH_MS = 15 * 60 * 1000;
+const FETCH_TIMEOUT_MS = 10000;
+const SPECTRAL_PREFIX = "spectral:";
+const EXTEND_SEVERITIES = ["off", "recommended", "all"];
+
+class RemoteRulesetError extends Error {
+  constructor(message) {
+    super(message);
+    this.name = "RemoteRulesetError";
+  }
+}
+
+const resolveFormat = (name) => {
+  if (typeof name !== "string" || typeof formats[name] !== "function") {
+    throw new RemoteRulesetError(`Onbekend format "${name}".`);
+  }
+  return formats[name];
+};
+
+const resolveFormats = (value) => (Array.isArray(value) ? value.map(resolveFormat) : value);
+
+const toExtendEntries = (value) => {
+  if (!Array.isArray(value)) {
+    return [value];
+  }
+  const singleTuple = value.length === 2 && typeof value[0] === "string" && EXTEND_SEVERITIES.includes(value[1]);
+  return singleTuple ? [value] : value;
+};
+
+const resolveExtend = (entry) => {
+  const [name, severity] = Array.isArray(entry) ? entry : [entry];
+  const builtin = typeof name === "string" && name.startsWith(SPECTRAL_PREFIX);
+  const ruleset = builtin ? rulesets[name.slice(SPECTRAL_PREFIX.length)] : undefined;
+  if (!ruleset) {
+    throw new RemoteRulesetError(`Alleen ingebouwde Spectral rulesets kunnen worden uitgebreid, niet "${name}".`);
+  }
+  return severity === undefined ? ruleset : [ruleset, severity];
+};
+
+const resolveThen = (then, ruleName, available) => {
+  const name = then?.function;
+  if (typeof name !== "string" || typeof available[name] !== "function") {
+    throw new RemoteRulesetError(`Regel "${ruleName}" gebruikt onbekende functie "${name}".`);
+  }
+  return { ...then, function: available[name] };
+};
+
+const resolveRule = (rule, ruleName, available) => {
+  if (!rule || typeof rule !== "object" || rule.then === undefined) {
+    return rule;
+  }
+  const then = Array.isArray(rule.then)
+    ? rule.then.map((item) => resolveThen(item, ruleName, available))
+    : resolveThen(rule.then, ruleName, available);
+  return { ...rule, ...(rule.formats ? { formats: resolveFormats(rule.formats) } : {}), then };
+};
+
+/**
+ * De eigen functies van een geladen ruleset (zoals de meegeleverde ADR ruleset), op naam. Een
+ * gepubliceerde ruleset mag die functies bij naam gebruiken; code van buiten wordt nooit geladen.
+ */
+const collectRulesetFunctions = (ruleset) => {
+  const collected = {};
+  for (const rule of Object.values(ruleset?.rules || {})) {
+    const thens = Array.isArray(rule?.then) ? rule.then : [rule?.then];
+    for (const then of thens) {
+      if (typeof then?.function === "function" && then.function.name) {
+        collected[then.function.name] = then.function;
+      }
+    }
+  }
+  return collected;
+};
+
+/**
+ * Zet een Spectral ruleset in YAML/JSON-vorm om naar een definitie die `setRuleset` accepteert: namen
+ * van formats, functies en `spectral:` rulesets worden vervangen door de implementaties. Eigen functies
+ * (`functions`) moeten in `functions` meegegeven zijn, met dezelfde naam; `functionsDir` wordt niet
+ * geladen. Een ruleset met een functie die hier niet bestaat, wordt geweigerd.
+ */
+const resolveRulesetDefinition = (document, { functions: customFunctions = {} } = {}) => {
+  if (!document || typeof document !== "object" || Array.isArray(document)) {
+    throw new RemoteRulesetError("Ruleset moet een object zijn.");
+  }
+  const declared = Array.isArray(document.functions) ? document.functions : [];
+  const missing = declared.filter((name) => typeof customFunctions[name] !== "function");
+  if (missing.length > 0) {
+    throw new RemoteRulesetError(`Eigen functies die hier niet bestaan: ${missing.join(", ")}.`);
+  }
+  const available = { ...Object.fromEntries(declared.map((name) => [name, customFunctions[name]])), ...functions };
+  const { functions: _functions, functionsDir: _functionsDir, ...definition } = document;
+  if (document.extends !== undefined) {
+    definition.extends = toExtendEntries(document.extends).map(resolveExtend);
+  }
+  if (document.formats) {
+    definition.formats = resolveFormats(document.formats);
+  }
+  if (document.rules && typeof document.rules === "object") {
+    definition.rules = Object.fromEntries(
+      Object.entries(document.rules).map(([name, rule]) => [name, resolveRule(rule, name, available)]),
+    );
+  }
+  return definition;
+};
+
+/**
+ * Spectral-instantie voor de tekst van een gepubliceerde ruleset, met `functions` als eigen functies.
+ */
+const buildRemoteSpectral = (contents, functions) => {
+  const spectral = new Spectral({ resolver: createSpectralResolver() });
+  spectral.setRuleset(resolveRulesetDefinition(parseYaml(contents), { functions }));
+  return spectral;
+};
+
+const fetchRulesetContents = async (url) => {
+  const response = await fetch(url, { signal: AbortSignal.timeout(FETCH_TIMEOUT_MS) });
+  if (!response.ok) {
+    throw new RemoteRulesetError(`Server gaf status ${response.status}`);
+  }
+  const bytes = Buffer.from(await response.arrayBuffer());
+  return decodeSpecification(bytes, { contentType: response.headers.get("content-type") });
+};
+
+const resolveRefreshMs = () => {
+  const value = Number(process.env.ADR_RULESET_REFRESH_MS);
+  return Number.isFinite(value) && value > 0 ? value : DEFAULT_REFRESH_MS;
+};
+
+let current;
+let refreshTimer;
+
+/**
+ * Haalt de ruleset van `url` op en neemt die over als Spectral hem met `loadFunctions()` als eigen
+ * functies accepteert. Lukt dat niet, dan blijft de vorige versie in gebruik, of anders de meegeleverde
+ * ruleset; de reden staat in het log.
+ */
+const refreshRemoteRuleset = async (url, loadFunctions = async () => ({})) => {
+  try {
+    const contents = await fetchRulesetContents(url);
+    buildRemoteSpectral(contents, await loadFunctions());
+    const hash = crypto.createHash("sha256").update(contents).digest("hex");
+    if (current?.url !== url || current.hash !== hash) {
+      logger.info(`[RemoteRulesetService] ruleset van ${url} geladen (sha256 ${hash.slice(0, 12)})`);
+    }
+    current = { url, contents, hash, loadedAt: Date.now() };
+  } catch (error) {
+    const fallback =
+      current?.url === url ? "de vorige versie blijft in gebruik" : "de meegeleverde ruleset wordt gebruikt";
+    logger.warn(`[RemoteRulesetService] ruleset van ${url} niet gebruikt (${error.message}); ${fallback}`);
+  }
+  return getRemoteRuleset();
+};
+
+/**
+ * Haalt de ruleset van `ADR_RULESET_URL` direct en daarna elke `ADR_RULESET_REFRESH_MS` op, in de
+ * hoofdthread. Lint-workers halen zelf niets op: ze krijgen de tekst mee (zie `getRemoteRuleset`).
+ */
+const startRemoteRulesetRefresh = (loadFunctions) => {
+  const url = process.env.ADR_RULESET_URL;
+  if (!url || refreshTimer) {
+    return;
+  }
+  refreshRemoteRuleset(url, loadFunctions);
+  refreshTimer = setInterval(() => refreshRemoteRuleset(url, loadFunctions), resolveRefreshMs());
+  // De timer houdt het proces niet in leven.
+  refreshTimer.unref();
+};
+
+const stopRemoteRulesetRefresh = () => {
+  clearInterval(refreshTimer);
+  refreshTimer = undefined;
+};
+
+/**
+ * De laatst geaccepteerde ruleset van `ADR_RULESET_URL` als `{ url, contents, hash }`, of `undefined`
+ * als er geen URL is ingesteld of nog geen ruleset is geaccepteerd.
+ */
+const getRemoteRuleset = () => {
+  const url = process.env.ADR_RULESET_URL;
+  return url && current?.url === url ? { url, contents: current.contents, hash: current.hash } : undefined;
+};
+
+module.exports = {
+  RemoteRulesetError,
+  buildRemoteSpectral,
+  collectRulesetFunctions,
+  getRemoteRuleset,
+  refreshRemoteRuleset,
+  resolveRulesetDefinition,
+  startRemoteRulesetRefresh,
+  stopRemoteRulesetRefresh,
+};
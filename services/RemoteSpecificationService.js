@@ -1,9 +1,43 @@
 const { fetch } = require("@stoplight/spectral-runtime");
 const Service = require("./Service");
+const { decodeSpecification } = require("../utils/encoding");
+const { withHttpCache } = require("../utils/httpCache");
+const { InputTooLargeError, readLimitedBody } = require("../utils/inputSize");
+const {
+  checkUrl,
+  createOutboundAgent,
+  findOutboundPolicyError,
+  loadOutboundPolicy,
+} = require("../utils/outboundPolicy");
 const logger = require("../logger");
 
+// Eerst met deze Origin-header, daarna zonder, voor servers die alleen bekende origins toelaten.
+const ORIGIN = "https://developer.overheid.nl";
 const DEFAULT_ERROR_MESSAGE = "Het ophalen van de specificatie is mislukt.";
 const DEFAULT_TIMEOUT_MS = 45000;
+const MAX_REQUEST_HEADERS = 20;
+const MAX_REDIRECTS = 5;
+const REDIRECT_STATUSES = new Set([301, 302, 303, 307, 308]);
+const HEADER_NAME_PATTERN = /^[!#$%&'*+.^_`|~0-9A-Za-z-]+$/;
+// Headers die de fetch zelf bepaalt of die de verbinding beïnvloeden worden niet doorgegeven.
+const RESERVED_HEADERS = new Set([
+  "connection",
+  "content-length",
+  "host",
+  "keep-alive",
+  "origin",
+  "proxy-authorization",
+  "te",
+  "transfer-encoding",
+  "upgrade",
+]);
+
+// Elke URL van een aanroeper (en elke redirect) valt onder het beleid voor uitgaand verkeer; de agent
+// toetst bij het verbinden de adressen waar een hostnaam naar wijst.
+const outboundPolicy = loadOutboundPolicy();
+const outboundAgent = createOutboundAgent(outboundPolicy);
+// Specificaties die steeds opnieuw worden opgehaald (harvest, lint), komen met ETag/Last-Modified uit de cache.
+const cachedFetch = withHttpCache(fetch);
 
 const resolveTimeoutMs = () => {
   const envValue = Number(process.env.OAS_FETCH_TIMEOUT_MS);
@@ -19,10 +53,40 @@ const buildFetchOptions = (url) => {
   const timeoutId = setTimeout(() => controller.abort(), timeout);
   const options = {
     signal: controller.signal,
+    agent: outboundAgent,
+    redirect: "manual",
   };
   return { options, cleanup: () => clearTimeout(timeoutId), timeout };
 };
 
+/**
+ * Controleert de door de aanroeper meegegeven headers (bijv. Authorization of X-API-Key) voor het
+ * ophalen van een beveiligde specificatie. Waarden worden nooit gelogd.
+ */
+const normalizeRequestHeaders = (headers) => {
+  if (headers === undefined || headers === null) {
+    return {};
+  }
+  if (typeof headers !== "object" || Array.isArray(headers)) {
+    throw Service.rejectResponse({ message: "headers moet een object met header-namen en waarden zijn." }, 400);
+  }
+  const entries = Object.entries(headers);
+  if (entries.length > MAX_REQUEST_HEADERS) {
+    throw Service.rejectResponse({ message: `Geef maximaal ${MAX_REQUEST_HEADERS} headers mee.` }, 400);
+  }
+  const normalized = {};
+  for (const [name, value] of entries) {
+    if (!HEADER_NAME_PATTERN.test(name) || RESERVED_HEADERS.has(name.toLowerCase())) {
+      throw Service.rejectResponse({ message: `Header "${name}" is niet toegestaan.` }, 400);
+    }
+    if (typeof value !== "string" || /[\r\n]/.test(value)) {
+      throw Service.rejectResponse({ message: `De waarde van header "${name}" is ongeldig.` }, 400);
+    }
+    normalized[name] = value;
+  }
+  return normalized;
+};
+
 const normalizeErrorDetail = (error) => {
   const parts = [];
   if (error?.message) {
@@ -37,21 +101,36 @@ const normalizeErrorDetail = (error) => {
   return parts.join(" ").trim() || "Onbekende netwerkfout";
 };
 
-const doFetch = async (url, { origin }) => {
+const doFetch = async (url, { origin, requestHeaders }) => {
   const { options, cleanup, timeout } = buildFetchOptions(url);
   try {
-    const headers = {};
+    const headers = { ...requestHeaders };
     if (origin) {
       headers.Origin = origin;
     }
     options.headers = headers;
-    const response = await fetch(url, options);
+    let current = url;
+    let response = await cachedFetch(current, options);
+    for (let redirects = 0; REDIRECT_STATUSES.has(response.status); redirects += 1) {
+      const location = response.headers.get("location");
+      if (!location || redirects >= MAX_REDIRECTS) {
+        throw new Error(location ? "Te veel redirects" : `Redirect (${response.status}) zonder Location`);
+      }
+      current = new URL(location, current).toString();
+      checkUrl(current, outboundPolicy);
+      // Zoals fetch met Authorization doet: de headers van de aanroeper gaan niet mee naar een andere origin.
+      if (new URL(current).origin !== new URL(url).origin) {
+        options.headers = origin ? { Origin: origin } : {};
+      }
+      response = await cachedFetch(current, options);
+    }
     if (!response.ok) {
       const preview = await response.text().catch(() => "");
       const trimmed = preview ? preview.slice(0, 200) : "";
       throw new Error(`Server gaf status ${response.status}${trimmed ? `: ${trimmed}` : ""}`);
     }
-    return await response.text();
+    const bytes = await readLimitedBody(response);
+    return decodeSpecification(bytes, { contentType: response.headers.get("content-type") });
   } catch (error) {
     error.timeout = timeout;
     throw error;
@@ -60,14 +139,41 @@ const doFetch = async (url, { origin }) => {
   }
 };
 
-const fetchSpecification = async (url, { errorMessage = DEFAULT_ERROR_MESSAGE } = {}) => {
-  const origin = "https://developer.overheid.nl";
-  const attempts = origin ? [{ origin }, { origin: undefined }] : [{ origin: undefined }];
+const rejectOutbound = (error) =>
+  Service.rejectResponse({ message: "Deze URL mag niet worden opgehaald.", detail: error.message }, 400);
+
+const rejectTooLarge = (error) =>
+  Service.rejectResponse({ message: `De specificatie achter deze URL is te groot. ${error.message}` }, 413);
+
+/**
+ * Een Spectral-resolver voor externe `$ref`s die dezelfde agent gebruikt, zodat ook die verwijzingen
+ * onder het beleid voor uitgaand verkeer vallen.
+ */
+const createSpectralResolver = () => {
+  const { createHttpAndFileResolver } = require("@stoplight/spectral-ref-resolver");
+  return createHttpAndFileResolver({ agent: outboundAgent });
+};
+
+const fetchSpecification = async (url, { errorMessage = DEFAULT_ERROR_MESSAGE, headers } = {}) => {
+  const requestHeaders = normalizeRequestHeaders(headers);
+  try {
+    checkUrl(url, outboundPolicy);
+  } catch (error) {
+    throw rejectOutbound(error);
+  }
   let lastError;
-  for (const attempt of attempts) {
+  for (const attempt of [{ origin: ORIGIN }, { origin: undefined }]) {
     try {
-      return await doFetch(url, attempt);
+      return await doFetch(url, { ...attempt, requestHeaders });
     } catch (error) {
+      // Een hostnaam die naar een intern adres wijst, wordt pas bij het verbinden door de agent geweigerd.
+      const policyError = findOutboundPolicyError(error);
+      if (policyError) {
+        throw rejectOutbound(policyError);
+      }
+      if (error instanceof InputTooLargeError) {
+        throw rejectTooLarge(error);
+      }
       lastError = error;
       const detail = normalizeErrorDetail(error);
       logger.error(
@@ -91,5 +197,7 @@ const fetchSpecification = async (url, { errorMessage = DEFAULT_ERROR_MESSAGE }
 };
 
 module.exports = {
+  createSpectralResolver,
   fetchSpecification,
+  normalizeRequestHeaders,
 };
@@ -0,0 +1,157 @@
+const fs = require("node:fs");
+const config = require("../config");
+const logger = require("../logger");
+const { parseYaml } = require("../utils/yaml");
+
+const SEVERITY_LABELS = ["error", "warning", "info", "hint"];
+const DEFAULT_REFRESH_MS = 5 * 60 * 1000;
+const FETCH_TIMEOUT_MS = 10000;
+
+class ScoringPolicyError extends Error {
+  constructor(message) {
+    super(message);
+    this.name = "ScoringPolicyError";
+  }
+}
+
+const normalizeRuleCode = (code) => String(code || "").replace(/^nlgov:/, "");
+
+/**
+ * Zet het YAML-document om naar een beleid met een lookup van regelcode naar groep. Een ongeldig
+ * document wordt geweigerd zodat een typfout in een remote policy niet stilletjes alle scores
+ * op 100 zet.
+ */
+const parsePolicy = (document) => {
+  if (!document || typeof document !== "object" || Array.isArray(document)) {
+    throw new ScoringPolicyError("Scorebeleid moet een object zijn.");
+  }
+  const severities = document.severities === undefined ? ["error"] : document.severities;
+  if (!Array.isArray(severities) || severities.some((severity) => !SEVERITY_LABELS.includes(severity))) {
+    throw new ScoringPolicyError(`severities moet een lijst zijn met waarden uit: ${SEVERITY_LABELS.join(", ")}.`);
+  }
+  if (!document.groups || typeof document.groups !== "object" || Array.isArray(document.groups)) {
+    throw new ScoringPolicyError("groups ontbreekt in het scorebeleid.");
+  }
+  const warningWeight = document.warningWeight === undefined ? 0 : Number(document.warningWeight);
+  if (!Number.isFinite(warningWeight) || warningWeight < 0 || warningWeight > 1) {
+    throw new ScoringPolicyError("warningWeight moet een getal tussen 0 en 1 zijn.");
+  }
+  const groups = {};
+  const ruleGroups = new Map();
+  for (const [name, group] of Object.entries(document.groups)) {
+    const rules = Array.isArray(group?.rules) ? group.rules : [];
+    if (rules.length === 0 || rules.some((rule) => typeof rule !== "string" || !rule.trim())) {
+      throw new ScoringPolicyError(`Groep "${name}" moet een niet-lege lijst met regelcodes hebben.`);
+    }
+    const weight = group.weight === undefined ? 1 : Number(group.weight);
+    if (!Number.isFinite(weight) || weight <= 0) {
+      throw new ScoringPolicyError(`Groep "${name}" heeft een ongeldig gewicht.`);
+    }
+    groups[name] = { weight, rules: rules.map((rule) => normalizeRuleCode(rule.trim())) };
+    for (const rule of groups[name].rules) {
+      ruleGroups.set(rule, name);
+    }
+  }
+  return { severities: new Set(severities), warningWeight, groups, ruleGroups };
+};
+
+const loadPolicyFile = (filePath) => parsePolicy(parseYaml(fs.readFileSync(filePath, "utf8")));
+
+const fetchRemotePolicy = async (url) => {
+  const response = await fetch(url, { signal: AbortSignal.timeout(FETCH_TIMEOUT_MS) });
+  if (!response.ok) {
+    throw new ScoringPolicyError(`Server gaf status ${response.status}`);
+  }
+  return parsePolicy(parseYaml(await response.text()));
+};
+
+const resolveRefreshMs = () => {
+  const value = Number(process.env.SCORING_POLICY_REFRESH_MS);
+  return Number.isFinite(value) && value > 0 ? value : DEFAULT_REFRESH_MS;
+};
+
+let embeddedPolicy;
+let remoteCache;
+
+const getEmbeddedPolicy = () => {
+  if (!embeddedPolicy) {
+    embeddedPolicy = loadPolicyFile(process.env.SCORING_POLICY_PATH || config.SCORING_POLICY_FILE);
+  }
+  return embeddedPolicy;
+};
+
+/**
+ * Met `SCORING_POLICY_URL` wordt het beleid periodiek opgehaald. Als ophalen mislukt blijft het
+ * laatst geldige beleid in gebruik, en anders het meegeleverde bestand.
+ */
+const getScoringPolicy = async () => {
+  const url = process.env.SCORING_POLICY_URL;
+  if (!url) {
+    return getEmbeddedPolicy();
+  }
+  if (remoteCache && remoteCache.url === url && Date.now() - remoteCache.loadedAt < resolveRefreshMs()) {
+    return remoteCache.policy;
+  }
+  try {
+    const policy = await fetchRemotePolicy(url);
+    remoteCache = { url, policy, loadedAt: Date.now() };
+    return policy;
+  } catch (error) {
+    logger.warn(`[ScoringPolicyService] scorebeleid van ${url} niet geladen: ${error.message}`);
+    if (remoteCache && remoteCache.url === url) {
+      remoteCache.loadedAt = Date.now();
+      return remoteCache.policy;
+    }
+    return getEmbeddedPolicy();
+  }
+};
+
+const isMeasured = (code, policy) => policy.ruleGroups.has(normalizeRuleCode(code));
+
+const toScore = (lostWeight, totalWeight) =>
+  Math.max(0, Math.min(100, Math.round((1 - lostWeight / totalWeight) * 100)));
+
+/**
+ * `score` is de strikte score: alleen groepen met een bevinding uit `severities` tellen. Voor
+ * `weightedScore` kost een groep met alleen warnings daarnaast `warningWeight` × het groepsgewicht.
+ */
+const computeScore = (messages, policy) => {
+  const failedGroups = new Set();
+  const warnedGroups = new Set();
+  for (const message of messages) {
+    const group = policy.ruleGroups.get(normalizeRuleCode(message.code));
+    const severity = String(message.severity).toLowerCase();
+    if (!group) {
+      continue;
+    }
+    if (policy.severities.has(severity)) {
+      failedGroups.add(group);
+    } else if (severity === "warning") {
+      warnedGroups.add(group);
+    }
+  }
+  const weights = Object.entries(policy.groups);
+  if (weights.length === 0) {
+    return { score: 100, weightedScore: 100, failedGroups: [], warnedGroups: [] };
+  }
+  const onlyWarned = Array.from(warnedGroups).filter((group) => !failedGroups.has(group));
+  const weightOf = (names) => names.reduce((sum, name) => sum + policy.groups[name].weight, 0);
+  const totalWeight = weightOf(Object.keys(policy.groups));
+  const failedWeight = weightOf(Array.from(failedGroups));
+  const warningWeight = weightOf(onlyWarned) * (policy.warningWeight || 0);
+  return {
+    score: toScore(failedWeight, totalWeight),
+    weightedScore: toScore(failedWeight + warningWeight, totalWeight),
+    failedGroups: Array.from(failedGroups).sort(),
+    warnedGroups: onlyWarned.sort(),
+  };
+};
+
+module.exports = {
+  ScoringPolicyError,
+  computeScore,
+  getEmbeddedPolicy,
+  getScoringPolicy,
+  isMeasured,
+  parsePolicy,
+};
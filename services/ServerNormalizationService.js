@@ -0,0 +1,145 @@
+const { isIP } = require("node:net");
+const Service = require("./Service");
+const { resolveOasDocument, serializeOasDocument } = require("./OasInputService");
+const { formatPointer } = require("../utils/jsonPointer");
+const { collectServerLists, expandServerUrl } = require("../utils/openapi");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+const { isSwagger2 } = require("../utils/swagger");
+
+const OUTPUT_FORMATS = ["spec", "report"];
+const LOCAL_HOST = /(^|\.)localhost$/;
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+const stripTrailingSlash = (url) => {
+  const stripped = url.replace(/\/+$/, "");
+  return stripped === "" ? url : stripped;
+};
+
+const hostnameOf = (server) => {
+  try {
+    return new URL(expandServerUrl(server)).hostname.toLowerCase();
+  } catch {
+    // relatieve URL (zoals /v1) of URL met variabelen zonder default
+    return undefined;
+  }
+};
+
+const validateServerMap = (serverMap) => {
+  if (serverMap === undefined || serverMap === null) {
+    return new Map();
+  }
+  if (!isObject(serverMap) || Object.values(serverMap).some((url) => typeof url !== "string" || !url.trim())) {
+    throw Service.rejectResponse(
+      { message: "serverMap moet een object zijn van server-URL naar vervangende URL (een niet-lege string)." },
+      400,
+    );
+  }
+  return new Map(Object.entries(serverMap).map(([from, to]) => [stripTrailingSlash(from.trim()), to.trim()]));
+};
+
+/**
+ * Controleert en corrigeert alle `servers`-lijsten (document, pad en operatie). Per server, in deze
+ * volgorde: vervangen volgens `serverMap` (sleutel is de huidige URL, een slash aan het eind telt niet
+ * mee), `http://` wordt `https://` en slashes aan het eind gaan weg. Localhost en IP-adressen als host
+ * zijn waarschuwingen: daar is geen vervanging voor te raden. Pointers wijzen naar de `url` van de server.
+ */
+const normalizeServers = (spec, { serverMap } = {}) => {
+  const substitutions = validateServerMap(serverMap);
+  const used = new Set();
+  const changes = [];
+  const warnings = [];
+  const change = (type, at, from, to) =>
+    changes.push({ type, path: formatPointer(at), message: `${from} wordt ${to}.` });
+  for (const { servers, at } of collectServerLists(spec)) {
+    servers.forEach((server, index) => {
+      if (!isObject(server) || typeof server.url !== "string") {
+        return;
+      }
+      const urlAt = [...at, index, "url"];
+      const key = stripTrailingSlash(server.url);
+      if (substitutions.has(key)) {
+        used.add(key);
+        change("substituted", urlAt, server.url, substitutions.get(key));
+        server.url = substitutions.get(key);
+      }
+      if (/^http:\/\//i.test(server.url)) {
+        const url = server.url.replace(/^http:\/\//i, "https://");
+        change("https", urlAt, server.url, url);
+        server.url = url;
+      }
+      const stripped = stripTrailingSlash(server.url);
+      if (stripped !== server.url) {
+        change("trailing-slash", urlAt, server.url, stripped);
+        server.url = stripped;
+      }
+      const hostname = hostnameOf(server);
+      if (hostname && LOCAL_HOST.test(hostname)) {
+        warnings.push({
+          type: "localhost",
+          path: formatPointer(urlAt),
+          message: `${server.url} wijst naar localhost.`,
+        });
+      } else if (hostname && isIP(hostname.replace(/^\[|\]$/g, "")) !== 0) {
+        warnings.push({
+          type: "ip-literal",
+          path: formatPointer(urlAt),
+          message: `${server.url} gebruikt een IP-adres in plaats van een hostnaam.`,
+        });
+      }
+    });
+  }
+  for (const key of substitutions.keys()) {
+    if (!used.has(key)) {
+      warnings.push({ type: "unused-mapping", message: `Geen server met URL ${key} gevonden in de specificatie.` });
+    }
+  }
+  return { spec, changes, warnings };
+};
+
+const resolveOutputFormat = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return "spec";
+  }
+  const normalized = String(value).toLowerCase();
+  if (!OUTPUT_FORMATS.includes(normalized)) {
+    throw Service.rejectResponse(
+      { message: `Onbekend outputFormat "${value}". Kies uit: ${OUTPUT_FORMATS.join(", ")}.` },
+      400,
+    );
+  }
+  return normalized;
+};
+
+/**
+ * Geeft de specificatie met genormaliseerde servers terug in het formaat van de input, met het aantal
+ * wijzigingen en waarschuwingen in headers, of met `outputFormat=report` het rapport zelf.
+ */
+const normalize = async (input, { outputFormat, responseFormat } = {}) => {
+  const format = resolveOutputFormat(outputFormat);
+  const resolved = await resolveOasDocument(input);
+  if (isSwagger2(resolved.spec)) {
+    throw Service.rejectResponse(
+      { message: "Swagger 2.0 kent geen servers. Zet de specificatie eerst om met POST /v1/oas/convert." },
+      400,
+    );
+  }
+  const { spec, changes, warnings } = normalizeServers(resolved.spec, { serverMap: input.serverMap });
+  if (format === "report") {
+    const body = { changes, warnings };
+    return {
+      headers: { "Content-Type": "application/json" },
+      rawBody: Buffer.from(JSON.stringify(body, null, 2), "utf8"),
+    };
+  }
+  stampDocument(spec, buildProvenance({ tool: "oas-servers", source: resolved.source }));
+  const result = serializeOasDocument(spec, responseFormat ?? resolved.format, "openapi-servers");
+  result.headers["X-Server-Changes"] = String(changes.length);
+  result.headers["X-Server-Warnings"] = String(warnings.length);
+  return result;
+};
+
+module.exports = {
+  normalize,
+  normalizeServers,
+};
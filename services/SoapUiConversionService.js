@@ -0,0 +1,380 @@
+const { createHash } = require("node:crypto");
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { sanitizeFileName } = require("../utils/fileName");
+const {
+  buildJsonRequestSample,
+  collectAuthVariables,
+  collectOperations,
+  expandServerUrl,
+  operationName,
+  parameterExample,
+  resolveAuthScheme,
+} = require("../utils/openapi");
+const { buildProvenance, describeProvenance } = require("../utils/provenance");
+
+const DEFAULT_PROJECT_NAME = "soapui-project";
+const DEFAULT_BASE_URL = "http://localhost";
+const SOAPUI_VERSION = "5.7.0";
+const CONFIG_NAMESPACE = "http://eviware.com/soapui/config";
+const XSI_NAMESPACE = "http://www.w3.org/2001/XMLSchema-instance";
+const INDENT = "  ";
+
+const PARAMETER_STYLES = { path: "TEMPLATE", query: "QUERY", header: "HEADER" };
+
+const escapeXml = (value) =>
+  String(value ?? "")
+    .replace(/&/g, "&amp;")
+    .replace(/</g, "&lt;")
+    .replace(/>/g, "&gt;")
+    .replace(/"/g, "&quot;");
+
+// In CDATA is alleen `]]>` bijzonder; splits die over twee secties.
+const cdata = (text) => `<![CDATA[${String(text).replace(/]]>/g, "]]]]><![CDATA[>")}]]>`;
+
+const attributes = (values) =>
+  Object.entries(values)
+    .filter(([, value]) => value !== undefined)
+    .map(([name, value]) => ` ${name}="${escapeXml(value)}"`)
+    .join("");
+
+/**
+ * Klein XML-element: `children` is een lijst van regels (al ingesprongen elementen) of tekst. Zonder
+ * inhoud wordt het element zelfsluitend.
+ */
+const element = (name, attrs = {}, children = []) => {
+  if (typeof children === "string") {
+    return [`<con:${name}${attributes(attrs)}>${children}</con:${name}>`];
+  }
+  if (children.length === 0) {
+    return [`<con:${name}${attributes(attrs)}/>`];
+  }
+  return [
+    `<con:${name}${attributes(attrs)}>`,
+    ...children.map((line) => `${INDENT}${line}`),
+    `</con:${name}>`,
+  ];
+};
+
+const textElement = (name, value) => element(name, {}, escapeXml(value));
+
+/**
+ * SoapUI koppelt onderdelen via ids. Ze worden afgeleid van de inhoud, zodat een opnieuw
+ * gegenereerd project dezelfde ids houdt.
+ */
+const resourceId = (...parts) => {
+  const hex = createHash("sha256").update(parts.join("\u0000")).digest("hex");
+  return `${hex.slice(0, 8)}-${hex.slice(8, 12)}-${hex.slice(12, 16)}-${hex.slice(16, 20)}-${hex.slice(20, 32)}`;
+};
+
+const property = (name) => `\${#Project#${name}}`;
+
+/**
+ * Parameters van een operatie, inclusief de authenticatieheader (bearer of API-key) met een
+ * projectproperty als waarde. Basic auth gaat via de credentials van het request.
+ */
+const collectParameters = (document, entry, scheme) => {
+  const parameters = entry.parameters
+    .filter((parameter) => PARAMETER_STYLES[parameter.in])
+    .map((parameter) => ({
+      name: parameter.name,
+      style: PARAMETER_STYLES[parameter.in],
+      required: parameter.required === true,
+      value: parameterExample(document, parameter),
+      description: parameter.description,
+    }));
+  if (scheme?.type === "bearer") {
+    parameters.push({ name: "Authorization", style: "HEADER", required: true, value: `Bearer ${property("token")}` });
+  }
+  if (scheme?.type === "apiKey") {
+    parameters.push({ name: scheme.header, style: "HEADER", required: true, value: property("apiKey") });
+  }
+  return parameters;
+};
+
+const renderParameterDefinition = (parameter) =>
+  element("parameter", { required: parameter.required ? "true" : undefined }, [
+    ...textElement("name", parameter.name),
+    ...textElement("value", parameter.value ?? ""),
+    ...textElement("style", parameter.style),
+    ...textElement("default", parameter.value ?? ""),
+    ...(parameter.description ? textElement("description", parameter.description) : []),
+  ]);
+
+// Waarden per request staan als `entry` elementen in de config-namespace (zonder prefix).
+const parameterValue = ({ name, value }) =>
+  `<entry key="${escapeXml(name)}" value="${escapeXml(value)}" xmlns="${CONFIG_NAMESPACE}"/>`;
+
+const renderParameterValues = (parameters) =>
+  element(
+    "parameters",
+    {},
+    parameters.filter((parameter) => parameter.required && parameter.value !== "").map(parameterValue),
+  );
+
+const renderCredentials = (scheme) =>
+  element(
+    "credentials",
+    {},
+    scheme?.type === "basic"
+      ? [
+          ...textElement("username", property("username")),
+          ...textElement("password", property("password")),
+          ...textElement("selectedAuthProfile", "Basic"),
+          ...textElement("addedBasicAuthenticationTypes", "Basic"),
+          ...textElement("preemptive", "true"),
+          ...textElement("authType", "Global HTTP Settings"),
+        ]
+      : textElement("authType", "No Authorization"),
+  );
+
+const renderRequestBody = (sample) =>
+  element("request", {}, sample === undefined ? [] : cdata(JSON.stringify(sample, null, 2)));
+
+const successCodes = (operation) => Object.keys(operation.responses || {}).filter((code) => /^2\d\d$/.test(code));
+
+const renderMethod = (document, entry, { projectName, endpoint, synthesizeExamples }) => {
+  const { path, method, operation, requestBody } = entry;
+  const scheme = resolveAuthScheme(document, operation);
+  const parameters = collectParameters(document, entry, scheme);
+  const sample = buildJsonRequestSample(document, requestBody, { synthesize: synthesizeExamples });
+  const name = operationName(entry);
+  const representations = successCodes(operation).map((code) =>
+    element("representation", { type: "RESPONSE" }, [
+      ...textElement("mediaType", "application/json"),
+      ...textElement("status", code),
+      ...element("params"),
+    ]),
+  );
+  if (sample !== undefined) {
+    representations.push(
+      element("representation", { type: "REQUEST" }, [
+        ...textElement("mediaType", "application/json"),
+        ...element("params"),
+      ]),
+    );
+  }
+  return element("method", { name, id: resourceId(projectName, method, path), method: method.toUpperCase() }, [
+    ...element("settings"),
+    ...(operation.description ? textElement("description", operation.description) : []),
+    ...element(
+      "parameters",
+      {},
+      parameters.filter((parameter) => parameter.style !== "TEMPLATE").flatMap(renderParameterDefinition),
+    ),
+    ...representations.flat(),
+    ...element(
+      "request",
+      { name: "Request 1", id: resourceId(projectName, method, path, "request"), mediaType: "application/json" },
+      [
+        ...element("settings"),
+        ...textElement("endpoint", endpoint),
+        ...renderRequestBody(sample),
+        ...renderCredentials(scheme),
+        ...element("jmsConfig", { JMSDeliveryMode: "PERSISTENT" }),
+        ...element("jmsPropertyConfig"),
+        ...renderParameterValues(parameters),
+      ],
+    ),
+  ]);
+};
+
+/**
+ * Een testcase per operatie met een REST request-stap. Als de operatie expliciete 2xx-responses
+ * documenteert, controleert een "Valid HTTP Status Codes" assertion daarop.
+ */
+const renderTestCase = (document, entry, { projectName, endpoint, synthesizeExamples }) => {
+  const { path, method, operation, requestBody } = entry;
+  const scheme = resolveAuthScheme(document, operation);
+  const parameters = collectParameters(document, entry, scheme);
+  const sample = buildJsonRequestSample(document, requestBody, { synthesize: synthesizeExamples });
+  const name = operationName(entry);
+  const codes = successCodes(operation);
+  const assertions =
+    codes.length === 0
+      ? []
+      : element(
+          "assertion",
+          {
+            type: "Valid HTTP Status Codes",
+            id: resourceId(projectName, method, path, "status"),
+            name: "Valid HTTP Status Codes",
+          },
+          [`<con:configuration><codes>${codes.join(",")}</codes></con:configuration>`],
+        );
+  const restRequest = element(
+    "restRequest",
+    { name, id: resourceId(projectName, method, path, "test-request"), mediaType: "application/json" },
+    [
+      ...element("settings"),
+      ...textElement("endpoint", endpoint),
+      ...renderRequestBody(sample),
+      ...assertions,
+      ...renderCredentials(scheme),
+      ...element("jmsConfig", { JMSDeliveryMode: "PERSISTENT" }),
+      ...element("jmsPropertyConfig"),
+      ...renderParameterValues(parameters),
+    ],
+  );
+  return element(
+    "testCase",
+    {
+      id: resourceId(projectName, method, path, "test-case"),
+      failOnError: "true",
+      failTestCaseOnErrors: "true",
+      keepSession: "false",
+      maxResults: "0",
+      name,
+      searchProperties: "true",
+    },
+    [
+      ...element("settings"),
+      ...element("testStep", { type: "restrequest", name, id: resourceId(projectName, method, path, "test-step") }, [
+        ...element("settings"),
+        ...element(
+          "config",
+          {
+            service: projectName,
+            methodName: name,
+            resourcePath: path,
+            "xsi:type": "con:RestRequestStep",
+            "xmlns:xsi": XSI_NAMESPACE,
+          },
+          restRequest,
+        ),
+      ]),
+      ...element("properties"),
+    ],
+  );
+};
+
+/**
+ * Zet een OpenAPI document om naar een SoapUI/ReadyAPI projectbestand: een REST-service met de
+ * servers als endpoints, een resource per pad en een methode per operatie (met parameters en een
+ * voorbeeldrequest), plus een testsuite met een testcase per operatie. Tokens, API-keys en basic
+ * auth gebruiken projectproperties (`${#Project#token}` enzovoort) die leeg in het project staan.
+ */
+const buildProject = (document, { source, synthesizeExamples = true } = {}) => {
+  const title = typeof document.info?.title === "string" && document.info.title.trim();
+  const projectName = title || DEFAULT_PROJECT_NAME;
+  const operations = collectOperations(document);
+  const servers = Array.isArray(document.servers) && document.servers.length > 0 ? document.servers : [{}];
+  const endpoints = [...new Set(servers.map((server) => expandServerUrl(server) || DEFAULT_BASE_URL))];
+  const context = { projectName, endpoint: endpoints[0], synthesizeExamples };
+
+  const resources = new Map();
+  for (const entry of operations) {
+    if (!resources.has(entry.path)) {
+      resources.set(entry.path, []);
+    }
+    resources.get(entry.path).push(entry);
+  }
+  const renderedResources = [...resources.entries()].map(([path, entries]) => {
+    const pathParameters = collectParameters(document, entries[0], undefined).filter(
+      (parameter) => parameter.style === "TEMPLATE",
+    );
+    return element("resource", { name: path, path, id: resourceId(projectName, path) }, [
+      ...element("settings"),
+      ...element("parameters", {}, pathParameters.flatMap(renderParameterDefinition)),
+      ...entries.flatMap((entry) => renderMethod(document, entry, context)),
+    ]);
+  });
+
+  const service = element(
+    "interface",
+    {
+      "xsi:type": "con:RestService",
+      id: resourceId(projectName, "service"),
+      wadlVersion: "http://wadl.dev.java.net/2009/02",
+      name: projectName,
+      type: "rest",
+      "xmlns:xsi": XSI_NAMESPACE,
+    },
+    [
+      ...element("settings"),
+      ...element("definitionCache"),
+      ...element("endpoints", {}, endpoints.flatMap((endpoint) => textElement("endpoint", endpoint))),
+      ...renderedResources.flat(),
+    ],
+  );
+
+  const testSuite = element("testSuite", { id: resourceId(projectName, "test-suite"), name: `${projectName} tests` }, [
+    ...element("settings"),
+    ...textElement("runType", "SEQUENTIAL"),
+    ...operations.flatMap((entry) => renderTestCase(document, entry, context)),
+    ...element("properties"),
+  ]);
+
+  const properties = element(
+    "properties",
+    {},
+    collectAuthVariables(document, operations).flatMap((name) =>
+      element("property", {}, [...textElement("name", name), ...textElement("value", "")]),
+    ),
+  );
+
+  const project = element(
+    "soapui-project",
+    {
+      id: resourceId(projectName, "project"),
+      activeEnvironment: "Default",
+      name: projectName,
+      resourceRoot: "",
+      "soapui-version": SOAPUI_VERSION,
+      abortOnError: "false",
+      runType: "SEQUENTIAL",
+      "xmlns:con": CONFIG_NAMESPACE,
+    },
+    [
+      ...element("settings"),
+      ...service,
+      ...testSuite,
+      ...properties,
+      ...element("wssContainer"),
+      ...element("oAuth2ProfileContainer"),
+      ...element("oAuth1ProfileContainer"),
+    ],
+  );
+
+  const provenance = describeProvenance(buildProvenance({ tool: "oas-soapui", source })).replace(/--/g, "- -");
+  return {
+    projectName,
+    xml: ['<?xml version="1.0" encoding="UTF-8"?>', `<!-- ${provenance} -->`, ...project, ""].join("\n"),
+  };
+};
+
+const convert = async (input) => {
+  const resolved = await resolveOasDocument(input);
+  let result;
+  try {
+    result = buildProject(resolved.spec, {
+      source: resolved.source,
+      synthesizeExamples: input.synthesizeExamples !== false,
+    });
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Conversie naar SoapUI is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const filenameBase = sanitizeFileName(result.projectName, {
+    fallback: DEFAULT_PROJECT_NAME,
+    lowercase: true,
+  });
+
+  return {
+    headers: {
+      "Content-Type": "application/xml; charset=utf-8",
+      "Content-Disposition": `attachment; filename="${filenameBase}-soapui-project.xml"`,
+    },
+    rawBody: Buffer.from(result.xml, "utf8"),
+  };
+};
+
+module.exports = {
+  buildProject,
+  convert,
+};
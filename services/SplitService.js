@@ -0,0 +1,178 @@
+const path = require("node:path");
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { sanitizeFileName, uniqueFileName } = require("../utils/fileName");
+const { decodePointerSegment, encodePointerSegment } = require("../utils/jsonPointer");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+const { dumpYaml } = require("../utils/yaml");
+const { createZip } = require("../utils/zip");
+
+const DEFAULT_FILENAME = "openapi";
+const ROOT_FILE = "openapi.yaml";
+const PATH_ITEM_SECTIONS = ["paths", "webhooks"];
+
+// Een $ref met een scheme (https:, urn:, ...) is absoluut en hoeft na het verplaatsen niet te veranderen.
+const ABSOLUTE_REF = /^[a-z][a-z0-9+.-]*:/i;
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+// `/dieren/{id}` wordt `dieren_{id}`, zoals bij `redocly split`; het rootpad `/` wordt `root`.
+const pathFileName = (route) => {
+  const name = route
+    .replace(/^\/+|\/+$/g, "")
+    .replace(/\//g, "_")
+    .replace(/[^0-9A-Za-z._{}-]+/g, "-");
+  return name || "root";
+};
+
+/**
+ * Bepaalt voor elke path item (in `paths` en `webhooks`) en elk component een eigen bestand:
+ * `paths/<pad>.yaml`, `webhooks/<naam>.yaml` en `components/<sectie>/<naam>.yaml`.
+ */
+const assignFiles = (spec) => {
+  const files = new Map();
+  const usedNames = new Map();
+  const register = (segments, directory, base) => {
+    if (!usedNames.has(directory)) {
+      usedNames.set(directory, new Set());
+    }
+    const name = `${directory}/${uniqueFileName(usedNames.get(directory), base)}.yaml`;
+    files.set(segments.map(encodePointerSegment).join("/"), name);
+  };
+  for (const section of PATH_ITEM_SECTIONS) {
+    for (const [key, item] of Object.entries(isObject(spec[section]) ? spec[section] : {})) {
+      if (isObject(item)) {
+        const base = section === "paths" ? pathFileName(key) : sanitizeFileName(key, { fallback: "webhook" });
+        register([section, key], section, base);
+      }
+    }
+  }
+  for (const [section, entries] of Object.entries(isObject(spec.components) ? spec.components : {})) {
+    for (const [key, entry] of Object.entries(isObject(entries) ? entries : {})) {
+      if (isObject(entry)) {
+        register(["components", section, key], `components/${section}`, sanitizeFileName(key, { fallback: section }));
+      }
+    }
+  }
+  return (segments) => files.get(segments.map(encodePointerSegment).join("/"));
+};
+
+// Zoekt het bestand waarin het doel van een lokale $ref terechtkomt, en de pointer binnen dat bestand.
+const locateTarget = (fileOf, ref) => {
+  const segments = ref.slice(1).split("/").slice(1).map(decodePointerSegment);
+  for (const length of [3, 2]) {
+    const file = segments.length >= length ? fileOf(segments.slice(0, length)) : undefined;
+    if (file) {
+      return { file, pointer: segments.slice(length) };
+    }
+  }
+  return { file: ROOT_FILE, pointer: segments };
+};
+
+const rewriteRef = (fileOf, ref, fromFile) => {
+  const fromDirectory = path.posix.dirname(fromFile);
+  if (ref.startsWith("#")) {
+    const { file, pointer } = locateTarget(fileOf, ref);
+    const relative = path.posix.relative(fromDirectory, file);
+    if (pointer.length === 0) {
+      return relative;
+    }
+    return `${file === fromFile ? "" : relative}#/${pointer.map(encodePointerSegment).join("/")}`;
+  }
+  if (ABSOLUTE_REF.test(ref) || fromDirectory === ".") {
+    return ref;
+  }
+  // Relatieve verwijzingen naar externe bestanden gingen uit van de map van het hoofddocument.
+  const [target, ...fragment] = ref.split("#");
+  const relative = path.posix.relative(fromDirectory, path.posix.normalize(target));
+  return [relative, ...fragment].join("#");
+};
+
+const rewriteRefs = (fileOf, value, fromFile) => {
+  if (Array.isArray(value)) {
+    return value.map((child) => rewriteRefs(fileOf, child, fromFile));
+  }
+  if (!isObject(value)) {
+    return value;
+  }
+  return Object.fromEntries(
+    Object.entries(value).map(([key, child]) => {
+      if (key === "$ref" && typeof child === "string") {
+        return [key, rewriteRef(fileOf, child, fromFile)];
+      }
+      // Een schema-example is data; een property die `$ref` heet is daar geen verwijzing.
+      return [key, key === "example" ? child : rewriteRefs(fileOf, child, fromFile)];
+    }),
+  );
+};
+
+/**
+ * Splitst een OpenAPI document op in een multi-file layout: `openapi.yaml` met de algemene velden,
+ * een bestand per path item en een bestand per component. Elke interne `$ref` wordt een relatieve
+ * verwijzing naar het bestand waar het doel nu staat, zodat het geheel weer te bundelen is.
+ */
+const splitDocument = (spec) => {
+  const fileOf = assignFiles(spec);
+  const files = [];
+  const root = {};
+  const extract = (segments, value) => {
+    const file = fileOf(segments);
+    if (!file) {
+      return rewriteRefs(fileOf, value, ROOT_FILE);
+    }
+    files.push({ name: file, data: dumpYaml(rewriteRefs(fileOf, value, file)) });
+    return { $ref: file };
+  };
+  for (const [key, value] of Object.entries(spec)) {
+    if (PATH_ITEM_SECTIONS.includes(key) && isObject(value)) {
+      root[key] = Object.fromEntries(Object.entries(value).map(([name, item]) => [name, extract([key, name], item)]));
+    } else if (key === "components" && isObject(value)) {
+      root[key] = Object.fromEntries(
+        Object.entries(value).map(([section, entries]) => [
+          section,
+          isObject(entries)
+            ? Object.fromEntries(
+                Object.entries(entries).map(([name, entry]) => [name, extract([key, section, name], entry)]),
+              )
+            : entries,
+        ]),
+      );
+    } else {
+      root[key] = rewriteRefs(fileOf, value, ROOT_FILE);
+    }
+  }
+  return { root, files };
+};
+
+const split = async (input) => {
+  const resolved = await resolveOasDocument(input);
+  let result;
+  try {
+    result = splitDocument(resolved.spec);
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Opsplitsen van de specificatie is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const root = stampDocument(result.root, buildProvenance({ tool: "oas-split", source: resolved.source }));
+  const title = typeof resolved.spec.info?.title === "string" ? resolved.spec.info.title : "";
+  const filenameBase = sanitizeFileName(title, { fallback: DEFAULT_FILENAME, lowercase: true });
+  const files = [{ name: ROOT_FILE, data: dumpYaml(root) }, ...result.files];
+
+  return {
+    headers: {
+      "Content-Type": "application/zip",
+      "Content-Disposition": `attachment; filename="${filenameBase}-split.zip"`,
+    },
+    rawBody: createZip(files.map((file) => ({ ...file, name: `${filenameBase}/${file.name}` }))),
+  };
+};
+
+module.exports = {
+  split,
+  splitDocument,
+};
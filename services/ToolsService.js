@@ -1,11 +1,56 @@
 /* eslint-disable no-unused-vars */
+const { randomUUID } = require("node:crypto");
 const Service = require("./Service");
 const OasConversionService = require("./OasConversionService");
 const OasBundleService = require("./OasBundleService");
+const SplitService = require("./SplitService");
+const ComponentLibraryService = require("./ComponentLibraryService");
+const OasFilterService = require("./OasFilterService");
+const OasPruneService = require("./OasPruneService");
+const OasFormatService = require("./OasFormatService");
+const OasReformatService = require("./OasReformatService");
+const OasDiffService = require("./OasDiffService");
+const RedactionService = require("./RedactionService");
+const OasStatsService = require("./OasStatsService");
+const ServerNormalizationService = require("./ServerNormalizationService");
+const TranslationService = require("./TranslationService");
+const PolymorphismService = require("./PolymorphismService");
+const AdrScaffoldService = require("./AdrScaffoldService");
 const OasValidatorService = require("./OasValidatorService");
+const OasStructureService = require("./OasStructureService");
+const LintReportService = require("./LintReportService");
+const LintDiffService = require("./LintDiffService");
+const LintFixService = require("./LintFixService");
 const OasGeneratorService = require("./OasGeneratorService");
 const PostmanConversionService = require("./PostmanConversionService");
+const BrunoConversionService = require("./BrunoConversionService");
+const InsomniaConversionService = require("./InsomniaConversionService");
+const HurlConversionService = require("./HurlConversionService");
+const HttpFileConversionService = require("./HttpFileConversionService");
+const CurlConversionService = require("./CurlConversionService");
+const HttpieConversionService = require("./HttpieConversionService");
+const RedocDocsService = require("./RedocDocsService");
+const DocsSiteService = require("./DocsSiteService");
+const ReferenceDocsService = require("./ReferenceDocsService");
+const LandingPageService = require("./LandingPageService");
+const ExampleGenerationService = require("./ExampleGenerationService");
+const OverlayService = require("./OverlayService");
+const CodegenService = require("./CodegenService");
+const TypeScriptTypesService = require("./TypeScriptTypesService");
+const MockBundleService = require("./MockBundleService");
+const HostedMockService = require("./HostedMockService");
+const PostmanImportService = require("./PostmanImportService");
+const BrunoImportService = require("./BrunoImportService");
+const HarImportService = require("./HarImportService");
+const SoapUiConversionService = require("./SoapUiConversionService");
+const KarateConversionService = require("./KarateConversionService");
 const ArazzoVisualizationService = require("./ArazzoVisualizationService");
+const ArazzoTestGeneratorService = require("./ArazzoTestGeneratorService");
+const ArazzoLintService = require("./ArazzoLintService");
+const WebhookService = require("./WebhookService");
+const { getLintRunStore, persistLintRun } = require("./LintRunStoreService");
+const { negotiateDocumentFormat } = require("./OasInputService");
+const OasResponseCoverageService = require("./OasResponseCoverageService");
 const { KeycloakService, parseUntrustClientInput, translateKeycloakError } = require("./KeycloakService");
 const logger = require("../logger");
 
@@ -89,17 +134,1193 @@ const arazzoMermaid = async (params) =>
     contentType: CONTENT_TYPE_TEXT,
   });
 
+/**
+ * Arazzo lint (POST)
+ * Valideert een Arazzo specificatie met de DON workflow-regels: verplichte en unieke workflowIds en stepIds en
+ * operationIds die in de sourceDescriptions (of het meegegeven OpenAPI-document) voorkomen.
+ *
+ * arazzoLintInput ArazzoLintInput  (optional)
+ * returns ModelsArazzoLintResult
+ */
+const arazzoLint = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "arazzoLint", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await ArazzoLintService.lint(requestPayload);
+    return Service.successResponse(result);
+  } catch (e) {
+    logServiceError("arazzoLint", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Arazzo tests (POST)
+ * Genereert uitvoerbare integratietests (Jest of Go) uit de workflows van een Arazzo specificatie.
+ *
+ * arazzoTestInput ArazzoTestInput  (optional)
+ * no response value expected for this operation
+ */
+const generateArazzoTests = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "generateArazzoTests", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await ArazzoTestGeneratorService.generate(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("generateArazzoTests", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
 /**
  * Converteer OpenAPI 3.0/3.1
- * Converteert standaard naar 3.1. Geef targetVersion (3.0 of 3.1) mee om een doelversie te forceren. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ * Converteert standaard naar 3.1. Geef targetVersion (2.0, 3.0 of 3.1) mee om een doelversie te forceren. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+// const convertOAS = async ({ oASInput }) => {
+const convertOAS = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "convertOAS", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await OasConversionService.convert(requestPayload, {
+      responseFormat: negotiateDocumentFormat(params),
+    });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("convertOAS", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Maak Postman-collectie (POST)
+ * Converteert OpenAPI naar een ZIP met een Postman Collection (v2.1) en een Postman environment per server, met baseUrl en variabelen voor de security schemes (apiKey, bearerToken, tokenUrl, ...). Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+// const createPostmanCollection = async ({ oASInput }) => {
+const createPostmanCollection = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createPostmanCollection", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await PostmanConversionService.convert(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createPostmanCollection", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Maak Bruno-collectie (POST)
+ * Converteert OpenAPI naar een Bruno collectie (ZIP met .bru bestanden per tag en een environment per server). Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const createBrunoCollection = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createBrunoCollection", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await BrunoConversionService.convert(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createBrunoCollection", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Maak Insomnia-export (POST)
+ * Converteert OpenAPI naar een Insomnia v4 export (JSON) met een request group per tag en een environment per server. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const createInsomniaExport = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createInsomniaExport", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await InsomniaConversionService.convert(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createInsomniaExport", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Maak Hurl-tests (POST)
+ * Genereert een ZIP met een .hurl bestand per operatie (asserts op status en API-Version header) en een hurl.env met variabelen. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const createHurlTests = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createHurlTests", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await HurlConversionService.convert(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createHurlTests", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Maak .http-bestanden (POST)
+ * Genereert een ZIP met VS Code REST Client bestanden: een .http bestand per tag en .vscode/settings.json met een environment per server en variabelen voor authenticatie. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const createHttpFiles = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createHttpFiles", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await HttpFileConversionService.convert(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createHttpFiles", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Maak curl-scripts (POST)
+ * Genereert curl-commando's per operatie, met voorbeeld-bodies en verplichte headers: één bash-script met een functie per operatie, of met outputFormat=zip een ZIP met een script per operatie. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * outputFormat String script (standaard) of zip  (optional)
+ * no response value expected for this operation
+ */
+const createCurlScripts = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createCurlScripts", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await CurlConversionService.convert(requestPayload, { outputFormat: params?.outputFormat });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createCurlScripts", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Maak HTTPie-scripts (POST)
+ * Genereert HTTPie-aanroepen per operatie, met voorbeeld-bodies en verplichte headers: één bash-script met een functie per operatie, of met outputFormat=zip een ZIP met een script per operatie. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * outputFormat String script (standaard) of zip  (optional)
+ * no response value expected for this operation
+ */
+const createHttpieScripts = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createHttpieScripts", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await HttpieConversionService.convert(requestPayload, { outputFormat: params?.outputFormat });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createHttpieScripts", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Postman-collectie naar OpenAPI (POST)
+ * Reconstrueert een OpenAPI 3.0 skelet (paden, methodes, parameters, request bodies en responses met uit voorbeelden afgeleide schema's) uit een Postman collectie (v2.0 of v2.1). Body: { oasUrl } of { oasBody } (stringified Postman collectie JSON).
  *
  * oASInput OASInput  (optional)
  * no response value expected for this operation
  */
-// const convertOAS = async ({ oASInput }) => {
-const convertOAS = async (params) => {
+const convertPostmanToOAS = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "convertPostmanToOAS", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await PostmanImportService.convert(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("convertPostmanToOAS", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Bruno-collectie naar OpenAPI (POST)
+ * Genereert een OpenAPI 3.0 skelet uit de .bru bestanden van een Bruno collectie: mappen worden tags, params en headers worden parameters en bodies leveren schema's. Body: { archive } (de collectie als base64-gecodeerde ZIP).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const convertBrunoToOAS = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "convertBrunoToOAS", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await BrunoImportService.convert(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("convertBrunoToOAS", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * HAR naar OpenAPI (POST)
+ * Leidt een OpenAPI 3.0 skelet (paden met padparameters, methodes, parameters en uit de voorbeelden afgeleide schema's) af uit een HAR-opname van de browser. Body: { oasUrl } of { oasBody } (stringified HAR JSON).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const convertHarToOAS = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "convertHarToOAS", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await HarImportService.convert(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("convertHarToOAS", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Maak SoapUI-project (POST)
+ * Genereert een SoapUI/ReadyAPI projectbestand (XML) met een REST-service (resource per pad, methode per operatie met parameters en voorbeeldrequest) en een testsuite met een testcase per operatie. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const createSoapUiProject = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createSoapUiProject", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await SoapUiConversionService.convert(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createSoapUiProject", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Maak Karate-tests (POST)
+ * Genereert een ZIP met een Karate feature-bestand per operatie (verwachte status, API-Version header en een schema-match op de JSON response) en karate-config.js. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const createKarateTests = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createKarateTests", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await KarateConversionService.convert(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createKarateTests", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Maak Redoc-documentatie (POST)
+ * Genereert één zelfstandig HTML-bestand dat de OpenAPI specificatie met Redoc toont, zonder externe scripts of fonts. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const createRedocDocs = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createRedocDocs", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await RedocDocsService.buildDocs(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createRedocDocs", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Maak documentatiesite (POST)
+ * Genereert een ZIP met een statische documentatiesite (index.html, spec.js en openapi.json) met Swagger UI of Stoplight Elements, te kiezen met renderer. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const createDocsSite = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createDocsSite", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await DocsSiteService.convert(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createDocsSite", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Maak API-referentie (POST)
+ * Genereert een API-referentie in Markdown, AsciiDoc, HTML of PDF (outputFormat): een sectie per tag met per operatie tabellen voor parameters, request body en responses, en een bijlage met de schema's. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * outputFormat String markdown (standaard), asciidoc, html of pdf  (optional)
+ * no response value expected for this operation
+ */
+const createReferenceDocs = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createReferenceDocs", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await ReferenceDocsService.convert(requestPayload, { outputFormat: params?.outputFormat });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createReferenceDocs", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Genereer Go-server (POST)
+ * Genereert met oapi-codegen een Go-server (Gin of Echo, te kiezen met framework) als ZIP: modellen en routering, stubs per operatie die 501 teruggeven en een main.go. OpenAPI 3.1 wordt eerst naar 3.0 omgezet. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const createGoServer = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createGoServer", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await CodegenService.generate(requestPayload, { language: "go-server" });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createGoServer", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Genereer code (POST)
+ * Genereert code uit een OpenAPI document als ZIP, met language: go-server (Go-server met oapi-codegen, zie /v1/codegen/go-server), python (Python-client met httpx en pydantic-modellen), java (Java-client met Spring WebClient) of csharp (.NET-client), beide via openapi-generator. OpenAPI 3.1 wordt eerst naar 3.0 omgezet. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * language String go-server, python, java of csharp
+ * no response value expected for this operation
+ */
+const createCode = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createCode", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await CodegenService.generate(requestPayload, { language: params?.language });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createCode", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Genereer TypeScript-types (POST)
+ * Genereert TypeScript-typedefinities (.d.ts) in de stijl van openapi-typescript: paths, components en operations als interfaces en een type-alias per schema. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const createTypeScriptTypes = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createTypeScriptTypes", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await TypeScriptTypesService.convert(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createTypeScriptTypes", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Maak Prism mock-setup (POST)
+ * Genereert een ZIP met een kant-en-klare Prism mock-server: de gebundelde specificatie zonder verwijzingen, een docker-compose.yml, een mock.sh voor npx en een README. Met mockDynamic genereert Prism responses uit de schema's. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const createPrismMock = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createPrismMock", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await MockBundleService.convert(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createPrismMock", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Start gehoste mock-server (POST)
+ * Start een tijdelijke mock-server voor de specificatie, bereikbaar onder /v1/mock/{mockId}/.
+ *
+ * oASInput OASInput  (optional)
+ * returns HostedMock
+ */
+const createHostedMock = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createHostedMock", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const mock = await HostedMockService.createMock(requestPayload);
+    return {
+      code: 201,
+      headers: { Location: mock.url },
+      payload: mock,
+    };
+  } catch (e) {
+    logServiceError("createHostedMock", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Stop gehoste mock-server (DELETE)
+ * Stopt een gehoste mock-server voordat de TTL is verstreken.
+ *
+ * mockId String
+ * no response value expected for this operation
+ */
+const deleteHostedMock = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "deleteHostedMock", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    HostedMockService.removeMock(params?.mockId);
+    return Service.successResponse("", 204);
+  } catch (e) {
+    logServiceError("deleteHostedMock", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Beantwoordt een request aan een gehoste mock-server. Deze requests staan niet in openapi.json
+ * (de paden komen uit de gemockte specificatie) en lopen daarom niet via de auto-mocks.
+ */
+const serveHostedMock = async ({ mockId, ...request }) => {
+  try {
+    return HostedMockService.handleRequest(mockId, request);
+  } catch (e) {
+    logServiceError("serveHostedMock", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Maak landingspagina (POST)
+ * Genereert een ZIP met een statische landingspagina om op de base-URL van de API te hosten: index.html met info, servers, contact en de ADR-score, badge.svg met die score, en de specificatie en de Postman- en Bruno-collecties om te downloaden. De lint-opties (ruleset, targetVersion, ignoreRules) gelden ook voor de score. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const createLandingPage = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createLandingPage", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await LandingPageService.convert(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createLandingPage", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Genereer voorbeelden (POST)
+ * Genereert realistische voorbeeldpayloads voor alle schema's, met respect voor formats, enums, patterns, lengtes en grenzen: als JSON-map van schemanaam naar voorbeeld (outputFormat=map), of in de specificatie gezet waar voorbeelden ontbreken (outputFormat=spec, in het formaat van de input). Met writeBack=true komt de specificatie terug met alleen een voorbeeld op elke request- en responsebody die er geen had. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * outputFormat String map (standaard) of spec  (optional)
+ * writeBack Boolean alleen request- en responsebodies aanvullen  (optional)
+ * no response value expected for this operation
+ */
+const createExamples = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "createExamples", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await ExampleGenerationService.convert(requestPayload, {
+      outputFormat: params?.outputFormat,
+      writeBack: params?.writeBack,
+      responseFormat: negotiateDocumentFormat(params),
+    });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("createExamples", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Pas overlay toe (POST)
+ * Past een OpenAPI Overlay 1.0 document toe op de specificatie en geeft de aangepaste specificatie terug in het formaat van de input. Elke actie selecteert nodes met een JSONPath-target en voegt update daarin samen of verwijdert ze met remove: true. Body: { oasUrl|oasBody, overlayUrl|overlayBody }.
+ *
+ * overlayInput OverlayInput  (optional)
+ * no response value expected for this operation
+ */
+const applyOverlay = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "applyOverlay", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await OverlayService.apply(requestPayload, { responseFormat: negotiateDocumentFormat(params) });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("applyOverlay", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Bundle OpenAPI
+ * Maakt één gebundeld OpenAPI document met opgeloste verwijzingen. Standaard (mode=dereference) wordt elke verwijzing uitgeschreven; met mode=bundle komen externe verwijzingen als componenten in het document en wijzen de $refs daarnaar. Met shareSchemas=true worden gelijke schema's na het uitschrijven weer gedeeld via components. Body: { oasUrl } of { oasBody }, optioneel met refHeaders voor externe $refs achter een gateway en refHttp voor de timeout, herhalingen en redirects bij het ophalen.
+ *
+ * oASInput OASInput  (optional)
+ * mode String dereference of bundle  (optional)
+ * onCycle String ref of error, bij een kringverwijzing in mode=dereference  (optional)
+ * scope String all of internal; internal schrijft alleen lokale verwijzingen uit  (optional)
+ * shareSchemas Boolean true brengt gelijke schema's na het uitschrijven terug naar één component  (optional)
+ * outputFormat String spec of manifest; manifest geeft de opgehaalde externe documenten  (optional)
+ * no response value expected for this operation
+ */
+const bundleOAS = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "bundleOAS", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await OasBundleService.bundle(requestPayload, {
+      mode: params?.mode,
+      onCycle: params?.onCycle,
+      scope: params?.scope,
+      shareSchemas: params?.shareSchemas,
+      outputFormat: params?.outputFormat,
+      responseFormat: negotiateDocumentFormat(params),
+    });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("bundleOAS", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Split OpenAPI
+ * Splitst een OpenAPI specificatie op in een ZIP met openapi.yaml, een bestand per pad en een bestand per component, met relatieve verwijzingen. Body: { oasUrl } of { oasBody }.
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const splitOAS = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "splitOAS", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await SplitService.split(requestPayload);
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("splitOAS", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Extract shared components
+ * Zoekt structureel gelijke schema's in meerdere specificaties van één organisatie en haalt ze naar een gedeeld components.yaml. Geeft een ZIP met dat bestand en de specificaties met verwijzingen ernaar, of met outputFormat=report een JSON-overzicht van de gedeelde schema's. Body: { specifications: [{ name, oasUrl|oasBody }], minSpecifications }.
+ *
+ * oasSharedComponentsInput OasSharedComponentsInput  (optional)
+ * no response value expected for this operation
+ */
+const extractSharedComponents = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "extractSharedComponents", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await ComponentLibraryService.extract(requestPayload, { outputFormat: params?.outputFormat });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("extractSharedComponents", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Filter OpenAPI
+ * Geeft een deelverzameling van de specificatie terug op basis van tags, pad-globs, methods en x-internal, bijvoorbeeld een publieke versie van een interne specificatie. Body: { oasUrl|oasBody, includeTags, excludeTags, includePaths, excludePaths, includeMethods, excludeMethods, excludeInternal, removeUnusedComponents }.
+ *
+ * oasFilterInput OasFilterInput  (optional)
+ * no response value expected for this operation
+ */
+const filterOAS = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "filterOAS", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await OasFilterService.filter(requestPayload, { responseFormat: negotiateDocumentFormat(params) });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("filterOAS", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Prune OpenAPI
+ * Verwijdert componenten die vanuit geen enkel pad of webhook bereikbaar zijn en geeft de specificatie terug in het formaat van de input. De verwijderde componenten staan in x-don-generated.removedComponents. Body: { oasUrl } of { oasBody }.
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const pruneOAS = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "pruneOAS", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await OasPruneService.prune(requestPayload, { responseFormat: negotiateDocumentFormat(params) });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("pruneOAS", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Format OpenAPI
+ * Normaliseert een OpenAPI specificatie zoals openapi-format: velden in de conventionele volgorde, methods lowercase en statuscodes oplopend (bereiken als 4XX). Met outputFormat wordt het document meteen omgezet naar JSON of YAML; zonder blijft het formaat van de input. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * outputFormat String json of yaml  (optional)
+ * no response value expected for this operation
+ */
+const formatOAS = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "formatOAS", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await OasFormatService.format(requestPayload, {
+      outputFormat: params?.outputFormat,
+      responseFormat: negotiateDocumentFormat(params),
+    });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("formatOAS", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Reformat OpenAPI
+ * Zet een OpenAPI specificatie om tussen JSON en YAML met de sleutels in de volgorde van de bron, ook statuscodes (404 blijft voor 200 staan). Zonder targetFormat wordt het het andere formaat. Verder verandert er niets aan de inhoud; alleen x-don-generated komt als laatste sleutel. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * targetFormat String json of yaml  (optional)
+ * no response value expected for this operation
+ */
+const reformatOAS = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "reformatOAS", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await OasReformatService.reformat(requestPayload, { targetFormat: params?.targetFormat });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("reformatOAS", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Diff OpenAPI
+ * Vergelijkt twee specificaties (base en head) en deelt elke wijziging in als breaking, non-breaking of deprecation, zoals verwijderde paden, beperkte enums en nieuwe verplichte velden. Geeft JSON terug met een versieadvies (major, minor of patch) en een Markdown-samenvatting. Body: { base: { oasUrl|oasBody }, head: { oasUrl|oasBody } }.
+ *
+ * oasDiffInput OasDiffInput  (optional)
+ * returns ModelsOasDiff
+ */
+const diffOAS = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "diffOAS", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await OasDiffService.diff(requestPayload);
+    return Service.successResponse(result);
+  } catch (e) {
+    logServiceError("diffOAS", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Redact OpenAPI
+ * Maakt een specificatie klaar voor externe publicatie: verwijdert onderdelen met x-internal: true, servers op interne hosts (localhost, privé-IP-adressen, .local, .internal, .corp), $comment, notitie-extensies (x-todo, x-note, x-internal-*) en HTML-commentaar in beschrijvingen, en vervangt e-mailadressen en BSN's in voorbeelden door testwaarden. De header X-Redactions geeft het aantal ingrepen; met outputFormat=report komt het rapport zelf terug. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * outputFormat String spec of report  (optional)
+ * no response value expected for this operation
+ */
+const redactOAS = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "redactOAS", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await RedactionService.redact(requestPayload, {
+      outputFormat: params?.outputFormat,
+      responseFormat: negotiateDocumentFormat(params),
+    });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("redactOAS", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Stats OpenAPI
+ * Geeft kengetallen over de omvang en complexiteit van een specificatie: het aantal paden, webhooks, operaties (totaal en per method), parameters per operatie, schema's en security schemes, de gemiddelde en maximale nestingdiepte van de schema's in components en de grootte van het document in bytes en regels. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * returns ModelsOasStats
+ */
+const statsOAS = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "statsOAS", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await OasStatsService.stats(requestPayload);
+    return Service.successResponse(result);
+  } catch (e) {
+    logServiceError("statsOAS", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Normalize servers
+ * Controleert en corrigeert de servers van een specificatie (op het hoogste niveau, per pad en per operatie): URL's uit serverMap worden vervangen, http:// wordt https:// en slashes aan het eind gaan weg. Localhost en IP-adressen als host worden als waarschuwing gemeld. De headers X-Server-Changes en X-Server-Warnings geven de aantallen; met outputFormat=report komt het rapport zelf terug. Body: { oasUrl|oasBody, serverMap }.
+ *
+ * oasServersInput OasServersInput  (optional)
+ * outputFormat String spec (standaard) of report  (optional)
+ * no response value expected for this operation
+ */
+const normalizeServers = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "normalizeServers", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await ServerNormalizationService.normalize(requestPayload, {
+      outputFormat: params?.outputFormat,
+      responseFormat: negotiateDocumentFormat(params),
+    });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("normalizeServers", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Translate OpenAPI
+ * Vertaalt de description- en summary-velden van een specificatie via de geconfigureerde vertaaldienst (TRANSLATION_URL), standaard van Nederlands naar Engels, zodat een team ook een Engelse variant kan publiceren. Namen, paden, schema's en voorbeelden blijven ongewijzigd. De header X-Translated-Fields geeft het aantal vertaalde velden. Body: { oasUrl|oasBody, sourceLanguage, targetLanguage }.
+ *
+ * oasTranslateInput OasTranslateInput  (optional)
+ * no response value expected for this operation
+ */
+const translateOAS = async (params) => {
   try {
-    const mockResult = await Service.applyMock("ToolsService", "convertOAS", params);
+    const mockResult = await Service.applyMock("ToolsService", "translateOAS", params);
     if (mockResult !== undefined) {
       if (mockResult.action === "reject") {
         throw mockResult.value;
@@ -107,30 +1328,31 @@ const convertOAS = async (params) => {
       return mockResult.value;
     }
     const requestPayload = Service.extractRequestBody(params);
-    const result = await OasConversionService.convert(requestPayload);
+    const result = await TranslationService.translate(requestPayload, {
+      responseFormat: negotiateDocumentFormat(params),
+    });
     return {
       code: 200,
       headers: result.headers,
       payload: result.rawBody,
     };
   } catch (e) {
-    logServiceError("convertOAS", e);
+    logServiceError("translateOAS", e);
     const { status, message, detail } = normalizeError(e);
     throw Service.rejectResponse({ message, detail }, status);
   }
 };
 
 /**
- * Maak Postman-collectie (POST)
- * Converteert OpenAPI naar Postman Collection JSON. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ * Normalize polymorphism
+ * Normaliseert polymorfie in de schema's: vult ontbrekende discriminator.mapping-entries aan (ook bij erven met allOf), zet een enum met één waarde om naar const in OpenAPI 3.1 en const terug naar enum in 3.0, en meldt oneOf/anyOf-patronen waar tooling vaak op breekt. De headers X-Schema-Changes en X-Schema-Warnings geven de aantallen; met outputFormat=report komt het rapport zelf terug. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
  *
  * oASInput OASInput  (optional)
  * no response value expected for this operation
  */
-// const createPostmanCollection = async ({ oASInput }) => {
-const createPostmanCollection = async (params) => {
+const normalizePolymorphism = async (params) => {
   try {
-    const mockResult = await Service.applyMock("ToolsService", "createPostmanCollection", params);
+    const mockResult = await Service.applyMock("ToolsService", "normalizePolymorphism", params);
     if (mockResult !== undefined) {
       if (mockResult.action === "reject") {
         throw mockResult.value;
@@ -138,29 +1360,32 @@ const createPostmanCollection = async (params) => {
       return mockResult.value;
     }
     const requestPayload = Service.extractRequestBody(params);
-    const result = await PostmanConversionService.convert(requestPayload);
+    const result = await PolymorphismService.normalize(requestPayload, {
+      outputFormat: params?.outputFormat,
+      responseFormat: negotiateDocumentFormat(params),
+    });
     return {
       code: 200,
       headers: result.headers,
       payload: result.rawBody,
     };
   } catch (e) {
-    logServiceError("createPostmanCollection", e);
+    logServiceError("normalizePolymorphism", e);
     const { status, message, detail } = normalizeError(e);
     throw Service.rejectResponse({ message, detail }, status);
   }
 };
 
 /**
- * Bundle OpenAPI
- * Maakt één gebundeld OpenAPI document met opgeloste verwijzingen. Body: { oasUrl } of { oasBody }.
+ * ADR scaffold
+ * Voegt de onderdelen die de ADR-regels verwachten toe aan een bestaande specificatie: de API-Version header op success responses, de standaard foutresponses (400, 401 en 403 bij beveiligde operaties, 500) met een verwijzing naar het ADR components-bestand, en naam, e-mail en URL in info.contact. Bestaande onderdelen blijven staan; met referenceAdrComponents worden inline foutresponses en API-Version/Link headers vervangen door de gedeelde ADR-componenten. De header X-Scaffolded geeft het aantal toevoegingen. Body: { oasUrl } of { oasBody } (stringified JSON of YAML), optioneel met contact en referenceAdrComponents.
  *
- * oASInput OASInput  (optional)
+ * oasAdrScaffoldInput OasAdrScaffoldInput  (optional)
  * no response value expected for this operation
  */
-const bundleOAS = async (params) => {
+const scaffoldAdr = async (params) => {
   try {
-    const mockResult = await Service.applyMock("ToolsService", "bundleOAS", params);
+    const mockResult = await Service.applyMock("ToolsService", "scaffoldAdr", params);
     if (mockResult !== undefined) {
       if (mockResult.action === "reject") {
         throw mockResult.value;
@@ -168,14 +1393,16 @@ const bundleOAS = async (params) => {
       return mockResult.value;
     }
     const requestPayload = Service.extractRequestBody(params);
-    const result = await OasBundleService.bundle(requestPayload);
+    const result = await AdrScaffoldService.scaffold(requestPayload, {
+      responseFormat: negotiateDocumentFormat(params),
+    });
     return {
       code: 200,
       headers: result.headers,
       payload: result.rawBody,
     };
   } catch (e) {
-    logServiceError("bundleOAS", e);
+    logServiceError("scaffoldAdr", e);
     const { status, message, detail } = normalizeError(e);
     throw Service.rejectResponse({ message, detail }, status);
   }
@@ -237,23 +1464,129 @@ const untrustClient = async (params) => {
     return Service.successResponse(result);
   } catch (e) {
     logServiceError("untrustClient", e);
-    if (Service.isErrorResponse(e)) {
-      throw e;
-    }
-    const mapped = translateKeycloakError(e);
-    if (mapped) {
-      Service.throwHttpError(mapped.status, mapped.message);
-    }
-    const status = typeof e.status === "number" && e.status > 0 ? e.status : 400;
-    const message = e?.message ? e.message : "Er is een fout opgetreden.";
-    throw Service.rejectResponse(
-      {
-        message,
-        detail: e.detail || message,
-      },
-      status,
-    );
+    rethrowKeycloakError(e);
+  }
+};
+
+/**
+ * Zet fouten van de KeycloakService om naar een problem-response met passende status.
+ */
+const rethrowKeycloakError = (e) => {
+  if (Service.isErrorResponse(e)) {
+    throw e;
+  }
+  const mapped = translateKeycloakError(e);
+  if (mapped) {
+    Service.throwHttpError(mapped.status, mapped.message);
+  }
+  const status = typeof e.status === "number" && e.status > 0 ? e.status : 400;
+  const message = e?.message ? e.message : "Er is een fout opgetreden.";
+  throw Service.rejectResponse(
+    {
+      message,
+      detail: e.detail || message,
+    },
+    status,
+  );
+};
+
+const handleSelfServiceClients = async ({ operationId, params, action }) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", operationId, params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    if (!keycloakService.isConfigured()) {
+      Service.throwHttpError(500, "Keycloak service niet geconfigureerd");
+    }
+    const identity = await keycloakService.fetchCallerIdentity(params?.authorization);
+    return await action(identity);
+  } catch (e) {
+    logServiceError(operationId, e);
+    rethrowKeycloakError(e);
+  }
+};
+
+/**
+ * Mijn clients (GET)
+ * Geeft de API keys terug die horen bij de identiteit uit het OIDC access token.
+ *
+ * returns List
+ */
+const listMyClients = async (params) =>
+  handleSelfServiceClients({
+    operationId: "listMyClients",
+    params,
+    action: async ({ subject }) => Service.successResponse(await keycloakService.listClientsForOwner(subject)),
+  });
+
+/**
+ * Maak eigen client (POST)
+ * Maakt een API key aan voor de identiteit uit het OIDC access token.
+ *
+ * returns ModelsKeycloakClientResult
+ */
+const createMyClient = async (params) =>
+  handleSelfServiceClients({
+    operationId: "createMyClient",
+    params,
+    action: async ({ subject, email }) =>
+      Service.successResponse(await keycloakService.createClient({ email, owner: subject }), 201),
+  });
+
+/**
+ * Verwijder eigen client (DELETE)
+ * Verwijdert een API key, mits die hoort bij de identiteit uit het OIDC access token.
+ *
+ * id String
+ * no response value expected for this operation
+ */
+const deleteMyClient = async (params) =>
+  handleSelfServiceClients({
+    operationId: "deleteMyClient",
+    params,
+    action: async ({ subject }) => {
+      await keycloakService.deleteClientForOwner(subject, params?.id);
+      return Service.successResponse("", 204);
+    },
+  });
+
+const SPECIFICATION_INPUTS = ["oasBody", "oasUrl", "oasArchive"];
+
+const hasSpecificationInput = (input) =>
+  SPECIFICATION_INPUTS.some((key) => typeof input?.[key] === "string" && input[key].trim().length > 0);
+
+/**
+ * Start de lint-run op de achtergrond en stuurt het LintResult (of een problem-object bij een
+ * fout) naar de callbackUrl. De aanroeper krijgt direct een 202 met het id van de run.
+ */
+const startLintWithCallback = (requestPayload, callbackUrl) => {
+  if (!hasSpecificationInput(requestPayload)) {
+    Service.throwHttpError(400, "Geef een oasBody, oasUrl of oasArchive mee.");
   }
+  const lintId = randomUUID();
+  // Alleen een mislukte lint-run wordt lint.failed; een run die niet bewaard kan worden, is wel gelukt.
+  OasValidatorService.validate(requestPayload, { lintId })
+    .then(
+      async (result) => {
+        try {
+          await persistLintRun(result);
+        } catch (error) {
+          logServiceError("persistLintRun", error);
+        }
+        return WebhookService.deliver(callbackUrl, "lint.completed", result);
+      },
+      (error) => {
+        logServiceError("validatorOpenAPIPost", error);
+        const { status, message, detail } = normalizeError(error);
+        return WebhookService.deliver(callbackUrl, "lint.failed", { id: lintId, status, title: message, detail });
+      },
+    )
+    .catch((error) => logServiceError("deliverLintCallback", error));
+  return Service.successResponse({ id: lintId, status: "accepted", callbackUrl }, 202);
 };
 
 /**
@@ -261,6 +1594,8 @@ const untrustClient = async (params) => {
  * Valideert een OpenAPI specificatie met de DON ADR ruleset. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
  *
  * oASInput OASInput  (optional)
+ * outputFormat String lintresult (standaard), spectral, github of markdown  (optional)
+ * annotationFile String bestandsnaam in de GitHub annotaties bij outputFormat=github  (optional)
  * returns ModelsLintResult
  */
 // const validatorOpenAPIPost = async ({ oASInput }) => {
@@ -274,7 +1609,23 @@ const validatorOpenAPIPost = async (params) => {
       return mockResult.value;
     }
     const requestPayload = Service.extractRequestBody(params);
-    const result = await OasValidatorService.validate(requestPayload);
+    const callbackUrl = WebhookService.parseCallbackUrl(requestPayload?.callbackUrl);
+    if (callbackUrl) {
+      return startLintWithCallback(requestPayload, callbackUrl);
+    }
+    const result = await OasValidatorService.validate(requestPayload, {
+      outputFormat: params?.outputFormat,
+      annotationFile: params?.annotationFile,
+    });
+    if (result?.rawBody) {
+      await persistLintRun(result.lintResult);
+      return {
+        code: 200,
+        headers: result.headers,
+        payload: result.rawBody,
+      };
+    }
+    await persistLintRun(result);
     return Service.successResponse(result);
   } catch (e) {
     logServiceError("validatorOpenAPIPost", e);
@@ -283,13 +1634,349 @@ const validatorOpenAPIPost = async (params) => {
   }
 };
 
+/**
+ * Validate OpenAPI met voortgang (POST)
+ * Zelfde validatie als validatorOpenAPIPost, maar als Server-Sent Events: eerst `progress` events per fase en tot
+ * slot een `result` event met het LintResult.
+ *
+ * oASInput OASInput  (optional)
+ * emit Function schrijft een SSE event naar de client
+ * returns ModelsLintResult
+ */
+const validatorOpenAPIStream = async (params, emit, signal) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "validatorOpenAPIStream", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await OasValidatorService.validate(requestPayload, {
+      onProgress: (progress) => emit("progress", progress),
+      signal,
+    });
+    await persistLintRun(result);
+    return Service.successResponse(result);
+  } catch (e) {
+    logServiceError("validatorOpenAPIStream", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Validate OpenAPI structuur (POST)
+ * Valideert alleen de structuur: of het document een geldige OpenAPI 3.0/3.1 (of Swagger 2.0) specificatie is volgens de officiële JSON Schema's, plus parseerfouten in de JSON of YAML. Dit staat los van de ADR-regels die POST /v1/oas/validate lint: een specificatie kan structureel geldig zijn en toch ADR-bevindingen hebben. Body: { oasUrl } of { oasBody } (stringified JSON of YAML).
+ *
+ * oASInput OASInput  (optional)
+ * returns ModelsStructuralValidation
+ */
+const validatorOpenAPIStructure = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "validatorOpenAPIStructure", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await OasStructureService.validateStructure(requestPayload);
+    return Service.successResponse(result);
+  } catch (e) {
+    logServiceError("validatorOpenAPIStructure", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Lint regels (GET)
+ * Geeft alle regels uit de actieve ADR ruleset met beschrijving, severity, documentatielink en of ze meetellen
+ * voor de score.
+ *
+ * targetVersion String ADR ruleset-versie: 2.0 of 2.1  (optional)
+ * ruleset String adr (standaard) of owasp  (optional)
+ * returns ModelsLintRuleCatalogue
+ */
+const listLintRules = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "listLintRules", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const result = await OasValidatorService.listRules({
+      targetVersion: params?.targetVersion,
+      ruleset: params?.ruleset,
+    });
+    return Service.successResponse(result);
+  } catch (e) {
+    logServiceError("listLintRules", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Lint-run ophalen (GET)
+ * Haalt een eerder uitgevoerde lint-run op aan de hand van het id uit het LintResult.
+ *
+ * id String
+ * returns ModelsLintResult
+ */
+const getLintRun = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "getLintRun", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const result = await getLintRunStore().get(params?.id);
+    if (!result) {
+      Service.throwHttpError(404, "Lint-run niet gevonden of verlopen.");
+    }
+    return Service.successResponse(result);
+  } catch (e) {
+    logServiceError("getLintRun", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Batch lint (POST)
+ * Valideert meerdere OpenAPI specificaties (oasUrls) gelijktijdig met de DON ADR ruleset.
+ *
+ * lintBatchInput LintBatchInput  (optional)
+ * returns List
+ */
+const lintBatch = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "lintBatch", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const results = await OasValidatorService.validateBatch(requestPayload);
+    await Promise.all(results.map((item) => persistLintRun(item.lintResult)));
+    return Service.successResponse(results);
+  } catch (e) {
+    logServiceError("lintBatch", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Lint rapport (POST)
+ * Lint alle API's van een organisatie (organisationUri uit het register) of een lijst oasUrls en geeft één
+ * samengevat rapport terug: gemiddelde score, meest voorkomende overtredingen en een samenvatting per API.
+ *
+ * lintReportInput LintReportInput  (optional)
+ * returns ModelsLintReport
+ */
+const lintReport = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "lintReport", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const { report, lintResults } = await LintReportService.buildReport(requestPayload);
+    await Promise.all(lintResults.map((lintResult) => persistLintRun(lintResult)));
+    return Service.successResponse(report);
+  } catch (e) {
+    logServiceError("lintReport", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Lint-diff (POST)
+ * Vergelijkt twee specificaties of twee opgeslagen lint-runs en geeft per bevinding aan of die nieuw, opgelost of
+ * ongewijzigd is.
+ *
+ * lintDiffInput LintDiffInput  (optional)
+ * returns ModelsLintDiff
+ */
+const lintDiff = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "lintDiff", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const { diff, lintResults } = await LintDiffService.diff(requestPayload);
+    await Promise.all(lintResults.map((lintResult) => persistLintRun(lintResult)));
+    return Service.successResponse(diff);
+  } catch (e) {
+    logServiceError("lintDiff", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Lint-fix
+ * Past automatische correcties toe voor een vaste set regels (paths-no-trailing-slash, info-contact-fields-exist met @TODO-placeholders en owasp:api8:2023-no-server-http) en lint de gecorrigeerde specificatie opnieuw met dezelfde instellingen. Geeft de toegepaste correcties, de gecorrigeerde specificatie (in het formaat van de input) en het LintResult met de overgebleven bevindingen; dat LintResult wordt als lint-run bewaard.
+ *
+ * oASInput OASInput  (optional)
+ * returns ModelsLintFix
+ */
+const lintFix = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "lintFix", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await LintFixService.fix(requestPayload);
+    await persistLintRun(result.lintResult);
+    return Service.successResponse(result);
+  } catch (e) {
+    logServiceError("lintFix", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Controleer responses (POST)
+ * Controleert of elke operatie een success-response met content en de ADR-verplichte foutresponses documenteert.
+ *
+ * oASInput OASInput  (optional)
+ * returns ModelsResponseCoverageReport
+ */
+const checkResponseCoverage = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "checkResponseCoverage", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const report = await OasResponseCoverageService.check(requestPayload);
+    return Service.successResponse(report);
+  } catch (e) {
+    logServiceError("checkResponseCoverage", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
+/**
+ * Herstel responses (POST)
+ * Voegt ontbrekende ADR-foutresponses toe als verwijzing naar het ADR components-bestand.
+ *
+ * oASInput OASInput  (optional)
+ * no response value expected for this operation
+ */
+const fixResponseCoverage = async (params) => {
+  try {
+    const mockResult = await Service.applyMock("ToolsService", "fixResponseCoverage", params);
+    if (mockResult !== undefined) {
+      if (mockResult.action === "reject") {
+        throw mockResult.value;
+      }
+      return mockResult.value;
+    }
+    const requestPayload = Service.extractRequestBody(params);
+    const result = await OasResponseCoverageService.fix(requestPayload, {
+      responseFormat: negotiateDocumentFormat(params),
+    });
+    return {
+      code: 200,
+      headers: result.headers,
+      payload: result.rawBody,
+    };
+  } catch (e) {
+    logServiceError("fixResponseCoverage", e);
+    const { status, message, detail } = normalizeError(e);
+    throw Service.rejectResponse({ message, detail }, status);
+  }
+};
+
 module.exports = {
+  arazzoLint,
   arazzoMarkdown,
   arazzoMermaid,
+  generateArazzoTests,
   convertOAS,
   createPostmanCollection,
+  createBrunoCollection,
+  createInsomniaExport,
+  createHurlTests,
+  createHttpFiles,
+  createCurlScripts,
+  createHttpieScripts,
+  createRedocDocs,
+  createDocsSite,
+  createReferenceDocs,
+  createLandingPage,
+  createGoServer,
+  createCode,
+  createTypeScriptTypes,
+  createPrismMock,
+  createHostedMock,
+  deleteHostedMock,
+  serveHostedMock,
+  convertPostmanToOAS,
+  convertBrunoToOAS,
+  convertHarToOAS,
+  createSoapUiProject,
+  createKarateTests,
   bundleOAS,
+  splitOAS,
+  extractSharedComponents,
+  filterOAS,
+  pruneOAS,
+  formatOAS,
+  reformatOAS,
+  diffOAS,
+  redactOAS,
+  statsOAS,
+  normalizeServers,
+  translateOAS,
+  normalizePolymorphism,
   generateOAS,
+  createExamples,
+  applyOverlay,
+  getLintRun,
+  listLintRules,
+  lintBatch,
+  lintReport,
+  lintDiff,
+  lintFix,
+  checkResponseCoverage,
+  fixResponseCoverage,
+  scaffoldAdr,
+  listMyClients,
+  createMyClient,
+  deleteMyClient,
   untrustClient,
   validatorOpenAPIPost,
+  validatorOpenAPIStream,
+  validatorOpenAPIStructure,
 };
@@ -0,0 +1,173 @@
+const Service = require("./Service");
+const { resolveOasDocument, serializeOasDocument } = require("./OasInputService");
+const { buildProvenance, stampDocument } = require("../utils/provenance");
+const logger = require("../logger");
+
+const LANGUAGES = ["nl", "en"];
+const TEXT_FIELDS = new Set(["description", "summary"]);
+// Waarden onder deze sleutels zijn data; een `description` daarin is geen documentatie.
+const DATA_KEYS = new Set(["example", "examples", "default", "const", "enum"]);
+const DEFAULT_TIMEOUT_MS = 30000;
+const BATCH_SIZE = 50;
+
+const NOT_CONFIGURED_ERROR = "Vertalen is niet geconfigureerd (TRANSLATION_URL).";
+const BACKEND_ERROR = "De vertaaldienst gaf geen bruikbaar antwoord.";
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+/**
+ * Hoe een batch teksten naar de vertaaldienst gaat en hoe de vertalingen terugkomen. `generic` is het
+ * eigen formaat van deze API (`{ source, target, texts }` naar `{ translations }`), zodat elke dienst
+ * achter een kleine adapter past; `libretranslate` spreekt de API van een (eigen) LibreTranslate-server.
+ */
+const PROVIDERS = {
+  generic: {
+    body: (texts, { source, target }) => ({ source, target, texts }),
+    headers: (apiKey) => (apiKey ? { Authorization: `Bearer ${apiKey}` } : {}),
+    translations: (payload) => payload?.translations,
+  },
+  libretranslate: {
+    body: (texts, { source, target }, apiKey) => ({
+      q: texts,
+      source,
+      target,
+      format: "text",
+      ...(apiKey ? { api_key: apiKey } : {}),
+    }),
+    headers: () => ({}),
+    translations: (payload) => payload?.translatedText,
+  },
+};
+
+/**
+ * Maakt de vertaalfunctie `(texts, { source, target }) => translations` voor een endpoint. Zonder
+ * `url` of met een onbekende provider volgt bij gebruik een 500, zoals bij de webhook callbacks.
+ */
+const createTranslationBackend = ({ url, provider = "generic", apiKey = "", timeoutMs, fetchImpl = fetch } = {}) => {
+  const endpoint = typeof url === "string" ? url.trim() : "";
+  const adapter = PROVIDERS[String(provider || "generic").toLowerCase()];
+  const timeout = Number.isFinite(timeoutMs) && timeoutMs > 0 ? timeoutMs : DEFAULT_TIMEOUT_MS;
+  return async (texts, languages) => {
+    if (!endpoint) {
+      Service.throwHttpError(500, NOT_CONFIGURED_ERROR);
+    }
+    if (!adapter) {
+      Service.throwHttpError(
+        500,
+        `Onbekende TRANSLATION_PROVIDER "${provider}". Kies uit: ${Object.keys(PROVIDERS).join(", ")}.`,
+      );
+    }
+    let payload;
+    try {
+      const response = await fetchImpl(endpoint, {
+        method: "POST",
+        headers: { "Content-Type": "application/json", Accept: "application/json", ...adapter.headers(apiKey) },
+        body: JSON.stringify(adapter.body(texts, languages, apiKey)),
+        redirect: "manual",
+        signal: AbortSignal.timeout(timeout),
+      });
+      if (!response.ok) {
+        throw new Error(`Vertaaldienst gaf status ${response.status}`);
+      }
+      payload = await response.json();
+    } catch (error) {
+      logger.warn(`[TranslationService] vertalen via ${endpoint} mislukt: ${error.message}`);
+      Service.throwHttpError(502, BACKEND_ERROR, error.message);
+    }
+    const translations = adapter.translations(payload);
+    if (
+      !Array.isArray(translations) ||
+      translations.length !== texts.length ||
+      translations.some((text) => typeof text !== "string")
+    ) {
+      Service.throwHttpError(502, BACKEND_ERROR, `Verwacht ${texts.length} vertalingen als lijst van strings.`);
+    }
+    return translations;
+  };
+};
+
+const backendFromEnv = () =>
+  createTranslationBackend({
+    url: process.env.TRANSLATION_URL,
+    provider: process.env.TRANSLATION_PROVIDER,
+    apiKey: process.env.TRANSLATION_API_KEY,
+    timeoutMs: Number(process.env.TRANSLATION_TIMEOUT_MS),
+  });
+
+// Alle niet-lege `description`- en `summary`-velden, buiten voorbeelden, defaults en extensies.
+const collectTextFields = (node, fields = []) => {
+  if (Array.isArray(node)) {
+    node.forEach((item) => collectTextFields(item, fields));
+  } else if (isObject(node)) {
+    for (const [key, value] of Object.entries(node)) {
+      if (TEXT_FIELDS.has(key) && typeof value === "string" && value.trim()) {
+        fields.push({ owner: node, key });
+      } else if (!DATA_KEYS.has(key) && !key.startsWith("x-")) {
+        collectTextFields(value, fields);
+      }
+    }
+  }
+  return fields;
+};
+
+const resolveLanguage = (value, fallback, name) => {
+  if (value === undefined || value === null || value === "") {
+    return fallback;
+  }
+  const normalized = String(value).toLowerCase();
+  if (!LANGUAGES.includes(normalized)) {
+    Service.throwHttpError(400, `Onbekende ${name} "${value}". Kies uit: ${LANGUAGES.join(", ")}.`);
+  }
+  return normalized;
+};
+
+/**
+ * Vertaalt de `description`- en `summary`-velden van een document in batches. Gelijke teksten gaan maar
+ * één keer naar de vertaaldienst. Geeft het aantal vertaalde velden terug; het document wordt aangepast.
+ */
+const translateDocument = async (spec, { source, target, backend }) => {
+  const fields = collectTextFields(spec);
+  const texts = [...new Set(fields.map(({ owner, key }) => owner[key]))];
+  const translated = new Map();
+  for (let start = 0; start < texts.length; start += BATCH_SIZE) {
+    const batch = texts.slice(start, start + BATCH_SIZE);
+    const translations = await backend(batch, { source, target });
+    batch.forEach((text, index) => translated.set(text, translations[index]));
+  }
+  for (const { owner, key } of fields) {
+    owner[key] = translated.get(owner[key]);
+  }
+  return fields.length;
+};
+
+/**
+ * Geeft de specificatie terug met vertaalde beschrijvingen en samenvattingen, standaard van Nederlands naar
+ * Engels. Namen, paden, schema's en voorbeelden blijven ongewijzigd; de header X-Translated-Fields geeft
+ * het aantal vertaalde velden.
+ */
+const translate = async (input, { responseFormat, backend = backendFromEnv() } = {}) => {
+  const source = resolveLanguage(input.sourceLanguage, "nl", "sourceLanguage");
+  const target = resolveLanguage(input.targetLanguage, source === "nl" ? "en" : "nl", "targetLanguage");
+  if (source === target) {
+    Service.throwHttpError(400, "sourceLanguage en targetLanguage moeten verschillen.");
+  }
+  const resolved = await resolveOasDocument(input);
+  const count = await translateDocument(resolved.spec, { source, target, backend });
+  stampDocument(
+    resolved.spec,
+    buildProvenance({
+      tool: "oas-translate",
+      source: resolved.source,
+      details: { sourceLanguage: source, targetLanguage: target },
+    }),
+  );
+  const result = serializeOasDocument(resolved.spec, responseFormat ?? resolved.format, `openapi-${target}`);
+  result.headers["X-Translated-Fields"] = String(count);
+  return result;
+};
+
+module.exports = {
+  createTranslationBackend,
+  translate,
+  translateDocument,
+};
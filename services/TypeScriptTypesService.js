@@ -0,0 +1,326 @@
+const Service = require("./Service");
+const { resolveOasDocument } = require("./OasInputService");
+const { sanitizeFileName } = require("../utils/fileName");
+const { collectOperations, resolveRef } = require("../utils/openapi");
+const { buildProvenance, describeProvenance } = require("../utils/provenance");
+
+const DEFAULT_FILENAME = "openapi";
+const INDENT = "    ";
+const COMPONENT_SECTIONS = ["schemas", "responses", "parameters", "requestBodies", "headers"];
+const PARAMETER_LOCATIONS = ["query", "header", "path", "cookie"];
+const ROOT_NAMES = new Set(["paths", "webhooks", "components", "operations", "$defs"]);
+const IDENTIFIER_PATTERN = /^[A-Za-z_$][A-Za-z0-9_$]*$/;
+
+const propertyKey = (name) => (IDENTIFIER_PATTERN.test(name) ? name : JSON.stringify(name));
+
+// JSDoc boven een veld, in de vorm die openapi-typescript ook gebruikt.
+const jsDoc = (schema, depth) => {
+  const tags = [];
+  if (schema?.deprecated) {
+    tags.push("@deprecated");
+  }
+  if (typeof schema?.description === "string" && schema.description.trim()) {
+    tags.push(`@description ${schema.description.trim()}`);
+  }
+  if (schema?.format) {
+    tags.push(`Format: ${schema.format}`);
+  }
+  if (schema?.default !== undefined) {
+    tags.push(`@default ${JSON.stringify(schema.default)}`);
+  }
+  if (schema?.example !== undefined) {
+    tags.push(`@example ${JSON.stringify(schema.example)}`);
+  }
+  if (tags.length === 0) {
+    return [];
+  }
+  const pad = INDENT.repeat(depth);
+  const lines = tags.join("\n").replace(/\*\//g, "*\\/").split("\n");
+  if (lines.length === 1) {
+    return [`${pad}/** ${lines[0]} */`];
+  }
+  return [`${pad}/**`, ...lines.map((line) => `${pad} * ${line}`), `${pad} */`];
+};
+
+const refType = (ref) => {
+  const match = /^#\/components\/([^/]+)\/(.+)$/.exec(ref);
+  if (!match || !COMPONENT_SECTIONS.includes(match[1])) {
+    return "unknown";
+  }
+  const name = match[2].replace(/~1/g, "/").replace(/~0/g, "~");
+  return `components[${JSON.stringify(match[1])}][${JSON.stringify(name)}]`;
+};
+
+const wrapUnion = (type) => (/[|&]/.test(type) && !/^[{(]/.test(type) ? `(${type})` : type);
+
+/**
+ * TypeScript-type voor een schema. Verwijzingen naar `components` blijven verwijzingen
+ * (`components["schemas"]["Dier"]`), zodat recursieve schema's geen probleem zijn. `depth` is het
+ * inspringniveau van de regel waarop het type begint.
+ */
+const schemaType = (schema, depth = 0) => {
+  if (schema === true || schema === undefined || schema === null) {
+    return "unknown";
+  }
+  if (schema === false) {
+    return "never";
+  }
+  if (typeof schema.$ref === "string") {
+    return refType(schema.$ref);
+  }
+  const types = Array.isArray(schema.type) ? schema.type : schema.type ? [schema.type] : [];
+  const nullable = schema.nullable === true || types.includes("null");
+  let type;
+  if (schema.const !== undefined) {
+    type = JSON.stringify(schema.const);
+  } else if (Array.isArray(schema.enum)) {
+    type = schema.enum.map((value) => JSON.stringify(value)).join(" | ") || "never";
+  } else if (Array.isArray(schema.oneOf) || Array.isArray(schema.anyOf)) {
+    type = (schema.oneOf || schema.anyOf).map((variant) => wrapUnion(schemaType(variant, depth))).join(" | ");
+  } else if (Array.isArray(schema.allOf)) {
+    const parts = schema.allOf.map((part) => wrapUnion(schemaType(part, depth)));
+    if (schema.properties) {
+      parts.push(objectType({ ...schema, allOf: undefined }, depth));
+    }
+    type = parts.join(" & ");
+  } else {
+    const variants = types
+      .filter((item) => item !== "null")
+      .map((item) => primitiveType(item, schema, depth));
+    if (variants.length === 0) {
+      variants.push(schema.properties || schema.additionalProperties ? objectType(schema, depth) : "unknown");
+    }
+    type = variants.join(" | ");
+  }
+  return nullable && type !== "unknown" ? `${type} | null` : type;
+};
+
+const primitiveType = (type, schema, depth) => {
+  switch (type) {
+    case "string":
+      return "string";
+    case "integer":
+    case "number":
+      return "number";
+    case "boolean":
+      return "boolean";
+    case "array":
+      return `${wrapUnion(schemaType(schema.items, depth))}[]`;
+    case "object":
+      return objectType(schema, depth);
+    default:
+      return "unknown";
+  }
+};
+
+// Naast vaste velden is de index signature `unknown`; een smaller type botst in TypeScript met de velden.
+const objectType = (schema, depth) => {
+  const pad = INDENT.repeat(depth + 1);
+  const required = new Set(Array.isArray(schema.required) ? schema.required : []);
+  const lines = [];
+  for (const [name, property] of Object.entries(schema.properties || {})) {
+    const readOnly = property?.readOnly ? "readonly " : "";
+    const optional = required.has(name) ? "" : "?";
+    const type = schemaType(property, depth + 1);
+    lines.push(...jsDoc(property, depth + 1), `${pad}${readOnly}${propertyKey(name)}${optional}: ${type};`);
+  }
+  const additional = schema.additionalProperties;
+  if (additional !== undefined && additional !== false) {
+    const valueType = lines.length > 0 || additional === true ? "unknown" : schemaType(additional, depth + 1);
+    lines.push(`${pad}[key: string]: ${valueType};`);
+  } else if (lines.length === 0) {
+    return additional === false ? "Record<string, never>" : "{ [key: string]: unknown }";
+  }
+  return `{\n${lines.join("\n")}\n${INDENT.repeat(depth)}}`;
+};
+
+const mediaContent = (content, depth) => {
+  const entries = Object.entries(content || {});
+  if (entries.length === 0) {
+    return "never";
+  }
+  const lines = entries.map(
+    ([type, media]) => `${INDENT.repeat(depth + 1)}${JSON.stringify(type)}: ${schemaType(media?.schema, depth + 1)};`,
+  );
+  return `{\n${lines.join("\n")}\n${INDENT.repeat(depth)}}`;
+};
+
+const responseType = (response, depth) => {
+  const pad = INDENT.repeat(depth + 1);
+  const content = mediaContent(response.content, depth + 1);
+  return [
+    "{",
+    `${pad}headers: {`,
+    `${pad}${INDENT}[name: string]: unknown;`,
+    `${pad}};`,
+    `${pad}content${content === "never" ? "?" : ""}: ${content};`,
+    `${INDENT.repeat(depth)}}`,
+  ].join("\n");
+};
+
+const parametersType = (document, parameters, depth) => {
+  const lines = [];
+  for (const location of PARAMETER_LOCATIONS) {
+    const matching = parameters.filter((parameter) => parameter.in === location);
+    if (matching.length === 0) {
+      lines.push(`${INDENT.repeat(depth + 1)}${location}?: never;`);
+      continue;
+    }
+    const fields = matching.flatMap((parameter) => [
+      ...jsDoc({ ...resolveRef(document, parameter.schema), description: parameter.description }, depth + 2),
+      [
+        INDENT.repeat(depth + 2),
+        propertyKey(parameter.name),
+        parameter.required ? ": " : "?: ",
+        schemaType(parameter.schema, depth + 2),
+        ";",
+      ].join(""),
+    ]);
+    const optional = matching.some((parameter) => parameter.required) ? "" : "?";
+    lines.push(`${INDENT.repeat(depth + 1)}${location}${optional}: {`, ...fields, `${INDENT.repeat(depth + 1)}};`);
+  }
+  return `{\n${lines.join("\n")}\n${INDENT.repeat(depth)}}`;
+};
+
+const operationType = (document, entry, depth) => {
+  const { operation, parameters, requestBody } = entry;
+  const pad = INDENT.repeat(depth + 1);
+  const lines = [`${pad}parameters: ${parametersType(document, parameters, depth + 1)};`];
+  if (requestBody) {
+    const optional = requestBody.required ? "" : "?";
+    lines.push(
+      `${pad}requestBody${optional}: {`,
+      `${pad}${INDENT}content: ${mediaContent(requestBody.content, depth + 2)};`,
+      `${pad}};`,
+    );
+  } else {
+    lines.push(`${pad}requestBody?: never;`);
+  }
+  lines.push(`${pad}responses: {`);
+  for (const [status, rawResponse] of Object.entries(operation.responses || {})) {
+    const response = resolveRef(document, rawResponse) || {};
+    const key = /^\d{3}$/.test(status) ? status : JSON.stringify(status);
+    const type = typeof rawResponse?.$ref === "string" ? refType(rawResponse.$ref) : responseType(response, depth + 2);
+    lines.push(...jsDoc({ description: response.description }, depth + 2), `${pad}${INDENT}${key}: ${type};`);
+  }
+  lines.push(`${pad}};`);
+  return `{\n${lines.join("\n")}\n${INDENT.repeat(depth)}}`;
+};
+
+/**
+ * Bouwt een `.d.ts` in de stijl van openapi-typescript: `paths`, `components` en `operations` als
+ * interfaces, plus een alias per schema (`export type Dier = components["schemas"]["Dier"]`) voor
+ * schema's met een naam die een geldige TypeScript-identifier is.
+ */
+const renderTypes = (document, { source } = {}) => {
+  const provenance = buildProvenance({ tool: "oas-typescript", source });
+  const lines = [
+    "/**",
+    ` * ${describeProvenance(provenance)}`,
+    " * Gegenereerd uit de OpenAPI-specificatie; niet met de hand aanpassen.",
+    " */",
+    "",
+  ];
+
+  const operations = collectOperations(document);
+  const byPath = new Map();
+  const namedOperations = [];
+  const usedOperationIds = new Set();
+  for (const entry of operations) {
+    const operationId = typeof entry.operation.operationId === "string" ? entry.operation.operationId : "";
+    const named = operationId && !usedOperationIds.has(operationId);
+    if (named) {
+      usedOperationIds.add(operationId);
+      namedOperations.push([operationId, entry]);
+    }
+    if (!byPath.has(entry.path)) {
+      byPath.set(entry.path, []);
+    }
+    byPath.get(entry.path).push({ entry, operationId: named ? operationId : "" });
+  }
+
+  lines.push("export interface paths {");
+  for (const [path, entries] of byPath) {
+    lines.push(`${INDENT}${JSON.stringify(path)}: {`);
+    for (const { entry, operationId } of entries) {
+      const type = operationId ? `operations[${JSON.stringify(operationId)}]` : operationType(document, entry, 2);
+      lines.push(...jsDoc({ description: entry.operation.summary, deprecated: entry.operation.deprecated }, 2));
+      lines.push(`${INDENT}${INDENT}${entry.method}: ${type};`);
+    }
+    lines.push(`${INDENT}};`);
+  }
+  lines.push("}", "", "export type webhooks = Record<string, never>;", "", "export interface components {");
+  for (const section of COMPONENT_SECTIONS) {
+    const entries = Object.entries(document.components?.[section] || {});
+    if (entries.length === 0) {
+      lines.push(`${INDENT}${section}: never;`);
+      continue;
+    }
+    lines.push(`${INDENT}${section}: {`);
+    for (const [name, rawValue] of entries) {
+      const value = section === "schemas" ? rawValue : resolveRef(document, rawValue) || {};
+      let type;
+      if (section === "schemas") {
+        type = schemaType(value, 2);
+      } else if (section === "responses") {
+        type = responseType(value, 2);
+      } else if (section === "requestBodies") {
+        type = `{\n${INDENT.repeat(3)}content: ${mediaContent(value.content, 3)};\n${INDENT.repeat(2)}}`;
+      } else {
+        type = schemaType(value.schema, 2);
+      }
+      lines.push(...jsDoc(section === "schemas" ? value : { description: value.description }, 2));
+      lines.push(`${INDENT}${INDENT}${propertyKey(name)}: ${type};`);
+    }
+    lines.push(`${INDENT}};`);
+  }
+  lines.push(`${INDENT}pathItems: never;`, "}", "", "export type $defs = Record<string, never>;", "");
+
+  if (namedOperations.length > 0) {
+    lines.push("export interface operations {");
+    for (const [operationId, entry] of namedOperations) {
+      lines.push(`${INDENT}${propertyKey(operationId)}: ${operationType(document, entry, 1)};`);
+    }
+    lines.push("}", "");
+  } else {
+    lines.push("export type operations = Record<string, never>;", "");
+  }
+
+  const aliases = Object.keys(document.components?.schemas || {}).filter(
+    (name) => IDENTIFIER_PATTERN.test(name) && !ROOT_NAMES.has(name),
+  );
+  for (const name of aliases) {
+    lines.push(`export type ${name} = components["schemas"][${JSON.stringify(name)}];`);
+  }
+  return `${lines.join("\n").replace(/\n+$/, "")}\n`;
+};
+
+const convert = async (input) => {
+  const resolved = await resolveOasDocument(input);
+  let text;
+  try {
+    text = renderTypes(resolved.spec, { source: resolved.source });
+  } catch (error) {
+    throw Service.rejectResponse(
+      {
+        message: "Genereren van de TypeScript-types is mislukt.",
+        detail: error.message,
+      },
+      500,
+    );
+  }
+  const title = typeof resolved.spec.info?.title === "string" ? resolved.spec.info.title.trim() : "";
+  const filenameBase = sanitizeFileName(title, { fallback: DEFAULT_FILENAME, lowercase: true });
+  return {
+    headers: {
+      "Content-Type": "application/typescript; charset=utf-8",
+      "Content-Disposition": `attachment; filename="${filenameBase}.d.ts"`,
+    },
+    rawBody: Buffer.from(text, "utf8"),
+  };
+};
+
+module.exports = {
+  convert,
+  renderTypes,
+};
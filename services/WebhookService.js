@@ -0,0 +1,114 @@
+const crypto = require("node:crypto");
+const { fetch } = require("@stoplight/spectral-runtime");
+const Service = require("./Service");
+const { OutboundPolicyError, checkUrl, createOutboundAgent, loadOutboundPolicy } = require("../utils/outboundPolicy");
+const logger = require("../logger");
+
+const SIGNATURE_HEADER = "X-DON-Signature";
+const TIMESTAMP_HEADER = "X-DON-Timestamp";
+const EVENT_HEADER = "X-DON-Event";
+const DEFAULT_TIMEOUT_MS = 10000;
+const DEFAULT_ATTEMPTS = 3;
+const RETRY_DELAY_MS = 2000;
+
+const INVALID_CALLBACK_URL_ERROR = "De waarde van callbackUrl is geen geldige http(s) URL.";
+const NOT_CONFIGURED_ERROR = "Webhook callbacks zijn niet geconfigureerd.";
+
+// Zoals in RemoteSpecificationService: de agent toetst bij het verbinden de adressen van de hostnaam.
+const outboundPolicy = loadOutboundPolicy();
+const outboundAgent = createOutboundAgent(outboundPolicy);
+
+const resolveSecret = () =>
+  typeof process.env.LINT_CALLBACK_SECRET === "string" ? process.env.LINT_CALLBACK_SECRET : "";
+
+const isConfigured = () => resolveSecret().trim().length > 0;
+
+const parseCallbackUrl = (value) => {
+  if (value === undefined || value === null || value === "") {
+    return null;
+  }
+  let parsed;
+  try {
+    parsed = new URL(String(value).trim());
+  } catch {
+    Service.throwHttpError(400, INVALID_CALLBACK_URL_ERROR);
+  }
+  if (parsed.protocol !== "https:" && parsed.protocol !== "http:") {
+    Service.throwHttpError(400, INVALID_CALLBACK_URL_ERROR);
+  }
+  try {
+    checkUrl(parsed, outboundPolicy);
+  } catch (error) {
+    if (error instanceof OutboundPolicyError) {
+      Service.throwHttpError(400, "Deze callbackUrl is niet toegestaan.", error.message);
+    }
+    throw error;
+  }
+  if (!isConfigured()) {
+    Service.throwHttpError(500, NOT_CONFIGURED_ERROR);
+  }
+  return parsed.toString();
+};
+
+/**
+ * De handtekening is een HMAC-SHA256 over `${timestamp}.${body}`, zodat ontvangers ook oude
+ * berichten kunnen weigeren. Het formaat van de header is `sha256=<hex>`.
+ */
+const sign = (body, timestamp, secret = resolveSecret()) =>
+  `sha256=${crypto.createHmac("sha256", secret).update(`${timestamp}.${body}`).digest("hex")}`;
+
+const wait = (ms) => new Promise((resolve) => setTimeout(resolve, ms));
+
+const postOnce = async (url, body, headers) => {
+  // De hostnaam kan intussen naar een ander adres wijzen: het adres wordt getoetst bij het verbinden,
+  // niet vooraf, zodat er geen tweede DNS-lookup tussen controle en verbinding zit.
+  checkUrl(url, outboundPolicy);
+  const response = await fetch(url, {
+    method: "POST",
+    headers,
+    body,
+    agent: outboundAgent,
+    redirect: "manual",
+    signal: AbortSignal.timeout(DEFAULT_TIMEOUT_MS),
+  });
+  if (response.status < 200 || response.status >= 300) {
+    throw new Error(`Callback gaf status ${response.status}`);
+  }
+};
+
+const deliver = async (url, event, payload, { attempts = DEFAULT_ATTEMPTS } = {}) => {
+  const body = JSON.stringify(payload);
+  let lastError;
+  for (let attempt = 1; attempt <= attempts; attempt += 1) {
+    const timestamp = Math.floor(Date.now() / 1000).toString();
+    const headers = {
+      "Content-Type": "application/json",
+      [EVENT_HEADER]: event,
+      [TIMESTAMP_HEADER]: timestamp,
+      [SIGNATURE_HEADER]: sign(body, timestamp),
+    };
+    try {
+      await postOnce(url, body, headers);
+      logger.info(`[WebhookService] ${event} afgeleverd bij ${url}`);
+      return true;
+    } catch (error) {
+      lastError = error;
+      logger.warn(`[WebhookService] ${event} naar ${url} mislukt (poging ${attempt}/${attempts}): ${error.message}`);
+      if (attempt < attempts) {
+        await wait(RETRY_DELAY_MS * attempt);
+      }
+    }
+  }
+  logger.error(`[WebhookService] ${event} niet afgeleverd bij ${url}: ${lastError?.message}`);
+  return false;
+};
+
+module.exports = {
+  SIGNATURE_HEADER,
+  TIMESTAMP_HEADER,
+  EVENT_HEADER,
+  deliver,
+  isConfigured,
+  parseCallbackUrl,
+  sign,
+};
@@ -0,0 +1,21 @@
+const fs = require("node:fs/promises");
+const { parentPort } = require("node:worker_threads");
+const { runEmbeddedEngine, runExampleValidation } = require("./OasValidatorService");
+
+// Draait de ingebouwde Spectral-engine in een worker thread (zie `lintWorkers` in OasValidatorService), zodat
+// een run na de timeout beëindigd kan worden. De Spectral-instanties blijven per worker geladen; een gepubliceerde
+// ruleset komt met de taak mee uit de hoofdthread. Het document leest de worker zelf uit `inputPath`.
+parentPort.on("message", async ({ task, inputPath, source, rulesetVersion, remoteRuleset, diagnostics }) => {
+  try {
+    const contents = await fs.readFile(inputPath, "utf8");
+    const result =
+      task === "examples"
+        ? await runExampleValidation(contents, source, diagnostics)
+        : await runEmbeddedEngine(contents, source, rulesetVersion, remoteRuleset, (event) =>
+            parentPort.postMessage({ event }),
+          );
+    parentPort.postMessage({ result });
+  } catch (error) {
+    parentPort.postMessage({ error });
+  }
+});
@@ -0,0 +1,107 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { scaffoldDocument } = require("../services/AdrScaffoldService");
+const { ADR_COMPONENTS_URL } = require("../services/OasResponseCoverageService");
+
+test("scaffoldDocument voegt contact, API-Version headers en standaard foutresponses toe", () => {
+  const spec = {
+    openapi: "3.0.3",
+    info: { title: "Dieren API", version: "1.0.0", contact: { name: "Team Dieren" } },
+    paths: {
+      "/dieren": {
+        get: {
+          responses: {
+            200: { $ref: "#/components/responses/Dieren" },
+            400: { description: "Fout" },
+          },
+        },
+      },
+      "/dieren/{id}": {
+        get: {
+          security: [{ apiKey: [] }],
+          responses: {
+            200: { $ref: "#/components/responses/Dieren" },
+            204: { description: "Leeg", headers: { "api-version": { schema: { type: "string" } } } },
+          },
+        },
+      },
+    },
+    components: { responses: { Dieren: { description: "OK" } } },
+  };
+
+  const { changes } = scaffoldDocument(spec, { contact: { email: "dieren@example.nl" } });
+
+  assert.deepEqual(spec.info.contact, {
+    name: "Team Dieren",
+    email: "dieren@example.nl",
+    url: "@TODO: Add contact url",
+  });
+  assert.deepEqual(spec.components.responses.Dieren.headers, {
+    "API-Version": { $ref: `${ADR_COMPONENTS_URL}#/headers/API-Version` },
+  });
+  assert.deepEqual(Object.keys(spec.paths["/dieren/{id}"].get.responses[204].headers), ["api-version"]);
+  assert.deepEqual(Object.keys(spec.paths["/dieren"].get.responses), ["200", "400", "500"]);
+  assert.deepEqual(spec.paths["/dieren/{id}"].get.responses[401], { $ref: `${ADR_COMPONENTS_URL}#/responses/401` });
+  assert.deepEqual(
+    changes.map((change) => change.path),
+    [
+      "#/info/contact/email",
+      "#/info/contact/url",
+      "#/components/responses/Dieren/headers/API-Version",
+      "#/paths/~1dieren/get/responses/500",
+      "#/paths/~1dieren~1{id}/get/responses/400",
+      "#/paths/~1dieren~1{id}/get/responses/401",
+      "#/paths/~1dieren~1{id}/get/responses/403",
+      "#/paths/~1dieren~1{id}/get/responses/500",
+    ],
+  );
+});
+
+test("referenceAdrComponents vervangt inline foutresponses en headers door de gedeelde componenten", () => {
+  const spec = {
+    openapi: "3.0.3",
+    info: {
+      title: "Dieren API",
+      version: "1.0.0",
+      contact: { name: "Team", email: "t@example.nl", url: "https://t.nl" },
+    },
+    paths: {
+      "/dieren": {
+        get: {
+          responses: {
+            200: { description: "OK", headers: { "api-version": { schema: { type: "string" } } } },
+            400: { description: "Eigen fout", content: { "application/problem+json": { schema: {} } } },
+            404: { $ref: "#/components/responses/404" },
+            409: { description: "Conflict" },
+            500: { $ref: `${ADR_COMPONENTS_URL}#/responses/500` },
+          },
+        },
+      },
+    },
+    components: {
+      headers: { "API-Version": { schema: { type: "string" } } },
+      responses: {
+        404: { description: "Niet gevonden", headers: { "API-Version": { $ref: "#/components/headers/API-Version" } } },
+      },
+    },
+  };
+
+  const { changes } = scaffoldDocument(spec, { referenceAdrComponents: true });
+  const responses = spec.paths["/dieren"].get.responses;
+
+  assert.deepEqual(responses[200].headers, { "api-version": { $ref: `${ADR_COMPONENTS_URL}#/headers/API-Version` } });
+  assert.deepEqual(responses[400], { $ref: `${ADR_COMPONENTS_URL}#/responses/400` });
+  assert.deepEqual(responses[404], { $ref: "#/components/responses/404" });
+  assert.deepEqual(responses[409], { description: "Conflict" });
+  assert.deepEqual(spec.components.responses[404], { $ref: `${ADR_COMPONENTS_URL}#/responses/404` });
+  assert.deepEqual(spec.components.headers["API-Version"], { $ref: `${ADR_COMPONENTS_URL}#/headers/API-Version` });
+  assert.deepEqual(
+    changes.map((change) => change.path),
+    [
+      "#/paths/~1dieren/get/responses/200/headers/api-version",
+      "#/paths/~1dieren/get/responses/400",
+      "#/components/responses/404",
+      "#/components/headers/API-Version",
+    ],
+  );
+});
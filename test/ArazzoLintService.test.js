@@ -0,0 +1,50 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { lintDocument } = require("../services/ArazzoLintService");
+const { buildOperationLookup } = require("../services/ArazzoVisualizationService");
+
+const openapi = { paths: { "/pets": { post: { operationId: "createPet" } } } };
+const lookups = new Map([["", { document: openapi, operations: buildOperationLookup(openapi) }]]);
+
+test("een geldig Arazzo document geeft geen bevindingen", () => {
+  const result = lintDocument(
+    {
+      arazzo: "1.0.0",
+      workflows: [
+        {
+          workflowId: "adopt",
+          steps: [{ stepId: "create", operationId: "createPet", outputs: { id: "$response.body#/id" } }],
+          outputs: { id: "$steps.create.outputs.id" },
+        },
+      ],
+    },
+    lookups,
+  );
+
+  assert.deepEqual(result, { valid: true, errorCount: 0, warningCount: 0, messages: [] });
+});
+
+test("dubbele stepIds en onbekende operationIds worden gemeld in plaats van overgeslagen", () => {
+  const result = lintDocument(
+    {
+      arazzo: "1.0.0",
+      workflows: [
+        {
+          workflowId: "adopt",
+          steps: [
+            { stepId: "create", operationId: "createPet" },
+            { stepId: "create", operationId: "deletePet" },
+          ],
+        },
+      ],
+    },
+    lookups,
+  );
+
+  assert.equal(result.valid, false);
+  assert.deepEqual(
+    result.messages.map((message) => message.code),
+    ["step-id-unique", "operation-id-resolvable"],
+  );
+  assert.deepEqual(result.messages[1].path, ["workflows", "0", "steps", "1", "operationId"]);
+});
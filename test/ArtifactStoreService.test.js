@@ -0,0 +1,51 @@
+const assert = require("node:assert/strict");
+const crypto = require("node:crypto");
+const fs = require("node:fs/promises");
+const os = require("node:os");
+const path = require("node:path");
+const test = require("node:test");
+const { ArtifactStore, parseEncryptionKey } = require("../services/ArtifactStoreService");
+
+const createStore = async (options = {}) => {
+  const directory = await fs.mkdtemp(path.join(os.tmpdir(), "artifact-store-test-"));
+  const store = new ArtifactStore({ directory, encryptionKey: crypto.randomBytes(32), ...options });
+  return { store, directory };
+};
+
+test("artifacts are encrypted on disk and round-trip through the store", async () => {
+  const { store, directory } = await createStore();
+  const filePath = await store.saveJson("lint", "run-1", { title: "Interne API" });
+
+  const raw = await fs.readFile(filePath);
+  assert.ok(!raw.toString("utf8").includes("Interne API"));
+  assert.deepEqual(await store.loadJson("lint", "run-1"), { title: "Interne API" });
+
+  await fs.rm(directory, { recursive: true, force: true });
+});
+
+test("artifacts past the retention period are purged", async () => {
+  const { store, directory } = await createStore({ retentionDays: 1 });
+  const filePath = await store.save("lint", "old", "oud");
+  await store.save("lint", "new", "nieuw");
+  const twoDaysAgo = new Date(Date.now() - 2 * 24 * 60 * 60 * 1000);
+  await fs.utimes(filePath, twoDaysAgo, twoDaysAgo);
+
+  assert.equal(await store.purgeExpired(), 1);
+  assert.equal(await store.load("lint", "old"), undefined);
+  assert.equal((await store.load("lint", "new")).toString("utf8"), "nieuw");
+
+  await fs.rm(directory, { recursive: true, force: true });
+});
+
+test("path traversal in identifiers is rejected", async () => {
+  const { store, directory } = await createStore();
+  await assert.rejects(() => store.save("lint", "../escape", "x"));
+  await fs.rm(directory, { recursive: true, force: true });
+});
+
+test("encryption keys must be 32 bytes", () => {
+  assert.equal(parseEncryptionKey(""), null);
+  assert.equal(parseEncryptionKey(crypto.randomBytes(32).toString("base64")).length, 32);
+  assert.equal(parseEncryptionKey(crypto.randomBytes(32).toString("hex")).length, 32);
+  assert.throws(() => parseEncryptionKey(crypto.randomBytes(16).toString("base64")));
+});
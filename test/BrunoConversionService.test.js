@@ -0,0 +1,115 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { buildCollection } = require("../services/BrunoConversionService");
+
+const spec = {
+  openapi: "3.0.1",
+  info: { title: "Dieren API", version: "1.0.0" },
+  servers: [{ url: "https://{env}.example.nl/v1", description: "Productie", variables: { env: { default: "api" } } }],
+  security: [{ bearer: [] }],
+  paths: {
+    "/dieren/{id}": {
+      parameters: [{ $ref: "#/components/parameters/id" }],
+      get: {
+        tags: ["dieren"],
+        summary: "Dier ophalen",
+        parameters: [{ name: "expand", in: "query", schema: { type: "string" } }],
+      },
+      put: {
+        tags: ["dieren"],
+        summary: "Dier bijwerken",
+        requestBody: {
+          content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } },
+        },
+      },
+    },
+  },
+  components: {
+    parameters: { id: { name: "id", in: "path", required: true, schema: { type: "integer", example: 42 } } },
+    schemas: {
+      Dier: {
+        type: "object",
+        properties: { id: { type: "integer", readOnly: true }, naam: { type: "string", example: "Bello" } },
+      },
+    },
+    securitySchemes: { bearer: { type: "http", scheme: "bearer" } },
+  },
+};
+
+test("buildCollection maakt een map per tag, requests en een environment per server", () => {
+  const { collectionName, files } = buildCollection(spec, { source: "request-body" });
+  const byName = Object.fromEntries(files.map((file) => [file.name, file.data]));
+
+  assert.equal(collectionName, "Dieren API");
+  assert.deepEqual(Object.keys(byName).sort(), [
+    "bruno.json",
+    "dieren/Dier-bijwerken.bru",
+    "dieren/Dier-ophalen.bru",
+    "dieren/folder.bru",
+    "environments/Productie.bru",
+  ]);
+  assert.equal(JSON.parse(byName["bruno.json"])["x-don-generated"].tool, "oas-bruno");
+  assert.equal(
+    byName["environments/Productie.bru"],
+    "vars {\n  baseUrl: https://api.example.nl/v1\n}\n\nvars:secret [\n  token\n]\n",
+  );
+
+  const get = byName["dieren/Dier-ophalen.bru"];
+  assert.match(get, /get \{\n {2}url: \{\{baseUrl\}\}\/dieren\/:id\n {2}body: none\n {2}auth: bearer\n\}/);
+  assert.match(get, /params:query \{\n {2}~expand: \n\}/);
+  assert.match(get, /params:path \{\n {2}id: 42\n\}/);
+  assert.match(get, /auth:bearer \{\n {2}token: \{\{token\}\}\n\}/);
+
+  const put = byName["dieren/Dier-bijwerken.bru"];
+  assert.match(put, /seq: 2/);
+  assert.match(put, /body:json \{\n {2}\{\n {4}"naam": "Bello"\n {2}\}\n\}/);
+});
+
+test("buildCollection zet OAuth2 client credentials op de collectie met tokenUrl en scope", () => {
+  const oauthSpec = {
+    ...spec,
+    servers: [{ url: "https://api.example.nl/v1" }],
+    security: [{ oauth: ["dieren:lezen"] }],
+    components: {
+      ...spec.components,
+      securitySchemes: {
+        oauth: {
+          type: "oauth2",
+          flows: { clientCredentials: { tokenUrl: "https://auth.example.nl/token", scopes: {} } },
+        },
+      },
+    },
+  };
+  const { files } = buildCollection(oauthSpec);
+  const byName = Object.fromEntries(files.map((file) => [file.name, file.data]));
+
+  assert.equal(
+    byName["environments/default.bru"],
+    [
+      "vars {",
+      "  baseUrl: https://api.example.nl/v1",
+      "  tokenUrl: https://auth.example.nl/token",
+      "  scope: dieren:lezen",
+      "  clientId: ",
+      "}",
+      "",
+      "vars:secret [",
+      "  clientSecret",
+      "]",
+      "",
+    ].join("\n"),
+  );
+  assert.match(byName["collection.bru"], /^auth \{\n {2}mode: oauth2\n\}\n/);
+  assert.match(byName["collection.bru"], /access_token_url: \{\{tokenUrl\}\}\n/);
+  assert.match(byName["collection.bru"], /scope: \{\{scope\}\}\n/);
+  assert.match(byName["dieren/Dier-ophalen.bru"], /auth: inherit\n/);
+  assert.doesNotMatch(byName["dieren/Dier-ophalen.bru"], /auth:oauth2/);
+});
+
+test("buildCollection zonder synthesizeExamples neemt alleen expliciete examples over", () => {
+  const { files } = buildCollection(spec, { synthesizeExamples: false });
+  const put = files.find((file) => file.name === "dieren/Dier-bijwerken.bru").data;
+
+  assert.match(put, /body: none/);
+  assert.doesNotMatch(put, /body:json/);
+});
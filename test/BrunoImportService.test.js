@@ -0,0 +1,73 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { buildCollection } = require("../services/BrunoConversionService");
+const { buildOpenApiDocument, parseBru } = require("../services/BrunoImportService");
+
+test("parseBru leest dictionary- en tekstblokken", () => {
+  const bru = parseBru(
+    [
+      "get {",
+      "  url: {{baseUrl}}/dieren/:id",
+      "  auth: bearer",
+      "}",
+      "",
+      "params:query {",
+      "  ~expand: true",
+      "}",
+      "",
+      "body:json {",
+      "  {",
+      '    "naam": "Bello"',
+      "  }",
+      "}",
+      "",
+    ].join("\n"),
+  );
+
+  assert.equal(bru.value("get", "url"), "{{baseUrl}}/dieren/:id");
+  assert.deepEqual(bru.dictionary("params:query"), [{ key: "expand", value: "true", enabled: false }]);
+  assert.equal(bru.text("body:json"), '{\n  "naam": "Bello"\n}');
+});
+
+test("buildOpenApiDocument leidt uit een Bruno export weer paden, servers en security af", () => {
+  const spec = {
+    openapi: "3.0.3",
+    info: { title: "Dieren API", version: "1.0.0" },
+    servers: [
+      { url: "https://api.example.nl/v1", description: "productie" },
+      { url: "https://test.example.nl/v1", description: "test" },
+    ],
+    security: [{ bearer: [] }],
+    components: { securitySchemes: { bearer: { type: "http", scheme: "bearer" } } },
+    paths: {
+      "/dieren/{id}": {
+        get: {
+          tags: ["dieren"],
+          summary: "Dier ophalen",
+          parameters: [
+            { name: "id", in: "path", required: true, schema: { type: "integer" }, example: 42 },
+            { name: "expand", in: "query", schema: { type: "boolean" }, example: true },
+          ],
+          responses: { 200: { description: "OK" } },
+        },
+      },
+    },
+  };
+  const { files } = buildCollection(spec);
+  const zipped = files.map((file) => ({ name: `dieren/${file.name}`, data: Buffer.from(file.data) }));
+  const document = buildOpenApiDocument(zipped);
+  const operation = document.paths["/dieren/{id}"].get;
+
+  assert.equal(document.info.title, "Dieren API");
+  assert.deepEqual(document.servers, [
+    { url: "https://api.example.nl/v1", description: "productie" },
+    { url: "https://test.example.nl/v1", description: "test" },
+  ]);
+  assert.deepEqual(document.tags, [{ name: "dieren" }]);
+  assert.equal(operation.summary, "Dier ophalen");
+  assert.deepEqual(
+    operation.parameters.map(({ name, in: location }) => `${location}:${name}`),
+    ["path:id", "query:expand"],
+  );
+  assert.deepEqual(operation.security, [{ bearerAuth: [] }]);
+});
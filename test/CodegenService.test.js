@@ -0,0 +1,215 @@
+const assert = require("node:assert/strict");
+const fs = require("node:fs/promises");
+const os = require("node:os");
+const path = require("node:path");
+const test = require("node:test");
+const {
+  generate,
+  parseServerInterface,
+  renderGoServerStubs,
+  renderPythonClient,
+} = require("../services/CodegenService");
+const { readZip } = require("../utils/zip");
+
+const generated = `package api
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Dieren ophalen
+	// (GET /dieren)
+	ListDieren(c *gin.Context, params ListDierenParams)
+	// (GET /dieren/{id})
+	GetDier(c *gin.Context, id openapi_types.UUID)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler ServerInterface
+}
+`;
+
+test("parseServerInterface leest de methodes met hun commentaar uit de gegenereerde code", () => {
+  const methods = parseServerInterface(generated);
+
+  assert.deepEqual(
+    methods.map(({ name, context, comments }) => ({ name, context, comments })),
+    [
+      { name: "ListDieren", context: "c", comments: ["// Dieren ophalen", "// (GET /dieren)"] },
+      { name: "GetDier", context: "c", comments: ["// (GET /dieren/{id})"] },
+    ],
+  );
+  assert.deepEqual(parseServerInterface("package api\n"), []);
+});
+
+test("renderGoServerStubs maakt een stub per operatie met de imports die de signatures nodig hebben", () => {
+  const source = renderGoServerStubs(parseServerInterface(generated), "gin");
+
+  assert.ok(
+    source.startsWith(
+      [
+        "package api",
+        "",
+        "import (",
+        '\t"net/http"',
+        "",
+        '\t"github.com/gin-gonic/gin"',
+        '\topenapi_types "github.com/oapi-codegen/runtime/types"',
+        ")\n",
+      ].join("\n"),
+    ),
+  );
+  assert.match(source, /var _ ServerInterface = \(\*Server\)\(nil\)/);
+  assert.ok(
+    source.endsWith(
+      [
+        "// (GET /dieren/{id})",
+        "func (s *Server) GetDier(c *gin.Context, id openapi_types.UUID) {",
+        "\tc.JSON(http.StatusNotImplemented, notImplemented)",
+        "}\n",
+      ].join("\n"),
+    ),
+  );
+
+  const ping = { name: "Ping", context: "ctx", signature: "Ping(ctx echo.Context) error", comments: [] };
+  const echo = renderGoServerStubs([ping], "echo");
+  assert.match(echo, /func \(s \*Server\) Ping\(ctx echo\.Context\) error \{\n\treturn ctx\.JSON\(/);
+});
+
+test("renderPythonClient maakt een methode per operatie met getypeerde parameters en pydantic-modellen", () => {
+  const spec = {
+    openapi: "3.0.3",
+    info: { title: "Dieren API", version: "1.2.0" },
+    servers: [{ url: "https://api.example.nl/v1" }],
+    components: { securitySchemes: { bearer: { type: "http", scheme: "bearer" } } },
+    security: [{ bearer: [] }],
+    paths: {
+      "/dieren/{id}": {
+        get: {
+          operationId: "getDier",
+          summary: "Dier ophalen",
+          parameters: [
+            { name: "id", in: "path", required: true, schema: { type: "string" } },
+            { name: "from", in: "query", schema: { type: "integer" } },
+          ],
+          responses: {
+            200: {
+              description: "OK",
+              content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } },
+            },
+          },
+        },
+      },
+    },
+  };
+  const source = renderPythonClient(spec, { modelNames: new Set(["Dier"]) });
+
+  assert.match(source, /^"""Client voor Dieren API 1\.2\.0\.\n"""\n/);
+  assert.match(source, /\nDEFAULT_BASE_URL = "https:\/\/api\.example\.nl\/v1"\n/);
+  assert.match(source, /\n {4}def get_dier\(self, id: str, \*, from_: int \| None = None\) -> models\.Dier:\n/);
+  assert.ok(
+    source.endsWith(
+      [
+        "        response = self._request(",
+        '            "GET",',
+        '            f"/dieren/{_path(id)}",',
+        '            params={"from": from_},',
+        '            auth="bearer",',
+        "        )",
+        "        return _parse(models.Dier, response.json())\n",
+      ].join("\n"),
+    ),
+  );
+});
+
+const dierenSpec = (extra = {}) =>
+  JSON.stringify({
+    openapi: "3.0.3",
+    info: { title: "Dieren", version: "1.2.0" },
+    paths: {
+      "/dieren": {
+        get: { operationId: "listDieren", responses: { 200: { description: "OK", ...extra } } },
+      },
+    },
+  });
+
+// Een nep-openapi-generator die zijn argumenten wegschrijft, of met FAIL een Java-fout geeft.
+const fakeGenerator = async (t) => {
+  const dir = await fs.mkdtemp(path.join(os.tmpdir(), "fake-generator-"));
+  const bin = path.join(dir, "openapi-generator-cli");
+  await fs.writeFile(
+    bin,
+    [
+      "#!/usr/bin/env node",
+      'const fs = require("node:fs");',
+      "const args = process.argv.slice(2);",
+      "if (process.env.FAIL) {",
+      "  console.error(`Fout in ${process.cwd()}/openapi.yaml`);",
+      '  console.error("\\tat org.openapitools.codegen.DefaultGenerator.generate(DefaultGenerator.java:1)");',
+      "  process.exit(1);",
+      "}",
+      'const out = args[args.indexOf("-o") + 1];',
+      "fs.mkdirSync(out, { recursive: true });",
+      'fs.writeFileSync(`${out}/README.md`, args.join(" "));',
+    ].join("\n"),
+    { mode: 0o755 },
+  );
+  process.env.OPENAPI_GENERATOR_BIN = bin;
+  t.after(async () => {
+    delete process.env.OPENAPI_GENERATOR_BIN;
+    delete process.env.FAIL;
+    await fs.rm(dir, { recursive: true, force: true });
+  });
+};
+
+test("generate geeft geen bestandsverwijzing of tijdelijke map door aan de generator", async (t) => {
+  await fakeGenerator(t);
+
+  const fileRef = dierenSpec({ content: { "text/plain": { schema: { $ref: "/etc/passwd" } } } });
+  await assert.rejects(
+    generate({ oasBody: fileRef }, { language: "java" }),
+    (error) => error.code === 400 && /wijst naar een bestand/.test(error.error.message),
+  );
+
+  process.env.FAIL = "1";
+  await assert.rejects(generate({ oasBody: dierenSpec() }, { language: "csharp" }), (error) => {
+    assert.equal(error.code, 400);
+    assert.equal(error.error.detail, "Fout in ./openapi.yaml");
+    return true;
+  });
+});
+
+const configOf = async (input, language) => {
+  const result = await generate(input, { language });
+  const config = readZip(result.rawBody).find((entry) => entry.name.endsWith("/openapi-generator.json"));
+  return JSON.parse(config.data.toString("utf8"));
+};
+
+test("generate geeft openapi-generator een vaste configuratie voor de Java-client", async (t) => {
+  await fakeGenerator(t);
+
+  const java = await configOf({ oasBody: dierenSpec() }, "java");
+  assert.equal(java.library, "webclient");
+  assert.equal(java.invokerPackage, "nl.dieren.client");
+  assert.equal(java.modelPackage, "nl.dieren.client.model");
+  assert.equal(java.artifactVersion, "1.2.0");
+  await assert.rejects(
+    generate({ oasBody: dierenSpec(), packageName: "Nl.Gemeente" }, { language: "java" }),
+    (error) => error.code === 400,
+  );
+});
+
+test("generate geeft de C#-client een namespace en een project-GUID die per namespace vastligt", async (t) => {
+  await fakeGenerator(t);
+
+  const input = { oasBody: dierenSpec(), packageName: "Gemeente.Dieren" };
+  const csharp = await configOf(input, "csharp");
+  assert.equal(csharp.library, "generichost");
+  assert.equal(csharp.packageName, "Gemeente.Dieren");
+  assert.equal(csharp.packageGuid, (await configOf(input, "csharp")).packageGuid);
+  assert.notEqual(csharp.packageGuid, (await configOf({ oasBody: dierenSpec() }, "csharp")).packageGuid);
+  assert.equal((await configOf({ oasBody: dierenSpec() }, "csharp")).packageName, "Dieren.Client");
+  await assert.rejects(
+    generate({ oasBody: dierenSpec(), packageName: "Gemeente-Dieren" }, { language: "csharp" }),
+    (error) => error.code === 400,
+  );
+});
@@ -0,0 +1,115 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { extract, extractLibrary, findSharedSchemas } = require("../services/ComponentLibraryService");
+
+const dieren = () => ({
+  openapi: "3.0.3",
+  info: { title: "Dieren API", version: "1.0.0" },
+  paths: {
+    "/dieren": {
+      get: {
+        responses: {
+          200: {
+            description: "OK",
+            content: { "application/json": { schema: { $ref: "#/components/schemas/Eigenaar" } } },
+          },
+        },
+      },
+    },
+  },
+  components: {
+    schemas: {
+      Adres: { type: "object", properties: { straat: { type: "string" }, huisnummer: { type: "integer" } } },
+      Eigenaar: {
+        type: "object",
+        properties: {
+          adres: { $ref: "#/components/schemas/Adres" },
+          partner: { $ref: "#/components/schemas/Eigenaar" },
+        },
+      },
+      Dier: { type: "object", properties: { naam: { type: "string" } } },
+    },
+  },
+});
+
+const planten = () => ({
+  openapi: "3.0.3",
+  info: { title: "Planten API", version: "1.0.0" },
+  paths: {},
+  components: {
+    schemas: {
+      // Zelfde structuur, andere naam en sleutelvolgorde.
+      Postadres: { properties: { huisnummer: { type: "integer" }, straat: { type: "string" } }, type: "object" },
+      Kweker: {
+        type: "object",
+        properties: {
+          adres: { $ref: "#/components/schemas/Postadres" },
+          partner: { $ref: "#/components/schemas/Kweker" },
+        },
+      },
+      Plant: { type: "object", properties: { naam: { type: "string" }, soort: { type: "string" } } },
+    },
+  },
+});
+
+test("findSharedSchemas herkent structureel gelijke schema's, ook recursieve en anders benoemde", () => {
+  const shared = findSharedSchemas([dieren(), planten()]);
+
+  assert.deepEqual(shared, [
+    {
+      name: "Adres",
+      occurrences: [
+        { index: 0, schema: "Adres" },
+        { index: 1, schema: "Postadres" },
+      ],
+    },
+    {
+      name: "Eigenaar",
+      occurrences: [
+        { index: 0, schema: "Eigenaar" },
+        { index: 1, schema: "Kweker" },
+      ],
+    },
+  ]);
+  assert.deepEqual(findSharedSchemas([dieren(), planten()], { minSpecifications: 3 }), []);
+});
+
+test("extractLibrary verplaatst gedeelde schema's en herschrijft de verwijzingen", () => {
+  const specs = [dieren(), planten()];
+  const { schemas, specs: rewritten } = extractLibrary(specs, findSharedSchemas(specs));
+
+  assert.deepEqual(Object.keys(schemas), ["Adres", "Eigenaar"]);
+  assert.equal(schemas.Eigenaar.properties.adres.$ref, "#/components/schemas/Adres");
+  assert.equal(schemas.Eigenaar.properties.partner.$ref, "#/components/schemas/Eigenaar");
+  assert.equal(
+    rewritten[0].paths["/dieren"].get.responses[200].content["application/json"].schema.$ref,
+    "components.yaml#/components/schemas/Eigenaar",
+  );
+  assert.deepEqual(Object.keys(rewritten[0].components.schemas), ["Dier"]);
+  assert.deepEqual(Object.keys(rewritten[1].components.schemas), ["Plant"]);
+});
+
+test("extract geeft met outputFormat=report de gedeelde schema's per specificatie", async () => {
+  const result = await extract(
+    {
+      specifications: [
+        { oasBody: JSON.stringify(dieren()) },
+        { name: "kwekerij", oasBody: JSON.stringify(planten()) },
+      ],
+    },
+    { outputFormat: "report" },
+  );
+  const { components } = JSON.parse(result.rawBody.toString("utf8"));
+
+  assert.deepEqual(components[0], {
+    name: "Adres",
+    occurrences: [
+      { specification: "dieren-api", schema: "Adres" },
+      { specification: "kwekerij", schema: "Postadres" },
+    ],
+  });
+  await assert.rejects(
+    extract({ specifications: [{ oasBody: JSON.stringify(dieren()) }] }),
+    (error) => error.code === 400,
+  );
+});
@@ -0,0 +1,103 @@
+const assert = require("node:assert/strict");
+const { EventEmitter } = require("node:events");
+const test = require("node:test");
+const Controller = require("../controllers/Controller");
+const Service = require("../services/Service");
+
+// Net genoeg van een Express-response om te zien wat er naar de client gaat.
+class FakeResponse extends EventEmitter {
+  constructor() {
+    super();
+    this.statusCode = 200;
+    this.headers = {};
+    this.chunks = [];
+    this.headersSent = false;
+    this.writableEnded = false;
+    this.writableFinished = false;
+  }
+
+  status(code) {
+    this.statusCode = code;
+    return this;
+  }
+
+  set(headers) {
+    Object.assign(this.headers, headers);
+    return this;
+  }
+
+  flushHeaders() {
+    this.headersSent = true;
+  }
+
+  write(chunk) {
+    this.headersSent = true;
+    this.chunks.push(chunk);
+  }
+
+  json(body) {
+    this.headersSent = true;
+    this.body = body;
+    this.end();
+  }
+
+  end() {
+    this.writableEnded = true;
+    this.writableFinished = true;
+    this.emit("close");
+  }
+
+  // De client verbreekt de verbinding voordat de response af is.
+  disconnect() {
+    this.emit("close");
+  }
+}
+
+const request = () => ({ openapi: { schema: {} }, body: {} });
+
+test("handleStreamRequest geeft een fout van voor het eerste event als gewone problem-response", async () => {
+  const response = new FakeResponse();
+
+  await Controller.handleStreamRequest(request(), response, async () => {
+    throw Service.rejectResponse({ message: "Geef een oasBody, oasUrl of oasArchive mee." }, 400);
+  });
+
+  assert.equal(response.statusCode, 400);
+  assert.equal(response.headers["Content-Type"], undefined);
+  assert.equal(response.body.detail, "Geef een oasBody, oasUrl of oasArchive mee.");
+  assert.deepEqual(response.chunks, []);
+});
+
+test("handleStreamRequest geeft een fout na het eerste event als error event", async () => {
+  const response = new FakeResponse();
+
+  await Controller.handleStreamRequest(request(), response, async (_params, emit) => {
+    emit("progress", { phase: "loading" });
+    throw Service.rejectResponse({ message: "Het ophalen van de OpenAPI specificatie is mislukt." }, 502);
+  });
+
+  assert.equal(response.statusCode, 200);
+  assert.equal(response.headers["Content-Type"], "text/event-stream; charset=utf-8");
+  const detail = "Het ophalen van de OpenAPI specificatie is mislukt.";
+  assert.deepEqual(response.chunks, [
+    'event: progress\ndata: {"phase":"loading"}\n\n',
+    `event: error\ndata: ${JSON.stringify({ status: 502, title: "Bad Gateway", detail })}\n\n`,
+  ]);
+  assert.equal(response.writableEnded, true);
+});
+
+test("handleStreamRequest breekt de service af als de client de verbinding verbreekt", async () => {
+  const response = new FakeResponse();
+  let aborted = false;
+
+  await Controller.handleStreamRequest(request(), response, async (_params, emit, signal) => {
+    emit("progress", { phase: "linting" });
+    response.disconnect();
+    aborted = signal.aborted;
+    emit("progress", { phase: "linted" });
+    throw signal.reason;
+  });
+
+  assert.equal(aborted, true);
+  assert.deepEqual(response.chunks, ['event: progress\ndata: {"phase":"linting"}\n\n']);
+});
@@ -0,0 +1,55 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { buildCurlScripts } = require("../services/CurlConversionService");
+
+const spec = {
+  openapi: "3.0.1",
+  info: { title: "Dieren API", version: "1.0.0" },
+  servers: [{ url: "https://api.example.nl/v1" }],
+  security: [{ bearer: [] }],
+  paths: {
+    "/dieren/{id}": {
+      get: {
+        operationId: "getDier",
+        summary: "Dier ophalen",
+        parameters: [
+          { name: "id", in: "path", required: true, schema: { type: "integer", example: 42 } },
+          { name: "taal", in: "query", required: true, schema: { type: "string", default: "nl" } },
+        ],
+        responses: { 200: { description: "OK", content: { "application/json": {} } } },
+      },
+    },
+    "/dieren": {
+      post: {
+        operationId: "createDier",
+        requestBody: { content: { "application/json": { example: { naam: "Bello $HOME" } } } },
+      },
+    },
+  },
+  components: { securitySchemes: { bearer: { type: "http", scheme: "bearer" } } },
+};
+
+test("buildCurlScripts maakt één script met een functie per operatie", () => {
+  const { script } = buildCurlScripts(spec);
+
+  assert.match(script, /^#!\/usr\/bin\/env bash\n/);
+  assert.match(script, /BASE_URL="\$\{BASE_URL:-https:\/\/api\.example\.nl\/v1\}"\nTOKEN="\$\{TOKEN:-\}"/);
+  assert.match(script, /getDier\(\) \{\n {2}curl --fail-with-body -sS -X GET /);
+  assert.match(script, /"\$\{BASE_URL\}\/dieren\/\$\{ID:-42\}\?taal=nl"/);
+  assert.match(script, /-H "Authorization: Bearer \$\{TOKEN\}"/);
+  assert.match(script, /--data-binary @- <<'JSON'\n\{\n {4}"naam": "Bello \$HOME"\n\}\nJSON\n\}/);
+  assert.match(script, / {2}createDier\) createDier ;;/);
+});
+
+test("buildCurlScripts met outputFormat=zip maakt een uitvoerbaar script per operatie", () => {
+  const { files } = buildCurlScripts(spec, { outputFormat: "zip" });
+
+  assert.deepEqual(
+    files.map((file) => [file.name, file.mode]),
+    [
+      ["getDier.sh", 0o755],
+      ["createDier.sh", 0o755],
+    ],
+  );
+  assert.match(files[1].data, /# createDier\ncurl --fail-with-body -sS -X POST "\$\{BASE_URL\}\/dieren"/);
+});
@@ -0,0 +1,24 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { buildSite } = require("../services/DocsSiteService");
+
+const spec = () => ({
+  openapi: "3.0.1",
+  info: { title: "Dieren <API>", version: "1.0.0" },
+  paths: {},
+});
+
+test("buildSite maakt een Swagger UI of Elements site met de specificatie in spec.js", () => {
+  const swagger = Object.fromEntries(buildSite(spec()).files.map((file) => [file.name, file.data]));
+
+  assert.deepEqual(Object.keys(swagger), ["index.html", "spec.js", "openapi.json"]);
+  assert.match(swagger["index.html"], /<title>Dieren &lt;API&gt;<\/title>/);
+  assert.match(swagger["index.html"], /swagger-ui-dist@5\/swagger-ui-bundle\.js/);
+  assert.match(swagger["index.html"], /<script src="spec\.js"><\/script>\n {4}<script>\n {6}window\.ui = /);
+  assert.match(swagger["spec.js"], /^window\.OPENAPI_SPEC = \{\n {2}"openapi": "3\.0\.1",/);
+  assert.equal(JSON.parse(swagger["openapi.json"])["x-don-generated"].tool, "oas-swagger-ui");
+
+  const elements = buildSite(spec(), { renderer: "elements" }).files[0].data;
+  assert.match(elements, /<elements-api id="docs" router="hash" layout="sidebar"><\/elements-api>/);
+  assert.match(elements, /apiDescriptionDocument = window\.OPENAPI_SPEC;/);
+});
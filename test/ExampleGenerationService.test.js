@@ -0,0 +1,111 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const {
+  buildExampleMap,
+  injectContentExamples,
+  injectExamples,
+  sampleFromPattern,
+} = require("../services/ExampleGenerationService");
+
+const createDocument = (openapi = "3.0.3") => ({
+  openapi,
+  info: { title: "Dieren API", version: "1.0.0" },
+  paths: {
+    "/dieren": {
+      post: {
+        requestBody: { content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } } },
+        responses: {
+          201: {
+            description: "Aangemaakt",
+            content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } },
+          },
+          400: {
+            description: "Fout",
+            content: { "application/problem+json": { example: { status: 400 } } },
+          },
+        },
+      },
+    },
+  },
+  components: {
+    schemas: {
+      Dier: {
+        type: "object",
+        properties: {
+          id: { type: "string", format: "uuid", readOnly: true },
+          naam: { type: "string" },
+          chipnummer: { type: "string", pattern: "^528[0-9]{12}$" },
+          leeftijd: { type: "integer", minimum: 0, exclusiveMinimum: true, maximum: 40 },
+          gewicht: { type: "number", minimum: 2.1, multipleOf: 0.5 },
+          soort: { type: "string", enum: ["kat", "hond"] },
+          kenmerken: { type: "array", minItems: 2, items: { type: "string", maxLength: 4 } },
+          moeder: { $ref: "#/components/schemas/Dier" },
+          eigenaar: { $ref: "#/components/schemas/Eigenaar" },
+        },
+      },
+      Eigenaar: {
+        allOf: [
+          { type: "object", properties: { email: { type: "string" }, postcode: { type: "string" } } },
+          { type: "object", properties: { geregistreerd: { type: "string", format: "date" } } },
+        ],
+      },
+    },
+  },
+});
+
+test("sampleFromPattern maakt een string die aan het pattern voldoet", () => {
+  assert.equal(sampleFromPattern("^[1-9][0-9]{3}\\s?[A-Z]{2}$"), "1000 AA");
+  assert.equal(sampleFromPattern("^(NL|BE)\\d{2}$"), "NL11");
+  assert.equal(sampleFromPattern("^[^@\\s]+@[^@\\s]+\\.nl$"), "a@a.nl");
+  assert.equal(sampleFromPattern("^(a)\\1$"), undefined);
+});
+
+test("buildExampleMap geeft per schema een voorbeeld dat formats, patterns en grenzen respecteert", () => {
+  const examples = buildExampleMap(createDocument());
+
+  assert.deepEqual(examples.Eigenaar, { email: "info@example.nl", postcode: "2511 AB", geregistreerd: "2024-01-15" });
+  assert.deepEqual(examples.Dier, {
+    id: "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+    naam: "Jan Jansen",
+    chipnummer: "528000000000000",
+    leeftijd: 1,
+    gewicht: 2.5,
+    soort: "kat",
+    kenmerken: ["voor", "voor"],
+    eigenaar: examples.Eigenaar,
+  });
+});
+
+test("injectExamples vult alleen ontbrekende voorbeelden aan, zonder readOnly velden in request bodies", () => {
+  const document = createDocument("3.1.0");
+  const inserted = injectExamples(document);
+  const operation = document.paths["/dieren"].post;
+
+  assert.equal(inserted, 4);
+  assert.equal(operation.requestBody.content["application/json"].example.id, undefined);
+  assert.equal(operation.responses[201].content["application/json"].example.id, "3fa85f64-5717-4562-b3fc-2c963f66afa6");
+  assert.deepEqual(operation.responses[400].content["application/problem+json"].example, { status: 400 });
+  assert.equal(document.components.schemas.Dier.examples.length, 1);
+  assert.equal(document.components.schemas.Dier.example, undefined);
+});
+
+test("injectContentExamples vult alleen request- en responsebodies aan, ook in webhooks en components", () => {
+  const document = createDocument();
+  document.webhooks = {
+    dierGeboren: {
+      post: { requestBody: { content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } } } },
+    },
+  };
+  document.components.responses = {
+    NietGevonden: {
+      description: "Niet gevonden",
+      content: { "application/json": { schema: { type: "object", properties: { status: { type: "integer" } } } } },
+    },
+  };
+
+  assert.equal(injectContentExamples(document), 4);
+  assert.equal(document.webhooks.dierGeboren.post.requestBody.content["application/json"].example.naam, "Jan Jansen");
+  assert.deepEqual(document.components.responses.NietGevonden.content["application/json"].example, { status: 1 });
+  assert.equal(document.components.schemas.Dier.example, undefined);
+  assert.equal(document.components.schemas.Eigenaar.example, undefined);
+});
@@ -0,0 +1,55 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { buildOpenApiDocument, templatePath } = require("../services/HarImportService");
+
+const entry = (method, url, { resourceType = "fetch", headers = [], status = 200, body, postData } = {}) => ({
+  _resourceType: resourceType,
+  request: { method, url, headers, queryString: [], postData },
+  response: { status, content: body === undefined ? {} : { mimeType: "application/json", text: JSON.stringify(body) } },
+});
+
+test("templatePath maakt padparameters van identificaties", () => {
+  assert.deepEqual(templatePath("/dieren/42/foto's/3f2b8c1e-0d4a-4e8a-9b7c-1a2b3c4d5e6f"), {
+    path: "/dieren/{dierenId}/foto's/{fotosId}",
+    pathParameters: [
+      { name: "dierenId", value: "42" },
+      { name: "fotosId", value: "3f2b8c1e-0d4a-4e8a-9b7c-1a2b3c4d5e6f" },
+    ],
+  });
+  assert.equal(templatePath("/v1/dieren").path, "/v1/dieren");
+});
+
+test("buildOpenApiDocument voegt API-verzoeken uit een HAR samen tot operaties", () => {
+  const har = {
+    log: {
+      entries: [
+        entry("GET", "https://portaal.example.nl/", { resourceType: "document" }),
+        entry("GET", "https://api.example.nl/v1/dieren/42", {
+          headers: [
+            { name: "Authorization", value: "Bearer geheim" },
+            { name: "sec-ch-ua", value: "Chromium" },
+          ],
+          body: { id: 42, naam: "Bello" },
+        }),
+        entry("GET", "https://api.example.nl/v1/dieren/43", { body: { id: 43, gewicht: 12.5 } }),
+        entry("OPTIONS", "https://api.example.nl/v1/dieren", { status: 204 }),
+      ],
+    },
+  };
+  const document = buildOpenApiDocument(har);
+  const operation = document.paths["/dieren/{dierenId}"].get;
+
+  assert.deepEqual(document.servers, [{ url: "https://api.example.nl/v1" }]);
+  assert.deepEqual(Object.keys(document.paths), ["/dieren/{dierenId}"]);
+  assert.deepEqual(Object.keys(operation.responses["200"].content["application/json"].schema.properties), [
+    "id",
+    "naam",
+    "gewicht",
+  ]);
+  assert.deepEqual(
+    operation.parameters.map(({ name, in: location }) => `${location}:${name}`),
+    ["path:dierenId"],
+  );
+  assert.deepEqual(operation.security, [{ bearerAuth: [] }]);
+  assert.doesNotMatch(JSON.stringify(document), /geheim/);
+});
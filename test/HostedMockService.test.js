@@ -0,0 +1,147 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { HostedMockStore, createMock, handleRequest, removeMock } = require("../services/HostedMockService");
+
+const spec = {
+  openapi: "3.0.3",
+  info: { title: "Dieren API", version: "1.0.0" },
+  paths: {
+    "/dieren": {
+      get: {
+        parameters: [{ name: "soort", in: "query", required: true, schema: { type: "string" } }],
+        responses: {
+          200: {
+            description: "OK",
+            content: {
+              "application/json": { schema: { type: "array", items: { $ref: "#/components/schemas/Dier" } } },
+            },
+          },
+        },
+      },
+      post: {
+        responses: {
+          201: {
+            description: "Aangemaakt",
+            content: {
+              "application/json": { examples: { kat: { value: { naam: "Tom" } }, hond: { value: { naam: "Rex" } } } },
+            },
+          },
+          409: { description: "Bestaat al" },
+        },
+      },
+    },
+    "/dieren/zoeken": { get: { responses: { 204: { description: "Geen resultaten" } } } },
+    "/dieren/{id}": {
+      get: {
+        responses: {
+          200: {
+            description: "OK",
+            content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } },
+          },
+        },
+      },
+    },
+  },
+  components: {
+    schemas: {
+      Dier: {
+        type: "object",
+        properties: {
+          id: { type: "string", readOnly: true, example: "d1" },
+          naam: { type: "string", example: "Tom" },
+          wachtwoord: { type: "string", writeOnly: true },
+        },
+      },
+    },
+  },
+};
+
+const parse = (response) => JSON.parse(response.payload.toString("utf8"));
+
+test("handleRequest beantwoordt requests uit voorbeelden en schema's en volgt de Prefer header", async () => {
+  const store = new HostedMockStore();
+  const mock = await createMock({ oasBody: JSON.stringify(spec) }, store);
+  assert.equal(mock.url, `/v1/mock/${mock.id}`);
+  assert.deepEqual(mock.operations[2], { method: "GET", path: "/dieren/zoeken" });
+
+  const request = (method, path, extra = {}) => handleRequest(mock.id, { method, path, headers: {}, ...extra }, store);
+
+  const list = request("GET", "/dieren", { query: { soort: "kat" } });
+  assert.equal(list.code, 200);
+  assert.equal(list.headers["Content-Type"], "application/json");
+  assert.deepEqual(parse(list), [{ id: "d1", naam: "Tom" }]);
+
+  assert.deepEqual(parse(request("POST", "/dieren")), { naam: "Tom" });
+  assert.deepEqual(parse(request("POST", "/dieren", { headers: { prefer: "example=hond" } })), { naam: "Rex" });
+  assert.equal(request("POST", "/dieren", { headers: { prefer: "code=409" } }).code, 409);
+  assert.equal(request("GET", "/dieren/zoeken").code, 204);
+  assert.deepEqual(parse(request("GET", "/dieren/42")), { id: "d1", naam: "Tom" });
+});
+
+test("handleRequest geeft problem-responses voor requests die niet bij de specificatie passen", async () => {
+  const store = new HostedMockStore({ maxMocks: 1 });
+  const mock = await createMock({ oasBody: JSON.stringify(spec) }, store);
+  const request = (method, path, extra = {}) => handleRequest(mock.id, { method, path, headers: {}, ...extra }, store);
+
+  const missing = request("GET", "/dieren", { query: {} });
+  assert.equal(missing.code, 422);
+  assert.deepEqual(parse(missing).invalidParams, [{ name: "soort", reason: "Verplichte query-parameter ontbreekt." }]);
+  assert.equal(request("GET", "/katten").code, 404);
+  const notAllowed = request("DELETE", "/dieren/42");
+  assert.equal(notAllowed.code, 405);
+  assert.equal(notAllowed.headers.Allow, "GET");
+
+  await assert.rejects(createMock({ oasBody: JSON.stringify(spec) }, store), { code: 429 });
+  removeMock(mock.id, store);
+  assert.throws(() => request("GET", "/dieren/42"), { code: 404 });
+});
+
+test("HostedMockStore ruimt mocks op na de TTL", () => {
+  let now = 0;
+  const store = new HostedMockStore({ ttlMinutes: 1, now: () => now });
+  const mock = store.add(spec);
+
+  now = 59 * 1000;
+  assert.equal(store.get(mock.id), mock);
+  now = 60 * 1000;
+  assert.equal(store.get(mock.id), undefined);
+  assert.equal(store.mocks.size, 0);
+});
+
+test("handleRequest stuurt geen beveiligingsheaders uit de specificatie mee en sandboxt elke response", async () => {
+  const store = new HostedMockStore();
+  const page = {
+    openapi: "3.0.3",
+    info: { title: "Pagina", version: "1.0.0" },
+    paths: {
+      "/pagina": {
+        get: {
+          responses: {
+            200: {
+              description: "OK",
+              headers: {
+                "Set-Cookie": { schema: { type: "string", example: "sessie=overgenomen" } },
+                "Access-Control-Allow-Origin": { schema: { type: "string", example: "*" } },
+                "X-Rate-Limit": { schema: { type: "integer", example: 100 } },
+              },
+              content: { "text/html": { example: "<script>alert(1)</script>" } },
+            },
+          },
+        },
+      },
+    },
+  };
+  const mock = await createMock({ oasBody: JSON.stringify(page) }, store);
+  const request = (headers = {}) => handleRequest(mock.id, { method: "GET", path: "/pagina", headers }, store);
+
+  const response = request();
+  assert.deepEqual(response.headers, {
+    "X-Rate-Limit": "100",
+    "Content-Type": "text/html",
+    "Content-Security-Policy": "sandbox",
+    "X-Content-Type-Options": "nosniff",
+  });
+  const informational = request({ prefer: "code=101" });
+  assert.equal(informational.code, 400);
+  assert.equal(informational.headers["Content-Security-Policy"], "sandbox");
+});
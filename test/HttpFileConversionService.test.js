@@ -0,0 +1,52 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { buildHttpFiles } = require("../services/HttpFileConversionService");
+
+const spec = {
+  openapi: "3.0.1",
+  info: { title: "Dieren API", version: "1.0.0" },
+  servers: [
+    { url: "https://api.example.nl/v1", description: "Productie" },
+    { url: "https://test.example.nl/v1", description: "Test" },
+  ],
+  security: [{ basic: [] }],
+  paths: {
+    "/dieren/{id}": {
+      get: {
+        tags: ["dieren"],
+        operationId: "getDier",
+        parameters: [
+          { name: "id", in: "path", required: true, schema: { type: "string" } },
+          { name: "taal", in: "query", required: true, schema: { type: "string", enum: ["nl", "en"] } },
+          { name: "expand", in: "query", schema: { type: "string" } },
+        ],
+      },
+    },
+    "/eigenaren": {
+      post: {
+        tags: ["eigenaren"],
+        summary: "Eigenaar aanmaken",
+        requestBody: { content: { "application/json": { example: { naam: "Jan" } } } },
+      },
+    },
+  },
+  components: { securitySchemes: { basic: { type: "http", scheme: "basic" } } },
+};
+
+test("buildHttpFiles maakt een .http bestand per tag en environments per server", () => {
+  const { files } = buildHttpFiles(spec);
+  const byName = Object.fromEntries(files.map((file) => [file.name, file.data]));
+
+  assert.deepEqual(Object.keys(byName), ["dieren.http", "eigenaren.http", ".vscode/settings.json"]);
+  assert.match(
+    byName["dieren.http"],
+    /### getDier\n# @name getDier\n# Optioneel: expand \(query\)\nGET \{\{baseUrl\}\}\/dieren\/\{\{id\}\}\?taal=nl\n/,
+  );
+  assert.match(byName["dieren.http"], /Authorization: Basic \{\{username\}\} \{\{password\}\}/);
+  assert.match(byName["eigenaren.http"], /POST \{\{baseUrl\}\}\/eigenaren\n.*\nContent-Type: application\/json\n\n\{/);
+  assert.deepEqual(JSON.parse(byName[".vscode/settings.json"])["rest-client.environmentVariables"], {
+    $shared: { username: "", password: "", id: "" },
+    Productie: { baseUrl: "https://api.example.nl/v1" },
+    Test: { baseUrl: "https://test.example.nl/v1" },
+  });
+});
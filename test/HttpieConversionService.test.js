@@ -0,0 +1,30 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { buildHttpieScripts } = require("../services/HttpieConversionService");
+
+const spec = {
+  openapi: "3.0.1",
+  info: { title: "Dieren API", version: "1.0.0" },
+  security: [{ sleutel: [] }],
+  paths: {
+    "/dieren/{id}": {
+      put: {
+        operationId: "updateDier",
+        parameters: [
+          { name: "id", in: "path", required: true, schema: { type: "string" } },
+          { name: "taal", in: "query", required: true, schema: { type: "string", enum: ["nl"] } },
+        ],
+        requestBody: { content: { "application/json": { example: { naam: "Bello" } } } },
+      },
+    },
+  },
+  components: { securitySchemes: { sleutel: { type: "apiKey", in: "header", name: "X-Api-Key" } } },
+};
+
+test("buildHttpieScripts maakt HTTPie-aanroepen met query-items, headers en body via stdin", () => {
+  const { script } = buildHttpieScripts(spec);
+
+  assert.match(script, /BASE_URL="\$\{BASE_URL:-http:\/\/localhost\}"\nAPI_KEY="\$\{API_KEY:-\}"/);
+  assert.match(script, /http --check-status PUT "\$\{BASE_URL\}\/dieren\/\$\{ID:\?Zet ID\}" \\\n {4}"taal==nl"/);
+  assert.match(script, /"X-Api-Key:\$\{API_KEY\}" \\\n {4}Content-Type:application\/json \\\n {4}<<'JSON'\n\{/);
+});
@@ -0,0 +1,45 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { buildTests } = require("../services/HurlConversionService");
+
+const spec = {
+  openapi: "3.0.1",
+  info: { title: "Dieren API", version: "1.2.0" },
+  servers: [{ url: "https://api.example.nl/v1" }],
+  security: [{ bearer: [] }],
+  paths: {
+    "/dieren/{id}": {
+      get: {
+        operationId: "getDier",
+        parameters: [
+          { name: "id", in: "path", required: true, schema: { type: "string" } },
+          { name: "expand", in: "query", schema: { type: "string", example: "eigenaar" } },
+        ],
+        responses: { 200: { description: "OK" }, 404: { description: "Niet gevonden" } },
+      },
+    },
+    "/dieren": {
+      post: {
+        summary: "Dier aanmaken",
+        requestBody: { content: { "application/json": { example: { naam: "Bello" } } } },
+        responses: { default: { description: "Fout" } },
+      },
+    },
+  },
+  components: { securitySchemes: { bearer: { type: "http", scheme: "bearer" } } },
+};
+
+test("buildTests maakt een .hurl bestand per operatie met asserts op status en API-Version", () => {
+  const { files } = buildTests(spec);
+  const byName = Object.fromEntries(files.map((file) => [file.name, file.data]));
+
+  assert.deepEqual(Object.keys(byName), ["getDier.hurl", "Dier-aanmaken.hurl", "hurl.env"]);
+  const get = byName["getDier.hurl"];
+  assert.match(get, /GET \{\{baseUrl\}\}\/dieren\/\{\{id\}\}\nAuthorization: Bearer \{\{token\}\}\n/);
+  assert.match(get, /\[QueryStringParams\]\n# expand: eigenaar\n/);
+  assert.match(get, /\nHTTP 200\n\[Asserts\]\nheader "API-Version" == "1\.2\.0"\n$/);
+
+  const post = byName["Dier-aanmaken.hurl"];
+  assert.match(post, /\{\n {2}"naam": "Bello"\n\}\n\nHTTP \*\n\[Asserts\]\nstatus < 400\n/);
+  assert.equal(byName["hurl.env"], "baseUrl=https://api.example.nl/v1\ntoken=\nid=\n");
+});
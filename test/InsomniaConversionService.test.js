@@ -0,0 +1,47 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { buildExport } = require("../services/InsomniaConversionService");
+
+const spec = {
+  openapi: "3.0.1",
+  info: { title: "Dieren API", version: "1.0.0" },
+  servers: [{ url: "https://api.example.nl/v1", description: "Productie" }],
+  paths: {
+    "/dieren/{id}": {
+      get: {
+        tags: ["dieren"],
+        summary: "Dier ophalen",
+        security: [{ sleutel: [] }],
+        parameters: [
+          { name: "id", in: "path", required: true, schema: { type: "integer", example: 42 } },
+          { name: "expand", in: "query", schema: { type: "string" } },
+        ],
+      },
+    },
+  },
+  components: { securitySchemes: { sleutel: { type: "apiKey", in: "header", name: "X-Api-Key" } } },
+};
+
+test("buildExport maakt een Insomnia v4 export met environments, groups en requests", () => {
+  const now = new Date("2024-05-01T12:00:00Z");
+  const first = buildExport(spec, { source: "request-body", now }).export;
+  const second = buildExport(spec, { source: "request-body", now }).export;
+
+  assert.equal(first.__export_format, 4);
+  assert.deepEqual(first, second, "ids zijn stabiel tussen exports");
+  const byType = (type) => first.resources.filter((resource) => resource._type === type);
+  const [workspace] = byType("workspace");
+  const [base, productie] = byType("environment");
+  const [group] = byType("request_group");
+  const [request] = byType("request");
+
+  assert.equal(workspace.name, "Dieren API");
+  assert.deepEqual(base.data, { baseUrl: "https://api.example.nl/v1", apiKey: "" });
+  assert.equal(productie.parentId, base._id);
+  assert.equal(group.parentId, workspace._id);
+  assert.equal(request.parentId, group._id);
+  assert.equal(request.url, "{{ _.baseUrl }}/dieren/:id");
+  assert.deepEqual(request.pathParameters, [{ name: "id", value: "42" }]);
+  assert.deepEqual(request.parameters, [{ name: "expand", value: "", disabled: true }]);
+  assert.deepEqual(request.headers, [{ name: "X-Api-Key", value: "{{ _.apiKey }}" }]);
+});
@@ -0,0 +1,97 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { buildFeatures } = require("../services/KarateConversionService");
+
+const spec = {
+  openapi: "3.0.1",
+  info: { title: "Dieren API", version: "1.2.0" },
+  servers: [{ url: "https://api.example.nl/v1" }, { url: "https://acc.example.nl/v1", description: "acceptatie" }],
+  security: [{ bearer: [] }],
+  paths: {
+    "/dieren/{id}": {
+      get: {
+        operationId: "getDier",
+        tags: ["Dieren"],
+        parameters: [
+          { name: "id", in: "path", required: true, schema: { type: "string" } },
+          { name: "expand", in: "query", schema: { type: "string", example: "eigenaar" } },
+        ],
+        responses: {
+          200: {
+            description: "OK",
+            content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } },
+          },
+        },
+      },
+    },
+    "/dieren": {
+      get: {
+        summary: "Dieren zoeken",
+        responses: {
+          200: {
+            description: "OK",
+            content: {
+              "application/json": { schema: { type: "array", items: { $ref: "#/components/schemas/Dier" } } },
+            },
+          },
+        },
+      },
+      post: {
+        summary: "Dier aanmaken",
+        requestBody: { content: { "application/json": { example: { naam: "Bello" } } } },
+        responses: { default: { description: "Fout" } },
+      },
+    },
+  },
+  components: {
+    securitySchemes: { bearer: { type: "http", scheme: "bearer" } },
+    schemas: {
+      Dier: {
+        type: "object",
+        required: ["id", "naam"],
+        properties: {
+          id: { type: "string", format: "uuid" },
+          naam: { type: "string" },
+          leeftijd: { type: "integer" },
+          eigenaar: { type: "object", properties: { naam: { type: "string" } } },
+          tags: { type: "array", items: { type: "string" } },
+        },
+      },
+    },
+  },
+};
+
+test("buildFeatures maakt een feature per operatie met status-, header- en schema-asserts", () => {
+  const { files } = buildFeatures(spec);
+  const byName = Object.fromEntries(files.map((file) => [file.name, file.data]));
+
+  assert.deepEqual(Object.keys(byName), [
+    "getDier.feature",
+    "Dieren-zoeken.feature",
+    "Dier-aanmaken.feature",
+    "karate-config.js",
+  ]);
+  const get = byName["getDier.feature"];
+  assert.match(get, /\n {2}@Dieren\n {2}Scenario: GET \/dieren\/\{id\}\n {4}Given path "dieren", id\n/);
+  assert.match(get, /\n {4}# And param expand = "eigenaar"\n {4}And header Authorization = 'Bearer ' \+ token\n/);
+  assert.match(get, /\n {4}When method get\n {4}Then status 200\n/);
+  assert.match(get, /And match apiVersion == "1\.2\.0"\n/);
+  assert.match(get, /\* def eigenaarSchema =\n {6}"""\n {6}\{\n {8}"naam": "##string"\n {6}\}\n {6}"""\n/);
+  assert.match(get, /"id": "#uuid",\n {8}"naam": "#string",\n {8}"leeftijd": "##number",/);
+  assert.match(get, /"eigenaar": "##\(eigenaarSchema\)",\n {8}"tags": "##\[\] #string"/);
+
+  const list = byName["Dieren-zoeken.feature"];
+  assert.match(list, /\* def dierSchema =\n/);
+  assert.match(list, /And match response == "#\[\] dierSchema"\n$/);
+
+  const post = byName["Dier-aanmaken.feature"];
+  assert.match(post, /And request\n {6}"""\n {6}\{\n {8}"naam": "Bello"\n {6}\}\n {6}"""\n {4}When method post\n/);
+  assert.match(post, /Then assert responseStatus < 400\n/);
+  assert.doesNotMatch(post, /match response/);
+
+  const config = byName["karate-config.js"];
+  assert.match(config, /baseUrl: "https:\/\/api\.example\.nl\/v1",\n/);
+  assert.match(config, /\n {4}token: karate\.properties\["token"\] \|\| '',\n/);
+  assert.match(config, /\n {4}id: karate\.properties\["id"\] \|\| ''\n/);
+  assert.match(config, /if \(karate\.env == "acceptatie"\) \{\n {4}config\.baseUrl = "https:\/\/acc\.example\.nl/);
+});
@@ -0,0 +1,61 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { ERROR_CODES, KeycloakService } = require("../services/KeycloakService");
+
+const client = (id, owner) => ({ id, clientId: id, enabled: true, attributes: { owner: [owner] } });
+
+// Een Keycloak die de q-parameter negeert (zoals oudere versies) en de clients per pagina teruggeeft.
+const fakeKeycloak = (clients) => {
+  const deleted = [];
+  const fetchImpl = async (url, init = {}) => {
+    const parsed = new URL(url);
+    if (parsed.pathname.endsWith("/token")) {
+      return Response.json({ access_token: "token" });
+    }
+    if (init.method === "DELETE") {
+      deleted.push(decodeURIComponent(parsed.pathname.split("/").pop()));
+      return new Response(null, { status: 204 });
+    }
+    const first = Number(parsed.searchParams.get("first") ?? 0);
+    const max = Number(parsed.searchParams.get("max") ?? 100);
+    return Response.json(clients.slice(first, first + max));
+  };
+  const service = new KeycloakService({
+    adminClientsURL: "https://keycloak.test/admin/realms/don/clients",
+    tokenURL: "https://keycloak.test/realms/don/protocol/openid-connect/token",
+    clientId: "tools",
+    clientSecret: "geheim",
+    fetchImpl,
+  });
+  return { service, deleted };
+};
+
+const clients = [
+  ...Array.from({ length: 150 }, (_, index) => client(`anna-${index}`, "anna")),
+  ...Array.from({ length: 30 }, (_, index) => client(`bram-${index}`, "bram")),
+];
+
+test("listClientsForOwner loopt alle pagina's af en geeft alleen de clients van de eigenaar", async () => {
+  const { service } = fakeKeycloak(clients);
+
+  const anna = await service.listClientsForOwner("anna");
+  const bram = await service.listClientsForOwner("bram");
+
+  assert.equal(anna.length, 150);
+  assert.ok(anna.some((summary) => summary.apiKey === "anna-149"));
+  assert.deepEqual(
+    bram.map((summary) => summary.apiKey),
+    clients.slice(150).map(({ id }) => id),
+  );
+});
+
+test("deleteClientForOwner verwijdert geen client van een andere eigenaar", async () => {
+  const { service, deleted } = fakeKeycloak(clients);
+
+  await assert.rejects(service.deleteClientForOwner("bram", "anna-3"), { code: ERROR_CODES.NOT_FOUND });
+  await assert.rejects(service.deleteClientForOwner("anna", "bestaat-niet"), { code: ERROR_CODES.NOT_FOUND });
+  assert.deepEqual(deleted, []);
+
+  await service.deleteClientForOwner("anna", "anna-120");
+  assert.deepEqual(deleted, ["anna-120"]);
+});
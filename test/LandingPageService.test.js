@@ -0,0 +1,43 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { renderLandingPage, renderScoreBadge } = require("../services/LandingPageService");
+
+const lintResult = { score: 87, rulesetVersion: "2.1", failures: 1, createdAt: "2026-01-02T03:04:05.000Z" };
+
+test("renderLandingPage toont info, servers, score en downloads en escapet tekst uit de specificatie", () => {
+  const document = {
+    openapi: "3.0.3",
+    info: {
+      title: "Dieren <API>",
+      version: "1.2.0",
+      contact: { name: "Team Dieren", email: "dieren@example.nl", url: "javascript:alert(1)" },
+      license: { name: "EUPL-1.2", url: "https://eupl.eu/1.2/nl/" },
+    },
+    servers: [{ url: "https://{omgeving}.example.nl/v1", variables: { omgeving: { default: "api" } } }],
+  };
+  const page = renderLandingPage(document, {
+    lintResult,
+    downloads: [
+      { href: "openapi.json", label: "OpenAPI-specificatie" },
+      { href: "downloads/dieren-postman.zip", label: "Postman-collectie" },
+    ],
+    provenance: { tool: "oas-landing-page" },
+  });
+
+  assert.match(page, /<title>Dieren &lt;API&gt;<\/title>/);
+  assert.match(page, /<p class="version">Versie 1\.2\.0<\/p>/);
+  assert.match(page, /<li><code>https:\/\/api\.example\.nl\/v1<\/code><\/li>/);
+  assert.match(page, /<p>Score 87% op de ADR 2\.1 ruleset op 2026-01-02, met 1 error-bevinding\.<\/p>/);
+  assert.match(page, /<li><a href="downloads\/dieren-postman\.zip">Postman-collectie<\/a><\/li>/);
+  assert.match(page, /<a href="mailto:dieren@example\.nl">dieren@example\.nl<\/a><br>javascript:alert\(1\)<\/p>/);
+  assert.doesNotMatch(page, /href="javascript:/);
+});
+
+test("renderScoreBadge kleurt de badge naar de score", () => {
+  const badge = renderScoreBadge(lintResult);
+
+  assert.match(badge, /aria-label="ADR 2\.1: 87%"/);
+  assert.match(badge, /fill="#689f38"/);
+  assert.match(renderScoreBadge({ score: 100, rulesetVersion: "owasp" }), /<title>OWASP: 100%<\/title>/);
+  assert.match(renderScoreBadge({ score: 20, rulesetVersion: "2.0" }), /fill="#c62828"/);
+});
@@ -0,0 +1,46 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { diff, diffLintResults } = require("../services/LintDiffService");
+
+const message = (code, path, severity = "error") => ({
+  code,
+  severity,
+  infos: [{ path, message: `${code} op ${path}` }],
+});
+
+const lintResult = (id, score, messages) => ({ id, score, failures: messages.length, rulesetVersion: "2.1", messages });
+
+test("diffLintResults deelt bevindingen in als nieuw, opgelost of ongewijzigd", () => {
+  const base = lintResult("base", 80, [
+    message("semver", "info.version"),
+    message("paths-kebab-case", "paths./Users"),
+    message("paths-kebab-case", "paths./Users"),
+  ]);
+  const head = lintResult("head", 90, [
+    message("paths-kebab-case", "paths./Users"),
+    message("missing-version-header", "paths./users.get", "warning"),
+  ]);
+
+  const result = diffLintResults(base, head);
+
+  assert.equal(result.scoreDelta, 10);
+  assert.equal(result.verdict, "improved");
+  assert.deepEqual(result.summary, { new: 1, resolved: 2, unchanged: 1 });
+  assert.deepEqual(result.new.map((finding) => finding.code), ["missing-version-header"]);
+  assert.deepEqual(result.resolved.map((finding) => finding.code).sort(), ["paths-kebab-case", "semver"]);
+});
+
+test("diffLintResults markeert nieuwe errors bij gelijke score als regressie", () => {
+  const result = diffLintResults(lintResult("a", 100, []), lintResult("b", 100, [message("semver", "info.version")]));
+
+  assert.equal(result.verdict, "regressed");
+});
+
+test("diff geeft 404 als een opgeslagen lint-run niet bestaat", async () => {
+  const store = { get: async (id) => (id === "base" ? lintResult("base", 100, []) : undefined) };
+
+  await assert.rejects(
+    diff({ base: { lintId: "base" }, head: { lintId: "onbekend" } }, { store }),
+    (error) => error.code === 404,
+  );
+});
@@ -0,0 +1,39 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { applyLintFixes } = require("../services/LintFixService");
+
+test("applyLintFixes corrigeert trailing slashes, contact en http-servers", () => {
+  const spec = {
+    openapi: "3.0.3",
+    info: { title: "Dieren API", version: "1.0.0" },
+    servers: [{ url: "http://api.example.nl/v1" }, { url: "https://acc.example.nl/v1" }],
+    paths: {
+      "/": { get: { responses: {} } },
+      "/dieren/": { get: { servers: [{ url: "HTTP://upload.example.nl" }], responses: {} } },
+      "/soorten/": { get: { responses: {} } },
+      "/soorten": { post: { responses: {} } },
+    },
+  };
+
+  const { fixes } = applyLintFixes(spec);
+
+  assert.deepEqual(Object.keys(spec.paths), ["/", "/dieren", "/soorten/", "/soorten"]);
+  assert.deepEqual(spec.info.contact, {
+    name: "@TODO: Add contact name",
+    email: "@TODO: Add contact email",
+    url: "@TODO: Add contact url",
+  });
+  assert.equal(spec.servers[0].url, "https://api.example.nl/v1");
+  assert.equal(spec.paths["/dieren"].get.servers[0].url, "https://upload.example.nl");
+  assert.deepEqual(
+    fixes.map(({ rule, path }) => `${rule} ${path}`),
+    [
+      "owasp:api8:2023-no-server-http #/servers/0/url",
+      "owasp:api8:2023-no-server-http #/paths/~1dieren~1/get/servers/0/url",
+      "paths-no-trailing-slash #/paths/~1dieren~1",
+      "info-contact-fields-exist #/info/contact/name",
+      "info-contact-fields-exist #/info/contact/email",
+      "info-contact-fields-exist #/info/contact/url",
+    ],
+  );
+});
@@ -0,0 +1,35 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { renderMarkdown } = require("../services/LintMarkdownService");
+
+const message = (code, severity, path, text) => ({ code, severity, infos: [{ path, message: text }] });
+
+test("renderMarkdown groepeert bevindingen per regel met errors bovenaan", () => {
+  const markdown = renderMarkdown({
+    id: "run-1",
+    score: 75,
+    successes: false,
+    rulesetVersion: "2.1",
+    messages: [
+      message("paths-no-trailing-slash", "warning", "paths./users/", "Geen slash | aan het eind"),
+      message("semver", "error", "info.version", "Versie moet semver zijn"),
+      message("paths-no-trailing-slash", "warning", "paths./orders/", "Geen slash | aan het eind"),
+    ],
+  });
+
+  assert.match(markdown, /\*\*Score:\*\* 75\/100 · ❌ Voldoet niet · ruleset 2\.1/);
+  const rows = markdown.split("\n").filter((line) => line.startsWith("| ❌") || line.startsWith("| ⚠️"));
+  assert.deepEqual(rows, [
+    "| ❌ | `semver` | error | 1 | Versie moet semver zijn |",
+    "| ⚠️ | `paths-no-trailing-slash` | warning | 2 | Geen slash \\| aan het eind |",
+  ]);
+  assert.match(markdown, /- `paths\.\/orders\/`/);
+  assert.match(markdown, /Lint-run `run-1`/);
+});
+
+test("renderMarkdown meldt een resultaat zonder bevindingen", () => {
+  const markdown = renderMarkdown({ score: 100, successes: true, rulesetVersion: "2.1", messages: [] });
+
+  assert.match(markdown, /✅ Voldoet/);
+  assert.match(markdown, /Geen bevindingen\./);
+});
@@ -0,0 +1,63 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const OasValidatorService = require("../services/OasValidatorService");
+const { aggregate, buildReport } = require("../services/LintReportService");
+
+const lintResult = (id, score, codes) => ({
+  id,
+  score,
+  successes: score === 100,
+  messages: codes.map((code) => ({ code, severity: "error" })),
+});
+
+test("aggregate berekent gemiddelde score en de meest voorkomende overtredingen", () => {
+  const report = aggregate([
+    { oasUrl: "https://a.example/openapi.json", lintResult: lintResult("a", 80, ["semver", "semver", "http-methods"]) },
+    { oasUrl: "https://b.example/openapi.json", lintResult: lintResult("b", 100, []) },
+    { oasUrl: "https://c.example/openapi.json", lintResult: lintResult("c", 90, ["http-methods"]) },
+    { oasUrl: "https://d.example/openapi.json", error: { status: 400, detail: "niet bereikbaar" } },
+  ]);
+
+  assert.equal(report.apiCount, 4);
+  assert.equal(report.lintedCount, 3);
+  assert.equal(report.failedCount, 1);
+  assert.equal(report.compliantCount, 1);
+  assert.equal(report.averageScore, 90);
+  assert.deepEqual(
+    report.topViolations.map(({ code, count, apiCount }) => ({ code, count, apiCount })),
+    [
+      { code: "http-methods", count: 2, apiCount: 2 },
+      { code: "semver", count: 2, apiCount: 1 },
+    ],
+  );
+  assert.deepEqual(report.apis[0], {
+    oasUrl: "https://a.example/openapi.json",
+    lintId: "a",
+    score: 80,
+    errors: 3,
+    warnings: 0,
+  });
+  assert.deepEqual(report.apis[3].error, { status: 400, detail: "niet bereikbaar" });
+});
+
+test("buildReport lint een organisatie met meer API's dan in een batch passen in delen", async (t) => {
+  const organisationUri = "https://identifier.overheid.nl/tooi/id/gemeente/gm0363";
+  const apis = Array.from({ length: 150 }, (_, index) => ({
+    organisation: organisationUri,
+    oasUrl: `https://api.example.nl/${index}/openapi.json`,
+  }));
+  const batches = [];
+  t.mock.method(OasValidatorService, "validateBatch", async ({ oasUrls }) => {
+    batches.push(oasUrls.length);
+    return oasUrls.map((oasUrl) => ({ oasUrl, lintResult: lintResult(oasUrl, 100, []) }));
+  });
+
+  const { report } = await buildReport(
+    { organisationUri },
+    { registerClient: { listApisForOrganisation: async () => apis } },
+  );
+
+  assert.deepEqual(batches, [100, 50]);
+  assert.equal(report.apiCount, 150);
+  assert.equal(report.compliantCount, 150);
+});
@@ -0,0 +1,67 @@
+const assert = require("node:assert/strict");
+const fs = require("node:fs/promises");
+const os = require("node:os");
+const path = require("node:path");
+const test = require("node:test");
+const { ArtifactStore } = require("../services/ArtifactStoreService");
+const {
+  ArtifactLintRunStore,
+  NoopLintRunStore,
+  SqliteLintRunStore,
+  createLintRunStore,
+  persistLintRun,
+} = require("../services/LintRunStoreService");
+
+const createStore = async () => {
+  const directory = await fs.mkdtemp(path.join(os.tmpdir(), "lint-runs-"));
+  const artifactStore = new ArtifactStore({ directory, encryptionKey: Buffer.alloc(32, 1) });
+  return new ArtifactLintRunStore(artifactStore);
+};
+
+test("een opgeslagen lint-run is terug te halen op id", async () => {
+  const store = await createStore();
+  const lintResult = { id: "3f0c2a4e-8d55-4d2b-9a3e-1f2b3c4d5e6f", failures: 0, messages: [] };
+
+  await persistLintRun(lintResult, store);
+
+  assert.deepEqual(await store.get(lintResult.id), lintResult);
+});
+
+test("onbekende of ongeldige ids geven undefined", async () => {
+  const store = await createStore();
+
+  assert.equal(await store.get("bestaat-niet"), undefined);
+  assert.equal(await store.get("../config"), undefined);
+});
+
+test("blijvende opslag weigert te starten zonder ARTIFACT_ENCRYPTION_KEY", () => {
+  assert.equal(process.env.ARTIFACT_ENCRYPTION_KEY, undefined);
+
+  assert.throws(() => createLintRunStore("artifact"), /ARTIFACT_ENCRYPTION_KEY/);
+  assert.throws(() => createLintRunStore("sqlite"), /ARTIFACT_ENCRYPTION_KEY/);
+  assert.ok(createLintRunStore(undefined) instanceof NoopLintRunStore);
+});
+
+let sqlite;
+try {
+  sqlite = require("node:sqlite");
+} catch {
+  // `node:sqlite` bestaat pas vanaf Node 22.5.
+}
+
+test("de SQLite-opslag bewaart runs versleuteld en laat ze na de bewaartermijn los", { skip: !sqlite }, async () => {
+  const database = new sqlite.DatabaseSync(":memory:");
+  const store = new SqliteLintRunStore({ database, encryptionKey: Buffer.alloc(32, 1), retentionDays: 1 });
+  const lintResult = { id: "3f0c2a4e-8d55-4d2b-9a3e-1f2b3c4d5e6f", failures: 0, messages: [] };
+  const savedAt = Date.now();
+
+  await store.save(lintResult, savedAt);
+
+  assert.deepEqual(await store.get(lintResult.id), lintResult);
+  const { payload } = database.prepare("SELECT payload FROM lint_runs").get();
+  assert.ok(!Buffer.from(payload).toString("latin1").includes(lintResult.id));
+  assert.equal(await store.get("../config"), undefined);
+  assert.equal(await store.get(lintResult.id, savedAt + 2 * 24 * 60 * 60 * 1000), undefined);
+  await store.save({ id: "ander" }, savedAt + 2 * 24 * 60 * 60 * 1000);
+  assert.equal(database.prepare("SELECT COUNT(*) AS count FROM lint_runs").get().count, 1);
+});
@@ -0,0 +1,88 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { convert } = require("../services/MockBundleService");
+const { readZip } = require("../utils/zip");
+
+const oasBody = JSON.stringify({
+  openapi: "3.0.3",
+  info: { title: "Dieren API", version: "1.0.0" },
+  paths: {
+    "/dieren": {
+      get: {
+        responses: {
+          200: {
+            description: "OK",
+            content: {
+              "application/json": { schema: { type: "array", items: { $ref: "#/components/schemas/Dier" } } },
+            },
+          },
+        },
+      },
+    },
+    "/dieren/{id}": {
+      get: {
+        parameters: [{ name: "id", in: "path", required: true, schema: { type: "integer" } }],
+        responses: {
+          200: {
+            description: "OK",
+            content: {
+              "application/json": {
+                schema: { $ref: "#/components/schemas/Dier" },
+                examples: { bello: { value: { naam: "Bello" } } },
+              },
+            },
+          },
+          404: { description: "Niet gevonden" },
+        },
+      },
+    },
+  },
+  components: {
+    schemas: { Dier: { type: "object", properties: { naam: { type: "string" } } } },
+  },
+});
+
+const unzip = (result) =>
+  Object.fromEntries(readZip(result.rawBody).map((entry) => [entry.name, entry.data.toString("utf8")]));
+
+test("convert bundelt de specificatie met een compose-bestand, mock.sh en README voor Prism", async () => {
+  const result = await convert({ oasBody });
+  const files = unzip(result);
+
+  assert.equal(result.headers["Content-Type"], "application/zip");
+  assert.equal(result.headers["Content-Disposition"], 'attachment; filename="dieren-api-mock.zip"');
+  assert.deepEqual(Object.keys(files).sort(), [
+    "dieren-api-mock/README.md",
+    "dieren-api-mock/docker-compose.yml",
+    "dieren-api-mock/mock.sh",
+    "dieren-api-mock/openapi.json",
+  ]);
+
+  const document = JSON.parse(files["dieren-api-mock/openapi.json"]);
+  assert.deepEqual(Object.keys(document.paths), ["/dieren", "/dieren/{id}"]);
+  const dier = document.paths["/dieren/{id}"].get.responses[200].content["application/json"];
+  assert.equal(dier.schema.type, "object");
+  assert.deepEqual(dier.examples.bello.value, { naam: "Bello" });
+  assert.equal(document["x-don-generated"].source, "request-body");
+
+  const compose = files["dieren-api-mock/docker-compose.yml"];
+  assert.match(compose, /^# generator=don-tools-api .*tool=oas-prism-mock /);
+  assert.match(compose, /command: mock -h 0\.0\.0\.0 \/api\/openapi\.json\n/);
+  assert.match(compose, / {6}- \.\/openapi\.json:\/api\/openapi\.json:ro\n/);
+  assert.match(
+    files["dieren-api-mock/mock.sh"],
+    /exec npx --yes @stoplight\/prism-cli@5 mock --port "\$\{PORT:-4010\}" openapi\.json\n/,
+  );
+  assert.match(files["dieren-api-mock/README.md"], /^# Mock-server voor Dieren API\n/);
+});
+
+test("convert met mockDynamic laat Prism de responses uit de schema's genereren", async () => {
+  const files = unzip(await convert({ oasBody, mockDynamic: true }));
+
+  assert.match(
+    files["dieren-api-mock/docker-compose.yml"],
+    /command: mock -h 0\.0\.0\.0 --dynamic \/api\/openapi\.json\n/,
+  );
+  assert.match(files["dieren-api-mock/mock.sh"], / --dynamic openapi\.json\n/);
+  assert.match(files["dieren-api-mock/README.md"], /uit de schema's gegenereerd \(`--dynamic`\)/);
+});
@@ -0,0 +1,50 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { assertRefsWithinArchive, findRoot, normalizeEntries } = require("../services/OasArchiveService");
+
+const archive = (entries) =>
+  normalizeEntries(Object.entries(entries).map(([name, text]) => ({ name, data: Buffer.from(text, "utf8") })));
+
+test("findRoot kiest het minst diepe openapi-document of oasArchiveRoot", () => {
+  const files = archive({
+    "dieren-main/openapi.yaml": "openapi: 3.0.3",
+    "dieren-main/examples/openapi.json": "{}",
+    "dieren-main/schemas/Dier.yaml": "type: object",
+  });
+
+  assert.equal(findRoot(files), "dieren-main/openapi.yaml");
+  assert.equal(findRoot(files, "./dieren-main/examples/openapi.json"), "dieren-main/examples/openapi.json");
+  assert.throws(() => findRoot(files, "elders.yaml"), (error) => error.code === 400);
+  assert.throws(() => findRoot(archive({ "a/openapi.yaml": "", "b/openapi.yml": "" })), (error) => error.code === 400);
+});
+
+test("relatieve verwijzingen moeten binnen het archief blijven", () => {
+  const files = archive({
+    "openapi.yaml": [
+      "paths:",
+      "  /dieren:",
+      "    $ref: paths/dieren.yaml",
+      "components:",
+      "  headers:",
+      "    API-Version:",
+      "      $ref: https://static.developer.overheid.nl/adr/components.yaml#/headers/API-Version",
+    ].join("\n"),
+    "paths/dieren.yaml": "get:\n  responses:\n    '200':\n      $ref: '../responses.yaml#/Dieren'",
+    "responses.yaml": "Dieren:\n  description: OK",
+  });
+  assert.doesNotThrow(() => assertRefsWithinArchive(files));
+
+  const escaping = archive({ "openapi.yaml": "paths:\n  /x:\n    $ref: ../../etc/passwd" });
+  assert.throws(() => assertRefsWithinArchive(escaping), (error) => error.code === 400);
+  assert.throws(() => archive({ "../openapi.yaml": "" }), (error) => error.code === 400);
+});
+
+test("een absolute verwijzing, schijfletter of file-URL wordt geweigerd, ook als het pad in het archief zit", () => {
+  const refs = ["/etc/passwd", "%2Fetc%2Fpasswd", "C:/etc/passwd", "file:///etc/passwd", "//etc/passwd"];
+  for (const ref of refs) {
+    const files = archive({ "openapi.yaml": `paths:\n  /x:\n    $ref: '${ref}'`, "etc/passwd": "get: {}" });
+    assert.throws(() => assertRefsWithinArchive(files), (error) => error.code === 400, ref);
+  }
+  const relative = archive({ "openapi.yaml": "paths:\n  /x:\n    $ref: etc/passwd", "etc/passwd": "get: {}" });
+  assert.doesNotThrow(() => assertRefsWithinArchive(relative));
+});
@@ -0,0 +1,116 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { bundle, resolveSchemaCollisions } = require("../services/OasBundleService");
+const { sourceTag } = require("../utils/schemaSources");
+
+const oasBody = JSON.stringify({
+  openapi: "3.0.3",
+  info: { title: "Dieren", version: "1.0.0" },
+  paths: {
+    "/dieren": {
+      get: {
+        responses: {
+          200: {
+            description: "OK",
+            content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } },
+          },
+          400: { $ref: "https://api.example.nl/responses.yaml#/BadRequest" },
+        },
+      },
+    },
+  },
+  components: {
+    schemas: {
+      Dier: { type: "object", properties: { ouder: { $ref: "#/components/schemas/Dier" } } },
+    },
+  },
+});
+
+test("scope=internal schrijft alleen lokale verwijzingen uit en haalt niets op", async () => {
+  const result = await bundle({ oasBody }, { scope: "internal" });
+  const document = JSON.parse(result.rawBody.toString("utf8"));
+  const responses = document.paths["/dieren"].get.responses;
+
+  assert.equal(responses[200].content["application/json"].schema.type, "object");
+  assert.deepEqual(responses[200].content["application/json"].schema.properties.ouder, {
+    $ref: "#/components/schemas/Dier",
+  });
+  assert.deepEqual(responses[400], { $ref: "https://api.example.nl/responses.yaml#/BadRequest" });
+  assert.equal(result.headers["X-Resolved-Documents"], "0");
+
+  const manifest = await bundle({ oasBody }, { scope: "internal", outputFormat: "manifest" });
+  assert.deepEqual(JSON.parse(manifest.rawBody.toString("utf8")).documents, []);
+});
+
+test("onCycle=error weigert een kringverwijzing en scope=internal en shareSchemas vragen om dereference", async () => {
+  await assert.rejects(bundle({ oasBody }, { scope: "internal", onCycle: "error" }), (error) => error.code === 400);
+  await assert.rejects(bundle({ oasBody }, { scope: "internal", mode: "bundle" }), (error) => error.code === 400);
+  await assert.rejects(bundle({ oasBody }, { shareSchemas: "true", mode: "bundle" }), (error) => error.code === 400);
+});
+
+test("refHeaders weigert een ongeldige host of header", async () => {
+  await assert.rejects(
+    bundle({ oasBody, refHeaders: { "https://example.nl": { "X-API-Key": "a" } } }),
+    (error) => error.code === 400,
+  );
+  await assert.rejects(
+    bundle({ oasBody, refHeaders: { "example.nl": { Host: "intern" } } }),
+    (error) => error.code === 400,
+  );
+});
+
+test("resolveSchemaCollisions geeft botsende externe schema's de bron als achtervoegsel", () => {
+  const gemeente = "https://a.example.nl/gemeente.yaml";
+  const bag = "https://b.example.nl/bag.yaml";
+  const kopie = "https://c.example.nl/kopie.yaml";
+  const tagged = (name, url) => `${name}__src_${sourceTag(url)}`;
+  const straat = { type: "object", properties: { straat: { type: "string" } } };
+  const ref = (name, url) => ({ $ref: `#/components/schemas/${tagged(name, url)}` });
+  const document = {
+    openapi: "3.0.3",
+    paths: {
+      "/adressen": {
+        get: {
+          responses: {
+            200: { description: "OK", content: { "application/json": { schema: ref("Adres", kopie) } } },
+          },
+        },
+      },
+    },
+    components: {
+      schemas: {
+        Adres: { type: "string" },
+        [tagged("Adres", gemeente)]: straat,
+        [tagged("Adres", bag)]: { type: "object", properties: { land: ref("Land", bag) } },
+        [tagged("Adres", kopie)]: structuredClone(straat),
+        [tagged("Land", bag)]: { type: "string" },
+      },
+    },
+  };
+
+  const result = resolveSchemaCollisions(document, [{ url: bag }, { url: gemeente }, { url: kopie }], "request-body");
+
+  assert.deepEqual(Object.keys(result.document.components.schemas), ["Adres", "Adres_gemeente", "Adres_bag", "Land"]);
+  assert.equal(result.document.components.schemas.Adres_bag.properties.land.$ref, "#/components/schemas/Land");
+  const schema = result.document.paths["/adressen"].get.responses[200].content["application/json"].schema;
+  assert.equal(schema.$ref, "#/components/schemas/Adres_gemeente");
+  assert.deepEqual(result.collisions, [
+    {
+      name: "Adres",
+      variants: [
+        { name: "Adres", sources: ["request-body"] },
+        { name: "Adres_gemeente", sources: [gemeente, kopie] },
+        { name: "Adres_bag", sources: [bag] },
+      ],
+    },
+  ]);
+});
+
+test("refHttp accepteert alleen bekende instellingen binnen de grenzen", async () => {
+  const reject = (refHttp) => assert.rejects(bundle({ oasBody, refHttp }), (error) => error.code === 400);
+
+  await reject({ proxy: "http://proxy.example.nl" });
+  await reject({ retries: 10 });
+  await reject({ timeoutMs: "5000" });
+  await reject([]);
+});
@@ -153,3 +153,231 @@ test("convert without targetVersion keeps existing 3.1 patch version", async ()
   assert.equal(result.headers["Content-Disposition"], 'attachment; filename="openapi-3-1-2.json"');
   assert.equal(converted.openapi, "3.1.2");
 });
+
+test("convert upgrades Swagger 2.0 to OpenAPI 3.0", async () => {
+  const sourceSpecYaml = `
+swagger: "2.0"
+info:
+  title: Legacy API
+  version: 1.0.0
+host: api.example.nl
+basePath: /v1
+schemes: [https]
+consumes: [application/json]
+produces: [application/json]
+securityDefinitions:
+  apiKey:
+    type: apiKey
+    name: X-Api-Key
+    in: header
+paths:
+  /items:
+    post:
+      parameters:
+        - name: dryRun
+          in: query
+          type: boolean
+        - name: body
+          in: body
+          required: true
+          schema:
+            $ref: "#/definitions/Item"
+      responses:
+        201:
+          description: Created
+          schema:
+            $ref: "#/definitions/Item"
+  /upload:
+    post:
+      consumes: [multipart/form-data]
+      parameters:
+        - name: file
+          in: formData
+          type: file
+          required: true
+      responses:
+        204:
+          description: Uploaded
+definitions:
+  Item:
+    type: object
+    properties:
+      name:
+        type: string
+        x-nullable: true
+`;
+
+  const result = await OasConversionService.convert({
+    oasBody: sourceSpecYaml,
+    targetVersion: "3.0",
+  });
+
+  const converted = toYaml(result.rawBody);
+  const createItem = converted.paths["/items"].post;
+  const upload = converted.paths["/upload"].post;
+
+  assert.equal(converted.openapi, "3.0.3");
+  assert.ok(!Object.hasOwn(converted, "swagger"));
+  assert.ok(!Object.hasOwn(converted, "definitions"));
+  assert.deepEqual(converted.servers, [{ url: "https://api.example.nl/v1" }]);
+  assert.deepEqual(createItem.parameters, [{ name: "dryRun", in: "query", schema: { type: "boolean" } }]);
+  assert.deepEqual(createItem.requestBody, {
+    required: true,
+    content: { "application/json": { schema: { $ref: "#/components/schemas/Item" } } },
+  });
+  assert.deepEqual(createItem.responses[201].content["application/json"].schema, {
+    $ref: "#/components/schemas/Item",
+  });
+  assert.deepEqual(upload.requestBody.content["multipart/form-data"].schema, {
+    type: "object",
+    properties: { file: { type: "string", format: "binary" } },
+    required: ["file"],
+  });
+  assert.equal(converted.components.schemas.Item.properties.name.nullable, true);
+  assert.deepEqual(converted.components.securitySchemes.apiKey, { type: "apiKey", name: "X-Api-Key", in: "header" });
+});
+
+test("convert downgrades OpenAPI 3.0 to Swagger 2.0 and reports lossy constructs", async () => {
+  const sourceSpec = {
+    openapi: "3.0.3",
+    info: { title: "Test API", version: "1.0.0" },
+    servers: [{ url: "https://api.example.nl/v1" }, { url: "https://test.example.nl/v1" }],
+    paths: {
+      "/items": {
+        post: {
+          parameters: [{ name: "session", in: "cookie", schema: { type: "string" } }],
+          requestBody: {
+            required: true,
+            content: { "application/json": { schema: { $ref: "#/components/schemas/Item" } } },
+          },
+          responses: {
+            201: {
+              description: "Created",
+              content: { "application/json": { schema: { $ref: "#/components/schemas/Item" } } },
+            },
+          },
+        },
+      },
+    },
+    components: {
+      schemas: {
+        Item: {
+          type: "object",
+          properties: {
+            name: { type: "string", nullable: true },
+            value: { oneOf: [{ type: "string" }, { type: "integer" }] },
+          },
+        },
+      },
+      securitySchemes: { basicAuth: { type: "http", scheme: "basic" } },
+    },
+  };
+
+  const result = await OasConversionService.convert({
+    oasBody: JSON.stringify(sourceSpec),
+    targetVersion: "2.0",
+  });
+
+  const converted = toJson(result.rawBody);
+  const createItem = converted.paths["/items"].post;
+
+  assert.equal(result.headers["Content-Disposition"], 'attachment; filename="swagger-2-0.json"');
+  assert.equal(result.headers["X-Conversion-Warnings"], "3");
+  assert.equal(converted.swagger, "2.0");
+  assert.ok(!Object.hasOwn(converted, "openapi"));
+  assert.equal(converted.host, "api.example.nl");
+  assert.equal(converted.basePath, "/v1");
+  assert.deepEqual(converted.schemes, ["https"]);
+  assert.deepEqual(createItem.consumes, ["application/json"]);
+  assert.deepEqual(createItem.parameters, [
+    { name: "body", in: "body", required: true, schema: { $ref: "#/definitions/Item" } },
+  ]);
+  assert.deepEqual(createItem.responses[201].schema, { $ref: "#/definitions/Item" });
+  assert.deepEqual(converted.definitions.Item.properties, { name: { type: "string", "x-nullable": true }, value: {} });
+  assert.deepEqual(converted.securityDefinitions.basicAuth, { type: "basic" });
+  assert.deepEqual(
+    converted["x-don-generated"].warnings.map((warning) => warning.path),
+    ["#/components/schemas/Item/properties/value/oneOf", "#/servers/1", "#/paths/~1items/post/parameters/0"],
+  );
+});
+
+test("convert 3.1 -> 3.0 maps JSON Schema keywords and reports lossy conversions", async () => {
+  const sourceSpec = {
+    openapi: "3.1.0",
+    info: { title: "Test API", version: "1.0.0" },
+    paths: {},
+    components: {
+      schemas: {
+        Measurement: {
+          $schema: "https://json-schema.org/draft/2020-12/schema",
+          type: "object",
+          properties: {
+            value: { type: "number", exclusiveMinimum: 0, exclusiveMaximum: 100 },
+            reading: { type: ["string", "integer", "null"] },
+            point: { type: "array", prefixItems: [{ type: "number" }, { type: "number" }], items: false },
+            photo: { type: "string", contentEncoding: "base64", contentMediaType: "image/png" },
+            file: { type: "string", contentMediaType: "application/octet-stream" },
+            unit: { const: "celsius", examples: ["celsius", "kelvin"] },
+          },
+        },
+      },
+    },
+  };
+
+  const result = await OasConversionService.convert({
+    oasBody: JSON.stringify(sourceSpec),
+    targetVersion: "3.0",
+  });
+
+  const converted = toJson(result.rawBody);
+  const properties = converted.components.schemas.Measurement.properties;
+
+  assert.equal(result.headers["X-Conversion-Warnings"], "2");
+  assert.ok(!Object.hasOwn(converted.components.schemas.Measurement, "$schema"));
+  assert.deepEqual(properties.value, {
+    type: "number",
+    minimum: 0,
+    exclusiveMinimum: true,
+    maximum: 100,
+    exclusiveMaximum: true,
+  });
+  assert.deepEqual(properties.reading, { nullable: true, oneOf: [{ type: "string" }, { type: "integer" }] });
+  assert.deepEqual(properties.point, { type: "array", items: { type: "number" }, maxItems: 2 });
+  assert.deepEqual(properties.photo, { type: "string", format: "byte" });
+  assert.deepEqual(properties.file, { type: "string", format: "binary" });
+  assert.deepEqual(properties.unit, { enum: ["celsius"], example: "celsius" });
+  assert.deepEqual(
+    converted["x-don-generated"].warnings.map((warning) => warning.path),
+    [
+      "#/components/schemas/Measurement/properties/photo/contentMediaType",
+      "#/components/schemas/Measurement/properties/unit/examples",
+    ],
+  );
+});
+
+test("convert keeps the key order of the source, including status codes", async () => {
+  const sourceSpecYaml = `
+openapi: 3.0.3
+info:
+  version: 1.0.0
+  title: Ordered API
+paths:
+  /items:
+    get:
+      responses:
+        "404":
+          description: Not found
+        "200":
+          description: OK
+        default:
+          description: Error
+`;
+
+  const result = await OasConversionService.convert({ oasBody: sourceSpecYaml, targetVersion: "3.0" });
+  const output = result.rawBody.toString("utf8");
+
+  assert.ok(output.indexOf("version:") < output.indexOf("title:"));
+  assert.ok(output.indexOf("'404':") < output.indexOf("'200':"));
+  assert.ok(output.indexOf("'200':") < output.indexOf("default:"));
+  assert.ok(output.indexOf("paths:") < output.indexOf("x-don-generated:"));
+});
@@ -0,0 +1,113 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { buildDiff, diff } = require("../services/OasDiffService");
+
+const createSpec = () => ({
+  openapi: "3.0.3",
+  info: { title: "Dieren API", version: "1.0.0" },
+  paths: {
+    "/dieren": {
+      get: {
+        parameters: [{ name: "soort", in: "query", schema: { type: "string", enum: ["kat", "hond", "vis"] } }],
+        responses: {
+          200: {
+            description: "OK",
+            content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } },
+          },
+        },
+      },
+      post: {
+        requestBody: { content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } } },
+        responses: { 201: { description: "Aangemaakt" } },
+      },
+    },
+    "/dieren/{id}": {
+      parameters: [{ name: "id", in: "path", required: true, schema: { type: "string" } }],
+      delete: { responses: { 204: { description: "Verwijderd" } } },
+    },
+    "/oud": { get: { responses: { 200: { description: "OK" } } } },
+  },
+  components: {
+    schemas: {
+      Dier: {
+        type: "object",
+        required: ["naam"],
+        properties: {
+          naam: { type: "string" },
+          moeder: { $ref: "#/components/schemas/Dier" },
+          status: { type: "string", enum: ["actief"] },
+        },
+      },
+    },
+  },
+});
+
+const codes = (changes, operation) =>
+  changes.filter((change) => change.operation === operation).map((change) => `${change.type}:${change.code}`);
+
+test("buildDiff deelt wijzigingen in naar richting van het bericht", () => {
+  const base = createSpec();
+  const head = createSpec();
+  head.info.version = "2.0.0";
+  delete head.paths["/oud"];
+  head.paths["/dieren/{dierId}"] = head.paths["/dieren/{id}"];
+  head.paths["/dieren/{dierId}"].parameters[0].name = "dierId";
+  delete head.paths["/dieren/{id}"];
+  head.paths["/dieren"].get.deprecated = true;
+  head.paths["/dieren"].get.parameters[0].schema.enum = ["kat", "hond"];
+  const dier = head.components.schemas.Dier;
+  dier.properties.chip = { type: "string" };
+  dier.required.push("chip");
+  dier.properties.status.enum.push("overleden");
+
+  const result = buildDiff(base, head);
+
+  assert.deepEqual(codes(result.changes, "/oud"), ["breaking:path-removed"]);
+  assert.deepEqual(codes(result.changes, "DELETE /dieren/{dierId}"), []);
+  assert.deepEqual(codes(result.changes, "GET /dieren"), [
+    "deprecation:operation-deprecated",
+    "breaking:enum-value-removed",
+    "breaking:enum-value-added",
+    "non-breaking:property-added",
+  ]);
+  assert.deepEqual(codes(result.changes, "POST /dieren"), [
+    "non-breaking:enum-value-added",
+    "breaking:required-property-added",
+  ]);
+  assert.deepEqual(result.summary, { breaking: 4, deprecation: 1, nonBreaking: 2 });
+  assert.match(result.markdown, /^## API-wijzigingen: Dieren API 1\.0\.0 → 2\.0\.0/);
+  assert.match(result.markdown, /\| `POST \/dieren` \| request body \(application\/json\) \| Verplichte property chip/);
+});
+
+test("diff zonder head geeft een 400", async () => {
+  await assert.rejects(diff({ base: { oasBody: JSON.stringify(createSpec()) } }), { code: 400 });
+});
+
+test("adviseVersion vergelijkt info.version met de aanbevolen verhoging", () => {
+  const base = createSpec();
+  const head = createSpec();
+  head.info.version = "1.1.0";
+  head.servers = [{ url: "https://api.example.nl/dieren/v1" }];
+  delete head.paths["/oud"];
+
+  const breaking = buildDiff(base, head).versioning;
+  assert.equal(breaking.recommendedBump, "major");
+  assert.equal(breaking.actualBump, "minor");
+  assert.equal(breaking.compliant, false);
+
+  head.info.version = "2.0.0";
+  const findings = buildDiff(base, head).versioning.findings;
+  assert.deepEqual(findings, [
+    "Server https://api.example.nl/dieren/v1 noemt v1 in plaats van v2 (include-major-version-in-uri).",
+  ]);
+
+  const patch = createSpec();
+  patch.info.version = "1.0.1";
+  patch.info.description = "Nu met beschrijving";
+  assert.deepEqual(buildDiff(base, patch).versioning, {
+    recommendedBump: "patch",
+    actualBump: "patch",
+    compliant: true,
+    findings: [],
+  });
+});
@@ -0,0 +1,67 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { filter, globToRegExp } = require("../services/OasFilterService");
+
+const spec = {
+  openapi: "3.0.3",
+  info: { title: "Dieren API", version: "1.0.0" },
+  tags: [{ name: "dieren" }, { name: "beheer" }, { name: "ongebruikt" }],
+  paths: {
+    "/dieren": {
+      get: { tags: ["dieren"], responses: { 200: { description: "OK" } } },
+      post: { tags: ["dieren"], "x-internal": true, responses: { 201: { description: "Aangemaakt" } } },
+    },
+    "/dieren/{id}": {
+      delete: { tags: ["dieren"], responses: { 204: { description: "Verwijderd" } } },
+    },
+    "/beheer": {
+      get: { tags: ["beheer"], responses: { 200: { description: "OK" } } },
+    },
+  },
+};
+
+test("globToRegExp matcht binnen en over padsegmenten", () => {
+  assert.ok(globToRegExp("/dieren/*").test("/dieren/{id}"));
+  assert.ok(!globToRegExp("/dieren/*").test("/dieren/{id}/foto"));
+  assert.ok(globToRegExp("/beheer/**").test("/beheer"));
+  assert.ok(globToRegExp("/beheer/**").test("/beheer/gebruikers/{id}"));
+  assert.ok(!globToRegExp("/beheer/**").test("/beheerders"));
+});
+
+test("filter verwijdert operaties, lege paden en tags die niet meer gebruikt worden", async () => {
+  const result = await filter({
+    oasBody: JSON.stringify(spec),
+    excludePaths: ["/beheer/**"],
+    excludeMethods: ["DELETE"],
+    excludeInternal: true,
+  });
+  const document = JSON.parse(result.rawBody);
+
+  assert.equal(result.headers["X-Removed-Operations"], "3");
+  assert.deepEqual(Object.keys(document.paths), ["/dieren"]);
+  assert.deepEqual(Object.keys(document.paths["/dieren"]), ["get"]);
+  assert.deepEqual(document.tags.map((tag) => tag.name), ["dieren", "ongebruikt"]);
+  assert.equal(document["x-don-generated"].tool, "oas-filter");
+});
+
+test("filter zonder filters geeft een 400", async () => {
+  await assert.rejects(filter({ oasBody: JSON.stringify(spec) }), { code: 400 });
+});
+
+test("filter verwijdert met removeUnusedComponents ook componenten die niet meer gebruikt worden", async () => {
+  const document = structuredClone(spec);
+  document.paths["/beheer"].get.responses[200].content = {
+    "application/json": { schema: { $ref: "#/components/schemas/Gebruiker" } },
+  };
+  document.components = { schemas: { Gebruiker: { type: "object" } } };
+  const result = await filter({
+    oasBody: JSON.stringify(document),
+    excludeTags: ["beheer"],
+    removeUnusedComponents: true,
+  });
+  const filtered = JSON.parse(result.rawBody);
+
+  assert.equal(result.headers["X-Removed-Components"], "1");
+  assert.equal(filtered.components, undefined);
+  assert.deepEqual(filtered["x-don-generated"].removedComponents, ["#/components/schemas/Gebruiker"]);
+});
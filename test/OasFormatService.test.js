@@ -0,0 +1,47 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const jsYaml = require("js-yaml");
+const { format } = require("../services/OasFormatService");
+
+const specJson = JSON.stringify({
+  "x-team": "dieren",
+  paths: {
+    "/dieren": {
+      GET: {
+        responses: {
+          default: { description: "Fout" },
+          "4xx": { description: "Clientfout" },
+          200: { content: { "application/json": { schema: { properties: {}, type: "object" } } }, description: "OK" },
+        },
+        operationId: "listDieren",
+        tags: ["dieren"],
+      },
+      parameters: [{ in: "query", name: "soort" }],
+    },
+  },
+  info: { version: "1.0.0", title: "Dieren API" },
+  openapi: "3.0.3",
+});
+
+test("format zet velden in de conventionele volgorde en normaliseert methods en statuscodes", async () => {
+  const result = await format({ oasBody: specJson }, { outputFormat: "yaml" });
+  const document = jsYaml.load(result.rawBody.toString("utf8"));
+  const pathItem = document.paths["/dieren"];
+
+  assert.equal(result.headers["Content-Type"], "application/yaml");
+  assert.deepEqual(Object.keys(document), ["openapi", "info", "paths", "x-team", "x-don-generated"]);
+  assert.deepEqual(Object.keys(document.info), ["title", "version"]);
+  assert.deepEqual(Object.keys(pathItem), ["parameters", "get"]);
+  assert.deepEqual(Object.keys(pathItem.parameters[0]), ["name", "in"]);
+  assert.deepEqual(Object.keys(pathItem.get), ["tags", "operationId", "responses"]);
+  assert.deepEqual(Object.keys(pathItem.get.responses), ["200", "4XX", "default"]);
+  assert.deepEqual(Object.keys(pathItem.get.responses[200]), ["description", "content"]);
+  assert.deepEqual(Object.keys(pathItem.get.responses[200].content["application/json"].schema), ["type", "properties"]);
+});
+
+test("format behoudt zonder outputFormat het formaat van de input", async () => {
+  const result = await format({ oasBody: specJson });
+
+  assert.equal(result.headers["Content-Type"], "application/json");
+  await assert.rejects(format({ oasBody: specJson }, { outputFormat: "xml" }), { code: 400 });
+});
@@ -0,0 +1,25 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { negotiateDocumentFormat, resolveOasInput } = require("../services/OasInputService");
+
+test("negotiateDocumentFormat volgt de format-parameter en anders de Accept header", () => {
+  assert.equal(negotiateDocumentFormat({ format: "YAML", accept: "application/json" }), "yaml");
+  assert.equal(negotiateDocumentFormat({ accept: "application/yaml" }), "yaml");
+  assert.equal(negotiateDocumentFormat({ accept: "application/yaml;q=0.5, application/json" }), "json");
+  assert.equal(negotiateDocumentFormat({ accept: "text/yaml, application/json" }), "yaml");
+  assert.equal(negotiateDocumentFormat({ accept: "application/vnd.oai.openapi+json;version=3.1" }), "json");
+  assert.equal(negotiateDocumentFormat({ accept: "application/json;q=0, */*" }), undefined);
+  assert.equal(negotiateDocumentFormat({ accept: "text/html,application/xhtml+xml,*/*;q=0.8" }), undefined);
+  assert.equal(negotiateDocumentFormat({}), undefined);
+  assert.throws(() => negotiateDocumentFormat({ format: "xml" }), (error) => error.code === 400);
+});
+
+test("resolveOasInput weigert een oasBody boven OAS_MAX_INPUT_BYTES met een 413", async (t) => {
+  process.env.OAS_MAX_INPUT_BYTES = "10";
+  t.after(() => {
+    delete process.env.OAS_MAX_INPUT_BYTES;
+  });
+
+  await assert.rejects(resolveOasInput({ oasBody: "openapi: 3.0.1\n" }), (error) => error.code === 413);
+  assert.equal((await resolveOasInput({ oasBody: "openapi: 3" })).contents, "openapi: 3");
+});
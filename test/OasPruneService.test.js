@@ -0,0 +1,59 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { pruneComponents } = require("../services/OasPruneService");
+
+test("pruneComponents verwijdert alleen componenten die nergens vandaan bereikbaar zijn", () => {
+  const spec = {
+    openapi: "3.0.3",
+    info: { title: "Dieren API", version: "1.0.0" },
+    security: [{ apiKey: [] }],
+    paths: {
+      "/dieren": {
+        get: {
+          responses: {
+            200: { $ref: "#/components/responses/Dieren" },
+            400: {
+              description: "Fout",
+              content: { "application/json": { example: { $ref: "#/components/schemas/Voorbeeld" } } },
+            },
+          },
+        },
+      },
+    },
+    components: {
+      responses: {
+        Dieren: {
+          description: "OK",
+          content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } },
+        },
+      },
+      schemas: {
+        Dier: {
+          oneOf: [{ $ref: "#/components/schemas/Kat" }],
+          discriminator: { propertyName: "soort", mapping: { hond: "Hond" } },
+        },
+        Kat: { type: "object" },
+        Hond: { type: "object", properties: { eigenaar: { $ref: "#/components/schemas/Eigenaar" } } },
+        Eigenaar: { type: "object" },
+        Oud: { type: "object", properties: { dier: { $ref: "#/components/schemas/Dier" } } },
+        Voorbeeld: { type: "object" },
+      },
+      parameters: { Limit: { name: "limit", in: "query" } },
+      securitySchemes: {
+        apiKey: { type: "apiKey", in: "header", name: "X-API-Key" },
+        oauth: { type: "oauth2", flows: {} },
+      },
+    },
+  };
+
+  const { removed } = pruneComponents(spec);
+
+  assert.deepEqual(removed, [
+    "#/components/schemas/Oud",
+    "#/components/schemas/Voorbeeld",
+    "#/components/parameters/Limit",
+    "#/components/securitySchemes/oauth",
+  ]);
+  assert.deepEqual(Object.keys(spec.components), ["responses", "schemas", "securitySchemes"]);
+  assert.deepEqual(Object.keys(spec.components.schemas), ["Dier", "Kat", "Hond", "Eigenaar"]);
+});
@@ -0,0 +1,55 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { reformat } = require("../services/OasReformatService");
+const { parseOrderedDocument, serializeOrdered } = require("../utils/orderedDocument");
+
+const YAML_SPEC = `openapi: 3.0.3
+info:
+  version: 1.0.0
+  title: Dieren API
+paths:
+  /dieren:
+    get:
+      responses:
+        "404": &nietGevonden
+          description: Niet gevonden
+        "200":
+          description: OK
+  /soorten:
+    get:
+      responses:
+        default:
+          description: Fout
+        "500": *nietGevonden
+`;
+
+test("parseOrderedDocument houdt de volgorde van statuscodes en lost aliases op", () => {
+  const { document, format } = parseOrderedDocument(YAML_SPEC);
+
+  assert.equal(format, "yaml");
+  assert.deepEqual([...document.get("info").keys()], ["version", "title"]);
+  const responses = (path) => document.get("paths").get(path).get("get").get("responses");
+  assert.deepEqual([...responses("/dieren").keys()], ["404", "200"]);
+  assert.deepEqual([...responses("/soorten").keys()], ["default", "500"]);
+  assert.equal(responses("/soorten").get("500").get("description"), "Niet gevonden");
+});
+
+test("serializeOrdered schrijft JSON en YAML in dezelfde volgorde terug", () => {
+  const json = serializeOrdered(parseOrderedDocument(YAML_SPEC).document, "json");
+  assert.ok(json.indexOf('"404"') < json.indexOf('"200"'));
+
+  const yaml = serializeOrdered(parseOrderedDocument(json).document, "yaml");
+  assert.ok(yaml.indexOf("'404':") < yaml.indexOf("'200':"));
+  assert.deepEqual(parseOrderedDocument(yaml).plain, parseOrderedDocument(YAML_SPEC).plain);
+});
+
+test("reformat zet YAML standaard om naar JSON met herkomst als laatste sleutel", async () => {
+  const result = await reformat({ oasBody: YAML_SPEC });
+  const body = result.rawBody.toString("utf8");
+
+  assert.equal(result.headers["Content-Type"], "application/json");
+  const keys = Object.keys(JSON.parse(body));
+  assert.deepEqual(keys, ["openapi", "info", "paths", "x-don-generated"]);
+  assert.equal(JSON.parse(body)["x-don-generated"].sourceFormat, "yaml");
+  await assert.rejects(reformat({ oasBody: YAML_SPEC }, { targetFormat: "xml" }), (error) => error.code === 400);
+});
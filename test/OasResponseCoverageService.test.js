@@ -0,0 +1,60 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const OasResponseCoverageService = require("../services/OasResponseCoverageService");
+
+const buildSpec = () => ({
+  openapi: "3.0.3",
+  info: { title: "Test API", version: "1.0.0" },
+  security: [{ apiKey: [] }],
+  paths: {
+    "/pets": {
+      get: {
+        operationId: "listPets",
+        responses: {
+          200: { description: "OK", content: { "application/json": { schema: { type: "array" } } } },
+          400: { description: "Bad Request" },
+          "4XX": { description: "Client error" },
+          500: { description: "Server error" },
+        },
+      },
+      post: {
+        operationId: "createPet",
+        security: [],
+        responses: {
+          201: { description: "Created" },
+        },
+      },
+    },
+  },
+});
+
+test("analyze reports missing success content and ADR error responses", () => {
+  const report = OasResponseCoverageService.analyze(buildSpec());
+
+  assert.equal(report.operationCount, 2);
+  assert.equal(report.compliantCount, 1);
+  assert.deepEqual(report.operations, [
+    {
+      path: "/pets",
+      method: "post",
+      operationId: "createPet",
+      hasSuccessResponse: true,
+      hasSuccessContent: false,
+      missingErrorResponses: ["400", "500"],
+    },
+  ]);
+});
+
+test("applyFixes inserts references to the ADR standard responses", () => {
+  const spec = buildSpec();
+  delete spec.paths["/pets"].get.responses["4XX"];
+  const inserted = OasResponseCoverageService.applyFixes(spec);
+
+  assert.deepEqual(
+    inserted.map(({ method, status }) => `${method} ${status}`),
+    ["get 401", "get 403", "post 400", "post 500"],
+  );
+  assert.deepEqual(spec.paths["/pets"].get.responses[401], {
+    $ref: "https://static.developer.overheid.nl/adr/components.yaml#/responses/401",
+  });
+});
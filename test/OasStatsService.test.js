@@ -0,0 +1,63 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { computeStats, schemaDepth } = require("../services/OasStatsService");
+
+test("schemaDepth telt geneste properties en items, maar volgt geen $ref", () => {
+  assert.equal(schemaDepth({ type: "string" }), 1);
+  assert.equal(schemaDepth({ $ref: "#/components/schemas/Dier" }), 1);
+  assert.equal(
+    schemaDepth({
+      type: "object",
+      properties: {
+        naam: { type: "string" },
+        eigenaren: { type: "array", items: { type: "object", properties: { naam: { type: "string" } } } },
+      },
+    }),
+    4,
+  );
+  assert.equal(schemaDepth({ allOf: [{ $ref: "#/components/schemas/Basis" }, { properties: { id: {} } }] }), 2);
+});
+
+test("computeStats telt paden, operaties, parameters en componenten", () => {
+  const contents = "openapi: 3.0.3\ninfo:\n  title: Dieren API\n";
+  const stats = computeStats(
+    {
+      openapi: "3.0.3",
+      info: { title: "Dieren API", version: "1.0.0" },
+      paths: {
+        "/dieren": {
+          parameters: [{ name: "soort", in: "query" }],
+          get: { parameters: [{ name: "pagina", in: "query" }] },
+          post: {},
+        },
+        "/dieren/{id}": {
+          get: { parameters: [{ $ref: "#/components/parameters/Id" }] },
+          delete: { parameters: [{ $ref: "#/components/parameters/Id" }] },
+        },
+      },
+      components: {
+        parameters: { Id: { name: "id", in: "path", required: true } },
+        schemas: { Dier: { type: "object", properties: { naam: { type: "string" } } }, Naam: { type: "string" } },
+        securitySchemes: { apiKey: { type: "apiKey", in: "header", name: "X-Api-Key" } },
+      },
+    },
+    contents,
+  );
+
+  assert.equal(stats.paths, 2);
+  assert.equal(stats.operations.total, 4);
+  assert.deepEqual(
+    Object.entries(stats.operations.byMethod).filter(([, count]) => count > 0),
+    [
+      ["get", 2],
+      ["post", 1],
+      ["delete", 1],
+    ],
+  );
+  assert.equal(stats.parameters, 5);
+  assert.equal(stats.schemas, 2);
+  assert.equal(stats.securitySchemes, 1);
+  assert.equal(stats.averageSchemaDepth, 1.5);
+  assert.equal(stats.maxSchemaDepth, 2);
+  assert.deepEqual(stats.documentSize, { bytes: Buffer.byteLength(contents), lines: 3 });
+});
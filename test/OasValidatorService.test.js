@@ -0,0 +1,177 @@
+const assert = require("node:assert/strict");
+const fs = require("node:fs/promises");
+const http = require("node:http");
+const os = require("node:os");
+const path = require("node:path");
+const test = require("node:test");
+const { validate } = require("../services/OasValidatorService");
+
+const spec = ["openapi: 3.0.3", "info:", "  title: Dieren", "  version: 1.0.0", "paths: {}", ""].join("\n");
+
+const diagnostic = {
+  code: "paths-kebab-case",
+  path: ["paths"],
+  message: "Paden moeten kebab-case zijn.",
+  severity: 0,
+  range: { start: { line: 4, character: 0 }, end: { line: 4, character: 9 } },
+};
+
+// Een nep-Spectral CLI: met SLEEP blijft hij hangen, anders schrijft hij een bevinding weg en sluit hij af
+// met code 1, zoals de echte CLI bij een error.
+const fakeSpectral = async (t) => {
+  const dir = await fs.mkdtemp(path.join(os.tmpdir(), "fake-spectral-"));
+  const bin = path.join(dir, "spectral");
+  await fs.writeFile(
+    bin,
+    [
+      "#!/usr/bin/env node",
+      "if (process.env.RECORD) {",
+      '  const fs = require("node:fs");',
+      "  const file = process.argv[3];",
+      '  fs.writeFileSync(process.env.RECORD, JSON.stringify([file, fs.readFileSync(file, "utf8")]));',
+      "}",
+      "if (process.env.SLEEP) {",
+      "  setTimeout(() => {}, 60000);",
+      "} else {",
+      `  process.stdout.write(${JSON.stringify(JSON.stringify([diagnostic]))});`,
+      "  process.exitCode = 1;",
+      "}",
+    ].join("\n"),
+    { mode: 0o755 },
+  );
+  process.env.SPECTRAL_BIN = bin;
+  process.env.SPECTRAL_RULESET_2_1 = path.join(dir, "ruleset.yaml");
+  t.after(async () => {
+    delete process.env.SPECTRAL_BIN;
+    delete process.env.SPECTRAL_RULESET_2_1;
+    delete process.env.SLEEP;
+    delete process.env.RECORD;
+    await fs.rm(dir, { recursive: true, force: true });
+  });
+};
+
+test("validate met de Spectral CLI leest de bevindingen ook als de CLI met code 1 afsluit", async (t) => {
+  await fakeSpectral(t);
+
+  const output = await validate({ oasBody: spec, engine: "spectral-cli" }, { outputFormat: "spectral" });
+
+  assert.deepEqual(
+    output.map(({ code, severity }) => ({ code, severity })),
+    [{ code: "paths-kebab-case", severity: 0 }],
+  );
+});
+
+test("validate geeft de Spectral CLI het document als tijdelijk bestand en ruimt dat daarna op", async (t) => {
+  await fakeSpectral(t);
+  const record = path.join(path.dirname(process.env.SPECTRAL_BIN), "record.json");
+  process.env.RECORD = record;
+
+  await validate({ oasBody: spec, engine: "spectral-cli" }, { outputFormat: "spectral" });
+
+  const [inputPath, contents] = JSON.parse(await fs.readFile(record, "utf8"));
+  assert.equal(path.basename(inputPath), "openapi.yaml");
+  assert.equal(contents, spec);
+  await assert.rejects(fs.access(inputPath), { code: "ENOENT" });
+});
+
+test("validate breekt de Spectral CLI af na timeoutSeconds", async (t) => {
+  await fakeSpectral(t);
+  process.env.SLEEP = "1";
+
+  const started = Date.now();
+  await assert.rejects(
+    validate({ oasBody: spec, engine: "spectral-cli", timeoutSeconds: 1 }, { outputFormat: "spectral" }),
+    (error) => error.code === 504,
+  );
+  assert.ok(Date.now() - started < 10000);
+});
+
+let spectralCliInstalled = true;
+try {
+  require.resolve("@stoplight/spectral-cli/dist/index.js");
+} catch {
+  // Zonder het pakket hoort alleen `engine: "spectral-cli"` te falen, niet het laden van de service.
+  spectralCliInstalled = false;
+}
+
+test(
+  "validate met de Spectral CLI geeft een 503 als de CLI niet geïnstalleerd is",
+  { skip: spectralCliInstalled },
+  async (t) => {
+    process.env.SPECTRAL_RULESET_2_1 = "ruleset.yaml";
+    t.after(() => delete process.env.SPECTRAL_RULESET_2_1);
+
+    await assert.rejects(
+      validate({ oasBody: spec, engine: "spectral-cli" }, { outputFormat: "spectral" }),
+      (error) => error.code === 503 && /niet beschikbaar/.test(error.error.message),
+    );
+  },
+);
+
+test("validate draait de ingebouwde engine in een worker thread en geeft de voortgang door", async () => {
+  const phases = [];
+  const result = await validate(
+    { oasBody: spec, ruleset: "owasp" },
+    { onProgress: ({ phase, engine }) => phases.push(engine ? `${phase}:${engine}` : phase) },
+  );
+
+  assert.ok(Array.isArray(result.messages));
+  assert.ok(phases.includes("linting:embedded"));
+  assert.equal(phases.at(-1), "scoring");
+});
+
+test("validate meldt een ontbrekende specificatie voordat er voortgang is", async () => {
+  const phases = [];
+
+  await assert.rejects(
+    validate({ targetVersion: "2.1" }, { onProgress: ({ phase }) => phases.push(phase) }),
+    (error) => error.code === 400,
+  );
+  assert.deepEqual(phases, []);
+});
+
+// Een server die de specificatie teruggeeft en onthoudt welke Authorization-header er meekwam.
+const startSpecServer = async (t) => {
+  const received = [];
+  const server = http.createServer((request, response) => {
+    received.push(request.headers.authorization);
+    response.setHeader("Content-Type", "application/yaml");
+    response.end(spec);
+  });
+  await new Promise((resolve) => server.listen(0, "127.0.0.1", resolve));
+  t.after(() => server.close());
+  return { url: `http://127.0.0.1:${server.address().port}/openapi.yaml`, received };
+};
+
+// Het uitgaande beleid wordt bij het laden vastgelegd; laad de services opnieuw met de testservers toegestaan.
+const loadWithLocalServers = (ports) => {
+  process.env.OUTBOUND_ALLOWLIST = "127.0.0.1";
+  process.env.OUTBOUND_ALLOWED_PORTS = ports.join(",");
+  for (const name of ["../services/RemoteSpecificationService", "../services/OasValidatorService"]) {
+    delete require.cache[require.resolve(name)];
+  }
+  const service = require("../services/OasValidatorService");
+  delete process.env.OUTBOUND_ALLOWLIST;
+  delete process.env.OUTBOUND_ALLOWED_PORTS;
+  return service;
+};
+
+test("validateBatch stuurt headers alleen naar de origin van de eerste URL", async (t) => {
+  await fakeSpectral(t);
+  const first = await startSpecServer(t);
+  const other = await startSpecServer(t);
+  const ports = [first.url, other.url].map((url) => new URL(url).port);
+  const { validateBatch } = loadWithLocalServers(ports);
+
+  const items = await validateBatch({
+    oasUrls: [first.url, other.url],
+    engine: "spectral-cli",
+    headers: { Authorization: "Bearer geheim" },
+  });
+
+  assert.deepEqual(items.map((item) => item.error), [undefined, undefined]);
+  assert.ok(first.received.length > 0);
+  assert.ok(first.received.every((value) => value === "Bearer geheim"));
+  assert.ok(other.received.length > 0);
+  assert.ok(other.received.every((value) => value === undefined));
+});
@@ -0,0 +1,70 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const jsYaml = require("js-yaml");
+const { apply, applyOverlay } = require("../services/OverlayService");
+
+const specYaml = `
+openapi: 3.0.3
+info:
+  title: Dieren API
+  version: 1.0.0
+servers:
+  - url: https://oud.example.nl
+paths:
+  /dieren:
+    get:
+      x-internal: true
+      responses:
+        "200":
+          description: OK
+    post:
+      tags: [dieren]
+      responses:
+        "201":
+          description: Aangemaakt
+`;
+
+test("apply voegt updates samen, verwijdert nodes en telt acties zonder match", async () => {
+  const overlay = {
+    overlay: "1.0.0",
+    info: { title: "Organisatie-overlay", version: "1.2.0" },
+    actions: [
+      { target: "$.info", update: { contact: { name: "Team API", email: "api@example.nl" } } },
+      { target: "$.servers[*]", remove: true },
+      { target: "$.servers", update: { url: "https://api.example.nl/v1" } },
+      { target: "$.paths.*[?@.x-internal == true]", remove: true },
+      { target: "$.paths.*.*.tags", update: "organisatie" },
+      { target: "$.components.schemas", update: { Fout: { type: "object" } } },
+    ],
+  };
+
+  const result = await apply({ oasBody: specYaml, overlayBody: jsYaml.dump(overlay) });
+  const patched = jsYaml.load(result.rawBody.toString("utf8"));
+
+  assert.equal(result.headers["Content-Type"], "application/yaml");
+  assert.equal(result.headers["X-Overlay-Unmatched-Actions"], "1");
+  assert.deepEqual(patched.info.contact, { name: "Team API", email: "api@example.nl" });
+  assert.equal(patched.info.title, "Dieren API");
+  assert.deepEqual(patched.servers, [{ url: "https://api.example.nl/v1" }]);
+  assert.deepEqual(Object.keys(patched.paths["/dieren"]), ["post"]);
+  assert.deepEqual(patched.paths["/dieren"].post.tags, ["dieren", "organisatie"]);
+  assert.equal(patched["x-don-generated"].overlay, "Organisatie-overlay 1.2.0");
+});
+
+test("applyOverlay voegt objecten recursief samen en vervangt arrays en andere waarden", () => {
+  const spec = { info: { title: "A", contact: { name: "Oud", url: "https://a" }, "x-tags": ["a"] } };
+  const { spec: patched } = applyOverlay(spec, {
+    overlay: "1.0.0",
+    actions: [{ target: "$", update: { info: { contact: { name: "Nieuw" }, "x-tags": ["b"] } } }],
+  });
+
+  assert.deepEqual(patched.info, { title: "A", contact: { name: "Nieuw", url: "https://a" }, "x-tags": ["b"] });
+});
+
+test("apply weigert een ongeldige overlay of target", async () => {
+  await assert.rejects(apply({ oasBody: specYaml, overlayBody: "actions: []" }), { code: 400 });
+  await assert.rejects(
+    apply({ oasBody: specYaml, overlayBody: JSON.stringify({ overlay: "1.0.0", actions: [{ target: "$.[" }] }) }),
+    (error) => error.code === 400 && error.error.message === "Ongeldige target in actie 1: $.[",
+  );
+});
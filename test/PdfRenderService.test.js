@@ -0,0 +1,42 @@
+const assert = require("node:assert/strict");
+const fs = require("node:fs/promises");
+const os = require("node:os");
+const path = require("node:path");
+const test = require("node:test");
+const { renderPdf } = require("../services/PdfRenderService");
+
+// Een nep-Chromium die zijn argumenten als "PDF" wegschrijft.
+const fakeChromium = async (t) => {
+  const dir = await fs.mkdtemp(path.join(os.tmpdir(), "fake-chromium-"));
+  const bin = path.join(dir, "chromium");
+  await fs.writeFile(
+    bin,
+    [
+      "#!/usr/bin/env node",
+      'const fs = require("node:fs");',
+      "const args = process.argv.slice(2);",
+      'const output = args.find((arg) => arg.startsWith("--print-to-pdf=")).slice("--print-to-pdf=".length);',
+      "fs.writeFileSync(output, JSON.stringify(args));",
+    ].join("\n"),
+    { mode: 0o755 },
+  );
+  process.env.CHROMIUM_BIN = bin;
+  t.after(async () => {
+    delete process.env.CHROMIUM_BIN;
+    delete process.env.CHROMIUM_NO_SANDBOX;
+    await fs.rm(dir, { recursive: true, force: true });
+  });
+};
+
+test("renderPdf start Chromium met sandbox en zonder netwerk", async (t) => {
+  await fakeChromium(t);
+
+  const args = JSON.parse(await renderPdf("<p>Dieren</p>"));
+  assert.ok(!args.includes("--no-sandbox"));
+  assert.ok(args.includes("--proxy-server=127.0.0.1:0"));
+  assert.ok(args.includes("--proxy-bypass-list=<-loopback>"));
+  assert.match(args.at(-1), /^file:\/\/.*\/document\.html$/);
+
+  process.env.CHROMIUM_NO_SANDBOX = "true";
+  assert.ok(JSON.parse(await renderPdf("<p>Dieren</p>")).includes("--no-sandbox"));
+});
@@ -0,0 +1,55 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { normalizePolymorphism } = require("../services/PolymorphismService");
+
+const createDocument = (openapi) => ({
+  openapi,
+  info: { title: "Dieren API", version: "1.0.0" },
+  paths: {},
+  components: {
+    schemas: {
+      Dier: {
+        type: "object",
+        discriminator: { propertyName: "soort", mapping: { poes: "#/components/schemas/Kat" } },
+        oneOf: [{ $ref: "#/components/schemas/Kat" }, { $ref: "#/components/schemas/Hond" }],
+      },
+      Kat: { type: "object", properties: { soort: { type: "string" } } },
+      Hond: { type: "object", properties: { soort: { type: "string", enum: ["hond"] }, const: { type: "string" } } },
+      Vis: { type: "object", properties: { soort: { const: "vis" } } },
+      Huisdier: { oneOf: [{ $ref: "#/components/schemas/Kat" }, { $ref: "#/components/schemas/Vis" }] },
+    },
+  },
+});
+
+test("normalizePolymorphism vult de mapping aan en zet enum met één waarde om naar const in 3.1", () => {
+  const { spec, changes, warnings } = normalizePolymorphism(createDocument("3.1.0"));
+
+  assert.deepEqual(spec.components.schemas.Dier.discriminator.mapping, {
+    poes: "#/components/schemas/Kat",
+    hond: "#/components/schemas/Hond",
+  });
+  assert.deepEqual(spec.components.schemas.Hond.properties.soort, { type: "string", const: "hond" });
+  assert.deepEqual(spec.components.schemas.Hond.properties.const, { type: "string" });
+  assert.deepEqual(
+    changes.map(({ type, path }) => [type, path]),
+    [
+      ["discriminator-mapping", "#/components/schemas/Dier/discriminator/mapping/hond"],
+      ["enum-to-const", "#/components/schemas/Hond/properties/soort/const"],
+    ],
+  );
+  assert.deepEqual(
+    warnings.map(({ type, path }) => [type, path]),
+    [["object-variants-without-discriminator", "#/components/schemas/Huisdier/oneOf"]],
+  );
+});
+
+test("normalizePolymorphism zet const in 3.0 terug naar een enum", () => {
+  const { spec, changes } = normalizePolymorphism(createDocument("3.0.3"));
+
+  assert.deepEqual(spec.components.schemas.Vis.properties.soort, { enum: ["vis"] });
+  assert.deepEqual(spec.components.schemas.Hond.properties.soort.enum, ["hond"]);
+  assert.deepEqual(
+    changes.map(({ type }) => type),
+    ["discriminator-mapping", "const-to-enum"],
+  );
+});
@@ -0,0 +1,146 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const {
+  buildEnvironments,
+  buildNewmanFiles,
+  configureClientCredentialsAuth,
+  fillEmptyBodies,
+  resolveConversionOptions,
+  toCollectionV20,
+} = require("../services/PostmanConversionService");
+
+test("buildEnvironments maakt een environment per server met variabelen voor de security schemes", () => {
+  const spec = {
+    servers: [
+      {
+        url: "https://{omgeving}.example.nl/v1",
+        description: "Productie",
+        variables: { omgeving: { default: "api" } },
+      },
+      { url: "https://test.example.nl/v1", description: "Test" },
+    ],
+    components: {
+      securitySchemes: {
+        sleutel: { type: "apiKey", in: "header", name: "X-Api-Key" },
+        oauth: {
+          type: "oauth2",
+          flows: { clientCredentials: { tokenUrl: "https://auth.example.nl/token", scopes: {} } },
+        },
+      },
+    },
+  };
+
+  const environments = buildEnvironments(spec, "Dieren API");
+
+  assert.deepEqual(
+    environments.map(({ fileName, environment }) => [fileName, environment.name]),
+    [
+      ["Productie", "Dieren API - Productie"],
+      ["Test", "Dieren API - Test"],
+    ],
+  );
+  assert.deepEqual(
+    environments[0].environment.values.map(({ key, value, type }) => [key, value, type]),
+    [
+      ["baseUrl", "https://api.example.nl/v1", "default"],
+      ["apiKey", "", "secret"],
+      ["tokenUrl", "https://auth.example.nl/token", "default"],
+      ["scope", "", "default"],
+      ["clientId", "", "default"],
+      ["clientSecret", "", "secret"],
+    ],
+  );
+});
+
+test("resolveConversionOptions vertaalt folderStrategy en requestParametersResolution naar library-opties", () => {
+  assert.deepEqual(resolveConversionOptions({ oasBody: "{}" }), {});
+  assert.deepEqual(resolveConversionOptions({ folderStrategy: "tags", requestParametersResolution: "Example" }), {
+    folderStrategy: "Tags",
+    parametersResolution: "Example",
+  });
+  assert.throws(() => resolveConversionOptions({ folderStrategy: "mappen" }), (error) => error.code === 400);
+});
+
+test("fillEmptyBodies vult lege bodies met een voorbeeld uit het schema", () => {
+  const spec = {
+    paths: {
+      "/dieren": {
+        post: {
+          requestBody: {
+            content: {
+              "application/json": {
+                schema: { type: "object", properties: { naam: { type: "string" }, leeftijd: { type: "integer" } } },
+              },
+            },
+          },
+        },
+      },
+    },
+  };
+  const collection = {
+    item: [
+      {
+        name: "dieren",
+        item: [
+          { request: { method: "POST", url: { raw: "{{baseUrl}}/v1/dieren", path: ["v1", "dieren"] }, body: {} } },
+          { request: { method: "GET", url: { raw: "{{baseUrl}}/v1/dieren", path: ["v1", "dieren"] } } },
+        ],
+      },
+    ],
+  };
+
+  assert.equal(fillEmptyBodies(collection, spec), 1);
+  const [post, get] = collection.item[0].item;
+  assert.deepEqual(JSON.parse(post.request.body.raw), { naam: "string", leeftijd: 0 });
+  assert.equal(get.request.body, undefined);
+});
+
+test("configureClientCredentialsAuth zet OAuth2 op de collectie en laat requests die erven", () => {
+  const spec = {
+    security: [{ oauth: ["tools"] }],
+    components: {
+      securitySchemes: {
+        oauth: { type: "oauth2", flows: { clientCredentials: { tokenUrl: "https://auth.example.nl/token" } } },
+      },
+    },
+  };
+  const collection = { item: [{ item: [{ request: { method: "GET", auth: { type: "oauth2" } } }] }] };
+
+  assert.equal(configureClientCredentialsAuth(collection, spec), true);
+  assert.equal(collection.item[0].item[0].request.auth, undefined);
+  assert.equal(collection.auth.type, "oauth2");
+  assert.deepEqual(
+    collection.auth.oauth2.filter(({ key }) => ["accessTokenUrl", "scope"].includes(key)).map(({ value }) => value),
+    ["{{tokenUrl}}", "{{scope}}"],
+  );
+  assert.equal(configureClientCredentialsAuth({ item: [] }, { components: {} }), false);
+});
+
+test("toCollectionV20 zet schema en auth-notatie om naar Postman Collection v2.0", () => {
+  const collection = {
+    info: { name: "Dieren", schema: "https://schema.getpostman.com/json/collection/v2.1.0/collection.json" },
+    auth: { type: "bearer", bearer: [{ key: "token", value: "{{bearerToken}}", type: "string" }] },
+    item: [{ item: [{ request: { auth: { type: "apikey", apikey: [{ key: "key", value: "X-Api-Key" }] } } }] }],
+  };
+
+  toCollectionV20(collection);
+
+  assert.equal(collection.info.schema, "https://schema.getpostman.com/json/collection/v2.0.0/collection.json");
+  assert.deepEqual(collection.auth, { type: "bearer", bearer: { token: "{{bearerToken}}" } });
+  assert.deepEqual(collection.item[0].item[0].request.auth, { type: "apikey", apikey: { key: "X-Api-Key" } });
+});
+
+test("buildNewmanFiles maakt package.json en een uitvoerbaar run-script per environment", () => {
+  const [packageFile, script] = buildNewmanFiles("dieren-api", ["productie", "test"], { source: "request-body" });
+  const packageJson = JSON.parse(packageFile.data);
+
+  assert.equal(packageFile.name, "package.json");
+  assert.equal(packageJson.devDependencies.newman, "^6.2.1");
+  assert.match(packageJson.scripts.test, /^newman run dieren-api\.postman_collection\.json -e productie\./);
+  assert.match(packageJson.scripts["test:test"], /-e test\.postman_environment\.json /);
+  assert.match(packageJson.scripts.test, /--reporter-junit-export results\/junit\.xml$/);
+  assert.equal(script.name, "run-newman.sh");
+  assert.equal(script.mode, 0o755);
+  assert.match(script.data, /^#!\/usr\/bin\/env bash\n/);
+  assert.match(script.data, /ENVIRONMENT="\$\{1:-productie\}"/);
+});
@@ -0,0 +1,75 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { buildOpenApiDocument } = require("../services/PostmanImportService");
+
+const collection = {
+  info: { name: "Dieren API", schema: "https://schema.getpostman.com/json/collection/v2.1.0/collection.json" },
+  variable: [{ key: "baseUrl", value: "https://api.example.nl/v1/" }],
+  auth: { type: "bearer", bearer: [{ key: "token", value: "{{token}}" }] },
+  item: [
+    {
+      name: "dieren",
+      item: [
+        {
+          name: "Dier ophalen",
+          request: {
+            method: "GET",
+            url: {
+              raw: "{{baseUrl}}/dieren/:id?expand=true",
+              query: [
+                { key: "expand", value: "true" },
+                { key: "debug", value: "1", disabled: true },
+              ],
+              variable: [{ key: "id", value: "42" }],
+            },
+            header: [{ key: "Accept", value: "application/json" }],
+          },
+          response: [
+            {
+              name: "Gevonden",
+              code: 200,
+              status: "OK",
+              header: [{ key: "Content-Type", value: "application/json" }],
+              body: '{"id":42,"geboren":"2020-01-01"}',
+            },
+          ],
+        },
+        {
+          name: "Dier aanmaken",
+          request: {
+            method: "POST",
+            url: "{{baseUrl}}/dieren",
+            body: { mode: "raw", raw: '{"naam":"Bello"}', options: { raw: { language: "json" } } },
+          },
+        },
+      ],
+    },
+  ],
+};
+
+test("buildOpenApiDocument reconstrueert paden, parameters, bodies en responses uit een Postman collectie", () => {
+  const document = buildOpenApiDocument(collection);
+  const getDier = document.paths["/dieren/{id}"].get;
+
+  assert.equal(document.openapi, "3.0.3");
+  assert.deepEqual(document.servers, [{ url: "https://api.example.nl/v1" }]);
+  assert.deepEqual(document.tags, [{ name: "dieren" }]);
+  assert.equal(getDier.operationId, "dierOphalen");
+  assert.deepEqual(
+    getDier.parameters.map(({ name, in: location, schema }) => [name, location, schema.type]),
+    [
+      ["id", "path", "integer"],
+      ["expand", "query", "boolean"],
+    ],
+  );
+  assert.deepEqual(getDier.responses["200"].content["application/json"].schema.properties.geboren, {
+    type: "string",
+    format: "date",
+  });
+  assert.deepEqual(getDier.security, [{ bearerAuth: [] }]);
+  assert.deepEqual(
+    document.paths["/dieren"].post.requestBody.content["application/json"].schema.properties.naam,
+    { type: "string" },
+  );
+  assert.deepEqual(document.components.securitySchemes, { bearerAuth: { type: "http", scheme: "bearer" } });
+});
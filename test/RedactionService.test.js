@@ -0,0 +1,110 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { redactDocument } = require("../services/RedactionService");
+
+const buildSpec = () => ({
+  openapi: "3.0.3",
+  info: { title: "Burgers API", version: "1.0.0", description: "Publiek <!-- TODO: auth nog regelen --> deel" },
+  servers: [{ url: "https://api.example.nl/v1" }, { url: "http://localhost:8080/v1" }],
+  tags: [{ name: "burgers" }, { name: "beheer", "x-internal": true }],
+  paths: {
+    "/burgers": {
+      get: {
+        tags: ["burgers"],
+        "x-todo": "paginering toevoegen",
+        parameters: [{ name: "debug", in: "query", "x-internal": true, schema: { type: "boolean" } }],
+        responses: {
+          200: {
+            description: "OK",
+            content: {
+              "application/json": {
+                schema: { $ref: "#/components/schemas/Burger" },
+                examples: {
+                  jan: { value: { bsn: "123456782", email: "jan@gemeente.nl", contact: "info@example.nl" } },
+                },
+              },
+            },
+          },
+        },
+      },
+    },
+    "/beheer/cache": {
+      delete: {
+        "x-internal": true,
+        tags: ["beheer"],
+        responses: { 200: { content: { "application/json": { schema: { $ref: "#/components/schemas/Cache" } } } } },
+      },
+    },
+  },
+  components: {
+    schemas: {
+      Burger: {
+        type: "object",
+        required: ["bsn", "intern"],
+        properties: {
+          bsn: { type: "string", example: "123456782" },
+          intern: { type: "string", "x-internal": true },
+        },
+      },
+      Cache: { type: "object", $comment: "alleen voor beheer" },
+      Ongebruikt: { type: "string" },
+    },
+  },
+});
+
+test("redactDocument verwijdert interne onderdelen en vervangt persoonsgegevens in voorbeelden", () => {
+  const { spec, redactions } = redactDocument(buildSpec());
+
+  assert.equal(spec.info.description, "Publiek deel");
+  assert.deepEqual(spec.servers, [{ url: "https://api.example.nl/v1" }]);
+  assert.deepEqual(spec.tags, [{ name: "burgers" }]);
+  assert.deepEqual(Object.keys(spec.paths), ["/burgers"]);
+
+  const operation = spec.paths["/burgers"].get;
+  assert.equal(operation.parameters, undefined);
+  assert.equal(operation["x-todo"], undefined);
+  assert.deepEqual(operation.responses[200].content["application/json"].examples.jan.value, {
+    bsn: "999999990",
+    email: "naam@example.com",
+    contact: "info@example.nl",
+  });
+
+  assert.deepEqual(spec.components.schemas.Burger, {
+    type: "object",
+    required: ["bsn"],
+    properties: { bsn: { type: "string", example: "999999990" } },
+  });
+  // Cache werd alleen door de interne operatie gebruikt; Ongebruikt was al ongebruikt en blijft staan.
+  assert.deepEqual(Object.keys(spec.components.schemas), ["Burger", "Ongebruikt"]);
+
+  assert.deepEqual(
+    redactions.map(({ type, path }) => `${type} ${path}`),
+    [
+      "internal-comment #/info/description",
+      "internal-server #/servers/1",
+      "x-internal #/tags/1",
+      "internal-comment #/paths/~1burgers/get/x-todo",
+      "x-internal #/paths/~1burgers/get/parameters/0",
+      "pii-bsn #/paths/~1burgers/get/responses/200/content/application~1json/examples/jan/value/bsn",
+      "pii-email #/paths/~1burgers/get/responses/200/content/application~1json/examples/jan/value/email",
+      "x-internal #/paths/~1beheer~1cache/delete",
+      "pii-bsn #/components/schemas/Burger/properties/bsn/example",
+      "x-internal #/components/schemas/Burger/properties/intern",
+      "internal-comment #/components/schemas/Cache/$comment",
+      "orphaned-component #/components/schemas/Cache",
+    ],
+  );
+});
+
+test("redactDocument laat getallen zonder geldige elfproef en relatieve servers staan", () => {
+  const { spec, redactions } = redactDocument({
+    openapi: "3.0.3",
+    info: { title: "Dieren API", version: "1.0.0" },
+    servers: [{ url: "/v1" }],
+    paths: {},
+    components: { schemas: { Dier: { type: "integer", example: 123456789 } } },
+  });
+  assert.deepEqual(spec.servers, [{ url: "/v1" }]);
+  assert.equal(spec.components.schemas.Dier.example, 123456789);
+  assert.deepEqual(redactions, []);
+});
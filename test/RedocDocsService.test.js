@@ -0,0 +1,42 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { buildDocs } = require("../services/RedocDocsService");
+
+const spec = {
+  openapi: "3.0.3",
+  info: { title: "Dieren API", version: "1.2.0" },
+  servers: [{ url: "https://api.example.nl/v1" }],
+  paths: {
+    "/dieren/{id}": {
+      get: {
+        operationId: "getDier",
+        summary: "Dier ophalen",
+        parameters: [{ name: "id", in: "path", required: true, schema: { type: "string" } }],
+        responses: {
+          200: {
+            description: "OK",
+            content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } },
+          },
+        },
+      },
+    },
+  },
+  components: {
+    schemas: { Dier: { type: "object", properties: { naam: { type: "string" } } } },
+  },
+};
+
+test("buildDocs maakt met Redocly één HTML-bestand met Redoc en de specificatie erin", async () => {
+  const result = await buildDocs({ oasBody: JSON.stringify(spec) });
+  const html = result.rawBody.toString("utf8");
+
+  assert.equal(result.headers["Content-Type"], "text/html; charset=utf-8");
+  assert.equal(result.headers["Content-Disposition"], 'attachment; filename="dieren-api-docs.html"');
+  assert.match(html, /^<!doctype html>\n<!-- generator=don-tools-api .*tool=oas-redoc .*-->/i);
+  assert.match(html, /<title>Dieren API<\/title>/);
+  assert.match(html, /__redoc_state/);
+  assert.match(html, /Dier ophalen/);
+  assert.match(html, /x-don-generated/);
+  assert.doesNotMatch(html, /fonts\.googleapis\.com/);
+  assert.doesNotMatch(html, /<script[^>]*\ssrc="https?:/i);
+});
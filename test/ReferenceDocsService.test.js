@@ -0,0 +1,106 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { asciidoc, html, markdown, renderReference } = require("../services/ReferenceDocsService");
+
+const spec = {
+  openapi: "3.0.1",
+  info: { title: "Dieren API", version: "1.2.0" },
+  servers: [{ url: "https://api.example.nl/v1", description: "productie" }],
+  tags: [{ name: "Dieren", description: "Dieren beheren" }],
+  paths: {
+    "/dieren/{id}": {
+      get: {
+        tags: ["Dieren"],
+        summary: "Dier ophalen",
+        parameters: [
+          { name: "id", in: "path", required: true, schema: { type: "string", format: "uuid" } },
+          { name: "expand", in: "query", description: "Uitbreiden", schema: { type: "string", enum: ["eigenaar"] } },
+        ],
+        responses: {
+          200: {
+            description: "OK",
+            content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } },
+          },
+          404: { description: "Niet gevonden" },
+        },
+      },
+    },
+    "/status": { get: { responses: { 200: { description: "OK" } } } },
+  },
+  components: {
+    schemas: {
+      Dier: {
+        type: "object",
+        required: ["id"],
+        properties: {
+          id: { type: "string", format: "uuid" },
+          tags: { type: "array", items: { type: "string" } },
+        },
+      },
+    },
+  },
+};
+
+test("renderReference maakt Markdown met secties per tag, tabellen en een schemabijlage", () => {
+  const text = renderReference(spec, markdown);
+
+  assert.match(text, /^<!-- generator=don-tools-api .*tool=oas-markdown .* -->\n\n# Dieren API\n/);
+  assert.match(text, /- \*\*Server:\*\* `https:\/\/api\.example\.nl\/v1` \(productie\)\n/);
+  assert.match(text, /- \[Dieren\]\(#tag-dieren\)\n- \[Overige operaties\]\(#tag-overige-operaties\)\n- \[Schema's\]/);
+  assert.match(text, /<a id="get-dieren-id"><\/a>\n\n### Dier ophalen\n\n`GET \/dieren\/\{id\}`\n/);
+  assert.match(text, /\| `id` \| path \| string \(uuid\) \| ja \| {2}\|\n/);
+  assert.match(text, /\| `expand` \| query \| string \| nee \| Uitbreiden Waarden: `"eigenaar"`\. \|\n/);
+  assert.match(text, /\| `200` \| OK \| `application\/json` \| \[Dier\]\(#schema-dier\) \|\n/);
+  assert.match(text, /\| `404` \| Niet gevonden \| {2}\| {2}\|\n/);
+  assert.match(text, /### GET \/status\n/);
+  assert.match(text, /<a id="schema-dier"><\/a>\n\n### Dier\n\n\| Veld \| Type \| Verplicht \| Beschrijving \|/);
+  assert.match(text, /\| `tags` \| array van string \| nee \| {2}\|\n$/);
+});
+
+test("renderReference met de AsciiDoc-writer gebruikt AsciiDoc-koppen, -tabellen en -links", () => {
+  const text = renderReference(spec, asciidoc);
+
+  assert.match(text, /^\/\/ generator=don-tools-api .*tool=oas-asciidoc .*\n\n= Dieren API\n/);
+  assert.match(text, /\* <<tag-dieren,Dieren>>\n/);
+  assert.match(text, /\[\[get-dieren-id\]\]\n=== Dier ophalen\n\n`\+GET \/dieren\/\{id\}\+`\n/);
+  assert.match(text, /\[options="header"\]\n\|===\n\|Naam \|In \|Type \|Verplicht \|Beschrijving\n\|`\+id\+` \|path /);
+  assert.match(text, /\|`\+200\+` \|OK \|`\+application\/json\+` \|<<schema-dier,Dier>>\n/);
+});
+
+test("renderReference met de HTML-writer geeft een zelfstandige pagina en escapet tekst uit de specificatie", () => {
+  const text = renderReference({ ...spec, info: { ...spec.info, description: "Alleen <b>lezen</b> & zoeken" } }, html);
+
+  assert.match(text, /^<!DOCTYPE html>\n<!-- generator=don-tools-api .*tool=oas-html .* -->\n<html lang="nl">\n/);
+  assert.match(text, /<style>\n@page \{ size: A4;/);
+  assert.doesNotMatch(text, /<(link|script)\b/);
+  assert.match(text, /http-equiv="Content-Security-Policy" content="default-src 'none'; style-src 'unsafe-inline'"/);
+  assert.match(text, /<p>Alleen &lt;b&gt;lezen&lt;\/b&gt; &amp; zoeken<\/p>/);
+  assert.match(text, /<h3 id="get-dieren-id">Dier ophalen<\/h3>\n<p><code>GET \/dieren\/\{id\}<\/code><\/p>/);
+  assert.match(text, /<td><code>200<\/code><\/td><td>OK<\/td><td><code>application\/json<\/code><\/td>/);
+  assert.match(text, /<a href="#schema-dier">Dier<\/a>/);
+  assert.match(text, /<\/body>\n<\/html>\n$/);
+});
+
+test("renderReference accepteert een schemanaam met een losse % in de $ref", () => {
+  const text = renderReference(
+    {
+      ...spec,
+      paths: {
+        "/korting": {
+          get: {
+            responses: {
+              200: {
+                description: "OK",
+                content: { "application/json": { schema: { $ref: "#/components/schemas/100%" } } },
+              },
+            },
+          },
+        },
+      },
+      components: { schemas: { "100%": { type: "object", properties: { id: { type: "string" } } } } },
+    },
+    markdown,
+  );
+
+  assert.match(text, /\| `200` \| OK \| `application\/json` \| \[100%\]\(#schema-100\) \|\n/);
+});
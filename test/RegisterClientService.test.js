@@ -0,0 +1,74 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { ERROR_CODES, RegisterClient } = require("../services/RegisterClientService");
+
+const jsonResponse = (status, body, headers = {}) =>
+  new Response(body === undefined ? null : JSON.stringify(body), { status, headers });
+
+test("getApi stuurt If-None-Match en hergebruikt de body bij 304", async () => {
+  const calls = [];
+  const responses = [jsonResponse(200, { id: "a" }, { ETag: '"v1"' }), new Response(null, { status: 304 })];
+  const client = new RegisterClient({
+    baseURL: "https://register.test/v1",
+    apiKey: "secret",
+    fetchImpl: async (url, init) => {
+      calls.push({ url, init });
+      return responses.shift();
+    },
+  });
+
+  const first = await client.getApi("a");
+  const second = await client.getApi("a");
+
+  assert.deepEqual(first.data, { id: "a" });
+  assert.equal(first.notModified, false);
+  assert.equal(second.notModified, true);
+  assert.deepEqual(second.data, { id: "a" });
+  assert.equal(calls[0].init.headers["X-Api-Key"], "secret");
+  assert.equal(calls[1].init.headers["If-None-Match"], '"v1"');
+});
+
+test("updateApi stuurt If-Match en vertaalt 412 naar een RegisterError", async () => {
+  let headers;
+  const client = new RegisterClient({
+    baseURL: "https://register.test/v1",
+    fetchImpl: async (_url, init) => {
+      headers = init.headers;
+      return jsonResponse(412, { detail: "gewijzigd" });
+    },
+  });
+
+  await assert.rejects(client.updateApi("a", { title: "x" }, { etag: '"v1"' }), {
+    name: "RegisterError",
+    code: ERROR_CODES.PRECONDITION_FAILED,
+    status: 412,
+  });
+  assert.equal(headers["If-Match"], '"v1"');
+});
+
+test("GET wordt herhaald bij een tijdelijke fout", async () => {
+  const responses = [jsonResponse(503), jsonResponse(200, [])];
+  const client = new RegisterClient({
+    baseURL: "https://register.test/v1",
+    fetchImpl: async () => responses.shift(),
+  });
+
+  const result = await client.listApis({ page: 1 });
+  assert.deepEqual(result.data, []);
+});
+
+test("listApisForOrganisation laat API's van andere organisaties en zonder organisatie weg", async () => {
+  const organisationUri = "https://identifier.overheid.nl/tooi/id/gemeente/gm0363";
+  const client = new RegisterClient({
+    baseURL: "https://register.test/v1",
+    fetchImpl: async () =>
+      jsonResponse(200, [
+        { id: "eigen", organisation: { uri: organisationUri } },
+        { id: "ander", organisation: "https://identifier.overheid.nl/tooi/id/gemeente/gm0599" },
+        { id: "zonder" },
+      ]),
+  });
+
+  const apis = await client.listApisForOrganisation(organisationUri);
+  assert.deepEqual(apis.map((api) => api.id), ["eigen"]);
+});
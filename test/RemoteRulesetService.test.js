@@ -0,0 +1,84 @@
+const assert = require("node:assert/strict");
+const http = require("node:http");
+const test = require("node:test");
+const { oas3 } = require("@stoplight/spectral-formats");
+const { truthy } = require("@stoplight/spectral-functions");
+const { oas } = require("@stoplight/spectral-rulesets");
+const {
+  RemoteRulesetError,
+  collectRulesetFunctions,
+  getRemoteRuleset,
+  refreshRemoteRuleset,
+  resolveRulesetDefinition,
+} = require("../services/RemoteRulesetService");
+
+test("resolveRulesetDefinition vervangt namen van functies, formats en rulesets", () => {
+  const definition = resolveRulesetDefinition({
+    extends: ["spectral:oas", "off"],
+    formats: ["oas3"],
+    rules: {
+      "info-contact": { given: "$.info", severity: "error", then: { field: "contact", function: "truthy" } },
+      "operation-tags": "off",
+    },
+  });
+
+  assert.deepEqual(definition.extends, [[oas, "off"]]);
+  assert.deepEqual(definition.formats, [oas3]);
+  assert.equal(definition.rules["info-contact"].then.function, truthy);
+  assert.equal(definition.rules["operation-tags"], "off");
+});
+
+test("resolveRulesetDefinition weigert eigen functies en onbekende rulesets", () => {
+  assert.throws(() => resolveRulesetDefinition({ functions: ["custom"], rules: {} }), RemoteRulesetError);
+  assert.throws(() => resolveRulesetDefinition({ extends: ["./local.yaml"] }), RemoteRulesetError);
+  assert.throws(
+    () => resolveRulesetDefinition({ rules: { x: { given: "$", then: { function: "custom" } } } }),
+    RemoteRulesetError,
+  );
+});
+
+test("resolveRulesetDefinition gebruikt eigen functies die hier op naam bestaan", () => {
+  const hasVersionHeader = () => [];
+  const functions = collectRulesetFunctions({
+    rules: { "version-header": { given: "$", then: { function: hasVersionHeader } }, uit: "off" },
+  });
+  const definition = resolveRulesetDefinition(
+    {
+      functions: ["hasVersionHeader"],
+      functionsDir: "./functions",
+      rules: { "version-header": { given: "$.paths", then: { function: "hasVersionHeader" } } },
+    },
+    { functions },
+  );
+
+  assert.deepEqual(functions, { hasVersionHeader });
+  assert.equal(definition.rules["version-header"].then.function, hasVersionHeader);
+  assert.equal(definition.functions, undefined);
+  assert.equal(definition.functionsDir, undefined);
+  assert.throws(
+    () => resolveRulesetDefinition({ functions: ["hasVersionHeader", "andere"], rules: {} }, { functions }),
+    /andere/,
+  );
+});
+
+test("refreshRemoteRuleset neemt een geldige ruleset over en houdt die bij een geweigerde versie", async (t) => {
+  let body = "rules:\n  info-contact:\n    given: $.info\n    then:\n      field: contact\n      function: truthy\n";
+  const server = http.createServer((request, response) => {
+    response.setHeader("Content-Type", "application/yaml");
+    response.end(body);
+  });
+  await new Promise((resolve) => server.listen(0, "127.0.0.1", resolve));
+  const url = `http://127.0.0.1:${server.address().port}/ruleset.yaml`;
+  process.env.ADR_RULESET_URL = url;
+  t.after(() => {
+    delete process.env.ADR_RULESET_URL;
+    server.close();
+  });
+
+  const loaded = await refreshRemoteRuleset(url);
+  assert.equal(loaded.contents, body);
+
+  body = "functions: [eigen]\nrules: {}\n";
+  assert.deepEqual(await refreshRemoteRuleset(url), loaded);
+  assert.deepEqual(getRemoteRuleset(), loaded);
+});
@@ -0,0 +1,94 @@
+const assert = require("node:assert/strict");
+const http = require("node:http");
+const test = require("node:test");
+const { fetchSpecification, normalizeRequestHeaders } = require("../services/RemoteSpecificationService");
+
+test("fetchSpecification stuurt meegegeven headers mee", async (t) => {
+  const calls = [];
+  t.mock.method(globalThis, "fetch", async (url, init) => {
+    calls.push({ url, init });
+    return new Response("openapi: 3.0.1", { status: 200 });
+  });
+
+  const contents = await fetchSpecification("https://gateway.test/openapi.yaml", {
+    headers: { Authorization: "Bearer token", "X-API-Key": "sleutel" },
+  });
+
+  assert.equal(contents, "openapi: 3.0.1");
+  assert.equal(calls[0].init.headers.Authorization, "Bearer token");
+  assert.equal(calls[0].init.headers["X-API-Key"], "sleutel");
+  assert.equal(calls[0].init.headers.Origin, "https://developer.overheid.nl");
+});
+
+test("fetchSpecification stuurt meegegeven headers niet mee naar een redirect op een andere origin", async (t) => {
+  const calls = [];
+  const redirects = {
+    "https://gateway.test/beveiligd.yaml": "/v2/beveiligd.yaml",
+    "https://gateway.test/v2/beveiligd.yaml": "https://elders.test/beveiligd.yaml",
+  };
+  t.mock.method(globalThis, "fetch", async (url, init) => {
+    calls.push({ url: String(url), headers: new Headers(init.headers) });
+    const location = redirects[String(url)];
+    return location
+      ? new Response(null, { status: 302, headers: { location } })
+      : new Response("openapi: 3.0.1", { status: 200 });
+  });
+
+  await fetchSpecification("https://gateway.test/beveiligd.yaml", { headers: { Authorization: "Bearer token" } });
+
+  assert.deepEqual(
+    calls.map((call) => [call.url, call.headers.get("authorization") ?? undefined]),
+    [
+      ["https://gateway.test/beveiligd.yaml", "Bearer token"],
+      ["https://gateway.test/v2/beveiligd.yaml", "Bearer token"],
+      ["https://elders.test/beveiligd.yaml", undefined],
+    ],
+  );
+  assert.equal(calls[2].headers.get("origin"), "https://developer.overheid.nl");
+});
+
+test("normalizeRequestHeaders weigert gereserveerde headers en ongeldige waarden", () => {
+  assert.deepEqual(normalizeRequestHeaders(undefined), {});
+  assert.throws(() => normalizeRequestHeaders({ Host: "evil.test" }), (error) => error.code === 400);
+  assert.throws(() => normalizeRequestHeaders({ "X-API-Key": 42 }), (error) => error.code === 400);
+  assert.throws(() => normalizeRequestHeaders({ Authorization: "a\r\nX-Injected: 1" }), (error) => error.code === 400);
+  assert.throws(() => normalizeRequestHeaders(["Authorization"]), (error) => error.code === 400);
+});
+
+test("fetchSpecification stopt met een 413 boven OAS_MAX_INPUT_BYTES", async (t) => {
+  t.mock.method(globalThis, "fetch", async () => new Response("openapi: 3.0.1\npaths: {}\n", { status: 200 }));
+  process.env.OAS_MAX_INPUT_BYTES = "10";
+  t.after(() => {
+    delete process.env.OAS_MAX_INPUT_BYTES;
+  });
+
+  await assert.rejects(fetchSpecification("https://gateway.test/groot.yaml"), (error) => error.code === 413);
+});
+
+test("fetchSpecification weigert een hostnaam die bij het verbinden naar 127.0.0.1 wijst", async (t) => {
+  const calls = [];
+  // Zoals node-fetch: de agent verbindt, een socketfout wordt een FetchError die alleen `code` overneemt.
+  t.mock.method(globalThis, "fetch", (url, init) => {
+    calls.push(String(url));
+    return new Promise((resolve, reject) => {
+      const request = http.get(url, { agent: init.agent(new URL(url)), headers: init.headers }, resolve);
+      request.on("error", (error) => {
+        reject(
+          Object.assign(new Error(`request to ${url} failed, reason: ${error.message}`), {
+            name: "FetchError",
+            type: "system",
+            code: error.code,
+          }),
+        );
+      });
+    });
+  });
+
+  await assert.rejects(fetchSpecification("http://localhost/openapi.yaml"), (error) => {
+    assert.equal(error.code, 400);
+    assert.equal(error.error.message, "Deze URL mag niet worden opgehaald.");
+    assert.match(error.error.detail, /localhost wijst naar een intern adres/);
+    return true;
+  });
+  assert.deepEqual(calls, ["http://localhost/openapi.yaml"]);
+});
@@ -0,0 +1,66 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const {
+  ScoringPolicyError,
+  computeScore,
+  getEmbeddedPolicy,
+  parsePolicy,
+} = require("../services/ScoringPolicyService");
+
+test("het meegeleverde beleid telt alleen errors van gemeten regels", () => {
+  const policy = getEmbeddedPolicy();
+  const { score, failedGroups } = computeScore(
+    [
+      { code: "nlgov:semver", severity: "error" },
+      { code: "nlgov:missing-header", severity: "error" },
+      { code: "nlgov:http-methods", severity: "warning" },
+      { code: "nlgov:unmeasured", severity: "error" },
+    ],
+    policy,
+  );
+
+  assert.deepEqual(failedGroups, ["semver", "version-header"]);
+  assert.equal(score, 75);
+});
+
+test("gewichten en severities zijn configureerbaar", () => {
+  const policy = parsePolicy({
+    severities: ["error", "warning"],
+    groups: {
+      zwaar: { weight: 3, rules: ["semver"] },
+      licht: { rules: ["http-methods"] },
+    },
+  });
+
+  assert.equal(computeScore([{ code: "semver", severity: "warning" }], policy).score, 25);
+  assert.equal(computeScore([{ code: "nlgov:http-methods", severity: "error" }], policy).score, 75);
+});
+
+test("warnings tellen alleen mee voor de gewogen score", () => {
+  const policy = parsePolicy({
+    warningWeight: 0.5,
+    groups: {
+      semver: { rules: ["semver"] },
+      methods: { rules: ["http-methods"] },
+    },
+  });
+
+  const result = computeScore(
+    [
+      { code: "http-methods", severity: "warning" },
+      { code: "semver", severity: "warning" },
+      { code: "semver", severity: "error" },
+    ],
+    policy,
+  );
+
+  assert.equal(result.score, 50);
+  assert.equal(result.weightedScore, 25);
+  assert.deepEqual(result.warnedGroups, ["methods"]);
+});
+
+test("een ongeldig beleid wordt geweigerd", () => {
+  assert.throws(() => parsePolicy({ groups: { leeg: { rules: [] } } }), ScoringPolicyError);
+  assert.throws(() => parsePolicy({ severities: ["fatal"], groups: {} }), ScoringPolicyError);
+  assert.throws(() => parsePolicy({ warningWeight: 2, groups: {} }), ScoringPolicyError);
+});
@@ -0,0 +1,44 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { normalizeServers } = require("../services/ServerNormalizationService");
+
+test("normalizeServers vervangt, dwingt https af en haalt slashes aan het eind weg", () => {
+  const spec = {
+    openapi: "3.0.3",
+    info: { title: "Dieren API", version: "1.0.0" },
+    servers: [
+      { url: "http://api.example.nl/v1/" },
+      { url: "https://test.dieren.intern/v1" },
+      { url: "http://localhost:8080/" },
+      { url: "https://{host}/v1", variables: { host: { default: "10.0.0.12" } } },
+      { url: "/" },
+    ],
+    paths: { "/dieren": { get: { servers: [{ url: "https://upload.example.nl//" }], responses: {} } } },
+  };
+
+  const { changes, warnings } = normalizeServers(spec, {
+    serverMap: { "https://test.dieren.intern/v1/": "https://test.api.example.nl/v1", "https://onbekend.nl": "x" },
+  });
+
+  assert.deepEqual(
+    spec.servers.map(({ url }) => url),
+    ["https://api.example.nl/v1", "https://test.api.example.nl/v1", "https://localhost:8080", "https://{host}/v1", "/"],
+  );
+  assert.equal(spec.paths["/dieren"].get.servers[0].url, "https://upload.example.nl");
+  assert.deepEqual(
+    changes.map(({ type, path }) => `${type} ${path}`),
+    [
+      "https #/servers/0/url",
+      "trailing-slash #/servers/0/url",
+      "substituted #/servers/1/url",
+      "https #/servers/2/url",
+      "trailing-slash #/servers/2/url",
+      "trailing-slash #/paths/~1dieren/get/servers/0/url",
+    ],
+  );
+  assert.deepEqual(
+    warnings.map(({ type }) => type),
+    ["localhost", "ip-literal", "unused-mapping"],
+  );
+  assert.equal(warnings[1].path, "#/servers/3/url");
+});
@@ -0,0 +1,35 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { buildProject } = require("../services/SoapUiConversionService");
+
+const spec = {
+  openapi: "3.0.3",
+  info: { title: "Dieren & co", version: "1.0.0" },
+  servers: [{ url: "https://api.example.nl/v1" }],
+  security: [{ bearer: [] }],
+  components: { securitySchemes: { bearer: { type: "http", scheme: "bearer" } } },
+  paths: {
+    "/dieren/{id}": {
+      get: {
+        summary: "Dier ophalen",
+        parameters: [{ name: "id", in: "path", required: true, schema: { type: "integer" }, example: 42 }],
+        responses: { 200: { description: "OK" } },
+      },
+    },
+  },
+};
+
+test("buildProject maakt een SoapUI project met service, testcase en projectproperties", () => {
+  const { projectName, xml } = buildProject(spec);
+
+  assert.equal(projectName, "Dieren & co");
+  assert.match(xml, /^<\?xml version="1.0" encoding="UTF-8"\?>\n<!-- generator=don-tools-api /);
+  assert.match(xml, /<con:soapui-project [^>]*name="Dieren &amp; co"/);
+  assert.match(xml, /<con:endpoint>https:\/\/api\.example\.nl\/v1<\/con:endpoint>/);
+  assert.match(xml, /<con:resource name="\/dieren\/\{id\}" path="\/dieren\/\{id\}"/);
+  assert.match(xml, /<con:method name="Dier ophalen" id="[0-9a-f-]{36}" method="GET">/);
+  assert.match(xml, /<entry key="Authorization" value="Bearer \$\{#Project#token\}"/);
+  assert.match(xml, /<con:configuration><codes>200<\/codes><\/con:configuration>/);
+  assert.match(xml, /<con:property>\n\s+<con:name>token<\/con:name>/);
+  assert.equal(buildProject(spec).xml.split("\n").slice(2).join("\n"), xml.split("\n").slice(2).join("\n"));
+});
@@ -0,0 +1,60 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const jsYaml = require("js-yaml");
+const { splitDocument } = require("../services/SplitService");
+
+const createDocument = () => ({
+  openapi: "3.0.3",
+  info: { title: "Dieren API", version: "1.0.0" },
+  paths: {
+    "/dieren/{id}": {
+      parameters: [{ $ref: "#/components/parameters/Id" }],
+      get: {
+        responses: {
+          200: {
+            description: "OK",
+            content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } },
+          },
+          404: { $ref: "#/paths/~1dieren~1%7Bid%7D/get/responses/200" },
+        },
+      },
+    },
+  },
+  components: {
+    schemas: {
+      Dier: {
+        type: "object",
+        properties: {
+          moeder: { $ref: "#/components/schemas/Dier" },
+          adres: { $ref: "./gedeeld.yaml#/Adres" },
+        },
+        example: { $ref: "geen verwijzing" },
+      },
+    },
+    parameters: { Id: { name: "id", in: "path", required: true, schema: { type: "string" } } },
+  },
+});
+
+test("splitDocument zet paden en componenten in eigen bestanden met relatieve verwijzingen", () => {
+  const { root, files } = splitDocument(createDocument());
+  const contents = Object.fromEntries(files.map((file) => [file.name, jsYaml.load(file.data)]));
+
+  assert.deepEqual(Object.keys(contents), [
+    "paths/dieren_{id}.yaml",
+    "components/schemas/Dier.yaml",
+    "components/parameters/Id.yaml",
+  ]);
+  assert.deepEqual(root.paths, { "/dieren/{id}": { $ref: "paths/dieren_{id}.yaml" } });
+  assert.deepEqual(root.components.schemas.Dier, { $ref: "components/schemas/Dier.yaml" });
+  assert.deepEqual(root.info, { title: "Dieren API", version: "1.0.0" });
+
+  const pathItem = contents["paths/dieren_{id}.yaml"];
+  assert.equal(pathItem.parameters[0].$ref, "../components/parameters/Id.yaml");
+  assert.equal(pathItem.get.responses[200].content["application/json"].schema.$ref, "../components/schemas/Dier.yaml");
+  assert.equal(pathItem.get.responses[404].$ref, "#/get/responses/200");
+
+  const schema = contents["components/schemas/Dier.yaml"];
+  assert.equal(schema.properties.moeder.$ref, "Dier.yaml");
+  assert.equal(schema.properties.adres.$ref, "../../gedeeld.yaml#/Adres");
+  assert.deepEqual(schema.example, { $ref: "geen verwijzing" });
+});
@@ -0,0 +1,73 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { createTranslationBackend, translateDocument } = require("../services/TranslationService");
+
+const createDocument = () => ({
+  openapi: "3.0.3",
+  info: { title: "Dieren API", version: "1.0.0", description: "Beheer van dieren." },
+  paths: {
+    "/dieren": {
+      get: {
+        summary: "Dieren ophalen",
+        description: "Beheer van dieren.",
+        responses: { 200: { description: "OK" } },
+        "x-notitie": { description: "Niet vertalen" },
+      },
+    },
+  },
+  components: {
+    schemas: {
+      Dier: {
+        type: "object",
+        properties: { description: { type: "string", description: "Omschrijving van het dier." } },
+        example: { description: "Een kat" },
+      },
+    },
+  },
+});
+
+test("translateDocument vertaalt beschrijvingen en samenvattingen, maar geen voorbeelden of extensies", async () => {
+  const spec = createDocument();
+  const batches = [];
+  const backend = async (texts, languages) => {
+    batches.push({ texts, languages });
+    return texts.map((text) => `EN: ${text}`);
+  };
+
+  const count = await translateDocument(spec, { source: "nl", target: "en", backend });
+
+  assert.equal(count, 5);
+  assert.deepEqual(batches, [
+    {
+      texts: ["Beheer van dieren.", "Dieren ophalen", "OK", "Omschrijving van het dier."],
+      languages: { source: "nl", target: "en" },
+    },
+  ]);
+  assert.equal(spec.info.description, "EN: Beheer van dieren.");
+  assert.equal(spec.paths["/dieren"].get.description, "EN: Beheer van dieren.");
+  assert.equal(spec.paths["/dieren"].get["x-notitie"].description, "Niet vertalen");
+  assert.equal(spec.components.schemas.Dier.properties.description.description, "EN: Omschrijving van het dier.");
+  assert.deepEqual(spec.components.schemas.Dier.example, { description: "Een kat" });
+  assert.equal(spec.info.title, "Dieren API");
+});
+
+test("createTranslationBackend spreekt LibreTranslate en weigert een onbruikbaar antwoord", async () => {
+  const requests = [];
+  const backend = createTranslationBackend({
+    url: "https://vertaal.test/translate",
+    provider: "libretranslate",
+    apiKey: "geheim",
+    fetchImpl: async (url, init) => {
+      requests.push({ url, body: JSON.parse(init.body) });
+      return { ok: true, status: 200, json: async () => ({ translatedText: ["Animals"] }) };
+    },
+  });
+
+  assert.deepEqual(await backend(["Dieren"], { source: "nl", target: "en" }), ["Animals"]);
+  assert.deepEqual(requests[0], {
+    url: "https://vertaal.test/translate",
+    body: { q: ["Dieren"], source: "nl", target: "en", format: "text", api_key: "geheim" },
+  });
+  await assert.rejects(backend(["Dieren", "Planten"], { source: "nl", target: "en" }), (error) => error.code === 502);
+  await assert.rejects(createTranslationBackend({})(["Dieren"], {}), (error) => error.code === 500);
+});
@@ -0,0 +1,61 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { renderTypes } = require("../services/TypeScriptTypesService");
+
+const spec = {
+  openapi: "3.0.3",
+  info: { title: "Dieren API", version: "1.0.0" },
+  paths: {
+    "/dieren/{id}": {
+      get: {
+        operationId: "getDier",
+        parameters: [{ name: "id", in: "path", required: true, schema: { type: "string", format: "uuid" } }],
+        responses: {
+          200: {
+            description: "OK",
+            content: { "application/json": { schema: { $ref: "#/components/schemas/Dier" } } },
+          },
+          404: { $ref: "#/components/responses/NotFound" },
+        },
+      },
+    },
+  },
+  components: {
+    responses: { NotFound: { description: "Niet gevonden" } },
+    schemas: {
+      Dier: {
+        type: "object",
+        required: ["id"],
+        properties: {
+          id: { type: "string", readOnly: true },
+          naam: { type: "string", nullable: true },
+          "soort-code": { type: "string", enum: ["hond", "kat"] },
+          kinderen: { type: "array", items: { $ref: "#/components/schemas/Dier" } },
+        },
+      },
+      Kat: {
+        allOf: [{ $ref: "#/components/schemas/Dier" }, { type: "object", properties: { miauw: { type: "boolean" } } }],
+      },
+    },
+  },
+};
+
+test("renderTypes zet schema's om naar TypeScript met verwijzingen, nullable en enums", () => {
+  const text = renderTypes(spec);
+
+  assert.match(text, /^\/\*\*\n \* generator=don-tools-api .*tool=oas-typescript /);
+  assert.match(text, /\n {8}Dier: \{\n {12}readonly id: string;\n {12}naam\?: string \| null;\n/);
+  assert.match(text, /\n {12}"soort-code"\?: "hond" \| "kat";\n/);
+  assert.match(text, /\n {12}kinderen\?: components\["schemas"\]\["Dier"\]\[\];\n/);
+  assert.match(text, /\n {8}Kat: components\["schemas"\]\["Dier"\] & \{\n {12}miauw\?: boolean;\n {8}\};\n/);
+  assert.match(text, /\nexport type Kat = components\["schemas"\]\["Kat"\];\n$/);
+});
+
+test("renderTypes verwijst vanuit paths naar operations en houdt response-refs intact", () => {
+  const text = renderTypes(spec);
+
+  assert.match(text, /\n {4}"\/dieren\/\{id\}": \{\n {8}get: operations\["getDier"\];\n {4}\};\n/);
+  assert.match(text, /\n {12}path: \{\n {16}\/\*\* Format: uuid \*\/\n {16}id: string;\n {12}\};\n/);
+  assert.match(text, /\n {12}404: components\["responses"\]\["NotFound"\];\n/);
+  assert.match(text, /\n {4}requestBodies: never;\n/);
+});
@@ -0,0 +1,85 @@
+const assert = require("node:assert/strict");
+const crypto = require("node:crypto");
+const http = require("node:http");
+const test = require("node:test");
+
+const SECRET = "webhook-geheim";
+
+// De service laadt het uitgaande beleid bij het laden; de testserver op 127.0.0.1 moet daarin toegestaan zijn.
+// Zonder poort geldt het standaardbeleid.
+const loadWebhookService = (port) => {
+  delete require.cache[require.resolve("../services/WebhookService")];
+  if (port === undefined) {
+    return require("../services/WebhookService");
+  }
+  process.env.OUTBOUND_ALLOWLIST = "127.0.0.1";
+  process.env.OUTBOUND_ALLOWED_PORTS = String(port);
+  const service = require("../services/WebhookService");
+  delete process.env.OUTBOUND_ALLOWLIST;
+  delete process.env.OUTBOUND_ALLOWED_PORTS;
+  return service;
+};
+
+// Een ontvanger die de eerste aanroep met een 500 weigert en daarna elke aanroep accepteert.
+const startReceiver = async (t) => {
+  const received = [];
+  const server = http.createServer((request, response) => {
+    const chunks = [];
+    request.on("data", (chunk) => chunks.push(chunk));
+    request.on("end", () => {
+      received.push({ headers: request.headers, body: Buffer.concat(chunks).toString("utf8") });
+      response.statusCode = received.length === 1 ? 500 : 204;
+      response.end();
+    });
+  });
+  await new Promise((resolve) => server.listen(0, "127.0.0.1", resolve));
+  t.after(() => server.close());
+  return { port: server.address().port, received };
+};
+
+test("deliver ondertekent de callback met sha256= over timestamp en body en probeert het opnieuw", async (t) => {
+  process.env.LINT_CALLBACK_SECRET = SECRET;
+  t.after(() => delete process.env.LINT_CALLBACK_SECRET);
+  const { port, received } = await startReceiver(t);
+  const { SIGNATURE_HEADER, TIMESTAMP_HEADER, deliver, sign } = loadWebhookService(port);
+
+  const payload = { lintId: "3f0c2a4e", score: 90 };
+  assert.equal(await deliver(`http://127.0.0.1:${port}/hook`, "lint.completed", payload, { attempts: 2 }), true);
+
+  assert.equal(received.length, 2);
+  for (const { headers, body } of received) {
+    const timestamp = headers[TIMESTAMP_HEADER.toLowerCase()];
+    const expected = crypto.createHmac("sha256", SECRET).update(`${timestamp}.${body}`).digest("hex");
+    assert.equal(body, JSON.stringify(payload));
+    assert.equal(headers[SIGNATURE_HEADER.toLowerCase()], `sha256=${expected}`);
+    assert.equal(headers["x-don-event"], "lint.completed");
+  }
+  assert.notEqual(sign("{}", "1700000000", SECRET), sign("{}", "1700000001", SECRET));
+});
+
+test("parseCallbackUrl weigert interne adressen en andere schema's", () => {
+  process.env.LINT_CALLBACK_SECRET = SECRET;
+  const { parseCallbackUrl } = loadWebhookService();
+
+  const rejected = (status, message) => (error) =>
+    error.code === status && (message === undefined || error.error.message === message);
+  for (const url of ["http://169.254.169.254/latest/meta-data/", "http://10.0.0.8/hook", "https://[::1]/hook"]) {
+    assert.throws(() => parseCallbackUrl(url), rejected(400, "Deze callbackUrl is niet toegestaan."));
+  }
+  assert.throws(() => parseCallbackUrl("ftp://ontvanger.example.nl/hook"), rejected(400));
+  assert.throws(() => parseCallbackUrl("geen url"), rejected(400));
+  assert.equal(parseCallbackUrl("https://ontvanger.example.nl/hook"), "https://ontvanger.example.nl/hook");
+  assert.equal(parseCallbackUrl(undefined), null);
+
+  delete process.env.LINT_CALLBACK_SECRET;
+  assert.throws(() => parseCallbackUrl("https://ontvanger.example.nl/hook"), rejected(500));
+});
+
+test("parseCallbackUrl toetst met het beleid dat de service bij het laden heeft ingelezen", (t) => {
+  process.env.LINT_CALLBACK_SECRET = SECRET;
+  t.after(() => delete process.env.LINT_CALLBACK_SECRET);
+  const { parseCallbackUrl } = loadWebhookService(8443);
+
+  assert.equal(parseCallbackUrl("http://127.0.0.1:8443/hook"), "http://127.0.0.1:8443/hook");
+  assert.throws(() => parseCallbackUrl("https://ontvanger.example.nl/hook"), (error) => error.code === 400);
+});
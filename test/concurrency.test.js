@@ -0,0 +1,22 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { mapWithConcurrency } = require("../utils/concurrency");
+
+test("mapWithConcurrency keeps input order and respects the limit", async () => {
+  let active = 0;
+  let peak = 0;
+  const results = await mapWithConcurrency([30, 10, 20, 5, 15], 2, async (delay, index) => {
+    active += 1;
+    peak = Math.max(peak, active);
+    await new Promise((resolve) => setTimeout(resolve, delay));
+    active -= 1;
+    return index;
+  });
+
+  assert.deepEqual(results, [0, 1, 2, 3, 4]);
+  assert.equal(peak, 2);
+});
+
+test("mapWithConcurrency handles an empty list", async () => {
+  assert.deepEqual(await mapWithConcurrency([], 4, async () => 1), []);
+});
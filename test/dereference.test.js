@@ -0,0 +1,52 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { CircularReferenceError, dereferenceDocument } = require("../utils/dereference");
+
+const spec = () => ({
+  openapi: "3.1.0",
+  paths: {
+    "/nodes": {
+      get: {
+        responses: {
+          200: {
+            description: "OK",
+            content: { "application/json": { schema: { $ref: "#/components/schemas/Node" } } },
+          },
+        },
+      },
+    },
+  },
+  components: {
+    schemas: {
+      Node: {
+        type: "object",
+        properties: {
+          naam: { $ref: "#/components/schemas/Naam", description: "Naam van de node" },
+          children: { type: "array", items: { $ref: "#/components/schemas/Node" } },
+        },
+        example: { $ref: "geen verwijzing" },
+      },
+      Naam: { type: "string" },
+    },
+  },
+});
+
+test("dereferenceDocument schrijft refs uit en laat de verwijzing die een kring sluit staan", () => {
+  const result = dereferenceDocument(spec());
+  const node = result.paths["/nodes"].get.responses[200].content["application/json"].schema;
+
+  assert.deepEqual(node.properties.naam, { type: "string", description: "Naam van de node" });
+  assert.deepEqual(node.properties.children.items, { $ref: "#/components/schemas/Node" });
+  assert.deepEqual(node.example, { $ref: "geen verwijzing" });
+  assert.deepEqual(result.components.schemas.Node.properties.children.items, { $ref: "#/components/schemas/Node" });
+  assert.doesNotThrow(() => JSON.stringify(result));
+});
+
+test("dereferenceDocument meldt de keten bij onCycle error", () => {
+  assert.throws(
+    () => dereferenceDocument(spec(), { onCycle: "error" }),
+    (error) =>
+      error instanceof CircularReferenceError &&
+      error.cycle.join(" ") === "#/components/schemas/Node #/components/schemas/Node",
+  );
+});
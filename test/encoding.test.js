@@ -0,0 +1,32 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { decodeSpecification, stripBom } = require("../utils/encoding");
+
+const spec = 'openapi: 3.0.1\ninfo:\n  title: "Café"\n';
+
+test("decodeSpecification verwijdert een UTF-8 BOM", () => {
+  const bytes = Buffer.concat([Buffer.from([0xef, 0xbb, 0xbf]), Buffer.from(spec, "utf8")]);
+
+  assert.equal(decodeSpecification(bytes), spec);
+});
+
+test("decodeSpecification herkent UTF-16 met en zonder BOM", () => {
+  const littleEndian = Buffer.from(spec, "utf16le");
+  const bigEndian = Buffer.from(littleEndian).swap16();
+
+  assert.equal(decodeSpecification(Buffer.concat([Buffer.from([0xff, 0xfe]), littleEndian])), spec);
+  assert.equal(decodeSpecification(Buffer.concat([Buffer.from([0xfe, 0xff]), bigEndian])), spec);
+  assert.equal(decodeSpecification(littleEndian), spec);
+  assert.equal(decodeSpecification(bigEndian), spec);
+});
+
+test("decodeSpecification volgt de charset uit Content-Type", () => {
+  const latin1 = Buffer.from(spec, "latin1");
+
+  assert.equal(decodeSpecification(latin1, { contentType: "application/yaml; charset=ISO-8859-1" }), spec);
+});
+
+test("stripBom laat tekst zonder BOM ongemoeid", () => {
+  assert.equal(stripBom("\uFEFF{}"), "{}");
+  assert.equal(stripBom("{}"), "{}");
+});
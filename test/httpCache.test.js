@@ -0,0 +1,101 @@
+const assert = require("node:assert/strict");
+const fs = require("node:fs");
+const os = require("node:os");
+const path = require("node:path");
+const test = require("node:test");
+const { loadHttpCacheConfig, withHttpCache } = require("../utils/httpCache");
+
+const ARTIFACT_ENCRYPTION_KEY = Buffer.alloc(32, 7).toString("base64");
+
+const cacheConfig = (env = {}) =>
+  loadHttpCacheConfig({
+    ARTIFACT_ENCRYPTION_KEY,
+    OAS_FETCH_CACHE_DIR: path.join(fs.mkdtempSync(path.join(os.tmpdir(), "http-cache-test-")), "cache"),
+    ...env,
+  });
+
+const etagFetch = (calls) => async (url) => {
+  calls.push(url);
+  return new Response("openapi: 3.1.0", { headers: { ETag: '"v1"', "Content-Type": "application/yaml" } });
+};
+
+test("withHttpCache hervalideert met de ETag en levert bij 304 de bewaarde inhoud", async () => {
+  const calls = [];
+  const fetchImpl = async (url, init = {}) => {
+    const ifNoneMatch = new Headers(init.headers).get("If-None-Match");
+    calls.push(ifNoneMatch);
+    return ifNoneMatch === '"v1"'
+      ? new Response(null, { status: 304 })
+      : new Response("openapi: 3.1.0", { headers: { ETag: '"v1"', "Content-Type": "application/yaml" } });
+  };
+  const cachedFetch = withHttpCache(fetchImpl, cacheConfig());
+
+  assert.equal(await (await cachedFetch("https://example.nl/openapi.yaml")).text(), "openapi: 3.1.0");
+  const revalidated = await cachedFetch("https://example.nl/openapi.yaml");
+
+  assert.equal(revalidated.status, 200);
+  assert.equal(revalidated.headers.get("content-type"), "application/yaml");
+  assert.equal(await revalidated.text(), "openapi: 3.1.0");
+  assert.deepEqual(calls, [null, '"v1"']);
+});
+
+test("withHttpCache slaat persoonlijke verzoeken en no-store over en vraagt binnen max-age niets", async () => {
+  let calls = 0;
+  const fetchImpl = async (url) => {
+    calls += 1;
+    const cacheControl = url.endsWith("vers.yaml") ? "max-age=60" : "no-store";
+    return new Response(`versie ${calls}`, { headers: { ETag: `"${calls}"`, "Cache-Control": cacheControl } });
+  };
+  const cachedFetch = withHttpCache(fetchImpl, cacheConfig());
+
+  await cachedFetch("https://example.nl/vers.yaml");
+  assert.equal(await (await cachedFetch("https://example.nl/vers.yaml")).text(), "versie 1");
+  await cachedFetch("https://example.nl/geheim.yaml");
+  assert.equal(await (await cachedFetch("https://example.nl/geheim.yaml")).text(), "versie 3");
+  const personal = await cachedFetch("https://example.nl/vers.yaml", { headers: { Authorization: "Bearer token" } });
+  assert.equal(await personal.text(), "versie 4");
+});
+
+test("withHttpCache versleutelt entries in een map die alleen de eigenaar kan lezen", async () => {
+  const config = cacheConfig();
+  const cachedFetch = withHttpCache(etagFetch([]), config);
+
+  await cachedFetch("https://example.nl/openapi.yaml");
+
+  const [name] = fs.readdirSync(config.directory);
+  const file = path.join(config.directory, name);
+  assert.equal(fs.statSync(config.directory).mode & 0o777, 0o700);
+  assert.equal(fs.statSync(file).mode & 0o777, 0o600);
+  assert.doesNotMatch(fs.readFileSync(file, "latin1"), /openapi|example\.nl/);
+});
+
+test("withHttpCache ruimt entries op die ouder zijn dan ARTIFACT_RETENTION_DAYS", async () => {
+  const calls = [];
+  const config = cacheConfig({ ARTIFACT_RETENTION_DAYS: "1" });
+  const cachedFetch = withHttpCache(etagFetch(calls), config);
+
+  await cachedFetch("https://example.nl/oud.yaml");
+  const [name] = fs.readdirSync(config.directory);
+  const twoDaysAgo = new Date(Date.now() - 2 * 24 * 60 * 60 * 1000);
+  fs.utimesSync(path.join(config.directory, name), twoDaysAgo, twoDaysAgo);
+  await cachedFetch("https://example.nl/nieuw.yaml");
+
+  assert.equal(fs.readdirSync(config.directory).length, 1);
+  await cachedFetch("https://example.nl/oud.yaml");
+  assert.deepEqual(calls, [
+    "https://example.nl/oud.yaml",
+    "https://example.nl/nieuw.yaml",
+    "https://example.nl/oud.yaml",
+  ]);
+});
+
+test("loadHttpCacheConfig zet de cache uit zonder ARTIFACT_ENCRYPTION_KEY", async () => {
+  const config = cacheConfig({ ARTIFACT_ENCRYPTION_KEY: undefined });
+  const calls = [];
+  const cachedFetch = withHttpCache(etagFetch(calls), config);
+
+  assert.equal(config.enabled, false);
+  await cachedFetch("https://example.nl/openapi.yaml");
+  assert.equal(fs.existsSync(config.directory), false);
+  assert.equal(loadHttpCacheConfig({}).directory, path.join(__dirname, "..", "artifacts", "fetch-cache"));
+});
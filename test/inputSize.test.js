@@ -0,0 +1,67 @@
+const assert = require("node:assert/strict");
+const fs = require("node:fs");
+const os = require("node:os");
+const path = require("node:path");
+const test = require("node:test");
+const { InputTooLargeError, readLimitedBody, spoolLimitedBody } = require("../utils/inputSize");
+
+// Een response die de body in losse chunks aanlevert, zoals een download over het netwerk.
+const chunkedResponse = (chunks, headers = {}) =>
+  new Response(
+    new ReadableStream({
+      start(controller) {
+        for (const chunk of chunks) {
+          controller.enqueue(new TextEncoder().encode(chunk));
+        }
+        controller.close();
+      },
+    }),
+    { headers },
+  );
+
+// Tijdelijke bestanden komen in een eigen map, zodat de test kan zien wat er achterblijft.
+const useTempDir = (t) => {
+  const directory = fs.mkdtempSync(path.join(os.tmpdir(), "input-size-test-"));
+  const previous = process.env.TMPDIR;
+  process.env.TMPDIR = directory;
+  t.after(() => {
+    if (previous === undefined) {
+      delete process.env.TMPDIR;
+    } else {
+      process.env.TMPDIR = previous;
+    }
+    fs.rmSync(directory, { recursive: true, force: true });
+  });
+  return directory;
+};
+
+test("spoolLimitedBody schrijft de body naar een tijdelijk bestand dat alleen de eigenaar kan lezen", async (t) => {
+  const directory = useTempDir(t);
+
+  const spooled = await spoolLimitedBody(chunkedResponse(["openapi: 3.1.0\n", "paths: {}\n"]), 100);
+
+  assert.equal(path.dirname(path.dirname(spooled.path)), directory);
+  assert.equal(spooled.size, 25);
+  assert.equal(fs.readFileSync(spooled.path, "utf8"), "openapi: 3.1.0\npaths: {}\n");
+  assert.equal(fs.statSync(spooled.path).mode & 0o777, 0o600);
+  await spooled.remove();
+  assert.deepEqual(fs.readdirSync(directory), []);
+});
+
+test("spoolLimitedBody stopt halverwege boven de limiet en ruimt het bestand op", async (t) => {
+  const directory = useTempDir(t);
+
+  await assert.rejects(spoolLimitedBody(chunkedResponse(["openapi: 3.1.0\n", "paths: {}\n"]), 20), InputTooLargeError);
+  assert.deepEqual(fs.readdirSync(directory), []);
+  await assert.rejects(spoolLimitedBody(chunkedResponse([], { "Content-Length": "21" }), 20), InputTooLargeError);
+});
+
+test("readLimitedBody leest het gespoolde bestand en laat niets achter", async (t) => {
+  const directory = useTempDir(t);
+
+  const body = await readLimitedBody(chunkedResponse(["openapi: ", "3.1.0"]), 100);
+
+  assert.equal(body.toString("utf8"), "openapi: 3.1.0");
+  assert.deepEqual(fs.readdirSync(directory), []);
+  assert.equal((await readLimitedBody(new Response(null), 100)).length, 0);
+});
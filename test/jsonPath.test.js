@@ -0,0 +1,41 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { JsonPathError, queryJsonPath } = require("../utils/jsonPath");
+
+const document = {
+  info: { title: "Dieren API" },
+  servers: [{ url: "https://api.example.nl" }, { url: "https://test.example.nl" }],
+  paths: {
+    "/dieren": {
+      get: { "x-internal": true, tags: ["dieren"] },
+      post: { tags: ["dieren"], deprecated: true },
+    },
+    "/eigenaren": { get: { tags: ["eigenaren"] } },
+  },
+};
+
+const keys = (query) => queryJsonPath(document, query).map((node) => node.key);
+
+test("queryJsonPath selecteert namen, wildcards, indexen en slices", () => {
+  assert.deepEqual(queryJsonPath(document, "$.info.title")[0].value, "Dieren API");
+  assert.deepEqual(queryJsonPath(document, "$.paths['/dieren'].post")[0].parent, document.paths["/dieren"]);
+  assert.deepEqual(keys("$.paths.*.*"), ["get", "post", "get"]);
+  assert.deepEqual(keys("$.servers[-1]"), [1]);
+  assert.deepEqual(keys("$.servers[::-1]"), [1, 0]);
+  assert.deepEqual(keys("$..tags"), ["tags", "tags", "tags"]);
+  assert.deepEqual(queryJsonPath(document, "$")[0].parent, null);
+});
+
+test("queryJsonPath ondersteunt filters met vergelijkingen en bestaanstests", () => {
+  assert.deepEqual(keys("$.paths.*[?@.x-internal == true]"), ["get"]);
+  assert.deepEqual(keys("$.paths.*[?(@['x-internal'] == true)]"), ["get"]);
+  assert.deepEqual(keys("$.paths.*[?(@.deprecated || @.tags[0] == 'eigenaren')]"), ["post", "get"]);
+  assert.deepEqual(keys("$.paths['/dieren'][?!@.deprecated]"), ["get"]);
+  assert.deepEqual(keys("$.servers[?@.url > 'https://b']"), [1]);
+});
+
+test("queryJsonPath geeft een JsonPathError bij een ongeldige query", () => {
+  assert.throws(() => queryJsonPath(document, "info.title"), JsonPathError);
+  assert.throws(() => queryJsonPath(document, "$.paths["), JsonPathError);
+  assert.throws(() => queryJsonPath(document, "$.servers[?(@.url == )]"), JsonPathError);
+});
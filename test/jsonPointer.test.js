@@ -0,0 +1,23 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { decodePointerSegment, encodePointerSegment, formatPointer, lookupPointer } = require("../utils/jsonPointer");
+
+test("encodePointerSegment en decodePointerSegment zijn elkaars omgekeerde", () => {
+  assert.equal(encodePointerSegment("a/b~c"), "a~1b~0c");
+  assert.equal(decodePointerSegment("a~1b~0c"), "a/b~c");
+  assert.equal(formatPointer(["paths", "/dieren", "get"]), "#/paths/~1dieren/get");
+});
+
+test("decodePointerSegment leest percent-encoding en laat ongeldige encoding staan", () => {
+  assert.equal(decodePointerSegment("%7Bid%7D"), "{id}");
+  assert.equal(decodePointerSegment("100%"), "100%");
+});
+
+test("lookupPointer volgt alleen eigen properties van lokale verwijzingen", () => {
+  const document = { components: { schemas: { "Dier/Kat": { type: "object" }, "100%": { type: "string" } } } };
+  assert.deepEqual(lookupPointer(document, "#/components/schemas/Dier~1Kat"), { type: "object" });
+  assert.deepEqual(lookupPointer(document, "#/components/schemas/100%"), { type: "string" });
+  assert.equal(lookupPointer(document, "#/components/schemas/Onbekend"), undefined);
+  assert.equal(lookupPointer(document, "#/components/schemas/constructor"), undefined);
+  assert.equal(lookupPointer(document, "https://example.nl/spec.json#/components"), undefined);
+});
@@ -0,0 +1,53 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const {
+  OutboundPolicyError,
+  checkResolvedUrl,
+  checkUrl,
+  createGuardedLookup,
+  loadOutboundPolicy,
+} = require("../utils/outboundPolicy");
+
+const isPolicyError = (error) => error instanceof OutboundPolicyError;
+
+test("checkUrl weigert interne adressen, andere schema's en poorten", () => {
+  const policy = loadOutboundPolicy({});
+
+  assert.doesNotThrow(() => checkUrl("https://api.example.nl/openapi.yaml", policy));
+  assert.throws(() => checkUrl("http://169.254.169.254/latest/meta-data/", policy), isPolicyError);
+  assert.throws(() => checkUrl("http://10.0.0.8:8080/openapi.json", policy), isPolicyError);
+  assert.throws(() => checkUrl("http://[::1]/openapi.json", policy), isPolicyError);
+  assert.throws(() => checkUrl("http://[::ffff:127.0.0.1]/openapi.json", policy), isPolicyError);
+  assert.throws(() => checkUrl("http://[64:ff9b::a9fe:a9fe]/latest/meta-data/", policy), isPolicyError);
+  assert.throws(() => checkUrl("http://[64:ff9b::10.0.0.8]/openapi.json", policy), isPolicyError);
+  assert.throws(() => checkUrl("http://[2002:c0a8:10a::1]/openapi.json", policy), isPolicyError);
+  assert.throws(() => checkUrl("http://[2002:7f00:1::]/openapi.json", policy), isPolicyError);
+  assert.throws(() => checkUrl("file:///etc/passwd", policy), isPolicyError);
+  assert.throws(() => checkUrl("https://api.example.nl:22/", policy), isPolicyError);
+});
+
+test("allowlist en denylist gaan voor de standaardregels", () => {
+  const policy = loadOutboundPolicy({
+    OUTBOUND_ALLOWLIST: "*.example.nl, 10.20.0.0/16",
+    OUTBOUND_DENYLIST: "intern.example.nl",
+  });
+
+  assert.doesNotThrow(() => checkUrl("https://api.example.nl/", policy));
+  assert.doesNotThrow(() => checkUrl("http://10.20.1.2/openapi.json", policy));
+  assert.throws(() => checkUrl("https://intern.example.nl/", policy), isPolicyError);
+  assert.throws(() => checkUrl("https://api.example.com/", policy), isPolicyError);
+  assert.doesNotThrow(() => checkUrl("http://10.0.0.8/", loadOutboundPolicy({ OUTBOUND_ALLOW_PRIVATE: "true" })));
+});
+
+test("de adressen van een hostnaam worden getoetst", async () => {
+  const policy = loadOutboundPolicy({});
+  const lookup = async () => [{ address: "192.168.1.10", family: 4 }];
+
+  await assert.rejects(checkResolvedUrl("https://rebind.example.nl/", policy, lookup), isPolicyError);
+
+  const guarded = createGuardedLookup(policy, 443, (hostname, options, callback) =>
+    callback(null, [{ address: "127.0.0.1", family: 4 }]),
+  );
+  const error = await new Promise((resolve) => guarded("rebind.example.nl", {}, resolve));
+  assert.ok(isPolicyError(error));
+});
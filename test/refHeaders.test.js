@@ -0,0 +1,36 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { headersFor, isHostPattern, withRefHeaders } = require("../utils/refHeaders");
+
+const rules = {
+  "schemas.example.nl": { "X-API-Key": "sleutel" },
+  "*.intern.example.nl": { Authorization: "Bearer token" },
+};
+
+test("headersFor kiest de headers van de hosts waar de URL op past", () => {
+  assert.deepEqual(headersFor(rules, new URL("https://schemas.example.nl/adres.yaml")), { "X-API-Key": "sleutel" });
+  assert.deepEqual(headersFor(rules, new URL("https://api.intern.example.nl/a.yaml")), {
+    Authorization: "Bearer token",
+  });
+  assert.deepEqual(headersFor(rules, new URL("https://example.nl/a.yaml")), {});
+  assert.ok(isHostPattern("*.example.nl"));
+  assert.ok(!isHostPattern("https://example.nl"));
+});
+
+test("withRefHeaders stuurt de headers niet mee na een redirect naar een andere host", async () => {
+  const calls = [];
+  const fetchImpl = async (url, init) => {
+    calls.push({ url: url.toString(), key: init.headers.get("X-API-Key") });
+    return url.hostname === "schemas.example.nl"
+      ? new Response(null, { status: 302, headers: { Location: "https://cdn.example.com/adres.yaml" } })
+      : new Response("type: object");
+  };
+
+  const response = await withRefHeaders(fetchImpl, rules)("https://schemas.example.nl/adres.yaml");
+
+  assert.equal(await response.text(), "type: object");
+  assert.deepEqual(calls, [
+    { url: "https://schemas.example.nl/adres.yaml", key: "sleutel" },
+    { url: "https://cdn.example.com/adres.yaml", key: null },
+  ]);
+});
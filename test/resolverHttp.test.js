@@ -0,0 +1,87 @@
+const assert = require("node:assert/strict");
+const http = require("node:http");
+const { once } = require("node:events");
+const test = require("node:test");
+const { loadOutboundPolicy } = require("../utils/outboundPolicy");
+const {
+  connectThroughProxy,
+  loadResolverHttpOptions,
+  resolverHttpEnv,
+  withResolverHttp,
+} = require("../utils/resolverHttp");
+
+const options = { timeoutMs: 1000, retries: 2, backoffMs: 0, maxRedirects: 1 };
+
+test("withResolverHttp herhaalt een 503 en volgt redirects tot maxRedirects", async () => {
+  const calls = [];
+  const responses = [
+    new Response(null, { status: 503 }),
+    new Response(null, { status: 302, headers: { Location: "/v2/adres.yaml" } }),
+    new Response("type: object"),
+  ];
+  const fetchImpl = async (url, init) => {
+    calls.push({ url: url.href, redirect: init.redirect });
+    return responses.shift();
+  };
+
+  const response = await withResolverHttp(fetchImpl, options)("https://schemas.example.nl/adres.yaml");
+
+  assert.equal(await response.text(), "type: object");
+  assert.deepEqual(calls, [
+    { url: "https://schemas.example.nl/adres.yaml", redirect: "manual" },
+    { url: "https://schemas.example.nl/adres.yaml", redirect: "manual" },
+    { url: "https://schemas.example.nl/v2/adres.yaml", redirect: "manual" },
+  ]);
+
+  const loop = async () => new Response(null, { status: 301, headers: { Location: "https://schemas.example.nl/" } });
+  await assert.rejects(withResolverHttp(loop, options)("https://schemas.example.nl/"), /Meer dan 1 redirects/);
+});
+
+test("withResolverHttp toetst via een proxy elke URL en leest overrides uit de omgeving", async () => {
+  const fetchImpl = async (_url, init) => new Response(init.dispatcher);
+  const proxied = withResolverHttp(
+    fetchImpl,
+    { ...options, proxy: "http://proxy.example.nl:3128" },
+    { createDispatcher: (proxy) => `via ${proxy}` },
+  );
+
+  assert.equal(await (await proxied("https://93.184.215.14/adres.yaml")).text(), "via http://proxy.example.nl:3128");
+  await assert.rejects(proxied("http://127.0.0.1/adres.yaml"), { name: "OutboundPolicyError" });
+  assert.deepEqual(loadResolverHttpOptions({ ...resolverHttpEnv({ retries: 4 }), OAS_RESOLVE_HTTP_TIMEOUT_MS: "5" }), {
+    timeoutMs: 20000,
+    retries: 4,
+    backoffMs: 500,
+    maxRedirects: 5,
+    proxy: undefined,
+  });
+});
+
+test("connectThroughProxy tunnelt naar het getoetste adres, niet naar de hostnaam", async (t) => {
+  const targets = [];
+  const proxy = http.createServer();
+  proxy.on("connect", (request, socket) => {
+    targets.push(request.url);
+    socket.end("HTTP/1.1 200 Connection Established\r\n\r\n");
+  });
+  proxy.listen(0, "127.0.0.1");
+  await once(proxy, "listening");
+  t.after(() => proxy.close());
+
+  const addresses = { "schemas.example.nl": "93.184.215.14", "rebind.example.nl": "10.0.0.5" };
+  const lookup = (hostname, _options, callback) => callback(null, [{ address: addresses[hostname], family: 4 }]);
+  const connect = connectThroughProxy(`http://127.0.0.1:${proxy.address().port}`, {
+    policy: loadOutboundPolicy({}),
+    lookup,
+  });
+  const open = (hostname) =>
+    new Promise((resolve, reject) => {
+      connect({ hostname, protocol: "http:", port: "" }, (error, socket) => {
+        socket?.destroy();
+        return error ? reject(error) : resolve();
+      });
+    });
+
+  await open("schemas.example.nl");
+  await assert.rejects(open("rebind.example.nl"), { name: "OutboundPolicyError" });
+  assert.deepEqual(targets, ["93.184.215.14:80"]);
+});
@@ -0,0 +1,55 @@
+const assert = require("node:assert/strict");
+const { createHash } = require("node:crypto");
+const test = require("node:test");
+const {
+  ResolverLimitError,
+  assertDepth,
+  limitFetch,
+  loadResolverLimits,
+  readLimitError,
+} = require("../utils/resolverLimits");
+
+const isLimit = (limit) => (error) => error instanceof ResolverLimitError && error.limit === limit;
+
+test("limitFetch begrenst het aantal documenten en het totaal aantal bytes", async () => {
+  const fetchImpl = async () => new Response("x".repeat(10));
+  const documents = [];
+  const byDocuments = limitFetch(fetchImpl, loadResolverLimits({ OAS_RESOLVE_MAX_DOCUMENTS: "2" }), {
+    onDocument: (entry) => documents.push(entry),
+  });
+
+  assert.equal(await (await byDocuments("https://api.example.nl/a.yaml")).text(), "x".repeat(10));
+  await byDocuments("https://api.example.nl/b.yaml");
+  await assert.rejects(byDocuments("https://api.example.nl/c.yaml"), isLimit("maxDocuments"));
+  assert.deepEqual(documents[0], {
+    url: "https://api.example.nl/a.yaml",
+    status: 200,
+    bytes: 10,
+    sha256: createHash("sha256").update("x".repeat(10)).digest("hex"),
+  });
+  assert.equal(documents.length, 2);
+
+  const byBytes = limitFetch(fetchImpl, loadResolverLimits({ OAS_RESOLVE_MAX_BYTES: "15" }));
+  await byBytes("https://api.example.nl/a.yaml");
+  await assert.rejects(byBytes("https://api.example.nl/b.yaml"), isLimit("maxBytes"));
+});
+
+test("assertDepth weigert te diep geneste documenten en volgt een kring maar één keer", () => {
+  const limits = loadResolverLimits({ OAS_RESOLVE_MAX_DEPTH: "4" });
+  const node = { type: "object", properties: {} };
+  node.properties.children = node;
+
+  assert.doesNotThrow(() => assertDepth({ components: { schemas: { Node: node } } }, limits));
+  assert.throws(() => assertDepth({ a: { b: { c: { d: { e: {} } } } } }, limits), isLimit("maxDepth"));
+});
+
+test("readLimitError leest de limiet uit de stderr van het child process", () => {
+  const error = readLimitError({
+    code: 86,
+    stderr: 'waarschuwing\nresolver-limit:{"limit":"maxBytes","message":"Te veel bytes."}\n',
+  });
+
+  assert.ok(isLimit("maxBytes")(error));
+  assert.equal(error.message, "Te veel bytes.");
+  assert.equal(readLimitError({ code: 1, stderr: "" }), undefined);
+});
@@ -0,0 +1,71 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { dereferenceDocument } = require("../utils/dereference");
+const { shareIdenticalSchemas } = require("../utils/schemaSharing");
+
+const json = (schema) => ({ description: "OK", content: { "application/json": { schema } } });
+const problem = { $ref: "#/components/schemas/Problem" };
+const adres = () => ({
+  type: "object",
+  properties: { straat: { type: "string" }, huisnummer: { type: "integer" } },
+});
+
+test("shareIdenticalSchemas wijst gelijke schema's naar een bestaand of nieuw component", () => {
+  const document = dereferenceDocument({
+    openapi: "3.1.0",
+    paths: {
+      "/panden": {
+        get: {
+          responses: {
+            200: json({ type: "object", properties: { adres: adres(), bouwjaar: { type: "integer" } } }),
+            400: json(problem),
+            500: json(problem),
+          },
+        },
+      },
+      "/adressen": {
+        get: { responses: { 200: json({ type: "array", items: adres() }), 400: json(problem) } },
+      },
+    },
+    components: {
+      schemas: {
+        Problem: { type: "object", properties: { title: { type: "string" }, status: { type: "integer" } } },
+      },
+    },
+  });
+
+  const result = shareIdenticalSchemas(document);
+  const panden = result.document.paths["/panden"].get.responses;
+
+  assert.deepEqual(panden[400].content["application/json"].schema, problem);
+  assert.deepEqual(panden[500].content["application/json"].schema, problem);
+  assert.deepEqual(panden[200].content["application/json"].schema.properties.adres, {
+    $ref: "#/components/schemas/Adres",
+  });
+  assert.deepEqual(panden[200].content["application/json"].schema.properties.bouwjaar, { type: "integer" });
+  assert.deepEqual(result.document.components.schemas.Adres, adres());
+  assert.equal(result.document.components.schemas.Problem.properties.status.type, "integer");
+  assert.deepEqual(result.shared, [
+    { name: "Adres", references: 2 },
+    { name: "Problem", references: 3 },
+  ]);
+});
+
+test("shareIdenticalSchemas laat kleine en unieke schema's inline, ook in Swagger 2.0", () => {
+  const document = {
+    swagger: "2.0",
+    paths: {
+      "/a": { get: { responses: { 200: { description: "OK", schema: { type: "string", format: "date" } } } } },
+      "/b": { get: { responses: { 200: { description: "OK", schema: { type: "string", format: "date" } } } } },
+      "/c": { get: { responses: { 200: { description: "OK", schema: { ...adres(), title: "Adres" } } } } },
+      "/d": { get: { responses: { 200: { description: "OK", schema: { ...adres(), title: "Adres" } } } } },
+    },
+  };
+
+  const result = shareIdenticalSchemas(document);
+
+  assert.deepEqual(result.document.paths["/a"].get.responses[200].schema, { type: "string", format: "date" });
+  assert.deepEqual(result.document.paths["/d"].get.responses[200].schema, { $ref: "#/definitions/Adres" });
+  assert.equal(result.document.definitions.Adres.title, "Adres");
+  assert.deepEqual(shareIdenticalSchemas({ openapi: "3.0.3", paths: {} }).shared, []);
+});
@@ -0,0 +1,46 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { parseTaggedName, sourceTag, tagSchemaSources } = require("../utils/schemaSources");
+
+test("tagSchemaSources zet de bron in schemanamen en verwijzingen, maar niet in het hoofddocument", () => {
+  const url = "https://example.nl/schemas/gemeente.yaml";
+  const bag = "https://example.nl/schemas/bag.yaml";
+  const external = tagSchemaSources(
+    {
+      components: {
+        schemas: {
+          Adres: {
+            properties: {
+              land: { $ref: "#/components/schemas/Land" },
+              postcode: { $ref: "bag.yaml#/components/schemas/Postcode" },
+            },
+            example: { $ref: "#/components/schemas/Land" },
+          },
+          Land: { type: "string" },
+        },
+      },
+    },
+    url,
+  );
+
+  assert.deepEqual(Object.keys(external.components.schemas).map(parseTaggedName), [
+    { name: "Adres", tag: sourceTag(url) },
+    { name: "Land", tag: sourceTag(url) },
+  ]);
+  const [adres] = Object.values(external.components.schemas);
+  assert.equal(adres.properties.land.$ref, `#/components/schemas/Land__src_${sourceTag(url)}`);
+  assert.equal(adres.properties.postcode.$ref, `bag.yaml#/components/schemas/Postcode__src_${sourceTag(bag)}`);
+  assert.deepEqual(adres.example, { $ref: "#/components/schemas/Land" });
+
+  const root = tagSchemaSources(
+    {
+      components: {
+        schemas: { Land: { $ref: `${url}#/components/schemas/Land` }, Eigen: { $ref: "#/components/schemas/Land" } },
+      },
+    },
+    undefined,
+    { local: false },
+  );
+  assert.equal(root.components.schemas.Land.$ref, `${url}#/components/schemas/Land__src_${sourceTag(url)}`);
+  assert.equal(root.components.schemas.Eigen.$ref, "#/components/schemas/Land");
+});
@@ -0,0 +1,58 @@
+const assert = require("node:assert/strict");
+const fs = require("node:fs/promises");
+const os = require("node:os");
+const path = require("node:path");
+const test = require("node:test");
+const { WorkerTimeoutError, createWorkerPool } = require("../utils/workerPool");
+
+// Een worker die een getal verdubbelt, of met `spin` synchroon blijft rekenen en tussendoor niets meer doet.
+const workerSource = [
+  'const { parentPort } = require("node:worker_threads");',
+  "parentPort.on(\"message\", ({ value, spin }) => {",
+  "  parentPort.postMessage({ event: { threadStarted: true } });",
+  "  while (spin) {}",
+  "  if (value < 0) {",
+  '    parentPort.postMessage({ error: { message: "negatief" } });',
+  "  } else {",
+  "    parentPort.postMessage({ result: value * 2 });",
+  "  }",
+  "});",
+].join("\n");
+
+const workerFile = async (t) => {
+  const dir = await fs.mkdtemp(path.join(os.tmpdir(), "worker-pool-"));
+  const file = path.join(dir, "worker.js");
+  await fs.writeFile(file, workerSource);
+  t.after(() => fs.rm(dir, { recursive: true, force: true }));
+  return file;
+};
+
+test("createWorkerPool geeft het resultaat, de fout en de tussentijdse events van de worker door", async (t) => {
+  const pool = createWorkerPool(await workerFile(t), { maxIdle: 1 });
+  const events = [];
+
+  assert.equal(await pool.run({ value: 21 }, { timeoutMs: 5000, onEvent: (event) => events.push(event) }), 42);
+  await assert.rejects(pool.run({ value: -1 }, { timeoutMs: 5000 }), { message: "negatief" });
+  assert.deepEqual(events, [{ threadStarted: true }]);
+});
+
+test("createWorkerPool beëindigt een worker die synchroon blijft rekenen na timeoutMs", async (t) => {
+  const pool = createWorkerPool(await workerFile(t), { maxIdle: 1 });
+
+  const started = Date.now();
+  await assert.rejects(pool.run({ value: 1, spin: true }, { timeoutMs: 200 }), WorkerTimeoutError);
+  assert.ok(Date.now() - started < 5000);
+  // De vastgelopen worker gaat niet terug in de pool: een nieuwe taak krijgt een verse worker.
+  assert.equal(await pool.run({ value: 2 }, { timeoutMs: 5000 }), 4);
+});
+
+test("createWorkerPool beëindigt de worker als het signal afgaat", async (t) => {
+  const pool = createWorkerPool(await workerFile(t), { maxIdle: 1 });
+  const controller = new AbortController();
+
+  const run = pool.run({ value: 1, spin: true }, { timeoutMs: 5000, signal: controller.signal });
+  setTimeout(() => controller.abort(), 100);
+
+  await assert.rejects(run, { name: "AbortError" });
+  assert.equal(await pool.run({ value: 3 }, { timeoutMs: 5000 }), 6);
+});
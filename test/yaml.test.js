@@ -0,0 +1,60 @@
+const assert = require("node:assert/strict");
+const test = require("node:test");
+const { YamlLimitError, assertSafeYaml, dumpYaml, parseJsonOrYaml, parseYaml } = require("../utils/yaml");
+
+const billionLaughs = `
+a: &a ["lol", "lol", "lol", "lol", "lol", "lol", "lol", "lol", "lol"]
+b: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a]
+c: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b]
+d: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c]
+e: &e [*d, *d, *d, *d, *d, *d, *d, *d, *d]
+f: &f [*e, *e, *e, *e, *e, *e, *e, *e, *e]
+g: &g [*f, *f, *f, *f, *f, *f, *f, *f, *f]
+`;
+
+test("alias expansion beyond the node limit is rejected", () => {
+  assert.throws(() => parseYaml(billionLaughs), YamlLimitError);
+  assert.throws(() => parseYaml(billionLaughs, { maxNodes: 100 }), /100 nodes/);
+});
+
+test("recursive aliases are rejected", () => {
+  assert.throws(() => parseYaml("a: &a\n  self: *a\n"), YamlLimitError);
+});
+
+test("a chain of aliases that expands beyond the depth limit is rejected", () => {
+  const chain = ["- &a0 []", ...Array.from({ length: 20 }, (_, index) => `- &a${index + 1} [*a${index}]`)].join("\n");
+  assert.throws(() => parseYaml(chain, { maxDepth: 10 }), /dieper dan 10 niveaus/);
+  assert.equal(parseYaml(chain, { maxDepth: 25 }).length, 21);
+});
+
+test("YAML 1.2 core schema with merge keys is used", () => {
+  const document = parseYaml(`
+base: &base
+  type: string
+field:
+  <<: *base
+  description: merged
+released: 2024-01-01
+flag: yes
+`);
+  assert.deepEqual(document.field, { type: "string", description: "merged" });
+  assert.equal(document.released, "2024-01-01");
+  assert.equal(document.flag, "yes");
+});
+
+test("parseJsonOrYaml reports the detected format", () => {
+  assert.equal(parseJsonOrYaml('{"openapi": "3.1.0"}').format, "json");
+  assert.equal(parseJsonOrYaml("openapi: 3.1.0").format, "yaml");
+});
+
+test("assertSafeYaml returns the parsed document and ignores syntax errors", () => {
+  assert.deepEqual(assertSafeYaml("info:\n  x-adr-version: '2.0'"), { info: { "x-adr-version": "2.0" } });
+  assert.equal(assertSafeYaml("a: [unclosed"), undefined);
+});
+
+test("dumpYaml writes shared objects out instead of emitting anchors", () => {
+  const shared = { type: "string" };
+  const output = dumpYaml({ a: shared, b: shared });
+  assert.ok(!output.includes("&"));
+  assert.ok(!output.includes("*"));
+});
@@ -0,0 +1,37 @@
+const assert = require("node:assert/strict");
+const fs = require("node:fs/promises");
+const os = require("node:os");
+const path = require("node:path");
+const test = require("node:test");
+const { createZipStream, readZip } = require("../utils/zip");
+
+const collect = async (stream) => {
+  const chunks = [];
+  for await (const chunk of stream) {
+    chunks.push(chunk);
+  }
+  return Buffer.concat(chunks);
+};
+
+test("createZipStream schrijft entries uit geheugen en van schijf als leesbare ZIP", async () => {
+  const directory = await fs.mkdtemp(path.join(os.tmpdir(), "zip-test-"));
+  try {
+    const filePath = path.join(directory, "groot.json");
+    const large = JSON.stringify({ items: Array.from({ length: 20000 }, (_, index) => ({ index })) });
+    await fs.writeFile(filePath, large);
+
+    const zip = await collect(
+      createZipStream([
+        { name: "export/klein.txt", data: "hallo" },
+        { name: "export/groot.json", path: filePath, mode: 0o644 },
+      ]),
+    );
+    const files = readZip(zip);
+
+    assert.deepEqual(files.map((file) => file.name), ["export/klein.txt", "export/groot.json"]);
+    assert.equal(files[0].data.toString("utf8"), "hallo");
+    assert.equal(files[1].data.toString("utf8"), large);
+  } finally {
+    await fs.rm(directory, { recursive: true, force: true });
+  }
+});
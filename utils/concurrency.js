@@ -0,0 +1,29 @@
+/**
+ * Voert `worker` uit voor alle items met maximaal `limit` gelijktijdige aanroepen. De volgorde
+ * van de resultaten komt overeen met de volgorde van de input.
+ */
+const mapWithConcurrency = async (items, limit, worker) => {
+  const list = Array.isArray(items) ? items : [];
+  const results = new Array(list.length);
+  const poolSize = Math.max(1, Math.min(Number.isFinite(limit) ? Math.floor(limit) : 1, list.length));
+  let nextIndex = 0;
+
+  const runWorker = async () => {
+    while (nextIndex < list.length) {
+      const index = nextIndex;
+      nextIndex += 1;
+      results[index] = await worker(list[index], index);
+    }
+  };
+
+  const workers = [];
+  for (let i = 0; i < poolSize; i += 1) {
+    workers.push(runWorker());
+  }
+  await Promise.all(workers);
+  return results;
+};
+
+module.exports = {
+  mapWithConcurrency,
+};
@@ -0,0 +1,102 @@
+const { encodePointerSegment, lookupPointer } = require("./jsonPointer");
+
+const LOCAL_REF_PREFIX = "#/";
+// Waarden onder deze sleutels zijn data; een property die `$ref` heet is daar geen verwijzing. Een
+// `examples`-lijst (JSON Schema) is data, een `examples`-map (OpenAPI) bevat Example Objects en refs.
+const DATA_KEYS = new Set(["example", "default", "const", "enum"]);
+const ON_CYCLE = ["ref", "error"];
+
+class CircularReferenceError extends Error {
+  constructor(cycle) {
+    super(`Kringverwijzing: ${cycle.join(" → ")}.`);
+    this.name = "CircularReferenceError";
+    this.cycle = cycle;
+  }
+}
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+/**
+ * Schrijft elke lokale `$ref` (`#/...`) in het document uit. Een verwijzing naar een object dat al wordt
+ * uitgeschreven (ook een schema in `components` dat naar zichzelf verwijst) is een kring: met
+ * `onCycle: "ref"` blijft daar de `$ref` staan (het doel blijft in het document, dus de verwijzing klopt),
+ * met `onCycle: "error"` volgt een CircularReferenceError met de keten. Externe en onvindbare verwijzingen
+ * blijven ongewijzigd. Andere sleutels naast een `$ref` (zoals `description` in OpenAPI 3.1) gaan voor
+ * die van het doel.
+ *
+ * Een uitgeschreven object zonder kring wordt hergebruikt; het resultaat kan dus gedeelde objecten bevatten.
+ */
+const dereferenceDocument = (document, { onCycle = "ref" } = {}) => {
+  const memo = new Map();
+  // Per object op de stapel: de plek (pointer of ref waarlangs het bereikt is) en bij een `$ref` de ref.
+  const stack = [];
+  const visitEntries = (node, at) => {
+    let reach = Number.POSITIVE_INFINITY;
+    const entries = Object.entries(node).map(([key, value]) => {
+      if (DATA_KEYS.has(key) || key.startsWith("x-") || (key === "examples" && Array.isArray(value))) {
+        return [key, value];
+      }
+      const result = visit(value, `${at}/${encodePointerSegment(key)}`);
+      reach = Math.min(reach, result.reach);
+      return [key, result.value];
+    });
+    return { value: Object.fromEntries(entries), reach };
+  };
+  // `reach` is de laagste plek op de stapel waar een kring naar terugwees; alleen een resultaat dat
+  // niet van de stapel afhangt, mag in de memo.
+  const expand = (node, at) => {
+    if (Array.isArray(node)) {
+      let reach = Number.POSITIVE_INFINITY;
+      const items = node.map((item, index) => {
+        const result = visit(item, `${at}/${index}`);
+        reach = Math.min(reach, result.reach);
+        return result.value;
+      });
+      return { value: items, reach };
+    }
+    const ref = typeof node.$ref === "string" && node.$ref.startsWith(LOCAL_REF_PREFIX) ? node.$ref : undefined;
+    const target = ref === undefined ? undefined : lookupPointer(document, ref);
+    if (target === undefined) {
+      return visitEntries(node, at);
+    }
+    const onStack = stack.findIndex((entry) => entry.node === target);
+    if (onStack !== -1) {
+      if (onCycle === "error") {
+        const refs = stack.slice(onStack + 1).flatMap((entry) => (entry.ref ? [entry.ref] : []));
+        throw new CircularReferenceError([stack[onStack].at, ...refs]);
+      }
+      return { value: { ...node }, reach: onStack };
+    }
+    const resolved = visit(target, ref);
+    const siblings = Object.fromEntries(Object.entries(node).filter(([key]) => key !== "$ref"));
+    if (Object.keys(siblings).length === 0 || !isObject(resolved.value)) {
+      return resolved;
+    }
+    const extra = visitEntries(siblings, at);
+    return { value: { ...resolved.value, ...extra.value }, reach: Math.min(resolved.reach, extra.reach) };
+  };
+  const visit = (node, at) => {
+    if (node === null || typeof node !== "object") {
+      return { value: node, reach: Number.POSITIVE_INFINITY };
+    }
+    if (memo.has(node)) {
+      return { value: memo.get(node), reach: Number.POSITIVE_INFINITY };
+    }
+    const depth = stack.length;
+    stack.push({ node, at, ref: typeof node.$ref === "string" ? node.$ref : undefined });
+    const result = expand(node, at);
+    stack.pop();
+    if (result.reach >= depth) {
+      memo.set(node, result.value);
+      return { value: result.value, reach: Number.POSITIVE_INFINITY };
+    }
+    return result;
+  };
+  return visit(document, "#").value;
+};
+
+module.exports = {
+  CircularReferenceError,
+  ON_CYCLE,
+  dereferenceDocument,
+};
@@ -0,0 +1,95 @@
+const UTF8_BOM = [0xef, 0xbb, 0xbf];
+const UTF16LE_BOM = [0xff, 0xfe];
+const UTF16BE_BOM = [0xfe, 0xff];
+const SNIFF_BYTES = 64;
+
+const startsWith = (bytes, prefix) => prefix.every((value, index) => bytes[index] === value);
+
+const swapBytes = (bytes) => {
+  const swapped = Buffer.alloc(bytes.length - (bytes.length % 2));
+  for (let index = 0; index + 1 < bytes.length; index += 2) {
+    swapped[index] = bytes[index + 1];
+    swapped[index + 1] = bytes[index];
+  }
+  return swapped;
+};
+
+const parseCharset = (contentType) => {
+  const match = /charset\s*=\s*"?([^";\s]+)"?/i.exec(contentType || "");
+  return match ? match[1].toLowerCase() : undefined;
+};
+
+/**
+ * Zonder BOM verraadt UTF-16 zich doordat ASCII-tekens (en een specificatie begint altijd met ASCII)
+ * om en om een nulbyte hebben: even posities bij big endian, oneven bij little endian.
+ */
+const sniffUtf16 = (bytes) => {
+  const sample = bytes.subarray(0, Math.min(bytes.length, SNIFF_BYTES) & ~1);
+  if (sample.length < 2) {
+    return undefined;
+  }
+  let evenZeros = 0;
+  let oddZeros = 0;
+  for (let index = 0; index < sample.length; index += 2) {
+    evenZeros += sample[index] === 0 ? 1 : 0;
+    oddZeros += sample[index + 1] === 0 ? 1 : 0;
+  }
+  const pairs = sample.length / 2;
+  if (oddZeros >= pairs * 0.75 && evenZeros === 0) {
+    return "utf-16le";
+  }
+  if (evenZeros >= pairs * 0.75 && oddZeros === 0) {
+    return "utf-16be";
+  }
+  return undefined;
+};
+
+const detectEncoding = (bytes, contentType) => {
+  if (startsWith(bytes, UTF8_BOM)) {
+    return { encoding: "utf-8", offset: UTF8_BOM.length };
+  }
+  if (startsWith(bytes, UTF16LE_BOM)) {
+    return { encoding: "utf-16le", offset: UTF16LE_BOM.length };
+  }
+  if (startsWith(bytes, UTF16BE_BOM)) {
+    return { encoding: "utf-16be", offset: UTF16BE_BOM.length };
+  }
+  const sniffed = sniffUtf16(bytes);
+  if (sniffed) {
+    return { encoding: sniffed, offset: 0 };
+  }
+  const charset = parseCharset(contentType);
+  if (charset === "utf-16") {
+    return { encoding: "utf-16le", offset: 0 };
+  }
+  return { encoding: charset || "utf-8", offset: 0 };
+};
+
+const stripBom = (text) => (typeof text === "string" && text.charCodeAt(0) === 0xfeff ? text.slice(1) : text);
+
+/**
+ * Zet de bytes van een specificatie om naar tekst. Een BOM heeft voorrang, daarna UTF-16 zonder BOM
+ * en dan de charset uit de Content-Type header; anders UTF-8. Zo worden bestanden uit Windows-tooling
+ * (UTF-8 met BOM of UTF-16) net zo verwerkt als gewone UTF-8.
+ */
+const decodeSpecification = (input, { contentType } = {}) => {
+  const bytes = Buffer.isBuffer(input) ? input : Buffer.from(input);
+  const { encoding, offset } = detectEncoding(bytes, contentType);
+  const body = bytes.subarray(offset);
+  if (encoding === "utf-16be") {
+    return stripBom(swapBytes(body).toString("utf16le"));
+  }
+  if (encoding === "utf-16le") {
+    return stripBom(body.toString("utf16le"));
+  }
+  try {
+    return stripBom(new TextDecoder(encoding).decode(body));
+  } catch {
+    return stripBom(new TextDecoder("utf-8").decode(body));
+  }
+};
+
+module.exports = {
+  decodeSpecification,
+  stripBom,
+};
@@ -0,0 +1,55 @@
+const crypto = require("node:crypto");
+
+const CIPHER_ALGORITHM = "aes-256-gcm";
+const KEY_LENGTH = 32;
+const IV_LENGTH = 12;
+const TAG_LENGTH = 16;
+const FILE_MAGIC = Buffer.from("DONA1", "utf8");
+
+/**
+ * Leest een sleutel van 32 bytes uit base64 of hex. Andere lengtes worden geweigerd zodat een
+ * verkeerd geconfigureerde sleutel niet stilzwijgend tot zwakkere versleuteling leidt.
+ */
+const parseEncryptionKey = (value) => {
+  const trimmed = typeof value === "string" ? value.trim() : "";
+  if (!trimmed) {
+    return null;
+  }
+  const candidates = [];
+  if (/^[0-9a-fA-F]+$/.test(trimmed)) {
+    candidates.push(Buffer.from(trimmed, "hex"));
+  }
+  candidates.push(Buffer.from(trimmed, "base64"));
+  const key = candidates.find((candidate) => candidate.length === KEY_LENGTH);
+  if (!key) {
+    throw new Error(`ARTIFACT_ENCRYPTION_KEY moet ${KEY_LENGTH} bytes bevatten (base64 of hex).`);
+  }
+  return key;
+};
+
+// Versleutelt met AES-256-GCM; het resultaat begint met een vaste markering, de IV en de auth tag.
+const encrypt = (key, plaintext) => {
+  const iv = crypto.randomBytes(IV_LENGTH);
+  const cipher = crypto.createCipheriv(CIPHER_ALGORITHM, key, iv, { authTagLength: TAG_LENGTH });
+  const ciphertext = Buffer.concat([cipher.update(plaintext), cipher.final()]);
+  return Buffer.concat([FILE_MAGIC, iv, cipher.getAuthTag(), ciphertext]);
+};
+
+const decrypt = (key, payload) => {
+  const headerLength = FILE_MAGIC.length + IV_LENGTH + TAG_LENGTH;
+  if (payload.length < headerLength || !payload.subarray(0, FILE_MAGIC.length).equals(FILE_MAGIC)) {
+    throw new Error("Artifact heeft een onbekend formaat.");
+  }
+  const iv = payload.subarray(FILE_MAGIC.length, FILE_MAGIC.length + IV_LENGTH);
+  const tag = payload.subarray(FILE_MAGIC.length + IV_LENGTH, headerLength);
+  const decipher = crypto.createDecipheriv(CIPHER_ALGORITHM, key, iv, { authTagLength: TAG_LENGTH });
+  decipher.setAuthTag(tag);
+  return Buffer.concat([decipher.update(payload.subarray(headerLength)), decipher.final()]);
+};
+
+module.exports = {
+  KEY_LENGTH,
+  decrypt,
+  encrypt,
+  parseEncryptionKey,
+};
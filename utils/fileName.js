@@ -53,6 +53,20 @@ const sanitizeFileName = (value, options = {}) => {
   return "";
 };
 
+/**
+ * Geeft `base` terug, of `base-2`, `base-3`, ... als die naam (hoofdletterongevoelig) al in `used`
+ * staat. De gekozen naam wordt aan `used` toegevoegd.
+ */
+const uniqueFileName = (used, base) => {
+  let candidate = base;
+  for (let counter = 2; used.has(candidate.toLowerCase()); counter += 1) {
+    candidate = `${base}-${counter}`;
+  }
+  used.add(candidate.toLowerCase());
+  return candidate;
+};
+
 module.exports = {
   sanitizeFileName,
+  uniqueFileName,
 };
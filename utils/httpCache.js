@@ -0,0 +1,218 @@
+const crypto = require("node:crypto");
+const fs = require("node:fs/promises");
+const path = require("node:path");
+const { Readable } = require("node:stream");
+const { ARTIFACT_DIR } = require("../config");
+const { decrypt, encrypt, parseEncryptionKey } = require("./encryption");
+
+const DEFAULT_MAX_ENTRIES = 500;
+const DEFAULT_MAX_ENTRY_BYTES = 10 * 1024 * 1024;
+// Dezelfde bewaartermijn als de artifact-opslag (`ARTIFACT_RETENTION_DAYS`).
+const DEFAULT_RETENTION_DAYS = 30;
+const DAY_MS = 24 * 60 * 60 * 1000;
+const ENTRY_EXTENSION = ".bin";
+// Headers die een antwoord niet persoonlijk maken. Een verzoek met andere headers (Authorization,
+// X-API-Key, cookies) gaat altijd langs de cache, zoals een gedeelde cache hoort te doen (RFC 9111 §3.5).
+const NEUTRAL_HEADERS = new Set(["accept", "accept-encoding", "accept-language", "origin", "user-agent"]);
+
+const positiveInteger = (value, fallback) => {
+  const parsed = Number(value);
+  return Number.isInteger(parsed) && parsed > 0 ? parsed : fallback;
+};
+
+const positiveNumber = (value, fallback) => {
+  const parsed = Number(value);
+  return Number.isFinite(parsed) && parsed > 0 ? parsed : fallback;
+};
+
+/**
+ * Instellingen van de cache voor opgehaalde specificaties en externe `$ref`s. De cache staat op schijf,
+ * zodat ook de child processes die verwijzingen ophalen (Redocly) hem delen. Entries worden net als
+ * artifacts versleuteld met `ARTIFACT_ENCRYPTION_KEY` en vallen onder dezelfde bewaartermijn. Zonder
+ * sleutel staat de cache uit: een tijdelijke sleutel per proces is niet te delen met de child processes.
+ */
+const loadHttpCacheConfig = (env = process.env) => {
+  const key = parseEncryptionKey(env.ARTIFACT_ENCRYPTION_KEY);
+  return {
+    enabled: key !== null && !["0", "false", "off", "no"].includes(String(env.OAS_FETCH_CACHE ?? "").toLowerCase()),
+    key,
+    directory: env.OAS_FETCH_CACHE_DIR || path.join(env.ARTIFACT_DIR || ARTIFACT_DIR, "fetch-cache"),
+    maxAgeMs: positiveNumber(env.ARTIFACT_RETENTION_DAYS, DEFAULT_RETENTION_DAYS) * DAY_MS,
+    maxEntries: positiveInteger(env.OAS_FETCH_CACHE_MAX_ENTRIES, DEFAULT_MAX_ENTRIES),
+    maxEntryBytes: positiveInteger(env.OAS_FETCH_CACHE_MAX_BYTES, DEFAULT_MAX_ENTRY_BYTES),
+  };
+};
+
+const parseCacheControl = (value) => {
+  const directives = new Map();
+  for (const part of String(value ?? "").split(",")) {
+    const [name, argument] = part.trim().toLowerCase().split("=");
+    if (name) {
+      directives.set(name, argument?.replace(/^"|"$/g, ""));
+    }
+  }
+  return directives;
+};
+
+const isNeutralRequest = (init) => [...new Headers(init?.headers).keys()].every((name) => NEUTRAL_HEADERS.has(name));
+
+const entryPath = (config, url) =>
+  path.join(config.directory, `${crypto.createHash("sha256").update(url).digest("hex")}${ENTRY_EXTENSION}`);
+
+const isExpired = (config, mtimeMs) => Date.now() - mtimeMs > config.maxAgeMs;
+
+// Een entry die verlopen is, niet te ontsleutelen is (andere sleutel) of bij een andere URL hoort, telt niet.
+const readEntry = async (config, url) => {
+  const file = entryPath(config, url);
+  try {
+    if (isExpired(config, (await fs.stat(file)).mtimeMs)) {
+      await fs.rm(file, { force: true });
+      return undefined;
+    }
+    const entry = JSON.parse(decrypt(config.key, await fs.readFile(file)).toString("utf8"));
+    return entry.url === url ? entry : undefined;
+  } catch {
+    return undefined;
+  }
+};
+
+// Verwijdert verlopen entries en houdt de cache onder `maxEntries` door de oudste bestanden te verwijderen.
+const prune = async (config) => {
+  const names = (await fs.readdir(config.directory)).filter((name) => name.endsWith(ENTRY_EXTENSION));
+  const files = await Promise.all(
+    names.map(async (name) => {
+      const file = path.join(config.directory, name);
+      const stats = await fs.stat(file).catch(() => undefined);
+      return { file, mtimeMs: stats?.mtimeMs ?? 0 };
+    }),
+  );
+  files.sort((a, b) => a.mtimeMs - b.mtimeMs);
+  const kept = files.filter(({ mtimeMs }) => !isExpired(config, mtimeMs));
+  const removed = [
+    ...files.filter(({ mtimeMs }) => isExpired(config, mtimeMs)),
+    ...kept.slice(0, Math.max(0, kept.length - config.maxEntries)),
+  ];
+  await Promise.all(removed.map(({ file }) => fs.rm(file, { force: true })));
+};
+
+// Schrijft via een tijdelijk bestand, zodat een gelijktijdige lezer nooit een half entry ziet. De map is
+// alleen voor de eigenaar; `chmod` ook voor een map die al met ruimere rechten bestond.
+const writeEntry = async (config, entry) => {
+  await fs.mkdir(config.directory, { recursive: true, mode: 0o700 });
+  await fs.chmod(config.directory, 0o700);
+  const file = entryPath(config, entry.url);
+  const temporary = `${file}.${process.pid}.${crypto.randomUUID()}.tmp`;
+  await fs.writeFile(temporary, encrypt(config.key, JSON.stringify(entry)), { mode: 0o600 });
+  await fs.rename(temporary, file);
+  await prune(config);
+};
+
+const isFresh = (entry) => !entry.noCache && Date.now() - entry.storedAt < entry.maxAge * 1000;
+
+const toResponse = (entry) =>
+  new Response(Buffer.from(entry.body, "base64"), {
+    status: 200,
+    headers: entry.contentType ? { "Content-Type": entry.contentType } : {},
+  });
+
+/**
+ * Leest een body tot `maxBytes`. Past die erin, dan volgt `{ body }`; anders `{ stream }` met de al
+ * gelezen chunks en de rest van de body, zodat een groot document niet voor de cache in het geheugen komt.
+ */
+const readUpTo = async (body, maxBytes) => {
+  const chunks = [];
+  let size = 0;
+  if (!body) {
+    return { body: Buffer.alloc(0) };
+  }
+  const iterator = body[Symbol.asyncIterator]();
+  for (let next = await iterator.next(); !next.done; next = await iterator.next()) {
+    chunks.push(Buffer.from(next.value));
+    size += next.value.length;
+    if (size > maxBytes) {
+      const rest = async function* rest() {
+        yield* chunks;
+        for (let item = await iterator.next(); !item.done; item = await iterator.next()) {
+          yield item.value;
+        }
+      };
+      return { stream: Readable.toWeb(Readable.from(rest())) };
+    }
+  }
+  return { body: Buffer.concat(chunks) };
+};
+
+const freshness = (response) => {
+  const directives = parseCacheControl(response.headers.get("cache-control"));
+  return {
+    storable: !directives.has("no-store") && !directives.has("private"),
+    noCache: directives.has("no-cache"),
+    maxAge: positiveInteger(directives.get("max-age"), 0),
+  };
+};
+
+/**
+ * Omhult een `fetch` met een HTTP-cache voor GET-verzoeken zonder persoonlijke headers. Een antwoord met
+ * ETag of Last-Modified wordt bewaard; een volgende keer gaat het verzoek met If-None-Match en
+ * If-Modified-Since en levert een 304 de bewaarde inhoud. Binnen `max-age` (en zonder `no-cache`) wordt
+ * er niet opnieuw gevraagd. `no-store` en `private` worden niet bewaard.
+ */
+const withHttpCache = (fetchImpl, config = loadHttpCacheConfig()) => async (resource, init = {}) => {
+  const url = String(resource?.url ?? resource);
+  if (!config.enabled || (init.method ?? "GET").toUpperCase() !== "GET" || !isNeutralRequest(init)) {
+    return fetchImpl(resource, init);
+  }
+  const entry = await readEntry(config, url);
+  if (entry && isFresh(entry)) {
+    return toResponse(entry);
+  }
+  const headers = new Headers(init.headers);
+  if (entry?.etag) {
+    headers.set("If-None-Match", entry.etag);
+  }
+  if (entry?.lastModified) {
+    headers.set("If-Modified-Since", entry.lastModified);
+  }
+  const response = await fetchImpl(resource, { ...init, headers: Object.fromEntries(headers) });
+  if (response.status === 304 && entry) {
+    const { noCache, maxAge } = freshness(response);
+    const revalidated = { ...entry, storedAt: Date.now(), noCache: noCache || entry.noCache, maxAge };
+    await writeEntry(config, revalidated).catch(() => {});
+    return toResponse(revalidated);
+  }
+  const etag = response.headers.get("etag");
+  const lastModified = response.headers.get("last-modified");
+  const { storable, noCache, maxAge } = freshness(response);
+  if (response.status !== 200 || !storable || (!etag && !lastModified && maxAge === 0)) {
+    return response;
+  }
+  const { body, stream } = await readUpTo(response.body, config.maxEntryBytes);
+  if (body) {
+    const stored = {
+      url,
+      etag,
+      lastModified,
+      contentType: response.headers.get("content-type"),
+      storedAt: Date.now(),
+      noCache,
+      maxAge,
+      body: body.toString("base64"),
+    };
+    // Een cache die niet beschreven kan worden, mag het ophalen zelf niet laten mislukken.
+    await writeEntry(config, stored).catch(() => {});
+  }
+  return new Response(body ?? stream, {
+    status: response.status,
+    statusText: response.statusText,
+    headers: response.headers,
+  });
+};
+
+// Node-argumenten voor een child process dat externe `$ref`s via deze cache ophaalt.
+const HTTP_CACHE_ARGS = ["--require", path.join(__dirname, "httpCachePreload.js")];
+
+module.exports = {
+  HTTP_CACHE_ARGS,
+  loadHttpCacheConfig,
+  withHttpCache,
+};
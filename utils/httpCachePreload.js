@@ -0,0 +1,7 @@
+/**
+ * Preload (`node --require`) voor de Redocly CLI bij bundelen en dereferencen: externe `$ref`s gaan via
+ * de HTTP-cache van `httpCache.js`, met If-None-Match en If-Modified-Since voor bekende documenten.
+ */
+const { withHttpCache } = require("./httpCache");
+
+globalThis.fetch = withHttpCache(globalThis.fetch);
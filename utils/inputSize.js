@@ -0,0 +1,82 @@
+const fs = require("node:fs");
+const path = require("node:path");
+const { Transform } = require("node:stream");
+const { pipeline } = require("node:stream/promises");
+const { createTempFiles } = require("./tempFiles");
+
+const DEFAULT_MAX_INPUT_BYTES = 14 * 1024 * 1024;
+
+class InputTooLargeError extends Error {
+  constructor(maxBytes) {
+    super(`De specificatie is groter dan ${maxBytes} bytes (OAS_MAX_INPUT_BYTES).`);
+    this.name = "InputTooLargeError";
+    this.maxBytes = maxBytes;
+  }
+}
+
+/**
+ * De maximale grootte van een specificatie als input, in bytes: een `oasBody`, een document achter een
+ * `oasUrl` of het samengevoegde resultaat van een `oasArchive`. Ook de JSON-body van een request is
+ * hieraan gebonden (zie `maxRequestBytes`).
+ */
+const loadMaxInputBytes = (env = process.env) => {
+  const parsed = Number(env.OAS_MAX_INPUT_BYTES);
+  return Number.isInteger(parsed) && parsed > 0 ? parsed : DEFAULT_MAX_INPUT_BYTES;
+};
+
+// Een `oasArchive` zit base64-gecodeerd (4/3 zo groot) in de JSON-body; de rest van de body is klein.
+const maxRequestBytes = (maxInputBytes = loadMaxInputBytes()) => Math.ceil((maxInputBytes * 4) / 3) + 1024 * 1024;
+
+const assertInputSize = (bytes, maxBytes = loadMaxInputBytes()) => {
+  if (bytes > maxBytes) {
+    throw new InputTooLargeError(maxBytes);
+  }
+};
+
+/**
+ * Schrijft de body van een fetch-response binnen `maxBytes` naar een tijdelijk bestand en geeft
+ * `{ path, size, remove }` terug. De chunks gaan direct naar schijf, zodat een grote specificatie niet
+ * als losse stukken in het geheugen staat. Zodra de limiet is overschreden stopt de download met een
+ * InputTooLargeError en is het bestand weer weg; een te grote `Content-Length` wordt niet eens gelezen.
+ */
+const spoolLimitedBody = async (response, maxBytes = loadMaxInputBytes()) => {
+  assertInputSize(Number(response.headers.get("content-length")) || 0, maxBytes);
+  const tempFiles = await createTempFiles("oas-download-");
+  const filePath = path.join(tempFiles.directory, "body");
+  let size = 0;
+  const counter = new Transform({
+    transform(chunk, _encoding, callback) {
+      size += chunk.length;
+      callback(size > maxBytes ? new InputTooLargeError(maxBytes) : null, chunk);
+    },
+  });
+  try {
+    await pipeline(response.body ?? [], counter, fs.createWriteStream(filePath, { mode: 0o600 }));
+  } catch (error) {
+    await tempFiles.remove();
+    throw error;
+  }
+  return { path: filePath, size, remove: tempFiles.remove };
+};
+
+/**
+ * Leest de body van een fetch-response binnen `maxBytes` via `spoolLimitedBody`: het resultaat wordt
+ * in één keer van schijf gelezen, in plaats van eerst als chunks en dan nog eens samengevoegd.
+ */
+const readLimitedBody = async (response, maxBytes = loadMaxInputBytes()) => {
+  const spooled = await spoolLimitedBody(response, maxBytes);
+  try {
+    return await fs.promises.readFile(spooled.path);
+  } finally {
+    await spooled.remove();
+  }
+};
+
+module.exports = {
+  InputTooLargeError,
+  assertInputSize,
+  loadMaxInputBytes,
+  maxRequestBytes,
+  readLimitedBody,
+  spoolLimitedBody,
+};
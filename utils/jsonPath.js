@@ -0,0 +1,360 @@
+const NAME_PATTERN = /^[^\s.[\]()=!<>&|,'"]+/;
+const NUMBER_PATTERN = /^-?\d+(\.\d+)?([eE][+-]?\d+)?/;
+const INTEGER_PATTERN = /^-?\d+/;
+const COMPARISON_PATTERN = /^(==|!=|<=|>=|<|>)/;
+
+class JsonPathError extends Error {}
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+/**
+ * Parser voor JSONPath (RFC 9535) zoals Overlay-documenten die gebruiken: namen (`.naam`,
+ * `['naam']`), wildcards, indexen, slices, recursive descent (`..`) en filters met vergelijkingen,
+ * `&&`, `||`, `!` en bestaanstests (`[?@.x-internal == true]`, `[?(@.deprecated)]`). Functies als
+ * `length()` en `match()` worden niet ondersteund.
+ */
+class Parser {
+  constructor(text) {
+    this.text = text;
+    this.position = 0;
+  }
+
+  rest() {
+    return this.text.slice(this.position);
+  }
+
+  peek(token) {
+    return this.rest().startsWith(token);
+  }
+
+  skipWhitespace() {
+    while (/\s/.test(this.text[this.position] ?? "")) {
+      this.position += 1;
+    }
+  }
+
+  fail(message) {
+    throw new JsonPathError(`${message} (positie ${this.position + 1} in ${this.text})`);
+  }
+
+  expect(token) {
+    this.skipWhitespace();
+    if (!this.peek(token)) {
+      this.fail(`'${token}' verwacht`);
+    }
+    this.position += token.length;
+  }
+
+  match(pattern) {
+    const found = pattern.exec(this.rest());
+    if (found) {
+      this.position += found[0].length;
+    }
+    return found?.[0];
+  }
+
+  parseQuery() {
+    this.skipWhitespace();
+    this.expect("$");
+    const segments = this.parseSegments();
+    this.skipWhitespace();
+    if (this.position < this.text.length) {
+      this.fail("Onverwacht teken");
+    }
+    return segments;
+  }
+
+  parseSegments() {
+    const segments = [];
+    while (this.peek(".") || this.peek("[")) {
+      const descendant = this.peek("..");
+      const dotted = !descendant && this.peek(".");
+      this.position += descendant ? 2 : dotted ? 1 : 0;
+      if (this.peek("[") && !dotted) {
+        segments.push({ descendant, selectors: this.parseBracket() });
+      } else if (this.peek("*")) {
+        this.position += 1;
+        segments.push({ descendant, selectors: [{ type: "wildcard" }] });
+      } else {
+        const name = this.match(NAME_PATTERN);
+        if (!name) {
+          this.fail("Naam verwacht");
+        }
+        segments.push({ descendant, selectors: [{ type: "name", name }] });
+      }
+    }
+    return segments;
+  }
+
+  parseString() {
+    const quote = this.text[this.position];
+    let value = "";
+    this.position += 1;
+    while (this.position < this.text.length && this.text[this.position] !== quote) {
+      if (this.text[this.position] === "\\") {
+        this.position += 1;
+      }
+      value += this.text[this.position];
+      this.position += 1;
+    }
+    if (this.position >= this.text.length) {
+      this.fail("Niet afgesloten string");
+    }
+    this.position += 1;
+    return value;
+  }
+
+  parseBracket() {
+    this.expect("[");
+    const selectors = [];
+    for (;;) {
+      this.skipWhitespace();
+      selectors.push(this.parseSelector());
+      this.skipWhitespace();
+      if (this.peek(",")) {
+        this.position += 1;
+      } else {
+        this.expect("]");
+        return selectors;
+      }
+    }
+  }
+
+  parseSelector() {
+    if (this.peek("?")) {
+      this.position += 1;
+      return { type: "filter", expression: this.parseOr() };
+    }
+    if (this.peek("'") || this.peek('"')) {
+      return { type: "name", name: this.parseString() };
+    }
+    if (this.peek("*")) {
+      this.position += 1;
+      return { type: "wildcard" };
+    }
+    const start = this.match(INTEGER_PATTERN);
+    this.skipWhitespace();
+    if (!this.peek(":")) {
+      if (start === undefined) {
+        this.fail("Selector verwacht");
+      }
+      return { type: "index", index: Number(start) };
+    }
+    this.position += 1;
+    this.skipWhitespace();
+    const end = this.match(INTEGER_PATTERN);
+    this.skipWhitespace();
+    let step;
+    if (this.peek(":")) {
+      this.position += 1;
+      this.skipWhitespace();
+      step = this.match(INTEGER_PATTERN);
+    }
+    return {
+      type: "slice",
+      start: start === undefined ? undefined : Number(start),
+      end: end === undefined ? undefined : Number(end),
+      step: step === undefined ? 1 : Number(step),
+    };
+  }
+
+  parseOr() {
+    let left = this.parseAnd();
+    this.skipWhitespace();
+    while (this.peek("||")) {
+      this.position += 2;
+      left = { type: "or", left, right: this.parseAnd() };
+      this.skipWhitespace();
+    }
+    return left;
+  }
+
+  parseAnd() {
+    let left = this.parseUnary();
+    this.skipWhitespace();
+    while (this.peek("&&")) {
+      this.position += 2;
+      left = { type: "and", left, right: this.parseUnary() };
+      this.skipWhitespace();
+    }
+    return left;
+  }
+
+  parseUnary() {
+    this.skipWhitespace();
+    if (this.peek("!") && !this.peek("!=")) {
+      this.position += 1;
+      return { type: "not", expression: this.parseUnary() };
+    }
+    if (this.peek("(")) {
+      this.position += 1;
+      const expression = this.parseOr();
+      this.expect(")");
+      return expression;
+    }
+    const left = this.parseOperand();
+    this.skipWhitespace();
+    const operator = this.match(COMPARISON_PATTERN);
+    if (operator) {
+      return { type: "compare", operator, left, right: this.parseOperand() };
+    }
+    if (left.type !== "path") {
+      this.fail("Vergelijking verwacht");
+    }
+    return { type: "exists", path: left };
+  }
+
+  parseOperand() {
+    this.skipWhitespace();
+    if (this.peek("@") || this.peek("$")) {
+      const relative = this.peek("@");
+      this.position += 1;
+      return { type: "path", relative, segments: this.parseSegments() };
+    }
+    if (this.peek("'") || this.peek('"')) {
+      return { type: "literal", value: this.parseString() };
+    }
+    for (const [token, value] of [
+      ["true", true],
+      ["false", false],
+      ["null", null],
+    ]) {
+      if (this.peek(token)) {
+        this.position += token.length;
+        return { type: "literal", value };
+      }
+    }
+    const number = this.match(NUMBER_PATTERN);
+    if (number === undefined) {
+      this.fail("Waarde verwacht");
+    }
+    return { type: "literal", value: Number(number) };
+  }
+}
+
+const childNodes = (node) => {
+  if (Array.isArray(node.value)) {
+    return node.value.map((value, key) => ({ value, parent: node.value, key }));
+  }
+  if (isObject(node.value)) {
+    return Object.entries(node.value).map(([key, value]) => ({ value, parent: node.value, key }));
+  }
+  return [];
+};
+
+const descendantNodes = (node) => [node, ...childNodes(node).flatMap(descendantNodes)];
+
+const sliceIndexes = (length, { start, end, step }) => {
+  const indexes = [];
+  const normalize = (value) => (value < 0 ? length + value : value);
+  if (step > 0) {
+    const from = Math.max(normalize(start ?? 0), 0);
+    const to = Math.min(normalize(end ?? length), length);
+    for (let index = from; index < to; index += step) {
+      indexes.push(index);
+    }
+  } else if (step < 0) {
+    const from = Math.min(normalize(start ?? length - 1), length - 1);
+    const to = Math.max(end === undefined ? -1 : normalize(end), -1);
+    for (let index = from; index > to; index += step) {
+      indexes.push(index);
+    }
+  }
+  return indexes;
+};
+
+const evaluatePath = (operand, current, root) => {
+  let nodes = [{ value: operand.relative ? current : root }];
+  for (const segment of operand.segments) {
+    nodes = selectSegment(nodes, segment, root);
+  }
+  return nodes;
+};
+
+const compare = (operator, left, right) => {
+  if (left.length !== 1 || right.length !== 1) {
+    const bothMissing = left.length === 0 && right.length === 0;
+    return operator === "==" ? bothMissing : operator === "!=" ? !bothMissing : false;
+  }
+  const [a, b] = [left[0].value, right[0].value];
+  const equal = a === b || (typeof a === "object" && JSON.stringify(a) === JSON.stringify(b));
+  if (operator === "==" || operator === "!=") {
+    return operator === "==" ? equal : !equal;
+  }
+  if (typeof a !== typeof b || !["number", "string"].includes(typeof a)) {
+    return false;
+  }
+  return { "<": a < b, "<=": a <= b, ">": a > b, ">=": a >= b }[operator];
+};
+
+const operandNodes = (operand, current, root) =>
+  operand.type === "literal" ? [{ value: operand.value }] : evaluatePath(operand, current, root);
+
+const evaluateFilter = (expression, current, root) => {
+  switch (expression.type) {
+    case "or":
+      return evaluateFilter(expression.left, current, root) || evaluateFilter(expression.right, current, root);
+    case "and":
+      return evaluateFilter(expression.left, current, root) && evaluateFilter(expression.right, current, root);
+    case "not":
+      return !evaluateFilter(expression.expression, current, root);
+    case "exists":
+      return evaluatePath(expression.path, current, root).length > 0;
+    default:
+      return compare(
+        expression.operator,
+        operandNodes(expression.left, current, root),
+        operandNodes(expression.right, current, root),
+      );
+  }
+};
+
+const selectChildren = (node, selector, root) => {
+  const { value } = node;
+  switch (selector.type) {
+    case "name":
+      return isObject(value) && Object.hasOwn(value, selector.name)
+        ? [{ value: value[selector.name], parent: value, key: selector.name }]
+        : [];
+    case "index": {
+      const index = selector.index < 0 && Array.isArray(value) ? value.length + selector.index : selector.index;
+      const found = Array.isArray(value) && index >= 0 && index < value.length;
+      return found ? [{ value: value[index], parent: value, key: index }] : [];
+    }
+    case "slice":
+      return Array.isArray(value)
+        ? sliceIndexes(value.length, selector).map((index) => ({ value: value[index], parent: value, key: index }))
+        : [];
+    case "filter":
+      return childNodes(node).filter((child) => evaluateFilter(selector.expression, child.value, root));
+    default:
+      return childNodes(node);
+  }
+};
+
+const selectSegment = (nodes, segment, root) => {
+  const sources = segment.descendant ? nodes.flatMap(descendantNodes) : nodes;
+  return sources.flatMap((node) => segment.selectors.flatMap((selector) => selectChildren(node, selector, root)));
+};
+
+/**
+ * Evalueert een JSONPath-query op een document. Elk resultaat is een node met de waarde, de ouder
+ * en de sleutel (property of index) in die ouder, zodat de aanroeper de waarde kan vervangen of
+ * verwijderen. De root heeft geen ouder. Een ongeldige query geeft een JsonPathError.
+ */
+const queryJsonPath = (document, query) => {
+  if (typeof query !== "string" || query.trim().length === 0) {
+    throw new JsonPathError("Lege JSONPath-query");
+  }
+  const segments = new Parser(query).parseQuery();
+  let nodes = [{ value: document, parent: null, key: null }];
+  for (const segment of segments) {
+    nodes = selectSegment(nodes, segment, document);
+  }
+  return nodes;
+};
+
+module.exports = {
+  JsonPathError,
+  queryJsonPath,
+};
@@ -0,0 +1,51 @@
+const LOCAL_REF_PREFIX = "#/";
+
+/**
+ * Codeert een sleutel als segment van een JSON pointer (RFC 6901): `~` wordt `~0` en `/` wordt `~1`.
+ */
+const encodePointerSegment = (segment) => String(segment).replace(/~/g, "~0").replace(/\//g, "~1");
+
+/**
+ * Leest een segment van een JSON pointer terug als sleutel. Een `$ref` is een URI-fragment en mag dus
+ * percent-encoding bevatten (`%7B` voor `{`); ongeldige percent-encoding (zoals `100%`) blijft staan.
+ */
+const decodePointerSegment = (segment) => {
+  let decoded = segment;
+  try {
+    decoded = decodeURIComponent(segment);
+  } catch {
+    // geen geldige percent-encoding; gebruik het segment zoals het is
+  }
+  return decoded.replace(/~1/g, "/").replace(/~0/g, "~");
+};
+
+/**
+ * Lokale verwijzing (`#/a/b`) naar de plek met de gegeven sleutels.
+ */
+const formatPointer = (segments) => `${LOCAL_REF_PREFIX}${segments.map(encodePointerSegment).join("/")}`;
+
+/**
+ * Zoekt het doel van een lokale verwijzing (`#/...`) in het document. Alleen eigen properties tellen;
+ * een verwijzing die niet lokaal is of niet bestaat levert `undefined` op.
+ */
+const lookupPointer = (document, ref) => {
+  if (typeof ref !== "string" || !ref.startsWith(LOCAL_REF_PREFIX)) {
+    return undefined;
+  }
+  let target = document;
+  for (const segment of ref.slice(LOCAL_REF_PREFIX.length).split("/")) {
+    const key = decodePointerSegment(segment);
+    if (target === null || typeof target !== "object" || !Object.hasOwn(target, key)) {
+      return undefined;
+    }
+    target = target[key];
+  }
+  return target;
+};
+
+module.exports = {
+  decodePointerSegment,
+  encodePointerSegment,
+  formatPointer,
+  lookupPointer,
+};
@@ -0,0 +1,451 @@
+const { HTTP_METHODS } = require("./openapi");
+
+const OPENAPI_VERSION = "3.0.3";
+const DEFAULT_VERSION = "1.0.0";
+const MISSING_SERVER_URL = "@TODO: Add server URL";
+const API_VERSION_HEADER = "API-Version";
+const MAX_SCHEMA_DEPTH = 16;
+
+// Headers die de specificatie al op een andere manier beschrijft (content, security) of die de client zet.
+const IGNORED_HEADERS = new Set([
+  "accept",
+  "authorization",
+  "connection",
+  "content-length",
+  "content-type",
+  "cookie",
+  "host",
+  "user-agent",
+]);
+
+const SECURITY_SCHEME_NAMES = {
+  bearer: "bearerAuth",
+  basic: "basicAuth",
+  apiKey: "apiKeyAuth",
+  oauth2: "oauth2",
+};
+
+const DATE_TIME_PATTERN = /^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:?\d{2})?$/;
+const DATE_PATTERN = /^\d{4}-\d{2}-\d{2}$/;
+const UUID_PATTERN = /^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/i;
+const EMAIL_PATTERN = /^[^\s@]+@[^\s@]+\.[^\s@]+$/;
+
+const stringFormat = (value) => {
+  if (DATE_TIME_PATTERN.test(value)) {
+    return "date-time";
+  }
+  if (DATE_PATTERN.test(value)) {
+    return "date";
+  }
+  if (UUID_PATTERN.test(value)) {
+    return "uuid";
+  }
+  if (EMAIL_PATTERN.test(value)) {
+    return "email";
+  }
+  return undefined;
+};
+
+/**
+ * Voegt twee afgeleide schema's samen: objecten krijgen de properties van beide, arrays een
+ * samengevoegd items-schema en integer/number wordt number. Bij verschillende typen wint het eerste.
+ */
+const mergeSchemas = (left, right) => {
+  if (!left) {
+    return right;
+  }
+  if (!right) {
+    return left;
+  }
+  if (left.type === "object" && right.type === "object") {
+    const properties = { ...left.properties };
+    for (const [name, schema] of Object.entries(right.properties || {})) {
+      properties[name] = mergeSchemas(properties[name], schema);
+    }
+    return { ...left, properties };
+  }
+  if (left.type === "array" && right.type === "array") {
+    return { ...left, items: mergeSchemas(left.items, right.items) };
+  }
+  const numeric = (type) => type === "integer" || type === "number";
+  if (left.type !== right.type && numeric(left.type) && numeric(right.type)) {
+    return { ...left, type: "number" };
+  }
+  if (left.nullable && !left.type) {
+    return { ...right, nullable: true };
+  }
+  if (right.nullable && !right.type) {
+    return { ...left, nullable: true };
+  }
+  return left;
+};
+
+/**
+ * Leidt een OpenAPI 3.0 schema af uit een voorbeeldwaarde. Properties worden niet `required`
+ * gemaakt: uit één voorbeeld valt niet op te maken welke velden altijd aanwezig zijn.
+ */
+const inferSchema = (value, depth = 0) => {
+  if (value === null || value === undefined) {
+    return { nullable: true };
+  }
+  if (Array.isArray(value)) {
+    const items = depth < MAX_SCHEMA_DEPTH ? value.map((item) => inferSchema(item, depth + 1)) : [];
+    return { type: "array", items: items.reduce(mergeSchemas, undefined) || {} };
+  }
+  if (typeof value === "object") {
+    if (depth >= MAX_SCHEMA_DEPTH) {
+      return { type: "object" };
+    }
+    return {
+      type: "object",
+      properties: Object.fromEntries(
+        Object.entries(value).map(([name, property]) => [name, inferSchema(property, depth + 1)]),
+      ),
+    };
+  }
+  if (typeof value === "boolean") {
+    return { type: "boolean" };
+  }
+  if (typeof value === "number") {
+    return { type: Number.isInteger(value) ? "integer" : "number" };
+  }
+  const format = stringFormat(String(value));
+  return format ? { type: "string", format } : { type: "string" };
+};
+
+// Query-, pad- en headerwaarden zijn altijd tekst; herken getallen en booleans voor een bruikbaarder schema.
+const inferParameterSchema = (value) => {
+  const text = value === undefined || value === null ? "" : String(value);
+  if (/^-?\d+$/.test(text)) {
+    return { type: "integer" };
+  }
+  if (/^-?\d+\.\d+$/.test(text)) {
+    return { type: "number" };
+  }
+  if (text === "true" || text === "false") {
+    return { type: "boolean" };
+  }
+  return inferSchema(text);
+};
+
+const parameterExample = (schema, value) => {
+  if (value === undefined || value === null || value === "") {
+    return undefined;
+  }
+  if (schema.type === "integer" || schema.type === "number") {
+    return Number(value);
+  }
+  if (schema.type === "boolean") {
+    return value === "true";
+  }
+  return String(value);
+};
+
+const buildParameter = (location, { name, value, description }) => {
+  const schema = inferParameterSchema(value);
+  const parameter = { name, in: location };
+  if (description) {
+    parameter.description = description;
+  }
+  if (location === "path") {
+    parameter.required = true;
+  }
+  parameter.schema = schema;
+  const example = parameterExample(schema, value);
+  if (example !== undefined) {
+    parameter.example = example;
+  }
+  return parameter;
+};
+
+/**
+ * Zet een pad om naar een OpenAPI pad-template: één leidende slash, geen dubbele of afsluitende
+ * slashes en `:id` of `{{id}}` als segment wordt `{id}`.
+ */
+const normalizePath = (path) => {
+  const segments = String(path || "")
+    .split("/")
+    .filter((segment) => segment !== "")
+    .map((segment) => segment.replace(/^:(.+)$/, "{$1}").replace(/^\{\{\s*([^}]+?)\s*\}\}$/, "{$1}"));
+  return `/${segments.join("/")}`;
+};
+
+/**
+ * Vervangt `{{variabele}}` door de waarde uit `variables` (een Map). Onbekende of lege variabelen
+ * blijven staan, zodat duidelijk is wat er nog ingevuld moet worden.
+ */
+const substituteVariables = (text, variables) =>
+  String(text).replace(/\{\{\s*([^}]+?)\s*\}\}/g, (match, name) =>
+    variables.has(name) && variables.get(name) !== "" ? variables.get(name) : match,
+  );
+
+/**
+ * Splitst een request-URL uit een collectie in server, pad en queryparameters. Een host die alleen
+ * uit een variabele bestaat (`{{baseUrl}}/dieren`) wordt teruggegeven als `hostVariable`, met de
+ * waarde uit `variables` als server; die mag zelf een basispad bevatten. Zonder protocol wordt https
+ * aangenomen.
+ */
+const splitRequestUrl = (raw, variables = new Map()) => {
+  const [location, queryString = ""] = String(raw || "")
+    .split("#")[0]
+    .split(/\?(.*)/s);
+  const query = queryString
+    .split("&")
+    .filter(Boolean)
+    .map((pair) => {
+      const [name, value = ""] = pair.split("=");
+      return { name: decodeURIComponent(name), value: decodeURIComponent(value.replace(/\+/g, " ")) };
+    });
+  const variableHost = /^\{\{\s*([^}]+?)\s*\}\}(.*)$/.exec(location);
+  if (variableHost) {
+    const value = variables.get(variableHost[1]);
+    return {
+      server: value ? value.replace(/\/+$/, "") : undefined,
+      hostVariable: variableHost[1],
+      path: variableHost[2],
+      query,
+    };
+  }
+  const match = /^(?:([a-z][a-z0-9+.-]*):\/\/)?([^/]*)(.*)$/i.exec(location);
+  const host = substituteVariables(match[2], variables);
+  return { server: host ? `${match[1] || "https"}://${host}` : undefined, path: match[3], query };
+};
+
+const toOperationId = (used, text) => {
+  const words = String(text || "")
+    .normalize("NFKD")
+    .replace(/\p{M}+/gu, "")
+    .split(/[^A-Za-z0-9]+/)
+    .filter(Boolean);
+  const base =
+    words
+      .map((word, index) =>
+        index === 0 ? word.charAt(0).toLowerCase() + word.slice(1) : word.charAt(0).toUpperCase() + word.slice(1),
+      )
+      .join("") || "operation";
+  let candidate = /^[A-Za-z_]/.test(base) ? base : `op${base}`;
+  const prefix = candidate;
+  for (let counter = 2; used.has(candidate); counter += 1) {
+    candidate = `${prefix}${counter}`;
+  }
+  used.add(candidate);
+  return candidate;
+};
+
+const isJsonType = (contentType) => /[/+]json\b/i.test(contentType || "");
+
+/**
+ * Media type voor een voorbeeld-body. Tekst die als JSON geparsed kon worden heeft een schema met
+ * properties; formulieren (`fields`) worden een object met een property per veld.
+ */
+const buildMediaType = (body) => {
+  if (body.fields) {
+    return {
+      schema: {
+        type: "object",
+        properties: Object.fromEntries(
+          body.fields.map((field) => [
+            field.name,
+            field.file ? { type: "string", format: "binary" } : inferParameterSchema(field.value),
+          ]),
+        ),
+      },
+    };
+  }
+  if (body.value === undefined || body.value === "") {
+    return { schema: isJsonType(body.contentType) ? { type: "object" } : { type: "string" } };
+  }
+  return { schema: inferSchema(body.value), example: body.value };
+};
+
+const securityRequirement = (security) => {
+  const name = SECURITY_SCHEME_NAMES[security.type];
+  return { [name]: security.type === "oauth2" ? security.scopes || [] : [] };
+};
+
+const buildSecurityScheme = (security) => {
+  if (security.type === "bearer") {
+    return { type: "http", scheme: "bearer" };
+  }
+  if (security.type === "basic") {
+    return { type: "http", scheme: "basic" };
+  }
+  if (security.type === "apiKey") {
+    return { type: "apiKey", in: security.in || "header", name: security.name || "X-API-Key" };
+  }
+  return {
+    type: "oauth2",
+    flows: {
+      clientCredentials: {
+        tokenUrl: security.tokenUrl || "@TODO: Add token URL",
+        scopes: Object.fromEntries((security.scopes || []).map((scope) => [scope, ""])),
+      },
+    },
+  };
+};
+
+const mergeParameters = (existing, additions) => {
+  const merged = [...existing];
+  for (const parameter of additions) {
+    if (!merged.some((item) => item.in === parameter.in && item.name === parameter.name)) {
+      merged.push(parameter);
+    }
+  }
+  return merged;
+};
+
+// Voegt de media types van een volgend voorbeeld toe; bij hetzelfde media type worden de schema's samengevoegd.
+const mergeContent = (existing, additions) => {
+  if (!additions) {
+    return existing;
+  }
+  const content = { ...existing };
+  for (const [type, media] of Object.entries(additions)) {
+    content[type] = content[type]
+      ? { ...content[type], schema: mergeSchemas(content[type].schema, media.schema) }
+      : media;
+  }
+  return content;
+};
+
+const buildResponses = (responses, apiVersionRef) => {
+  const result = {};
+  for (const response of responses) {
+    const status = /^[1-5]\d\d$/.test(String(response.status)) ? String(response.status) : "default";
+    if (!result[status]) {
+      result[status] = { description: response.description || "OK", headers: { ...apiVersionRef } };
+    }
+    if (response.body && !result[status].content?.[response.body.contentType]) {
+      result[status].content = {
+        ...result[status].content,
+        [response.body.contentType]: buildMediaType(response.body),
+      };
+    }
+  }
+  if (Object.keys(result).length === 0) {
+    result["200"] = { description: "OK", headers: { ...apiVersionRef } };
+  }
+  return result;
+};
+
+/**
+ * Bouwt een OpenAPI 3.0 skelet uit requests die uit een collectie of opname zijn gehaald. `servers`
+ * bevat URL's of server-objecten (`{ url, description }`). Elke operatie heeft `method`, `path` en
+ * optioneel `summary`, `description`, `tag`, `pathParameters`, `query` en `headers` (lijsten van
+ * `{ name, value, description }`), `requestBody` (`{ contentType, value }` of `{ contentType,
+ * fields }`), `responses` (`{ status, description, body }`) en `security` (`{ type:
+ * bearer|basic|apiKey|oauth2, ... }`). Requests met dezelfde methode en hetzelfde pad worden één
+ * operatie; hun parameters, responses en schema's worden samengevoegd.
+ */
+const buildOasSkeleton = ({ info = {}, servers = [], operations = [] }) => {
+  const version = info.version || DEFAULT_VERSION;
+  const apiVersionRef = { [API_VERSION_HEADER]: { $ref: `#/components/headers/${API_VERSION_HEADER}` } };
+  const paths = {};
+  const tags = [];
+  const securitySchemes = {};
+  const usedOperationIds = new Set();
+
+  for (const entry of operations) {
+    const method = String(entry.method || "get").toLowerCase();
+    if (!HTTP_METHODS.includes(method)) {
+      continue;
+    }
+    const path = normalizePath(entry.path);
+    const pathParameterNames = [...path.matchAll(/\{([^}]+)\}/g)].map((match) => match[1]);
+    const parameters = [
+      ...pathParameterNames.map((name) =>
+        buildParameter("path", (entry.pathParameters || []).find((item) => item.name === name) || { name }),
+      ),
+      ...(entry.query || []).map((item) => buildParameter("query", item)),
+      ...(entry.headers || [])
+        .filter((item) => !IGNORED_HEADERS.has(item.name.toLowerCase()))
+        .filter((item) => !(entry.security?.type === "apiKey" && item.name === entry.security.name))
+        .map((item) => buildParameter("header", item)),
+    ];
+    const responses = buildResponses(entry.responses || [], apiVersionRef);
+    paths[path] = paths[path] || {};
+    const existing = paths[path][method];
+
+    if (existing) {
+      existing.parameters = mergeParameters(existing.parameters || [], parameters);
+      for (const [status, response] of Object.entries(responses)) {
+        if (existing.responses[status]) {
+          existing.responses[status].content = mergeContent(existing.responses[status].content, response.content);
+        } else {
+          existing.responses[status] = response;
+        }
+      }
+      if (entry.requestBody) {
+        const content = { [entry.requestBody.contentType]: buildMediaType(entry.requestBody) };
+        existing.requestBody = { content: mergeContent(existing.requestBody?.content, content) };
+      }
+      continue;
+    }
+
+    const operation = {};
+    if (entry.tag) {
+      operation.tags = [entry.tag];
+      if (!tags.some((tag) => tag.name === entry.tag)) {
+        tags.push({ name: entry.tag });
+      }
+    }
+    operation.summary = entry.summary || `${method.toUpperCase()} ${path}`;
+    if (entry.description) {
+      operation.description = entry.description;
+    }
+    operation.operationId = toOperationId(usedOperationIds, entry.summary || `${method} ${path}`);
+    if (parameters.length > 0) {
+      operation.parameters = parameters;
+    }
+    if (entry.requestBody) {
+      operation.requestBody = {
+        content: { [entry.requestBody.contentType]: buildMediaType(entry.requestBody) },
+      };
+    }
+    operation.responses = responses;
+    if (entry.security) {
+      const requirement = securityRequirement(entry.security);
+      const [name] = Object.keys(requirement);
+      securitySchemes[name] = securitySchemes[name] || buildSecurityScheme(entry.security);
+      operation.security = [requirement];
+    }
+    paths[path][method] = operation;
+  }
+
+  const document = {
+    openapi: OPENAPI_VERSION,
+    info: {
+      title: info.title || "API",
+      ...(info.description ? { description: info.description } : {}),
+      version,
+    },
+    servers: (servers.length > 0 ? servers : [MISSING_SERVER_URL]).map((server) =>
+      typeof server === "string" ? { url: server } : server,
+    ),
+  };
+  if (tags.length > 0) {
+    document.tags = tags;
+  }
+  document.paths = paths;
+  document.components = {
+    headers: {
+      [API_VERSION_HEADER]: {
+        description: "De API-versie van de response",
+        schema: { type: "string", example: version },
+      },
+    },
+  };
+  if (Object.keys(securitySchemes).length > 0) {
+    document.components.securitySchemes = securitySchemes;
+  }
+  return document;
+};
+
+module.exports = {
+  buildOasSkeleton,
+  inferSchema,
+  mergeSchemas,
+  normalizePath,
+  splitRequestUrl,
+  substituteVariables,
+};
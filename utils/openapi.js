@@ -0,0 +1,358 @@
+const { decodePointerSegment, lookupPointer } = require("./jsonPointer");
+
+const HTTP_METHODS = ["get", "put", "post", "delete", "options", "head", "patch", "trace"];
+const LOCAL_REF_PREFIX = "#/";
+const SCHEMA_REF_PREFIX = "#/components/schemas/";
+const MAX_SAMPLE_DEPTH = 8;
+
+/**
+ * Volgt een lokale `$ref` (`#/components/...`) binnen hetzelfde document. Externe verwijzingen en
+ * verwijzingen die niet bestaan leveren `undefined` op; een keten van refs wordt tot het einde gevolgd.
+ */
+const resolveRef = (document, value, seen = new Set()) => {
+  if (!value || typeof value !== "object" || typeof value.$ref !== "string") {
+    return value;
+  }
+  const ref = value.$ref;
+  if (!ref.startsWith(LOCAL_REF_PREFIX) || seen.has(ref)) {
+    return undefined;
+  }
+  seen.add(ref);
+  return resolveRef(document, lookupPointer(document, ref), seen);
+};
+
+/**
+ * Naam van het schema waar een `$ref` naar `#/components/schemas/...` naar wijst, zoals die in
+ * `components` staat; `undefined` voor andere waarden.
+ */
+const schemaRefName = (schema) =>
+  typeof schema?.$ref === "string" && schema.$ref.startsWith(SCHEMA_REF_PREFIX)
+    ? decodePointerSegment(schema.$ref.slice(SCHEMA_REF_PREFIX.length))
+    : undefined;
+
+/**
+ * Vult de variabelen van een server-URL in met hun default, bijvoorbeeld
+ * `https://{env}.example.nl/v1` wordt `https://api.example.nl/v1`.
+ */
+const expandServerUrl = (server) => {
+  const url = typeof server?.url === "string" ? server.url : "";
+  return url.replace(/\{([^}]+)\}/g, (match, name) => {
+    const variable = server.variables?.[name];
+    return variable?.default !== undefined ? String(variable.default) : match;
+  });
+};
+
+/**
+ * Alle `servers`-lijsten van het document: op het hoogste niveau, per pad en per operatie, met `at`
+ * als pad naar de lijst (voor een JSON pointer).
+ */
+const collectServerLists = (document) => {
+  const lists = [{ servers: document?.servers, at: ["servers"] }];
+  for (const [path, pathItem] of Object.entries(document?.paths || {})) {
+    lists.push({ servers: pathItem?.servers, at: ["paths", path, "servers"] });
+    for (const method of HTTP_METHODS.filter((method) => pathItem?.[method] && typeof pathItem[method] === "object")) {
+      lists.push({ servers: pathItem[method].servers, at: ["paths", path, method, "servers"] });
+    }
+  }
+  return lists.filter(({ servers }) => Array.isArray(servers));
+};
+
+const mergeParameters = (document, pathParameters, operationParameters) => {
+  const merged = new Map();
+  for (const candidate of [...(pathParameters || []), ...(operationParameters || [])]) {
+    const parameter = resolveRef(document, candidate);
+    if (parameter?.name && parameter.in) {
+      merged.set(`${parameter.in}:${parameter.name}`, parameter);
+    }
+  }
+  return [...merged.values()];
+};
+
+/**
+ * Alle `$ref`s die niet binnen het document blijven (niet `#/...`), zoals een URL of een bestandspad,
+ * elk één keer. Ook verwijzingen in voorbeelden tellen mee: een tool die ze oplost, kijkt daar niet naar.
+ */
+const collectExternalRefs = (document) => {
+  const refs = new Set();
+  const visit = (node) => {
+    if (Array.isArray(node)) {
+      node.forEach(visit);
+    } else if (node && typeof node === "object") {
+      for (const [key, value] of Object.entries(node)) {
+        if (key === "$ref" && typeof value === "string") {
+          if (!value.startsWith("#")) {
+            refs.add(value);
+          }
+        } else {
+          visit(value);
+        }
+      }
+    }
+  };
+  visit(document);
+  return [...refs];
+};
+
+/**
+ * Alle operaties van het document in documentvolgorde, met de parameters van het pad en de operatie
+ * samengevoegd (de operatie wint) en lokale refs in parameters en requestBody opgelost.
+ */
+const collectOperations = (document) => {
+  const operations = [];
+  for (const [path, rawPathItem] of Object.entries(document?.paths || {})) {
+    const pathItem = resolveRef(document, rawPathItem);
+    if (!pathItem || typeof pathItem !== "object") {
+      continue;
+    }
+    for (const method of HTTP_METHODS) {
+      const operation = pathItem[method];
+      if (!operation || typeof operation !== "object") {
+        continue;
+      }
+      operations.push({
+        path,
+        method,
+        operation,
+        parameters: mergeParameters(document, pathItem.parameters, operation.parameters),
+        requestBody: resolveRef(document, operation.requestBody),
+      });
+    }
+  }
+  return operations;
+};
+
+/**
+ * Leesbare naam van een operatie: `summary`, anders `operationId`, anders methode en pad.
+ */
+const operationName = ({ method, path, operation }) =>
+  String(operation.summary || operation.operationId || `${method.toUpperCase()} ${path}`).trim();
+
+/**
+ * Eerste security scheme van de operatie (of het document) dat een HTTP-client zelf kan invullen:
+ * `bearer` (HTTP bearer, OAuth2 en OpenID Connect), `basic` of `apiKey` in een header. Andere
+ * schemes, zoals een API-key in de query of een cookie, leveren `undefined` op. `definition` is het
+ * opgeloste scheme en `scopes` de scopes uit het security requirement.
+ */
+const resolveAuthScheme = (document, operation) => {
+  const requirements = operation?.security ?? document?.security ?? [];
+  const schemes = document?.components?.securitySchemes || {};
+  for (const requirement of requirements) {
+    for (const name of Object.keys(requirement || {})) {
+      const scheme = resolveRef(document, schemes[name]);
+      const httpScheme = String(scheme?.scheme).toLowerCase();
+      const details = { definition: scheme, scopes: Array.isArray(requirement[name]) ? requirement[name] : [] };
+      if (scheme?.type === "oauth2" || scheme?.type === "openIdConnect") {
+        return { type: "bearer", ...details };
+      }
+      if (scheme?.type === "http" && (httpScheme === "bearer" || httpScheme === "basic")) {
+        return { type: httpScheme, ...details };
+      }
+      if (scheme?.type === "apiKey" && scheme.in === "header" && scheme.name) {
+        return { type: "apiKey", header: scheme.name, ...details };
+      }
+    }
+  }
+  return undefined;
+};
+
+/**
+ * Token-URL van het eerste OAuth2 scheme, met de client credentials flow als voorkeur. Leeg als het
+ * document geen OAuth2 met een token-URL beschrijft.
+ */
+const resolveTokenUrl = (document) => {
+  for (const candidate of Object.values(document?.components?.securitySchemes || {})) {
+    const flows = resolveRef(document, candidate)?.flows;
+    const flow = flows?.clientCredentials ?? flows?.authorizationCode ?? flows?.password;
+    if (typeof flow?.tokenUrl === "string") {
+      return flow.tokenUrl;
+    }
+  }
+  return "";
+};
+
+/**
+ * Het eerste OAuth2 scheme met een client credentials flow: naam, token-URL en de scopes die de
+ * security requirements van het document en de operaties voor dat scheme vragen (op volgorde van
+ * voorkomen, zonder dubbelen). `undefined` als het document geen client credentials flow kent.
+ */
+const resolveClientCredentials = (document) => {
+  for (const [name, candidate] of Object.entries(document?.components?.securitySchemes || {})) {
+    const scheme = resolveRef(document, candidate);
+    const flow = scheme?.type === "oauth2" && scheme.flows?.clientCredentials;
+    if (!flow) {
+      continue;
+    }
+    const requirements = [
+      ...(document.security || []),
+      ...collectOperations(document).flatMap(({ operation }) => operation.security || []),
+    ];
+    const scopes = new Set(requirements.flatMap((requirement) => requirement?.[name] || []));
+    return { name, tokenUrl: typeof flow.tokenUrl === "string" ? flow.tokenUrl : "", scopes: [...scopes] };
+  }
+  return undefined;
+};
+
+/**
+ * Variabelen die een client nodig heeft voor de authenticatie van de operaties, in vaste volgorde.
+ */
+const collectAuthVariables = (document, operations) => {
+  const variables = new Set();
+  for (const { operation } of operations) {
+    const auth = resolveAuthScheme(document, operation);
+    if (auth?.type === "bearer") {
+      variables.add("token");
+    }
+    if (auth?.type === "basic") {
+      variables.add("username").add("password");
+    }
+    if (auth?.type === "apiKey") {
+      variables.add("apiKey");
+    }
+  }
+  return [...variables];
+};
+
+/**
+ * Voorbeeldwaarde van een parameter als string: `example`, of `example`/`default`/eerste enum-waarde
+ * van het schema. Lege string als er niets bekend is.
+ */
+const parameterExample = (document, parameter) => {
+  const schema = resolveRef(document, parameter.schema);
+  const value = parameter.example ?? schema?.example ?? schema?.default ?? schema?.enum?.[0];
+  if (value === undefined || value === null) {
+    return "";
+  }
+  return typeof value === "object" ? JSON.stringify(value) : String(value);
+};
+
+/**
+ * Namen van padparameters zonder voorbeeldwaarde; clients maken daar een (lege) variabele van.
+ */
+const collectPathVariables = (document, operations) => {
+  const variables = new Set();
+  for (const { path, parameters } of operations) {
+    for (const [, name] of path.matchAll(/\{([^}]+)\}/g)) {
+      const parameter = parameters.find((item) => item.in === "path" && item.name === name);
+      if (!parameter || !parameterExample(document, parameter)) {
+        variables.add(name);
+      }
+    }
+  }
+  return [...variables];
+};
+
+const sampleForType = (schema) => {
+  if (schema.format === "date-time") {
+    return "2024-01-01T00:00:00Z";
+  }
+  if (schema.format === "date") {
+    return "2024-01-01";
+  }
+  if (schema.format === "uuid") {
+    return "00000000-0000-0000-0000-000000000000";
+  }
+  if (schema.format === "email") {
+    return "user@example.com";
+  }
+  if (schema.format === "uri") {
+    return "https://example.com";
+  }
+  return "string";
+};
+
+/**
+ * Bouwt een voorbeeldwaarde uit een schema: `example`, `default` en de eerste enum-waarde gaan voor,
+ * anders wordt per type een plausibele waarde gekozen. Recursie stopt na een vaste diepte zodat
+ * zelfverwijzende schema's eindigen. Properties met `skip` (standaard `readOnly`, voor request-bodies)
+ * worden weggelaten; voor een response-body geef je `writeOnly` mee.
+ */
+const buildSample = (document, rawSchema, depth = 0, { skip = "readOnly" } = {}) => {
+  const schema = resolveRef(document, rawSchema);
+  if (!schema || typeof schema !== "object" || depth > MAX_SAMPLE_DEPTH) {
+    return null;
+  }
+  if (schema.example !== undefined) {
+    return schema.example;
+  }
+  if (schema.default !== undefined) {
+    return schema.default;
+  }
+  if (Array.isArray(schema.enum) && schema.enum.length > 0) {
+    return schema.enum[0];
+  }
+  if (Array.isArray(schema.allOf)) {
+    return schema.allOf.reduce((sample, part) => {
+      const partSample = buildSample(document, part, depth + 1, { skip });
+      return partSample && typeof partSample === "object" && !Array.isArray(partSample)
+        ? { ...sample, ...partSample }
+        : sample;
+    }, {});
+  }
+  const variant = schema.oneOf?.[0] ?? schema.anyOf?.[0];
+  if (variant) {
+    return buildSample(document, variant, depth + 1, { skip });
+  }
+  const type = Array.isArray(schema.type) ? schema.type.find((item) => item !== "null") : schema.type;
+  if (type === "object" || (!type && schema.properties)) {
+    return Object.fromEntries(
+      Object.entries(schema.properties || {})
+        .filter(([, property]) => !resolveRef(document, property)?.[skip])
+        .map(([name, property]) => [name, buildSample(document, property, depth + 1, { skip })]),
+    );
+  }
+  if (type === "array") {
+    const item = buildSample(document, schema.items, depth + 1, { skip });
+    return item === null ? [] : [item];
+  }
+  if (type === "integer" || type === "number") {
+    return schema.minimum ?? 0;
+  }
+  if (type === "boolean") {
+    return true;
+  }
+  if (type === "string") {
+    return sampleForType(schema);
+  }
+  return null;
+};
+
+/**
+ * Voorbeeld-body voor de eerste JSON media type van een requestBody: een expliciet `example`, het
+ * eerste van `examples`, of (met `synthesize`, de standaard) een uit het schema opgebouwde waarde.
+ * `undefined` als er geen JSON body of geen voorbeeld is.
+ */
+const buildJsonRequestSample = (document, requestBody, { synthesize = true } = {}) => {
+  const content = requestBody?.content || {};
+  const mediaType = Object.keys(content).find((type) => /[/+]json\b/i.test(type));
+  if (!mediaType) {
+    return undefined;
+  }
+  const media = content[mediaType] || {};
+  if (media.example !== undefined) {
+    return media.example;
+  }
+  const firstExample = resolveRef(document, Object.values(media.examples || {})[0]);
+  if (firstExample?.value !== undefined) {
+    return firstExample.value;
+  }
+  return synthesize ? buildSample(document, media.schema) : undefined;
+};
+
+module.exports = {
+  HTTP_METHODS,
+  buildJsonRequestSample,
+  buildSample,
+  collectAuthVariables,
+  collectExternalRefs,
+  collectOperations,
+  collectPathVariables,
+  collectServerLists,
+  expandServerUrl,
+  operationName,
+  parameterExample,
+  resolveAuthScheme,
+  resolveClientCredentials,
+  resolveRef,
+  resolveTokenUrl,
+  schemaRefName,
+};
@@ -0,0 +1,269 @@
+const { formatPointer } = require("./jsonPointer");
+
+const DEFAULT_DIALECTS = new Set([
+  "https://spec.openapis.org/oas/3.1/dialect/base",
+  "https://json-schema.org/draft/2020-12/schema",
+]);
+
+// JSON Schema keywords zonder tegenhanger in het Schema Object van OpenAPI 3.0.
+const UNSUPPORTED_KEYWORDS = [
+  "$id",
+  "$anchor",
+  "$dynamicRef",
+  "$dynamicAnchor",
+  "$defs",
+  "$vocabulary",
+  "if",
+  "then",
+  "else",
+  "dependentSchemas",
+  "dependentRequired",
+  "unevaluatedProperties",
+  "unevaluatedItems",
+  "contains",
+  "minContains",
+  "maxContains",
+  "propertyNames",
+  "patternProperties",
+];
+
+// Binaire inhoud heet in 3.1 `contentMediaType`/`contentEncoding` en in 3.0 `format`.
+const BASE64_ENCODINGS = new Set(["base64", "base64url"]);
+
+// Keywords die na de loop per schema worden omgezet, of ($comment) zonder gevolgen wegvallen.
+const HANDLED_KEYWORDS = new Set([
+  "exclusiveMinimum",
+  "exclusiveMaximum",
+  "minimum",
+  "maximum",
+  "contentEncoding",
+  "contentMediaType",
+  "prefixItems",
+  "$comment",
+]);
+
+const SUBSCHEMA_KEYS = ["items", "additionalProperties", "not"];
+const SCHEMA_LIST_KEYS = ["allOf", "anyOf", "oneOf"];
+
+// Voorbeelden zijn data, geen schema's; daarin wordt niet gezocht naar `schema`.
+const EXAMPLE_KEYS = new Set(["example", "examples"]);
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+const lossy = (context, at, message) =>
+  context.warnings.push({ path: formatPointer(at), message });
+
+const dropped = (context, at, keyword) =>
+  lossy(context, at, `${keyword} bestaat niet in OpenAPI 3.0 en is weggelaten.`);
+
+// `exclusiveMinimum: 5` wordt `minimum: 5, exclusiveMinimum: true`; de strengste grens wint.
+const applyExclusiveBound = (converted, schema, inclusiveKey, exclusiveKey, isStricter) => {
+  const exclusive = schema[exclusiveKey];
+  const inclusive = schema[inclusiveKey];
+  if (typeof exclusive !== "number") {
+    if (exclusive !== undefined) {
+      converted[exclusiveKey] = exclusive;
+    }
+    return;
+  }
+  if (typeof inclusive === "number" && !isStricter(exclusive, inclusive)) {
+    converted[inclusiveKey] = inclusive;
+    return;
+  }
+  converted[inclusiveKey] = exclusive;
+  converted[exclusiveKey] = true;
+};
+
+const convertType = (context, converted, schema, at) => {
+  const { type } = schema;
+  if (!Array.isArray(type)) {
+    converted.type = type;
+    return;
+  }
+  const types = [...new Set(type.filter((value) => value !== "null"))];
+  if (types.length < type.length) {
+    converted.nullable = true;
+  }
+  if (types.length === 1) {
+    converted.type = types[0];
+  } else if (types.length > 1) {
+    // Type-specifieke keywords blijven op het schema staan; die gelden alleen voor het passende type.
+    const alternatives = types.map((value) => ({ type: value }));
+    if (schema.oneOf === undefined && schema.anyOf === undefined) {
+      converted.oneOf = alternatives;
+    } else {
+      converted.allOf = [...(converted.allOf ?? []), { oneOf: alternatives }];
+    }
+  } else if (converted.nullable) {
+    lossy(context, [...at, "type"], 'type: "null" bestaat niet in OpenAPI 3.0; het schema staat alleen null toe.');
+    converted.enum = [null];
+  }
+};
+
+const convertContent = (context, converted, schema, at) => {
+  const encoding = schema.contentEncoding;
+  const mediaType = schema.contentMediaType;
+  if (encoding !== undefined) {
+    if (BASE64_ENCODINGS.has(String(encoding).toLowerCase())) {
+      converted.format = converted.format ?? "byte";
+    } else {
+      dropped(context, [...at, "contentEncoding"], `contentEncoding ${encoding}`);
+    }
+  }
+  if (mediaType !== undefined) {
+    if (encoding === undefined && converted.type === "string") {
+      converted.format = converted.format ?? "binary";
+    }
+    if (encoding !== undefined || mediaType !== "application/octet-stream") {
+      dropped(context, [...at, "contentMediaType"], `contentMediaType ${mediaType}`);
+    }
+  }
+};
+
+const convertPrefixItems = (context, converted, schema, at) => {
+  const members = schema.prefixItems.map((member, index) =>
+    convertSchema(context, member, [...at, "prefixItems", index]),
+  );
+  if (schema.items !== undefined && schema.items !== false) {
+    members.push(convertSchema(context, schema.items, [...at, "items"]));
+  }
+  // Een tuple van gelijke items (zoals een coördinaat) is gewoon een array van dat item.
+  const unique = [...new Map(members.map((member) => [JSON.stringify(member), member])).values()];
+  converted.items = unique.length === 1 ? unique[0] : { oneOf: unique };
+  if (schema.items === false) {
+    const length = schema.prefixItems.length;
+    converted.maxItems = Math.min(converted.maxItems ?? length, length);
+  }
+  if (unique.length > 1) {
+    const message = "prefixItems (tuple) bestaat niet in OpenAPI 3.0; de items zijn een oneOf zonder vaste positie.";
+    lossy(context, [...at, "prefixItems"], message);
+  }
+};
+
+/**
+ * Zet één JSON Schema 2020-12 schema om naar een OpenAPI 3.0 Schema Object. Wat zonder verlies kan
+ * (nullable, const, exclusieve grenzen als getal, $ref met siblings) gebeurt stil; de rest wordt
+ * benaderd of weggelaten en als lossy gemeld.
+ */
+const convertSchema = (context, schema, at) => {
+  if (schema === true) {
+    return {};
+  }
+  if (schema === false) {
+    return { not: {} };
+  }
+  if (!isObject(schema)) {
+    return schema;
+  }
+  const converted = {};
+  for (const [key, value] of Object.entries(schema)) {
+    if (key === "items" && Array.isArray(schema.prefixItems)) {
+      continue;
+    }
+    if (key === "type") {
+      convertType(context, converted, schema, at);
+    } else if (key === "const") {
+      converted.enum = [value];
+    } else if (key === "examples" && Array.isArray(value)) {
+      if (value.length > 0 && converted.example === undefined) {
+        converted.example = value[0];
+      }
+      if (value.length > 1) {
+        lossy(context, [...at, key], "OpenAPI 3.0 kent één example per schema; alleen het eerste is overgenomen.");
+      }
+    } else if (key === "$schema") {
+      if (!DEFAULT_DIALECTS.has(value)) {
+        dropped(context, [...at, key], `$schema ${value}`);
+      }
+    } else if (key === "properties" && isObject(value)) {
+      converted.properties = Object.fromEntries(
+        Object.entries(value).map(([name, property]) => [name, convertSchema(context, property, [...at, key, name])]),
+      );
+    } else if (key === "additionalProperties" && typeof value === "boolean") {
+      converted[key] = value;
+    } else if (SUBSCHEMA_KEYS.includes(key)) {
+      converted[key] = convertSchema(context, value, [...at, key]);
+    } else if (SCHEMA_LIST_KEYS.includes(key) && Array.isArray(value)) {
+      const members = value.map((member, index) => convertSchema(context, member, [...at, key, index]));
+      converted[key] = [...(converted[key] ?? []), ...members];
+    } else if (UNSUPPORTED_KEYWORDS.includes(key)) {
+      dropped(context, [...at, key], key);
+    } else if (!HANDLED_KEYWORDS.has(key)) {
+      converted[key] = value;
+    }
+  }
+  applyExclusiveBound(converted, schema, "minimum", "exclusiveMinimum", (exclusive, bound) => exclusive >= bound);
+  applyExclusiveBound(converted, schema, "maximum", "exclusiveMaximum", (exclusive, bound) => exclusive <= bound);
+  convertContent(context, converted, schema, at);
+  if (Array.isArray(schema.prefixItems)) {
+    convertPrefixItems(context, converted, schema, at);
+  }
+  // In 3.0 worden siblings van een $ref genegeerd; met allOf blijven ze gelden.
+  if (typeof converted.$ref === "string" && Object.keys(converted).length > 1) {
+    const { $ref, ...siblings } = converted;
+    return { allOf: [{ $ref }], ...siblings };
+  }
+  return converted;
+};
+
+const walkDocument = (context, value, at) => {
+  if (Array.isArray(value)) {
+    return value.map((child, index) => walkDocument(context, child, [...at, index]));
+  }
+  if (!isObject(value)) {
+    return value;
+  }
+  // Direct onder components staan namen, geen velden: een parameter mag bijvoorbeeld "schema" heten.
+  const inComponent = at.length === 2 && at[0] === "components";
+  const inSchemas = inComponent && at[1] === "schemas";
+  return Object.fromEntries(
+    Object.entries(value).map(([key, child]) => {
+      if (inSchemas || (key === "schema" && !inComponent)) {
+        return [key, convertSchema(context, child, [...at, key])];
+      }
+      if (EXAMPLE_KEYS.has(key) || key.startsWith("x-")) {
+        return [key, child];
+      }
+      return [key, walkDocument(context, child, [...at, key])];
+    }),
+  );
+};
+
+/**
+ * Bereidt een OpenAPI 3.1 document voor op de downgrade naar 3.0: alle schema's (in components, en
+ * als `schema` van parameters, headers en media types) krijgen 3.0 keywords, en velden die alleen in
+ * 3.1 bestaan worden weggelaten. `warnings` noemt elke conversie waarbij informatie verloren gaat,
+ * met een JSON pointer naar de plek in het brondocument.
+ */
+const convertSchemas31To30 = (spec) => {
+  const context = { warnings: [] };
+  const converted = walkDocument(context, spec, []);
+  if (converted.jsonSchemaDialect !== undefined) {
+    if (!DEFAULT_DIALECTS.has(converted.jsonSchemaDialect)) {
+      dropped(context, ["jsonSchemaDialect"], `jsonSchemaDialect ${converted.jsonSchemaDialect}`);
+    }
+    delete converted.jsonSchemaDialect;
+  }
+  if (converted.info?.summary !== undefined) {
+    dropped(context, ["info", "summary"], "info.summary");
+    delete converted.info.summary;
+  }
+  if (converted.info?.license?.identifier !== undefined) {
+    dropped(context, ["info", "license", "identifier"], "info.license.identifier");
+    delete converted.info.license.identifier;
+  }
+  if (converted.components?.pathItems !== undefined) {
+    dropped(context, ["components", "pathItems"], "components.pathItems");
+    delete converted.components.pathItems;
+  }
+  if (converted.webhooks !== undefined) {
+    lossy(context, ["webhooks"], "webhooks bestaan niet in OpenAPI 3.0; ze staan nu in x-webhooks.");
+    converted["x-webhooks"] = converted.webhooks;
+    delete converted.webhooks;
+  }
+  return { spec: converted, warnings: context.warnings };
+};
+
+module.exports = {
+  convertSchemas31To30,
+};
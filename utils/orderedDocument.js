@@ -0,0 +1,190 @@
+const { isDeepStrictEqual } = require("node:util");
+const jsYaml = require("js-yaml");
+const { YAML_SCHEMA, parseJsonOrYaml } = require("./yaml");
+
+// JavaScript zet sleutels die op een array-index lijken (`200`, `404`) altijd vooraan en oplopend.
+// Zulke sleutels krijgen tijdelijk dit voorvoegsel, zodat ze hun plek houden.
+const KEY_MARKER = "\u0000";
+const INDEX_KEY = /^(0|[1-9]\d*)$/;
+const MERGE_KEY = "<<";
+
+const isPlainObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+const markKey = (key) => (INDEX_KEY.test(key) ? `${KEY_MARKER}${key}` : key);
+
+const unmarkKey = (key) => (key.startsWith(KEY_MARKER) ? key.slice(KEY_MARKER.length) : key);
+
+/**
+ * Zet gewone objecten om naar het geordende model: een `Map` per object, zodat de volgorde van alle
+ * sleutels behouden blijft. Sleutels met voorvoegsel verliezen dat hier.
+ */
+const fromPlain = (value) => {
+  if (Array.isArray(value)) {
+    return value.map(fromPlain);
+  }
+  if (isPlainObject(value)) {
+    return new Map(Object.entries(value).map(([key, child]) => [unmarkKey(key), fromPlain(child)]));
+  }
+  return value;
+};
+
+const toPlain = (value, mapKey = (key) => key) => {
+  if (Array.isArray(value)) {
+    return value.map((item) => toPlain(item, mapKey));
+  }
+  if (value instanceof Map) {
+    return Object.fromEntries([...value].map(([key, child]) => [mapKey(key), toPlain(child, mapKey)]));
+  }
+  if (isPlainObject(value)) {
+    return Object.fromEntries(Object.entries(value).map(([key, child]) => [mapKey(key), toPlain(child, mapKey)]));
+  }
+  return value;
+};
+
+/**
+ * JSON in bronvolgorde: elke sleutel die uit cijfers bestaat krijgt in de tekst een voorvoegsel.
+ * De strings worden van links naar rechts doorlopen, dus een dubbele punt binnen een string telt niet.
+ */
+const parseOrderedJson = (text) => {
+  const marked = text.replace(/"((?:[^"\\]|\\.)*)"(\s*:)?/g, (match, content, colon) =>
+    colon && INDEX_KEY.test(content) ? `"\\u0000${content}"${colon}` : match,
+  );
+  return fromPlain(JSON.parse(marked));
+};
+
+const buildMapping = (children) => {
+  const entries = [];
+  for (let index = 0; index + 1 < children.length; index += 2) {
+    entries.push([String(children[index].value), children[index + 1].value]);
+  }
+  const explicit = new Set(entries.filter(([key]) => key !== MERGE_KEY).map(([key]) => key));
+  const mapping = new Map();
+  for (const [key, value] of entries) {
+    if (key !== MERGE_KEY) {
+      mapping.set(key, value);
+      continue;
+    }
+    // Merge keys (`<<: *basis`): eerdere bronnen winnen, expliciete sleutels altijd.
+    for (const source of (Array.isArray(value) ? value : [value]).filter((item) => item instanceof Map)) {
+      for (const [mergedKey, mergedValue] of source) {
+        if (!explicit.has(mergedKey) && !mapping.has(mergedKey)) {
+          mapping.set(mergedKey, mergedValue);
+        }
+      }
+    }
+  }
+  return mapping;
+};
+
+/**
+ * YAML in bronvolgorde. js-yaml meldt via `listener` het openen en sluiten van elke node in
+ * documentvolgorde; daaruit wordt de boom opnieuw opgebouwd, met aliases naar dezelfde node.
+ * Wijkt het resultaat inhoudelijk af van wat js-yaml zelf oplevert, dan geldt dat laatste.
+ */
+const parseOrderedYaml = (text, plain) => {
+  const stack = [{ children: [] }];
+  const built = new WeakMap();
+  const listener = (event, state) => {
+    if (event === "open") {
+      stack.push({ children: [] });
+      return;
+    }
+    const { children } = stack.pop();
+    const raw = state.result;
+    let value = raw;
+    if (children.length === 1 && children[0].raw === raw && raw !== null && typeof raw === "object") {
+      value = children[0].value;
+    } else if (Array.isArray(raw)) {
+      value = children.length === 0 && built.has(raw) ? built.get(raw) : children.map((child) => child.value);
+    } else if (isPlainObject(raw)) {
+      value = children.length === 0 && built.has(raw) ? built.get(raw) : buildMapping(children);
+    }
+    if (raw !== null && typeof raw === "object") {
+      built.set(raw, value);
+    }
+    stack[stack.length - 1].children.push({ raw, value });
+  };
+  try {
+    jsYaml.load(text, { schema: YAML_SCHEMA, listener });
+  } catch {
+    return fromPlain(plain);
+  }
+  const document = stack[0].children.at(-1)?.value;
+  return isDeepStrictEqual(toPlain(document), plain) ? document : fromPlain(plain);
+};
+
+/**
+ * Parseert JSON of YAML naar het geordende model (`Map` voor objecten) met de sleutels in de volgorde
+ * van de bron. `plain` is hetzelfde document als gewone objecten; de limieten van utils/yaml gelden.
+ */
+const parseOrderedDocument = (contents, options = {}) => {
+  const text = typeof contents === "string" ? contents : String(contents ?? "");
+  const { document: plain, format } = parseJsonOrYaml(text, options);
+  const document = format === "json" ? parseOrderedJson(text) : parseOrderedYaml(text, plain);
+  return { document, plain, format };
+};
+
+/**
+ * Het geordende model van een bewerkt document (gewone objecten), met de sleutelvolgorde van `source`
+ * (het geordende model van de bron) op dezelfde plek. Sleutels die ook in de bron staan, vullen hun
+ * plekken in de volgorde van de bron; nieuwe sleutels, zoals `openapi` na een omzetting van Swagger 2.0,
+ * blijven waar ze staan.
+ */
+const withSourceOrder = (document, source) => {
+  if (Array.isArray(document)) {
+    return document.map((item, index) => withSourceOrder(item, Array.isArray(source) ? source[index] : undefined));
+  }
+  if (!isPlainObject(document)) {
+    return document;
+  }
+  const sourceKeys = source instanceof Map ? [...source.keys()].filter((key) => Object.hasOwn(document, key)) : [];
+  const shared = new Set(sourceKeys);
+  const result = new Map();
+  for (const key of Object.keys(document)) {
+    const placed = shared.has(key) ? sourceKeys.shift() : key;
+    result.set(placed, withSourceOrder(document[placed], source instanceof Map ? source.get(placed) : undefined));
+  }
+  return result;
+};
+
+const stringifyJson = (value, indent) => {
+  const inner = `${indent}  `;
+  if (Array.isArray(value)) {
+    const items = value.map((item) => `${inner}${stringifyJson(item, inner)}`);
+    return items.length === 0 ? "[]" : `[\n${items.join(",\n")}\n${indent}]`;
+  }
+  if (value instanceof Map) {
+    const entries = [...value].map(([key, child]) => `${inner}${JSON.stringify(key)}: ${stringifyJson(child, inner)}`);
+    return entries.length === 0 ? "{}" : `{\n${entries.join(",\n")}\n${indent}}`;
+  }
+  if (isPlainObject(value)) {
+    return stringifyJson(fromPlain(value), indent);
+  }
+  return JSON.stringify(value) ?? "null";
+};
+
+/**
+ * Serialiseert het geordende model als JSON (zelfde opmaak als `JSON.stringify(doc, null, 2)`) of als
+ * YAML (zelfde opties als `dumpYaml`), met de sleutels in de volgorde van het model.
+ */
+const serializeOrdered = (document, format) => {
+  if (format === "json") {
+    return stringifyJson(document, "");
+  }
+  const dump = (value) => jsYaml.dump(value, { lineWidth: -1, noRefs: true, schema: YAML_SCHEMA });
+  const plain = toPlain(document);
+  const marked = toPlain(document, markKey);
+  if (JSON.stringify(plain).includes("\\u0000")) {
+    // Een NUL-teken in de inhoud zelf: het voorvoegsel is dan niet betrouwbaar terug te vinden.
+    return dump(plain);
+  }
+  return dump(marked).replace(/"\\0(\d+)":/g, (match, key) => `${dump(key).trimEnd()}:`);
+};
+
+module.exports = {
+  fromPlain,
+  parseOrderedDocument,
+  serializeOrdered,
+  toPlain,
+  withSourceOrder,
+};
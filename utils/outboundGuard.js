@@ -0,0 +1,18 @@
+/**
+ * Preload (`node --require`) voor child processes die zelf URL's ophalen, zoals de Redocly CLI bij het
+ * volgen van externe `$ref`s. Elke TCP-verbinding valt daarmee onder het beleid van `outboundPolicy.js`,
+ * behalve die met de proxy uit `OAS_RESOLVE_HTTP_PROXY`; `resolverHttp.js` toetst dan elke URL zelf.
+ */
+const { guardSocketConnect } = require("./outboundPolicy");
+const { PROXY_ENV } = require("./resolverHttp");
+
+const proxyHost = (proxy) => {
+  if (!proxy) {
+    return [];
+  }
+  const url = new URL(proxy);
+  const port = url.port || (url.protocol === "https:" ? 443 : 80);
+  return [`${url.hostname.replace(/^\[|\]$/g, "").toLowerCase()}:${port}`];
+};
+
+guardSocketConnect(undefined, { trusted: proxyHost(process.env[PROXY_ENV]) });
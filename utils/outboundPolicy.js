@@ -0,0 +1,284 @@
+const dns = require("node:dns");
+const http = require("node:http");
+const https = require("node:https");
+const net = require("node:net");
+const path = require("node:path");
+
+const DEFAULT_SCHEMES = ["https", "http"];
+const DEFAULT_PORTS = [80, 443, 8080, 8443];
+const DEFAULT_PORT_BY_SCHEME = { "http:": 80, "https:": 443 };
+
+// Loopback, privé-netwerken, link-local (waaronder 169.254.169.254 van cloud-metadata), CGNAT,
+// documentatie-, benchmark-, multicast- en gereserveerde ranges. NAT64 en 6to4 bevatten een IPv4-adres
+// (ook een intern adres) in het IPv6-adres en zijn daarom helemaal dicht.
+const BLOCKED_RANGES = [
+  ["0.0.0.0", 8, "ipv4"],
+  ["10.0.0.0", 8, "ipv4"],
+  ["100.64.0.0", 10, "ipv4"],
+  ["127.0.0.0", 8, "ipv4"],
+  ["169.254.0.0", 16, "ipv4"],
+  ["172.16.0.0", 12, "ipv4"],
+  ["192.0.0.0", 24, "ipv4"],
+  ["192.0.2.0", 24, "ipv4"],
+  ["192.168.0.0", 16, "ipv4"],
+  ["198.18.0.0", 15, "ipv4"],
+  ["198.51.100.0", 24, "ipv4"],
+  ["203.0.113.0", 24, "ipv4"],
+  ["224.0.0.0", 4, "ipv4"],
+  ["240.0.0.0", 4, "ipv4"],
+  ["::", 128, "ipv6"],
+  ["::1", 128, "ipv6"],
+  ["fc00::", 7, "ipv6"],
+  ["fe80::", 10, "ipv6"],
+  ["ff00::", 8, "ipv6"],
+  ["2001:db8::", 32, "ipv6"],
+  ["64:ff9b::", 96, "ipv6"],
+  ["64:ff9b:1::", 48, "ipv6"],
+  ["2002::", 16, "ipv6"],
+];
+
+const blockedAddresses = new net.BlockList();
+for (const [address, prefix, type] of BLOCKED_RANGES) {
+  blockedAddresses.addSubnet(address, prefix, type);
+}
+
+const OUTBOUND_POLICY_ERROR_CODE = "ERR_OUTBOUND_POLICY";
+
+class OutboundPolicyError extends Error {
+  constructor(message) {
+    super(message);
+    this.name = "OutboundPolicyError";
+    this.code = OUTBOUND_POLICY_ERROR_CODE;
+  }
+}
+
+/**
+ * Vindt een beleidsfout, ook als fetch die bij het verbinden heeft verpakt: undici zet de fout van de
+ * lookup in `cause`, node-fetch maakt er een FetchError van die alleen `code` overneemt.
+ */
+const findOutboundPolicyError = (error) => {
+  for (let current = error; current; current = current.cause) {
+    if (current instanceof OutboundPolicyError || current.code === OUTBOUND_POLICY_ERROR_CODE) {
+      return current;
+    }
+  }
+  return null;
+};
+
+const parseList = (value) =>
+  String(value ?? "")
+    .split(",")
+    .map((entry) => entry.trim().toLowerCase())
+    .filter(Boolean);
+
+const parseBoolean = (value) => ["1", "true", "yes", "on"].includes(String(value ?? "").toLowerCase());
+
+/**
+ * Het beleid voor uitgaand verkeer naar URL's die een aanroeper meegeeft (oasUrl, callbackUrl en externe
+ * `$ref`s). Interne adressen zijn standaard geblokkeerd; de lijsten bevatten hostnamen (`api.example.nl`),
+ * wildcards (`*.example.nl`), IP-adressen of CIDR-ranges (`10.20.0.0/16`).
+ */
+const loadOutboundPolicy = (env = process.env) => {
+  const schemes = parseList(env.OUTBOUND_ALLOWED_SCHEMES);
+  const ports = parseList(env.OUTBOUND_ALLOWED_PORTS).map(Number).filter(Number.isInteger);
+  return {
+    schemes: new Set(schemes.length > 0 ? schemes : DEFAULT_SCHEMES),
+    ports: new Set(ports.length > 0 ? ports : DEFAULT_PORTS),
+    allowPrivate: parseBoolean(env.OUTBOUND_ALLOW_PRIVATE),
+    allowlist: parseList(env.OUTBOUND_ALLOWLIST),
+    denylist: parseList(env.OUTBOUND_DENYLIST),
+  };
+};
+
+const stripBrackets = (hostname) => hostname.replace(/^\[|\]$/g, "");
+
+// `::ffff:10.0.0.1` (ook als `::ffff:a00:1`) is een IPv4-adres in IPv6-vorm.
+const embeddedIpv4 = (address) => {
+  const match = /^::ffff:(?:(\d+\.\d+\.\d+\.\d+)|([0-9a-f]{1,4}):([0-9a-f]{1,4}))$/i.exec(address);
+  if (!match) {
+    return undefined;
+  }
+  if (match[1]) {
+    return match[1];
+  }
+  const high = Number.parseInt(match[2], 16);
+  const low = Number.parseInt(match[3], 16);
+  return [high >> 8, high & 255, low >> 8, low & 255].join(".");
+};
+
+const isBlockedAddress = (address) => {
+  const ipv4 = embeddedIpv4(address);
+  if (ipv4) {
+    return blockedAddresses.check(ipv4, "ipv4");
+  }
+  const family = net.isIP(address);
+  return family === 0 || blockedAddresses.check(address, family === 4 ? "ipv4" : "ipv6");
+};
+
+const matchesAddress = (entry, address) => {
+  const [base, prefix] = entry.split("/");
+  const family = net.isIP(base);
+  if (family === 0) {
+    return false;
+  }
+  const type = family === 4 ? "ipv4" : "ipv6";
+  const candidate = family === 4 ? (embeddedIpv4(address) ?? address) : address;
+  if (net.isIP(candidate) !== family) {
+    return false;
+  }
+  const list = new net.BlockList();
+  if (prefix === undefined) {
+    list.addAddress(base, type);
+  } else {
+    list.addSubnet(base, Number(prefix), type);
+  }
+  return list.check(candidate, type);
+};
+
+const matchesHost = (entry, hostname) =>
+  entry.startsWith("*.") ? hostname.endsWith(entry.slice(1)) : entry === hostname || matchesAddress(entry, hostname);
+
+const listed = (list, hostname, addresses) =>
+  list.some((entry) => matchesHost(entry, hostname) || addresses.some((address) => matchesAddress(entry, address)));
+
+/**
+ * Toetst een host met de adressen waar die naar wijst. De denylist gaat altijd voor. Staat er iets op
+ * de allowlist, dan mag alleen wat daarop staat, ook als het een intern adres is (expliciet vertrouwd).
+ */
+const checkAddresses = (hostname, port, addresses, policy) => {
+  if (port !== undefined && !policy.ports.has(port)) {
+    throw new OutboundPolicyError(`Poort ${port} is niet toegestaan.`);
+  }
+  if (listed(policy.denylist, hostname, addresses)) {
+    throw new OutboundPolicyError(`${hostname} staat op de denylist.`);
+  }
+  if (policy.allowlist.length > 0) {
+    if (!listed(policy.allowlist, hostname, addresses)) {
+      throw new OutboundPolicyError(`${hostname} staat niet op de allowlist.`);
+    }
+    return;
+  }
+  const blocked = addresses.find(isBlockedAddress);
+  if (blocked !== undefined && !policy.allowPrivate) {
+    throw new OutboundPolicyError(`${hostname} wijst naar een intern adres (${blocked}).`);
+  }
+};
+
+const parseOutboundUrl = (url, policy) => {
+  let parsed;
+  try {
+    parsed = url instanceof URL ? url : new URL(String(url));
+  } catch {
+    throw new OutboundPolicyError("Geen geldige URL.");
+  }
+  if (!policy.schemes.has(parsed.protocol.replace(/:$/, ""))) {
+    throw new OutboundPolicyError(`Het schema ${parsed.protocol} is niet toegestaan.`);
+  }
+  const port = parsed.port ? Number(parsed.port) : DEFAULT_PORT_BY_SCHEME[parsed.protocol];
+  return { hostname: stripBrackets(parsed.hostname.toLowerCase()), port };
+};
+
+/**
+ * Controleert een URL zonder DNS: schema, poort, de lijsten en een IP-adres als host. Voor een hostnaam
+ * volgt de controle van de adressen bij het verbinden (zie `createGuardedLookup`).
+ */
+const checkUrl = (url, policy = loadOutboundPolicy()) => {
+  const { hostname, port } = parseOutboundUrl(url, policy);
+  checkAddresses(hostname, port, net.isIP(hostname) ? [hostname] : [], policy);
+};
+
+// Als `checkUrl`, maar zoekt ook de adressen van de hostnaam op en toetst die.
+const checkResolvedUrl = async (url, policy = loadOutboundPolicy(), lookup = dns.promises.lookup) => {
+  const { hostname, port } = parseOutboundUrl(url, policy);
+  const addresses = net.isIP(hostname)
+    ? [hostname]
+    : (await lookup(hostname, { all: true })).map(({ address }) => address);
+  checkAddresses(hostname, port, addresses, policy);
+};
+
+/**
+ * Een `lookup` voor sockets die elk opgezocht adres toetst op het moment van verbinden. Zo helpt een
+ * hostnaam die na de controle naar een intern adres gaat wijzen (DNS rebinding) niet. Zonder `port`
+ * blijft de poort buiten beschouwing.
+ */
+const createGuardedLookup =
+  (policy = loadOutboundPolicy(), port = undefined, lookup = dns.lookup) =>
+  (hostname, options, callback) => {
+    const done = typeof options === "function" ? options : callback;
+    const lookupOptions = typeof options === "function" ? {} : { ...options };
+    lookup(hostname, { ...lookupOptions, all: true }, (error, results) => {
+      if (error) {
+        done(error);
+        return;
+      }
+      try {
+        const addresses = results.map(({ address }) => address);
+        checkAddresses(hostname.toLowerCase(), port, addresses, policy);
+      } catch (policyError) {
+        done(policyError);
+        return;
+      }
+      if (lookupOptions.all) {
+        done(null, results);
+      } else {
+        done(null, results[0].address, results[0].family);
+      }
+    });
+  };
+
+/**
+ * Agents voor node-fetch (`agent` mag een functie van de URL zijn) die bij het verbinden het beleid
+ * toepassen. De poort is dan al door `checkUrl` gecontroleerd.
+ */
+const createOutboundAgent = (policy = loadOutboundPolicy()) => {
+  const lookup = createGuardedLookup(policy);
+  const agents = { "http:": new http.Agent({ lookup }), "https:": new https.Agent({ lookup }) };
+  return (url) => agents[url.protocol];
+};
+
+/**
+ * Past het beleid toe op elke TCP-verbinding van het proces door `net.Socket.prototype.connect` te
+ * omhullen. Alleen bedoeld voor child processes die zelf URL's ophalen (zie `outboundGuard.js`).
+ * Verbindingen met `trusted` (`host:poort`, zoals een door de beheerder ingestelde proxy) zijn vrij.
+ */
+const guardSocketConnect = (policy = loadOutboundPolicy(), { trusted = [] } = {}) => {
+  const connect = net.Socket.prototype.connect;
+  net.Socket.prototype.connect = function guardedConnect(...args) {
+    const normalized = Array.isArray(args[0]) ? args[0] : args;
+    const options = normalized[0];
+    if (options && typeof options === "object" && typeof options.host === "string" && !options.path) {
+      const hostname = stripBrackets(options.host.toLowerCase());
+      const port = Number(options.port);
+      if (trusted.includes(`${hostname}:${port}`)) {
+        return connect.apply(this, args);
+      }
+      try {
+        if (net.isIP(hostname)) {
+          checkAddresses(hostname, port, [hostname], policy);
+        } else {
+          options.lookup = createGuardedLookup(policy, port, options.lookup ?? dns.lookup);
+        }
+      } catch (error) {
+        process.nextTick(() => this.destroy(error));
+        return this;
+      }
+    }
+    return connect.apply(this, args);
+  };
+};
+
+// Node-argumenten voor een child process (zoals de Redocly CLI) dat externe `$ref`s ophaalt.
+const OUTBOUND_GUARD_ARGS = ["--require", path.join(__dirname, "outboundGuard.js")];
+
+module.exports = {
+  OUTBOUND_GUARD_ARGS,
+  OutboundPolicyError,
+  checkResolvedUrl,
+  checkUrl,
+  createGuardedLookup,
+  createOutboundAgent,
+  findOutboundPolicyError,
+  guardSocketConnect,
+  isBlockedAddress,
+  loadOutboundPolicy,
+};
@@ -0,0 +1,78 @@
+const { dependencies, version: TOOL_VERSION } = require("../package.json");
+
+const GENERATOR = "don-tools-api";
+const PROVENANCE_EXTENSION = "x-don-generated";
+// De ADR-rulesets komen van een vaste commit; die commit is de versie van de regels.
+const RULESET_VERSION = `adr-rulesets#${dependencies["@developer-overheid-nl/adr-rulesets"].split("#").pop()}`;
+
+// Een URL verliest zijn query, fragment en gebruikersgegevens: daar staan soms tokens in, en de
+// provenance komt in gepubliceerde artifacts terecht.
+const describeSource = (source) => {
+  if (!source) {
+    return "request-body";
+  }
+  let url;
+  try {
+    url = new URL(source);
+  } catch {
+    return source;
+  }
+  if (url.protocol !== "http:" && url.protocol !== "https:") {
+    return source;
+  }
+  url.username = "";
+  url.password = "";
+  url.search = "";
+  url.hash = "";
+  return url.toString();
+};
+
+/**
+ * Beschrijft hoe een artifact tot stand is gekomen: welke tool (en versie) het maakte, met welke versie
+ * van de ADR-rulesets, van welke bron en wanneer. `source` is de opgehaalde URL (zonder query) of
+ * `request-body`; extra velden (zoals de doelversie van een conversie) worden alleen opgenomen als ze
+ * een waarde hebben.
+ */
+const buildProvenance = ({ tool, source, details = {}, now = new Date() }) => {
+  const provenance = {
+    generator: GENERATOR,
+    version: TOOL_VERSION,
+    rulesetVersion: RULESET_VERSION,
+    tool,
+    source: describeSource(source),
+    generatedAt: now.toISOString(),
+  };
+  for (const [key, value] of Object.entries(details)) {
+    if (value !== undefined && value !== null && value !== "") {
+      provenance[key] = value;
+    }
+  }
+  return provenance;
+};
+
+/**
+ * Zet de provenance als `x-don-generated` extensie op het hoogste niveau van het document. Een
+ * bestaande extensie (van een eerdere bewerking) wordt overschreven, zodat altijd de laatste stap
+ * beschreven staat.
+ */
+const stampDocument = (document, provenance) => {
+  if (document && typeof document === "object" && !Array.isArray(document)) {
+    document[PROVENANCE_EXTENSION] = provenance;
+  }
+  return document;
+};
+
+const describeProvenance = (provenance) =>
+  Object.entries(provenance)
+    .map(([key, value]) => `${key}=${value}`)
+    .join(" ");
+
+module.exports = {
+  GENERATOR,
+  PROVENANCE_EXTENSION,
+  RULESET_VERSION,
+  TOOL_VERSION,
+  buildProvenance,
+  describeProvenance,
+  stampDocument,
+};
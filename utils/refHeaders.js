@@ -0,0 +1,63 @@
+const path = require("node:path");
+const { loadResolverHttpOptions } = require("./resolverHttp");
+
+// Zo gaan de headers per host naar het child process; de waarden komen niet op de command line of in een bestand.
+const REF_HEADERS_ENV = "OAS_RESOLVE_REF_HEADERS";
+const REDIRECT_STATUSES = new Set([301, 302, 303, 307, 308]);
+const HOST_PATTERN = /^(\*\.)?[a-z0-9-]+(\.[a-z0-9-]+)*(:\d{1,5})?$/;
+
+const isHostPattern = (value) => HOST_PATTERN.test(value);
+
+// `api.example.nl` past op precies die host, `*.example.nl` op elk subdomein; met een poort erbij telt ook die.
+const matchesHost = (pattern, url) => {
+  const host = pattern.includes(":") ? url.host : url.hostname;
+  return pattern.startsWith("*.") ? host.endsWith(pattern.slice(1)) : host === pattern;
+};
+
+const headersFor = (rules, url) =>
+  Object.assign(
+    {},
+    ...Object.entries(rules)
+      .filter(([pattern]) => matchesHost(pattern, url))
+      .map(([, headers]) => headers),
+  );
+
+/**
+ * Omhult een `fetch` zodat elk verzoek de headers krijgt van de hosts waar de URL op past. Redirects
+ * worden hier gevolgd, zodat de headers per stap opnieuw bepaald worden en niet meegaan naar een andere host.
+ */
+const withRefHeaders = (fetchImpl, rules, { maxRedirects = 5 } = {}) => async (resource, init = {}) => {
+  let url = new URL(resource?.url ?? String(resource));
+  for (let redirects = 0; ; redirects += 1) {
+    const headers = new Headers(init.headers);
+    for (const [name, value] of Object.entries(headersFor(rules, url))) {
+      headers.set(name, value);
+    }
+    const response = await fetchImpl(url, { ...init, headers, redirect: "manual" });
+    const location = response.headers.get("location");
+    if (!REDIRECT_STATUSES.has(response.status) || !location || redirects >= maxRedirects) {
+      return response;
+    }
+    url = new URL(location, url);
+  }
+};
+
+// Voor de preload (zie `refHeadersPreload.js`): de headers per host uit de omgeving van het child process.
+const applyRefHeaders = (env = process.env) => {
+  const rules = JSON.parse(env[REF_HEADERS_ENV] || "{}");
+  if (Object.keys(rules).length > 0) {
+    globalThis.fetch = withRefHeaders(globalThis.fetch, rules, loadResolverHttpOptions(env));
+  }
+};
+
+// Node-argumenten voor een child process dat externe `$ref`s met deze headers ophaalt.
+const REF_HEADERS_ARGS = ["--require", path.join(__dirname, "refHeadersPreload.js")];
+
+module.exports = {
+  REF_HEADERS_ARGS,
+  REF_HEADERS_ENV,
+  applyRefHeaders,
+  headersFor,
+  isHostPattern,
+  withRefHeaders,
+};
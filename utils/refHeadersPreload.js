@@ -0,0 +1,7 @@
+/**
+ * Preload (`node --require`) voor de Redocly CLI bij bundelen en dereferencen: externe `$ref`s op een
+ * host uit `refHeaders` worden met de bijbehorende headers opgehaald (zie `refHeaders.js`).
+ */
+const { applyRefHeaders } = require("./refHeaders");
+
+applyRefHeaders();
@@ -0,0 +1,189 @@
+const dns = require("node:dns");
+const http = require("node:http");
+const https = require("node:https");
+const net = require("node:net");
+const path = require("node:path");
+const { setTimeout: sleep } = require("node:timers/promises");
+const tls = require("node:tls");
+const { checkUrl, createGuardedLookup, loadOutboundPolicy } = require("./outboundPolicy");
+
+const REDIRECT_STATUSES = new Set([301, 302, 303, 307, 308]);
+const RETRY_STATUSES = new Set([408, 429, 500, 502, 503, 504]);
+const MAX_RETRY_DELAY_MS = 30000;
+
+// Per instelling de omgevingsvariabele, de standaardwaarde en de grenzen voor een override per request.
+const HTTP_SETTINGS = {
+  timeoutMs: { env: "OAS_RESOLVE_HTTP_TIMEOUT_MS", fallback: 20000, min: 1000, max: 120000 },
+  retries: { env: "OAS_RESOLVE_HTTP_RETRIES", fallback: 2, min: 0, max: 5 },
+  backoffMs: { env: "OAS_RESOLVE_HTTP_BACKOFF_MS", fallback: 500, min: 0, max: 10000 },
+  maxRedirects: { env: "OAS_RESOLVE_HTTP_MAX_REDIRECTS", fallback: 5, min: 0, max: 10 },
+};
+const PROXY_ENV = "OAS_RESOLVE_HTTP_PROXY";
+
+const isAllowedValue = (name, value) =>
+  Number.isInteger(value) && value >= HTTP_SETTINGS[name].min && value <= HTTP_SETTINGS[name].max;
+
+/**
+ * Instellingen van de HTTP-client waarmee de Redocly CLI externe `$ref`s ophaalt: de timeout per poging,
+ * het aantal herhalingen met exponentiële backoff, het maximale aantal redirects en een proxy. Een
+ * ongeldige waarde valt terug op de standaard.
+ */
+const loadResolverHttpOptions = (env = process.env) => {
+  const options = {};
+  for (const [name, setting] of Object.entries(HTTP_SETTINGS)) {
+    const value = env[setting.env] === undefined || env[setting.env] === "" ? Number.NaN : Number(env[setting.env]);
+    options[name] = isAllowedValue(name, value) ? value : setting.fallback;
+  }
+  options.proxy = env[PROXY_ENV] || undefined;
+  return options;
+};
+
+// De omgevingsvariabelen voor het child process met de overrides uit een request.
+const resolverHttpEnv = (overrides) =>
+  Object.fromEntries(Object.entries(overrides).map(([name, value]) => [HTTP_SETTINGS[name].env, String(value)]));
+
+// Retry-After in seconden of als datum; zonder (geldige) header de exponentiële backoff.
+const retryDelay = (response, attempt, backoffMs) => {
+  const retryAfter = response?.headers.get("retry-after");
+  const seconds = Number(retryAfter);
+  let delay = backoffMs * 2 ** attempt;
+  if (retryAfter && Number.isFinite(seconds)) {
+    delay = seconds * 1000;
+  } else if (retryAfter && !Number.isNaN(Date.parse(retryAfter))) {
+    delay = Date.parse(retryAfter) - Date.now();
+  }
+  return Math.min(Math.max(delay, 0), MAX_RETRY_DELAY_MS);
+};
+
+const proxyAuthorization = (proxyUrl) => {
+  if (!proxyUrl.username) {
+    return {};
+  }
+  const credentials = `${decodeURIComponent(proxyUrl.username)}:${decodeURIComponent(proxyUrl.password)}`;
+  return { "proxy-authorization": `Basic ${Buffer.from(credentials).toString("base64")}` };
+};
+
+/**
+ * Een undici-connector die via de proxy een CONNECT-tunnel opent naar het adres dat hier is opgezocht en
+ * getoetst, niet naar de hostnaam. Anders zoekt de proxy de hostnaam zelf nog eens op en kan die tussen
+ * de controle en de verbinding naar een intern adres gaan wijzen (DNS rebinding). TLS gaat daarna door de
+ * tunnel met de hostnaam als servername, zodat het certificaat gewoon wordt gecontroleerd.
+ */
+const connectThroughProxy = (proxy, { policy = loadOutboundPolicy(), lookup = dns.lookup } = {}) => {
+  const proxyUrl = new URL(proxy);
+  const transport = proxyUrl.protocol === "https:" ? https : http;
+  return (options, callback) => {
+    let settled = false;
+    const done = (error, socket) => {
+      if (!settled) {
+        settled = true;
+        callback(error, error ? null : socket);
+      }
+    };
+    const hostname = options.hostname.replace(/^\[|\]$/g, "");
+    const port = Number(options.port) || (options.protocol === "https:" ? 443 : 80);
+    createGuardedLookup(policy, port, lookup)(hostname, {}, (error, address, family) => {
+      if (error) {
+        done(error);
+        return;
+      }
+      const target = `${family === 6 ? `[${address}]` : address}:${port}`;
+      const request = transport.request({
+        host: proxyUrl.hostname,
+        port: proxyUrl.port || (proxyUrl.protocol === "https:" ? 443 : 80),
+        method: "CONNECT",
+        path: target,
+        headers: { host: target, ...proxyAuthorization(proxyUrl) },
+      });
+      request.once("error", done);
+      request.once("connect", (response, socket) => {
+        if (response.statusCode !== 200) {
+          socket.destroy();
+          done(new Error(`De proxy weigerde de verbinding met ${hostname} (status ${response.statusCode}).`));
+          return;
+        }
+        if (options.protocol !== "https:") {
+          done(null, socket);
+          return;
+        }
+        const servername = net.isIP(hostname) ? undefined : (options.servername ?? hostname);
+        const secure = tls.connect({ socket, servername, ALPNProtocols: ["http/1.1"] });
+        secure.once("secureConnect", () => done(null, secure));
+        secure.once("error", done);
+      });
+      request.end();
+    });
+  };
+};
+
+const createProxyDispatcher = (proxy) => {
+  const { Agent } = require("undici");
+  return new Agent({ connect: connectThroughProxy(proxy) });
+};
+
+/**
+ * Omhult een `fetch` met de instellingen van `loadResolverHttpOptions`. Een netwerkfout, timeout of
+ * status als 503 wordt herhaald: een document ophalen is een GET en dus veilig te herhalen. Redirects worden
+ * hier gevolgd, behalve als de aanroeper ze zelf volgt (`redirect: "manual"`, zie `refHeaders.js`). Via
+ * een proxy ziet de socket-guard alleen de proxy; dan toetst `connectThroughProxy` elk adres.
+ */
+const withResolverHttp = (fetchImpl, options = loadResolverHttpOptions(), { createDispatcher } = {}) => {
+  const dispatcher = options.proxy ? (createDispatcher ?? createProxyDispatcher)(options.proxy) : undefined;
+  const attempt = async (url, init) => {
+    for (let retry = 0; ; retry += 1) {
+      if (dispatcher) {
+        checkUrl(url);
+      }
+      const timeout = AbortSignal.timeout(options.timeoutMs);
+      const signal = init.signal ? AbortSignal.any([init.signal, timeout]) : timeout;
+      let response;
+      try {
+        response = await fetchImpl(url, { ...init, signal, redirect: "manual", ...(dispatcher ? { dispatcher } : {}) });
+      } catch (error) {
+        const blocked = [error?.name, error?.cause?.name].includes("OutboundPolicyError");
+        if (init.signal?.aborted || blocked || retry >= options.retries) {
+          throw error;
+        }
+      }
+      if (response && (!RETRY_STATUSES.has(response.status) || retry >= options.retries)) {
+        return response;
+      }
+      await response?.body?.cancel?.().catch(() => {});
+      await sleep(retryDelay(response, retry, options.backoffMs));
+    }
+  };
+  return async (resource, init = {}) => {
+    let url = new URL(resource?.url ?? String(resource));
+    for (let redirects = 0; ; redirects += 1) {
+      const response = await attempt(url, init);
+      const location = response.headers.get("location");
+      if (init.redirect === "manual" || !REDIRECT_STATUSES.has(response.status) || !location) {
+        return response;
+      }
+      if (redirects >= options.maxRedirects) {
+        throw new Error(`Meer dan ${options.maxRedirects} redirects voor ${url.href}.`);
+      }
+      url = new URL(location, url);
+    }
+  };
+};
+
+// Voor de preload (zie `resolverHttpPreload.js`).
+const applyResolverHttp = (env = process.env) => {
+  globalThis.fetch = withResolverHttp(globalThis.fetch, loadResolverHttpOptions(env));
+};
+
+// Node-argumenten voor een child process dat externe `$ref`s met deze client ophaalt.
+const RESOLVER_HTTP_ARGS = ["--require", path.join(__dirname, "resolverHttpPreload.js")];
+
+module.exports = {
+  HTTP_SETTINGS,
+  PROXY_ENV,
+  RESOLVER_HTTP_ARGS,
+  applyResolverHttp,
+  connectThroughProxy,
+  isAllowedValue,
+  loadResolverHttpOptions,
+  resolverHttpEnv,
+  withResolverHttp,
+};
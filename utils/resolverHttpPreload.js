@@ -0,0 +1,7 @@
+/**
+ * Preload (`node --require`) voor de Redocly CLI bij bundelen en dereferencen: externe `$ref`s worden
+ * opgehaald met de timeout, herhalingen, redirects en proxy van `resolverHttp.js`.
+ */
+const { applyResolverHttp } = require("./resolverHttp");
+
+applyResolverHttp();
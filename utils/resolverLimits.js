@@ -0,0 +1,180 @@
+const crypto = require("node:crypto");
+const fs = require("node:fs");
+const path = require("node:path");
+
+const DEFAULT_MAX_DOCUMENTS = 50;
+const DEFAULT_MAX_BYTES = 20 * 1024 * 1024;
+const DEFAULT_MAX_DEPTH = 256;
+const DEFAULT_TIMEOUT_MS = 60000;
+
+// Een child process dat een limiet overschrijdt, stopt met deze exitcode en meldt de limiet als JSON
+// op één regel in stderr, achter deze markering.
+const LIMIT_EXIT_CODE = 86;
+const LIMIT_MARKER = "resolver-limit:";
+const MANIFEST_ENV = "OAS_RESOLVE_MANIFEST";
+
+class ResolverLimitError extends Error {
+  constructor(limit, message) {
+    super(message);
+    this.name = "ResolverLimitError";
+    this.limit = limit;
+  }
+}
+
+const positiveInteger = (value, fallback) => {
+  const parsed = Number(value);
+  return Number.isInteger(parsed) && parsed > 0 ? parsed : fallback;
+};
+
+/**
+ * Limieten voor het oplossen van externe `$ref`s bij bundelen en dereferencen: het aantal opgehaalde
+ * documenten, het totaal aantal bytes, de diepte van het resultaat en de totale tijd.
+ */
+const loadResolverLimits = (env = process.env) => ({
+  maxDocuments: positiveInteger(env.OAS_RESOLVE_MAX_DOCUMENTS, DEFAULT_MAX_DOCUMENTS),
+  maxBytes: positiveInteger(env.OAS_RESOLVE_MAX_BYTES, DEFAULT_MAX_BYTES),
+  maxDepth: positiveInteger(env.OAS_RESOLVE_MAX_DEPTH, DEFAULT_MAX_DEPTH),
+  timeoutMs: positiveInteger(env.OAS_RESOLVE_TIMEOUT_MS, DEFAULT_TIMEOUT_MS),
+});
+
+const documentsExceeded = (limits) =>
+  new ResolverLimitError(
+    "maxDocuments",
+    `Meer dan ${limits.maxDocuments} externe documenten nodig (OAS_RESOLVE_MAX_DOCUMENTS).`,
+  );
+
+const bytesExceeded = (limits) =>
+  new ResolverLimitError(
+    "maxBytes",
+    `Meer dan ${limits.maxBytes} bytes aan externe documenten (OAS_RESOLVE_MAX_BYTES).`,
+  );
+
+/**
+ * Omhult een `fetch` zodat het aantal documenten en het totaal aantal bytes over alle aanroepen
+ * begrensd is. Een body wordt per chunk geteld, zodat een te groot document niet eerst helemaal binnenkomt.
+ * `onDocument` krijgt per opgehaald document de URL, de HTTP-status, het aantal bytes en de SHA-256.
+ */
+const limitFetch = (
+  fetchImpl,
+  limits,
+  { onExceeded = (error) => Promise.reject(error), onDocument = () => {} } = {},
+) => {
+  let documents = 0;
+  let bytes = 0;
+  return async (resource, init) => {
+    documents += 1;
+    if (documents > limits.maxDocuments) {
+      return onExceeded(documentsExceeded(limits));
+    }
+    const response = await fetchImpl(resource, init);
+    if (Number(response.headers.get("content-length")) > limits.maxBytes - bytes) {
+      return onExceeded(bytesExceeded(limits));
+    }
+    const chunks = [];
+    if (response.body) {
+      for await (const chunk of response.body) {
+        bytes += chunk.length;
+        if (bytes > limits.maxBytes) {
+          return onExceeded(bytesExceeded(limits));
+        }
+        chunks.push(chunk);
+      }
+    }
+    const body = Buffer.concat(chunks);
+    onDocument({
+      url: String(resource?.url ?? resource),
+      status: response.status,
+      bytes: body.length,
+      sha256: crypto.createHash("sha256").update(body).digest("hex"),
+    });
+    return new Response(response.body ? body : null, {
+      status: response.status,
+      statusText: response.statusText,
+      headers: response.headers,
+    });
+  };
+};
+
+/**
+ * Begrenst de `fetch` van een child process (zie `resolverLimitsGuard.js`). Bij een overschreden limiet
+ * stopt het proces direct: de resolver zou de fout anders als ontbrekende `$ref` laten staan. Met
+ * `OAS_RESOLVE_MANIFEST` komt elk opgehaald document als JSON-regel in dat bestand.
+ */
+const guardResolverFetch = (limits = loadResolverLimits(), manifestPath = process.env[MANIFEST_ENV]) => {
+  globalThis.fetch = limitFetch(globalThis.fetch, limits, {
+    onExceeded: (error) => {
+      process.stderr.write(`${LIMIT_MARKER}${JSON.stringify({ limit: error.limit, message: error.message })}\n`);
+      process.exit(LIMIT_EXIT_CODE);
+    },
+    onDocument: (entry) => {
+      if (manifestPath) {
+        fs.appendFileSync(manifestPath, `${JSON.stringify(entry)}\n`);
+      }
+    },
+  });
+};
+
+// De documenten die een child process volgens het manifest heeft opgehaald, op URL gesorteerd.
+const readManifest = async (manifestPath) => {
+  const text = await fs.promises.readFile(manifestPath, "utf8").catch(() => "");
+  return text
+    .split("\n")
+    .filter(Boolean)
+    .map((line) => JSON.parse(line))
+    .sort((a, b) => a.url.localeCompare(b.url));
+};
+
+// Leest de limiet terug uit een child process dat met `LIMIT_EXIT_CODE` stopte.
+const readLimitError = (error) => {
+  if (error?.code !== LIMIT_EXIT_CODE) {
+    return undefined;
+  }
+  const line = String(error.stderr ?? "")
+    .split("\n")
+    .find((entry) => entry.startsWith(LIMIT_MARKER));
+  try {
+    const { limit, message } = JSON.parse(line.slice(LIMIT_MARKER.length));
+    return new ResolverLimitError(limit, message);
+  } catch {
+    return new ResolverLimitError("unknown", "Een limiet voor externe documenten is overschreden.");
+  }
+};
+
+/**
+ * Gooit een ResolverLimitError als het document dieper genest is dan `maxDepth`. Een object dat al
+ * op deze diepte of hoger is bezocht (een gedeeld deel of een kringverwijzing) wordt niet opnieuw doorlopen.
+ */
+const assertDepth = (document, limits) => {
+  const seen = new Map();
+  const visit = (node, depth) => {
+    if (node === null || typeof node !== "object" || seen.get(node) <= depth) {
+      return;
+    }
+    if (depth > limits.maxDepth) {
+      throw new ResolverLimitError(
+        "maxDepth",
+        `Het resultaat is dieper genest dan ${limits.maxDepth} niveaus (OAS_RESOLVE_MAX_DEPTH).`,
+      );
+    }
+    seen.set(node, depth);
+    for (const child of Object.values(node)) {
+      visit(child, depth + 1);
+    }
+  };
+  visit(document, 0);
+};
+
+// Node-argumenten voor een child process dat externe `$ref`s ophaalt binnen deze limieten.
+const RESOLVER_LIMIT_ARGS = ["--require", path.join(__dirname, "resolverLimitsGuard.js")];
+
+module.exports = {
+  MANIFEST_ENV,
+  RESOLVER_LIMIT_ARGS,
+  ResolverLimitError,
+  assertDepth,
+  guardResolverFetch,
+  limitFetch,
+  loadResolverLimits,
+  readLimitError,
+  readManifest,
+};
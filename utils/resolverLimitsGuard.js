@@ -0,0 +1,7 @@
+/**
+ * Preload (`node --require`) voor de Redocly CLI bij bundelen en dereferencen: de `fetch` waarmee
+ * externe `$ref`s worden opgehaald, valt onder de limieten van `resolverLimits.js`.
+ */
+const { guardResolverFetch } = require("./resolverLimits");
+
+guardResolverFetch();
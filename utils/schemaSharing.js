@@ -0,0 +1,249 @@
+const crypto = require("node:crypto");
+const { encodePointerSegment } = require("./jsonPointer");
+const { isSwagger2 } = require("./swagger");
+
+// Zoals in `dereference.js`: waarden onder deze sleutels zijn data, geen schema's.
+const DATA_KEYS = new Set(["example", "default", "const", "enum"]);
+// Sleutels waaronder een schema weer schema's bevat: als map, als lijst of als één schema.
+const SCHEMA_MAPS = new Set(["properties", "patternProperties", "dependentSchemas", "$defs", "definitions"]);
+const SCHEMA_LISTS = new Set(["allOf", "anyOf", "oneOf", "prefixItems"]);
+const SCHEMA_VALUES = new Set([
+  "items",
+  "additionalItems",
+  "additionalProperties",
+  "unevaluatedItems",
+  "unevaluatedProperties",
+  "contains",
+  "propertyNames",
+  "not",
+  "if",
+  "then",
+  "else",
+]);
+// Kleine schema's zoals `{ type: string, format: date }` blijven inline; een `$ref` maakt die niet kleiner.
+const DEFAULT_MIN_SIZE = 6;
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+const isDataKey = (key, value) =>
+  DATA_KEYS.has(key) || key.startsWith("x-") || (key === "examples" && Array.isArray(value));
+
+// Roept `visit(schema, hint)` aan voor elk direct subschema; `hint` is de propertynaam als die er is.
+const eachSubschema = (schema, visit) => {
+  for (const [key, value] of Object.entries(schema)) {
+    if (SCHEMA_MAPS.has(key) && isObject(value)) {
+      for (const [name, child] of Object.entries(value)) {
+        visit(child, name);
+      }
+    } else if ((SCHEMA_LISTS.has(key) || SCHEMA_VALUES.has(key)) && Array.isArray(value)) {
+      for (const child of value) {
+        visit(child);
+      }
+    } else if (SCHEMA_VALUES.has(key)) {
+      visit(value);
+    }
+  }
+};
+
+// Hetzelfde als `eachSubschema`, maar geeft een kopie met elk subschema vervangen door `replace(schema, hint)`.
+const mapSubschemas = (schema, replace) =>
+  Object.fromEntries(
+    Object.entries(schema).map(([key, value]) => {
+      if (SCHEMA_MAPS.has(key) && isObject(value)) {
+        return [key, Object.fromEntries(Object.entries(value).map(([name, child]) => [name, replace(child, name)]))];
+      }
+      if ((SCHEMA_LISTS.has(key) || SCHEMA_VALUES.has(key)) && Array.isArray(value)) {
+        return [key, value.map((child) => replace(child))];
+      }
+      if (SCHEMA_VALUES.has(key)) {
+        return [key, replace(value)];
+      }
+      return [key, value];
+    }),
+  );
+
+// Een componentnaam moet voldoen aan `^[a-zA-Z0-9._-]+$`.
+const componentName = (hint) => {
+  const cleaned = String(hint ?? "").replace(/[^A-Za-z0-9._-]/g, "");
+  return cleaned ? `${cleaned[0].toUpperCase()}${cleaned.slice(1)}` : "Schema";
+};
+
+/**
+ * Brengt structureel gelijke schema's in een uitgeschreven document terug naar één component. Na
+ * `dereferenceDocument` staat een gedeeld schema (zoals een foutbericht) op elke plek waar het gebruikt
+ * wordt. Elk schema dat minstens twee keer voorkomt en minstens `minSize` sleutels en items bevat, wordt
+ * een `$ref` naar `components.schemas` (`definitions` bij Swagger 2.0). Bestaat daar al een component met
+ * dezelfde structuur, dan wijst de `$ref` daarnaar; anders komt er een component bij met de `title` of de
+ * propertynaam als naam. Componenten zelf blijven uitgeschreven.
+ *
+ * Geeft het nieuwe document en per gedeeld schema de naam en het aantal plekken dat nu verwijst.
+ */
+const shareIdenticalSchemas = (document, { minSize = DEFAULT_MIN_SIZE } = {}) => {
+  const swagger = isSwagger2(document);
+  const components = (swagger ? document.definitions : document.components?.schemas) ?? {};
+  const refPrefix = swagger ? "#/definitions/" : "#/components/schemas/";
+
+  // Een hash over de volledige inhoud met gesorteerde sleutels, per object één keer berekend: het
+  // uitgeschreven document kan hetzelfde object op veel plekken bevatten.
+  const fingerprints = new WeakMap();
+  const sizes = new Map();
+  const fingerprint = (value) => {
+    if (value === null || typeof value !== "object") {
+      return { hash: JSON.stringify(value) ?? "null", size: 0 };
+    }
+    if (fingerprints.has(value)) {
+      return fingerprints.get(value);
+    }
+    const hash = crypto.createHash("sha256");
+    let size = 0;
+    if (Array.isArray(value)) {
+      hash.update("[");
+      for (const item of value) {
+        const child = fingerprint(item);
+        hash.update(`${child.hash},`);
+        size += child.size + 1;
+      }
+    } else {
+      hash.update("{");
+      for (const key of Object.keys(value).sort()) {
+        const child = fingerprint(value[key]);
+        hash.update(`${JSON.stringify(key)}:${child.hash},`);
+        size += child.size + 1;
+      }
+    }
+    const result = { hash: hash.digest("hex"), size };
+    fingerprints.set(value, result);
+    sizes.set(result.hash, size);
+    return result;
+  };
+
+  // Roept `onSchema(schema)` aan voor elk schema in het document, de componenten als laatste; geeft
+  // `onSchema` false terug, dan worden de subschema's overgeslagen.
+  const walkSchemas = (onSchema) => {
+    const visitSchema = (schema) => {
+      if (isObject(schema) && onSchema(schema) !== false) {
+        eachSubschema(schema, visitSchema);
+      }
+    };
+    const visit = (node) => {
+      if (Array.isArray(node)) {
+        node.forEach((item) => visit(item));
+        return;
+      }
+      if (!isObject(node)) {
+        return;
+      }
+      for (const [childKey, value] of Object.entries(node)) {
+        if (isDataKey(childKey, value) || value === components) {
+          continue;
+        }
+        if (childKey === "schema") {
+          visitSchema(value);
+        } else {
+          visit(value);
+        }
+      }
+    };
+    visit(document);
+    Object.values(components).forEach(visitSchema);
+  };
+
+  // Eerst alle plekken tellen; daarna opnieuw, maar binnen een schema dat gedeeld gaat worden alleen bij
+  // het eerste voorkomen, zodat een subschema dat alleen daarbinnen herhaald wordt inline blijft.
+  const countSchemas = (isShared) => {
+    const counts = new Map();
+    const seen = new Set();
+    walkSchemas((schema) => {
+      const { hash } = fingerprint(schema);
+      counts.set(hash, (counts.get(hash) ?? 0) + 1);
+      if (isShared?.(hash)) {
+        if (seen.has(hash)) {
+          return false;
+        }
+        seen.add(hash);
+      }
+      return true;
+    });
+    return counts;
+  };
+  const candidates = (counts) =>
+    new Set([...counts].filter(([hash, count]) => count >= 2 && sizes.get(hash) >= minSize).map(([hash]) => hash));
+  const initial = candidates(countSchemas());
+  const counts = countSchemas((hash) => initial.has(hash));
+  const shared = candidates(counts);
+
+  const names = new Map();
+  const used = new Set(Object.keys(components));
+  for (const [name, schema] of Object.entries(components)) {
+    const { hash } = fingerprint(schema);
+    if (isObject(schema) && shared.has(hash) && !names.has(hash)) {
+      names.set(hash, name);
+    }
+  }
+  const added = {};
+  const report = new Map();
+  const replaceSchema = (schema, hint) => {
+    if (!isObject(schema)) {
+      return schema;
+    }
+    const { hash } = fingerprint(schema);
+    if (!shared.has(hash)) {
+      return mapSubschemas(schema, replaceSchema);
+    }
+    if (!names.has(hash)) {
+      const base = componentName(schema.title ?? hint);
+      let name = base;
+      for (let counter = 2; used.has(name); counter += 1) {
+        name = `${base}${counter}`;
+      }
+      used.add(name);
+      names.set(hash, name);
+      added[name] = mapSubschemas(schema, replaceSchema);
+    }
+    const name = names.get(hash);
+    report.set(name, (report.get(name) ?? 0) + 1);
+    return { $ref: `${refPrefix}${encodePointerSegment(name)}` };
+  };
+  const rewrite = (node) => {
+    if (Array.isArray(node)) {
+      return node.map((item) => rewrite(item));
+    }
+    if (!isObject(node)) {
+      return node;
+    }
+    return Object.fromEntries(
+      Object.entries(node).map(([childKey, value]) => {
+        if (isDataKey(childKey, value) || value === components) {
+          return [childKey, value];
+        }
+        return [childKey, childKey === "schema" ? replaceSchema(value) : rewrite(value)];
+      }),
+    );
+  };
+
+  const result = rewrite(document);
+  const rewrittenComponents = Object.fromEntries(
+    Object.entries(components).map(([name, schema]) => [
+      name,
+      isObject(schema) ? mapSubschemas(schema, replaceSchema) : schema,
+    ]),
+  );
+  if (report.size === 0) {
+    return { document, shared: [] };
+  }
+  if (swagger) {
+    result.definitions = { ...rewrittenComponents, ...added };
+  } else {
+    result.components = { ...result.components, schemas: { ...rewrittenComponents, ...added } };
+  }
+  return {
+    document: result,
+    shared: [...report]
+      .map(([name, references]) => ({ name, references }))
+      .sort((a, b) => a.name.localeCompare(b.name)),
+  };
+};
+
+module.exports = {
+  shareIdenticalSchemas,
+};
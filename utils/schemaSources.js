@@ -0,0 +1,121 @@
+const crypto = require("node:crypto");
+const path = require("node:path");
+const { decodePointerSegment, encodePointerSegment } = require("./jsonPointer");
+const { parseJsonOrYaml } = require("./yaml");
+
+const SCHEMA_POINTER = /^\/components\/schemas\/([^/]+)(\/.*)?$/;
+const TAGGED_NAME = /^(.+)__src_([0-9a-f]{10})$/;
+// Zoals in `dereference.js`: onder deze sleutels is een `$ref` data, geen verwijzing.
+const DATA_KEYS = new Set(["example", "default", "const", "enum"]);
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+// De URL van een document zonder fragment, zoals die in het manifest staat.
+const documentUrl = (url) => {
+  const parsed = new URL(String(url));
+  parsed.hash = "";
+  return parsed.href;
+};
+
+const sourceTag = (url) => crypto.createHash("sha256").update(documentUrl(url)).digest("hex").slice(0, 10);
+
+// `Adres` uit `https://example.nl/gemeente.yaml` heet tijdens het bundelen `Adres__src_<tag>`.
+const tagName = (name, url) => `${name}__src_${sourceTag(url)}`;
+
+const parseTaggedName = (name) => {
+  const match = TAGGED_NAME.exec(name);
+  return match ? { name: match[1], tag: match[2] } : undefined;
+};
+
+/**
+ * Geeft elk schema in `components.schemas` van een extern document een naam met de bron erin, en past
+ * elke `$ref` naar zo'n schema aan, ook naar een ander extern document. Redocly neemt de laatste stap van
+ * de pointer als componentnaam; zo krijgen twee schema's met dezelfde naam uit verschillende documenten
+ * nooit dezelfde naam. Met `local: false` (het hoofddocument) blijven de eigen schema's en lokale
+ * verwijzingen ongemoeid. Alleen verwijzingen naar een http(s)-URL krijgen een bron.
+ */
+const tagSchemaSources = (document, baseUrl, { local = true } = {}) => {
+  const tagRef = (ref) => {
+    const hashIndex = ref.indexOf("#");
+    const location = hashIndex === -1 ? ref : ref.slice(0, hashIndex);
+    const match = hashIndex === -1 ? null : SCHEMA_POINTER.exec(ref.slice(hashIndex + 1));
+    if (!match || (location === "" && !local)) {
+      return ref;
+    }
+    let target;
+    try {
+      target = new URL(location || baseUrl, baseUrl);
+    } catch {
+      return ref;
+    }
+    if (target.protocol !== "https:" && target.protocol !== "http:") {
+      return ref;
+    }
+    const name = encodePointerSegment(tagName(decodePointerSegment(match[1]), target));
+    return `${location}#/components/schemas/${name}${match[2] ?? ""}`;
+  };
+  const rewrite = (node) => {
+    if (Array.isArray(node)) {
+      return node.map(rewrite);
+    }
+    if (!isObject(node)) {
+      return node;
+    }
+    return Object.fromEntries(
+      Object.entries(node).map(([key, value]) => {
+        if (key === "$ref" && typeof value === "string") {
+          return [key, tagRef(value)];
+        }
+        if (DATA_KEYS.has(key) || key.startsWith("x-") || (key === "examples" && Array.isArray(value))) {
+          return [key, value];
+        }
+        return [key, rewrite(value)];
+      }),
+    );
+  };
+  const result = rewrite(document);
+  if (local && baseUrl && isObject(result?.components?.schemas)) {
+    result.components.schemas = Object.fromEntries(
+      Object.entries(result.components.schemas).map(([name, schema]) => [tagName(name, baseUrl), schema]),
+    );
+  }
+  return result;
+};
+
+/**
+ * Omhult een `fetch` zodat elk opgehaald document met `tagSchemaSources` wordt herschreven (als JSON,
+ * wat ook geldige YAML is). Een antwoord dat geen JSON of YAML is, blijft ongewijzigd.
+ */
+const withSchemaSources = (fetchImpl) => async (resource, init) => {
+  const response = await fetchImpl(resource, init);
+  if (response.status !== 200) {
+    return response;
+  }
+  const text = await response.text();
+  const headers = new Headers(response.headers);
+  headers.delete("content-length");
+  let document;
+  try {
+    ({ document } = parseJsonOrYaml(text));
+  } catch {
+    document = undefined;
+  }
+  if (!isObject(document)) {
+    return new Response(text, { status: response.status, statusText: response.statusText, headers });
+  }
+  headers.set("content-type", "application/json");
+  const tagged = tagSchemaSources(document, String(resource?.url ?? resource));
+  return new Response(JSON.stringify(tagged), { status: response.status, statusText: response.statusText, headers });
+};
+
+// Node-argumenten voor de Redocly CLI, zodat de schema's van elk extern document hun bron dragen.
+const SCHEMA_SOURCES_ARGS = ["--require", path.join(__dirname, "schemaSourcesPreload.js")];
+
+module.exports = {
+  SCHEMA_SOURCES_ARGS,
+  documentUrl,
+  parseTaggedName,
+  sourceTag,
+  tagSchemaSources,
+  withSchemaSources,
+};
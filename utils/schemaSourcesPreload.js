@@ -0,0 +1,7 @@
+/**
+ * Preload (`node --require`) voor de Redocly CLI bij bundelen en dereferencen: de schema's van elk
+ * opgehaald document krijgen een naam met hun bron (zie `schemaSources.js`).
+ */
+const { withSchemaSources } = require("./schemaSources");
+
+globalThis.fetch = withSchemaSources(globalThis.fetch);
@@ -0,0 +1,36 @@
+const SEMVER_PATTERN = /^v?(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$/;
+
+const BUMPS = ["none", "patch", "minor", "major"];
+
+/**
+ * Leest een semantische versie (`1.2.3`, `1.2.3-rc.1`, eventueel met `v` ervoor). Geeft `undefined`
+ * terug als de waarde geen semver is.
+ */
+const parseSemver = (value) => {
+  const match = typeof value === "string" ? SEMVER_PATTERN.exec(value.trim()) : null;
+  if (!match) {
+    return undefined;
+  }
+  return { major: Number(match[1]), minor: Number(match[2]), patch: Number(match[3]), prerelease: match[4] };
+};
+
+/**
+ * Het soort verhoging van `from` naar `to`: `major`, `minor`, `patch` of `none`, of `downgrade` als
+ * `to` lager is. Een prerelease-label telt niet mee.
+ */
+const classifyBump = (from, to) => {
+  for (const part of ["major", "minor", "patch"]) {
+    if (to[part] !== from[part]) {
+      return to[part] > from[part] ? part : "downgrade";
+    }
+  }
+  return "none";
+};
+
+const bumpRank = (bump) => BUMPS.indexOf(bump);
+
+module.exports = {
+  bumpRank,
+  classifyBump,
+  parseSemver,
+};
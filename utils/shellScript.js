@@ -0,0 +1,160 @@
+const { sanitizeFileName, uniqueFileName } = require("./fileName");
+const {
+  collectAuthVariables,
+  collectOperations,
+  expandServerUrl,
+  operationName,
+  parameterExample,
+} = require("./openapi");
+const { buildProvenance, describeProvenance } = require("./provenance");
+
+const DEFAULT_BASE_URL = "http://localhost";
+const SHELL_OUTPUT_FORMATS = ["script", "zip"];
+const EXECUTABLE_MODE = 0o755;
+const BODY_DELIMITER = "JSON";
+
+const AUTH_VARIABLES = {
+  token: "TOKEN",
+  username: "USERNAME",
+  password: "PASSWORD",
+  apiKey: "API_KEY",
+};
+
+const comment = (text) => `# ${String(text).replace(/\r?\n/g, " ")}`;
+
+// Binnen dubbele quotes zijn alleen \, ", $ en ` bijzonder; zo blijven ${VAR}-verwijzingen die we zelf
+// toevoegen werken en wordt tekst uit de specificatie letterlijk doorgegeven.
+const escapeDoubleQuoted = (text) => String(text).replace(/[\\"$`]/g, "\\$&");
+
+const toEnvName = (name) => {
+  const envName = String(name)
+    .replace(/[^A-Za-z0-9]+/g, "_")
+    .replace(/^_+|_+$/g, "")
+    .toUpperCase();
+  return /^[A-Z_]/.test(envName) ? envName : `P_${envName}`;
+};
+
+const toFunctionName = (used, entry) => {
+  const base = sanitizeFileName(entry.operation.operationId || `${entry.method}_${entry.path}`, {
+    fallback: "operatie",
+  }).replace(/[.-]+/g, "_");
+  return uniqueFileName(used, /^[A-Za-z_]/.test(base) ? base : `op_${base}`).replace(/-/g, "_");
+};
+
+/**
+ * URL voor binnen dubbele quotes. Padparameters worden een omgevingsvariabele met de voorbeeldwaarde
+ * als default (`${ID:-42}`); zonder voorbeeld stopt het script met een melding als de variabele niet
+ * gezet is. Met `includeQuery` komen de verplichte queryparameters in de URL.
+ */
+const buildShellUrl = (document, path, parameters, { includeQuery = true } = {}) => {
+  const url = path
+    .split(/(\{[^}]+\})/)
+    .map((segment) => {
+      const match = /^\{([^}]+)\}$/.exec(segment);
+      if (!match) {
+        return escapeDoubleQuoted(segment);
+      }
+      const parameter = parameters.find((item) => item.in === "path" && item.name === match[1]);
+      const example = parameter ? parameterExample(document, parameter) : "";
+      const envName = toEnvName(match[1]);
+      return example
+        ? `\${${envName}:-${escapeDoubleQuoted(encodeURIComponent(example))}}`
+        : `\${${envName}:?Zet ${envName}}`;
+    })
+    .join("");
+  const query = includeQuery
+    ? parameters
+        .filter((parameter) => parameter.in === "query" && parameter.required === true)
+        .map(
+          (parameter) =>
+            `${encodeURIComponent(parameter.name)}=${encodeURIComponent(parameterExample(document, parameter))}`,
+        )
+    : [];
+  return `\${BASE_URL}${url}${query.length > 0 ? `?${escapeDoubleQuoted(query.join("&"))}` : ""}`;
+};
+
+const acceptsJson = (operation) =>
+  Object.values(operation.responses || {}).some((response) =>
+    Object.keys(response?.content || {}).some((type) => /[/+]json\b/i.test(type)),
+  );
+
+// Voorbeeld-body via een heredoc, zodat JSON niet ge-escaped hoeft te worden.
+const withHeredocBody = (command, sample) => `${command}\n${JSON.stringify(sample, null, 2)}\n${BODY_DELIMITER}`;
+
+const renderPreamble = (document, operations, header) => {
+  const servers = Array.isArray(document.servers) ? document.servers : [];
+  const baseUrl = expandServerUrl(servers[0]) || DEFAULT_BASE_URL;
+  const lines = [
+    "#!/usr/bin/env bash",
+    header,
+    "set -euo pipefail",
+    "",
+    `BASE_URL="\${BASE_URL:-${escapeDoubleQuoted(baseUrl)}}"`,
+  ];
+  for (const variable of collectAuthVariables(document, operations)) {
+    lines.push(`${AUTH_VARIABLES[variable]}="\${${AUTH_VARIABLES[variable]}:-}"`);
+  }
+  return lines.join("\n");
+};
+
+/**
+ * Eén script met een shellfunctie per operatie; het eerste argument kiest de operatie
+ * (`./api.sh getDier`), zonder argument toont het script de beschikbare operaties.
+ */
+const buildScript = (document, operations, header, renderCommand) => {
+  const used = new Set();
+  const functions = operations.map((entry) => ({
+    name: toFunctionName(used, entry),
+    title: operationName(entry),
+    command: renderCommand(document, entry),
+  }));
+  const definitions = functions.map(
+    ({ name, title, command }) => `${comment(title)}\n${name}() {\n  ${command.replace(/\n {2}/g, "\n    ")}\n}`,
+  );
+  const usage = [
+    '  echo "Gebruik: $0 <operatie>"',
+    '  echo "Operaties:"',
+    ...functions.map(({ name, title }) => `  echo "  ${name}  ${escapeDoubleQuoted(title)}"`),
+  ];
+  const cases = functions.map(({ name }) => `  ${name}) ${name} ;;`);
+  return [
+    renderPreamble(document, operations, header),
+    ...definitions,
+    `usage() {\n${usage.join("\n")}\n}`,
+    `case "\${1:-}" in\n${cases.join("\n")}\n  *)\n    usage\n    exit 1\n    ;;\nesac`,
+  ].join("\n\n");
+};
+
+/**
+ * Bouwt bash-scripts rond de commando's van `renderCommand(document, entry)`: standaard één script
+ * met een functie per operatie, of met `outputFormat=zip` een uitvoerbaar script per operatie. De
+ * basis-URL en authenticatie komen uit omgevingsvariabelen (`BASE_URL`, `TOKEN`,
+ * `USERNAME`/`PASSWORD`, `API_KEY`).
+ */
+const buildShellScripts = (document, { source, tool, outputFormat = "script", renderCommand }) => {
+  const operations = collectOperations(document);
+  const header = comment(describeProvenance(buildProvenance({ tool, source })));
+  if (outputFormat !== "zip") {
+    return { script: `${buildScript(document, operations, header, renderCommand)}\n` };
+  }
+  const used = new Set();
+  const files = operations.map((entry) => ({
+    name: `${toFunctionName(used, entry)}.sh`,
+    data: [
+      renderPreamble(document, [entry], header),
+      `${comment(operationName(entry))}\n${renderCommand(document, entry)}\n`,
+    ].join("\n\n"),
+    mode: EXECUTABLE_MODE,
+  }));
+  return { files };
+};
+
+module.exports = {
+  BODY_DELIMITER,
+  SHELL_OUTPUT_FORMATS,
+  acceptsJson,
+  buildShellScripts,
+  buildShellUrl,
+  escapeDoubleQuoted,
+  withHeredocBody,
+};
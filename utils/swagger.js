@@ -0,0 +1,928 @@
+const { formatPointer } = require("./jsonPointer");
+const { HTTP_METHODS, expandServerUrl, resolveRef } = require("./openapi");
+
+const OPENAPI_VERSION = "3.0.3";
+const SWAGGER_VERSION = "2.0";
+const DEFAULT_MEDIA_TYPE = "application/json";
+const FORM_MEDIA_TYPES = ["multipart/form-data", "application/x-www-form-urlencoded"];
+
+// Verwijzingen naar secties die in OpenAPI 3 onder `components` vallen.
+const REF_PREFIXES = [
+  ["#/definitions/", "#/components/schemas/"],
+  ["#/parameters/", "#/components/parameters/"],
+  ["#/responses/", "#/components/responses/"],
+];
+
+// Eigenschappen van een Swagger 2.0 parameter of header die in OpenAPI 3 in `schema` horen.
+const SCHEMA_KEYS = [
+  "type",
+  "format",
+  "items",
+  "default",
+  "maximum",
+  "exclusiveMaximum",
+  "minimum",
+  "exclusiveMinimum",
+  "maxLength",
+  "minLength",
+  "pattern",
+  "maxItems",
+  "minItems",
+  "uniqueItems",
+  "enum",
+  "multipleOf",
+];
+
+const PARAMETER_KEYS = ["name", "in", "description", "required", "deprecated", "allowEmptyValue"];
+
+const OAUTH2_FLOWS = {
+  implicit: "implicit",
+  password: "password",
+  application: "clientCredentials",
+  accessCode: "authorizationCode",
+};
+
+const isObject = (value) => value !== null && typeof value === "object" && !Array.isArray(value);
+
+// Extensies die bij de omzetting een eigen OpenAPI 3 veld krijgen.
+const CONVERTED_EXTENSIONS = new Set(["x-nullable", "x-example"]);
+
+const copyExtensions = (source, target) => {
+  for (const [key, value] of Object.entries(source)) {
+    if (key.startsWith("x-") && !CONVERTED_EXTENSIONS.has(key)) {
+      target[key] = value;
+    }
+  }
+  return target;
+};
+
+const isSwagger2 = (spec) => isObject(spec) && String(spec.swagger ?? "").trim() === SWAGGER_VERSION;
+
+/**
+ * Zet een Swagger 2.0 schema om: `x-nullable` wordt `nullable`, `type: file` een binaire string en
+ * een discriminator met alleen een propertynaam een discriminator-object.
+ */
+const convertSchema = (schema) => {
+  if (Array.isArray(schema)) {
+    return schema.map(convertSchema);
+  }
+  if (!isObject(schema)) {
+    return schema;
+  }
+  const converted = {};
+  for (const [key, value] of Object.entries(schema)) {
+    if (key === "x-nullable") {
+      converted.nullable = value;
+    } else if (key === "discriminator" && typeof value === "string") {
+      converted.discriminator = { propertyName: value };
+    } else if (key === "properties" && isObject(value)) {
+      converted.properties = Object.fromEntries(Object.entries(value).map(([name, sub]) => [name, convertSchema(sub)]));
+    } else if (["items", "additionalProperties", "not", "allOf", "anyOf", "oneOf"].includes(key)) {
+      converted[key] = convertSchema(value);
+    } else {
+      converted[key] = value;
+    }
+  }
+  if (converted.type === "file") {
+    converted.type = "string";
+    converted.format = "binary";
+  }
+  return converted;
+};
+
+// `items` van een niet-body parameter kan zelf weer een collectionFormat hebben; dat bestaat niet in een schema.
+const schemaFromSimple = (source) => {
+  const schema = {};
+  for (const key of SCHEMA_KEYS) {
+    if (source[key] !== undefined) {
+      schema[key] = key === "items" && isObject(source.items) ? schemaFromSimple(source.items) : source[key];
+    }
+  }
+  return convertSchema(schema);
+};
+
+const serializationStyle = (parameter) => {
+  switch (parameter.collectionFormat) {
+    case "multi":
+      return { style: "form", explode: true };
+    case "ssv":
+      return { style: "spaceDelimited", explode: false };
+    case "pipes":
+      return { style: "pipeDelimited", explode: false };
+    case "csv":
+    case "tsv":
+      return parameter.in === "query" || parameter.in === "formData" ? { style: "form", explode: false } : {};
+    default:
+      return {};
+  }
+};
+
+const convertParameter = (parameter) => {
+  const converted = {};
+  for (const key of PARAMETER_KEYS) {
+    if (parameter[key] !== undefined) {
+      converted[key] = parameter[key];
+    }
+  }
+  if (parameter.type === "array") {
+    Object.assign(converted, serializationStyle(parameter));
+  }
+  converted.schema = schemaFromSimple(parameter);
+  if (parameter["x-example"] !== undefined) {
+    converted.example = parameter["x-example"];
+  }
+  return copyExtensions(parameter, converted);
+};
+
+const convertHeaders = (headers) =>
+  Object.fromEntries(
+    Object.entries(headers).map(([name, header]) => {
+      const converted = { ...(header.description ? { description: header.description } : {}) };
+      converted.schema = schemaFromSimple(header);
+      return [name, copyExtensions(header, converted)];
+    }),
+  );
+
+const convertResponse = (response, produces) => {
+  if (!isObject(response) || typeof response.$ref === "string") {
+    return response;
+  }
+  const converted = { description: response.description ?? "" };
+  if (isObject(response.headers)) {
+    converted.headers = convertHeaders(response.headers);
+  }
+  const examples = isObject(response.examples) ? response.examples : {};
+  if (response.schema !== undefined || Object.keys(examples).length > 0) {
+    const schema = response.schema === undefined ? undefined : convertSchema(response.schema);
+    const mediaTypes = [...new Set([...produces, ...Object.keys(examples)])];
+    converted.content = Object.fromEntries(
+      mediaTypes.map((mediaType) => [
+        mediaType,
+        {
+          ...(schema !== undefined ? { schema } : {}),
+          ...(examples[mediaType] !== undefined ? { example: examples[mediaType] } : {}),
+        },
+      ]),
+    );
+  }
+  return copyExtensions(response, converted);
+};
+
+const convertResponses = (responses, produces) =>
+  Object.fromEntries(
+    Object.entries(responses).map(([status, response]) => [
+      status,
+      status.startsWith("x-") ? response : convertResponse(response, produces),
+    ]),
+  );
+
+const formRequestBody = (parameters, consumes) => {
+  const schema = { type: "object", properties: {} };
+  const required = [];
+  for (const parameter of parameters) {
+    const property = schemaFromSimple(parameter);
+    if (parameter.description) {
+      property.description = parameter.description;
+    }
+    schema.properties[parameter.name] = property;
+    if (parameter.required) {
+      required.push(parameter.name);
+    }
+  }
+  if (required.length > 0) {
+    schema.required = required;
+  }
+  const formTypes = consumes.filter((mediaType) => FORM_MEDIA_TYPES.includes(mediaType));
+  const hasFile = parameters.some((parameter) => parameter.type === "file");
+  const mediaTypes = formTypes.length > 0 ? formTypes : [hasFile ? FORM_MEDIA_TYPES[0] : FORM_MEDIA_TYPES[1]];
+  return {
+    ...(required.length > 0 ? { required: true } : {}),
+    content: Object.fromEntries(mediaTypes.map((mediaType) => [mediaType, { schema }])),
+  };
+};
+
+const bodyRequestBody = (parameter, consumes) => {
+  const schema = convertSchema(parameter.schema ?? {});
+  const mediaTypes = consumes.filter((mediaType) => !FORM_MEDIA_TYPES.includes(mediaType));
+  return copyExtensions(parameter, {
+    ...(parameter.description ? { description: parameter.description } : {}),
+    ...(parameter.required ? { required: true } : {}),
+    content: Object.fromEntries(
+      (mediaTypes.length > 0 ? mediaTypes : [DEFAULT_MEDIA_TYPE]).map((mediaType) => [mediaType, { schema }]),
+    ),
+  });
+};
+
+const isBodyParameter = (parameter) => parameter?.in === "body" || parameter?.in === "formData";
+
+const convertParameterOrRef = (parameter) =>
+  typeof parameter.$ref === "string" ? { $ref: parameter.$ref } : convertParameter(parameter);
+
+const parameterKey = (spec, parameter) => {
+  const resolved = resolveRef(spec, parameter);
+  return resolved ? `${resolved.in}:${resolved.name}` : parameter.$ref;
+};
+
+/**
+ * Body- en formData-parameters worden één requestBody; verwijzingen naar zulke parameters in
+ * `#/parameters` worden daarvoor opgelost. Parameters van het pad tellen mee tenzij de operatie
+ * dezelfde parameter zelf definieert.
+ */
+const convertOperationParameters = (spec, pathParameters, operationParameters, consumes) => {
+  const overridden = new Set(operationParameters.map((parameter) => parameterKey(spec, parameter)));
+  const inherited = pathParameters.filter((parameter) => !overridden.has(parameterKey(spec, parameter)));
+  const bodyParameters = [...inherited, ...operationParameters]
+    .map((parameter) => resolveRef(spec, parameter))
+    .filter((parameter) => isBodyParameter(parameter));
+  const body = bodyParameters.find((parameter) => parameter.in === "body");
+  const formParameters = bodyParameters.filter((parameter) => parameter.in === "formData");
+  // Overige parameters van het pad blijven op het pad staan.
+  const parameters = operationParameters
+    .filter((parameter) => isObject(parameter) && !isBodyParameter(resolveRef(spec, parameter)))
+    .map(convertParameterOrRef);
+  let requestBody;
+  if (body) {
+    requestBody = bodyRequestBody(body, consumes);
+  } else if (formParameters.length > 0) {
+    requestBody = formRequestBody(formParameters, consumes);
+  }
+  return { parameters, requestBody };
+};
+
+const convertOperation = (spec, operation, pathParameters) => {
+  const consumes = operation.consumes ?? spec.consumes ?? [];
+  const produces = operation.produces ?? spec.produces ?? [DEFAULT_MEDIA_TYPE];
+  const { parameters, requestBody } = convertOperationParameters(
+    spec,
+    pathParameters,
+    Array.isArray(operation.parameters) ? operation.parameters : [],
+    consumes,
+  );
+  const converted = {};
+  for (const [key, value] of Object.entries(operation)) {
+    if (key === "parameters") {
+      if (parameters.length > 0) {
+        converted.parameters = parameters;
+      }
+      if (requestBody) {
+        converted.requestBody = requestBody;
+      }
+    } else if (key === "responses" && isObject(value)) {
+      converted.responses = convertResponses(value, produces);
+    } else if (!["consumes", "produces", "schemes"].includes(key)) {
+      converted[key] = value;
+    }
+  }
+  if (requestBody && !converted.requestBody) {
+    converted.requestBody = requestBody;
+  }
+  return converted;
+};
+
+const convertPathItem = (spec, pathItem) => {
+  if (!isObject(pathItem) || typeof pathItem.$ref === "string") {
+    return pathItem;
+  }
+  const pathParameters = Array.isArray(pathItem.parameters) ? pathItem.parameters : [];
+  const converted = {};
+  for (const [key, value] of Object.entries(pathItem)) {
+    if (HTTP_METHODS.includes(key) && isObject(value)) {
+      converted[key] = convertOperation(spec, value, pathParameters);
+    } else if (key === "parameters") {
+      const parameters = pathParameters
+        .filter((parameter) => isObject(parameter) && !isBodyParameter(resolveRef(spec, parameter)))
+        .map(convertParameterOrRef);
+      if (parameters.length > 0) {
+        converted.parameters = parameters;
+      }
+    } else {
+      converted[key] = value;
+    }
+  }
+  return converted;
+};
+
+const convertSecurityScheme = (scheme) => {
+  const base = scheme.description ? { description: scheme.description } : {};
+  let converted;
+  if (scheme.type === "basic") {
+    converted = { type: "http", scheme: "basic", ...base };
+  } else if (scheme.type === "oauth2") {
+    const flow = {
+      ...(scheme.authorizationUrl ? { authorizationUrl: scheme.authorizationUrl } : {}),
+      ...(scheme.tokenUrl ? { tokenUrl: scheme.tokenUrl } : {}),
+      scopes: scheme.scopes ?? {},
+    };
+    converted = { type: "oauth2", ...base, flows: { [OAUTH2_FLOWS[scheme.flow] ?? scheme.flow]: flow } };
+  } else {
+    converted = { type: scheme.type, ...base, name: scheme.name, in: scheme.in };
+  }
+  return copyExtensions(scheme, converted);
+};
+
+const buildServers = (spec) => {
+  const basePath = typeof spec.basePath === "string" ? spec.basePath.replace(/\/+$/, "") : "";
+  if (typeof spec.host !== "string" || spec.host.trim().length === 0) {
+    return basePath ? [{ url: basePath }] : [];
+  }
+  const schemes = Array.isArray(spec.schemes) && spec.schemes.length > 0 ? spec.schemes : ["https"];
+  return schemes.map((scheme) => ({ url: `${scheme}://${spec.host.trim()}${basePath}` }));
+};
+
+const rewriteRefs = (value, prefixes = REF_PREFIXES) => {
+  if (Array.isArray(value)) {
+    return value.map((child) => rewriteRefs(child, prefixes));
+  }
+  if (!isObject(value)) {
+    return value;
+  }
+  return Object.fromEntries(
+    Object.entries(value).map(([key, child]) => {
+      if (key === "$ref" && typeof child === "string") {
+        const prefix = prefixes.find(([from]) => child.startsWith(from));
+        return [key, prefix ? `${prefix[1]}${child.slice(prefix[0].length)}` : child];
+      }
+      return [key, rewriteRefs(child, prefixes)];
+    }),
+  );
+};
+
+/**
+ * Zet een Swagger 2.0 document om naar OpenAPI 3.0: `host`, `basePath` en `schemes` worden `servers`,
+ * body- en formData-parameters een `requestBody`, `consumes`/`produces` de media types in `content`,
+ * en `definitions`, `parameters`, `responses` en `securityDefinitions` verhuizen naar `components`.
+ */
+const upgradeSwagger2 = (spec) => {
+  const upgraded = { openapi: OPENAPI_VERSION };
+  const components = {};
+  const servers = buildServers(spec);
+  const produces = spec.produces ?? [DEFAULT_MEDIA_TYPE];
+  for (const [key, value] of Object.entries(spec)) {
+    switch (key) {
+      case "swagger":
+      case "host":
+      case "basePath":
+      case "schemes":
+      case "consumes":
+      case "produces":
+        break;
+      case "paths":
+        upgraded.paths = Object.fromEntries(
+          Object.entries(value ?? {}).map(([path, pathItem]) => [
+            path,
+            path.startsWith("x-") ? pathItem : convertPathItem(spec, pathItem),
+          ]),
+        );
+        break;
+      case "definitions":
+        components.schemas = Object.fromEntries(
+          Object.entries(value ?? {}).map(([name, schema]) => [name, convertSchema(schema)]),
+        );
+        break;
+      case "parameters": {
+        // Body- en formData-parameters zijn in operaties al opgelost tot een requestBody.
+        const parameters = Object.entries(value ?? {}).filter(([, parameter]) => !isBodyParameter(parameter));
+        if (parameters.length > 0) {
+          components.parameters = Object.fromEntries(
+            parameters.map(([name, parameter]) => [name, convertParameter(parameter)]),
+          );
+        }
+        break;
+      }
+      case "responses":
+        components.responses = convertResponses(value ?? {}, produces);
+        break;
+      case "securityDefinitions":
+        components.securitySchemes = Object.fromEntries(
+          Object.entries(value ?? {}).map(([name, scheme]) => [name, convertSecurityScheme(scheme)]),
+        );
+        break;
+      default:
+        upgraded[key] = value;
+        if (key === "info" && servers.length > 0) {
+          upgraded.servers = servers;
+        }
+    }
+  }
+  if (servers.length > 0 && !upgraded.servers) {
+    upgraded.servers = servers;
+  }
+  upgraded.paths = upgraded.paths ?? {};
+  if (Object.keys(components).length > 0) {
+    upgraded.components = components;
+  }
+  return rewriteRefs(upgraded);
+};
+
+// Omgekeerde richting: van `components` terug naar de secties van Swagger 2.0.
+const DOWNGRADE_REF_PREFIXES = REF_PREFIXES.map(([from, to]) => [to, from]);
+const SWAGGER_METHODS = HTTP_METHODS.filter((method) => method !== "trace");
+const JSON_MEDIA_TYPE = /[/+]json(;|$)/i;
+const SERVER_URL = /^([a-z][a-z0-9+.-]*):\/\/([^/]+)(\/.*)?$/i;
+const SWAGGER_FLOWS = Object.fromEntries(Object.entries(OAUTH2_FLOWS).map(([swagger, openapi]) => [openapi, swagger]));
+
+const AS_STRING = "de waarde is als string beschreven.";
+
+const isFormMediaType = (mediaType) => FORM_MEDIA_TYPES.includes(mediaType);
+
+const preferredMediaType = (mediaTypes) =>
+  mediaTypes.find((mediaType) => JSON_MEDIA_TYPE.test(mediaType)) ?? mediaTypes[0];
+
+const notSupported = (context, at, what) => context.warn(at, `${what} bestaat niet in Swagger 2.0 en is weggelaten.`);
+
+/**
+ * Zet een OpenAPI 3.0 schema om naar Swagger 2.0. `oneOf`, `anyOf`, `not` en `writeOnly` hebben geen
+ * tegenhanger en vallen weg; `nullable` en `deprecated` worden extensies.
+ */
+const downgradeSchema = (context, schema, at) => {
+  if (!isObject(schema)) {
+    return schema;
+  }
+  if (typeof schema.$ref === "string") {
+    return { $ref: schema.$ref };
+  }
+  const converted = {};
+  for (const [key, value] of Object.entries(schema)) {
+    switch (key) {
+      case "nullable":
+        converted["x-nullable"] = value;
+        break;
+      case "deprecated":
+        converted["x-deprecated"] = value;
+        break;
+      case "oneOf":
+      case "anyOf":
+      case "not":
+      case "writeOnly":
+        notSupported(context, [...at, key], key);
+        break;
+      case "discriminator":
+        converted.discriminator = value?.propertyName;
+        if (value?.mapping) {
+          notSupported(context, [...at, key, "mapping"], "discriminator.mapping");
+        }
+        break;
+      case "properties":
+        converted.properties = Object.fromEntries(
+          Object.entries(value ?? {}).map(([name, property]) => [
+            name,
+            downgradeSchema(context, property, [...at, key, name]),
+          ]),
+        );
+        break;
+      case "items":
+      case "additionalProperties":
+        converted[key] = downgradeSchema(context, value, [...at, key]);
+        break;
+      case "allOf":
+        converted.allOf = (Array.isArray(value) ? value : []).map((member, index) =>
+          downgradeSchema(context, member, [...at, key, index]),
+        );
+        break;
+      default:
+        converted[key] = value;
+    }
+  }
+  return converted;
+};
+
+// Parameters, headers en formData-velden hebben in Swagger 2.0 geen schema maar losse typevelden.
+const flattenSchema = (context, rawSchema, at) => {
+  const schema = resolveRef(context.spec, rawSchema) ?? {};
+  const isComposite = schema.properties || schema.allOf || schema.oneOf || schema.anyOf;
+  if (schema.type === "object" || (!schema.type && isComposite)) {
+    context.warn(at, `Een object-schema kan in Swagger 2.0 alleen in een body staan; ${AS_STRING}`);
+    return { type: "string" };
+  }
+  const flat = {};
+  for (const key of SCHEMA_KEYS) {
+    if (schema[key] !== undefined) {
+      flat[key] = key === "items" ? flattenSchema(context, schema.items, [...at, "items"]) : schema[key];
+    }
+  }
+  flat.type = flat.type ?? "string";
+  if (schema.nullable) {
+    flat["x-nullable"] = true;
+  }
+  return flat;
+};
+
+const collectionFormat = (context, parameter, at) => {
+  const style = parameter.style ?? (parameter.in === "query" ? "form" : "simple");
+  const explode = parameter.explode ?? style === "form";
+  switch (style) {
+    case "form":
+      return explode ? "multi" : "csv";
+    case "spaceDelimited":
+      return "ssv";
+    case "pipeDelimited":
+      return "pipes";
+    case "simple":
+      return "csv";
+    default:
+      context.warn([...at, "style"], `Style ${style} bestaat niet in Swagger 2.0; de waarden zijn kommagescheiden.`);
+      return "csv";
+  }
+};
+
+const downgradeParameter = (context, parameter, at) => {
+  if (typeof parameter?.$ref === "string") {
+    // Cookie-parameters uit components zijn daar al gemeld en weggelaten.
+    return resolveRef(context.spec, parameter)?.in === "cookie" ? undefined : { $ref: parameter.$ref };
+  }
+  if (!isObject(parameter)) {
+    return undefined;
+  }
+  if (parameter.in === "cookie") {
+    notSupported(context, at, `Cookie-parameter ${parameter.name}`);
+    return undefined;
+  }
+  const converted = {};
+  for (const key of ["name", "in", "description", "required", "allowEmptyValue"]) {
+    if (parameter[key] !== undefined) {
+      converted[key] = parameter[key];
+    }
+  }
+  let schema = parameter.schema;
+  if (!schema && isObject(parameter.content)) {
+    context.warn([...at, "content"], `Een parameter met content kan niet in Swagger 2.0; ${AS_STRING}`);
+    schema = { type: "string" };
+  }
+  Object.assign(converted, flattenSchema(context, schema, [...at, "schema"]));
+  if (converted.type === "array") {
+    converted.collectionFormat = collectionFormat(context, parameter, at);
+  }
+  if (parameter.example !== undefined) {
+    converted["x-example"] = parameter.example;
+  }
+  if (parameter.deprecated) {
+    converted["x-deprecated"] = true;
+  }
+  return copyExtensions(parameter, converted);
+};
+
+const downgradeHeaders = (context, headers, at) =>
+  Object.fromEntries(
+    Object.entries(headers).map(([name, rawHeader]) => {
+      const header = resolveRef(context.spec, rawHeader) ?? {};
+      const converted = header.description ? { description: header.description } : {};
+      Object.assign(converted, flattenSchema(context, header.schema, [...at, name, "schema"]));
+      return [name, copyExtensions(header, converted)];
+    }),
+  );
+
+// Swagger 2.0 kent één schema per body; bij meerdere media types wint JSON.
+const pickContent = (context, content, mediaTypes, at) => {
+  const mediaType = preferredMediaType(mediaTypes);
+  const schema = content[mediaType]?.schema;
+  const differs = mediaTypes.some((other) => JSON.stringify(content[other]?.schema) !== JSON.stringify(schema));
+  if (differs) {
+    context.warn(at, `Swagger 2.0 kent één schema per body; het schema van ${mediaType} is overgenomen.`);
+  }
+  return { mediaType, schema };
+};
+
+const formParameters = (context, rawSchema, at) => {
+  const schema = resolveRef(context.spec, rawSchema) ?? {};
+  const required = new Set(Array.isArray(schema.required) ? schema.required : []);
+  return Object.entries(schema.properties ?? {}).map(([name, rawProperty]) => {
+    const property = resolveRef(context.spec, rawProperty) ?? {};
+    const flat = flattenSchema(context, property, [...at, "properties", name]);
+    const isFile = flat.type === "string" && flat.format === "binary";
+    return {
+      name,
+      in: "formData",
+      ...(property.description ? { description: property.description } : {}),
+      ...(required.has(name) ? { required: true } : {}),
+      ...(isFile ? { type: "file" } : flat),
+      ...(flat.type === "array" ? { collectionFormat: "multi" } : {}),
+    };
+  });
+};
+
+/**
+ * Een requestBody wordt een body-parameter of, bij een formulier, formData-parameters per property.
+ * Swagger 2.0 kent niet beide in één operatie; de soort van het voorkeurs-media type wint.
+ */
+const downgradeRequestBody = (context, rawRequestBody, at) => {
+  const requestBody = resolveRef(context.spec, rawRequestBody);
+  const content = isObject(requestBody?.content) ? requestBody.content : {};
+  const mediaTypes = Object.keys(content);
+  if (mediaTypes.length === 0) {
+    return { parameters: [], consumes: [] };
+  }
+  const useForm = isFormMediaType(preferredMediaType(mediaTypes));
+  const consumes = mediaTypes.filter((mediaType) => isFormMediaType(mediaType) === useForm);
+  if (consumes.length < mediaTypes.length) {
+    const dropped = mediaTypes.filter((mediaType) => !consumes.includes(mediaType));
+    context.warn([...at, "content"], `Swagger 2.0 kent geen body naast formData; ${dropped.join(", ")} is weggelaten.`);
+  }
+  const { mediaType, schema } = pickContent(context, content, consumes, [...at, "content"]);
+  const schemaAt = [...at, "content", mediaType, "schema"];
+  if (useForm) {
+    return { parameters: formParameters(context, schema, schemaAt), consumes };
+  }
+  const parameter = {
+    name: "body",
+    in: "body",
+    ...(requestBody.description ? { description: requestBody.description } : {}),
+    ...(requestBody.required ? { required: true } : {}),
+    schema: downgradeSchema(context, schema ?? {}, schemaAt),
+  };
+  return { parameters: [copyExtensions(requestBody, parameter)], consumes };
+};
+
+const mediaExample = (media) => {
+  if (media?.example !== undefined) {
+    return media.example;
+  }
+  const first = Object.values(isObject(media?.examples) ? media.examples : {})[0];
+  return first?.value;
+};
+
+const downgradeResponse = (context, response, at) => {
+  if (typeof response?.$ref === "string") {
+    const resolved = resolveRef(context.spec, response);
+    return { response: { $ref: response.$ref }, produces: Object.keys(resolved?.content ?? {}) };
+  }
+  if (!isObject(response)) {
+    return { response, produces: [] };
+  }
+  const converted = { description: response.description ?? "" };
+  const content = isObject(response.content) ? response.content : {};
+  const mediaTypes = Object.keys(content);
+  if (mediaTypes.length > 0) {
+    const { mediaType, schema } = pickContent(context, content, mediaTypes, [...at, "content"]);
+    if (schema !== undefined) {
+      converted.schema = downgradeSchema(context, schema, [...at, "content", mediaType, "schema"]);
+    }
+    const examples = mediaTypes
+      .map((type) => [type, mediaExample(content[type])])
+      .filter(([, example]) => example !== undefined);
+    if (examples.length > 0) {
+      converted.examples = Object.fromEntries(examples);
+    }
+  }
+  if (isObject(response.headers)) {
+    converted.headers = downgradeHeaders(context, response.headers, [...at, "headers"]);
+  }
+  if (response.links) {
+    notSupported(context, [...at, "links"], "links");
+  }
+  return { response: copyExtensions(response, converted), produces: mediaTypes };
+};
+
+const downgradeResponses = (context, responses, at) => {
+  const converted = {};
+  const produces = new Set();
+  for (const [status, response] of Object.entries(responses)) {
+    if (status.startsWith("x-")) {
+      converted[status] = response;
+    } else if (!/^(\d{3}|default)$/.test(status)) {
+      notSupported(context, [...at, status], `Statusbereik ${status}`);
+    } else {
+      const result = downgradeResponse(context, response, [...at, status]);
+      converted[status] = result.response;
+      for (const mediaType of result.produces) {
+        produces.add(mediaType);
+      }
+    }
+  }
+  return { responses: converted, produces: [...produces] };
+};
+
+// Een requirement die alleen weggelaten schemes noemde, zou anders "geen authenticatie" gaan betekenen.
+const downgradeSecurity = (context, requirements) =>
+  requirements
+    .map((requirement) =>
+      Object.fromEntries(Object.entries(requirement).filter(([name]) => !context.droppedSchemes.has(name))),
+    )
+    .filter(
+      (requirement, index) => Object.keys(requirement).length > 0 || Object.keys(requirements[index]).length === 0,
+    );
+
+const downgradeOperation = (context, operation, at) => {
+  const converted = {};
+  let body = { parameters: [], consumes: [] };
+  let produces = [];
+  for (const [key, value] of Object.entries(operation)) {
+    switch (key) {
+      case "parameters":
+        converted.parameters = (Array.isArray(value) ? value : [])
+          .map((parameter, index) => downgradeParameter(context, parameter, [...at, key, index]))
+          .filter(Boolean);
+        break;
+      case "requestBody":
+        body = downgradeRequestBody(context, value, [...at, key]);
+        break;
+      case "responses": {
+        const result = downgradeResponses(context, isObject(value) ? value : {}, [...at, key]);
+        converted.responses = result.responses;
+        produces = result.produces;
+        break;
+      }
+      case "security":
+        converted.security = downgradeSecurity(context, Array.isArray(value) ? value : []);
+        break;
+      case "callbacks":
+      case "servers":
+        notSupported(context, [...at, key], key);
+        break;
+      default:
+        converted[key] = value;
+    }
+  }
+  const parameters = [...(converted.parameters ?? []), ...body.parameters];
+  delete converted.parameters;
+  return {
+    ...(body.consumes.length > 0 ? { consumes: body.consumes } : {}),
+    ...(produces.length > 0 ? { produces } : {}),
+    ...(parameters.length > 0 ? { parameters } : {}),
+    ...converted,
+  };
+};
+
+const downgradePathItem = (context, pathItem, at) => {
+  if (!isObject(pathItem) || typeof pathItem.$ref === "string") {
+    return pathItem;
+  }
+  const converted = {};
+  for (const [key, value] of Object.entries(pathItem)) {
+    if (SWAGGER_METHODS.includes(key)) {
+      converted[key] = downgradeOperation(context, value ?? {}, [...at, key]);
+    } else if (key === "parameters") {
+      const parameters = (Array.isArray(value) ? value : [])
+        .map((parameter, index) => downgradeParameter(context, parameter, [...at, key, index]))
+        .filter(Boolean);
+      if (parameters.length > 0) {
+        converted.parameters = parameters;
+      }
+    } else if (key.startsWith("x-")) {
+      converted[key] = value;
+    } else {
+      notSupported(context, [...at, key], key === "trace" ? "De methode trace" : key);
+    }
+  }
+  return converted;
+};
+
+const downgradeSecurityScheme = (context, name, scheme, at) => {
+  const description = scheme.description ? { description: scheme.description } : {};
+  if (scheme.type === "apiKey" && scheme.in !== "cookie") {
+    return copyExtensions(scheme, { type: "apiKey", ...description, name: scheme.name, in: scheme.in });
+  }
+  if (scheme.type === "http" && String(scheme.scheme).toLowerCase() === "basic") {
+    return copyExtensions(scheme, { type: "basic", ...description });
+  }
+  if (scheme.type === "http") {
+    context.warn(at, `HTTP ${scheme.scheme} authenticatie is beschreven als apiKey in de Authorization header.`);
+    return copyExtensions(scheme, { type: "apiKey", ...description, name: "Authorization", in: "header" });
+  }
+  if (scheme.type === "oauth2" && isObject(scheme.flows) && Object.keys(scheme.flows).length > 0) {
+    const [flowName, flow] = Object.entries(scheme.flows)[0];
+    if (Object.keys(scheme.flows).length > 1) {
+      const message = `Swagger 2.0 kent één flow per security scheme; alleen ${flowName} is overgenomen.`;
+      context.warn([...at, "flows"], message);
+    }
+    return copyExtensions(scheme, {
+      type: "oauth2",
+      ...description,
+      flow: SWAGGER_FLOWS[flowName] ?? flowName,
+      ...(flow.authorizationUrl ? { authorizationUrl: flow.authorizationUrl } : {}),
+      ...(flow.tokenUrl ? { tokenUrl: flow.tokenUrl } : {}),
+      scopes: flow.scopes ?? {},
+    });
+  }
+  notSupported(context, at, `Security scheme ${name} (${scheme.type}${scheme.in ? ` in ${scheme.in}` : ""})`);
+  context.droppedSchemes.add(name);
+  return undefined;
+};
+
+/**
+ * Swagger 2.0 kent één host en basePath, met een lijst schemes. De eerste server bepaalt host en
+ * basePath; servers met een andere host of een ander pad vallen weg.
+ */
+const downgradeServers = (context, servers) => {
+  const parsed = servers.map((server, index) => {
+    if (isObject(server?.variables)) {
+      const message = "Servervariabelen bestaan niet in Swagger 2.0; de default is ingevuld.";
+      context.warn(["servers", index, "variables"], message);
+    }
+    const url = expandServerUrl(server);
+    const match = SERVER_URL.exec(url);
+    const path = (match ? match[3] : url) || "";
+    return { scheme: match?.[1].toLowerCase(), host: match?.[2], basePath: path.replace(/\/+$/, "") || "/" };
+  });
+  const [first] = parsed;
+  const schemes = [];
+  parsed.forEach((server, index) => {
+    if (server.host !== first.host || server.basePath !== first.basePath) {
+      context.warn(["servers", index], "Swagger 2.0 kent één host en basePath; deze server is weggelaten.");
+    } else if (server.scheme && !schemes.includes(server.scheme)) {
+      schemes.push(server.scheme);
+    }
+  });
+  return {
+    ...(first.host ? { host: first.host } : {}),
+    basePath: first.basePath,
+    ...(schemes.length > 0 ? { schemes } : {}),
+  };
+};
+
+const downgradeComponents = (context, components) => {
+  const converted = {};
+  const entries = (section) => Object.entries(isObject(components[section]) ? components[section] : {});
+  if (components.schemas) {
+    converted.definitions = Object.fromEntries(
+      entries("schemas").map(([name, schema]) => [
+        name,
+        downgradeSchema(context, schema, ["components", "schemas", name]),
+      ]),
+    );
+  }
+  const parameters = entries("parameters")
+    .map(([name, parameter]) => [name, downgradeParameter(context, parameter, ["components", "parameters", name])])
+    .filter(([, parameter]) => parameter);
+  if (parameters.length > 0) {
+    converted.parameters = Object.fromEntries(parameters);
+  }
+  if (components.responses) {
+    converted.responses = Object.fromEntries(
+      entries("responses").map(([name, response]) => [
+        name,
+        downgradeResponse(context, response, ["components", "responses", name]).response,
+      ]),
+    );
+  }
+  const securityDefinitions = entries("securitySchemes")
+    .map(([name, scheme]) => {
+      const at = ["components", "securitySchemes", name];
+      return [name, downgradeSecurityScheme(context, name, resolveRef(context.spec, scheme) ?? {}, at)];
+    })
+    .filter(([, scheme]) => scheme);
+  if (securityDefinitions.length > 0) {
+    converted.securityDefinitions = Object.fromEntries(securityDefinitions);
+  }
+  // requestBodies en headers worden waar ze gebruikt worden ingevuld; voor deze secties kan dat niet.
+  for (const section of ["examples", "links", "callbacks"]) {
+    if (components[section]) {
+      notSupported(context, ["components", section], `components.${section}`);
+    }
+  }
+  return converted;
+};
+
+/**
+ * Zet een OpenAPI 3.0 document om naar Swagger 2.0, voor gateways die geen OpenAPI 3 kunnen
+ * importeren. Wat Swagger 2.0 niet kan uitdrukken valt weg of wordt vereenvoudigd; elke keer dat
+ * dat gebeurt staat in `warnings`, met een JSON pointer naar de plek in het brondocument.
+ */
+const downgradeToSwagger2 = (spec) => {
+  const warnings = [];
+  const context = {
+    spec,
+    droppedSchemes: new Set(),
+    warn: (segments, message) => warnings.push({ path: formatPointer(segments), message }),
+  };
+  // Eerst components, zodat weggelaten security schemes bekend zijn voor de security requirements.
+  const components = downgradeComponents(context, isObject(spec.components) ? spec.components : {});
+  const servers = Array.isArray(spec.servers) && spec.servers.length > 0 ? downgradeServers(context, spec.servers) : {};
+  const downgraded = { swagger: SWAGGER_VERSION };
+  for (const [key, value] of Object.entries(spec)) {
+    switch (key) {
+      case "openapi":
+      case "components":
+      case "servers":
+        break;
+      case "info":
+        Object.assign(downgraded, { info: value }, servers);
+        break;
+      case "paths":
+        downgraded.paths = Object.fromEntries(
+          Object.entries(value ?? {}).map(([path, pathItem]) => [
+            path,
+            path.startsWith("x-") ? pathItem : downgradePathItem(context, pathItem, ["paths", path]),
+          ]),
+        );
+        break;
+      case "security":
+        downgraded.security = downgradeSecurity(context, Array.isArray(value) ? value : []);
+        break;
+      default:
+        downgraded[key] = value;
+    }
+  }
+  if (!downgraded.info) {
+    Object.assign(downgraded, servers);
+  }
+  downgraded.paths = downgraded.paths ?? {};
+  Object.assign(downgraded, components);
+  return { spec: rewriteRefs(downgraded, DOWNGRADE_REF_PREFIXES), warnings };
+};
+
+module.exports = {
+  SWAGGER_VERSION,
+  downgradeToSwagger2,
+  isSwagger2,
+  upgradeSwagger2,
+};
@@ -0,0 +1,59 @@
+const fs = require("node:fs/promises");
+const os = require("node:os");
+const path = require("node:path");
+const { createZipStream } = require("./zip");
+
+/**
+ * Tijdelijke map voor gegenereerde exportbestanden. `write` zet de inhoud op schijf en geeft een
+ * ZIP-entry terug die naar dat bestand verwijst, zodat de inhoud daarna niet meer in het geheugen
+ * hoeft te blijven. `remove` ruimt de map op en gooit nooit.
+ */
+const createTempFiles = async (prefix) => {
+  const directory = await fs.mkdtemp(path.join(os.tmpdir(), prefix));
+  let counter = 0;
+  return {
+    directory,
+    write: async ({ name, data, mode }) => {
+      counter += 1;
+      const filePath = path.join(directory, String(counter));
+      await fs.writeFile(filePath, data);
+      return mode ? { name, path: filePath, mode } : { name, path: filePath };
+    },
+    remove: async () => {
+      try {
+        await fs.rm(directory, { recursive: true, force: true });
+      } catch {
+        // ignore cleanup errors
+      }
+    },
+  };
+};
+
+/**
+ * Schrijft `files` (een array of generator van `{ name, data, mode }`) naar een tijdelijke map en
+ * geeft een ZIP-stream terug die de bestanden één voor één van schijf leest. Met een generator staat
+ * steeds maar één bestand in het geheugen. De map wordt opgeruimd zodra de stream klaar of
+ * afgebroken is.
+ */
+const createTempZipStream = async (prefix, files) => {
+  const tempFiles = await createTempFiles(prefix);
+  try {
+    const entries = [];
+    for (const file of files) {
+      entries.push(await tempFiles.write(file));
+    }
+    const stream = createZipStream(entries);
+    stream.once("close", () => {
+      tempFiles.remove();
+    });
+    return stream;
+  } catch (error) {
+    await tempFiles.remove();
+    throw error;
+  }
+};
+
+module.exports = {
+  createTempFiles,
+  createTempZipStream,
+};
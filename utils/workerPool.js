@@ -0,0 +1,84 @@
+const { Worker } = require("node:worker_threads");
+
+class WorkerTimeoutError extends Error {
+  constructor(timeoutMs) {
+    super(`De worker is na ${timeoutMs} ms beëindigd.`);
+    this.name = "WorkerTimeoutError";
+  }
+}
+
+/**
+ * Pool van worker threads die `filename` draaien, elk met één taak tegelijk. Een worker krijgt de taak
+ * als bericht en antwoordt met `{ result }` of `{ error }`; berichten met `{ event }` gaan tussendoor naar
+ * `onEvent`. Na een taak blijft de worker (tot `maxIdle` stuks) beschikbaar, zodat wat hij geladen heeft
+ * niet opnieuw geladen hoeft te worden. Duurt een taak langer dan `timeoutMs`, dan wordt de worker
+ * beëindigd: anders dan een timer naast een promise stopt dat ook synchrone code. Hetzelfde gebeurt
+ * als `signal` afgaat, bijvoorbeeld omdat de aanroeper niet meer op het resultaat wacht.
+ */
+const createWorkerPool = (filename, { maxIdle = 4 } = {}) => {
+  const idle = [];
+
+  const take = () => {
+    const worker = idle.pop() ?? new Worker(filename);
+    worker.ref();
+    return worker;
+  };
+
+  const release = (worker) => {
+    if (idle.length < maxIdle) {
+      // Een wachtende worker houdt het proces niet in leven.
+      worker.unref();
+      idle.push(worker);
+    } else {
+      worker.terminate();
+    }
+  };
+
+  const run = (task, { timeoutMs, onEvent = () => {}, signal }) =>
+    new Promise((resolve, reject) => {
+      if (signal?.aborted) {
+        reject(signal.reason);
+        return;
+      }
+      const worker = take();
+      let timeoutId;
+      const onAbort = () => finish(reject, signal.reason, false);
+      const finish = (settle, value, reusable) => {
+        clearTimeout(timeoutId);
+        signal?.removeEventListener("abort", onAbort);
+        worker.off("message", onMessage);
+        worker.off("error", onError);
+        worker.off("exit", onExit);
+        if (reusable) {
+          release(worker);
+        } else {
+          worker.terminate();
+        }
+        settle(value);
+      };
+      const onMessage = (message) => {
+        if (Object.hasOwn(message, "event")) {
+          onEvent(message.event);
+        } else if (Object.hasOwn(message, "error")) {
+          finish(reject, message.error, true);
+        } else {
+          finish(resolve, message.result, true);
+        }
+      };
+      const onError = (error) => finish(reject, error, false);
+      const onExit = (code) => finish(reject, new Error(`De worker stopte onverwacht met code ${code}.`), false);
+      worker.on("message", onMessage);
+      worker.on("error", onError);
+      worker.on("exit", onExit);
+      signal?.addEventListener("abort", onAbort);
+      timeoutId = setTimeout(() => finish(reject, new WorkerTimeoutError(timeoutMs), false), timeoutMs);
+      worker.postMessage(task);
+    });
+
+  return { run };
+};
+
+module.exports = {
+  WorkerTimeoutError,
+  createWorkerPool,
+};
@@ -0,0 +1,137 @@
+const jsYaml = require("js-yaml");
+
+const DEFAULT_MAX_NODES = 1000000;
+const DEFAULT_MAX_DEPTH = 256;
+
+/**
+ * YAML 1.2 core schema (geen timestamps, `yes`/`no` blijven strings) aangevuld met merge keys
+ * (`<<`), zodat alle services hetzelfde document zien als de linter.
+ */
+const YAML_SCHEMA = jsYaml.CORE_SCHEMA.extend({ implicit: [jsYaml.types.merge] });
+
+class YamlLimitError extends Error {
+  constructor(message) {
+    super(message);
+    this.name = "YamlLimitError";
+    this.status = 400;
+  }
+}
+
+const resolveLimit = (value, envValue, fallback) => {
+  const candidate = Number(value ?? envValue);
+  if (Number.isInteger(candidate) && candidate > 0) {
+    return candidate;
+  }
+  return fallback;
+};
+
+const resolveLimits = (options = {}) => ({
+  maxNodes: resolveLimit(options.maxNodes, process.env.YAML_MAX_NODES, DEFAULT_MAX_NODES),
+  maxDepth: resolveLimit(options.maxDepth, process.env.YAML_MAX_DEPTH, DEFAULT_MAX_DEPTH),
+});
+
+/**
+ * Berekent hoeveel nodes het document telt als alle aliases uitgeschreven worden. js-yaml deelt
+ * objecten achter een alias, dus de berekening wordt per object gememoized; zo kost een
+ * "billion laughs" document hier lineaire tijd en wordt het geweigerd voordat een serializer
+ * het opblaast. Per object wordt ook de hoogte (het aantal niveaus eronder) bewaard, zodat de
+ * diepte ook klopt voor een alias die dieper in het document opnieuw voorkomt. Recursieve
+ * aliases worden ook geweigerd.
+ */
+const assertWithinLimits = (document, options = {}) => {
+  const { maxNodes, maxDepth } = resolveLimits(options);
+  const measured = new Map();
+  const inProgress = new Set();
+  const tooDeep = () => new YamlLimitError(`YAML document is dieper dan ${maxDepth} niveaus.`);
+
+  const measure = (value, depth) => {
+    if (value === null || typeof value !== "object") {
+      return { size: 1, height: -1 };
+    }
+    if (measured.has(value)) {
+      const result = measured.get(value);
+      if (depth + result.height > maxDepth) {
+        throw tooDeep();
+      }
+      return result;
+    }
+    if (inProgress.has(value)) {
+      throw new YamlLimitError("YAML document bevat een recursieve alias.");
+    }
+    if (depth > maxDepth) {
+      throw tooDeep();
+    }
+    inProgress.add(value);
+    let size = 1;
+    let height = 0;
+    const children = Array.isArray(value) ? value : Object.values(value);
+    for (const child of children) {
+      const result = measure(child, depth + 1);
+      size += result.size;
+      height = Math.max(height, result.height + 1);
+      if (size > maxNodes) {
+        throw new YamlLimitError(`YAML document bevat na het uitschrijven van aliases meer dan ${maxNodes} nodes.`);
+      }
+    }
+    inProgress.delete(value);
+    const result = { size, height };
+    measured.set(value, result);
+    return result;
+  };
+
+  measure(document, 0);
+  return document;
+};
+
+const parseYaml = (contents, options = {}) => {
+  const document = jsYaml.load(contents, { schema: YAML_SCHEMA, filename: options.filename });
+  return assertWithinLimits(document, options);
+};
+
+/**
+ * Parseert JSON of YAML. JSON wordt eerst geprobeerd zodat het formaat van de input behouden kan
+ * blijven bij het serialiseren van het resultaat.
+ */
+const parseJsonOrYaml = (contents, options = {}) => {
+  const text = typeof contents === "string" ? contents : String(contents ?? "");
+  try {
+    return { document: assertWithinLimits(JSON.parse(text), options), format: "json" };
+  } catch (jsonError) {
+    if (jsonError instanceof YamlLimitError) {
+      throw jsonError;
+    }
+  }
+  return { document: parseYaml(text, options), format: "yaml" };
+};
+
+/**
+ * Controleert alleen de limieten en geeft het geparste document terug. Syntaxfouten worden
+ * genegeerd (resultaat `undefined`) zodat de aanroepende tool (Spectral, Redocly) zijn eigen, meer
+ * gedetailleerde melding kan geven.
+ */
+const assertSafeYaml = (contents, options = {}) => {
+  try {
+    return parseJsonOrYaml(contents, options).document;
+  } catch (error) {
+    if (error instanceof YamlLimitError) {
+      throw error;
+    }
+    return undefined;
+  }
+};
+
+/**
+ * Serialiseert zonder anchors/aliases (`noRefs`), zodat gedeelde objecten altijd uitgeschreven
+ * worden en de uitvoer voor elke consumer hetzelfde leest.
+ */
+const dumpYaml = (document) => jsYaml.dump(document, { lineWidth: -1, noRefs: true, schema: YAML_SCHEMA });
+
+module.exports = {
+  YAML_SCHEMA,
+  YamlLimitError,
+  assertSafeYaml,
+  assertWithinLimits,
+  dumpYaml,
+  parseJsonOrYaml,
+  parseYaml,
+};
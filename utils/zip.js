@@ -0,0 +1,240 @@
+const fs = require("node:fs");
+const { once } = require("node:events");
+const { Readable } = require("node:stream");
+const zlib = require("node:zlib");
+
+const LOCAL_HEADER_SIGNATURE = 0x04034b50;
+const CENTRAL_HEADER_SIGNATURE = 0x02014b50;
+const END_OF_CENTRAL_DIRECTORY_SIGNATURE = 0x06054b50;
+const DATA_DESCRIPTOR_SIGNATURE = 0x08074b50;
+const VERSION = 20;
+const UTF8_FLAG = 0x0800;
+const METHOD_STORED = 0;
+const METHOD_DEFLATE = 8;
+const ENCRYPTED_FLAG = 0x0001;
+const DATA_DESCRIPTOR_FLAG = 0x0008;
+const MAX_COMMENT_LENGTH = 0xffff;
+const DEFAULT_MAX_ENTRIES = 5000;
+const DEFAULT_MAX_SIZE = 50 * 1024 * 1024;
+const MADE_BY_UNIX = 3 << 8;
+
+const toDosDateTime = (date) => ({
+  time: (date.getHours() << 11) | (date.getMinutes() << 5) | Math.floor(date.getSeconds() / 2),
+  date: ((date.getFullYear() - 1980) << 9) | ((date.getMonth() + 1) << 5) | date.getDate(),
+});
+
+const localHeader = ({ name, flags, crc, compressedSize, size, time, date }) => {
+  const local = Buffer.alloc(30);
+  local.writeUInt32LE(LOCAL_HEADER_SIGNATURE, 0);
+  local.writeUInt16LE(VERSION, 4);
+  local.writeUInt16LE(flags, 6);
+  local.writeUInt16LE(METHOD_DEFLATE, 8);
+  local.writeUInt16LE(time, 10);
+  local.writeUInt16LE(date, 12);
+  local.writeUInt32LE(crc, 14);
+  local.writeUInt32LE(compressedSize, 18);
+  local.writeUInt32LE(size, 22);
+  local.writeUInt16LE(name.length, 26);
+  local.writeUInt16LE(0, 28);
+  return Buffer.concat([local, name]);
+};
+
+const centralHeader = ({ name, flags, crc, compressedSize, size, time, date, mode, offset }) => {
+  const central = Buffer.alloc(46);
+  central.writeUInt32LE(CENTRAL_HEADER_SIGNATURE, 0);
+  central.writeUInt16LE(mode ? MADE_BY_UNIX | VERSION : VERSION, 4);
+  central.writeUInt16LE(VERSION, 6);
+  central.writeUInt16LE(flags, 8);
+  central.writeUInt16LE(METHOD_DEFLATE, 10);
+  central.writeUInt16LE(time, 12);
+  central.writeUInt16LE(date, 14);
+  central.writeUInt32LE(crc, 16);
+  central.writeUInt32LE(compressedSize, 20);
+  central.writeUInt32LE(size, 24);
+  central.writeUInt16LE(name.length, 28);
+  if (mode) {
+    // Bovenste 16 bits: st_mode van een regulier bestand.
+    central.writeUInt32LE(((0o100000 | mode) << 16) >>> 0, 38);
+  }
+  central.writeUInt32LE(offset, 42);
+  return Buffer.concat([central, name]);
+};
+
+const endOfCentralDirectory = (count, size, offset) => {
+  const end = Buffer.alloc(22);
+  end.writeUInt32LE(END_OF_CENTRAL_DIRECTORY_SIGNATURE, 0);
+  end.writeUInt16LE(count, 8);
+  end.writeUInt16LE(count, 10);
+  end.writeUInt32LE(size, 12);
+  end.writeUInt32LE(offset, 16);
+  return end;
+};
+
+/**
+ * Minimale ZIP-writer (deflate, geen ZIP64) voor exports die uit meerdere bestanden bestaan. Genoeg
+ * voor collecties van een paar honderd kleine tekstbestanden, zonder extra dependency. Een entry met
+ * `mode` (bijvoorbeeld `0o755` voor scripts) krijgt Unix-bestandsrechten mee.
+ */
+const createZip = (entries, { now = new Date() } = {}) => {
+  const { time, date } = toDosDateTime(now);
+  const localParts = [];
+  const centralParts = [];
+  let offset = 0;
+  for (const entry of entries) {
+    const name = Buffer.from(entry.name, "utf8");
+    const data = Buffer.isBuffer(entry.data) ? entry.data : Buffer.from(String(entry.data), "utf8");
+    const compressed = zlib.deflateRawSync(data);
+    const header = {
+      name,
+      flags: UTF8_FLAG,
+      crc: zlib.crc32(data),
+      compressedSize: compressed.length,
+      size: data.length,
+      time,
+      date,
+    };
+    const local = localHeader(header);
+    localParts.push(local, compressed);
+    centralParts.push(centralHeader({ ...header, mode: entry.mode, offset }));
+    offset += local.length + compressed.length;
+  }
+  const centralDirectory = Buffer.concat(centralParts);
+  const end = endOfCentralDirectory(entries.length, centralDirectory.length, offset);
+  return Buffer.concat([...localParts, centralDirectory, end]);
+};
+
+async function* zipChunks(entries, { time, date }) {
+  const centralParts = [];
+  let offset = 0;
+  for (const entry of entries) {
+    const name = Buffer.from(entry.name, "utf8");
+    // CRC en groottes zijn pas na het comprimeren bekend; ze volgen in een data descriptor.
+    const flags = UTF8_FLAG | DATA_DESCRIPTOR_FLAG;
+    const local = localHeader({ name, flags, crc: 0, compressedSize: 0, size: 0, time, date });
+    yield local;
+
+    const source =
+      entry.path !== undefined
+        ? fs.createReadStream(entry.path)
+        : [Buffer.isBuffer(entry.data) ? entry.data : Buffer.from(String(entry.data), "utf8")];
+    const deflate = zlib.createDeflateRaw();
+    let crc = 0;
+    let size = 0;
+    let compressedSize = 0;
+    const feed = (async () => {
+      for await (const chunk of source) {
+        crc = zlib.crc32(chunk, crc);
+        size += chunk.length;
+        if (!deflate.write(chunk)) {
+          await once(deflate, "drain");
+        }
+      }
+      deflate.end();
+    })().catch((error) => deflate.destroy(error));
+    for await (const chunk of deflate) {
+      compressedSize += chunk.length;
+      yield chunk;
+    }
+    await feed;
+
+    const descriptor = Buffer.alloc(16);
+    descriptor.writeUInt32LE(DATA_DESCRIPTOR_SIGNATURE, 0);
+    descriptor.writeUInt32LE(crc, 4);
+    descriptor.writeUInt32LE(compressedSize, 8);
+    descriptor.writeUInt32LE(size, 12);
+    yield descriptor;
+
+    centralParts.push(centralHeader({ name, flags, crc, compressedSize, size, time, date, mode: entry.mode, offset }));
+    offset += local.length + compressedSize + descriptor.length;
+  }
+  const centralDirectory = Buffer.concat(centralParts);
+  yield centralDirectory;
+  yield endOfCentralDirectory(entries.length, centralDirectory.length, offset);
+}
+
+/**
+ * Zelfde ZIP als `createZip`, maar als stream: entries worden één voor één gecomprimeerd en
+ * doorgegeven, zodat het archief nooit in zijn geheel in het geheugen staat. Een entry heeft `data`
+ * of `path` (een bestand op schijf, dat in stukken gelezen wordt).
+ */
+const createZipStream = (entries, { now = new Date() } = {}) =>
+  Readable.from(zipChunks(entries, toDosDateTime(now)), { objectMode: false });
+
+const findEndOfCentralDirectory = (buffer) => {
+  const lowest = Math.max(0, buffer.length - 22 - MAX_COMMENT_LENGTH);
+  for (let position = buffer.length - 22; position >= lowest; position -= 1) {
+    if (buffer.readUInt32LE(position) === END_OF_CENTRAL_DIRECTORY_SIGNATURE) {
+      return position;
+    }
+  }
+  throw new Error("Geen geldig ZIP-bestand: einde van de centrale directory ontbreekt.");
+};
+
+/**
+ * Leest de bestanden uit een ZIP (stored of deflate, geen ZIP64 of encryptie) als
+ * `[{ name, data }]`, zonder mappen. `maxEntries` en `maxSize` (totaal uitgepakt, in bytes) begrenzen
+ * wat een upload mag opleveren, zodat een zip bomb niet het geheugen vult.
+ */
+const readZip = (buffer, { maxEntries = DEFAULT_MAX_ENTRIES, maxSize = DEFAULT_MAX_SIZE } = {}) => {
+  if (!Buffer.isBuffer(buffer) || buffer.length < 22) {
+    throw new Error("Geen geldig ZIP-bestand.");
+  }
+  const end = findEndOfCentralDirectory(buffer);
+  const count = buffer.readUInt16LE(end + 10);
+  if (count > maxEntries) {
+    throw new Error(`ZIP-bestand bevat meer dan ${maxEntries} bestanden.`);
+  }
+  const files = [];
+  let position = buffer.readUInt32LE(end + 16);
+  let remaining = maxSize;
+  for (let index = 0; index < count; index += 1) {
+    if (position + 46 > buffer.length || buffer.readUInt32LE(position) !== CENTRAL_HEADER_SIGNATURE) {
+      throw new Error("Geen geldig ZIP-bestand: centrale directory is beschadigd.");
+    }
+    const flags = buffer.readUInt16LE(position + 8);
+    const method = buffer.readUInt16LE(position + 10);
+    const compressedSize = buffer.readUInt32LE(position + 20);
+    const size = buffer.readUInt32LE(position + 24);
+    const nameLength = buffer.readUInt16LE(position + 28);
+    const extraLength = buffer.readUInt16LE(position + 30);
+    const commentLength = buffer.readUInt16LE(position + 32);
+    const localOffset = buffer.readUInt32LE(position + 42);
+    const name = buffer
+      .subarray(position + 46, position + 46 + nameLength)
+      .toString(flags & UTF8_FLAG ? "utf8" : "latin1");
+    position += 46 + nameLength + extraLength + commentLength;
+
+    if (name.endsWith("/")) {
+      continue;
+    }
+    if (flags & ENCRYPTED_FLAG) {
+      throw new Error(`Versleutelde bestanden in een ZIP worden niet ondersteund: ${name}.`);
+    }
+    if (size > remaining) {
+      throw new Error(`ZIP-bestand is uitgepakt groter dan ${maxSize} bytes.`);
+    }
+    if (localOffset + 30 > buffer.length || buffer.readUInt32LE(localOffset) !== LOCAL_HEADER_SIGNATURE) {
+      throw new Error(`Geen geldig ZIP-bestand: lokale header van ${name} ontbreekt.`);
+    }
+    const dataStart = localOffset + 30 + buffer.readUInt16LE(localOffset + 26) + buffer.readUInt16LE(localOffset + 28);
+    const compressed = buffer.subarray(dataStart, dataStart + compressedSize);
+    let data;
+    if (method === METHOD_STORED) {
+      data = compressed;
+    } else if (method === METHOD_DEFLATE) {
+      // De grootte uit de header is niet te vertrouwen; begrens daarom ook het uitpakken zelf.
+      data = zlib.inflateRawSync(compressed, { maxOutputLength: Math.max(remaining, 1) });
+    } else {
+      throw new Error(`Compressiemethode ${method} van ${name} wordt niet ondersteund.`);
+    }
+    remaining -= data.length;
+    files.push({ name, data });
+  }
+  return files;
+};
+
+module.exports = {
+  createZip,
+  createZipStream,
+  readZip,
+};